@@ -226,6 +226,45 @@ grafana-cli user-manager conflicts ingest-file <filepath>
 	},
 }
 
+var alertingCommands = []*cli.Command{
+	{
+		Name:  "import-prometheus-rules",
+		Usage: "import-prometheus-rules <rule file>",
+		Action: func(context *cli.Context) error {
+			return importPrometheusRulesCommand(&utils.ContextCommandLine{Context: context})
+		},
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "url",
+				Usage: "The URL of the Grafana instance",
+				Value: "http://localhost:3000",
+			},
+			&cli.StringFlag{
+				Name:    "token",
+				Usage:   "The service account token used to authenticate with the Grafana instance",
+				EnvVars: []string{"GF_CLI_TOKEN"},
+			},
+			&cli.StringFlag{
+				Name:  "folder-uid",
+				Usage: "The UID of the folder the rules are imported to",
+			},
+			&cli.StringFlag{
+				Name:  "datasource-uid",
+				Usage: "The UID of the Prometheus data source that the rules query",
+			},
+			&cli.StringFlag{
+				Name:  "recording-rules-target-datasource-uid",
+				Usage: "The UID of the data source that the recording rules write to",
+			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Print the changes without saving them",
+				Value: false,
+			},
+		},
+	},
+}
+
 var Commands = []*cli.Command{
 	{
 		Name:        "plugins",
@@ -237,4 +276,9 @@ var Commands = []*cli.Command{
 		Usage:       "Grafana admin commands",
 		Subcommands: adminCommands,
 	},
+	{
+		Name:        "alerting",
+		Usage:       "Grafana Alerting commands",
+		Subcommands: alertingCommands,
+	},
 }
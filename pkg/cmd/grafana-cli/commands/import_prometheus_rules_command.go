@@ -0,0 +1,111 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+const importPrometheusRulesTimeout = time.Minute
+
+var importPrometheusRulesClient = &http.Client{Timeout: importPrometheusRulesTimeout}
+
+// importPrometheusRulesCommand sends a Prometheus rule file to the import endpoint of the ruler API of a Grafana
+// instance and prints the changes to the rule groups of the folder.
+func importPrometheusRulesCommand(c utils.CommandLine) error {
+	path := c.Args().First()
+	if path == "" {
+		return errors.New("please specify the path to the Prometheus rule file")
+	}
+	folderUID := c.String("folder-uid")
+	if folderUID == "" {
+		return errors.New("missing folder-uid flag")
+	}
+	datasourceUID := c.String("datasource-uid")
+	if datasourceUID == "" {
+		return errors.New("missing datasource-uid flag")
+	}
+
+	rules, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read the rule file: %w", err)
+	}
+	body, err := json.Marshal(apimodels.PrometheusRulesImport{
+		Rules:                             string(rules),
+		DatasourceUID:                     datasourceUID,
+		RecordingRulesTargetDatasourceUID: c.String("recording-rules-target-datasource-uid"),
+		DryRun:                            c.Bool("dry-run"),
+	})
+	if err != nil {
+		return err
+	}
+
+	endpoint := strings.TrimSuffix(c.String("url"), "/") + "/api/ruler/grafana/api/v1/rules/" + url.PathEscape(folderUID) + "/import"
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token := c.String("token"); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := importPrometheusRulesClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send the rule file to Grafana: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			logger.Warnf("failed to close response body: %v\n", err)
+		}
+	}()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read the response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		return fmt.Errorf("failed to import the rule file: %s: %s", resp.Status, strings.TrimSpace(string(respBody)))
+	}
+
+	var result apimodels.PrometheusRulesImportResponse
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return fmt.Errorf("failed to parse the response: %w", err)
+	}
+	printPrometheusRulesImport(result)
+	return nil
+}
+
+func printPrometheusRulesImport(result apimodels.PrometheusRulesImportResponse) {
+	if result.DryRun {
+		logger.Info("dry run, the following changes were not saved:\n")
+	}
+	for _, group := range result.Groups {
+		if len(group.Created) == 0 && len(group.Updated) == 0 && len(group.Deleted) == 0 {
+			logger.Infof("group %s: no changes\n", group.Name)
+			continue
+		}
+		logger.Infof("group %s:\n", group.Name)
+		for _, title := range group.Created {
+			logger.Infof("  %s %s\n", color.GreenString("+"), title)
+		}
+		for _, diff := range group.Updated {
+			logger.Infof("  %s %s (%s)\n", color.YellowString("~"), diff.Title, strings.Join(diff.Fields, ", "))
+		}
+		for _, title := range group.Deleted {
+			logger.Infof("  %s %s\n", color.RedString("-"), title)
+		}
+	}
+}
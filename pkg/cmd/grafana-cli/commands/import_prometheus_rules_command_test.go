@@ -0,0 +1,91 @@
+package commands
+
+import (
+	"encoding/json"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+func TestImportPrometheusRulesCommand(t *testing.T) {
+	rules := "groups:\n  - name: node\n    rules:\n      - alert: InstanceDown\n        expr: up == 0\n"
+	path := filepath.Join(t.TempDir(), "rules.yml")
+	require.NoError(t, os.WriteFile(path, []byte(rules), 0o600))
+
+	newCommandLine := func(t *testing.T, flags map[string]string, args ...string) utils.CommandLine {
+		t.Helper()
+		flagSet := flag.NewFlagSet("Test", 0)
+		flagSet.Bool("dry-run", false, "")
+		for name, value := range flags {
+			if name == "dry-run" {
+				require.NoError(t, flagSet.Set(name, value))
+				continue
+			}
+			flagSet.String(name, "", "")
+			require.NoError(t, flagSet.Set(name, value))
+		}
+		require.NoError(t, flagSet.Parse(args))
+		return &utils.ContextCommandLine{Context: cli.NewContext(&cli.App{Name: "Test"}, flagSet, nil)}
+	}
+
+	t.Run("sends the rule file to the import endpoint", func(t *testing.T) {
+		var received apimodels.PrometheusRulesImport
+		var authorization, requestPath string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestPath = r.URL.Path
+			authorization = r.Header.Get("Authorization")
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+			w.WriteHeader(http.StatusOK)
+			require.NoError(t, json.NewEncoder(w).Encode(apimodels.PrometheusRulesImportResponse{
+				DryRun: true,
+				Groups: []apimodels.PrometheusRulesImportGroupDelta{{Name: "node", Created: []string{"InstanceDown"}}},
+			}))
+		}))
+		t.Cleanup(server.Close)
+
+		c := newCommandLine(t, map[string]string{
+			"url":            server.URL,
+			"token":          "secret",
+			"folder-uid":     "folder",
+			"datasource-uid": "prometheus",
+			"dry-run":        "true",
+		}, path)
+		require.NoError(t, importPrometheusRulesCommand(c))
+
+		require.Equal(t, "/api/ruler/grafana/api/v1/rules/folder/import", requestPath)
+		require.Equal(t, "Bearer secret", authorization)
+		require.Equal(t, rules, received.Rules)
+		require.Equal(t, "prometheus", received.DatasourceUID)
+		require.True(t, received.DryRun)
+	})
+
+	t.Run("returns the error of the server", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, `{"message":"invalid Prometheus rule file"}`, http.StatusBadRequest)
+		}))
+		t.Cleanup(server.Close)
+
+		c := newCommandLine(t, map[string]string{
+			"url":            server.URL,
+			"folder-uid":     "folder",
+			"datasource-uid": "prometheus",
+		}, path)
+		err := importPrometheusRulesCommand(c)
+		require.ErrorContains(t, err, "invalid Prometheus rule file")
+	})
+
+	t.Run("requires rule file, folder and data source", func(t *testing.T) {
+		require.Error(t, importPrometheusRulesCommand(newCommandLine(t, map[string]string{"folder-uid": "folder", "datasource-uid": "prometheus"})))
+		require.Error(t, importPrometheusRulesCommand(newCommandLine(t, map[string]string{"datasource-uid": "prometheus"}, path)))
+		require.Error(t, importPrometheusRulesCommand(newCommandLine(t, map[string]string{"folder-uid": "folder"}, path)))
+	})
+}
@@ -0,0 +1,310 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// Algorithm used to detect anomalies
+// +enum
+type AnomalyAlgorithm string
+
+const (
+	// Distance from the mean of the rolling window, in standard deviations
+	AnomalyZScore AnomalyAlgorithm = "zscore"
+
+	// Distance from the median of the rolling window, in median absolute deviations
+	AnomalyMAD AnomalyAlgorithm = "mad"
+
+	// Distance from the seasonal baseline, in standard deviations of the residuals
+	AnomalySeasonal AnomalyAlgorithm = "seasonal"
+)
+
+// Result of the anomaly detection
+// +enum
+type AnomalyOutput string
+
+const (
+	// One number per series, 1 if the latest point is an anomaly, otherwise 0
+	AnomalyOutputNumber AnomalyOutput = "number"
+
+	// Lower and upper bound series of the expected values for each point
+	AnomalyOutputBand AnomalyOutput = "band"
+
+	// Both the number and the band series of each series
+	AnomalyOutputBoth AnomalyOutput = "both"
+)
+
+const (
+	defaultAnomalyWindow      = 20
+	defaultAnomalySensitivity = 3
+
+	// AnomalyBandLabel is the label that tells the lower and the upper bound series of a band apart.
+	AnomalyBandLabel = "anomaly_band"
+
+	// madScale makes the median absolute deviation a consistent estimator of the standard deviation of normally distributed data.
+	madScale = 1.4826
+)
+
+var supportedAnomalyAlgorithms = []string{
+	string(AnomalyZScore),
+	string(AnomalyMAD),
+	string(AnomalySeasonal),
+}
+
+// AnomalyCommand is an expression command that flags anomalies in time series without calling any external service.
+// For every point it computes a band of expected values from the points that precede it, and the point is an anomaly if
+// it falls outside of the band.
+type AnomalyCommand struct {
+	ReferenceVar string
+	RefID        string
+	Algorithm    AnomalyAlgorithm
+	// Window is the number of preceding points the band is computed from.
+	Window int
+	// Sensitivity is the half-width of the band, in standard deviations (or scaled median absolute deviations).
+	Sensitivity float64
+	// Season is the number of points in one season. Only used by the seasonal algorithm.
+	Season int
+	Output AnomalyOutput
+}
+
+// NewAnomalyCommand creates a new AnomalyCommand. Zero window and sensitivity fall back to the defaults.
+func NewAnomalyCommand(refID, referenceVar string, algorithm AnomalyAlgorithm, window int, sensitivity float64, season int, output AnomalyOutput) (*AnomalyCommand, error) {
+	if window == 0 {
+		window = defaultAnomalyWindow
+	}
+	if sensitivity == 0 {
+		sensitivity = defaultAnomalySensitivity
+	}
+	if output == "" {
+		output = AnomalyOutputNumber
+	}
+
+	switch algorithm {
+	case AnomalyZScore, AnomalyMAD:
+	case AnomalySeasonal:
+		if season < 2 {
+			return nil, fmt.Errorf("seasonal anomaly detection requires a season of at least 2 points, got %d", season)
+		}
+		if window < season {
+			return nil, fmt.Errorf("seasonal anomaly detection requires a window of at least one season (%d points), got %d", season, window)
+		}
+	default:
+		return nil, fmt.Errorf("expected anomaly algorithm to be one of [%s], got %s", strings.Join(supportedAnomalyAlgorithms, ", "), algorithm)
+	}
+	if window < 3 {
+		return nil, fmt.Errorf("anomaly window must be at least 3 points, got %d", window)
+	}
+	if sensitivity < 0 {
+		return nil, fmt.Errorf("anomaly sensitivity must not be negative, got %v", sensitivity)
+	}
+	switch output {
+	case AnomalyOutputNumber, AnomalyOutputBand, AnomalyOutputBoth:
+	default:
+		return nil, fmt.Errorf("expected anomaly output to be one of [%s, %s, %s], got %s", AnomalyOutputNumber, AnomalyOutputBand, AnomalyOutputBoth, output)
+	}
+
+	return &AnomalyCommand{
+		ReferenceVar: referenceVar,
+		RefID:        refID,
+		Algorithm:    algorithm,
+		Window:       window,
+		Sensitivity:  sensitivity,
+		Season:       season,
+		Output:       output,
+	}, nil
+}
+
+// UnmarshalAnomalyCommand creates an AnomalyCommand from Grafana's frontend query.
+func UnmarshalAnomalyCommand(rn *rawNode) (*AnomalyCommand, error) {
+	q := AnomalyQuery{}
+	if err := json.Unmarshal(rn.QueryRaw, &q); err != nil {
+		return nil, fmt.Errorf("failed to parse the anomaly command: %w", err)
+	}
+	referenceVar, err := getReferenceVar(q.Expression, rn.RefID)
+	if err != nil {
+		return nil, err
+	}
+	return NewAnomalyCommand(rn.RefID, referenceVar, q.Algorithm, q.Window, q.Sensitivity, q.Season, q.Output)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (ac *AnomalyCommand) NeedsVars() []string {
+	return []string{ac.ReferenceVar}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (ac *AnomalyCommand) Execute(ctx context.Context, _ time.Time, vars mathexp.Vars, tracer tracing.Tracer) (mathexp.Results, error) {
+	_, span := tracer.Start(ctx, "SSE.ExecuteAnomaly")
+	defer span.End()
+	span.SetAttributes(attribute.String("algorithm", string(ac.Algorithm)), attribute.Int("window", ac.Window))
+
+	newRes := mathexp.Results{}
+	for _, val := range vars[ac.ReferenceVar].Values {
+		switch v := val.(type) {
+		case mathexp.Series:
+			lower, upper, anomalous := ac.band(v)
+			if ac.Output != AnomalyOutputBand {
+				n := mathexp.NewNumber(ac.RefID, v.GetLabels())
+				n.SetValue(util.Pointer(float64(0)))
+				if anomalous {
+					n.SetValue(util.Pointer(float64(1)))
+				}
+				newRes.Values = append(newRes.Values, n)
+			}
+			if ac.Output != AnomalyOutputNumber {
+				newRes.Values = append(newRes.Values,
+					ac.bandSeries(v, "lower", lower),
+					ac.bandSeries(v, "upper", upper),
+				)
+			}
+		case mathexp.NoData:
+			newRes.Values = append(newRes.Values, v.New())
+		default:
+			return newRes, fmt.Errorf("can only detect anomalies in type series, got type %v", val.Type())
+		}
+	}
+	return newRes, nil
+}
+
+func (ac *AnomalyCommand) Type() string {
+	return TypeAnomaly.String()
+}
+
+// bandSeries creates the series of one bound of the band. Its labels are the labels of s plus AnomalyBandLabel.
+func (ac *AnomalyCommand) bandSeries(s mathexp.Series, bound string, values []*float64) mathexp.Series {
+	labels := data.Labels{}
+	if s.GetLabels() != nil {
+		labels = s.GetLabels().Copy()
+	}
+	labels[AnomalyBandLabel] = bound
+	band := mathexp.NewSeries(ac.RefID, labels, s.Len())
+	for i := 0; i < s.Len(); i++ {
+		band.SetPoint(i, s.GetTime(i), values[i])
+	}
+	return band
+}
+
+// band computes the lower and upper bound of the expected value of every point of s. The bounds are nil where there is
+// not enough history to compute them. The last return value is true if the latest non-null point is outside of its band.
+func (ac *AnomalyCommand) band(s mathexp.Series) ([]*float64, []*float64, bool) {
+	lower := make([]*float64, s.Len())
+	upper := make([]*float64, s.Len())
+
+	// indexes of the non-null points, the algorithms only look at those
+	idx := make([]int, 0, s.Len())
+	values := make([]float64, 0, s.Len())
+	for i := 0; i < s.Len(); i++ {
+		if f := s.GetValue(i); f != nil && !math.IsNaN(*f) {
+			idx = append(idx, i)
+			values = append(values, *f)
+		}
+	}
+
+	anomalous := false
+	for j := range values {
+		center, deviation, ok := ac.expected(values, j)
+		if !ok {
+			continue
+		}
+		lo := center - ac.Sensitivity*deviation
+		hi := center + ac.Sensitivity*deviation
+		lower[idx[j]] = &lo
+		upper[idx[j]] = &hi
+		if j == len(values)-1 {
+			anomalous = values[j] < lo || values[j] > hi
+		}
+	}
+	return lower, upper, anomalous
+}
+
+// expected returns the expected value of values[j] and its deviation, computed from the points before j.
+func (ac *AnomalyCommand) expected(values []float64, j int) (float64, float64, bool) {
+	history := values[max(0, j-ac.Window):j]
+	switch ac.Algorithm {
+	case AnomalyMAD:
+		if len(history) < 2 {
+			return 0, 0, false
+		}
+		m := median(history)
+		deviations := make([]float64, len(history))
+		for i, v := range history {
+			deviations[i] = math.Abs(v - m)
+		}
+		return m, median(deviations) * madScale, true
+	case AnomalySeasonal:
+		baseline, ok := seasonalBaseline(values, j, ac.Season, ac.Window)
+		if !ok {
+			return 0, 0, false
+		}
+		residuals := make([]float64, 0, len(history))
+		for i := j - len(history); i < j; i++ {
+			if b, ok := seasonalBaseline(values, i, ac.Season, ac.Window); ok {
+				residuals = append(residuals, values[i]-b)
+			}
+		}
+		if len(residuals) < 2 {
+			return 0, 0, false
+		}
+		_, sd := meanStdDev(residuals)
+		return baseline, sd, true
+	default:
+		if len(history) < 2 {
+			return 0, 0, false
+		}
+		mean, sd := meanStdDev(history)
+		return mean, sd, true
+	}
+}
+
+// seasonalBaseline returns the mean of the values at the same position in the previous seasons that are within the window.
+func seasonalBaseline(values []float64, j, season, window int) (float64, bool) {
+	var sum float64
+	var count int
+	for k := j - season; k >= 0 && j-k <= window; k -= season {
+		sum += values[k]
+		count++
+	}
+	if count == 0 {
+		return 0, false
+	}
+	return sum / float64(count), true
+}
+
+func meanStdDev(values []float64) (float64, float64) {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+	var sq float64
+	for _, v := range values {
+		sq += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(sq / float64(len(values)))
+}
+
+func median(values []float64) float64 {
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
@@ -0,0 +1,169 @@
+package expr
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func TestNewAnomalyCommand(t *testing.T) {
+	cases := []struct {
+		name          string
+		algorithm     AnomalyAlgorithm
+		window        int
+		sensitivity   float64
+		season        int
+		output        AnomalyOutput
+		expectedError string
+	}{
+		{name: "zscore with defaults", algorithm: AnomalyZScore},
+		{name: "mad", algorithm: AnomalyMAD, window: 10, sensitivity: 2},
+		{name: "seasonal", algorithm: AnomalySeasonal, window: 48, season: 24, output: AnomalyOutputBand},
+		{name: "unknown algorithm", algorithm: "prophet", expectedError: "expected anomaly algorithm to be one of"},
+		{name: "seasonal without season", algorithm: AnomalySeasonal, expectedError: "requires a season"},
+		{name: "seasonal window shorter than season", algorithm: AnomalySeasonal, window: 10, season: 24, expectedError: "requires a window of at least one season"},
+		{name: "window too small", algorithm: AnomalyZScore, window: 2, expectedError: "window must be at least 3"},
+		{name: "negative sensitivity", algorithm: AnomalyZScore, sensitivity: -1, expectedError: "must not be negative"},
+		{name: "unknown output", algorithm: AnomalyZScore, output: "table", expectedError: "expected anomaly output"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cmd, err := NewAnomalyCommand("B", "A", tc.algorithm, tc.window, tc.sensitivity, tc.season, tc.output)
+			if tc.expectedError != "" {
+				require.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, []string{"A"}, cmd.NeedsVars())
+			require.NotZero(t, cmd.Window)
+			require.NotZero(t, cmd.Sensitivity)
+			require.NotEmpty(t, cmd.Output)
+		})
+	}
+}
+
+func TestUnmarshalAnomalyCommand(t *testing.T) {
+	rn := &rawNode{
+		RefID:    "B",
+		QueryRaw: []byte(`{"type":"anomaly","expression":"$A","algorithm":"mad","window":15,"sensitivity":2.5,"output":"band"}`),
+	}
+	cmd, err := UnmarshalAnomalyCommand(rn)
+	require.NoError(t, err)
+	require.Equal(t, &AnomalyCommand{
+		ReferenceVar: "A",
+		RefID:        "B",
+		Algorithm:    AnomalyMAD,
+		Window:       15,
+		Sensitivity:  2.5,
+		Output:       AnomalyOutputBand,
+	}, cmd)
+}
+
+func TestAnomalyCommandExecute(t *testing.T) {
+	makeSeries := func(labels data.Labels, values ...float64) mathexp.Series {
+		s := mathexp.NewSeries("A", labels, len(values))
+		for i, v := range values {
+			s.SetPoint(i, time.Unix(int64(i*60), 0), util.Pointer(v))
+		}
+		return s
+	}
+	flat := []float64{10, 11, 9, 10, 11, 9, 10, 11, 9, 10}
+
+	cases := []struct {
+		name      string
+		algorithm AnomalyAlgorithm
+		season    int
+		values    []float64
+		expected  float64
+	}{
+		{name: "zscore detects a spike", algorithm: AnomalyZScore, values: append(flat, 50), expected: 1},
+		{name: "zscore ignores normal values", algorithm: AnomalyZScore, values: append(flat, 10.5), expected: 0},
+		{name: "mad detects a drop", algorithm: AnomalyMAD, values: append(flat, -20), expected: 1},
+		{name: "mad ignores normal values", algorithm: AnomalyMAD, values: append(flat, 9.5), expected: 0},
+		{name: "seasonal follows the season", algorithm: AnomalySeasonal, season: 3, values: []float64{1, 5, 9, 1.2, 5.1, 9.2, 0.9, 4.9, 8.8, 1.1, 5.2}, expected: 0},
+		{name: "seasonal detects a value out of season", algorithm: AnomalySeasonal, season: 3, values: []float64{1, 5, 9, 1.2, 5.1, 9.2, 0.9, 4.9, 8.8, 1.1, 9}, expected: 1},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cmd, err := NewAnomalyCommand("B", "A", tc.algorithm, 0, 0, tc.season, "")
+			require.NoError(t, err)
+			vars := mathexp.Vars{"A": mathexp.Results{Values: mathexp.Values{makeSeries(data.Labels{"host": "a"}, tc.values...)}}}
+
+			res, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+			require.NoError(t, err)
+			require.Len(t, res.Values, 1)
+			n, ok := res.Values[0].(mathexp.Number)
+			require.True(t, ok)
+			require.Equal(t, data.Labels{"host": "a"}, n.GetLabels())
+			require.Equal(t, tc.expected, *n.GetFloat64Value())
+		})
+	}
+
+	t.Run("band output returns lower and upper series", func(t *testing.T) {
+		cmd, err := NewAnomalyCommand("B", "A", AnomalyZScore, 0, 0, 0, AnomalyOutputBand)
+		require.NoError(t, err)
+		vars := mathexp.Vars{"A": mathexp.Results{Values: mathexp.Values{makeSeries(data.Labels{"host": "a"}, 1, 2, 3, 4)}}}
+
+		res, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.Len(t, res.Values, 2)
+
+		lower, upper := res.Values[0].(mathexp.Series), res.Values[1].(mathexp.Series)
+		require.Equal(t, data.Labels{"host": "a", AnomalyBandLabel: "lower"}, lower.GetLabels())
+		require.Equal(t, data.Labels{"host": "a", AnomalyBandLabel: "upper"}, upper.GetLabels())
+		require.Equal(t, 4, lower.Len())
+		// there is not enough history for the first two points
+		require.Nil(t, lower.GetValue(0))
+		require.Nil(t, upper.GetValue(1))
+		// mean of 1 and 2 is 1.5 with a standard deviation of 0.5
+		require.Equal(t, 0.0, *lower.GetValue(2))
+		require.Equal(t, 3.0, *upper.GetValue(2))
+	})
+
+	t.Run("both output returns the number and the band series", func(t *testing.T) {
+		cmd, err := NewAnomalyCommand("B", "A", AnomalyZScore, 0, 0, 0, AnomalyOutputBoth)
+		require.NoError(t, err)
+		vars := mathexp.Vars{"A": mathexp.Results{Values: mathexp.Values{makeSeries(data.Labels{"host": "a"}, 1, 2, 3, 4, 100)}}}
+
+		res, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.Len(t, res.Values, 3)
+
+		n, ok := res.Values[0].(mathexp.Number)
+		require.True(t, ok)
+		require.Equal(t, data.Labels{"host": "a"}, n.GetLabels())
+		require.Equal(t, 1.0, *n.GetFloat64Value())
+		lower, upper := res.Values[1].(mathexp.Series), res.Values[2].(mathexp.Series)
+		require.Equal(t, data.Labels{"host": "a", AnomalyBandLabel: "lower"}, lower.GetLabels())
+		require.Equal(t, data.Labels{"host": "a", AnomalyBandLabel: "upper"}, upper.GetLabels())
+		require.Equal(t, 5, upper.Len())
+	})
+
+	t.Run("no data is passed through", func(t *testing.T) {
+		cmd, err := NewAnomalyCommand("B", "A", AnomalyZScore, 0, 0, 0, "")
+		require.NoError(t, err)
+		vars := mathexp.Vars{"A": mathexp.Results{Values: mathexp.Values{mathexp.NewNoData()}}}
+
+		res, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.True(t, res.IsNoData())
+	})
+
+	t.Run("numbers are rejected", func(t *testing.T) {
+		cmd, err := NewAnomalyCommand("B", "A", AnomalyZScore, 0, 0, 0, "")
+		require.NoError(t, err)
+		vars := mathexp.Vars{"A": mathexp.Results{Values: mathexp.Values{mathexp.NewNumber("A", nil)}}}
+
+		_, err = cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.Error(t, err)
+	})
+}
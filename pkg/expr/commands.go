@@ -73,7 +73,9 @@ func (gm *MathCommand) Execute(ctx context.Context, _ time.Time, vars mathexp.Va
 	_, span := tracer.Start(ctx, "SSE.ExecuteMath")
 	span.SetAttributes(attribute.String("expression", gm.RawExpression))
 	defer span.End()
-	return gm.Expression.Execute(gm.refID, vars, tracer)
+	res, drops, err := gm.Expression.ExecuteWithDrops(gm.refID, vars, tracer)
+	TraceFromContext(ctx).addMathDrops(gm.refID, drops)
+	return res, err
 }
 
 func (gm *MathCommand) Type() string {
@@ -90,8 +92,7 @@ type ReduceCommand struct {
 
 // NewReduceCommand creates a new ReduceCMD.
 func NewReduceCommand(refID string, reducer mathexp.ReducerID, varToReduce string, mapper mathexp.ReduceMapper) (*ReduceCommand, error) {
-	_, err := mathexp.GetReduceFunc(reducer)
-	if err != nil {
+	if err := mathexp.ValidateReducer(reducer); err != nil {
 		return nil, err
 	}
 
@@ -214,24 +215,36 @@ func (gr *ReduceCommand) Type() string {
 type ResampleCommand struct {
 	Window        time.Duration
 	VarToResample string
-	Downsampler   mathexp.ReducerID
+	Downsampler   mathexp.Downsampler
 	Upsampler     mathexp.Upsampler
+	Alignment     mathexp.ResampleAlignment
 	TimeRange     TimeRange
 	refID         string
 }
 
 // NewResampleCommand creates a new ResampleCMD.
-func NewResampleCommand(refID, rawWindow, varToResample string, downsampler mathexp.ReducerID, upsampler mathexp.Upsampler, tr TimeRange) (*ResampleCommand, error) {
-	// TODO: validate reducer here, before execution
+func NewResampleCommand(refID, rawWindow, varToResample string, downsampler mathexp.Downsampler, upsampler mathexp.Upsampler, alignment mathexp.ResampleAlignment, tr TimeRange) (*ResampleCommand, error) {
+	switch downsampler {
+	case mathexp.DownsamplerSum, mathexp.DownsamplerMean, mathexp.DownsamplerMin, mathexp.DownsamplerMax,
+		mathexp.DownsamplerCount, mathexp.DownsamplerLast, mathexp.DownsamplerFirst:
+	default:
+		return nil, fmt.Errorf("resample downsampler %v not implemented", downsampler)
+	}
 	window, err := gtime.ParseDuration(rawWindow)
 	if err != nil {
 		return nil, fmt.Errorf(`failed to parse resample "window" duration field %q: %w`, window, err)
 	}
+	switch alignment {
+	case "", mathexp.ResampleAlignStart, mathexp.ResampleAlignEpoch:
+	default:
+		return nil, fmt.Errorf("resample alignment %v not implemented", alignment)
+	}
 	return &ResampleCommand{
 		Window:        window,
 		VarToResample: varToResample,
 		Downsampler:   downsampler,
 		Upsampler:     upsampler,
+		Alignment:     alignment,
 		TimeRange:     tr,
 		refID:         refID,
 	}, nil
@@ -280,10 +293,19 @@ func UnmarshalResampleCommand(rn *rawNode) (*ResampleCommand, error) {
 		return nil, fmt.Errorf("expected resample downsampler to be a string, got type %T", upsampler)
 	}
 
+	var alignment string
+	if rawAlignment, ok := rn.Query["alignment"]; ok {
+		alignment, ok = rawAlignment.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected resample alignment to be a string, got type %T", rawAlignment)
+		}
+	}
+
 	return NewResampleCommand(rn.RefID, window,
 		varToResample,
-		mathexp.ReducerID(downsampler),
+		mathexp.Downsampler(downsampler),
 		mathexp.Upsampler(upsampler),
+		mathexp.ResampleAlignment(alignment),
 		rn.TimeRange)
 }
 
@@ -300,13 +322,17 @@ func (gr *ResampleCommand) Execute(ctx context.Context, now time.Time, vars math
 	defer span.End()
 	newRes := mathexp.Results{}
 	timeRange := gr.TimeRange.AbsoluteTime(now)
+	from, err := gr.Alignment.Align(timeRange.From, gr.Window)
+	if err != nil {
+		return newRes, err
+	}
 	for _, val := range vars[gr.VarToResample].Values {
 		if val == nil {
 			continue
 		}
 		switch v := val.(type) {
 		case mathexp.Series:
-			num, err := v.Resample(gr.refID, gr.Window, gr.Downsampler, gr.Upsampler, timeRange.From, timeRange.To)
+			num, err := v.Resample(gr.refID, gr.Window, gr.Downsampler, gr.Upsampler, from, timeRange.To)
 			if err != nil {
 				return newRes, err
 			}
@@ -343,6 +369,10 @@ const (
 	TypeThreshold
 	// TypeSQL is the CMDType for running SQL expressions
 	TypeSQL
+	// TypeAnomaly is the CMDType for detecting anomalies in time series
+	TypeAnomaly
+	// TypeForecast is the CMDType for projecting time series into the future
+	TypeForecast
 )
 
 func (gt CommandType) String() string {
@@ -359,6 +389,10 @@ func (gt CommandType) String() string {
 		return "threshold"
 	case TypeSQL:
 		return "sql"
+	case TypeAnomaly:
+		return "anomaly"
+	case TypeForecast:
+		return "forecast"
 	default:
 		return "unknown"
 	}
@@ -379,6 +413,10 @@ func ParseCommandType(s string) (CommandType, error) {
 		return TypeThreshold, nil
 	case "sql":
 		return TypeSQL, nil
+	case "anomaly":
+		return TypeAnomaly, nil
+	case "forecast":
+		return TypeForecast, nil
 	default:
 		return TypeUnknown, fmt.Errorf("'%v' is not a recognized expression type", s)
 	}
@@ -221,9 +221,12 @@ func TestResampleCommand_Execute(t *testing.T) {
 		From: -10 * time.Second,
 		To:   0,
 	}
-	cmd, err := NewResampleCommand(util.GenerateShortUID(), "1s", varToReduce, "sum", "pad", tr)
+	cmd, err := NewResampleCommand(util.GenerateShortUID(), "1s", varToReduce, "sum", "pad", "", tr)
 	require.NoError(t, err)
 
+	_, err = NewResampleCommand(util.GenerateShortUID(), "1s", varToReduce, "median", "pad", "", tr)
+	require.ErrorContains(t, err, "resample downsampler median not implemented")
+
 	var tests = []struct {
 		name         string
 		vals         mathexp.Value
@@ -0,0 +1,205 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+// Method used to forecast values
+// +enum
+type ForecastMethod string
+
+const (
+	// Least squares linear regression, like predict_linear in Prometheus
+	ForecastLinear ForecastMethod = "linear"
+
+	// Double exponential smoothing of the level and the trend, like holt_winters in Prometheus
+	ForecastHoltWinters ForecastMethod = "holt_winters"
+)
+
+const (
+	defaultForecastSmoothing = 0.5
+	defaultForecastTrend     = 0.5
+)
+
+var supportedForecastMethods = []string{
+	string(ForecastLinear),
+	string(ForecastHoltWinters),
+}
+
+// ForecastCommand is an expression command that projects each time series into the future and reduces it to the value
+// expected at the time of its latest point plus the horizon.
+type ForecastCommand struct {
+	ReferenceVar string
+	RefID        string
+	Method       ForecastMethod
+	Horizon      time.Duration
+	// Smoothing and Trend are the smoothing factors of the level and of the trend used by the holt_winters method.
+	Smoothing float64
+	Trend     float64
+}
+
+// NewForecastCommand creates a new ForecastCommand. Zero smoothing factors fall back to the defaults.
+func NewForecastCommand(refID, referenceVar string, method ForecastMethod, rawHorizon string, smoothing, trend float64) (*ForecastCommand, error) {
+	horizon, err := gtime.ParseDuration(rawHorizon)
+	if err != nil {
+		return nil, fmt.Errorf(`failed to parse forecast "horizon" duration field %q: %w`, rawHorizon, err)
+	}
+	if horizon < 0 {
+		return nil, fmt.Errorf("forecast horizon must not be negative, got %v", horizon)
+	}
+
+	switch method {
+	case ForecastLinear:
+	case ForecastHoltWinters:
+		if smoothing == 0 {
+			smoothing = defaultForecastSmoothing
+		}
+		if trend == 0 {
+			trend = defaultForecastTrend
+		}
+		if smoothing <= 0 || smoothing >= 1 {
+			return nil, fmt.Errorf("forecast smoothing factor must be between 0 and 1, got %v", smoothing)
+		}
+		if trend <= 0 || trend >= 1 {
+			return nil, fmt.Errorf("forecast trend factor must be between 0 and 1, got %v", trend)
+		}
+	default:
+		return nil, fmt.Errorf("expected forecast method to be one of [%s], got %s", strings.Join(supportedForecastMethods, ", "), method)
+	}
+
+	return &ForecastCommand{
+		ReferenceVar: referenceVar,
+		RefID:        refID,
+		Method:       method,
+		Horizon:      horizon,
+		Smoothing:    smoothing,
+		Trend:        trend,
+	}, nil
+}
+
+// UnmarshalForecastCommand creates a ForecastCommand from Grafana's frontend query.
+func UnmarshalForecastCommand(rn *rawNode) (*ForecastCommand, error) {
+	q := ForecastQuery{}
+	if err := json.Unmarshal(rn.QueryRaw, &q); err != nil {
+		return nil, fmt.Errorf("failed to parse the forecast command: %w", err)
+	}
+	referenceVar, err := getReferenceVar(q.Expression, rn.RefID)
+	if err != nil {
+		return nil, err
+	}
+	return NewForecastCommand(rn.RefID, referenceVar, q.Method, q.Horizon, q.Smoothing, q.Trend)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (fc *ForecastCommand) NeedsVars() []string {
+	return []string{fc.ReferenceVar}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (fc *ForecastCommand) Execute(ctx context.Context, _ time.Time, vars mathexp.Vars, tracer tracing.Tracer) (mathexp.Results, error) {
+	_, span := tracer.Start(ctx, "SSE.ExecuteForecast")
+	defer span.End()
+	span.SetAttributes(attribute.String("method", string(fc.Method)), attribute.String("horizon", fc.Horizon.String()))
+
+	newRes := mathexp.Results{}
+	for _, val := range vars[fc.ReferenceVar].Values {
+		switch v := val.(type) {
+		case mathexp.Series:
+			n := mathexp.NewNumber(fc.RefID, v.GetLabels())
+			n.SetValue(fc.forecast(v))
+			newRes.Values = append(newRes.Values, n)
+		case mathexp.NoData:
+			newRes.Values = append(newRes.Values, v.New())
+		default:
+			return newRes, fmt.Errorf("can only forecast type series, got type %v", val.Type())
+		}
+	}
+	return newRes, nil
+}
+
+func (fc *ForecastCommand) Type() string {
+	return TypeForecast.String()
+}
+
+// forecast returns the value expected at the time of the latest point of s plus the horizon.
+// Null and NaN points are ignored. If less than two points are left, nil is returned.
+func (fc *ForecastCommand) forecast(s mathexp.Series) *float64 {
+	times := make([]time.Time, 0, s.Len())
+	values := make([]float64, 0, s.Len())
+	for i := 0; i < s.Len(); i++ {
+		t, f := s.GetPoint(i)
+		if f == nil || math.IsNaN(*f) {
+			continue
+		}
+		times = append(times, t)
+		values = append(values, *f)
+	}
+	if len(values) < 2 {
+		return nil
+	}
+
+	var f float64
+	switch fc.Method {
+	case ForecastHoltWinters:
+		f = holtWinters(times, values, fc.Smoothing, fc.Trend, fc.Horizon)
+	default:
+		f = predictLinear(times, values, fc.Horizon)
+	}
+	if math.IsNaN(f) {
+		return nil
+	}
+	return &f
+}
+
+// predictLinear fits a line to the points with least squares and returns its value at the time of the last point plus horizon.
+func predictLinear(times []time.Time, values []float64, horizon time.Duration) float64 {
+	last := times[len(times)-1]
+	var sumX, sumY, sumXY, sumX2 float64
+	for i, t := range times {
+		x := t.Sub(last).Seconds()
+		sumX += x
+		sumY += values[i]
+		sumXY += x * values[i]
+		sumX2 += x * x
+	}
+	n := float64(len(values))
+	covXY := sumXY - sumX*sumY/n
+	varX := sumX2 - sumX*sumX/n
+	if varX == 0 {
+		return math.NaN()
+	}
+	slope := covXY / varX
+	intercept := sumY/n - slope*sumX/n
+	return intercept + slope*horizon.Seconds()
+}
+
+// holtWinters smooths the level and the trend of the points and extrapolates the trend to the time of the last
+// point plus horizon. The trend is per point, so the horizon is converted to points using the mean interval between them.
+func holtWinters(times []time.Time, values []float64, smoothing, trend float64, horizon time.Duration) float64 {
+	level := values[0]
+	slope := values[1] - values[0]
+	for i := 1; i < len(values); i++ {
+		prevLevel := level
+		level = smoothing*values[i] + (1-smoothing)*(level+slope)
+		slope = trend*(level-prevLevel) + (1-trend)*slope
+	}
+	interval := times[len(times)-1].Sub(times[0]) / time.Duration(len(times)-1)
+	if interval <= 0 {
+		return math.NaN()
+	}
+	steps := float64(horizon) / float64(interval)
+	return level + slope*steps
+}
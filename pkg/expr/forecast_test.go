@@ -0,0 +1,145 @@
+package expr
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func TestNewForecastCommand(t *testing.T) {
+	cases := []struct {
+		name          string
+		method        ForecastMethod
+		horizon       string
+		smoothing     float64
+		trend         float64
+		expectedError string
+	}{
+		{name: "linear", method: ForecastLinear, horizon: "4h"},
+		{name: "holt_winters with defaults", method: ForecastHoltWinters, horizon: "1d"},
+		{name: "holt_winters with factors", method: ForecastHoltWinters, horizon: "1d", smoothing: 0.2, trend: 0.8},
+		{name: "unknown method", method: "arima", horizon: "4h", expectedError: "expected forecast method to be one of"},
+		{name: "invalid horizon", method: ForecastLinear, horizon: "soon", expectedError: "failed to parse forecast"},
+		{name: "negative horizon", method: ForecastLinear, horizon: "-1h", expectedError: "must not be negative"},
+		{name: "smoothing out of range", method: ForecastHoltWinters, horizon: "1h", smoothing: 1.5, expectedError: "smoothing factor must be between 0 and 1"},
+		{name: "trend out of range", method: ForecastHoltWinters, horizon: "1h", trend: -0.1, expectedError: "trend factor must be between 0 and 1"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cmd, err := NewForecastCommand("B", "A", tc.method, tc.horizon, tc.smoothing, tc.trend)
+			if tc.expectedError != "" {
+				require.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, []string{"A"}, cmd.NeedsVars())
+		})
+	}
+}
+
+func TestUnmarshalForecastCommand(t *testing.T) {
+	rn := &rawNode{
+		RefID:    "B",
+		QueryRaw: []byte(`{"type":"forecast","expression":"$A","method":"holt_winters","horizon":"4h","smoothing":0.3}`),
+	}
+	cmd, err := UnmarshalForecastCommand(rn)
+	require.NoError(t, err)
+	require.Equal(t, &ForecastCommand{
+		ReferenceVar: "A",
+		RefID:        "B",
+		Method:       ForecastHoltWinters,
+		Horizon:      4 * time.Hour,
+		Smoothing:    0.3,
+		Trend:        defaultForecastTrend,
+	}, cmd)
+}
+
+func TestForecastCommandExecute(t *testing.T) {
+	// a disk that fills by 10 every minute
+	series := mathexp.NewSeries("A", data.Labels{"mountpoint": "/"}, 0)
+	for i := 0; i < 10; i++ {
+		series.AppendPoint(time.Unix(int64(i*60), 0), util.Pointer(float64(i*10)))
+	}
+	series.AppendPoint(time.Unix(600, 0), nil)
+
+	cases := []struct {
+		name     string
+		method   ForecastMethod
+		vars     mathexp.Values
+		expected mathexp.Values
+	}{
+		{
+			name:   "linear extrapolates the trend",
+			method: ForecastLinear,
+			vars:   mathexp.Values{series},
+			expected: mathexp.Values{
+				func() mathexp.Number {
+					n := mathexp.NewNumber("B", data.Labels{"mountpoint": "/"})
+					n.SetValue(util.Pointer(float64(90 + 600)))
+					return n
+				}(),
+			},
+		},
+		{
+			name:   "holt_winters extrapolates the trend",
+			method: ForecastHoltWinters,
+			vars:   mathexp.Values{series},
+			expected: mathexp.Values{
+				func() mathexp.Number {
+					n := mathexp.NewNumber("B", data.Labels{"mountpoint": "/"})
+					n.SetValue(util.Pointer(float64(90 + 600)))
+					return n
+				}(),
+			},
+		},
+		{
+			name:   "series with a single point forecasts nothing",
+			method: ForecastLinear,
+			vars: mathexp.Values{func() mathexp.Series {
+				s := mathexp.NewSeries("A", nil, 0)
+				s.AppendPoint(time.Unix(0, 0), util.Pointer(1.0))
+				return s
+			}()},
+			expected: mathexp.Values{mathexp.NewNumber("B", nil)},
+		},
+		{
+			name:     "no data is passed through",
+			method:   ForecastLinear,
+			vars:     mathexp.Values{mathexp.NewNoData()},
+			expected: mathexp.Values{mathexp.NewNoData()},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cmd, err := NewForecastCommand("B", "A", tc.method, "1h", 0, 0)
+			require.NoError(t, err)
+
+			res, err := cmd.Execute(context.Background(), time.Now(), mathexp.Vars{"A": mathexp.Results{Values: tc.vars}}, tracing.InitializeTracerForTest())
+			require.NoError(t, err)
+			require.Len(t, res.Values, len(tc.expected))
+			for i, v := range res.Values {
+				if n, ok := v.(mathexp.Number); ok {
+					expected := tc.expected[i].(mathexp.Number).GetFloat64Value()
+					actual := n.GetFloat64Value()
+					if expected == nil {
+						require.Nil(t, actual)
+						continue
+					}
+					require.InDelta(t, *expected, *actual, 1e-9)
+					require.Equal(t, tc.expected[i].GetLabels(), n.GetLabels())
+					continue
+				}
+				require.Equal(t, tc.expected[i], v)
+			}
+		})
+	}
+}
@@ -62,6 +62,7 @@ type DataPipeline []Node
 // map of the refId of the of each command
 func (dp *DataPipeline) execute(c context.Context, now time.Time, s *Service) (mathexp.Vars, error) {
 	vars := make(mathexp.Vars)
+	trace := TraceFromContext(c)
 
 	groupByDSFlag := s.features.IsEnabled(c, featuremgmt.FlagSseGroupByDatasource)
 	// Execute datasource nodes first, and grouped by datasource.
@@ -93,6 +94,8 @@ func (dp *DataPipeline) execute(c context.Context, now time.Time, s *Service) (m
 						Error: MakeDependencyError(node.RefID(), neededVar),
 					}
 					vars[node.RefID()] = errResult
+					trace.startNode(node, vars, time.Now())
+					trace.endNode(node.RefID(), errResult, time.Now())
 					hasDepError = true
 					break
 				}
@@ -115,12 +118,14 @@ func (dp *DataPipeline) execute(c context.Context, now time.Time, s *Service) (m
 			return vars, makeUnexpectedNodeTypeError(node.RefID(), node.NodeType().String())
 		}
 
+		trace.startNode(node, vars, time.Now())
 		res, err := execNode.Execute(c, now, vars, s)
 		if err != nil {
 			res.Error = err
 		}
 
 		vars[node.RefID()] = res
+		trace.endNode(node.RefID(), res, time.Now())
 	}
 	return vars, nil
 }
@@ -0,0 +1,244 @@
+package mathexp
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
+)
+
+// sumBy returns the sum of the values in each group of values with the same given labels.
+func sumBy(e *State, varSet Results, labels ...string) (Results, error) {
+	return aggregateBy(e, "sum_by", varSet, labels, func(values []float64) *float64 {
+		if len(values) == 0 {
+			return nil
+		}
+		var sum float64
+		for _, v := range values {
+			sum += v
+		}
+		return &sum
+	})
+}
+
+// avgBy returns the mean of the values in each group of values with the same given labels.
+func avgBy(e *State, varSet Results, labels ...string) (Results, error) {
+	return aggregateBy(e, "avg_by", varSet, labels, func(values []float64) *float64 {
+		if len(values) == 0 {
+			return nil
+		}
+		var sum float64
+		for _, v := range values {
+			sum += v
+		}
+		avg := sum / float64(len(values))
+		return &avg
+	})
+}
+
+// maxBy returns the largest value in each group of values with the same given labels.
+func maxBy(e *State, varSet Results, labels ...string) (Results, error) {
+	return aggregateBy(e, "max_by", varSet, labels, func(values []float64) *float64 {
+		if len(values) == 0 {
+			return nil
+		}
+		m := values[0]
+		for _, v := range values[1:] {
+			if v > m || math.IsNaN(m) {
+				m = v
+			}
+		}
+		return &m
+	})
+}
+
+// countBy returns the number of non-null values in each group of values with the same given labels.
+func countBy(e *State, varSet Results, labels ...string) (Results, error) {
+	return aggregateBy(e, "count_by", varSet, labels, func(values []float64) *float64 {
+		c := float64(len(values))
+		return &c
+	})
+}
+
+// aggregateBy groups the series or numbers of varSet by the given labels and reduces each group to a single
+// series or number with aggF, which gets the non-null values of the group. The result only has the given labels.
+// Without labels, all values are reduced to one. Series are reduced point by point over the times of all series
+// in the group. Scalars and no data are passed through.
+func aggregateBy(e *State, name string, varSet Results, labels []string, aggF func([]float64) *float64) (Results, error) {
+	newRes := Results{}
+	if varSet.IsNoData() {
+		newRes.Values = append(newRes.Values, NewNoData())
+		return newRes, nil
+	}
+
+	groups := []data.Labels{}
+	members := map[string][]Value{}
+	valueType := varSet.Values[0].Type()
+	for _, val := range varSet.Values {
+		switch val.(type) {
+		case Scalar:
+			newRes.Values = append(newRes.Values, val)
+			continue
+		case Series, Number:
+		default:
+			return newRes, fmt.Errorf("%s: unsupported type %v", name, val.Type())
+		}
+		if val.Type() != valueType {
+			return newRes, fmt.Errorf("%s: can not aggregate %v and %v together", name, valueType, val.Type())
+		}
+		groupLabels := matchLabels(val.GetLabels(), &parse.LabelMatching{On: true, Labels: labels})
+		key := groupLabels.String()
+		if _, ok := members[key]; !ok {
+			groups = append(groups, groupLabels)
+		}
+		members[key] = append(members[key], val)
+	}
+
+	for _, groupLabels := range groups {
+		group := members[groupLabels.String()]
+		if len(groupLabels) == 0 {
+			groupLabels = nil
+		}
+		if _, ok := group[0].(Number); ok {
+			values := make([]float64, 0, len(group))
+			for _, v := range group {
+				if f := v.(Number).GetFloat64Value(); f != nil {
+					values = append(values, *f)
+				}
+			}
+			n := NewNumber(e.RefID, groupLabels)
+			n.SetValue(aggF(values))
+			newRes.Values = append(newRes.Values, n)
+			continue
+		}
+
+		points := map[time.Time][]float64{}
+		times := []time.Time{}
+		for _, v := range group {
+			s := v.(Series)
+			for i := 0; i < s.Len(); i++ {
+				t, f := s.GetPoint(i)
+				t = t.UTC()
+				values, ok := points[t]
+				if !ok {
+					times = append(times, t)
+					values = []float64{}
+				}
+				if f != nil {
+					values = append(values, *f)
+				}
+				points[t] = values
+			}
+		}
+		sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+		newSeries := NewSeries(e.RefID, groupLabels, len(times))
+		for i, t := range times {
+			newSeries.SetPoint(i, t, aggF(points[t]))
+		}
+		newRes.Values = append(newRes.Values, newSeries)
+	}
+	return newRes, nil
+}
+
+// topk returns the n numbers with the largest values. For series, it returns for each time the points of the
+// n series with the largest values at that time, series without any of those points are dropped.
+func topk(e *State, varSet Results, nRes Results) (Results, error) {
+	return selectK(e, "topk", varSet, nRes, func(a, b float64) bool { return a > b })
+}
+
+// bottomk returns the n numbers with the smallest values. For series, it returns for each time the points of the
+// n series with the smallest values at that time, series without any of those points are dropped.
+func bottomk(e *State, varSet Results, nRes Results) (Results, error) {
+	return selectK(e, "bottomk", varSet, nRes, func(a, b float64) bool { return a < b })
+}
+
+// selectK keeps the n values that come first when ordered by less. Null and NaN values are never selected.
+// Ties keep the order of varSet.
+func selectK(e *State, name string, varSet Results, nRes Results, less func(a, b float64) bool) (Results, error) {
+	nF, err := scalarArg(name, nRes)
+	if err != nil {
+		return Results{}, err
+	}
+	if nF < 1 || nF != math.Trunc(nF) {
+		return Results{}, fmt.Errorf("%s: expected a positive integer, got %v", name, nF)
+	}
+	n := int(nF)
+
+	newRes := Results{}
+	if varSet.IsNoData() {
+		newRes.Values = append(newRes.Values, NewNoData())
+		return newRes, nil
+	}
+
+	type candidate struct {
+		idx   int
+		value float64
+	}
+	top := func(candidates []candidate) []candidate {
+		sort.SliceStable(candidates, func(i, j int) bool { return less(candidates[i].value, candidates[j].value) })
+		return candidates[:min(n, len(candidates))]
+	}
+
+	switch varSet.Values[0].(type) {
+	case Number:
+		candidates := []candidate{}
+		for i, val := range varSet.Values {
+			num, ok := val.(Number)
+			if !ok {
+				return newRes, fmt.Errorf("%s: can not select %v and %v together", name, parse.TypeNumberSet, val.Type())
+			}
+			if f := num.GetFloat64Value(); f != nil && !math.IsNaN(*f) {
+				candidates = append(candidates, candidate{i, *f})
+			}
+		}
+		for _, c := range top(candidates) {
+			src := varSet.Values[c.idx].(Number)
+			num := NewNumber(e.RefID, src.GetLabels())
+			num.SetValue(src.GetFloat64Value())
+			newRes.Values = append(newRes.Values, num)
+		}
+		return newRes, nil
+	case Series:
+		selected := make([]map[time.Time]bool, len(varSet.Values))
+		byTime := map[time.Time][]candidate{}
+		for i, val := range varSet.Values {
+			s, ok := val.(Series)
+			if !ok {
+				return newRes, fmt.Errorf("%s: can not select %v and %v together", name, parse.TypeSeriesSet, val.Type())
+			}
+			selected[i] = map[time.Time]bool{}
+			for j := 0; j < s.Len(); j++ {
+				t, f := s.GetPoint(j)
+				if f != nil && !math.IsNaN(*f) {
+					byTime[t.UTC()] = append(byTime[t.UTC()], candidate{i, *f})
+				}
+			}
+		}
+		for t, candidates := range byTime {
+			for _, c := range top(candidates) {
+				selected[c.idx][t] = true
+			}
+		}
+		for i, val := range varSet.Values {
+			if len(selected[i]) == 0 {
+				continue
+			}
+			s := val.(Series)
+			newSeries := NewSeries(e.RefID, s.GetLabels(), 0)
+			for j := 0; j < s.Len(); j++ {
+				t, f := s.GetPoint(j)
+				if selected[i][t.UTC()] {
+					newSeries.AppendPoint(t, f)
+				}
+			}
+			newRes.Values = append(newRes.Values, newSeries)
+		}
+		return newRes, nil
+	default:
+		return Results{Values: varSet.Values}, nil
+	}
+}
@@ -0,0 +1,137 @@
+package mathexp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+func TestAggregateByFuncs(t *testing.T) {
+	vars := Vars{
+		"N": resultValuesNoErr(
+			makeNumber("", data.Labels{"dc": "east", "host": "a"}, float64Pointer(1)),
+			makeNumber("", data.Labels{"dc": "east", "host": "b"}, float64Pointer(3)),
+			makeNumber("", data.Labels{"dc": "west", "host": "c"}, float64Pointer(10)),
+			makeNumber("", data.Labels{"dc": "west", "host": "d"}, nil),
+		),
+		"S": resultValuesNoErr(
+			makeSeries("", data.Labels{"dc": "east", "host": "a"},
+				tp{time.Unix(0, 0), float64Pointer(1)},
+				tp{time.Unix(10, 0), float64Pointer(2)},
+			),
+			makeSeries("", data.Labels{"dc": "east", "host": "b"},
+				tp{time.Unix(10, 0), float64Pointer(5)},
+				tp{time.Unix(20, 0), float64Pointer(6)},
+			),
+		),
+	}
+	var tests = []struct {
+		name     string
+		expr     string
+		newErrIs require.ErrorAssertionFunc
+		results  Results
+	}{
+		{
+			name:     "sum_by on numbers",
+			expr:     `sum_by($N, "dc")`,
+			newErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeNumber("", data.Labels{"dc": "east"}, float64Pointer(4)),
+				makeNumber("", data.Labels{"dc": "west"}, float64Pointer(10)),
+			),
+		},
+		{
+			name:     "avg_by on numbers ignores nulls",
+			expr:     `avg_by($N, "dc")`,
+			newErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeNumber("", data.Labels{"dc": "east"}, float64Pointer(2)),
+				makeNumber("", data.Labels{"dc": "west"}, float64Pointer(10)),
+			),
+		},
+		{
+			name:     "count_by without labels counts all non-null values",
+			expr:     `count_by($N)`,
+			newErrIs: require.NoError,
+			results:  resultValuesNoErr(makeNumber("", nil, float64Pointer(3))),
+		},
+		{
+			name:     "max_by on series is computed for each time",
+			expr:     `max_by($S, "dc")`,
+			newErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeSeries("", data.Labels{"dc": "east"},
+					tp{time.Unix(0, 0).UTC(), float64Pointer(1)},
+					tp{time.Unix(10, 0).UTC(), float64Pointer(5)},
+					tp{time.Unix(20, 0).UTC(), float64Pointer(6)},
+				),
+			),
+		},
+		{
+			name:     "sum_by on series",
+			expr:     `sum_by($S, "dc", "missing")`,
+			newErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeSeries("", data.Labels{"dc": "east"},
+					tp{time.Unix(0, 0).UTC(), float64Pointer(1)},
+					tp{time.Unix(10, 0).UTC(), float64Pointer(7)},
+					tp{time.Unix(20, 0).UTC(), float64Pointer(6)},
+				),
+			),
+		},
+		{
+			name:     "topk on numbers skips nulls",
+			expr:     `topk($N, 2)`,
+			newErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeNumber("", data.Labels{"dc": "west", "host": "c"}, float64Pointer(10)),
+				makeNumber("", data.Labels{"dc": "east", "host": "b"}, float64Pointer(3)),
+			),
+		},
+		{
+			name:     "bottomk on series keeps the selected points of each series",
+			expr:     `bottomk($S, 1)`,
+			newErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeSeries("", data.Labels{"dc": "east", "host": "a"},
+					tp{time.Unix(0, 0), float64Pointer(1)},
+					tp{time.Unix(10, 0), float64Pointer(2)},
+				),
+				makeSeries("", data.Labels{"dc": "east", "host": "b"},
+					tp{time.Unix(20, 0), float64Pointer(6)},
+				),
+			),
+		},
+		{
+			name:     "topk with zero should error",
+			expr:     `topk($N, 0)`,
+			newErrIs: require.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			tt.newErrIs(t, err)
+			if e != nil {
+				res, err := e.Execute("", vars, tracing.InitializeTracerForTest())
+				require.NoError(t, err)
+				require.Equal(t, tt.results, res)
+			}
+		})
+	}
+
+	t.Run("mixing numbers and series should error", func(t *testing.T) {
+		e, err := New(`sum_by($A, "dc")`)
+		require.NoError(t, err)
+		mixed := Vars{"A": resultValuesNoErr(
+			makeNumber("", data.Labels{"dc": "east"}, float64Pointer(1)),
+			makeSeries("", data.Labels{"dc": "east"}, tp{time.Unix(0, 0), float64Pointer(1)}),
+		)}
+		_, err = e.Execute("", mixed, tracing.InitializeTracerForTest())
+		require.Error(t, err)
+	})
+}
@@ -51,6 +51,13 @@ func New(expr string, funcs ...map[string]parse.Func) (*Expr, error) {
 
 // Execute applies a parse expression to the context and executes it
 func (e *Expr) Execute(refID string, vars Vars, tracer tracing.Tracer) (r Results, err error) {
+	r, _, err = e.ExecuteWithDrops(refID, vars, tracer)
+	return r, err
+}
+
+// ExecuteWithDrops is like Execute but also returns the labels of the values that were dropped
+// from unions, by the text of the binary node and the side of the node they were on.
+func (e *Expr) ExecuteWithDrops(refID string, vars Vars, tracer tracing.Tracer) (r Results, drops map[string]map[string][]data.Labels, err error) {
 	s := &State{
 		Expr:  e,
 		Vars:  vars,
@@ -58,7 +65,8 @@ func (e *Expr) Execute(refID string, vars Vars, tracer tracing.Tracer) (r Result
 
 		tracer: tracer,
 	}
-	return e.executeState(s)
+	r, err = e.executeState(s)
+	return r, s.Drops, err
 }
 
 func (e *Expr) executeState(s *State) (r Results, err error) {
@@ -208,34 +216,10 @@ func (e *State) union(aResults, bResults Results, biNode *parse.BinaryNode) []*U
 		unions = append(unions, u)
 	}
 
-	aVar := biNode.Args[0].String()
-	bVar := biNode.Args[1].String()
-
 	aMatched := make([]bool, len(aResults.Values))
 	bMatched := make([]bool, len(bResults.Values))
 	collectDrops := func() {
-		check := func(v string, matchArray []bool, r *Results) {
-			for i, b := range matchArray {
-				if b {
-					continue
-				}
-				if e.Drops == nil {
-					e.Drops = make(map[string]map[string][]data.Labels)
-				}
-				if e.Drops[biNode.String()] == nil {
-					e.Drops[biNode.String()] = make(map[string][]data.Labels)
-				}
-
-				if r.Values[i].Type() == parse.TypeNoData {
-					continue
-				}
-
-				e.DropCount++
-				e.Drops[biNode.String()][v] = append(e.Drops[biNode.String()][v], r.Values[i].GetLabels())
-			}
-		}
-		check(aVar, aMatched, &aResults)
-		check(bVar, bMatched, &bResults)
+		e.collectDrops(biNode, aResults, bResults, aMatched, bMatched)
 	}
 
 	aValueLen := len(aResults.Values)
@@ -260,23 +244,8 @@ func (e *State) union(aResults, bResults Results, biNode *parse.BinaryNode) []*U
 
 	for iA, a := range aResults.Values {
 		for iB, b := range bResults.Values {
-			var labels data.Labels
-			aLabels := a.GetLabels()
-			bLabels := b.GetLabels()
-			switch {
-			case aLabels.Equals(bLabels) || len(aLabels) == 0 || len(bLabels) == 0:
-				l := aLabels
-				if len(aLabels) == 0 {
-					l = bLabels
-				}
-				labels = l
-			case len(aLabels) == len(bLabels):
-				continue // invalid union, drop for now
-			case aLabels.Contains(bLabels):
-				labels = aLabels
-			case bLabels.Contains(aLabels):
-				labels = bLabels
-			default:
+			labels, ok := unionLabels(a.GetLabels(), b.GetLabels())
+			if !ok {
 				continue
 			}
 			u := &Union{
@@ -306,6 +275,145 @@ func (e *State) union(aResults, bResults Results, biNode *parse.BinaryNode) []*U
 	return unions
 }
 
+// unionLabels returns the labels of the union of two values, which are the labels of the value with more labels
+// if the labels of the other value are a subset of them. It returns false if the values can not be joined.
+func unionLabels(aLabels, bLabels data.Labels) (data.Labels, bool) {
+	switch {
+	case aLabels.Equals(bLabels) || len(aLabels) == 0 || len(bLabels) == 0:
+		if len(aLabels) == 0 {
+			return bLabels, true
+		}
+		return aLabels, true
+	case len(aLabels) == len(bLabels):
+		return nil, false // invalid union, drop for now
+	case aLabels.Contains(bLabels):
+		return aLabels, true
+	case bLabels.Contains(aLabels):
+		return bLabels, true
+	default:
+		return nil, false
+	}
+}
+
+// collectDrops records the values of either side of a binary operation that are not part of any union.
+func (e *State) collectDrops(biNode *parse.BinaryNode, aResults, bResults Results, aMatched, bMatched []bool) {
+	check := func(v string, matchArray []bool, r *Results) {
+		for i, b := range matchArray {
+			if b {
+				continue
+			}
+			if e.Drops == nil {
+				e.Drops = make(map[string]map[string][]data.Labels)
+			}
+			if e.Drops[biNode.String()] == nil {
+				e.Drops[biNode.String()] = make(map[string][]data.Labels)
+			}
+
+			if r.Values[i].Type() == parse.TypeNoData {
+				continue
+			}
+
+			e.DropCount++
+			e.Drops[biNode.String()][v] = append(e.Drops[biNode.String()][v], r.Values[i].GetLabels())
+		}
+	}
+	check(biNode.Args[0].String(), aMatched, &aResults)
+	check(biNode.Args[1].String(), bMatched, &bResults)
+}
+
+// matchUnion creates Union objects for a binary operation with an on(...) or ignoring(...) label matching.
+// Values are paired when their labels are equal once only the labels in on(...) are kept, or the labels in
+// ignoring(...) are dropped. When a single value on each side matches, the union has the compared labels.
+// When several values on one side match a single value on the other, each union has the labels of the value
+// on the side with several values. Several values matching on both sides is an error. Operations with scalars
+// or no data ignore the matching.
+func (e *State) matchUnion(aResults, bResults Results, biNode *parse.BinaryNode) ([]*Union, error) {
+	if !labeledValues(aResults) || !labeledValues(bResults) {
+		return e.union(aResults, bResults, biNode), nil
+	}
+
+	m := biNode.Matching
+	aSignatures := make([]string, len(aResults.Values))
+	aBySignature := map[string][]int{}
+	for i, a := range aResults.Values {
+		aSignatures[i] = matchLabels(a.GetLabels(), m).String()
+		aBySignature[aSignatures[i]] = append(aBySignature[aSignatures[i]], i)
+	}
+	bBySignature := map[string][]int{}
+	for i, b := range bResults.Values {
+		sig := matchLabels(b.GetLabels(), m).String()
+		bBySignature[sig] = append(bBySignature[sig], i)
+	}
+
+	unions := []*Union{}
+	aMatched := make([]bool, len(aResults.Values))
+	bMatched := make([]bool, len(bResults.Values))
+	for iA, a := range aResults.Values {
+		sig := aSignatures[iA]
+		as, bs := aBySignature[sig], bBySignature[sig]
+		if len(bs) == 0 {
+			continue
+		}
+		if len(as) > 1 && len(bs) > 1 {
+			return nil, fmt.Errorf("many-to-many matching is not supported in %s: %v values on the left and %v values on the right match {%s}", biNode, len(as), len(bs), sig)
+		}
+		for _, iB := range bs {
+			b := bResults.Values[iB]
+			var labels data.Labels
+			switch {
+			case len(as) > 1:
+				labels = a.GetLabels()
+			case len(bs) > 1:
+				labels = b.GetLabels()
+			default:
+				labels = matchLabels(a.GetLabels(), m)
+			}
+			unions = append(unions, &Union{
+				Labels: labels,
+				A:      a,
+				B:      b,
+			})
+			aMatched[iA] = true
+			bMatched[iB] = true
+		}
+	}
+
+	e.collectDrops(biNode, aResults, bResults, aMatched, bMatched)
+	return unions, nil
+}
+
+// labeledValues is true if all values of r are series or numbers.
+func labeledValues(r Results) bool {
+	for _, v := range r.Values {
+		switch v.(type) {
+		case Series, Number:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// matchLabels returns a copy of labels that only has the labels compared by the label matching m.
+func matchLabels(labels data.Labels, m *parse.LabelMatching) data.Labels {
+	matched := data.Labels{}
+	if m.On {
+		for _, k := range m.Labels {
+			if v, ok := labels[k]; ok {
+				matched[k] = v
+			}
+		}
+		return matched
+	}
+	for k, v := range labels {
+		matched[k] = v
+	}
+	for _, k := range m.Labels {
+		delete(matched, k)
+	}
+	return matched
+}
+
 func (e *State) walkBinary(node *parse.BinaryNode) (Results, error) {
 	res := Results{Values: Values{}}
 	ar, err := e.walk(node.Args[0])
@@ -316,7 +424,15 @@ func (e *State) walkBinary(node *parse.BinaryNode) (Results, error) {
 	if err != nil {
 		return res, err
 	}
-	unions := e.union(ar, br, node)
+	var unions []*Union
+	if node.Matching != nil {
+		unions, err = e.matchUnion(ar, br, node)
+		if err != nil {
+			return res, err
+		}
+	} else {
+		unions = e.union(ar, br, node)
+	}
 	for _, uni := range unions {
 		var value Value
 		switch at := uni.A.(type) {
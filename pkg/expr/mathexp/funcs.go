@@ -1,7 +1,12 @@
 package mathexp
 
 import (
+	"fmt"
 	"math"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 
 	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
 )
@@ -68,6 +73,99 @@ var builtins = map[string]parse.Func{
 		VariantReturn: true,
 		F:             floor,
 	},
+	"clamp": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeScalar, parse.TypeScalar},
+		VariantReturn: true,
+		F:             clamp,
+	},
+	"shift": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeString},
+		Return: parse.TypeSeriesSet,
+		F:      shift,
+		Check:  checkDurationArg(1),
+	},
+	"rate": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      rate,
+	},
+	"delta": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      delta,
+	},
+	"cumsum": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      cumsum,
+	},
+	"moving_avg": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeScalar},
+		Return: parse.TypeSeriesSet,
+		F:      movingAvg,
+		Check:  checkPositiveIntArg(1),
+	},
+	"if": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeVariantSet, parse.TypeVariantSet},
+		VariantReturn: true,
+		F:             ifFunc,
+	},
+	"label_replace": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeString, parse.TypeString, parse.TypeString, parse.TypeString},
+		VariantReturn: true,
+		F:             labelReplace,
+		Check:         checkLabelReplace,
+	},
+	"label_drop": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeString},
+		VariantReturn: true,
+		Variadic:      true,
+		F:             labelDrop,
+		Check:         checkMinArgs(2),
+	},
+	"label_keep": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeString},
+		VariantReturn: true,
+		Variadic:      true,
+		F:             labelKeep,
+		Check:         checkMinArgs(2),
+	},
+	"sum_by": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeString},
+		VariantReturn: true,
+		Variadic:      true,
+		F:             sumBy,
+	},
+	"avg_by": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeString},
+		VariantReturn: true,
+		Variadic:      true,
+		F:             avgBy,
+	},
+	"max_by": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeString},
+		VariantReturn: true,
+		Variadic:      true,
+		F:             maxBy,
+	},
+	"count_by": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeString},
+		VariantReturn: true,
+		Variadic:      true,
+		F:             countBy,
+	},
+	"topk": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeScalar},
+		VariantReturn: true,
+		F:             topk,
+		Check:         checkPositiveIntArg(1),
+	},
+	"bottomk": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeScalar},
+		VariantReturn: true,
+		F:             bottomk,
+		Check:         checkPositiveIntArg(1),
+	},
 }
 
 // abs returns the absolute value for each result in NumberSet, SeriesSet, or Scalar
@@ -307,3 +405,334 @@ func floor(e *State, varSet Results) (Results, error) {
 	}
 	return newRes, nil
 }
+
+// clamp limits the value for each result in NumberSet, SeriesSet, or Scalar to the range [min, max].
+func clamp(e *State, varSet Results, minRes Results, maxRes Results) (Results, error) {
+	newRes := Results{}
+	lo, err := scalarArg("clamp", minRes)
+	if err != nil {
+		return newRes, err
+	}
+	hi, err := scalarArg("clamp", maxRes)
+	if err != nil {
+		return newRes, err
+	}
+	if lo > hi {
+		return newRes, fmt.Errorf("clamp: min (%v) must not be greater than max (%v)", lo, hi)
+	}
+	for _, res := range varSet.Values {
+		newVal, err := perFloat(e, res, func(f float64) float64 {
+			if math.IsNaN(f) {
+				return f
+			}
+			return math.Max(lo, math.Min(hi, f))
+		})
+		if err != nil {
+			return newRes, err
+		}
+		newRes.Values = append(newRes.Values, newVal)
+	}
+	return newRes, nil
+}
+
+// shift moves every point of each series in SeriesSet forward in time by the given duration,
+// so that the value observed at t is reported at t + duration. This makes it possible to
+// compare a series with a lagged copy of itself, e.g. $A - shift($A, "1h").
+func shift(e *State, varSet Results, rawDuration string) (Results, error) {
+	d, err := gtime.ParseDuration(rawDuration)
+	if err != nil {
+		return Results{}, fmt.Errorf("shift: failed to parse duration %q: %w", rawDuration, err)
+	}
+	return perSeries(e, "shift", varSet, func(s Series) (Series, error) {
+		newSeries := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		for i := 0; i < s.Len(); i++ {
+			t, f := s.GetPoint(i)
+			newSeries.SetPoint(i, t.Add(d), f)
+		}
+		return newSeries, nil
+	})
+}
+
+// rate returns the per-second rate of increase between consecutive points of each series in SeriesSet.
+// A decrease in value is treated as a counter reset. The first point of each series is dropped.
+func rate(e *State, varSet Results) (Results, error) {
+	return perSeries(e, "rate", varSet, func(s Series) (Series, error) {
+		return pairwise(e, s, func(prevT, t time.Time, prev, cur float64) *float64 {
+			dt := t.Sub(prevT).Seconds()
+			if dt <= 0 {
+				return nil
+			}
+			dv := cur - prev
+			if dv < 0 {
+				dv = cur
+			}
+			r := dv / dt
+			return &r
+		}), nil
+	})
+}
+
+// delta returns the difference between consecutive points of each series in SeriesSet.
+// The first point of each series is dropped.
+func delta(e *State, varSet Results) (Results, error) {
+	return perSeries(e, "delta", varSet, func(s Series) (Series, error) {
+		return pairwise(e, s, func(_, _ time.Time, prev, cur float64) *float64 {
+			d := cur - prev
+			return &d
+		}), nil
+	})
+}
+
+// cumsum returns the running total of each series in SeriesSet. Null points are kept as null
+// and do not contribute to the total.
+func cumsum(e *State, varSet Results) (Results, error) {
+	return perSeries(e, "cumsum", varSet, func(s Series) (Series, error) {
+		newSeries := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		sum := float64(0)
+		for i := 0; i < s.Len(); i++ {
+			t, f := s.GetPoint(i)
+			if f == nil {
+				newSeries.SetPoint(i, t, nil)
+				continue
+			}
+			sum += *f
+			nF := sum
+			newSeries.SetPoint(i, t, &nF)
+		}
+		return newSeries, nil
+	})
+}
+
+// movingAvg returns, for every point of each series in SeriesSet, the mean of the non-null values
+// of that point and the n-1 points before it. If the window holds no values the point is null.
+func movingAvg(e *State, varSet Results, nRes Results) (Results, error) {
+	rawN, err := scalarArg("moving_avg", nRes)
+	if err != nil {
+		return Results{}, err
+	}
+	if rawN < 1 || rawN != math.Trunc(rawN) {
+		return Results{}, fmt.Errorf("moving_avg: window must be a positive integer, got %v", rawN)
+	}
+	n := int(rawN)
+	return perSeries(e, "moving_avg", varSet, func(s Series) (Series, error) {
+		newSeries := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		for i := 0; i < s.Len(); i++ {
+			sum, count := float64(0), 0
+			for j := max(0, i-n+1); j <= i; j++ {
+				if f := s.GetValue(j); f != nil {
+					sum += *f
+					count++
+				}
+			}
+			var nF *float64
+			if count > 0 {
+				avg := sum / float64(count)
+				nF = &avg
+			}
+			newSeries.SetPoint(i, s.GetTime(i), nF)
+		}
+		return newSeries, nil
+	})
+}
+
+// perSeries passes each Series in varSet to seriesF and collects the results. NoData values are
+// passed through, any other non-series value is an error since window functions need points over time.
+func perSeries(e *State, name string, varSet Results, seriesF func(s Series) (Series, error)) (Results, error) {
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		switch v := res.(type) {
+		case Series:
+			newSeries, err := seriesF(v)
+			if err != nil {
+				return newRes, err
+			}
+			newRes.Values = append(newRes.Values, newSeries)
+		case NoData:
+			newRes.Values = append(newRes.Values, NewNoData())
+		default:
+			return newRes, fmt.Errorf("%s: expected %v but got %v", name, parse.TypeSeriesSet, res.Type())
+		}
+	}
+	return newRes, nil
+}
+
+// pairwise builds a series from each pair of consecutive points in s, reporting the result of pairF
+// at the time of the second point. If either value of a pair is null, the resulting point is null.
+func pairwise(e *State, s Series, pairF func(prevT, t time.Time, prev, cur float64) *float64) Series {
+	newSeries := NewSeries(e.RefID, s.GetLabels(), 0)
+	for i := 1; i < s.Len(); i++ {
+		prevT, prev := s.GetPoint(i - 1)
+		t, cur := s.GetPoint(i)
+		if prev == nil || cur == nil {
+			newSeries.AppendPoint(t, nil)
+			continue
+		}
+		newSeries.AppendPoint(t, pairF(prevT, t, *prev, *cur))
+	}
+	return newSeries
+}
+
+// scalarArg returns the value of a scalar function argument.
+func scalarArg(name string, r Results) (float64, error) {
+	if len(r.Values) != 1 {
+		return 0, fmt.Errorf("%s: expected a single scalar argument, got %v values", name, len(r.Values))
+	}
+	s, ok := r.Values[0].(Scalar)
+	if !ok {
+		return 0, fmt.Errorf("%s: expected %v argument but got %v", name, parse.TypeScalar, r.Values[0].Type())
+	}
+	f := s.GetFloat64Value()
+	if f == nil {
+		return 0, fmt.Errorf("%s: scalar argument must not be null", name)
+	}
+	return *f, nil
+}
+
+// checkDurationArg returns a parse time check that the string argument at idx is a valid duration.
+func checkDurationArg(idx int) func(*parse.Tree, *parse.FuncNode) error {
+	return func(t *parse.Tree, f *parse.FuncNode) error {
+		s, ok := f.Args[idx].(*parse.StringNode)
+		if !ok {
+			return nil
+		}
+		if _, err := gtime.ParseDuration(s.Text); err != nil {
+			return fmt.Errorf("parse: invalid duration %s for argument %v of %s: %w", s.Quoted, idx, f.Name, err)
+		}
+		return nil
+	}
+}
+
+// checkMinArgs returns a parse time check that a variadic function got at least n arguments.
+func checkMinArgs(n int) func(*parse.Tree, *parse.FuncNode) error {
+	return func(t *parse.Tree, f *parse.FuncNode) error {
+		if len(f.Args) < n {
+			return fmt.Errorf("parse: not enough arguments for %s, expected at least %v", f.Name, n)
+		}
+		return nil
+	}
+}
+
+// checkPositiveIntArg returns a parse time check that a constant argument at idx is a positive integer.
+// Arguments that are not constants can only be validated at execution time.
+func checkPositiveIntArg(idx int) func(*parse.Tree, *parse.FuncNode) error {
+	return func(t *parse.Tree, f *parse.FuncNode) error {
+		n, ok := f.Args[idx].(*parse.ScalarNode)
+		if !ok {
+			return nil
+		}
+		if !n.IsUint || n.Uint64 == 0 {
+			return fmt.Errorf("parse: expected a positive integer for argument %v of %s, got %s", idx, f.Name, n.Text)
+		}
+		return nil
+	}
+}
+
+// ifFunc returns the value of a where cond is not zero and the value of b where it is zero, for each
+// combination of values of cond, a and b with compatible labels. Labels are joined like in binary operations.
+// Where cond is null or NaN, the result is null. Series are compared point by point, points that are not in
+// all the series are dropped.
+func ifFunc(e *State, cond, a, b Results) (Results, error) {
+	newRes := Results{}
+	for _, r := range []Results{cond, a, b} {
+		if r.IsNoData() {
+			newRes.Values = append(newRes.Values, NewNoData())
+			return newRes, nil
+		}
+	}
+
+	for _, c := range cond.Values {
+		for _, aVal := range a.Values {
+			labels, ok := unionLabels(c.GetLabels(), aVal.GetLabels())
+			if !ok && (len(cond.Values) > 1 || len(a.Values) > 1) {
+				continue
+			}
+			for _, bVal := range b.Values {
+				labels, ok := unionLabels(labels, bVal.GetLabels())
+				if !ok && (len(cond.Values) > 1 || len(a.Values) > 1 || len(b.Values) > 1) {
+					continue
+				}
+				newVal, err := e.ifValue(labels, c, aVal, bVal)
+				if err != nil {
+					return newRes, err
+				}
+				newRes.Values = append(newRes.Values, newVal)
+			}
+		}
+	}
+	return newRes, nil
+}
+
+// ifValue computes the result of if for a single combination of values. The result is a series if any of
+// them is a series, otherwise a number if any of them is a number, otherwise a scalar.
+func (e *State) ifValue(labels data.Labels, cond, a, b Value) (Value, error) {
+	pick := func(c, x, y *float64) *float64 {
+		if c == nil || math.IsNaN(*c) {
+			return nil
+		}
+		if *c == 0 {
+			x = y
+		}
+		if x == nil {
+			return nil
+		}
+		f := *x
+		return &f
+	}
+
+	lookups := make([]func(time.Time) (*float64, bool), 3)
+	var times []time.Time
+	hasNumber := false
+	for i, v := range []Value{cond, a, b} {
+		switch v := v.(type) {
+		case Scalar:
+			f := v.GetFloat64Value()
+			lookups[i] = func(time.Time) (*float64, bool) { return f, true }
+		case Number:
+			f := v.GetFloat64Value()
+			lookups[i] = func(time.Time) (*float64, bool) { return f, true }
+			hasNumber = true
+		case Series:
+			points := make(map[int64]*float64, v.Len())
+			for j := 0; j < v.Len(); j++ {
+				t, f := v.GetPoint(j)
+				points[t.UnixNano()] = f
+			}
+			lookups[i] = func(t time.Time) (*float64, bool) {
+				f, ok := points[t.UnixNano()]
+				return f, ok
+			}
+			if times == nil {
+				times = make([]time.Time, v.Len())
+				for j := range times {
+					times[j] = v.GetTime(j)
+				}
+			}
+		default:
+			return nil, fmt.Errorf("if: unsupported type %v", v.Type())
+		}
+	}
+
+	if times == nil {
+		c, _ := lookups[0](time.Time{})
+		x, _ := lookups[1](time.Time{})
+		y, _ := lookups[2](time.Time{})
+		if !hasNumber {
+			return NewScalar(e.RefID, pick(c, x, y)), nil
+		}
+		n := NewNumber(e.RefID, labels)
+		n.SetValue(pick(c, x, y))
+		return n, nil
+	}
+
+	newSeries := NewSeries(e.RefID, labels, 0)
+	for _, t := range times {
+		c, cOK := lookups[0](t)
+		x, xOK := lookups[1](t)
+		y, yOK := lookups[2](t)
+		if !cOK || !xOK || !yOK {
+			continue
+		}
+		newSeries.AppendPoint(t, pick(c, x, y))
+	}
+	return newSeries, nil
+}
@@ -5,6 +5,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/stretchr/testify/require"
 )
@@ -140,3 +142,144 @@ func TestIsNumberFunc(t *testing.T) {
 		})
 	}
 }
+
+func TestSeriesWindowFuncs(t *testing.T) {
+	series := func(points ...tp) Results {
+		return resultValuesNoErr(makeSeries("", data.Labels{"host": "a"}, points...))
+	}
+	var tests = []struct {
+		name     string
+		expr     string
+		vars     Vars
+		newErrIs require.ErrorAssertionFunc
+		results  Results
+	}{
+		{
+			name: "shift moves points forward in time",
+			expr: `shift($A, "1h")`,
+			vars: Vars{"A": series(
+				tp{time.Unix(0, 0), float64Pointer(1)},
+				tp{time.Unix(60, 0), float64Pointer(2)},
+			)},
+			newErrIs: require.NoError,
+			results: series(
+				tp{time.Unix(3600, 0), float64Pointer(1)},
+				tp{time.Unix(3660, 0), float64Pointer(2)},
+			),
+		},
+		{
+			name:     "shift with invalid duration should error",
+			expr:     `shift($A, "soon")`,
+			vars:     Vars{},
+			newErrIs: require.Error,
+		},
+		{
+			name: "rate handles counter resets",
+			expr: `rate($A)`,
+			vars: Vars{"A": series(
+				tp{time.Unix(0, 0), float64Pointer(10)},
+				tp{time.Unix(10, 0), float64Pointer(30)},
+				tp{time.Unix(20, 0), float64Pointer(5)},
+				tp{time.Unix(30, 0), nil},
+			)},
+			newErrIs: require.NoError,
+			results: series(
+				tp{time.Unix(10, 0), float64Pointer(2)},
+				tp{time.Unix(20, 0), float64Pointer(0.5)},
+				tp{time.Unix(30, 0), nil},
+			),
+		},
+		{
+			name: "delta",
+			expr: `delta($A)`,
+			vars: Vars{"A": series(
+				tp{time.Unix(0, 0), float64Pointer(10)},
+				tp{time.Unix(10, 0), float64Pointer(30)},
+				tp{time.Unix(20, 0), float64Pointer(5)},
+			)},
+			newErrIs: require.NoError,
+			results: series(
+				tp{time.Unix(10, 0), float64Pointer(20)},
+				tp{time.Unix(20, 0), float64Pointer(-25)},
+			),
+		},
+		{
+			name: "cumsum skips null values",
+			expr: `cumsum($A)`,
+			vars: Vars{"A": series(
+				tp{time.Unix(0, 0), float64Pointer(1)},
+				tp{time.Unix(10, 0), nil},
+				tp{time.Unix(20, 0), float64Pointer(2)},
+			)},
+			newErrIs: require.NoError,
+			results: series(
+				tp{time.Unix(0, 0), float64Pointer(1)},
+				tp{time.Unix(10, 0), nil},
+				tp{time.Unix(20, 0), float64Pointer(3)},
+			),
+		},
+		{
+			name: "moving_avg",
+			expr: `moving_avg($A, 2)`,
+			vars: Vars{"A": series(
+				tp{time.Unix(0, 0), float64Pointer(1)},
+				tp{time.Unix(10, 0), float64Pointer(3)},
+				tp{time.Unix(20, 0), nil},
+				tp{time.Unix(30, 0), nil},
+			)},
+			newErrIs: require.NoError,
+			results: series(
+				tp{time.Unix(0, 0), float64Pointer(1)},
+				tp{time.Unix(10, 0), float64Pointer(2)},
+				tp{time.Unix(20, 0), float64Pointer(3)},
+				tp{time.Unix(30, 0), nil},
+			),
+		},
+		{
+			name:     "moving_avg with zero window should error",
+			expr:     `moving_avg($A, 0)`,
+			vars:     Vars{},
+			newErrIs: require.Error,
+		},
+		{
+			name: "clamp on series",
+			expr: `clamp($A, 0, 10)`,
+			vars: Vars{"A": series(
+				tp{time.Unix(0, 0), float64Pointer(-5)},
+				tp{time.Unix(10, 0), float64Pointer(5)},
+				tp{time.Unix(20, 0), float64Pointer(15)},
+			)},
+			newErrIs: require.NoError,
+			results: series(
+				tp{time.Unix(0, 0), float64Pointer(0)},
+				tp{time.Unix(10, 0), float64Pointer(5)},
+				tp{time.Unix(20, 0), float64Pointer(10)},
+			),
+		},
+		{
+			name:     "clamp on scalar",
+			expr:     `clamp(20, 0, 10)`,
+			vars:     Vars{},
+			newErrIs: require.NoError,
+			results:  resultValuesNoErr(NewScalar("", float64Pointer(10))),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			tt.newErrIs(t, err)
+			if e != nil {
+				res, err := e.Execute("", tt.vars, tracing.InitializeTracerForTest())
+				require.NoError(t, err)
+				require.Equal(t, tt.results, res)
+			}
+		})
+	}
+}
+
+func TestSeriesWindowFuncsOnNumber(t *testing.T) {
+	e, err := New("rate($A)")
+	require.NoError(t, err)
+	_, err = e.Execute("", Vars{"A": resultValuesNoErr(makeNumber("", nil, float64Pointer(1)))}, tracing.InitializeTracerForTest())
+	require.Error(t, err)
+}
@@ -0,0 +1,113 @@
+package mathexp
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
+)
+
+var labelNameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// labelReplace sets the label dst of each value to replacement if the value of the label src matches regex.
+// The regex is anchored at both ends and replacement may reference its capture groups, like $1 or ${name}.
+// If the regex does not match, the value is unchanged. If the replacement is empty, dst is removed.
+func labelReplace(e *State, varSet Results, dst, replacement, src, regex string) (Results, error) {
+	re, err := regexp.Compile("^(?:" + regex + ")$")
+	if err != nil {
+		return Results{}, fmt.Errorf("label_replace: invalid regex %q: %w", regex, err)
+	}
+	return relabel(e, "label_replace", varSet, func(labels data.Labels) data.Labels {
+		value := labels[src]
+		match := re.FindStringSubmatchIndex(value)
+		if match == nil {
+			return labels
+		}
+		replaced := string(re.ExpandString(nil, replacement, value, match))
+		if replaced == "" {
+			delete(labels, dst)
+		} else {
+			labels[dst] = replaced
+		}
+		return labels
+	})
+}
+
+// labelDrop removes the given labels from each value.
+func labelDrop(e *State, varSet Results, names ...string) (Results, error) {
+	return relabel(e, "label_drop", varSet, func(labels data.Labels) data.Labels {
+		for _, name := range names {
+			delete(labels, name)
+		}
+		return labels
+	})
+}
+
+// labelKeep removes all labels but the given labels from each value.
+func labelKeep(e *State, varSet Results, names ...string) (Results, error) {
+	return relabel(e, "label_keep", varSet, func(labels data.Labels) data.Labels {
+		kept := data.Labels{}
+		for _, name := range names {
+			if v, ok := labels[name]; ok {
+				kept[name] = v
+			}
+		}
+		return kept
+	})
+}
+
+// relabel copies each Series and Number of varSet with the labels returned by labelsF, which gets a copy of the
+// labels of the value. Other values are passed through. It is an error if two values end up with the same labels.
+func relabel(e *State, name string, varSet Results, labelsF func(data.Labels) data.Labels) (Results, error) {
+	newRes := Results{}
+	seen := map[string]bool{}
+	for _, val := range varSet.Values {
+		var labels data.Labels
+		switch val.(type) {
+		case Series, Number:
+			labels = data.Labels{}
+			if val.GetLabels() != nil {
+				labels = val.GetLabels().Copy()
+			}
+			labels = labelsF(labels)
+			if seen[labels.String()] {
+				return newRes, fmt.Errorf("%s: more than one value with labels {%s}", name, labels)
+			}
+			seen[labels.String()] = true
+		}
+
+		switch v := val.(type) {
+		case Series:
+			newSeries := NewSeries(e.RefID, labels, v.Len())
+			for i := 0; i < v.Len(); i++ {
+				t, f := v.GetPoint(i)
+				newSeries.SetPoint(i, t, f)
+			}
+			newRes.Values = append(newRes.Values, newSeries)
+		case Number:
+			n := NewNumber(e.RefID, labels)
+			n.SetValue(v.GetFloat64Value())
+			newRes.Values = append(newRes.Values, n)
+		case NoData:
+			newRes.Values = append(newRes.Values, NewNoData())
+		default:
+			newRes.Values = append(newRes.Values, val)
+		}
+	}
+	return newRes, nil
+}
+
+// checkLabelReplace is a parse time check of the destination label name and of the regex of label_replace.
+func checkLabelReplace(t *parse.Tree, f *parse.FuncNode) error {
+	if dst, ok := f.Args[1].(*parse.StringNode); ok && !labelNameRegexp.MatchString(dst.Text) {
+		return fmt.Errorf("parse: invalid label name %s for argument 1 of %s", dst.Quoted, f.Name)
+	}
+	if regex, ok := f.Args[4].(*parse.StringNode); ok {
+		if _, err := regexp.Compile("^(?:" + regex.Text + ")$"); err != nil {
+			return fmt.Errorf("parse: invalid regex %s for argument 4 of %s: %w", regex.Quoted, f.Name, err)
+		}
+	}
+	return nil
+}
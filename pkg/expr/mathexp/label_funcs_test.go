@@ -0,0 +1,247 @@
+package mathexp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+func TestLabelFuncs(t *testing.T) {
+	numbers := resultValuesNoErr(
+		makeNumber("", data.Labels{"instance": "web-1:9090", "job": "web"}, float64Pointer(1)),
+		makeNumber("", data.Labels{"instance": "web-2:9090", "job": "web"}, float64Pointer(2)),
+	)
+	var tests = []struct {
+		name      string
+		expr      string
+		newErrIs  require.ErrorAssertionFunc
+		execErrIs require.ErrorAssertionFunc
+		results   Results
+	}{
+		{
+			name:      "label_replace with capture group",
+			expr:      `label_replace($A, "host", "$1", "instance", "(.*):.*")`,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeNumber("", data.Labels{"instance": "web-1:9090", "job": "web", "host": "web-1"}, float64Pointer(1)),
+				makeNumber("", data.Labels{"instance": "web-2:9090", "job": "web", "host": "web-2"}, float64Pointer(2)),
+			),
+		},
+		{
+			name:      "label_replace without a match keeps the labels",
+			expr:      `label_replace($A, "host", "$1", "instance", "db-(.*)")`,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results:   numbers,
+		},
+		{
+			name:      "label_replace with an empty replacement removes the label",
+			expr:      `label_replace($A, "job", "", "job", ".*")`,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeNumber("", data.Labels{"instance": "web-1:9090"}, float64Pointer(1)),
+				makeNumber("", data.Labels{"instance": "web-2:9090"}, float64Pointer(2)),
+			),
+		},
+		{
+			name:     "label_replace with an invalid regex should error",
+			expr:     `label_replace($A, "host", "$1", "instance", "(.*")`,
+			newErrIs: require.Error,
+		},
+		{
+			name:     "label_replace with an invalid label name should error",
+			expr:     `label_replace($A, "1host", "$1", "instance", "(.*)")`,
+			newErrIs: require.Error,
+		},
+		{
+			name:      "label_drop",
+			expr:      `label_drop($A, "job")`,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeNumber("", data.Labels{"instance": "web-1:9090"}, float64Pointer(1)),
+				makeNumber("", data.Labels{"instance": "web-2:9090"}, float64Pointer(2)),
+			),
+		},
+		{
+			name:      "label_keep with several labels",
+			expr:      `label_keep($A, "job", "instance")`,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results:   numbers,
+		},
+		{
+			name:      "label_keep that makes labels collide should error",
+			expr:      `label_keep($A, "job")`,
+			newErrIs:  require.NoError,
+			execErrIs: require.Error,
+		},
+		{
+			name:     "label_drop without labels should error",
+			expr:     `label_drop($A)`,
+			newErrIs: require.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			tt.newErrIs(t, err)
+			if e != nil {
+				res, err := e.Execute("", Vars{"A": numbers}, tracing.InitializeTracerForTest())
+				tt.execErrIs(t, err)
+				if err == nil {
+					require.Equal(t, tt.results, res)
+				}
+			}
+		})
+	}
+}
+
+func TestIfFunc(t *testing.T) {
+	vars := Vars{
+		"A": resultValuesNoErr(
+			makeSeries("", data.Labels{"host": "a"},
+				tp{time.Unix(0, 0), float64Pointer(1)},
+				tp{time.Unix(10, 0), float64Pointer(5)},
+				tp{time.Unix(20, 0), nil},
+			),
+		),
+		"N": resultValuesNoErr(
+			makeNumber("", data.Labels{"host": "a"}, float64Pointer(0)),
+			makeNumber("", data.Labels{"host": "b"}, float64Pointer(1)),
+		),
+	}
+	var tests = []struct {
+		name    string
+		expr    string
+		results Results
+	}{
+		{
+			name: "series condition selects points",
+			expr: `if($A > 2, $A, 0)`,
+			results: resultValuesNoErr(
+				makeSeries("", data.Labels{"host": "a"},
+					tp{time.Unix(0, 0), float64Pointer(0)},
+					tp{time.Unix(10, 0), float64Pointer(5)},
+					tp{time.Unix(20, 0), nil},
+				),
+			),
+		},
+		{
+			name: "number condition",
+			expr: `if($N, 10, 20)`,
+			results: resultValuesNoErr(
+				makeNumber("", data.Labels{"host": "a"}, float64Pointer(20)),
+				makeNumber("", data.Labels{"host": "b"}, float64Pointer(10)),
+			),
+		},
+		{
+			name:    "scalar condition",
+			expr:    `if(1, 2, 3)`,
+			results: resultValuesNoErr(NewScalar("", float64Pointer(2))),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			require.NoError(t, err)
+			res, err := e.Execute("", vars, tracing.InitializeTracerForTest())
+			require.NoError(t, err)
+			require.Equal(t, tt.results, res)
+		})
+	}
+
+	t.Run("returns the largest type of its arguments", func(t *testing.T) {
+		e, err := New(`if(1, $A, 0)`)
+		require.NoError(t, err)
+		require.Equal(t, "seriesSet", e.Tree.Root.Return().String())
+	})
+}
+
+func TestLabelMatching(t *testing.T) {
+	vars := Vars{
+		"A": resultValuesNoErr(
+			makeNumber("", data.Labels{"host": "a", "source": "prometheus"}, float64Pointer(10)),
+			makeNumber("", data.Labels{"host": "b", "source": "prometheus"}, float64Pointer(20)),
+		),
+		"B": resultValuesNoErr(
+			makeNumber("", data.Labels{"host": "a", "source": "influx"}, float64Pointer(1)),
+			makeNumber("", data.Labels{"host": "b", "source": "influx"}, float64Pointer(2)),
+		),
+		"C": resultValuesNoErr(
+			makeNumber("", data.Labels{"host": "a", "cpu": "0"}, float64Pointer(1)),
+			makeNumber("", data.Labels{"host": "a", "cpu": "1"}, float64Pointer(2)),
+		),
+		"D": resultValuesNoErr(
+			makeNumber("", data.Labels{"host": "a", "source": "prometheus"}, float64Pointer(10)),
+		),
+	}
+	var tests = []struct {
+		name      string
+		expr      string
+		execErrIs require.ErrorAssertionFunc
+		results   Results
+	}{
+		{
+			name:      "without matching mismatched labels produce nothing",
+			expr:      `$A + $B`,
+			execErrIs: require.NoError,
+			results:   Results{Values: Values{}},
+		},
+		{
+			name:      "on keeps the compared labels",
+			expr:      `$A + on(host) $B`,
+			execErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeNumber("", data.Labels{"host": "a"}, float64Pointer(11)),
+				makeNumber("", data.Labels{"host": "b"}, float64Pointer(22)),
+			),
+		},
+		{
+			name:      "ignoring drops the ignored labels",
+			expr:      `$A - ignoring(source) $B`,
+			execErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeNumber("", data.Labels{"host": "a"}, float64Pointer(9)),
+				makeNumber("", data.Labels{"host": "b"}, float64Pointer(18)),
+			),
+		},
+		{
+			name:      "many to one keeps the labels of the many side",
+			expr:      `$C * on(host) $D`,
+			execErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeNumber("", data.Labels{"host": "a", "cpu": "0"}, float64Pointer(10)),
+				makeNumber("", data.Labels{"host": "a", "cpu": "1"}, float64Pointer(20)),
+			),
+		},
+		{
+			name:      "many to many should error",
+			expr:      `$C * on() $A`,
+			execErrIs: require.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			require.NoError(t, err)
+			res, err := e.Execute("", vars, tracing.InitializeTracerForTest())
+			tt.execErrIs(t, err)
+			if err == nil {
+				require.Equal(t, tt.results, res)
+			}
+		})
+	}
+
+	t.Run("matching is part of the expression string", func(t *testing.T) {
+		e, err := New(`$A + ignoring(source, "k8s_pod") $B`)
+		require.NoError(t, err)
+		require.Equal(t, `$A + ignoring(source, k8s_pod) $B`, e.Tree.Root.String())
+	})
+}
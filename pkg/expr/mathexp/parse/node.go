@@ -9,6 +9,7 @@ package parse
 import (
 	"fmt"
 	"strconv"
+	"strings"
 )
 
 // A Node is an element in the parse tree. The interface is trivial.
@@ -163,14 +164,18 @@ func (f *FuncNode) StringAST() string {
 
 // Check performs parse time checking on the FuncNode so it fulfills the Node interface.
 func (f *FuncNode) Check(t *Tree) error {
-	if len(f.Args) < len(f.F.Args) {
+	minArgs := len(f.F.Args)
+	if f.F.Variadic {
+		minArgs--
+	}
+	if len(f.Args) < minArgs {
 		return fmt.Errorf("parse: not enough arguments for %s", f.Name)
-	} else if len(f.Args) > len(f.F.Args) {
+	} else if len(f.Args) > len(f.F.Args) && !f.F.Variadic {
 		return fmt.Errorf("parse: too many arguments for %s", f.Name)
 	}
 
 	for i, arg := range f.Args {
-		funcType := f.F.Args[i]
+		funcType := f.F.Args[min(i, len(f.F.Args)-1)]
 		argType := arg.Return()
 		// if funcType == TypeNumberSet && argType == TypeScalar {
 		// 	argType = TypeNumberSet
@@ -300,6 +305,25 @@ type BinaryNode struct {
 	Args     [2]Node
 	Operator item
 	OpStr    string
+	// Matching is the optional on(...) or ignoring(...) modifier that sets which labels pair the arguments.
+	Matching *LabelMatching
+}
+
+// LabelMatching holds the labels a binary operation pairs its arguments on.
+type LabelMatching struct {
+	// On is true for on(...), where only Labels are compared, and false for ignoring(...), where all labels
+	// but Labels are compared.
+	On     bool
+	Labels []string
+}
+
+// String returns the string representation of the LabelMatching, e.g. on(host, pod).
+func (m *LabelMatching) String() string {
+	name := "ignoring"
+	if m.On {
+		name = "on"
+	}
+	return fmt.Sprintf("%s(%s)", name, strings.Join(m.Labels, ", "))
 }
 
 func newBinary(operator item, arg1, arg2 Node) *BinaryNode {
@@ -308,11 +332,17 @@ func newBinary(operator item, arg1, arg2 Node) *BinaryNode {
 
 // String returns the string representation of the BinaryNode so it fulfills the Node interface.
 func (b *BinaryNode) String() string {
+	if b.Matching != nil {
+		return fmt.Sprintf("%s %s %s %s", b.Args[0], b.Operator.val, b.Matching, b.Args[1])
+	}
 	return fmt.Sprintf("%s %s %s", b.Args[0], b.Operator.val, b.Args[1])
 }
 
 // StringAST returns the string representation of abstract syntax tree of the BinaryNode so it fulfills the Node interface.
 func (b *BinaryNode) StringAST() string {
+	if b.Matching != nil {
+		return fmt.Sprintf("%s %s(%s, %s)", b.Operator.val, b.Matching, b.Args[0], b.Args[1])
+	}
 	return fmt.Sprintf("%s(%s, %s)", b.Operator.val, b.Args[0], b.Args[1])
 }
 
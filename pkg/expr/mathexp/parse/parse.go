@@ -30,11 +30,15 @@ type Tree struct {
 
 // Func holds the structure of a parsed function call.
 type Func struct {
-	Args          []ReturnType
-	Return        ReturnType
-	F             interface{}
+	Args   []ReturnType
+	Return ReturnType
+	F      interface{}
+	// VariantReturn makes the function return the type of its first argument, or the largest type of
+	// its TypeVariantSet arguments if it has more than one.
 	VariantReturn bool
-	Check         func(*Tree, *FuncNode) error
+	// Variadic allows the last argument to be repeated or left out, F must then be variadic as well.
+	Variadic bool
+	Check    func(*Tree, *FuncNode) error
 }
 
 // Parse returns a Tree, created by parsing the expression described in the
@@ -167,14 +171,15 @@ func (t *Tree) parse() {
 /* Grammar:
 O -> A {"||" A}
 A -> C {"&&" C}
-C -> P {( "==" | "!=" | ">" | ">=" | "<" | "<=") P}
-P -> M {( "+" | "-" ) M}
-M -> E {( "*" | "/" ) F}
-E -> F {( "**" ) F}
+C -> P {( "==" | "!=" | ">" | ">=" | "<" | "<=") [Match] P}
+P -> M {( "+" | "-" ) [Match] M}
+M -> E {( "*" | "/" ) [Match] F}
+E -> F {( "**" ) [Match] F}
 F -> v | "(" O ")" | "!" O | "-" O
 v -> number | func(..) | queryVar
 Func -> name "(" param {"," param} ")"
 param -> number | "string" | queryVar
+Match -> ( "on" | "ignoring" ) "(" [label {"," label}] ")"
 */
 
 // expr:
@@ -185,7 +190,7 @@ func (t *Tree) O() Node {
 	for {
 		switch t.peek().typ {
 		case itemOr:
-			n = newBinary(t.next(), n, t.A())
+			n = t.binary(n, t.A)
 		default:
 			return n
 		}
@@ -198,7 +203,7 @@ func (t *Tree) A() Node {
 	for {
 		switch t.peek().typ {
 		case itemAnd:
-			n = newBinary(t.next(), n, t.C())
+			n = t.binary(n, t.C)
 		default:
 			return n
 		}
@@ -211,7 +216,7 @@ func (t *Tree) C() Node {
 	for {
 		switch t.peek().typ {
 		case itemEq, itemNotEq, itemGreater, itemGreaterEq, itemLess, itemLessEq:
-			n = newBinary(t.next(), n, t.P())
+			n = t.binary(n, t.P)
 		default:
 			return n
 		}
@@ -224,7 +229,7 @@ func (t *Tree) P() Node {
 	for {
 		switch t.peek().typ {
 		case itemPlus, itemMinus:
-			n = newBinary(t.next(), n, t.M())
+			n = t.binary(n, t.M)
 		default:
 			return n
 		}
@@ -237,7 +242,7 @@ func (t *Tree) M() Node {
 	for {
 		switch t.peek().typ {
 		case itemMult, itemDiv, itemMod:
-			n = newBinary(t.next(), n, t.E())
+			n = t.binary(n, t.E)
 		default:
 			return n
 		}
@@ -250,13 +255,60 @@ func (t *Tree) E() Node {
 	for {
 		switch t.peek().typ {
 		case itemPow:
-			n = newBinary(t.next(), n, t.F())
+			n = t.binary(n, t.F)
 		default:
 			return n
 		}
 	}
 }
 
+// binary consumes the operator and its optional label matching, and parses the right hand side with rhs.
+func (t *Tree) binary(lhs Node, rhs func() Node) Node {
+	operator := t.next()
+	matching := t.Match()
+	n := newBinary(operator, lhs, rhs())
+	n.Matching = matching
+	return n
+}
+
+// Match is ( "on" | "ignoring" ) "(" [label {"," label}] ")" in the grammar. It returns nil if the next token
+// is not a label matching.
+func (t *Tree) Match() *LabelMatching {
+	token := t.peek()
+	if token.typ != itemFunc || (token.val != "on" && token.val != "ignoring") {
+		return nil
+	}
+	t.next()
+	m := &LabelMatching{On: token.val == "on", Labels: []string{}}
+	t.expect(itemLeftParen, token.val)
+	if t.peek().typ == itemRightParen {
+		t.next()
+		return m
+	}
+	for {
+		label := t.next()
+		switch label.typ {
+		case itemFunc:
+			m.Labels = append(m.Labels, label.val)
+		case itemString:
+			s, err := strconv.Unquote(label.val)
+			if err != nil {
+				t.errorf("Unquoting error: %s", err)
+			}
+			m.Labels = append(m.Labels, s)
+		default:
+			t.unexpected(label, token.val)
+		}
+		switch sep := t.next(); sep.typ {
+		case itemComma:
+		case itemRightParen:
+			return m
+		default:
+			t.unexpected(sep, token.val)
+		}
+	}
+}
+
 // F is v | "(" O ")" | "!" O | "-" O in the grammar.
 func (t *Tree) F() Node {
 	switch token := t.peek(); token.typ {
@@ -320,8 +372,11 @@ func (t *Tree) Func() (f *FuncNode) {
 			t.backup()
 			node := t.O()
 			f.append(node)
-			if len(f.Args) == 1 && f.F.VariantReturn {
-				f.F.Return = node.Return()
+			if f.F.VariantReturn {
+				i := len(f.Args) - 1
+				if i == 0 || (i < len(f.F.Args) && f.F.Args[i] == TypeVariantSet && node.Return() > f.F.Return) {
+					f.F.Return = node.Return()
+				}
 			}
 		case itemString:
 			s, err := strconv.Unquote(token.val)
@@ -332,6 +387,17 @@ func (t *Tree) Func() (f *FuncNode) {
 		case itemRightParen:
 			return
 		}
+		// Arguments are separated by commas, anything else must close the call.
+		switch token = t.next(); token.typ {
+		case itemComma:
+			if t.peek().typ == itemRightParen {
+				t.unexpected(token, "func")
+			}
+		case itemRightParen:
+			return
+		default:
+			t.unexpected(token, "func")
+		}
 	}
 }
 
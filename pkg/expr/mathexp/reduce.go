@@ -3,7 +3,9 @@ package mathexp
 import (
 	"fmt"
 	"math"
+	"regexp"
 	"sort"
+	"strconv"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 )
@@ -15,18 +17,55 @@ type ReducerFunc = func(fv *Float64Field) *float64
 type ReducerID string
 
 const (
-	ReducerSum    ReducerID = "sum"
-	ReducerMean   ReducerID = "mean"
-	ReducerMin    ReducerID = "min"
-	ReducerMax    ReducerID = "max"
-	ReducerCount  ReducerID = "count"
-	ReducerLast   ReducerID = "last"
-	ReducerMedian ReducerID = "median"
+	ReducerSum          ReducerID = "sum"
+	ReducerMean         ReducerID = "mean"
+	ReducerMin          ReducerID = "min"
+	ReducerMax          ReducerID = "max"
+	ReducerCount        ReducerID = "count"
+	ReducerLast         ReducerID = "last"
+	ReducerMedian       ReducerID = "median"
+	ReducerFirst        ReducerID = "first"
+	ReducerStdDev       ReducerID = "stddev"
+	ReducerVariance     ReducerID = "variance"
+	ReducerDiff         ReducerID = "diff"
+	ReducerPercentDiff  ReducerID = "percent_diff"
+	ReducerCountNonNull ReducerID = "count_non_null"
+	ReducerRange        ReducerID = "range"
+	ReducerRate         ReducerID = "rate"
+	ReducerP50          ReducerID = "p50"
+	ReducerP75          ReducerID = "p75"
+	ReducerP90          ReducerID = "p90"
+	ReducerP95          ReducerID = "p95"
+	ReducerP99          ReducerID = "p99"
 )
 
-// GetSupportedReduceFuncs returns collection of supported function names
+// PercentileReducerPattern matches the pN reducers, which return the Nth percentile of the series. N is a number
+// between 0 and 100 with optional decimals, such as p95 or p99.9.
+const PercentileReducerPattern = `p(100|[0-9]?[0-9](\.[0-9]+)?)`
+
+var percentileReducerRegexp = regexp.MustCompile("^" + PercentileReducerPattern + "$")
+
+// GetSupportedReduceFuncs returns collection of supported function names.
+// Percentile reducers are not limited to the listed ones, any reducer that matches PercentileReducerPattern is accepted.
 func GetSupportedReduceFuncs() []ReducerID {
-	return []ReducerID{ReducerSum, ReducerMean, ReducerMin, ReducerMax, ReducerCount, ReducerLast, ReducerMedian}
+	return []ReducerID{
+		ReducerSum, ReducerMean, ReducerMin, ReducerMax, ReducerCount, ReducerLast, ReducerMedian,
+		ReducerFirst, ReducerStdDev, ReducerVariance, ReducerDiff, ReducerPercentDiff, ReducerCountNonNull,
+		ReducerRange, ReducerRate, ReducerP50, ReducerP75, ReducerP90, ReducerP95, ReducerP99,
+	}
+}
+
+// Percentile returns the percentile N of a pN reducer such as p95 or p99.9.
+// The second return value is false if the reducer is not a percentile reducer.
+func (r ReducerID) Percentile() (float64, bool) {
+	if !percentileReducerRegexp.MatchString(string(r)) {
+		return 0, false
+	}
+	p, err := strconv.ParseFloat(string(r[1:]), 64)
+	if err != nil {
+		return 0, false
+	}
+	return p, true
 }
 
 func Sum(fv *Float64Field) *float64 {
@@ -126,6 +165,148 @@ func Median(fv *Float64Field) *float64 {
 	}
 }
 
+func First(fv *Float64Field) *float64 {
+	var f float64
+	if fv.Len() == 0 {
+		f = math.NaN()
+		return &f
+	}
+	return fv.GetValue(0)
+}
+
+// Percentile returns the p-th percentile of the values, linearly interpolating between the closest ranks.
+func Percentile(fv *Float64Field, p float64) *float64 {
+	values, ok := numbers(fv)
+	if !ok || len(values) == 0 {
+		nan := math.NaN()
+		return &nan
+	}
+
+	sort.Float64s(values)
+	rank := p / 100 * float64(len(values)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	v := values[lower] + (values[upper]-values[lower])*(rank-float64(lower))
+	return &v
+}
+
+// Variance returns the population variance of the values.
+func Variance(fv *Float64Field) *float64 {
+	values, ok := numbers(fv)
+	if !ok || len(values) == 0 {
+		nan := math.NaN()
+		return &nan
+	}
+
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+	var sq float64
+	for _, v := range values {
+		sq += (v - mean) * (v - mean)
+	}
+	variance := sq / float64(len(values))
+	return &variance
+}
+
+// StdDev returns the population standard deviation of the values.
+func StdDev(fv *Float64Field) *float64 {
+	f := math.Sqrt(*Variance(fv))
+	return &f
+}
+
+// Diff returns the difference between the last and the first value.
+func Diff(fv *Float64Field) *float64 {
+	return firstLast(fv, func(first, last float64) float64 {
+		return last - first
+	})
+}
+
+// PercentDiff returns the difference between the last and the first value as a percentage of the first value.
+func PercentDiff(fv *Float64Field) *float64 {
+	return firstLast(fv, func(first, last float64) float64 {
+		return (last - first) / math.Abs(first) * 100
+	})
+}
+
+// CountNonNull returns the number of values that are neither null nor NaN.
+func CountNonNull(fv *Float64Field) *float64 {
+	var f float64
+	for i := 0; i < fv.Len(); i++ {
+		v := fv.GetValue(i)
+		if v != nil && !math.IsNaN(*v) {
+			f++
+		}
+	}
+	return &f
+}
+
+// Range returns the difference between the maximum and the minimum value.
+func Range(fv *Float64Field) *float64 {
+	f := *Max(fv) - *Min(fv)
+	return &f
+}
+
+// Rate returns the per-second rate of increase over the series. A decrease in value
+// is treated as a counter reset. Series with less than two points reduce to NaN.
+func Rate(s Series) *float64 {
+	nan := math.NaN()
+	if s.Len() < 2 {
+		return &nan
+	}
+	var increase float64
+	for i := 0; i < s.Len(); i++ {
+		v := s.GetValue(i)
+		if v == nil || math.IsNaN(*v) {
+			return &nan
+		}
+		if i == 0 {
+			continue
+		}
+		d := *v - *s.GetValue(i - 1)
+		if d < 0 {
+			d = *v
+		}
+		increase += d
+	}
+	span := s.GetTime(s.Len() - 1).Sub(s.GetTime(0)).Seconds()
+	if span <= 0 {
+		return &nan
+	}
+	f := increase / span
+	return &f
+}
+
+// numbers returns the values of the field. The second return value is false if any of the values is null or NaN.
+func numbers(fv *Float64Field) ([]float64, bool) {
+	values := make([]float64, 0, fv.Len())
+	for i := 0; i < fv.Len(); i++ {
+		v := fv.GetValue(i)
+		if v == nil || math.IsNaN(*v) {
+			return nil, false
+		}
+		values = append(values, *v)
+	}
+	return values, true
+}
+
+// firstLast applies fn to the first and the last value of the field.
+// NaN is returned if the field is empty or either of the values is null or NaN.
+func firstLast(fv *Float64Field, fn func(first, last float64) float64) *float64 {
+	nan := math.NaN()
+	if fv.Len() == 0 {
+		return &nan
+	}
+	first, last := fv.GetValue(0), fv.GetValue(fv.Len()-1)
+	if first == nil || last == nil || math.IsNaN(*first) || math.IsNaN(*last) {
+		return &nan
+	}
+	f := fn(*first, *last)
+	return &f
+}
+
 func GetReduceFunc(rFunc ReducerID) (ReducerFunc, error) {
 	switch rFunc {
 	case ReducerSum:
@@ -142,11 +323,41 @@ func GetReduceFunc(rFunc ReducerID) (ReducerFunc, error) {
 		return Last, nil
 	case ReducerMedian:
 		return Median, nil
+	case ReducerFirst:
+		return First, nil
+	case ReducerStdDev:
+		return StdDev, nil
+	case ReducerVariance:
+		return Variance, nil
+	case ReducerDiff:
+		return Diff, nil
+	case ReducerPercentDiff:
+		return PercentDiff, nil
+	case ReducerCountNonNull:
+		return CountNonNull, nil
+	case ReducerRange:
+		return Range, nil
+	case ReducerRate:
+		return nil, fmt.Errorf("reduction %v needs the time of each point and can only be applied to a series", rFunc)
 	default:
+		if p, ok := rFunc.Percentile(); ok {
+			return func(fv *Float64Field) *float64 {
+				return Percentile(fv, p)
+			}, nil
+		}
 		return nil, fmt.Errorf("reduction %v not implemented", rFunc)
 	}
 }
 
+// ValidateReducer returns an error if the reducer is not supported.
+func ValidateReducer(rFunc ReducerID) error {
+	if rFunc == ReducerRate {
+		return nil
+	}
+	_, err := GetReduceFunc(rFunc)
+	return err
+}
+
 // Reduce turns the Series into a Number based on the given reduction function
 // if ReduceMapper is defined it applies it to the provided series and performs reduction of the resulting series.
 // Otherwise, the reduction operation is done against the original series.
@@ -161,13 +372,17 @@ func (s Series) Reduce(refID string, rFunc ReducerID, mapper ReduceMapper) (Numb
 	if mapper != nil {
 		series = mapSeries(s, mapper)
 	}
-	fVec := series.Frame.Fields[seriesTypeValIdx]
-	floatField := Float64Field(*fVec)
-	reduceFunc, err := GetReduceFunc(rFunc)
-	if err != nil {
-		return number, fmt.Errorf("invalid expression '%s': %w", refID, err)
+	if rFunc == ReducerRate {
+		f = Rate(series)
+	} else {
+		fVec := series.Frame.Fields[seriesTypeValIdx]
+		floatField := Float64Field(*fVec)
+		reduceFunc, err := GetReduceFunc(rFunc)
+		if err != nil {
+			return number, fmt.Errorf("invalid expression '%s': %w", refID, err)
+		}
+		f = reduceFunc(&floatField)
 	}
-	f = reduceFunc(&floatField)
 	if f != nil && mapper != nil {
 		f = mapper.MapOutput(f)
 	}
@@ -7,6 +7,20 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 )
 
+// The downsample function
+// +enum
+type Downsampler string
+
+const (
+	DownsamplerSum   Downsampler = "sum"
+	DownsamplerMean  Downsampler = "mean"
+	DownsamplerMin   Downsampler = "min"
+	DownsamplerMax   Downsampler = "max"
+	DownsamplerCount Downsampler = "count"
+	DownsamplerLast  Downsampler = "last"
+	DownsamplerFirst Downsampler = "first"
+)
+
 // The upsample function
 // +enum
 type Upsampler string
@@ -20,10 +34,47 @@ const (
 
 	// Do not fill values (nill)
 	UpsamplerFillNA Upsampler = "fillna"
+
+	// Interpolate linearly between the surrounding values
+	UpsamplerLinear Upsampler = "linear"
 )
 
+// Where the resampled buckets are anchored
+// +enum
+type ResampleAlignment string
+
+const (
+	// Anchor buckets to the start of the time range
+	ResampleAlignStart ResampleAlignment = "start"
+
+	// Anchor buckets to multiples of the window since the Unix epoch
+	ResampleAlignEpoch ResampleAlignment = "epoch"
+)
+
+// Align returns the time of the first bucket of a resampled series that starts at from.
+// Epoch alignment moves the start forward to the next multiple of interval since the Unix epoch,
+// so that series resampled over different time ranges share the same timestamps.
+// An empty alignment is the same as ResampleAlignStart.
+func (a ResampleAlignment) Align(from time.Time, interval time.Duration) (time.Time, error) {
+	switch a {
+	case "", ResampleAlignStart:
+		return from, nil
+	case ResampleAlignEpoch:
+		if interval <= 0 {
+			return from, fmt.Errorf("resample interval must be positive, got %v", interval)
+		}
+		aligned := from.Add(-time.Duration(from.UnixNano() % int64(interval)))
+		if aligned.Before(from) {
+			aligned = aligned.Add(interval)
+		}
+		return aligned, nil
+	default:
+		return from, fmt.Errorf("resample alignment %v not implemented", a)
+	}
+}
+
 // Resample turns the Series into a Number based on the given reduction function
-func (s Series) Resample(refID string, interval time.Duration, downsampler ReducerID, upsampler Upsampler, from, to time.Time) (Series, error) {
+func (s Series) Resample(refID string, interval time.Duration, downsampler Downsampler, upsampler Upsampler, from, to time.Time) (Series, error) {
 	newSeriesLength := int(float64(to.Sub(from).Nanoseconds()) / float64(interval.Nanoseconds()))
 	if newSeriesLength <= 0 {
 		return s, fmt.Errorf("the series cannot be sampled further; the time range is shorter than the interval")
@@ -31,6 +82,7 @@ func (s Series) Resample(refID string, interval time.Duration, downsampler Reduc
 	resampled := NewSeries(refID, s.GetLabels(), newSeriesLength+1)
 	bookmark := 0
 	var lastSeen *float64
+	var lastSeenTime time.Time
 	idx := 0
 	t := from
 	for !t.After(to) && idx <= newSeriesLength {
@@ -47,10 +99,14 @@ func (s Series) Resample(refID string, interval time.Duration, downsampler Reduc
 			bookmark++
 			sIdx++
 			lastSeen = v
+			lastSeenTime = st
 			vals = append(vals, v)
 		}
 		var value *float64
-		if len(vals) == 0 { // upsampling
+		if len(vals) == 0 && downsampler == DownsamplerCount { // an empty bucket has no values to count
+			zero := float64(0)
+			value = &zero
+		} else if len(vals) == 0 { // upsampling
 			switch upsampler {
 			case UpsamplerPad:
 				if lastSeen != nil {
@@ -66,26 +122,35 @@ func (s Series) Resample(refID string, interval time.Duration, downsampler Reduc
 				}
 			case UpsamplerFillNA:
 				value = nil
+			case UpsamplerLinear:
+				if sIdx < s.Len() {
+					nextTime, next := s.GetPoint(sIdx)
+					value = interpolate(lastSeenTime, lastSeen, nextTime, next, t)
+				}
 			default:
 				return s, fmt.Errorf("upsampling %v not implemented", upsampler)
 			}
-		} else if len(vals) == 1 {
+		} else if len(vals) == 1 && downsampler != DownsamplerCount {
 			value = vals[0]
 		} else { // downsampling
 			fVec := data.NewField("", s.GetLabels(), vals)
 			ff := Float64Field(*fVec)
 			var tmp *float64
 			switch downsampler {
-			case ReducerSum:
+			case DownsamplerSum:
 				tmp = Sum(&ff)
-			case ReducerMean:
+			case DownsamplerMean:
 				tmp = Avg(&ff)
-			case ReducerMin:
+			case DownsamplerMin:
 				tmp = Min(&ff)
-			case ReducerMax:
+			case DownsamplerMax:
 				tmp = Max(&ff)
-			case ReducerLast:
+			case DownsamplerLast:
 				tmp = Last(&ff)
+			case DownsamplerFirst:
+				tmp = First(&ff)
+			case DownsamplerCount:
+				tmp = Count(&ff)
 			default:
 				return s, fmt.Errorf("downsampling %v not implemented", downsampler)
 			}
@@ -97,3 +162,14 @@ func (s Series) Resample(refID string, interval time.Duration, downsampler Reduc
 	}
 	return resampled, nil
 }
+
+// interpolate returns the value at t on the line between the points (prevTime, prev) and (nextTime, next).
+// If either of the points is missing or null, nil is returned.
+func interpolate(prevTime time.Time, prev *float64, nextTime time.Time, next *float64, t time.Time) *float64 {
+	if prev == nil || next == nil || !nextTime.After(prevTime) {
+		return nil
+	}
+	ratio := float64(t.Sub(prevTime)) / float64(nextTime.Sub(prevTime))
+	f := *prev + (*next-*prev)*ratio
+	return &f
+}
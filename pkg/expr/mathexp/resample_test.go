@@ -13,7 +13,7 @@ func TestResampleSeries(t *testing.T) {
 	var tests = []struct {
 		name             string
 		interval         time.Duration
-		downsampler      ReducerID
+		downsampler      Downsampler
 		upsampler        Upsampler
 		timeRange        backend.TimeRange
 		seriesToResample Series
@@ -277,6 +277,100 @@ func TestResampleSeries(t *testing.T) {
 				time.Unix(9, 0), float64Pointer(0),
 			}),
 		},
+		{
+			name:        "resample series: upsampling (linear)",
+			interval:    time.Second * 2,
+			downsampler: "mean",
+			upsampler:   "linear",
+			timeRange: backend.TimeRange{
+				From: time.Unix(0, 0),
+				To:   time.Unix(8, 0),
+			},
+			seriesToResample: makeSeries("", nil, tp{
+				time.Unix(2, 0), float64Pointer(2),
+			}, tp{
+				time.Unix(6, 0), float64Pointer(10),
+			}),
+			series: makeSeries("", nil, tp{
+				time.Unix(0, 0), nil,
+			}, tp{
+				time.Unix(2, 0), float64Pointer(2),
+			}, tp{
+				time.Unix(4, 0), float64Pointer(6),
+			}, tp{
+				time.Unix(6, 0), float64Pointer(10),
+			}, tp{
+				time.Unix(8, 0), nil,
+			}),
+		},
+		{
+			name:        "resample series: downsampling (count / fillna)",
+			interval:    time.Second * 5,
+			downsampler: "count",
+			upsampler:   "fillna",
+			timeRange: backend.TimeRange{
+				From: time.Unix(0, 0),
+				To:   time.Unix(10, 0),
+			},
+			seriesToResample: makeSeries("", nil, tp{
+				time.Unix(2, 0), float64Pointer(2),
+			}, tp{
+				time.Unix(4, 0), float64Pointer(3),
+			}, tp{
+				time.Unix(7, 0), float64Pointer(7),
+			}),
+			series: makeSeries("", nil, tp{
+				time.Unix(0, 0), float64Pointer(0),
+			}, tp{
+				time.Unix(5, 0), float64Pointer(2),
+			}, tp{
+				time.Unix(10, 0), float64Pointer(1),
+			}),
+		},
+		{
+			name:        "resample series: downsampling (count / pad)",
+			interval:    time.Second * 5,
+			downsampler: "count",
+			upsampler:   "pad",
+			timeRange: backend.TimeRange{
+				From: time.Unix(0, 0),
+				To:   time.Unix(15, 0),
+			},
+			seriesToResample: makeSeries("", nil, tp{
+				time.Unix(2, 0), float64Pointer(2),
+			}, tp{
+				time.Unix(4, 0), float64Pointer(3),
+			}),
+			series: makeSeries("", nil, tp{
+				time.Unix(0, 0), float64Pointer(0),
+			}, tp{
+				time.Unix(5, 0), float64Pointer(2),
+			}, tp{
+				time.Unix(10, 0), float64Pointer(0),
+			}, tp{
+				time.Unix(15, 0), float64Pointer(0),
+			}),
+		},
+		{
+			name:        "resample series: downsampling (first / fillna)",
+			interval:    time.Second * 5,
+			downsampler: "first",
+			upsampler:   "fillna",
+			timeRange: backend.TimeRange{
+				From: time.Unix(0, 0),
+				To:   time.Unix(5, 0),
+			},
+			seriesToResample: makeSeries("", nil, tp{
+				time.Unix(2, 0), float64Pointer(2),
+			}, tp{
+				time.Unix(4, 0), float64Pointer(3),
+			}),
+			series: makeSeries("", nil, tp{
+				time.Unix(0, 0), nil,
+			}, tp{
+				time.Unix(5, 0), float64Pointer(2),
+			}),
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -290,3 +384,27 @@ func TestResampleSeries(t *testing.T) {
 		})
 	}
 }
+
+func TestResampleAlignment(t *testing.T) {
+	from := time.Unix(7, 0)
+	interval := 5 * time.Second
+
+	aligned, err := ResampleAlignStart.Align(from, interval)
+	require.NoError(t, err)
+	require.Equal(t, from, aligned)
+
+	aligned, err = ResampleAlignment("").Align(from, interval)
+	require.NoError(t, err)
+	require.Equal(t, from, aligned)
+
+	aligned, err = ResampleAlignEpoch.Align(from, interval)
+	require.NoError(t, err)
+	require.Equal(t, time.Unix(10, 0), aligned)
+
+	aligned, err = ResampleAlignEpoch.Align(time.Unix(10, 0), interval)
+	require.NoError(t, err)
+	require.Equal(t, time.Unix(10, 0), aligned)
+
+	_, err = ResampleAlignment("middle").Align(from, interval)
+	require.Error(t, err)
+}
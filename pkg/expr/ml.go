@@ -131,6 +131,9 @@ func (m *MLNode) Execute(ctx context.Context, now time.Time, _ mathexp.Vars, s *
 
 	// process the response the same way DSNode does. Use plugin ID as data source type. Semantically, they are the same.
 	responseType, result, err = s.converter.Convert(ctx, mlPluginID, dataFrames, s.allowLongFrames)
+	if err == nil {
+		TraceFromContext(ctx).addConversion(m.refID, framesDescription(dataFrames), responseType)
+	}
 	return result, err
 }
 
@@ -158,6 +158,10 @@ func buildCMDNode(rn *rawNode, toggles featuremgmt.FeatureToggles) (*CMDNode, er
 		node.Command, err = UnmarshalThresholdCommand(rn, toggles)
 	case TypeSQL:
 		node.Command, err = UnmarshalSQLCommand(rn)
+	case TypeAnomaly:
+		node.Command, err = UnmarshalAnomalyCommand(rn)
+	case TypeForecast:
+		node.Command, err = UnmarshalForecastCommand(rn)
 	default:
 		return nil, fmt.Errorf("expression command type '%v' in expression '%v' not implemented", commandType, rn.RefID)
 	}
@@ -265,6 +269,16 @@ func executeDSNodesGrouped(ctx context.Context, now time.Time, vars mathexp.Vars
 		func() {
 			ctx, span := s.tracer.Start(ctx, "SSE.ExecuteDatasourceQuery")
 			defer span.End()
+			trace := TraceFromContext(ctx)
+			start := time.Now()
+			for _, dn := range nodeGroup {
+				trace.startNode(dn, vars, start)
+			}
+			defer func() {
+				for _, dn := range nodeGroup {
+					trace.endNode(dn.refID, vars[dn.refID], time.Now())
+				}
+			}()
 			firstNode := nodeGroup[0]
 			pCtx, err := s.pCtxProvider.GetWithDataSource(ctx, firstNode.datasource.Type, firstNode.request.User, firstNode.datasource)
 			if err != nil {
@@ -336,6 +350,8 @@ func executeDSNodesGrouped(ctx context.Context, now time.Time, vars mathexp.Vars
 				responseType, result, err := s.converter.Convert(ctx, dn.datasource.Type, dataFrames, s.allowLongFrames)
 				if err != nil {
 					result.Error = makeConversionError(dn.RefID(), err)
+				} else {
+					trace.addConversion(dn.refID, framesDescription(dataFrames), responseType)
 				}
 				instrument(err, responseType)
 				vars[dn.refID] = result
@@ -404,6 +420,8 @@ func (dn *DSNode) Execute(ctx context.Context, now time.Time, _ mathexp.Vars, s
 	responseType, result, err = s.converter.Convert(ctx, dn.datasource.Type, dataFrames, s.allowLongFrames)
 	if err != nil {
 		err = makeConversionError(dn.refID, err)
+	} else {
+		TraceFromContext(ctx).addConversion(dn.refID, framesDescription(dataFrames), responseType)
 	}
 	return result, err
 }
@@ -27,8 +27,14 @@ const (
 	// Threshold
 	QueryTypeThreshold QueryType = "threshold"
 
-	// SQL query via DuckDB
+	// SQL query over the results of other queries
 	QueryTypeSQL QueryType = "sql"
+
+	// Detect anomalies in query results
+	QueryTypeAnomaly QueryType = "anomaly"
+
+	// Forecast query results
+	QueryTypeForecast QueryType = "forecast"
 )
 
 type MathQuery struct {
@@ -40,8 +46,9 @@ type ReduceQuery struct {
 	// Reference to single query result
 	Expression string `json:"expression" jsonschema:"minLength=1,example=$A"`
 
-	// The reducer
-	Reducer mathexp.ReducerID `json:"reducer"`
+	// The reducer: sum, mean, min, max, count, last, median, first, stddev, variance, diff, percent_diff,
+	// count_non_null, range, rate, or pN for the Nth percentile, where N is a number between 0 and 100 such as 95 or 99.9
+	Reducer mathexp.ReducerID `json:"reducer" jsonschema:"pattern=^(sum|mean|min|max|count|last|median|first|stddev|variance|diff|percent_diff|count_non_null|range|rate|p(100|[0-9]?[0-9](\\.[0-9]+)?))$,example=max,example=p99.9"`
 
 	// Reducer Options
 	Settings *ReduceSettings `json:"settings,omitempty"`
@@ -56,10 +63,13 @@ type ResampleQuery struct {
 	Window string `json:"window" jsonschema:"minLength=1,example=1d,example=10m"`
 
 	// The downsample function
-	Downsampler mathexp.ReducerID `json:"downsampler"`
+	Downsampler mathexp.Downsampler `json:"downsampler"`
 
 	// The upsample function
 	Upsampler mathexp.Upsampler `json:"upsampler"`
+
+	// Where the resampled buckets are anchored, defaults to the start of the time range
+	Alignment mathexp.ResampleAlignment `json:"alignment,omitempty"`
 }
 
 type ThresholdQuery struct {
@@ -74,6 +84,43 @@ type ClassicQuery struct {
 	Conditions []classic.ConditionJSON `json:"conditions"`
 }
 
+type AnomalyQuery struct {
+	// Reference to single query result
+	Expression string `json:"expression" jsonschema:"minLength=1,example=$A"`
+
+	// The detection algorithm
+	Algorithm AnomalyAlgorithm `json:"algorithm"`
+
+	// Number of preceding points the expected values are computed from (default 20)
+	Window int `json:"window,omitempty"`
+
+	// Width of the band of expected values, in deviations (default 3)
+	Sensitivity float64 `json:"sensitivity,omitempty"`
+
+	// Number of points in one season, required by the seasonal algorithm
+	Season int `json:"season,omitempty"`
+
+	// What the expression returns (default number)
+	Output AnomalyOutput `json:"output,omitempty"`
+}
+
+type ForecastQuery struct {
+	// Reference to single query result
+	Expression string `json:"expression" jsonschema:"minLength=1,example=$A"`
+
+	// The forecast method
+	Method ForecastMethod `json:"method"`
+
+	// How far past the latest point to forecast
+	Horizon string `json:"horizon" jsonschema:"minLength=1,example=4h,example=1d"`
+
+	// Smoothing factor of the level, between 0 and 1 (holt_winters only, default 0.5)
+	Smoothing float64 `json:"smoothing,omitempty"`
+
+	// Smoothing factor of the trend, between 0 and 1 (holt_winters only, default 0.5)
+	Trend float64 `json:"trend,omitempty"`
+}
+
 // SQLQuery requires the sqlExpression feature flag
 type SQLExpression struct {
 	Expression string `json:"expression" jsonschema:"minLength=1,example=SELECT * FROM A LIMIT 1"`
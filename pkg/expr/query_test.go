@@ -22,10 +22,14 @@ func TestQueryTypeDefinitions(t *testing.T) {
 				CodePath:    "./",
 			}},
 			Enums: []reflect.Type{
-				reflect.TypeOf(mathexp.ReducerSum),   // pick an example value (not the root)
-				reflect.TypeOf(mathexp.UpsamplerPad), // pick an example value (not the root)
-				reflect.TypeOf(ReduceModeDrop),       // pick an example value (not the root)
+				reflect.TypeOf(mathexp.DownsamplerSum), // pick an example value (not the root)
+				reflect.TypeOf(mathexp.UpsamplerPad),   // pick an example value (not the root)
+				reflect.TypeOf(ReduceModeDrop),         // pick an example value (not the root)
 				reflect.TypeOf(ThresholdIsAbove),
+				reflect.TypeOf(mathexp.ResampleAlignStart),
+				reflect.TypeOf(AnomalyZScore),
+				reflect.TypeOf(AnomalyOutputNumber),
+				reflect.TypeOf(ForecastLinear),
 				reflect.TypeOf(classic.ConditionOperatorAnd),
 			},
 		})
@@ -74,7 +78,7 @@ func TestQueryTypeDefinitions(t *testing.T) {
 					SaveModel: data.AsUnstructured(ResampleQuery{
 						Expression:  "$A",
 						Window:      "1d",
-						Downsampler: mathexp.ReducerLast,
+						Downsampler: mathexp.DownsamplerLast,
 						Upsampler:   mathexp.UpsamplerPad,
 					}),
 				},
@@ -92,6 +96,35 @@ func TestQueryTypeDefinitions(t *testing.T) {
 				},
 			},
 		},
+		schemabuilder.QueryTypeInfo{
+			Discriminators: data.NewDiscriminators("type", QueryTypeAnomaly),
+			GoType:         reflect.TypeOf(&AnomalyQuery{}),
+			Examples: []data.QueryExample{
+				{
+					Name: "Latest point of A is more than 3 standard deviations from the mean",
+					SaveModel: data.AsUnstructured(AnomalyQuery{
+						Expression:  "$A",
+						Algorithm:   AnomalyZScore,
+						Window:      30,
+						Sensitivity: 3,
+					}),
+				},
+			},
+		},
+		schemabuilder.QueryTypeInfo{
+			Discriminators: data.NewDiscriminators("type", QueryTypeForecast),
+			GoType:         reflect.TypeOf(&ForecastQuery{}),
+			Examples: []data.QueryExample{
+				{
+					Name: "Value of A in 4 hours",
+					SaveModel: data.AsUnstructured(ForecastQuery{
+						Expression: "$A",
+						Method:     ForecastLinear,
+						Horizon:    "4h",
+					}),
+				},
+			},
+		},
 		schemabuilder.QueryTypeInfo{
 			Discriminators: data.NewDiscriminators("type", QueryTypeClassic),
 			GoType:         reflect.TypeOf(&ClassicQuery{}),
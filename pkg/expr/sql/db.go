@@ -0,0 +1,342 @@
+package sql
+
+import (
+	"context"
+	gosql "database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	sqlite3 "github.com/mattn/go-sqlite3"
+)
+
+// QueryFrames runs the query in a new in-memory SQLite database that holds one table per RefID of frames. Frames that
+// share a RefID are appended to the same table, and a column is created for every field name found in any of them.
+// The database is read-only while the query runs and is dropped when QueryFrames returns.
+func QueryFrames(ctx context.Context, name, query string, frames []*data.Frame) (*data.Frame, error) {
+	db, err := gosql.Open("sqlite3", ":memory:")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the in-memory database: %w", err)
+	}
+	defer func() { _ = db.Close() }()
+	// every connection to :memory: gets its own database
+	db.SetMaxOpenConns(1)
+
+	if err := loadTables(ctx, db, frames); err != nil {
+		return nil, err
+	}
+	if _, err := db.ExecContext(ctx, "PRAGMA query_only = ON"); err != nil {
+		return nil, fmt.Errorf("failed to make the database read-only: %w", err)
+	}
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute sql: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	frame, err := framesFromRows(name, rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the result of sql: %w", err)
+	}
+	return frame, nil
+}
+
+type column struct {
+	name     string
+	declType string
+}
+
+type table struct {
+	name    string
+	columns []column
+	index   map[string]int
+	frames  []*data.Frame
+}
+
+func loadTables(ctx context.Context, db *gosql.DB, frames []*data.Frame) error {
+	tables := []*table{}
+	byName := map[string]*table{}
+	for _, frame := range frames {
+		if frame == nil {
+			continue
+		}
+		t, ok := byName[strings.ToLower(frame.RefID)]
+		if !ok {
+			t = &table{name: frame.RefID, index: map[string]int{}}
+			byName[strings.ToLower(frame.RefID)] = t
+			tables = append(tables, t)
+		}
+		t.frames = append(t.frames, frame)
+		for i, field := range frame.Fields {
+			name := columnName(field, i)
+			idx, ok := t.index[strings.ToLower(name)]
+			if !ok {
+				t.index[strings.ToLower(name)] = len(t.columns)
+				t.columns = append(t.columns, column{name: name, declType: declType(field.Type())})
+				continue
+			}
+			if t.columns[idx].declType != declType(field.Type()) {
+				// SQLite is dynamically typed, the values keep their own type
+				t.columns[idx].declType = ""
+			}
+		}
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+	for _, t := range tables {
+		if err := t.load(ctx, tx); err != nil {
+			return fmt.Errorf("failed to load table %s: %w", t.name, err)
+		}
+	}
+	return tx.Commit()
+}
+
+func (t *table) load(ctx context.Context, tx *gosql.Tx) error {
+	if len(t.columns) == 0 {
+		// frames without fields, such as no data, still need a table to select from
+		t.columns = append(t.columns, column{name: "value", declType: "REAL"})
+	}
+	defs := make([]string, len(t.columns))
+	names := make([]string, len(t.columns))
+	params := make([]string, len(t.columns))
+	for i, c := range t.columns {
+		names[i] = quoteIdentifier(c.name)
+		defs[i] = strings.TrimSpace(names[i] + " " + c.declType)
+		params[i] = "?"
+	}
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("CREATE TABLE %s (%s)", quoteIdentifier(t.name), strings.Join(defs, ", "))); err != nil {
+		return err
+	}
+
+	stmt, err := tx.PrepareContext(ctx, fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", quoteIdentifier(t.name), strings.Join(names, ", "), strings.Join(params, ", ")))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = stmt.Close() }()
+
+	args := make([]any, len(t.columns))
+	for _, frame := range t.frames {
+		for row := 0; row < frame.Rows(); row++ {
+			clear(args)
+			for i, field := range frame.Fields {
+				args[t.index[strings.ToLower(columnName(field, i))]] = sqlValue(field, row)
+			}
+			if _, err := stmt.ExecContext(ctx, args...); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// columnName is the name of the field, or its position if it has no name.
+func columnName(field *data.Field, i int) string {
+	if field.Name == "" {
+		return "field" + strconv.Itoa(i+1)
+	}
+	return field.Name
+}
+
+// declType is the declared SQLite type of a column of fields of type ft. Times are declared as TIMESTAMP, so that
+// the driver reads them back as times.
+func declType(ft data.FieldType) string {
+	switch {
+	case ft.Time():
+		return "TIMESTAMP"
+	case ft == data.FieldTypeBool || ft == data.FieldTypeNullableBool:
+		return "BOOLEAN"
+	case ft == data.FieldTypeFloat32 || ft == data.FieldTypeNullableFloat32 ||
+		ft == data.FieldTypeFloat64 || ft == data.FieldTypeNullableFloat64:
+		return "REAL"
+	case ft.Numeric():
+		return "INTEGER"
+	default:
+		return "TEXT"
+	}
+}
+
+func sqlValue(field *data.Field, row int) any {
+	v, ok := field.ConcreteAt(row)
+	if !ok || v == nil {
+		return nil
+	}
+	switch v := v.(type) {
+	case time.Time:
+		return v.UTC()
+	case uint64:
+		return float64(v)
+	case string, bool, float64, float32, int64, int32, int16, int8, uint32, uint16, uint8:
+		return v
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// framesFromRows reads the rows into a frame. The type of each field is chosen from the values of its column: times,
+// then text, then floats, then integers. Fields are nullable only if the column holds nulls.
+func framesFromRows(name string, rows *gosql.Rows) (*data.Frame, error) {
+	names, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	columns := make([][]any, len(names))
+	for rows.Next() {
+		values := make([]any, len(names))
+		ptrs := make([]any, len(names))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+		for i, v := range values {
+			if b, ok := v.([]byte); ok {
+				v = string(b)
+			}
+			columns[i] = append(columns[i], v)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	frame := data.NewFrame(name)
+	for i, values := range columns {
+		frame.Fields = append(frame.Fields, fieldFromValues(names[i], values))
+	}
+	return frame, nil
+}
+
+func fieldFromValues(name string, values []any) *data.Field {
+	var hasNull, hasTime, hasString, hasFloat, hasBool, hasInt bool
+	for _, v := range values {
+		switch v.(type) {
+		case nil:
+			hasNull = true
+		case time.Time:
+			hasTime = true
+		case string:
+			hasString = true
+		case float64:
+			hasFloat = true
+		case bool:
+			hasBool = true
+		case int64:
+			hasInt = true
+		}
+	}
+	if hasString && !hasTime && !hasFloat && !hasBool && !hasInt && parsesAsTimes(values) {
+		hasString, hasTime = false, true
+	}
+
+	var field *data.Field
+	switch {
+	case hasTime && !hasString && !hasFloat && !hasInt && !hasBool:
+		field = data.NewFieldFromFieldType(data.FieldTypeNullableTime, len(values))
+		for i, v := range values {
+			switch v := v.(type) {
+			case time.Time:
+				field.Set(i, &v)
+			case string:
+				t, _ := parseTime(v)
+				field.Set(i, &t)
+			}
+		}
+	case hasString || hasTime:
+		field = data.NewFieldFromFieldType(data.FieldTypeNullableString, len(values))
+		for i, v := range values {
+			if v != nil {
+				s := fmt.Sprintf("%v", v)
+				field.Set(i, &s)
+			}
+		}
+	case hasFloat || (hasInt && hasBool):
+		field = data.NewFieldFromFieldType(data.FieldTypeNullableFloat64, len(values))
+		for i, v := range values {
+			var f float64
+			switch v := v.(type) {
+			case float64:
+				f = v
+			case int64:
+				f = float64(v)
+			case bool:
+				if v {
+					f = 1
+				}
+			default:
+				continue
+			}
+			field.Set(i, &f)
+		}
+	case hasInt:
+		field = data.NewFieldFromFieldType(data.FieldTypeNullableInt64, len(values))
+		for i, v := range values {
+			if v, ok := v.(int64); ok {
+				field.Set(i, &v)
+			}
+		}
+	case hasBool:
+		field = data.NewFieldFromFieldType(data.FieldTypeNullableBool, len(values))
+		for i, v := range values {
+			if v, ok := v.(bool); ok {
+				field.Set(i, &v)
+			}
+		}
+	default:
+		// only nulls, or no rows
+		field = data.NewFieldFromFieldType(data.FieldTypeNullableFloat64, len(values))
+	}
+	field.Name = name
+
+	if !hasNull {
+		if nonNullable, err := nonNullableCopy(field); err == nil {
+			return nonNullable
+		}
+	}
+	return field
+}
+
+func nonNullableCopy(field *data.Field) (*data.Field, error) {
+	out := data.NewFieldFromFieldType(field.Type().NonNullableType(), field.Len())
+	out.Name = field.Name
+	for i := 0; i < field.Len(); i++ {
+		v, ok := field.ConcreteAt(i)
+		if !ok {
+			return nil, fmt.Errorf("null value at index %d", i)
+		}
+		out.Set(i, v)
+	}
+	return out, nil
+}
+
+// parsesAsTimes is true if every string in values is a time the way the driver writes them. The declared type of
+// computed columns, such as max(time), is unknown, so the driver returns their times as strings.
+func parsesAsTimes(values []any) bool {
+	for _, v := range values {
+		s, ok := v.(string)
+		if !ok {
+			continue
+		}
+		if _, err := parseTime(s); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func parseTime(s string) (time.Time, error) {
+	return time.Parse(sqlite3.SQLiteTimestampFormats[0], s)
+}
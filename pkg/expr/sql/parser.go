@@ -1,66 +1,93 @@
 package sql
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
 	"sort"
 	"strings"
 
 	"github.com/grafana/grafana/pkg/infra/log"
-	"github.com/jeremywohl/flatten"
-	"github.com/scottlepp/go-duck/duck"
 )
 
+var logger = log.New("sql_expr")
+
+type tokenKind int
+
 const (
-	TABLE_NAME    = "table_name"
-	ERROR         = ".error"
-	ERROR_MESSAGE = ".error_message"
+	tokenWord tokenKind = iota
+	// tokenQuoted is a quoted identifier: "name", `name` or [name]
+	tokenQuoted
+	tokenString
+	tokenNumber
+	tokenSymbol
 )
 
-var logger = log.New("sql_expr")
+type token struct {
+	kind tokenKind
+	text string
+}
+
+// reserved are the keywords that can not be used as a table alias without quoting.
+var reserved = map[string]bool{
+	"ALL": true, "AND": true, "AS": true, "BETWEEN": true, "BY": true, "CASE": true, "CROSS": true, "DISTINCT": true,
+	"ELSE": true, "END": true, "EXCEPT": true, "EXISTS": true, "FETCH": true, "FROM": true, "FULL": true, "GROUP": true,
+	"HAVING": true, "IN": true, "INNER": true, "INTERSECT": true, "INTO": true, "IS": true, "JOIN": true, "LEFT": true,
+	"LIKE": true, "LIMIT": true, "NATURAL": true, "NOT": true, "NULL": true, "OFFSET": true, "ON": true, "OR": true,
+	"ORDER": true, "OUTER": true, "QUALIFY": true, "RECURSIVE": true, "RETURNING": true, "RIGHT": true, "SELECT": true,
+	"SET": true, "THEN": true, "UNION": true, "USING": true, "VALUES": true, "WHEN": true, "WHERE": true,
+	"WINDOW": true, "WITH": true,
+}
 
-// TablesList returns a list of tables for the sql statement
+func (t token) isKeyword(keywords ...string) bool {
+	if t.kind != tokenWord {
+		return false
+	}
+	for _, k := range keywords {
+		if strings.EqualFold(t.text, k) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t token) isSymbol(s string) bool {
+	return t.kind == tokenSymbol && t.text == s
+}
+
+// isIdentifier is true for quoted identifiers and for words that are not reserved keywords.
+func (t token) isIdentifier() bool {
+	return t.kind == tokenQuoted || (t.kind == tokenWord && !reserved[strings.ToUpper(t.text)])
+}
+
+// TablesList returns the sorted list of tables the sql statement reads from. Tables defined by common table
+// expressions are not part of the list. It returns an error if the statement is not a single read-only query.
 func TablesList(rawSQL string) ([]string, error) {
-	duckDB := duck.NewInMemoryDB()
-	rawSQL = strings.Replace(rawSQL, "'", "''", -1)
-	cmd := fmt.Sprintf("SELECT json_serialize_sql('%s')", rawSQL)
-	ret, err := duckDB.RunCommands([]string{cmd})
+	tokens, err := tokenize(rawSQL)
 	if err != nil {
-		logger.Error("error serializing sql", "error", err.Error(), "sql", rawSQL, "cmd", cmd)
-		return nil, fmt.Errorf("error serializing sql: %s", err.Error())
+		return nil, err
 	}
-
-	ast := []map[string]any{}
-	err = json.Unmarshal([]byte(ret), &ast)
+	tokens, err = singleStatement(tokens)
 	if err != nil {
-		logger.Error("error converting json sql to ast", "error", err.Error(), "ret", ret)
-		return nil, fmt.Errorf("error converting json to ast: %s", err.Error())
+		return nil, err
 	}
 
-	return tablesFromAST(ast)
-}
-
-// tablesFromAST returns a list of tables from the ast
-func tablesFromAST(ast []map[string]any) ([]string, error) {
-	flat, err := flatten.Flatten(ast[0], "", flatten.DotStyle)
+	ctes, err := cteNames(tokens)
 	if err != nil {
-		logger.Error("error flattening ast", "error", err.Error(), "ast", ast)
-		return nil, fmt.Errorf("error flattening ast: %s", err.Error())
+		return nil, err
 	}
 
 	tables := []string{}
-	for k, v := range flat {
-		if strings.HasSuffix(k, ERROR) {
-			v, ok := v.(bool)
-			if ok && v {
-				logger.Error("error in sql", "error", k)
-				return nil, astError(k, flat)
-			}
+	for i := 0; i < len(tokens); i++ {
+		if !tokens[i].isKeyword("FROM", "JOIN") || isFunctionArgument(tokens, i) {
+			continue
+		}
+		found, err := tablesAfter(tokens, i)
+		if err != nil {
+			return nil, err
 		}
-		if strings.Contains(k, TABLE_NAME) {
-			table, ok := v.(string)
-			if ok && !existsInList(table, tables) {
-				tables = append(tables, v.(string))
+		for _, table := range found {
+			if !ctes[strings.ToLower(table)] && !existsInList(table, tables) {
+				tables = append(tables, table)
 			}
 		}
 	}
@@ -71,13 +98,255 @@ func tablesFromAST(ast []map[string]any) ([]string, error) {
 	return tables, nil
 }
 
-func astError(k string, flat map[string]any) error {
-	key := strings.Replace(k, ERROR, "", 1)
-	message, ok := flat[key+ERROR_MESSAGE]
-	if !ok {
-		message = "unknown error in sql"
+// tablesAfter returns the tables referenced by the FROM or JOIN clause that starts at tokens[i].
+// Subqueries and parenthesized joins are skipped, they are read when TablesList reaches their tokens.
+func tablesAfter(tokens []token, i int) ([]string, error) {
+	clause := strings.ToUpper(tokens[i].text)
+	tables := []string{}
+	j := i + 1
+	for {
+		if j >= len(tokens) {
+			return nil, fmt.Errorf("error in sql: expected a table after %s", clause)
+		}
+		if tokens[j].isSymbol("(") {
+			k := j
+			for k < len(tokens) && tokens[k].isSymbol("(") {
+				k++
+			}
+			if k == len(tokens) || !tokens[k].isIdentifier() {
+				return tables, nil
+			}
+			// parenthesized join, such as JOIN (B INNER JOIN C ON ...)
+			j = k
+		}
+		if !tokens[j].isIdentifier() {
+			return nil, fmt.Errorf("error in sql: expected a table after %s, got %q", clause, tokens[j].text)
+		}
+		name := tokens[j].text
+		j++
+		for j+1 < len(tokens) && tokens[j].isSymbol(".") && tokens[j+1].isIdentifier() {
+			name += "." + tokens[j+1].text
+			j += 2
+		}
+		if j < len(tokens) && tokens[j].isSymbol("(") {
+			// table-valued function, such as json_each(...)
+			return tables, nil
+		}
+		tables = append(tables, name)
+
+		if j < len(tokens) && tokens[j].isKeyword("AS") {
+			j++
+			if j >= len(tokens) || !tokens[j].isIdentifier() {
+				return nil, fmt.Errorf("error in sql: expected an alias after AS for table %s", name)
+			}
+			j++
+		} else if j < len(tokens) && tokens[j].isIdentifier() {
+			j++
+		}
+		if j < len(tokens) && tokens[j].isIdentifier() {
+			return nil, fmt.Errorf("error in sql: unexpected %q after table %s", tokens[j].text, name)
+		}
+
+		if clause != "FROM" || j >= len(tokens) || !tokens[j].isSymbol(",") {
+			return tables, nil
+		}
+		j++
 	}
-	return fmt.Errorf("error in sql: %s", message)
+}
+
+// cteNames returns the lower case names of the tables defined by common table expressions.
+func cteNames(tokens []token) (map[string]bool, error) {
+	names := map[string]bool{}
+	for i := 0; i < len(tokens); i++ {
+		if !tokens[i].isKeyword("WITH") {
+			continue
+		}
+		j := i + 1
+		if j < len(tokens) && tokens[j].isKeyword("RECURSIVE") {
+			j++
+		}
+		for {
+			if j >= len(tokens) || !tokens[j].isIdentifier() {
+				return nil, errors.New("error in sql: expected a common table expression name after WITH")
+			}
+			name := tokens[j].text
+			names[strings.ToLower(name)] = true
+			j++
+			if j < len(tokens) && tokens[j].isSymbol("(") {
+				j = skipParens(tokens, j)
+			}
+			if j >= len(tokens) || !tokens[j].isKeyword("AS") {
+				return nil, fmt.Errorf("error in sql: expected AS after common table expression %s", name)
+			}
+			j++
+			if j < len(tokens) && tokens[j].isKeyword("NOT") {
+				j++
+			}
+			if j < len(tokens) && tokens[j].isKeyword("MATERIALIZED") {
+				j++
+			}
+			if j >= len(tokens) || !tokens[j].isSymbol("(") {
+				return nil, fmt.Errorf("error in sql: expected ( after AS in common table expression %s", name)
+			}
+			j = skipParens(tokens, j)
+			if j >= len(tokens) || !tokens[j].isSymbol(",") {
+				break
+			}
+			j++
+		}
+	}
+	return names, nil
+}
+
+// skipParens returns the index of the token after the parenthesis that closes the one at tokens[i].
+func skipParens(tokens []token, i int) int {
+	depth := 0
+	for ; i < len(tokens); i++ {
+		switch {
+		case tokens[i].isSymbol("("):
+			depth++
+		case tokens[i].isSymbol(")"):
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return i
+}
+
+// isFunctionArgument is true if tokens[i] is inside the arguments of a function call, such as
+// EXTRACT(YEAR FROM time), or follows IS DISTINCT, where FROM does not start a list of tables.
+func isFunctionArgument(tokens []token, i int) bool {
+	if i > 0 && tokens[i-1].isKeyword("DISTINCT") {
+		return true
+	}
+	depth := 0
+	for k := i - 1; k >= 0; k-- {
+		switch {
+		case tokens[k].isSymbol(")"):
+			depth++
+		case tokens[k].isSymbol("("):
+			if depth > 0 {
+				depth--
+				continue
+			}
+			return k > 0 && tokens[k-1].kind == tokenWord && !reserved[strings.ToUpper(tokens[k-1].text)]
+		}
+	}
+	return false
+}
+
+// singleStatement returns the tokens of the statement without its trailing semicolons. It returns an error if
+// there is more than one statement or if the statement does not read data.
+func singleStatement(tokens []token) ([]token, error) {
+	for len(tokens) > 0 && tokens[len(tokens)-1].isSymbol(";") {
+		tokens = tokens[:len(tokens)-1]
+	}
+	if len(tokens) == 0 {
+		return nil, errors.New("error in sql: empty statement")
+	}
+	for _, t := range tokens {
+		if t.isSymbol(";") {
+			return nil, errors.New("error in sql: only one statement is supported")
+		}
+	}
+	first := 0
+	for first < len(tokens) && tokens[first].isSymbol("(") {
+		first++
+	}
+	if first == len(tokens) || !tokens[first].isKeyword("SELECT", "WITH", "VALUES") {
+		return nil, fmt.Errorf("error in sql: only SELECT statements are supported, got %q", tokens[min(first, len(tokens)-1)].text)
+	}
+	return tokens, nil
+}
+
+// tokenize splits the sql statement into tokens. Comments are dropped.
+func tokenize(rawSQL string) ([]token, error) {
+	tokens := []token{}
+	s := rawSQL
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case strings.HasPrefix(s[i:], "--"):
+			end := strings.IndexByte(s[i:], '\n')
+			if end < 0 {
+				return tokens, nil
+			}
+			i += end + 1
+		case strings.HasPrefix(s[i:], "/*"):
+			end := strings.Index(s[i+2:], "*/")
+			if end < 0 {
+				return nil, errors.New("error in sql: unterminated comment")
+			}
+			i += end + 4
+		case c == '\'':
+			end, err := closingQuote(s, i, '\'')
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenString, text: strings.ReplaceAll(s[i+1:end], "''", "'")})
+			i = end + 1
+		case c == '"' || c == '`' || c == '[':
+			closing := c
+			if c == '[' {
+				closing = ']'
+			}
+			end, err := closingQuote(s, i, closing)
+			if err != nil {
+				return nil, err
+			}
+			text := s[i+1 : end]
+			if c != '[' {
+				text = strings.ReplaceAll(text, string([]byte{c, c}), string(c))
+			}
+			tokens = append(tokens, token{kind: tokenQuoted, text: text})
+			i = end + 1
+		case isWordStart(c):
+			j := i + 1
+			for j < len(s) && isWordPart(s[j]) {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenWord, text: s[i:j]})
+			i = j
+		case c >= '0' && c <= '9':
+			j := i + 1
+			for j < len(s) && (isWordPart(s[j]) || s[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: s[i:j]})
+			i = j
+		default:
+			tokens = append(tokens, token{kind: tokenSymbol, text: string(c)})
+			i++
+		}
+	}
+	return tokens, nil
+}
+
+// closingQuote returns the index of the quote that closes the one at s[start]. Doubled quotes are escapes.
+func closingQuote(s string, start int, quote byte) (int, error) {
+	for i := start + 1; i < len(s); i++ {
+		if s[i] != quote {
+			continue
+		}
+		if quote != ']' && i+1 < len(s) && s[i+1] == quote {
+			i++
+			continue
+		}
+		return i, nil
+	}
+	return 0, fmt.Errorf("error in sql: unterminated quote %c", s[start])
+}
+
+func isWordStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= 0x80
+}
+
+func isWordPart(c byte) bool {
+	return isWordStart(c) || c == '$' || (c >= '0' && c <= '9')
 }
 
 func existsInList(table string, list []string) bool {
@@ -4,10 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
-	"github.com/scottlepp/go-duck/duck"
 
 	"github.com/grafana/grafana/pkg/apimachinery/errutil"
 	"github.com/grafana/grafana/pkg/expr/mathexp"
@@ -15,6 +15,11 @@ import (
 	"github.com/grafana/grafana/pkg/infra/tracing"
 )
 
+const (
+	sqlTimeColumn  = "time"
+	sqlValueColumn = "value"
+)
+
 // SQLCommand is an expression to run SQL over results
 type SQLCommand struct {
 	query       string
@@ -32,8 +37,8 @@ func NewSQLCommand(refID, rawSQL string) (*SQLCommand, error) {
 	if err != nil {
 		logger.Warn("invalid sql query", "sql", rawSQL, "error", err)
 		return nil, errutil.BadRequest("sql-invalid-sql",
-			errutil.WithPublicMessage("error reading SQL command"),
-		)
+			errutil.WithPublicMessage(fmt.Sprintf("error reading SQL command: %s", err)),
+		).Errorf("%w", err)
 	}
 	if len(tables) == 0 {
 		logger.Warn("no tables found in SQL query", "sql", rawSQL)
@@ -77,6 +82,10 @@ func (gr *SQLCommand) NeedsVars() []string {
 
 // Execute runs the command and returns the results or an error if the command
 // failed to execute.
+//
+// Each variable is loaded as a table named after its refID. Series are loaded in long format with a time column,
+// a value column and one column per label, numbers with a value column and one column per label, and tables as they
+// are. The result is converted to numbers or series when it has that shape, otherwise it is returned as a table.
 func (gr *SQLCommand) Execute(ctx context.Context, now time.Time, vars mathexp.Vars, tracer tracing.Tracer) (mathexp.Results, error) {
 	_, span := tracer.Start(ctx, "SSE.ExecuteSQL")
 	defer span.End()
@@ -88,17 +97,13 @@ func (gr *SQLCommand) Execute(ctx context.Context, now time.Time, vars mathexp.V
 			logger.Warn("no results found for", "ref", ref)
 			continue
 		}
-		frames := results.Values.AsDataFrames(ref)
-		allFrames = append(allFrames, frames...)
+		allFrames = append(allFrames, valuesToTables(ref, results.Values)...)
 	}
 
 	rsp := mathexp.Results{}
 
-	duckDB := duck.NewInMemoryDB()
-	var frame = &data.Frame{}
-
 	logger.Debug("Executing query", "query", gr.query, "frames", len(allFrames))
-	err := duckDB.QueryFramesInto(gr.refID, gr.query, allFrames, frame)
+	frame, err := sql.QueryFrames(ctx, gr.refID, gr.query, allFrames)
 	if err != nil {
 		logger.Error("Failed to query frames", "error", err.Error())
 		rsp.Error = err
@@ -112,15 +117,163 @@ func (gr *SQLCommand) Execute(ctx context.Context, now time.Time, vars mathexp.V
 		rsp.Values = mathexp.Values{
 			mathexp.NoData{Frame: frame},
 		}
+		return rsp, nil
 	}
 
-	rsp.Values = mathexp.Values{
-		mathexp.TableData{Frame: frame},
+	rsp.Values, err = tableToValues(gr.refID, frame)
+	if err != nil {
+		rsp.Error = err
 	}
-
 	return rsp, nil
 }
 
 func (gr *SQLCommand) Type() string {
 	return TypeSQL.String()
 }
+
+// valuesToTables converts the values of a variable to frames that are loaded in the table named refID.
+func valuesToTables(refID string, values mathexp.Values) []*data.Frame {
+	frames := make([]*data.Frame, 0, len(values))
+	for _, v := range values {
+		var frame *data.Frame
+		switch v := v.(type) {
+		case mathexp.Series:
+			fields := data.Fields{
+				data.NewField(sqlTimeColumn, nil, make([]time.Time, v.Len())),
+				data.NewField(sqlValueColumn, nil, make([]*float64, v.Len())),
+			}
+			for i := 0; i < v.Len(); i++ {
+				t, f := v.GetPoint(i)
+				fields[0].Set(i, t)
+				fields[1].Set(i, f)
+			}
+			frame = data.NewFrame("", append(fields, labelFields(v.GetLabels(), v.Len())...)...)
+		case mathexp.Number:
+			frame = data.NewFrame("", append(data.Fields{
+				data.NewField(sqlValueColumn, nil, []*float64{v.GetFloat64Value()}),
+			}, labelFields(v.GetLabels(), 1)...)...)
+		case mathexp.Scalar:
+			frame = data.NewFrame("", data.NewField(sqlValueColumn, nil, []*float64{v.GetFloat64Value()}))
+		case mathexp.TableData:
+			if v.Frame == nil {
+				continue
+			}
+			frame = v.Frame
+		case mathexp.NoData:
+			frame = data.NewFrame("", data.NewField(sqlValueColumn, nil, []*float64{}))
+		default:
+			continue
+		}
+		frame.RefID = refID
+		frames = append(frames, frame)
+	}
+	return frames
+}
+
+func labelFields(labels data.Labels, rows int) data.Fields {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	fields := make(data.Fields, 0, len(labels))
+	for _, k := range keys {
+		values := make([]string, rows)
+		for i := range values {
+			values[i] = labels[k]
+		}
+		fields = append(fields, data.NewField(k, nil, values))
+	}
+	return fields
+}
+
+// tableToValues converts the result of the SQL query to the values of the expression:
+//   - numbers if the frame has exactly one numeric column and all other columns are strings,
+//   - series if the frame has exactly one time column, exactly one numeric column and all other columns are strings,
+//   - a table otherwise.
+//
+// The string columns are the labels of the numbers and series. Each set of labels must be unique for numbers.
+func tableToValues(refID string, frame *data.Frame) (mathexp.Values, error) {
+	timeIdx, valueIdx := -1, -1
+	labelIdxs := []int{}
+	for i, field := range frame.Fields {
+		ft := field.Type()
+		switch {
+		case ft.Time() && timeIdx == -1:
+			timeIdx = i
+		case ft.Numeric() && valueIdx == -1:
+			valueIdx = i
+		case ft == data.FieldTypeString || ft == data.FieldTypeNullableString:
+			labelIdxs = append(labelIdxs, i)
+		default:
+			return mathexp.Values{mathexp.TableData{Frame: frame}}, nil
+		}
+	}
+	if valueIdx == -1 {
+		return mathexp.Values{mathexp.TableData{Frame: frame}}, nil
+	}
+
+	labelsAt := func(row int) data.Labels {
+		if len(labelIdxs) == 0 {
+			return nil
+		}
+		labels := data.Labels{}
+		for _, idx := range labelIdxs {
+			if v, ok := frame.Fields[idx].ConcreteAt(row); ok {
+				labels[frame.Fields[idx].Name] = v.(string)
+			}
+		}
+		return labels
+	}
+	valueAt := func(row int) *float64 {
+		if _, ok := frame.Fields[valueIdx].ConcreteAt(row); !ok {
+			return nil
+		}
+		f, err := frame.Fields[valueIdx].FloatAt(row)
+		if err != nil {
+			return nil
+		}
+		return &f
+	}
+
+	if timeIdx == -1 {
+		values := make(mathexp.Values, 0, frame.Rows())
+		seen := map[string]bool{}
+		for row := 0; row < frame.Rows(); row++ {
+			labels := labelsAt(row)
+			if seen[labels.String()] {
+				return nil, fmt.Errorf("sql expression %s returned more than one number with labels %s, add the columns that tell them apart to the result or group by them", refID, labels)
+			}
+			seen[labels.String()] = true
+			n := mathexp.NewNumber(refID, labels)
+			n.SetValue(valueAt(row))
+			values = append(values, n)
+		}
+		return values, nil
+	}
+
+	order := []string{}
+	series := map[string]mathexp.Series{}
+	for row := 0; row < frame.Rows(); row++ {
+		t, ok := frame.Fields[timeIdx].ConcreteAt(row)
+		if !ok {
+			return nil, fmt.Errorf("sql expression %s returned a row without time at index %d", refID, row)
+		}
+		labels := labelsAt(row)
+		key := labels.String()
+		s, ok := series[key]
+		if !ok {
+			s = mathexp.NewSeries(refID, labels, 0)
+			order = append(order, key)
+		}
+		s.AppendPoint(t.(time.Time), valueAt(row))
+		series[key] = s
+	}
+	values := make(mathexp.Values, 0, len(order))
+	for _, key := range order {
+		s := series[key]
+		s.SortByTime(false)
+		values = append(values, s)
+	}
+	return values, nil
+}
@@ -1,12 +1,20 @@
 package expr
 
 import (
+	"context"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/util"
 )
 
 func TestNewCommand(t *testing.T) {
-	t.Skip()
 	cmd, err := NewSQLCommand("a", "select a from foo, bar")
 	if err != nil && strings.Contains(err.Error(), "feature is not enabled") {
 		return
@@ -25,3 +33,103 @@ func TestNewCommand(t *testing.T) {
 		return
 	}
 }
+
+func TestNewCommandUnsupportedSyntax(t *testing.T) {
+	_, err := NewSQLCommand("a", "DROP TABLE A")
+	require.ErrorContains(t, err, "only SELECT statements are supported")
+}
+
+func TestSQLCommandExecute(t *testing.T) {
+	makeSeries := func(refID string, labels data.Labels, values ...float64) mathexp.Series {
+		s := mathexp.NewSeries(refID, labels, len(values))
+		for i, v := range values {
+			s.SetPoint(i, time.Unix(int64(i*60), 0), util.Pointer(v))
+		}
+		return s
+	}
+	makeNumber := func(refID string, labels data.Labels, value float64) mathexp.Number {
+		n := mathexp.NewNumber(refID, labels)
+		n.SetValue(util.Pointer(value))
+		return n
+	}
+	vars := mathexp.Vars{
+		"A": mathexp.Results{Values: mathexp.Values{
+			makeSeries("A", data.Labels{"host": "a", "dc": "east"}, 1, 2, 3),
+			makeSeries("A", data.Labels{"host": "b", "dc": "west"}, 10, 20, 30),
+		}},
+		"B": mathexp.Results{Values: mathexp.Values{
+			makeNumber("B", data.Labels{"host": "a"}, 2),
+			makeNumber("B", data.Labels{"host": "b"}, 25),
+		}},
+	}
+
+	execute := func(t *testing.T, query string) mathexp.Results {
+		t.Helper()
+		cmd, err := NewSQLCommand("C", query)
+		require.NoError(t, err)
+		res, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		return res
+	}
+
+	t.Run("group by returns numbers labeled by the string columns", func(t *testing.T) {
+		res := execute(t, `SELECT host, sum(value) AS total FROM A GROUP BY host ORDER BY host`)
+		require.NoError(t, res.Error)
+		require.Len(t, res.Values, 2)
+		require.Equal(t, data.Labels{"host": "a"}, res.Values[0].GetLabels())
+		require.Equal(t, 6.0, *res.Values[0].(mathexp.Number).GetFloat64Value())
+		require.Equal(t, data.Labels{"host": "b"}, res.Values[1].GetLabels())
+		require.Equal(t, 60.0, *res.Values[1].(mathexp.Number).GetFloat64Value())
+	})
+
+	t.Run("join across refIDs with a CTE", func(t *testing.T) {
+		res := execute(t, `
+			WITH latest AS (SELECT host, value FROM A WHERE time = (SELECT max(time) FROM A))
+			SELECT latest.host, latest.value - B.value AS diff
+			FROM latest JOIN B ON B.host = latest.host
+			ORDER BY latest.host`)
+		require.NoError(t, res.Error)
+		require.Len(t, res.Values, 2)
+		require.Equal(t, data.Labels{"host": "a"}, res.Values[0].GetLabels())
+		require.Equal(t, 1.0, *res.Values[0].(mathexp.Number).GetFloat64Value())
+		require.Equal(t, 5.0, *res.Values[1].(mathexp.Number).GetFloat64Value())
+	})
+
+	t.Run("window function returns series", func(t *testing.T) {
+		res := execute(t, `
+			SELECT time, host, sum(value) OVER (PARTITION BY host ORDER BY time) AS running
+			FROM A ORDER BY time DESC`)
+		require.NoError(t, res.Error)
+		require.Len(t, res.Values, 2)
+		s, ok := res.Values[0].(mathexp.Series)
+		require.True(t, ok)
+		require.Equal(t, data.Labels{"host": "a"}, s.GetLabels())
+		require.Equal(t, 3, s.Len())
+		require.Equal(t, time.Unix(0, 0).UTC(), s.GetTime(0).UTC())
+		require.Equal(t, 1.0, *s.GetValue(0))
+		require.Equal(t, 6.0, *s.GetValue(2))
+	})
+
+	t.Run("several numeric columns return a table", func(t *testing.T) {
+		res := execute(t, `SELECT host, min(value), max(value) FROM A GROUP BY host`)
+		require.NoError(t, res.Error)
+		require.Len(t, res.Values, 1)
+		require.IsType(t, mathexp.TableData{}, res.Values[0])
+	})
+
+	t.Run("duplicate labels are an error", func(t *testing.T) {
+		res := execute(t, `SELECT host, value FROM A`)
+		require.ErrorContains(t, res.Error, "more than one number")
+	})
+
+	t.Run("no rows is no data", func(t *testing.T) {
+		res := execute(t, `SELECT host, value FROM B WHERE value > 100`)
+		require.NoError(t, res.Error)
+		require.True(t, res.IsNoData())
+	})
+
+	t.Run("errors from the engine are returned", func(t *testing.T) {
+		res := execute(t, `SELECT missing FROM A`)
+		require.ErrorContains(t, res.Error, "no such column: missing")
+	})
+}
@@ -0,0 +1,302 @@
+package expr
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+// TraceRefID is the key of the response that holds the trace of a pipeline executed in debug mode.
+const TraceRefID = "_trace"
+
+// maxTraceLabelSets limits the number of label sets that are kept for the inputs and output of a node.
+const maxTraceLabelSets = 100
+
+// Trace is a record of the execution of each node of a pipeline, in execution order.
+// It is collected when the context given to the pipeline carries one, see WithTrace.
+type Trace struct {
+	mtx   sync.Mutex
+	Nodes []*NodeTrace `json:"nodes"`
+}
+
+// NodeTrace is the execution record of a single node.
+type NodeTrace struct {
+	RefID    string `json:"refId"`
+	NodeType string `json:"nodeType"`
+	// Kind is the command type for expressions and the data source type for queries.
+	Kind        string            `json:"kind,omitempty"`
+	Inputs      []TraceValues     `json:"inputs,omitempty"`
+	Output      TraceValues       `json:"output"`
+	Start       time.Time         `json:"start"`
+	DurationMs  float64           `json:"durationMs"`
+	Error       string            `json:"error,omitempty"`
+	Dropped     []TraceDrop       `json:"dropped,omitempty"`
+	Conversions []TraceConversion `json:"conversions,omitempty"`
+}
+
+// TraceValues describes the results of a node.
+type TraceValues struct {
+	RefID string `json:"refId"`
+	// Type is the type of the values, or "mixed" if they are not all of the same type.
+	Type  string `json:"type"`
+	Count int    `json:"count"`
+	// Labels are the label sets of the values, up to the first 100.
+	Labels []string `json:"labels,omitempty"`
+	Error  string   `json:"error,omitempty"`
+}
+
+// TraceDrop describes values that were discarded while executing a node.
+type TraceDrop struct {
+	Reason string   `json:"reason"`
+	Labels []string `json:"labels,omitempty"`
+}
+
+// TraceConversion describes a change of the type of data made by a node.
+type TraceConversion struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type traceKey struct{}
+
+// WithTrace returns a context that makes the pipelines executed with it record their execution to t.
+func WithTrace(ctx context.Context, t *Trace) context.Context {
+	return context.WithValue(ctx, traceKey{}, t)
+}
+
+// TraceFromContext returns the trace carried by ctx, or nil if there is none.
+func TraceFromContext(ctx context.Context) *Trace {
+	t, _ := ctx.Value(traceKey{}).(*Trace)
+	return t
+}
+
+// node returns the record of the node with the given refID, creating it if needed.
+// The caller must hold the lock.
+func (t *Trace) node(refID string) *NodeTrace {
+	for _, n := range t.Nodes {
+		if n.RefID == refID {
+			return n
+		}
+	}
+	n := &NodeTrace{RefID: refID}
+	t.Nodes = append(t.Nodes, n)
+	return n
+}
+
+// Node returns a copy of the record of the node with the given refID, and whether it exists.
+func (t *Trace) Node(refID string) (NodeTrace, bool) {
+	if t == nil {
+		return NodeTrace{}, false
+	}
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	for _, n := range t.Nodes {
+		if n.RefID == refID {
+			return *n, true
+		}
+	}
+	return NodeTrace{}, false
+}
+
+// startNode records the start of the execution of node.
+func (t *Trace) startNode(node Node, vars mathexp.Vars, start time.Time) {
+	if t == nil {
+		return
+	}
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	n := t.node(node.RefID())
+	n.NodeType = node.NodeType().String()
+	n.Kind = nodeKind(node)
+	n.Start = start
+	n.Inputs = nil
+	for _, refID := range node.NeedsVars() {
+		if res, ok := vars[refID]; ok {
+			n.Inputs = append(n.Inputs, traceValues(refID, res))
+		}
+	}
+}
+
+// endNode records the results of node, and the conversion of the type of its inputs if there is one.
+func (t *Trace) endNode(refID string, res mathexp.Results, end time.Time) {
+	if t == nil {
+		return
+	}
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	n := t.node(refID)
+	n.Output = traceValues(refID, res)
+	if res.Error != nil {
+		n.Error = res.Error.Error()
+	}
+	if !n.Start.IsZero() {
+		n.DurationMs = float64(end.Sub(n.Start).Nanoseconds()) / float64(time.Millisecond)
+	}
+	if res.Error != nil || n.Output.Count == 0 {
+		return
+	}
+	for _, in := range n.Inputs {
+		if in.Error == "" && in.Count > 0 && in.Type != n.Output.Type {
+			n.Conversions = append(n.Conversions, TraceConversion{From: in.Type, To: n.Output.Type})
+			break
+		}
+	}
+}
+
+// addConversion records that the data of the node with the given refID was converted.
+func (t *Trace) addConversion(refID, from, to string) {
+	if t == nil {
+		return
+	}
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	n := t.node(refID)
+	n.Conversions = append(n.Conversions, TraceConversion{From: from, To: to})
+}
+
+// addDrop records that the values with the given labels were discarded by the node with the given refID.
+func (t *Trace) addDrop(refID, reason string, labels []data.Labels) {
+	if t == nil {
+		return
+	}
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	n := t.node(refID)
+	drop := TraceDrop{Reason: reason}
+	for _, l := range labels {
+		drop.Labels = append(drop.Labels, l.String())
+	}
+	n.Dropped = append(n.Dropped, drop)
+}
+
+// addMathDrops records the values dropped from the unions of binary operations of a math expression.
+func (t *Trace) addMathDrops(refID string, drops map[string]map[string][]data.Labels) {
+	if t == nil {
+		return
+	}
+	binaryNodes := make([]string, 0, len(drops))
+	for text := range drops {
+		binaryNodes = append(binaryNodes, text)
+	}
+	sort.Strings(binaryNodes)
+	for _, text := range binaryNodes {
+		sides := make([]string, 0, len(drops[text]))
+		for side := range drops[text] {
+			sides = append(sides, side)
+		}
+		sort.Strings(sides)
+		for _, side := range sides {
+			t.addDrop(refID, fmt.Sprintf("no matching labels in %s for values of %s", text, side), drops[text][side])
+		}
+	}
+}
+
+// Frame returns the trace as a frame with one row per node. The full trace is in the custom metadata of the frame.
+func (t *Trace) Frame() *data.Frame {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	refIDs := make([]string, 0, len(t.Nodes))
+	nodeTypes := make([]string, 0, len(t.Nodes))
+	kinds := make([]string, 0, len(t.Nodes))
+	outputTypes := make([]string, 0, len(t.Nodes))
+	outputCounts := make([]int64, 0, len(t.Nodes))
+	durations := make([]float64, 0, len(t.Nodes))
+	errs := make([]string, 0, len(t.Nodes))
+	for _, n := range t.Nodes {
+		refIDs = append(refIDs, n.RefID)
+		nodeTypes = append(nodeTypes, n.NodeType)
+		kinds = append(kinds, n.Kind)
+		outputTypes = append(outputTypes, n.Output.Type)
+		outputCounts = append(outputCounts, int64(n.Output.Count))
+		durations = append(durations, n.DurationMs)
+		errs = append(errs, n.Error)
+	}
+	return data.NewFrame("trace",
+		data.NewField("refId", nil, refIDs),
+		data.NewField("nodeType", nil, nodeTypes),
+		data.NewField("kind", nil, kinds),
+		data.NewField("outputType", nil, outputTypes),
+		data.NewField("outputCount", nil, outputCounts),
+		data.NewField("durationMs", nil, durations),
+		data.NewField("error", nil, errs),
+	).SetMeta(&data.FrameMeta{Custom: t})
+}
+
+// AddToResponse adds the trace to res under TraceRefID.
+func (t *Trace) AddToResponse(res *backend.QueryDataResponse) {
+	if t == nil || res == nil {
+		return
+	}
+	res.Responses[TraceRefID] = backend.DataResponse{Frames: data.Frames{t.Frame()}}
+}
+
+func nodeKind(node Node) string {
+	switch n := node.(type) {
+	case *CMDNode:
+		return n.CMDType.String()
+	case *DSNode:
+		if n.datasource != nil {
+			return n.datasource.Type
+		}
+	case *MLNode:
+		return n.command.Type()
+	}
+	return ""
+}
+
+func traceValues(refID string, res mathexp.Results) TraceValues {
+	tv := TraceValues{RefID: refID, Count: len(res.Values)}
+	if res.Error != nil {
+		tv.Type = "error"
+		tv.Error = res.Error.Error()
+		return tv
+	}
+	for _, v := range res.Values {
+		if _, ok := v.(mathexp.NoData); ok {
+			tv.Count--
+			continue
+		}
+		switch valueType := v.Type().String(); tv.Type {
+		case "":
+			tv.Type = valueType
+		case valueType:
+		default:
+			tv.Type = "mixed"
+		}
+		switch v.(type) {
+		case mathexp.Series, mathexp.Number:
+			if len(tv.Labels) < maxTraceLabelSets {
+				tv.Labels = append(tv.Labels, v.GetLabels().String())
+			}
+		}
+	}
+	if tv.Type == "" {
+		tv.Type = "noData"
+	}
+	return tv
+}
+
+// framesDescription describes the frames returned by a data source, like "2 frames (timeseries-multi)".
+func framesDescription(frames data.Frames) string {
+	kinds := []string{}
+	seen := map[string]bool{}
+	for _, frame := range frames {
+		kind := frame.TimeSeriesSchema().Type.String()
+		if frame.Meta != nil && frame.Meta.Type != "" {
+			kind = string(frame.Meta.Type)
+		}
+		if !seen[kind] {
+			seen[kind] = true
+			kinds = append(kinds, kind)
+		}
+	}
+	return fmt.Sprintf("%d frames (%s)", len(frames), strings.Join(kinds, ", "))
+}
@@ -0,0 +1,138 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	datafakes "github.com/grafana/grafana/pkg/services/datasources/fakes"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginconfig"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/plugincontext"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestTrace(t *testing.T) {
+	seriesFrame := func(host string, v float64) *data.Frame {
+		return data.NewFrame("",
+			data.NewField("time", nil, []time.Time{time.Unix(1, 0)}),
+			data.NewField("value", data.Labels{"host": host}, []*float64{fp(v)}))
+	}
+	me := &mockEndpoint{
+		Responses: map[string]backend.DataResponse{
+			"A": {Frames: data.Frames{seriesFrame("a", 1), seriesFrame("b", 2)}},
+			"B": {Frames: data.Frames{seriesFrame("a", 10), seriesFrame("c", 20)}},
+		},
+	}
+
+	pCtxProvider := plugincontext.ProvideService(setting.NewCfg(), nil, &pluginstore.FakePluginStore{
+		PluginList: []pluginstore.Plugin{
+			{JSONData: plugins.JSONData{ID: "test"}},
+		},
+	}, &datafakes.FakeCacheService{}, &datafakes.FakeDataSourceService{}, nil, pluginconfig.NewFakePluginRequestConfigProvider())
+
+	cfg := setting.NewCfg()
+	cfg.ExpressionsEnabled = true
+	features := featuremgmt.WithFeatures()
+	s := Service{
+		cfg:          cfg,
+		dataService:  me,
+		pCtxProvider: pCtxProvider,
+		features:     features,
+		tracer:       tracing.InitializeTracerForTest(),
+		metrics:      newMetrics(nil),
+		converter: &ResultConverter{
+			Features: features,
+			Tracer:   tracing.InitializeTracerForTest(),
+		},
+	}
+
+	dsQuery := func(refID string) Query {
+		return Query{
+			RefID:      refID,
+			DataSource: &datasources.DataSource{OrgID: 1, UID: "test", Type: "test"},
+			JSON:       json.RawMessage(`{ "datasource": { "uid": "test" }, "intervalMs": 1000, "maxDataPoints": 1000 }`),
+			TimeRange:  AbsoluteTimeRange{},
+		}
+	}
+	queries := []Query{
+		dsQuery("A"),
+		dsQuery("B"),
+		{
+			RefID:      "C",
+			DataSource: dataSourceModel(),
+			JSON:       json.RawMessage(`{ "datasource": { "uid": "__expr__", "type": "__expr__"}, "type": "math", "expression": "$A + $B" }`),
+		},
+		{
+			RefID:      "D",
+			DataSource: dataSourceModel(),
+			JSON:       json.RawMessage(`{ "datasource": { "uid": "__expr__", "type": "__expr__"}, "type": "reduce", "expression": "C", "reducer": "last" }`),
+		},
+	}
+
+	t.Run("no trace without debug", func(t *testing.T) {
+		res, err := s.TransformData(context.Background(), time.Now(), &Request{Queries: queries, User: &user.SignedInUser{}})
+		require.NoError(t, err)
+		require.NotContains(t, res.Responses, TraceRefID)
+	})
+
+	t.Run("debug adds a trace of each node", func(t *testing.T) {
+		res, err := s.TransformData(context.Background(), time.Now(), &Request{Queries: queries, User: &user.SignedInUser{}, Debug: true})
+		require.NoError(t, err)
+		require.Contains(t, res.Responses, TraceRefID)
+
+		frame := res.Responses[TraceRefID].Frames[0]
+		require.Equal(t, 4, frame.Rows())
+		trace, ok := frame.Meta.Custom.(*Trace)
+		require.True(t, ok)
+
+		a, ok := trace.Node("A")
+		require.True(t, ok)
+		require.Equal(t, "Datasource", a.NodeType)
+		require.Equal(t, "test", a.Kind)
+		require.Equal(t, TraceValues{RefID: "A", Type: "seriesSet", Count: 2, Labels: []string{"host=a", "host=b"}}, a.Output)
+		require.Equal(t, []TraceConversion{{From: "2 frames (wide)", To: "multi frame series"}}, a.Conversions)
+
+		c, ok := trace.Node("C")
+		require.True(t, ok)
+		require.Equal(t, "Expression", c.NodeType)
+		require.Equal(t, "math", c.Kind)
+		require.Len(t, c.Inputs, 2)
+		require.Equal(t, []string{"host=a"}, c.Output.Labels)
+		require.Equal(t, []TraceDrop{
+			{Reason: "no matching labels in $A + $B for values of $A", Labels: []string{"host=b"}},
+			{Reason: "no matching labels in $A + $B for values of $B", Labels: []string{"host=c"}},
+		}, c.Dropped)
+		require.Empty(t, c.Conversions)
+
+		d, ok := trace.Node("D")
+		require.True(t, ok)
+		require.Equal(t, "reduce", d.Kind)
+		require.Equal(t, TraceValues{RefID: "D", Type: "numberSet", Count: 1, Labels: []string{"host=a"}}, d.Output)
+		require.Equal(t, []TraceConversion{{From: "seriesSet", To: "numberSet"}}, d.Conversions)
+	})
+
+	t.Run("nodes that are not executed because of an error are traced", func(t *testing.T) {
+		me.Responses["A"] = backend.DataResponse{Error: errors.New("womp womp")}
+		res, err := s.TransformData(context.Background(), time.Now(), &Request{Queries: queries, User: &user.SignedInUser{}, Debug: true})
+		require.NoError(t, err)
+
+		trace := res.Responses[TraceRefID].Frames[0].Meta.Custom.(*Trace)
+		c, ok := trace.Node("C")
+		require.True(t, ok)
+		require.NotEmpty(t, c.Error)
+		require.Equal(t, "error", c.Output.Type)
+		require.Equal(t, "error", c.Inputs[0].Type)
+	})
+}
@@ -15,6 +15,7 @@ import (
 // Request is similar to plugins.DataQuery but with the Time Ranges is per Query.
 type Request struct {
 	Headers map[string]string
+	// Debug adds a Trace of the execution of the pipeline to the response, under TraceRefID.
 	Debug   bool
 	OrgId   int64
 	Queries []Query
@@ -93,6 +94,13 @@ func (s *Service) TransformData(ctx context.Context, now time.Time, req *Request
 		return nil, err
 	}
 
+	// In debug mode, record how each node is executed and return it with the responses
+	var trace *Trace
+	if req.Debug {
+		trace = &Trace{}
+		ctx = WithTrace(ctx, trace)
+	}
+
 	// Execute the pipeline
 	responses, err := s.ExecutePipeline(ctx, now, pipeline)
 	if err != nil {
@@ -116,6 +124,7 @@ func (s *Service) TransformData(ctx context.Context, now time.Time, req *Request
 		responses = filteredRes
 	}
 
+	trace.AddToResponse(responses)
 	return responses, nil
 }
 
@@ -21,6 +21,8 @@ import (
 	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
 	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
 	"github.com/grafana/grafana/pkg/services/ngalert/image"
+	"github.com/grafana/grafana/pkg/services/ngalert/state/historian"
+	"github.com/grafana/grafana/pkg/services/ngalert/writer"
 	"github.com/grafana/grafana/pkg/services/queryhistory"
 	"github.com/grafana/grafana/pkg/services/shorturls"
 	tempuser "github.com/grafana/grafana/pkg/services/temp_user"
@@ -101,6 +103,8 @@ func (srv *CleanUpService) clean(ctx context.Context) {
 		{"delete expired snapshots", srv.deleteExpiredSnapshots},
 		{"delete expired dashboard versions", srv.deleteExpiredDashboardVersions},
 		{"delete expired images", srv.deleteExpiredImages},
+		{"delete expired alert state history", srv.deleteExpiredAlertStateHistory},
+		{"delete expired recording rule samples", srv.deleteExpiredRecordingRuleSamples},
 		{"cleanup old annotations", srv.cleanUpOldAnnotations},
 		{"expire old user invites", srv.expireOldUserInvites},
 		{"delete stale short URLs", srv.deleteStaleShortURLs},
@@ -228,6 +232,32 @@ func (srv *CleanUpService) deleteExpiredImages(ctx context.Context) {
 	}
 }
 
+func (srv *CleanUpService) deleteExpiredAlertStateHistory(ctx context.Context) {
+	logger := srv.log.FromContext(ctx)
+	maxAge := srv.Cfg.UnifiedAlerting.StateHistory.SQLMaxAge
+	if !srv.Cfg.UnifiedAlerting.IsEnabled() || maxAge <= 0 {
+		return
+	}
+	if rowsAffected, err := historian.DeleteExpiredStateHistory(ctx, srv.store, time.Now().Add(-maxAge)); err != nil {
+		logger.Error("Failed to delete expired alert state history", "error", err.Error())
+	} else {
+		logger.Debug("Deleted expired alert state history", "rows affected", rowsAffected)
+	}
+}
+
+func (srv *CleanUpService) deleteExpiredRecordingRuleSamples(ctx context.Context) {
+	logger := srv.log.FromContext(ctx)
+	maxAge := srv.Cfg.UnifiedAlerting.RecordingRules.LocalMaxAge
+	if !srv.Cfg.UnifiedAlerting.IsEnabled() || maxAge <= 0 {
+		return
+	}
+	if rowsAffected, err := writer.DeleteExpiredLocalSamples(ctx, srv.store, time.Now().Add(-maxAge)); err != nil {
+		logger.Error("Failed to delete expired recording rule samples", "error", err.Error())
+	} else {
+		logger.Debug("Deleted expired recording rule samples", "rows affected", rowsAffected)
+	}
+}
+
 func (srv *CleanUpService) expireOldUserInvites(ctx context.Context) {
 	logger := srv.log.FromContext(ctx)
 	maxInviteLifetime := srv.Cfg.UserInviteMaxLifetime
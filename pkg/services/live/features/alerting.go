@@ -0,0 +1,45 @@
+package features
+
+import (
+	"context"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/live/model"
+)
+
+// AlertingHandler serves `grafana/alerting/*` channels. Only Grafana publishes to them.
+type AlertingHandler struct {
+	AccessControl accesscontrol.AccessControl
+}
+
+// GetHandlerForPath called on init
+func (h *AlertingHandler) GetHandlerForPath(_ string) (model.ChannelHandler, error) {
+	return h, nil
+}
+
+// OnSubscribe lets users who can read all alert rules and their instances subscribe to the state changes of alert instances.
+func (h *AlertingHandler) OnSubscribe(ctx context.Context, user identity.Requester, e model.SubscribeEvent) (model.SubscribeReply, backend.SubscribeStreamStatus, error) {
+	if e.Path != "events" {
+		return model.SubscribeReply{}, backend.SubscribeStreamStatusNotFound, nil
+	}
+	ok, err := h.AccessControl.Evaluate(ctx, user, accesscontrol.EvalAll(
+		accesscontrol.EvalPermission(accesscontrol.ActionAlertingInstanceRead),
+		accesscontrol.EvalPermission(accesscontrol.ActionAlertingRuleRead, dashboards.ScopeFoldersAll),
+	))
+	if err != nil {
+		return model.SubscribeReply{}, backend.SubscribeStreamStatusPermissionDenied, err
+	}
+	if !ok {
+		return model.SubscribeReply{}, backend.SubscribeStreamStatusPermissionDenied, nil
+	}
+	return model.SubscribeReply{}, backend.SubscribeStreamStatusOK, nil
+}
+
+// OnPublish does not let clients publish to the channels
+func (h *AlertingHandler) OnPublish(_ context.Context, _ identity.Requester, _ model.PublishEvent) (model.PublishReply, backend.PublishStreamStatus, error) {
+	return model.PublishReply{}, backend.PublishStreamStatusPermissionDenied, nil
+}
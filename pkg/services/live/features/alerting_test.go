@@ -0,0 +1,45 @@
+package features
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/live/model"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestAlertingHandler_OnSubscribe(t *testing.T) {
+	u := &user.SignedInUser{UserID: 2, OrgID: 1}
+
+	t.Run("allows users who can read alerts", func(t *testing.T) {
+		h := &AlertingHandler{AccessControl: actest.FakeAccessControl{ExpectedEvaluate: true}}
+		_, status, err := h.OnSubscribe(context.Background(), u, model.SubscribeEvent{Channel: "grafana/alerting/events", Path: "events"})
+		require.NoError(t, err)
+		require.Equal(t, backend.SubscribeStreamStatusOK, status)
+	})
+
+	t.Run("denies users who cannot read alerts", func(t *testing.T) {
+		h := &AlertingHandler{AccessControl: actest.FakeAccessControl{ExpectedEvaluate: false}}
+		_, status, err := h.OnSubscribe(context.Background(), u, model.SubscribeEvent{Channel: "grafana/alerting/events", Path: "events"})
+		require.NoError(t, err)
+		require.Equal(t, backend.SubscribeStreamStatusPermissionDenied, status)
+	})
+
+	t.Run("unknown path", func(t *testing.T) {
+		h := &AlertingHandler{AccessControl: actest.FakeAccessControl{ExpectedEvaluate: true}}
+		_, status, err := h.OnSubscribe(context.Background(), u, model.SubscribeEvent{Channel: "grafana/alerting/other", Path: "other"})
+		require.NoError(t, err)
+		require.Equal(t, backend.SubscribeStreamStatusNotFound, status)
+	})
+}
+
+func TestAlertingHandler_OnPublish(t *testing.T) {
+	h := &AlertingHandler{AccessControl: actest.FakeAccessControl{ExpectedEvaluate: true}}
+	_, status, err := h.OnPublish(context.Background(), &user.SignedInUser{OrgID: 1}, model.PublishEvent{Channel: "grafana/alerting/events", Path: "events"})
+	require.NoError(t, err)
+	require.Equal(t, backend.PublishStreamStatusPermissionDenied, status)
+}
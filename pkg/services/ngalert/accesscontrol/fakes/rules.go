@@ -24,6 +24,7 @@ type FakeRuleService struct {
 	HasAccessInFolderFunc                     func(context.Context, identity.Requester, models.Namespaced) (bool, error)
 	AuthorizeAccessInFolderFunc               func(context.Context, identity.Requester, models.Namespaced) error
 	AuthorizeRuleChangesFunc                  func(context.Context, identity.Requester, *store.GroupDelta) error
+	AuthorizeRuleUpdateFunc                   func(context.Context, identity.Requester, *models.AlertRule) error
 	CanReadAllRulesFunc                       func(context.Context, identity.Requester) (bool, error)
 
 	Calls []Call
@@ -101,6 +102,14 @@ func (s *FakeRuleService) AuthorizeRuleChanges(ctx context.Context, user identit
 	return nil
 }
 
+func (s *FakeRuleService) AuthorizeRuleUpdate(ctx context.Context, user identity.Requester, rule *models.AlertRule) error {
+	s.Calls = append(s.Calls, Call{"AuthorizeRuleUpdate", []interface{}{ctx, user, rule}})
+	if s.AuthorizeRuleUpdateFunc != nil {
+		return s.AuthorizeRuleUpdateFunc(ctx, user, rule)
+	}
+	return nil
+}
+
 func (s *FakeRuleService) CanReadAllRules(ctx context.Context, user identity.Requester) (bool, error) {
 	s.Calls = append(s.Calls, Call{"CanReadAllRules", []interface{}{ctx, user}})
 	if s.CanReadAllRulesFunc != nil {
@@ -69,6 +69,14 @@ func (r *RuleService) getRulesQueryEvaluator(rules ...*models.AlertRule) accessc
 			evals = append(evals, accesscontrol.EvalPermission(datasources.ActionQuery, datasources.ScopeProvider.GetResourceScopeUID(query.DatasourceUID)))
 			added[query.DatasourceUID] = struct{}{}
 		}
+		// recording rules also need access to the data source they write to.
+		if rule.Record != nil && rule.Record.TargetDatasourceUID != "" {
+			uid := rule.Record.TargetDatasourceUID
+			if _, ok := added[uid]; !ok {
+				evals = append(evals, accesscontrol.EvalPermission(datasources.ActionQuery, datasources.ScopeProvider.GetResourceScopeUID(uid)))
+				added[uid] = struct{}{}
+			}
+		}
 	}
 	if len(evals) == 1 {
 		return evals[0]
@@ -151,6 +159,24 @@ func (r *RuleService) AuthorizeAccessInFolder(ctx context.Context, user identity
 	})
 }
 
+// AuthorizeRuleUpdate checks that the identity.Requester has permissions to update the given rule,
+// which requires the following permissions:
+// - ("folders:read") read the folder
+// - ("alert.rules:read") read alert rules in the folder
+// - ("alert.rules:write") update alert rules in the folder
+// - ("datasources:query") query all data sources the rule uses
+// Returns error if at least one permission is missing or if something went wrong during the permission evaluation
+func (r *RuleService) AuthorizeRuleUpdate(ctx context.Context, user identity.Requester, rule *models.AlertRule) error {
+	eval := accesscontrol.EvalAll(
+		getReadFolderAccessEvaluator(rule.NamespaceUID),
+		accesscontrol.EvalPermission(ruleUpdate, dashboards.ScopeFoldersProvider.GetResourceScopeUID(rule.NamespaceUID)),
+		r.getRulesQueryEvaluator(rule),
+	)
+	return r.HasAccessOrError(ctx, user, eval, func() string {
+		return fmt.Sprintf("update alert rule '%s' (UID: %s)", rule.Title, rule.UID)
+	})
+}
+
 // AuthorizeRuleChanges analyzes changes in the rule group, and checks whether the changes are authorized.
 // NOTE: if there are rules for deletion, and the user does not have access to data sources that a rule uses, the rule is removed from the list.
 // If the user is not authorized to perform the changes the function returns ErrAuthorization with a description of what action is not authorized.
@@ -0,0 +1,344 @@
+package acknowledgement
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/go-openapi/strfmt"
+	alertingModels "github.com/grafana/alerting/models"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// DefaultSyncInterval is the interval at which the service reloads the acknowledgements that were changed by other
+// Grafana instances.
+const DefaultSyncInterval = 30 * time.Second
+
+// silenceDuration is the duration of the silences of acknowledgements that do not expire. The silences are expired
+// earlier when the alert instances are resolved or unacknowledged.
+const silenceDuration = 365 * 24 * time.Hour
+
+// Store persists the acknowledgements of alert instances.
+type Store interface {
+	ListAlertInstanceAcknowledgements(ctx context.Context) ([]*models.AlertInstanceAcknowledgement, error)
+	GetAlertInstanceAcknowledgement(ctx context.Context, key models.AlertInstanceKey) (*models.AlertInstanceAcknowledgement, error)
+	SaveAlertInstanceAcknowledgement(ctx context.Context, ack models.AlertInstanceAcknowledgement) error
+	SetAlertInstanceAcknowledgementSilence(ctx context.Context, key models.AlertInstanceKey, previous, silenceID string) (bool, error)
+	DeleteAlertInstanceAcknowledgements(ctx context.Context, keys ...models.AlertInstanceKey) error
+}
+
+// SilenceService creates and expires the silences that suppress the notifications of acknowledged alert instances.
+type SilenceService interface {
+	CreateSilence(ctx context.Context, orgID int64, ps models.Silence) (string, error)
+	DeleteSilence(ctx context.Context, orgID int64, silenceID string) error
+}
+
+// Service acknowledges alert instances and assigns them to users. It keeps the acknowledgements of all organizations
+// in memory, so that the state manager can add them to the alerts it sends to the Alertmanager.
+//
+// The notifications of acknowledged alert instances are suppressed by silences that match their labels exactly. The
+// silences end when the acknowledgements expire, and are expired when the alert instances are unacknowledged or
+// resolved. The silences do not match the acknowledged copies of the alerts that the state manager sends, which have
+// the label models.AcknowledgedLabel, so that notification policies can route acknowledged alerts.
+//
+// The acknowledgements are reloaded from the database at every interval, so that changes made through other Grafana
+// instances are applied. Acknowledgements that expired and have no assignee are deleted.
+type Service struct {
+	store    Store
+	silences SilenceService
+	interval time.Duration
+	clock    clock.Clock
+	log      log.Logger
+
+	mtx  sync.RWMutex
+	acks map[models.AlertInstanceKey]models.AlertInstanceAcknowledgement
+}
+
+func NewService(store Store, silences SilenceService, interval time.Duration, clk clock.Clock) *Service {
+	if interval <= 0 {
+		interval = DefaultSyncInterval
+	}
+	return &Service{
+		store:    store,
+		silences: silences,
+		interval: interval,
+		clock:    clk,
+		log:      log.New("ngalert.acknowledgement"),
+		acks:     make(map[models.AlertInstanceKey]models.AlertInstanceAcknowledgement),
+	}
+}
+
+// Run reloads the acknowledgements at every interval until the context is cancelled.
+func (s *Service) Run(ctx context.Context) error {
+	ticker := s.clock.Ticker(s.interval)
+	defer ticker.Stop()
+	for {
+		if err := s.sync(ctx); err != nil {
+			s.log.Error("Failed to sync alert instance acknowledgements", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Acknowledgement returns the acknowledgement of the alert instance with the given key, if there is one.
+func (s *Service) Acknowledgement(key models.AlertInstanceKey) (models.AlertInstanceAcknowledgement, bool) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	ack, ok := s.acks[key]
+	return ack, ok
+}
+
+// Acknowledge acknowledges the alert instance until it is resolved or, if expiresAt is not nil, until expiresAt.
+func (s *Service) Acknowledge(ctx context.Context, key models.AlertInstanceKey, by, comment string, expiresAt *time.Time) (models.AlertInstanceAcknowledgement, error) {
+	return s.update(ctx, key, by, func(ack *models.AlertInstanceAcknowledgement, now time.Time) {
+		ack.Acknowledged = true
+		ack.AcknowledgedBy = by
+		ack.AcknowledgedAt = now
+		ack.ExpiresAt = expiresAt
+		ack.Comment = comment
+	})
+}
+
+// Unacknowledge removes the acknowledgement of the alert instance. It stays assigned to its assignee.
+func (s *Service) Unacknowledge(ctx context.Context, key models.AlertInstanceKey, by string) (models.AlertInstanceAcknowledgement, error) {
+	return s.update(ctx, key, by, func(ack *models.AlertInstanceAcknowledgement, _ time.Time) {
+		ack.Acknowledged = false
+		ack.AcknowledgedBy = ""
+		ack.AcknowledgedAt = time.Time{}
+		ack.ExpiresAt = nil
+	})
+}
+
+// Assign assigns the alert instance to the user with the given login. An empty assignee removes the assignment.
+func (s *Service) Assign(ctx context.Context, key models.AlertInstanceKey, assignee, by, comment string) (models.AlertInstanceAcknowledgement, error) {
+	return s.update(ctx, key, by, func(ack *models.AlertInstanceAcknowledgement, _ time.Time) {
+		ack.Assignee = assignee
+		if comment != "" {
+			ack.Comment = comment
+		}
+	})
+}
+
+// Suppress silences the notifications of the alert instance with the given key and labels if it is acknowledged and
+// not silenced yet. It is called when the alert instance fires, as the labels of the alert instance are not known
+// when it is acknowledged.
+func (s *Service) Suppress(ctx context.Context, key models.AlertInstanceKey, lbls data.Labels) error {
+	now := s.clock.Now()
+	ack, ok := s.Acknowledgement(key)
+	if !ok || !ack.IsAcknowledged(now) || ack.SilenceID != "" {
+		return nil
+	}
+
+	silenceID, err := s.silences.CreateSilence(ctx, key.RuleOrgID, newSilence(ack, lbls, now))
+	if err != nil {
+		return fmt.Errorf("failed to create silence: %w", err)
+	}
+	saved, err := s.store.SetAlertInstanceAcknowledgementSilence(ctx, key, "", silenceID)
+	if err != nil || !saved {
+		// Another instance silenced the alert instance, or it was unacknowledged in the meantime.
+		s.deleteSilence(ctx, key.RuleOrgID, silenceID)
+		if err != nil {
+			return err
+		}
+		return s.reload(ctx, key)
+	}
+	s.log.Debug("Silenced acknowledged alert instance", "rule_uid", key.RuleUID, "labels_hash", key.LabelsHash, "silence_id", silenceID)
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if cached, ok := s.acks[key]; ok && cached.SilenceID == "" {
+		cached.SilenceID = silenceID
+		s.acks[key] = cached
+	}
+	return nil
+}
+
+// Release deletes the acknowledgements of the alert instances with the given keys and expires their silences. It is
+// called once the alert instances are resolved, before the resolved alerts are sent, so that the resolved alerts are
+// notified and the alert instances are not acknowledged when they fire again.
+func (s *Service) Release(ctx context.Context, keys ...models.AlertInstanceKey) error {
+	s.mtx.RLock()
+	released := make([]models.AlertInstanceAcknowledgement, 0, len(keys))
+	releasedKeys := make([]models.AlertInstanceKey, 0, len(keys))
+	for _, key := range keys {
+		if ack, ok := s.acks[key]; ok {
+			released = append(released, ack)
+			releasedKeys = append(releasedKeys, key)
+		}
+	}
+	s.mtx.RUnlock()
+	if len(released) == 0 {
+		return nil
+	}
+	if err := s.store.DeleteAlertInstanceAcknowledgements(ctx, releasedKeys...); err != nil {
+		return err
+	}
+	for _, ack := range released {
+		if ack.SilenceID != "" {
+			s.deleteSilence(ctx, ack.RuleOrgID, ack.SilenceID)
+		}
+	}
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for _, key := range releasedKeys {
+		delete(s.acks, key)
+	}
+	return nil
+}
+
+func (s *Service) update(ctx context.Context, key models.AlertInstanceKey, by string, apply func(ack *models.AlertInstanceAcknowledgement, now time.Time)) (models.AlertInstanceAcknowledgement, error) {
+	ack := models.AlertInstanceAcknowledgement{AlertInstanceKey: key}
+	stored, err := s.store.GetAlertInstanceAcknowledgement(ctx, key)
+	if err != nil && !errors.Is(err, models.ErrAlertInstanceAcknowledgementNotFound) {
+		return models.AlertInstanceAcknowledgement{}, err
+	}
+	if stored != nil {
+		ack = *stored
+	}
+
+	now := s.clock.Now()
+	previous := ack
+	apply(&ack, now)
+	ack.Updated = now
+	ack.UpdatedBy = by
+	// The silence is created again with the new expiry the next time the alert instance fires.
+	if ack.SilenceID != "" && (!ack.IsAcknowledged(now) || !equalTimes(ack.ExpiresAt, previous.ExpiresAt)) {
+		ack.SilenceID = ""
+	}
+
+	if ack.IsEmpty(now) {
+		err = s.store.DeleteAlertInstanceAcknowledgements(ctx, key)
+	} else {
+		err = s.store.SaveAlertInstanceAcknowledgement(ctx, ack)
+	}
+	if err != nil {
+		return models.AlertInstanceAcknowledgement{}, err
+	}
+	if previous.SilenceID != "" && ack.SilenceID != previous.SilenceID {
+		s.deleteSilence(ctx, key.RuleOrgID, previous.SilenceID)
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if ack.IsEmpty(now) {
+		delete(s.acks, key)
+	} else {
+		s.acks[key] = ack
+	}
+	return ack, nil
+}
+
+// sync reloads the acknowledgements and deletes the ones that no longer apply.
+func (s *Service) sync(ctx context.Context) error {
+	now := s.clock.Now()
+	stored, err := s.store.ListAlertInstanceAcknowledgements(ctx)
+	if err != nil {
+		return err
+	}
+	acks := make(map[models.AlertInstanceKey]models.AlertInstanceAcknowledgement, len(stored))
+	var expired []models.AlertInstanceKey
+	for _, ack := range stored {
+		if ack.IsEmpty(now) {
+			expired = append(expired, ack.AlertInstanceKey)
+			continue
+		}
+		acks[ack.AlertInstanceKey] = *ack
+	}
+	if err := s.store.DeleteAlertInstanceAcknowledgements(ctx, expired...); err != nil {
+		s.log.Warn("Failed to delete expired alert instance acknowledgements", "error", err)
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.acks = acks
+	return nil
+}
+
+// reload replaces the cached acknowledgement of the alert instance with the one in the database.
+func (s *Service) reload(ctx context.Context, key models.AlertInstanceKey) error {
+	stored, err := s.store.GetAlertInstanceAcknowledgement(ctx, key)
+	if err != nil && !errors.Is(err, models.ErrAlertInstanceAcknowledgementNotFound) {
+		return err
+	}
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if stored == nil {
+		delete(s.acks, key)
+	} else {
+		s.acks[key] = *stored
+	}
+	return nil
+}
+
+func (s *Service) deleteSilence(ctx context.Context, orgID int64, silenceID string) {
+	// The silence could have been expired by a user or by another instance.
+	if err := s.silences.DeleteSilence(ctx, orgID, silenceID); err != nil {
+		s.log.Debug("Failed to expire silence of alert instance acknowledgement", "error", err, "silence_id", silenceID)
+	}
+}
+
+// newSilence returns the silence of the acknowledged alert instance with the given labels. It matches the labels of
+// the alert instance exactly, so that it does not silence other alert instances of the rule.
+func newSilence(ack models.AlertInstanceAcknowledgement, lbls data.Labels, now time.Time) models.Silence {
+	end := now.Add(silenceDuration)
+	if ack.ExpiresAt != nil {
+		end = *ack.ExpiresAt
+	}
+	comment := fmt.Sprintf("Acknowledged by %s", ack.AcknowledgedBy)
+	if ack.Comment != "" {
+		comment = fmt.Sprintf("%s: %s", comment, ack.Comment)
+	}
+	silence := models.Silence{}
+	silence.Silence = amv2.Silence{
+		Comment:   util.Pointer(comment),
+		CreatedBy: util.Pointer(ack.AcknowledgedBy),
+		StartsAt:  util.Pointer(strfmt.DateTime(now)),
+		EndsAt:    util.Pointer(strfmt.DateTime(end)),
+		Matchers:  make(amv2.Matchers, 0, len(lbls)),
+	}
+	names := make([]string, 0, len(lbls))
+	for name, value := range lbls {
+		// The Alertmanager drops the label of the folder UID and empty labels.
+		if name == alertingModels.NamespaceUIDLabel || value == "" {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		silence.Matchers = append(silence.Matchers, &amv2.Matcher{
+			Name:    util.Pointer(name),
+			Value:   util.Pointer(lbls[name]),
+			IsEqual: util.Pointer(true),
+			IsRegex: util.Pointer(false),
+		})
+	}
+	// The acknowledged copy of the alert is routed by the notification policies instead.
+	silence.Matchers = append(silence.Matchers, &amv2.Matcher{
+		Name:    util.Pointer(models.AcknowledgedLabel),
+		Value:   util.Pointer("true"),
+		IsEqual: util.Pointer(false),
+		IsRegex: util.Pointer(false),
+	})
+	return silence
+}
+
+func equalTimes(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
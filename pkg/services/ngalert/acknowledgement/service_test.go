@@ -0,0 +1,241 @@
+package acknowledgement
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestService(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2024, 6, 1, 2, 0, 0, 0, time.UTC)
+	key := models.AlertInstanceKey{RuleOrgID: 1, RuleUID: "rule", LabelsHash: "hash"}
+
+	t.Run("acknowledges and assigns alert instances", func(t *testing.T) {
+		clk := clock.NewMock()
+		clk.Set(start)
+		store := newFakeStore()
+		sut := NewService(store, newFakeSilences(), time.Minute, clk)
+
+		ack, err := sut.Acknowledge(ctx, key, "admin", "looking into it", nil)
+		require.NoError(t, err)
+		require.True(t, ack.IsAcknowledged(start))
+		require.Equal(t, "admin", ack.AcknowledgedBy)
+		require.Equal(t, start, ack.AcknowledgedAt)
+		require.Equal(t, "looking into it", store.acks[key].Comment)
+
+		ack, err = sut.Assign(ctx, key, "editor", "admin", "")
+		require.NoError(t, err)
+		require.True(t, ack.IsAcknowledged(start))
+		require.Equal(t, "editor", ack.Assignee)
+		require.Equal(t, "looking into it", ack.Comment)
+
+		cached, ok := sut.Acknowledgement(key)
+		require.True(t, ok)
+		require.Equal(t, ack, cached)
+
+		t.Run("and keeps the assignment once unacknowledged", func(t *testing.T) {
+			ack, err := sut.Unacknowledge(ctx, key, "editor")
+			require.NoError(t, err)
+			require.False(t, ack.Acknowledged)
+			require.Equal(t, "editor", ack.Assignee)
+			require.Equal(t, "editor", ack.UpdatedBy)
+		})
+
+		t.Run("and deletes the acknowledgement once it is empty", func(t *testing.T) {
+			_, err := sut.Assign(ctx, key, "", "admin", "")
+			require.NoError(t, err)
+			require.Empty(t, store.acks)
+			_, ok := sut.Acknowledgement(key)
+			require.False(t, ok)
+		})
+	})
+
+	t.Run("sync loads acknowledgements and deletes the expired ones", func(t *testing.T) {
+		clk := clock.NewMock()
+		clk.Set(start)
+		store := newFakeStore()
+		expires := start.Add(time.Hour)
+		other := models.AlertInstanceKey{RuleOrgID: 1, RuleUID: "rule", LabelsHash: "other"}
+		store.acks[key] = models.AlertInstanceAcknowledgement{AlertInstanceKey: key, Acknowledged: true, ExpiresAt: &expires}
+		store.acks[other] = models.AlertInstanceAcknowledgement{AlertInstanceKey: other, Acknowledged: true, ExpiresAt: &expires, Assignee: "editor"}
+		sut := NewService(store, newFakeSilences(), time.Minute, clk)
+
+		require.NoError(t, sut.sync(ctx))
+		_, ok := sut.Acknowledgement(key)
+		require.True(t, ok)
+
+		clk.Add(time.Hour)
+		require.NoError(t, sut.sync(ctx))
+		_, ok = sut.Acknowledgement(key)
+		require.False(t, ok)
+		require.NotContains(t, store.acks, key)
+		ack, ok := sut.Acknowledgement(other)
+		require.True(t, ok, "assigned alert instances are kept after the acknowledgement expires")
+		require.False(t, ack.IsAcknowledged(clk.Now()))
+	})
+
+	t.Run("suppress silences acknowledged alert instances once", func(t *testing.T) {
+		clk := clock.NewMock()
+		clk.Set(start)
+		store := newFakeStore()
+		silences := newFakeSilences()
+		sut := NewService(store, silences, time.Minute, clk)
+		lbls := data.Labels{"alertname": "test", "service": "db", "__alert_rule_namespace_uid__": "folder", "empty": ""}
+
+		require.NoError(t, sut.Suppress(ctx, key, lbls))
+		require.Empty(t, silences.silences, "alert instances that are not acknowledged should not be silenced")
+
+		expires := start.Add(time.Hour)
+		_, err := sut.Acknowledge(ctx, key, "admin", "looking into it", &expires)
+		require.NoError(t, err)
+		require.NoError(t, sut.Suppress(ctx, key, lbls))
+		require.NoError(t, sut.Suppress(ctx, key, lbls))
+		require.Len(t, silences.silences, 1)
+		silenceID := store.acks[key].SilenceID
+		require.Contains(t, silences.silences, silenceID)
+		silence := silences.silences[silenceID]
+		require.Equal(t, expires, time.Time(*silence.EndsAt))
+		require.Equal(t, "Acknowledged by admin: looking into it", *silence.Comment)
+		matchers := make(labels.Matchers, 0, len(silence.Matchers))
+		for _, m := range silence.Matchers {
+			require.False(t, *m.IsRegex)
+			matchType := labels.MatchEqual
+			if !*m.IsEqual {
+				matchType = labels.MatchNotEqual
+			}
+			matcher, err := labels.NewMatcher(matchType, *m.Name, *m.Value)
+			require.NoError(t, err)
+			matchers = append(matchers, matcher)
+		}
+		require.Len(t, matchers, 3)
+		require.True(t, matchers.Matches(model.LabelSet{"alertname": "test", "service": "db"}))
+		require.False(t, matchers.Matches(model.LabelSet{"alertname": "test", "service": "web"}), "other alert instances should not be silenced")
+		require.False(t, matchers.Matches(model.LabelSet{"alertname": "test", "service": "db", models.AcknowledgedLabel: "true"}), "the acknowledged copy should not be silenced")
+		cached, _ := sut.Acknowledgement(key)
+		require.Equal(t, silenceID, cached.SilenceID)
+
+		t.Run("and expires the silence once unacknowledged", func(t *testing.T) {
+			_, err := sut.Assign(ctx, key, "editor", "admin", "")
+			require.NoError(t, err)
+			require.Contains(t, silences.silences, silenceID, "assigning should not expire the silence")
+
+			ack, err := sut.Unacknowledge(ctx, key, "admin")
+			require.NoError(t, err)
+			require.Empty(t, ack.SilenceID)
+			require.Empty(t, silences.silences)
+		})
+	})
+
+	t.Run("suppress does not keep the silence if another instance silenced the alert instance", func(t *testing.T) {
+		clk := clock.NewMock()
+		clk.Set(start)
+		store := newFakeStore()
+		silences := newFakeSilences()
+		sut := NewService(store, silences, time.Minute, clk)
+
+		_, err := sut.Acknowledge(ctx, key, "admin", "", nil)
+		require.NoError(t, err)
+		ack := store.acks[key]
+		ack.SilenceID = "other"
+		store.acks[key] = ack
+
+		require.NoError(t, sut.Suppress(ctx, key, data.Labels{"service": "db"}))
+		require.Empty(t, silences.silences)
+		cached, _ := sut.Acknowledgement(key)
+		require.Equal(t, "other", cached.SilenceID)
+	})
+
+	t.Run("release deletes the acknowledgements of resolved alert instances", func(t *testing.T) {
+		clk := clock.NewMock()
+		clk.Set(start)
+		store := newFakeStore()
+		silences := newFakeSilences()
+		sut := NewService(store, silences, time.Minute, clk)
+
+		_, err := sut.Acknowledge(ctx, key, "admin", "", nil)
+		require.NoError(t, err)
+		require.NoError(t, sut.Suppress(ctx, key, data.Labels{"service": "db"}))
+		require.Len(t, silences.silences, 1)
+		require.NoError(t, sut.Release(ctx, key, models.AlertInstanceKey{RuleOrgID: 1, RuleUID: "unknown"}))
+		require.Empty(t, store.acks)
+		require.Empty(t, silences.silences, "the silence should be expired so that the resolved alert is notified")
+		_, ok := sut.Acknowledgement(key)
+		require.False(t, ok)
+	})
+}
+
+type fakeStore struct {
+	acks map[models.AlertInstanceKey]models.AlertInstanceAcknowledgement
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{acks: make(map[models.AlertInstanceKey]models.AlertInstanceAcknowledgement)}
+}
+
+func (f *fakeStore) ListAlertInstanceAcknowledgements(_ context.Context) ([]*models.AlertInstanceAcknowledgement, error) {
+	result := make([]*models.AlertInstanceAcknowledgement, 0, len(f.acks))
+	for _, ack := range f.acks {
+		a := ack
+		result = append(result, &a)
+	}
+	return result, nil
+}
+
+func (f *fakeStore) GetAlertInstanceAcknowledgement(_ context.Context, key models.AlertInstanceKey) (*models.AlertInstanceAcknowledgement, error) {
+	ack, ok := f.acks[key]
+	if !ok {
+		return nil, models.ErrAlertInstanceAcknowledgementNotFound
+	}
+	return &ack, nil
+}
+
+func (f *fakeStore) SaveAlertInstanceAcknowledgement(_ context.Context, ack models.AlertInstanceAcknowledgement) error {
+	f.acks[ack.AlertInstanceKey] = ack
+	return nil
+}
+
+func (f *fakeStore) SetAlertInstanceAcknowledgementSilence(_ context.Context, key models.AlertInstanceKey, previous, silenceID string) (bool, error) {
+	ack, ok := f.acks[key]
+	if !ok || !ack.Acknowledged || ack.SilenceID != previous {
+		return false, nil
+	}
+	ack.SilenceID = silenceID
+	f.acks[key] = ack
+	return true, nil
+}
+
+func (f *fakeStore) DeleteAlertInstanceAcknowledgements(_ context.Context, keys ...models.AlertInstanceKey) error {
+	for _, key := range keys {
+		delete(f.acks, key)
+	}
+	return nil
+}
+
+type fakeSilences struct {
+	silences map[string]models.Silence
+}
+
+func newFakeSilences() *fakeSilences {
+	return &fakeSilences{silences: make(map[string]models.Silence)}
+}
+
+func (f *fakeSilences) CreateSilence(_ context.Context, _ int64, ps models.Silence) (string, error) {
+	id := fmt.Sprintf("silence-%d", len(f.silences)+1)
+	f.silences[id] = ps
+	return id, nil
+}
+
+func (f *fakeSilences) DeleteSilence(_ context.Context, _ int64, silenceID string) error {
+	delete(f.silences, silenceID)
+	return nil
+}
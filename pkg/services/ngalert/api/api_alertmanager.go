@@ -5,18 +5,24 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
 
 	alertingNotify "github.com/grafana/alerting/notify"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/common/model"
 
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/util"
@@ -33,6 +39,11 @@ type AlertmanagerSrv struct {
 	mam        *notifier.MultiOrgAlertmanager
 	crypto     notifier.Crypto
 	silenceSvc SilenceService
+	// instances provides the alert instances that notifications are previewed for.
+	instances state.AlertInstanceManager
+	appURL    *url.URL
+	// escalations escalates the firing alert groups and tracks their acknowledgements.
+	escalations AlertGroupEscalationService
 }
 
 type UnknownReceiverError struct {
@@ -279,6 +290,60 @@ func (srv AlertmanagerSrv) RoutePostTestTemplates(c *contextmodel.ReqContext, bo
 	return response.JSON(http.StatusOK, newTestTemplateResult(res))
 }
 
+// RoutePostPreviewTemplates renders the notifications that a contact point would send for the firing alert instances
+// of a rule, or of all rules if the instances are selected by matchers, without sending them.
+func (srv AlertmanagerSrv) RoutePostPreviewTemplates(c *contextmodel.ReqContext, body apimodels.PreviewNotificationsBodyParams) response.Response {
+	if body.Receiver == "" {
+		return ErrResp(http.StatusBadRequest, errors.New("receiver is required"), "")
+	}
+	if body.RuleUID == "" && len(body.Matchers) == 0 {
+		return ErrResp(http.StatusBadRequest, errors.New("either ruleUid or matchers must be set"), "")
+	}
+	matchers := make(labels.Matchers, 0, len(body.Matchers))
+	for _, s := range body.Matchers {
+		m, err := labels.ParseMatcher(s)
+		if err != nil {
+			return ErrResp(http.StatusBadRequest, err, "invalid matcher %q", s)
+		}
+		matchers = append(matchers, m)
+	}
+
+	orgID := c.SignedInUser.GetOrgID()
+	var states []*state.State
+	if body.RuleUID != "" {
+		states = srv.instances.GetStatesForRuleUID(c.Req.Context(), orgID, body.RuleUID)
+	} else {
+		states = srv.instances.GetAll(c.Req.Context(), orgID)
+	}
+	alerts := make(amv2.PostableAlerts, 0, len(states))
+	for _, s := range states {
+		switch s.State {
+		case eval.Alerting, eval.NoData, eval.Error:
+		default:
+			continue
+		}
+		alert := state.StateToPostableAlert(state.StateTransition{State: s, PreviousState: s.State}, srv.appURL)
+		lset := make(model.LabelSet, len(alert.Labels))
+		for k, v := range alert.Labels {
+			lset[model.LabelName(k)] = model.LabelValue(v)
+		}
+		if !matchers.Matches(lset) {
+			continue
+		}
+		alerts = append(alerts, alert)
+	}
+
+	result, err := srv.mam.PreviewNotifications(c.Req.Context(), orgID, notifier.PreviewNotificationsParams{
+		Receiver: body.Receiver,
+		Alerts:   alerts,
+		Template: body.Template,
+	})
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to preview notifications", err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
 // contextWithTimeoutFromRequest returns a context with a deadline set from the
 // Request-Timeout header in the HTTP request. If the header is absent then the
 // context will use the default timeout. The timeout in the Request-Timeout
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// AlertGroupEscalationService escalates the firing alert groups of Grafana AM and tracks their acknowledgements.
+type AlertGroupEscalationService interface {
+	GetAlertGroupEscalations(ctx context.Context, orgID int64) ([]*models.AlertGroupEscalation, error)
+	Acknowledge(ctx context.Context, orgID int64, receiver string, groupLabels map[string]string, by, comment string) (*models.AlertGroupEscalation, error)
+	Unacknowledge(ctx context.Context, orgID int64, groupKey string) (*models.AlertGroupEscalation, error)
+}
+
+// RouteGetAlertGroupEscalations is the escalation list GET endpoint for Grafana AM.
+func (srv AlertmanagerSrv) RouteGetAlertGroupEscalations(c *contextmodel.ReqContext) response.Response {
+	escalations, err := srv.escalations.GetAlertGroupEscalations(c.Req.Context(), c.SignedInUser.GetOrgID())
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to list alert group escalations", err)
+	}
+	result := make(apimodels.AlertGroupEscalations, 0, len(escalations))
+	for _, e := range escalations {
+		result = append(result, AlertGroupEscalationToApiAlertGroupEscalation(e))
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// RoutePostAlertGroupAcknowledgement is the alert group acknowledgement POST endpoint for Grafana AM.
+func (srv AlertmanagerSrv) RoutePostAlertGroupAcknowledgement(c *contextmodel.ReqContext, body apimodels.PostableAlertGroupAcknowledgement) response.Response {
+	if body.Receiver == "" {
+		return ErrResp(http.StatusBadRequest, errors.New("receiver is required"), "")
+	}
+	escalation, err := srv.escalations.Acknowledge(c.Req.Context(), c.SignedInUser.GetOrgID(), body.Receiver, body.Labels, c.SignedInUser.GetLogin(), body.Comment)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to acknowledge alert group", err)
+	}
+	return response.JSON(http.StatusOK, AlertGroupEscalationToApiAlertGroupEscalation(escalation))
+}
+
+// RouteDeleteAlertGroupAcknowledgement is the alert group acknowledgement DELETE endpoint for Grafana AM.
+func (srv AlertmanagerSrv) RouteDeleteAlertGroupAcknowledgement(c *contextmodel.ReqContext, groupKey string) response.Response {
+	escalation, err := srv.escalations.Unacknowledge(c.Req.Context(), c.SignedInUser.GetOrgID(), groupKey)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to remove alert group acknowledgement", err)
+	}
+	return response.JSON(http.StatusOK, AlertGroupEscalationToApiAlertGroupEscalation(escalation))
+}
@@ -23,10 +23,12 @@ import (
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
 	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	ngfakes "github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/secrets/fakes"
@@ -555,6 +557,68 @@ func TestRoutePostTestTemplates(t *testing.T) {
 	})
 }
 
+func TestRoutePostPreviewTemplates(t *testing.T) {
+	sut := createSut(t)
+	instances := NewFakeAlertInstanceManager(t)
+	instances.GenerateAlertInstances(1, "rule-1", 3, func(s *state.State) *state.State {
+		if s.Labels["alertname"] != "test_title_2" {
+			s.State = eval.Alerting
+		}
+		return s
+	})
+	sut.instances = instances
+
+	t.Run("assert 400 when receiver is missing", func(t *testing.T) {
+		response := sut.RoutePostPreviewTemplates(createRequestCtxInOrg(1), apimodels.PreviewNotificationsBodyParams{RuleUID: "rule-1"})
+		require.Equal(t, 400, response.Status())
+	})
+
+	t.Run("assert 400 when neither rule nor matchers are set", func(t *testing.T) {
+		response := sut.RoutePostPreviewTemplates(createRequestCtxInOrg(1), apimodels.PreviewNotificationsBodyParams{Receiver: "grafana-default-email"})
+		require.Equal(t, 400, response.Status())
+	})
+
+	t.Run("assert 400 when a matcher is invalid", func(t *testing.T) {
+		response := sut.RoutePostPreviewTemplates(createRequestCtxInOrg(1), apimodels.PreviewNotificationsBodyParams{
+			Receiver: "grafana-default-email",
+			Matchers: []string{"alertname=~("},
+		})
+		require.Equal(t, 400, response.Status())
+	})
+
+	t.Run("assert 404 when receiver does not exist", func(t *testing.T) {
+		response := sut.RoutePostPreviewTemplates(createRequestCtxInOrg(1), apimodels.PreviewNotificationsBodyParams{Receiver: "unknown", RuleUID: "rule-1"})
+		require.Equal(t, 404, response.Status())
+	})
+
+	t.Run("assert 200 with the firing alerts of the rule", func(t *testing.T) {
+		response := sut.RoutePostPreviewTemplates(createRequestCtxInOrg(1), apimodels.PreviewNotificationsBodyParams{Receiver: "grafana-default-email", RuleUID: "rule-1"})
+		require.Equal(t, 200, response.Status())
+
+		var result apimodels.NotificationPreviewResults
+		require.NoError(t, json.Unmarshal(response.Body(), &result))
+		var alerts int
+		for _, g := range result.Groups {
+			alerts += len(g.Alerts)
+		}
+		require.Equal(t, 2, alerts, "normal alert instances should not be previewed")
+	})
+
+	t.Run("assert 200 with the alerts that match the matchers", func(t *testing.T) {
+		response := sut.RoutePostPreviewTemplates(createRequestCtxInOrg(1), apimodels.PreviewNotificationsBodyParams{
+			Receiver: "grafana-default-email",
+			Matchers: []string{`alertname="test_title_0"`},
+		})
+		require.Equal(t, 200, response.Status())
+
+		var result apimodels.NotificationPreviewResults
+		require.NoError(t, json.Unmarshal(response.Body(), &result))
+		require.Len(t, result.Groups, 1)
+		require.Len(t, result.Groups[0].Alerts, 1)
+		require.Equal(t, "test_title_0", result.Groups[0].Alerts[0]["alertname"])
+	})
+}
+
 func createSut(t *testing.T) AlertmanagerSrv {
 	t.Helper()
 
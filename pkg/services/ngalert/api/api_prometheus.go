@@ -29,6 +29,7 @@ type PrometheusSrv struct {
 	manager state.AlertInstanceManager
 	store   RuleStore
 	authz   RuleAccessControlService
+	acks    AlertInstanceAcknowledgementService
 }
 
 const queryIncludeInternalLabels = "includeInternalLabels"
@@ -61,6 +62,7 @@ func (srv PrometheusSrv) RouteGetAlertStatuses(c *contextmodel.ReqContext) respo
 	c.Query("")
 
 	resp := PrepareAlertStatuses(srv.manager, AlertStatusesOptions{
+		Ctx:   c.Req.Context(),
 		OrgID: c.SignedInUser.GetOrgID(),
 		Query: c.Req.Form,
 	})
@@ -69,6 +71,7 @@ func (srv PrometheusSrv) RouteGetAlertStatuses(c *contextmodel.ReqContext) respo
 }
 
 type AlertStatusesOptions struct {
+	Ctx   context.Context
 	OrgID int64
 	Query url.Values
 }
@@ -88,7 +91,7 @@ func PrepareAlertStatuses(manager state.AlertInstanceManager, opts AlertStatuses
 		labelOptions = append(labelOptions, ngmodels.WithoutInternalLabels())
 	}
 
-	for _, alertState := range manager.GetAll(opts.OrgID) {
+	for _, alertState := range manager.GetAll(opts.Ctx, opts.OrgID) {
 		startsAt := alertState.StartsAt
 		valString := ""
 
@@ -98,7 +101,7 @@ func PrepareAlertStatuses(manager state.AlertInstanceManager, opts AlertStatuses
 
 		alertResponse.Data.Alerts = append(alertResponse.Data.Alerts, &apimodels.Alert{
 			Labels:      apimodels.LabelsFromMap(alertState.GetLabels(labelOptions...)),
-			Annotations: apimodels.LabelsFromMap(alertState.Annotations),
+			Annotations: apimodels.LabelsFromMap(alertAnnotations(alertState)),
 
 			// TODO: or should we make this two fields? Using one field lets the
 			// frontend use the same logic for parsing text on annotations and this.
@@ -111,6 +114,21 @@ func PrepareAlertStatuses(manager state.AlertInstanceManager, opts AlertStatuses
 	return alertResponse
 }
 
+// alertAnnotations returns the annotations of the state, including the ones that describe its acknowledgement.
+func alertAnnotations(alertState *state.State) map[string]string {
+	if alertState.Acknowledgement == nil {
+		return alertState.Annotations
+	}
+	result := make(map[string]string, len(alertState.Annotations))
+	for k, v := range alertState.Annotations {
+		result[k] = v
+	}
+	for k, v := range alertState.Acknowledgement.Annotations(alertState.LastEvaluationTime) {
+		result[k] = v
+	}
+	return result
+}
+
 func formatValues(alertState *state.State) string {
 	var fv string
 	values := alertState.GetLastEvaluationValuesForCondition()
@@ -346,7 +364,7 @@ func PrepareRuleGroupStatuses(log log.Logger, manager state.AlertInstanceManager
 			continue
 		}
 
-		ruleGroup, totals := toRuleGroup(log, manager, groupKey, folder, rules, limitAlertsPerRule, withStatesFast, matchers, labelOptions)
+		ruleGroup, totals := toRuleGroup(opts.Ctx, log, manager, groupKey, folder, rules, limitAlertsPerRule, withStatesFast, matchers, labelOptions)
 		ruleGroup.Totals = totals
 		for k, v := range totals {
 			rulesTotals[k] += v
@@ -432,7 +450,7 @@ func matchersMatch(matchers []*labels.Matcher, labels map[string]string) bool {
 	return true
 }
 
-func toRuleGroup(log log.Logger, manager state.AlertInstanceManager, groupKey ngmodels.AlertRuleGroupKey, folderFullPath string, rules []*ngmodels.AlertRule, limitAlerts int64, withStates map[eval.State]struct{}, matchers labels.Matchers, labelOptions []ngmodels.LabelOption) (*apimodels.RuleGroup, map[string]int64) {
+func toRuleGroup(ctx context.Context, log log.Logger, manager state.AlertInstanceManager, groupKey ngmodels.AlertRuleGroupKey, folderFullPath string, rules []*ngmodels.AlertRule, limitAlerts int64, withStates map[eval.State]struct{}, matchers labels.Matchers, labelOptions []ngmodels.LabelOption) (*apimodels.RuleGroup, map[string]int64) {
 	newGroup := &apimodels.RuleGroup{
 		Name: groupKey.RuleGroup,
 		// file is what Prometheus uses for provisioning, we replace it with namespace which is the folder in Grafana.
@@ -444,11 +462,12 @@ func toRuleGroup(log log.Logger, manager state.AlertInstanceManager, groupKey ng
 	ngmodels.RulesGroup(rules).SortByGroupIndex()
 	for _, rule := range rules {
 		alertingRule := apimodels.AlertingRule{
-			State:       "inactive",
-			Name:        rule.Title,
-			Query:       ruleToQuery(log, rule),
-			Duration:    rule.For.Seconds(),
-			Annotations: apimodels.LabelsFromMap(rule.Annotations),
+			State:         "inactive",
+			Name:          rule.Title,
+			Query:         ruleToQuery(log, rule),
+			Duration:      rule.For.Seconds(),
+			KeepFiringFor: rule.KeepFiringFor.Seconds(),
+			Annotations:   apimodels.LabelsFromMap(rule.Annotations),
 		}
 
 		newRule := apimodels.Rule{
@@ -459,7 +478,7 @@ func toRuleGroup(log log.Logger, manager state.AlertInstanceManager, groupKey ng
 			LastEvaluation: time.Time{},
 		}
 
-		states := manager.GetStatesForRuleUID(rule.OrgID, rule.UID)
+		states := manager.GetStatesForRuleUID(ctx, rule.OrgID, rule.UID)
 		totals := make(map[string]int64)
 		totalsFiltered := make(map[string]int64)
 		for _, alertState := range states {
@@ -476,7 +495,7 @@ func toRuleGroup(log log.Logger, manager state.AlertInstanceManager, groupKey ng
 			}
 			alert := apimodels.Alert{
 				Labels:      apimodels.LabelsFromMap(alertState.GetLabels(labelOptions...)),
-				Annotations: apimodels.LabelsFromMap(alertState.Annotations),
+				Annotations: apimodels.LabelsFromMap(alertAnnotations(alertState)),
 
 				// TODO: or should we make this two fields? Using one field lets the
 				// frontend use the same logic for parsing text on annotations and this.
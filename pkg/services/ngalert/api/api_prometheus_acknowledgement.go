@@ -0,0 +1,92 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// AlertInstanceAcknowledgementService acknowledges alert instances and assigns them to users.
+type AlertInstanceAcknowledgementService interface {
+	Acknowledge(ctx context.Context, key ngmodels.AlertInstanceKey, by, comment string, expiresAt *time.Time) (ngmodels.AlertInstanceAcknowledgement, error)
+	Unacknowledge(ctx context.Context, key ngmodels.AlertInstanceKey, by string) (ngmodels.AlertInstanceAcknowledgement, error)
+	Assign(ctx context.Context, key ngmodels.AlertInstanceKey, assignee, by, comment string) (ngmodels.AlertInstanceAcknowledgement, error)
+}
+
+func (srv PrometheusSrv) RoutePostAlertAcknowledge(c *contextmodel.ReqContext, body apimodels.PostableAlertAcknowledgement) response.Response {
+	if body.ExpiresAt != nil && !body.ExpiresAt.After(time.Now()) {
+		return ErrResp(http.StatusBadRequest, errors.New("expiresAt must be in the future"), "")
+	}
+	key, errResp := srv.getAuthorizedAlertInstanceKey(c, body.RuleUID, body.Labels)
+	if errResp != nil {
+		return errResp
+	}
+	ack, err := srv.acks.Acknowledge(c.Req.Context(), key, c.SignedInUser.GetLogin(), body.Comment, body.ExpiresAt)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to acknowledge alert", err)
+	}
+	return response.JSON(http.StatusOK, AlertInstanceAcknowledgementToApiAlertAcknowledgement(ack))
+}
+
+func (srv PrometheusSrv) RoutePostAlertUnacknowledge(c *contextmodel.ReqContext, body apimodels.AlertInstanceReference) response.Response {
+	key, errResp := srv.getAuthorizedAlertInstanceKey(c, body.RuleUID, body.Labels)
+	if errResp != nil {
+		return errResp
+	}
+	ack, err := srv.acks.Unacknowledge(c.Req.Context(), key, c.SignedInUser.GetLogin())
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to remove the acknowledgement of the alert", err)
+	}
+	return response.JSON(http.StatusOK, AlertInstanceAcknowledgementToApiAlertAcknowledgement(ack))
+}
+
+func (srv PrometheusSrv) RoutePostAlertAssign(c *contextmodel.ReqContext, body apimodels.PostableAlertAssignment) response.Response {
+	key, errResp := srv.getAuthorizedAlertInstanceKey(c, body.RuleUID, body.Labels)
+	if errResp != nil {
+		return errResp
+	}
+	ack, err := srv.acks.Assign(c.Req.Context(), key, body.Assignee, c.SignedInUser.GetLogin(), body.Comment)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to assign alert", err)
+	}
+	return response.JSON(http.StatusOK, AlertInstanceAcknowledgementToApiAlertAcknowledgement(ack))
+}
+
+// getAuthorizedAlertInstanceKey returns the key of the current alert instance of the rule with the given labels, if the
+// user can access the rule. The labels do not include internal labels, like the alerts API returns them.
+func (srv PrometheusSrv) getAuthorizedAlertInstanceKey(c *contextmodel.ReqContext, ruleUID string, lbls map[string]string) (ngmodels.AlertInstanceKey, response.Response) {
+	if ruleUID == "" {
+		return ngmodels.AlertInstanceKey{}, ErrResp(http.StatusBadRequest, errors.New("ruleUid is required"), "")
+	}
+	ctx := c.Req.Context()
+	orgID := c.SignedInUser.GetOrgID()
+	rule, err := srv.store.GetAlertRuleByUID(ctx, &ngmodels.GetAlertRuleByUIDQuery{UID: ruleUID, OrgID: orgID})
+	if err != nil {
+		if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
+			return ngmodels.AlertInstanceKey{}, ErrResp(http.StatusNotFound, err, "")
+		}
+		return ngmodels.AlertInstanceKey{}, response.ErrOrFallback(http.StatusInternalServerError, "failed to get rule by UID", err)
+	}
+	if err := srv.authz.AuthorizeAccessInFolder(ctx, c.SignedInUser, rule); err != nil {
+		return ngmodels.AlertInstanceKey{}, response.ErrOrFallback(http.StatusInternalServerError, "failed to authorize access to rule", err)
+	}
+
+	for _, s := range srv.manager.GetStatesForRuleUID(ctx, orgID, ruleUID) {
+		if !maps.Equal(s.GetLabels(ngmodels.WithoutInternalLabels()), lbls) {
+			continue
+		}
+		key, err := s.GetAlertInstanceKey()
+		if err != nil {
+			return ngmodels.AlertInstanceKey{}, ErrResp(http.StatusInternalServerError, err, "")
+		}
+		return key, nil
+	}
+	return ngmodels.AlertInstanceKey{}, ErrResp(http.StatusNotFound, errors.New("alert not found"), "")
+}
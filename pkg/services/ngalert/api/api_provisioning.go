@@ -29,6 +29,8 @@ type ProvisioningSrv struct {
 	contactPointService ContactPointService
 	templates           TemplateService
 	muteTimings         MuteTimingService
+	maintenanceWindows  MaintenanceWindowService
+	escalationChains    EscalationChainService
 	alertRules          AlertRuleService
 	folderSvc           folder.Service
 
@@ -64,6 +66,22 @@ type MuteTimingService interface {
 	DeleteMuteTiming(ctx context.Context, name string, orgID int64, provenance definitions.Provenance, version string) error
 }
 
+type MaintenanceWindowService interface {
+	GetMaintenanceWindows(ctx context.Context, orgID int64) ([]alerting_models.MaintenanceWindow, error)
+	GetMaintenanceWindow(ctx context.Context, orgID int64, uid string) (alerting_models.MaintenanceWindow, error)
+	CreateMaintenanceWindow(ctx context.Context, orgID int64, w alerting_models.MaintenanceWindow) (alerting_models.MaintenanceWindow, error)
+	UpdateMaintenanceWindow(ctx context.Context, orgID int64, w alerting_models.MaintenanceWindow) (alerting_models.MaintenanceWindow, error)
+	DeleteMaintenanceWindow(ctx context.Context, orgID int64, uid string, provenance alerting_models.Provenance) error
+}
+
+type EscalationChainService interface {
+	GetEscalationChains(ctx context.Context, orgID int64) ([]alerting_models.EscalationChain, error)
+	GetEscalationChain(ctx context.Context, orgID int64, uid string) (alerting_models.EscalationChain, error)
+	CreateEscalationChain(ctx context.Context, orgID int64, c alerting_models.EscalationChain) (alerting_models.EscalationChain, error)
+	UpdateEscalationChain(ctx context.Context, orgID int64, c alerting_models.EscalationChain) (alerting_models.EscalationChain, error)
+	DeleteEscalationChain(ctx context.Context, orgID int64, uid string, provenance alerting_models.Provenance) error
+}
+
 type AlertRuleService interface {
 	GetAlertRules(ctx context.Context, user identity.Requester) ([]*alerting_models.AlertRule, map[string]alerting_models.Provenance, error)
 	GetAlertRule(ctx context.Context, user identity.Requester, ruleUID string) (alerting_models.AlertRule, alerting_models.Provenance, error)
@@ -312,6 +330,104 @@ func (srv *ProvisioningSrv) RouteDeleteMuteTiming(c *contextmodel.ReqContext, na
 	return response.JSON(http.StatusNoContent, nil)
 }
 
+func (srv *ProvisioningSrv) RouteGetMaintenanceWindows(c *contextmodel.ReqContext) response.Response {
+	windows, err := srv.maintenanceWindows.GetMaintenanceWindows(c.Req.Context(), c.SignedInUser.GetOrgID())
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get maintenance windows", err)
+	}
+	result := make(definitions.MaintenanceWindows, 0, len(windows))
+	for _, w := range windows {
+		result = append(result, ApiMaintenanceWindowFromMaintenanceWindow(w))
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func (srv *ProvisioningSrv) RouteGetMaintenanceWindow(c *contextmodel.ReqContext, uid string) response.Response {
+	w, err := srv.maintenanceWindows.GetMaintenanceWindow(c.Req.Context(), c.SignedInUser.GetOrgID(), uid)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get maintenance window", err)
+	}
+	return response.JSON(http.StatusOK, ApiMaintenanceWindowFromMaintenanceWindow(w))
+}
+
+func (srv *ProvisioningSrv) RoutePostMaintenanceWindow(c *contextmodel.ReqContext, mw definitions.MaintenanceWindow) response.Response {
+	w := MaintenanceWindowFromApiMaintenanceWindow(mw)
+	w.Provenance = alerting_models.Provenance(determineProvenance(c))
+	created, err := srv.maintenanceWindows.CreateMaintenanceWindow(c.Req.Context(), c.SignedInUser.GetOrgID(), w)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to create maintenance window", err)
+	}
+	return response.JSON(http.StatusCreated, ApiMaintenanceWindowFromMaintenanceWindow(created))
+}
+
+func (srv *ProvisioningSrv) RoutePutMaintenanceWindow(c *contextmodel.ReqContext, mw definitions.MaintenanceWindow, uid string) response.Response {
+	w := MaintenanceWindowFromApiMaintenanceWindow(mw)
+	w.UID = uid
+	w.Provenance = alerting_models.Provenance(determineProvenance(c))
+	updated, err := srv.maintenanceWindows.UpdateMaintenanceWindow(c.Req.Context(), c.SignedInUser.GetOrgID(), w)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to update maintenance window", err)
+	}
+	return response.JSON(http.StatusAccepted, ApiMaintenanceWindowFromMaintenanceWindow(updated))
+}
+
+func (srv *ProvisioningSrv) RouteDeleteMaintenanceWindow(c *contextmodel.ReqContext, uid string) response.Response {
+	err := srv.maintenanceWindows.DeleteMaintenanceWindow(c.Req.Context(), c.SignedInUser.GetOrgID(), uid, alerting_models.Provenance(determineProvenance(c)))
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to delete maintenance window", err)
+	}
+	return response.JSON(http.StatusNoContent, nil)
+}
+
+func (srv *ProvisioningSrv) RouteGetEscalationChains(c *contextmodel.ReqContext) response.Response {
+	chains, err := srv.escalationChains.GetEscalationChains(c.Req.Context(), c.SignedInUser.GetOrgID())
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get escalation chains", err)
+	}
+	result := make(definitions.EscalationChains, 0, len(chains))
+	for _, ch := range chains {
+		result = append(result, ApiEscalationChainFromEscalationChain(ch))
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func (srv *ProvisioningSrv) RouteGetEscalationChain(c *contextmodel.ReqContext, uid string) response.Response {
+	ch, err := srv.escalationChains.GetEscalationChain(c.Req.Context(), c.SignedInUser.GetOrgID(), uid)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get escalation chain", err)
+	}
+	return response.JSON(http.StatusOK, ApiEscalationChainFromEscalationChain(ch))
+}
+
+func (srv *ProvisioningSrv) RoutePostEscalationChain(c *contextmodel.ReqContext, ec definitions.EscalationChain) response.Response {
+	ch := EscalationChainFromApiEscalationChain(ec)
+	ch.Provenance = alerting_models.Provenance(determineProvenance(c))
+	created, err := srv.escalationChains.CreateEscalationChain(c.Req.Context(), c.SignedInUser.GetOrgID(), ch)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to create escalation chain", err)
+	}
+	return response.JSON(http.StatusCreated, ApiEscalationChainFromEscalationChain(created))
+}
+
+func (srv *ProvisioningSrv) RoutePutEscalationChain(c *contextmodel.ReqContext, ec definitions.EscalationChain, uid string) response.Response {
+	ch := EscalationChainFromApiEscalationChain(ec)
+	ch.UID = uid
+	ch.Provenance = alerting_models.Provenance(determineProvenance(c))
+	updated, err := srv.escalationChains.UpdateEscalationChain(c.Req.Context(), c.SignedInUser.GetOrgID(), ch)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to update escalation chain", err)
+	}
+	return response.JSON(http.StatusAccepted, ApiEscalationChainFromEscalationChain(updated))
+}
+
+func (srv *ProvisioningSrv) RouteDeleteEscalationChain(c *contextmodel.ReqContext, uid string) response.Response {
+	err := srv.escalationChains.DeleteEscalationChain(c.Req.Context(), c.SignedInUser.GetOrgID(), uid, alerting_models.Provenance(determineProvenance(c)))
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to delete escalation chain", err)
+	}
+	return response.JSON(http.StatusNoContent, nil)
+}
+
 func (srv *ProvisioningSrv) RouteGetAlertRules(c *contextmodel.ReqContext) response.Response {
 	rules, provenances, err := srv.alertRules.GetAlertRules(c.Req.Context(), c.SignedInUser)
 	if err != nil {
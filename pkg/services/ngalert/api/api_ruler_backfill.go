@@ -0,0 +1,125 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/backtesting"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// RecordingRuleBackfiller evaluates recording rules over past time ranges.
+type RecordingRuleBackfiller interface {
+	Start(ctx context.Context, rule *ngmodels.AlertRule, from, to time.Time) (*ngmodels.RecordingRuleBackfill, error)
+	Resume(ctx context.Context, rule *ngmodels.AlertRule, uid string) (*ngmodels.RecordingRuleBackfill, error)
+	Cancel(ctx context.Context, rule *ngmodels.AlertRule, uid string) (*ngmodels.RecordingRuleBackfill, error)
+	List(ctx context.Context, rule *ngmodels.AlertRule) ([]*ngmodels.RecordingRuleBackfill, error)
+}
+
+// RoutePostRuleBackfill starts a backfill of the recording rule. The user must be allowed to update the rule.
+func (srv RulerSrv) RoutePostRuleBackfill(c *contextmodel.ReqContext, cmd apimodels.BackfillConfig, ruleUID string) response.Response {
+	rule, errResp := srv.getBackfillRule(c, ruleUID, true)
+	if errResp != nil {
+		return errResp
+	}
+	backfill, err := srv.backfiller.Start(c.Req.Context(), rule, cmd.From, cmd.To)
+	if err != nil {
+		return backfillErrorToResponse(err)
+	}
+	return response.JSON(http.StatusAccepted, toGettableRecordingRuleBackfill(backfill))
+}
+
+// RouteGetRuleBackfills returns the backfills of the recording rule, most recent first.
+func (srv RulerSrv) RouteGetRuleBackfills(c *contextmodel.ReqContext, ruleUID string) response.Response {
+	rule, errResp := srv.getBackfillRule(c, ruleUID, false)
+	if errResp != nil {
+		return errResp
+	}
+	backfills, err := srv.backfiller.List(c.Req.Context(), rule)
+	if err != nil {
+		return backfillErrorToResponse(err)
+	}
+	result := make(apimodels.GettableRecordingRuleBackfills, 0, len(backfills))
+	for _, backfill := range backfills {
+		result = append(result, toGettableRecordingRuleBackfill(backfill))
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// RoutePostRuleBackfillResume resumes a failed or cancelled backfill of the recording rule.
+func (srv RulerSrv) RoutePostRuleBackfillResume(c *contextmodel.ReqContext, ruleUID string, backfillUID string) response.Response {
+	rule, errResp := srv.getBackfillRule(c, ruleUID, true)
+	if errResp != nil {
+		return errResp
+	}
+	backfill, err := srv.backfiller.Resume(c.Req.Context(), rule, backfillUID)
+	if err != nil {
+		return backfillErrorToResponse(err)
+	}
+	return response.JSON(http.StatusAccepted, toGettableRecordingRuleBackfill(backfill))
+}
+
+// RouteDeleteRuleBackfill cancels a running backfill of the recording rule.
+func (srv RulerSrv) RouteDeleteRuleBackfill(c *contextmodel.ReqContext, ruleUID string, backfillUID string) response.Response {
+	rule, errResp := srv.getBackfillRule(c, ruleUID, true)
+	if errResp != nil {
+		return errResp
+	}
+	backfill, err := srv.backfiller.Cancel(c.Req.Context(), rule, backfillUID)
+	if err != nil {
+		return backfillErrorToResponse(err)
+	}
+	return response.JSON(http.StatusOK, toGettableRecordingRuleBackfill(backfill))
+}
+
+// getBackfillRule returns the rule if the user can access it, and, if update is true, change it.
+func (srv RulerSrv) getBackfillRule(c *contextmodel.ReqContext, ruleUID string, update bool) (*ngmodels.AlertRule, response.Response) {
+	if srv.backfiller == nil {
+		return nil, ErrResp(http.StatusNotFound, nil, "Recording rules are not enabled")
+	}
+	ctx := c.Req.Context()
+	rule, err := srv.getAuthorizedRuleByUid(ctx, c, ruleUID)
+	if err != nil {
+		if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
+			return nil, response.Empty(http.StatusNotFound)
+		}
+		return nil, response.ErrOrFallback(http.StatusInternalServerError, "failed to get rule by UID", err)
+	}
+	if update {
+		if err := srv.authz.AuthorizeRuleUpdate(ctx, c.SignedInUser, &rule); err != nil {
+			return nil, response.ErrOrFallback(http.StatusInternalServerError, "failed to authorize access to rule", err)
+		}
+	}
+	return &rule, nil
+}
+
+func backfillErrorToResponse(err error) response.Response {
+	switch {
+	case errors.Is(err, ngmodels.ErrBackfillNotFound):
+		return ErrResp(http.StatusNotFound, err, "")
+	case errors.Is(err, ngmodels.ErrBackfillConflict):
+		return ErrResp(http.StatusConflict, err, "")
+	case errors.Is(err, backtesting.ErrInvalidInputData), errors.Is(err, backtesting.ErrBackfillNotResumable):
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+	return ErrResp(http.StatusInternalServerError, err, "")
+}
+
+func toGettableRecordingRuleBackfill(backfill *ngmodels.RecordingRuleBackfill) apimodels.GettableRecordingRuleBackfill {
+	return apimodels.GettableRecordingRuleBackfill{
+		UID:     backfill.UID,
+		RuleUID: backfill.RuleUID,
+		From:    backfill.From,
+		To:      backfill.To,
+		Next:    backfill.Next,
+		State:   string(backfill.State),
+		Error:   backfill.Error,
+		Created: backfill.Created,
+		Updated: backfill.Updated,
+	}
+}
@@ -0,0 +1,129 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/backtesting"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
+)
+
+func TestRouteRuleBackfill(t *testing.T) {
+	orgID := rand.Int63()
+	folder := randFolder()
+	ruleStore := fakes.NewRuleStore(t)
+	ruleStore.Folders[orgID] = append(ruleStore.Folders[orgID], folder)
+	groupKey := models.GenerateGroupKey(orgID)
+	groupKey.NamespaceUID = folder.UID
+	gen := models.RuleGen.With(models.RuleGen.WithGroupKey(groupKey), models.RuleGen.WithAllRecordingRules())
+	rule := gen.GenerateRef()
+	ruleStore.PutRule(context.Background(), rule)
+
+	readPerms := createPermissionsForRules([]*models.AlertRule{rule}, orgID)
+	writePerms := createPermissionsForRules([]*models.AlertRule{rule}, orgID)
+	writePerms[orgID][ac.ActionAlertingRuleUpdate] = []string{dashboards.ScopeFoldersProvider.GetResourceScopeUID(folder.UID)}
+
+	now := time.Now().Truncate(time.Second)
+	cmd := apimodels.BackfillConfig{From: now.Add(-time.Hour), To: now}
+
+	t.Run("returns 404 when recording rules are disabled", func(t *testing.T) {
+		srv := createService(ruleStore)
+		response := srv.RoutePostRuleBackfill(createRequestContextWithPerms(orgID, writePerms, nil), cmd, rule.UID)
+		require.Equal(t, http.StatusNotFound, response.Status())
+	})
+
+	t.Run("starts a backfill", func(t *testing.T) {
+		backfiller := &fakeBackfiller{}
+		srv := createService(ruleStore)
+		srv.backfiller = backfiller
+
+		response := srv.RoutePostRuleBackfill(createRequestContextWithPerms(orgID, writePerms, nil), cmd, rule.UID)
+		require.Equal(t, http.StatusAccepted, response.Status())
+
+		result := apimodels.GettableRecordingRuleBackfill{}
+		require.NoError(t, json.Unmarshal(response.Body(), &result))
+		require.Equal(t, rule.UID, result.RuleUID)
+		require.Equal(t, string(models.BackfillStateRunning), result.State)
+		require.True(t, cmd.From.Equal(backfiller.from))
+		require.True(t, cmd.To.Equal(backfiller.to))
+	})
+
+	t.Run("requires permission to update the rule", func(t *testing.T) {
+		srv := createService(ruleStore)
+		srv.backfiller = &fakeBackfiller{}
+
+		response := srv.RoutePostRuleBackfill(createRequestContextWithPerms(orgID, readPerms, nil), cmd, rule.UID)
+		require.Equal(t, http.StatusForbidden, response.Status())
+
+		response = srv.RouteGetRuleBackfills(createRequestContextWithPerms(orgID, readPerms, nil), rule.UID)
+		require.Equal(t, http.StatusOK, response.Status())
+	})
+
+	t.Run("returns 400 for invalid time range", func(t *testing.T) {
+		srv := createService(ruleStore)
+		srv.backfiller = &fakeBackfiller{err: backtesting.ErrInvalidInputData}
+
+		response := srv.RoutePostRuleBackfill(createRequestContextWithPerms(orgID, writePerms, nil), cmd, rule.UID)
+		require.Equal(t, http.StatusBadRequest, response.Status())
+	})
+
+	t.Run("returns 404 for unknown backfill", func(t *testing.T) {
+		srv := createService(ruleStore)
+		srv.backfiller = &fakeBackfiller{err: models.ErrBackfillNotFound}
+
+		response := srv.RouteDeleteRuleBackfill(createRequestContextWithPerms(orgID, writePerms, nil), rule.UID, "unknown")
+		require.Equal(t, http.StatusNotFound, response.Status())
+	})
+
+	t.Run("returns 404 for unknown rule", func(t *testing.T) {
+		srv := createService(ruleStore)
+		srv.backfiller = &fakeBackfiller{}
+
+		response := srv.RouteGetRuleBackfills(createRequestContextWithPerms(orgID, readPerms, nil), "unknown")
+		require.Equal(t, http.StatusNotFound, response.Status())
+	})
+}
+
+type fakeBackfiller struct {
+	err      error
+	from, to time.Time
+}
+
+func (f *fakeBackfiller) Start(_ context.Context, rule *models.AlertRule, from, to time.Time) (*models.RecordingRuleBackfill, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.from, f.to = from, to
+	return &models.RecordingRuleBackfill{UID: "backfill", OrgID: rule.OrgID, RuleUID: rule.UID, From: from, To: to, Next: from, State: models.BackfillStateRunning}, nil
+}
+
+func (f *fakeBackfiller) Resume(_ context.Context, rule *models.AlertRule, uid string) (*models.RecordingRuleBackfill, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &models.RecordingRuleBackfill{UID: uid, OrgID: rule.OrgID, RuleUID: rule.UID, State: models.BackfillStateRunning}, nil
+}
+
+func (f *fakeBackfiller) Cancel(_ context.Context, rule *models.AlertRule, uid string) (*models.RecordingRuleBackfill, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &models.RecordingRuleBackfill{UID: uid, OrgID: rule.OrgID, RuleUID: rule.UID, State: models.BackfillStateCancelled}, nil
+}
+
+func (f *fakeBackfiller) List(_ context.Context, rule *models.AlertRule) ([]*models.RecordingRuleBackfill, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return nil, nil
+}
@@ -0,0 +1,160 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/prometheus/prometheus/model/rulefmt"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/prom"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+// RoutePostPrometheusRulesImport converts the rule groups of a Prometheus rule file to Grafana-managed rules and replaces the groups
+// with the same names in the folder. Converted rules update the existing rules of the group they are imported to with the same title,
+// so that importing the same file again does not change the UIDs of the rules. Rules of other groups are never matched. All groups
+// are updated in a single transaction.
+func (srv RulerSrv) RoutePostPrometheusRulesImport(c *contextmodel.ReqContext, body apimodels.PrometheusRulesImport, namespaceUID string) response.Response {
+	namespace, err := srv.store.GetNamespaceByUID(c.Req.Context(), namespaceUID, c.SignedInUser.GetOrgID(), c.SignedInUser)
+	if err != nil {
+		return toNamespaceErrorResponse(err)
+	}
+
+	groups, err := srv.convertPrometheusRules(c.SignedInUser.GetOrgID(), namespace.UID, body)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+
+	existing, err := srv.store.ListAlertRules(c.Req.Context(), &ngmodels.ListAlertRulesQuery{
+		OrgID:         c.SignedInUser.GetOrgID(),
+		NamespaceUIDs: []string{namespace.UID},
+	})
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get rules in the folder")
+	}
+	uidsByGroupAndTitle := make(map[string]map[string]string)
+	for _, r := range existing {
+		if uidsByGroupAndTitle[r.RuleGroup] == nil {
+			uidsByGroupAndTitle[r.RuleGroup] = make(map[string]string)
+		}
+		uidsByGroupAndTitle[r.RuleGroup][r.Title] = r.UID
+	}
+
+	result := apimodels.PrometheusRulesImportResponse{
+		DryRun: body.DryRun,
+		Groups: make([]apimodels.PrometheusRulesImportGroupDelta, 0, len(groups)),
+	}
+	var dbConfig *ngmodels.AlertConfiguration
+	err = srv.xactManager.InTransaction(c.Req.Context(), func(tranCtx context.Context) error {
+		for _, group := range groups {
+			rules := make([]*ngmodels.AlertRuleWithOptionals, 0, len(group.Rules))
+			for _, r := range group.Rules {
+				r.UID = uidsByGroupAndTitle[group.Title][r.Title]
+				rules = append(rules, &ngmodels.AlertRuleWithOptionals{AlertRule: r})
+			}
+			groupKey := ngmodels.AlertRuleGroupKey{
+				OrgID:        c.SignedInUser.GetOrgID(),
+				NamespaceUID: namespace.UID,
+				RuleGroup:    group.Title,
+			}
+			changes, cfg, err := srv.applyRuleGroupChanges(tranCtx, c, groupKey, rules, c.Req.Header.Get(changeMessageHeaderName), body.DryRun)
+			if err != nil {
+				return fmt.Errorf("failed to import rule group '%s': %w", group.Title, err)
+			}
+			if cfg != nil {
+				dbConfig = cfg
+			}
+			result.Groups = append(result.Groups, toPrometheusRulesImportGroupDelta(group.Title, changes))
+		}
+		return nil
+	})
+	if err != nil {
+		return toRuleGroupUpdateErrorResponse(err)
+	}
+
+	if body.DryRun {
+		return response.JSON(http.StatusOK, result)
+	}
+	srv.refreshAlertmanagerConfig(c, c.SignedInUser.GetOrgID(), dbConfig)
+	return response.JSON(http.StatusAccepted, result)
+}
+
+// convertPrometheusRules parses the Prometheus rule file and converts its groups to validated rule groups in the folder.
+func (srv RulerSrv) convertPrometheusRules(orgID int64, namespaceUID string, body apimodels.PrometheusRulesImport) ([]ngmodels.AlertRuleGroup, error) {
+	ruleFile, errs := rulefmt.Parse([]byte(body.Rules))
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid Prometheus rule file: %w", errors.Join(errs...))
+	}
+
+	cfg := prom.Config{
+		DatasourceUID:                     body.DatasourceUID,
+		DefaultInterval:                   srv.cfg.DefaultRuleEvaluationInterval,
+		RecordingRulesTargetDatasourceUID: body.RecordingRulesTargetDatasourceUID,
+	}
+	if body.NoDataState != "" {
+		noDataState, err := ngmodels.NoDataStateFromString(string(body.NoDataState))
+		if err != nil {
+			return nil, err
+		}
+		cfg.NoDataState = noDataState
+	}
+	if body.ExecErrState != "" {
+		execErrState, err := ngmodels.ErrStateFromString(string(body.ExecErrState))
+		if err != nil {
+			return nil, err
+		}
+		cfg.ExecErrState = execErrState
+	}
+	converter, err := prom.NewConverter(cfg)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := converter.PrometheusRulesToGrafana(orgID, namespaceUID, ruleFile.Groups)
+	if err != nil {
+		return nil, err
+	}
+
+	limits := RuleLimitsFromConfig(srv.cfg, srv.featureManager)
+	for _, group := range groups {
+		for _, rule := range group.Rules {
+			if rule.Type() == ngmodels.RuleTypeRecording && !limits.RecordingRulesAllowed {
+				return nil, fmt.Errorf("%w: recording rules cannot be created on this instance", ngmodels.ErrAlertRuleFailedValidation)
+			}
+			if len(rule.Title) > store.AlertRuleMaxTitleLength {
+				return nil, fmt.Errorf("alert rule title '%s' is too long. Max length is %d", rule.Title, store.AlertRuleMaxTitleLength)
+			}
+			if err := rule.ValidateAlertRule(*srv.cfg); err != nil {
+				return nil, fmt.Errorf("invalid rule '%s' in group '%s': %w", rule.Title, group.Title, err)
+			}
+		}
+	}
+	return groups, nil
+}
+
+func toPrometheusRulesImportGroupDelta(name string, changes *store.GroupDelta) apimodels.PrometheusRulesImportGroupDelta {
+	delta := apimodels.PrometheusRulesImportGroupDelta{
+		Name:    name,
+		Created: make([]string, 0, len(changes.New)),
+		Updated: make([]apimodels.PrometheusRulesImportDiff, 0, len(changes.Update)),
+		Deleted: make([]string, 0, len(changes.Delete)),
+	}
+	for _, r := range changes.New {
+		delta.Created = append(delta.Created, r.Title)
+	}
+	for _, r := range changes.Update {
+		delta.Updated = append(delta.Updated, apimodels.PrometheusRulesImportDiff{
+			Title:  r.New.Title,
+			Fields: r.Diff.Paths(),
+		})
+	}
+	for _, r := range changes.Delete {
+		delta.Deleted = append(delta.Deleted, r.Title)
+	}
+	return delta
+}
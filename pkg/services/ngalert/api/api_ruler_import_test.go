@@ -0,0 +1,248 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/prom"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
+	"github.com/grafana/grafana/pkg/services/quota/quotatest"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const importTestRules = `
+groups:
+  - name: node
+    interval: 30s
+    rules:
+      - alert: InstanceDown
+        expr: up == 0
+        for: 5m
+        labels:
+          severity: critical
+        annotations:
+          summary: Instance {{ $labels.instance }} is down
+      - record: job:up:sum
+        expr: sum by (job) (up)
+`
+
+func TestRoutePostPrometheusRulesImport(t *testing.T) {
+	orgID := rand.Int63()
+	folder := randFolder()
+	scope := dashboards.ScopeFoldersProvider.GetResourceScopeUID(folder.UID)
+	perms := map[int64]map[string][]string{orgID: {
+		dashboards.ActionFoldersRead: {scope},
+		ac.ActionAlertingRuleRead:    {scope},
+		ac.ActionAlertingRuleCreate:  {scope},
+		ac.ActionAlertingRuleUpdate:  {scope},
+		ac.ActionAlertingRuleDelete:  {scope},
+		datasources.ActionQuery:      {datasources.ScopeAll},
+	}}
+
+	createImportService := func(ruleStore *fakes.RuleStore) *RulerSrv {
+		srv := createService(ruleStore)
+		srv.QuotaService = quotatest.New(false, nil)
+		srv.conditionValidator = &recordingConditionValidator{}
+		srv.cfg.DefaultRuleEvaluationInterval = time.Minute
+		return srv
+	}
+	createStore := func() *fakes.RuleStore {
+		ruleStore := fakes.NewRuleStore(t)
+		ruleStore.Folders[orgID] = append(ruleStore.Folders[orgID], folder)
+		return ruleStore
+	}
+	listRules := func(t *testing.T, ruleStore *fakes.RuleStore) models.RulesGroup {
+		rules, err := ruleStore.ListAlertRules(context.Background(), &models.ListAlertRulesQuery{OrgID: orgID, NamespaceUIDs: []string{folder.UID}})
+		require.NoError(t, err)
+		return rules
+	}
+	getInsertedRules := func(ruleStore *fakes.RuleStore) []models.AlertRule {
+		var result []models.AlertRule
+		for _, cmd := range ruleStore.GetRecordedCommands(func(cmd any) (any, bool) {
+			c, ok := cmd.([]models.AlertRule)
+			return c, ok
+		}) {
+			result = append(result, cmd.([]models.AlertRule)...)
+		}
+		return result
+	}
+	importRules := func(t *testing.T, srv *RulerSrv, body apimodels.PrometheusRulesImport, expectedStatus int) apimodels.PrometheusRulesImportResponse {
+		t.Helper()
+		response := srv.RoutePostPrometheusRulesImport(createRequestContextWithPerms(orgID, perms, nil), body, folder.UID)
+		require.Equalf(t, expectedStatus, response.Status(), "unexpected response: %s", string(response.Body()))
+		result := apimodels.PrometheusRulesImportResponse{}
+		require.NoError(t, json.Unmarshal(response.Body(), &result))
+		return result
+	}
+
+	t.Run("dry run returns the changes without saving them", func(t *testing.T) {
+		ruleStore := createStore()
+		result := importRules(t, createImportService(ruleStore), apimodels.PrometheusRulesImport{
+			Rules:         importTestRules,
+			DatasourceUID: "prometheus",
+			DryRun:        true,
+		}, http.StatusOK)
+
+		require.True(t, result.DryRun)
+		require.Len(t, result.Groups, 1)
+		require.Equal(t, "node", result.Groups[0].Name)
+		require.Equal(t, []string{"InstanceDown", "job:up:sum"}, result.Groups[0].Created)
+		require.Empty(t, listRules(t, ruleStore))
+	})
+
+	t.Run("creates alerting and recording rules", func(t *testing.T) {
+		ruleStore := createStore()
+		importRules(t, createImportService(ruleStore), apimodels.PrometheusRulesImport{
+			Rules:         importTestRules,
+			DatasourceUID: "prometheus",
+		}, http.StatusAccepted)
+
+		rules := getInsertedRules(ruleStore)
+		require.Len(t, rules, 2)
+
+		alert := rules[0]
+		require.Equal(t, "InstanceDown", alert.Title)
+		require.Equal(t, "node", alert.RuleGroup)
+		require.EqualValues(t, 30, alert.IntervalSeconds)
+		require.Equal(t, 5*time.Minute, alert.For)
+		require.Equal(t, prom.ThresholdRefID, alert.Condition)
+		require.Equal(t, map[string]string{"severity": "critical"}, alert.Labels)
+		require.Equal(t, "Instance {{ $labels.instance }} is down", alert.Annotations["summary"])
+
+		record := rules[1]
+		require.Equal(t, "job:up:sum", record.Title)
+		require.NotNil(t, record.Record)
+		require.Equal(t, "job:up:sum", record.Record.Metric)
+	})
+
+	t.Run("updates rules with the same title and deletes the missing ones", func(t *testing.T) {
+		ruleStore := createStore()
+		srv := createImportService(ruleStore)
+		importRules(t, srv, apimodels.PrometheusRulesImport{
+			Rules:         importTestRules,
+			DatasourceUID: "prometheus",
+		}, http.StatusAccepted)
+		existing := getInsertedRules(ruleStore)
+		require.Len(t, existing, 2)
+		for i := range existing {
+			existing[i].UID = util.GenerateShortUID()
+			ruleStore.PutRule(context.Background(), &existing[i])
+		}
+
+		result := importRules(t, srv, apimodels.PrometheusRulesImport{
+			Rules: `
+groups:
+  - name: node
+    interval: 30s
+    rules:
+      - alert: InstanceDown
+        expr: up == 0
+        for: 10m
+`,
+			DatasourceUID: "prometheus",
+		}, http.StatusAccepted)
+
+		require.Len(t, result.Groups, 1)
+		require.Empty(t, result.Groups[0].Created)
+		require.Len(t, result.Groups[0].Updated, 1)
+		require.Equal(t, "InstanceDown", result.Groups[0].Updated[0].Title)
+		require.Contains(t, result.Groups[0].Updated[0].Fields, "For")
+		require.Equal(t, []string{"job:up:sum"}, result.Groups[0].Deleted)
+
+		updates := ruleStore.GetRecordedCommands(func(cmd any) (any, bool) {
+			c, ok := cmd.([]models.UpdateRule)
+			return c, ok
+		})
+		require.Len(t, updates, 1)
+		update := updates[0].([]models.UpdateRule)
+		require.Len(t, update, 1)
+		require.Equal(t, existing[0].UID, update[0].New.UID)
+		require.Equal(t, 10*time.Minute, update[0].New.For)
+
+		rules := listRules(t, ruleStore)
+		require.Len(t, rules, 1)
+		require.Equal(t, existing[0].UID, rules[0].UID)
+	})
+
+	t.Run("does not update rules with the same title in other groups", func(t *testing.T) {
+		ruleStore := createStore()
+		srv := createImportService(ruleStore)
+		importRules(t, srv, apimodels.PrometheusRulesImport{
+			Rules:         importTestRules,
+			DatasourceUID: "prometheus",
+		}, http.StatusAccepted)
+		other := getInsertedRules(ruleStore)[0]
+		other.UID = util.GenerateShortUID()
+		other.RuleGroup = "other"
+		ruleStore.PutRule(context.Background(), &other)
+
+		result := importRules(t, srv, apimodels.PrometheusRulesImport{
+			Rules: `
+groups:
+  - name: node
+    interval: 30s
+    rules:
+      - alert: InstanceDown
+        expr: up == 0
+`,
+			DatasourceUID: "prometheus",
+		}, http.StatusAccepted)
+
+		require.Len(t, result.Groups, 1)
+		require.Equal(t, []string{"InstanceDown"}, result.Groups[0].Created)
+		require.Empty(t, result.Groups[0].Updated)
+
+		require.Empty(t, ruleStore.GetRecordedCommands(func(cmd any) (any, bool) {
+			c, ok := cmd.([]models.UpdateRule)
+			return c, ok
+		}))
+		rules := listRules(t, ruleStore)
+		require.Len(t, rules, 1)
+		require.Equal(t, other.UID, rules[0].UID)
+		require.Equal(t, "other", rules[0].RuleGroup)
+	})
+
+	t.Run("returns 400 for invalid rule file", func(t *testing.T) {
+		srv := createImportService(createStore())
+		response := srv.RoutePostPrometheusRulesImport(createRequestContextWithPerms(orgID, perms, nil), apimodels.PrometheusRulesImport{
+			Rules:         "groups:\n  - name: node\n    rules:\n      - alert: InstanceDown\n",
+			DatasourceUID: "prometheus",
+		}, folder.UID)
+		require.Equal(t, http.StatusBadRequest, response.Status())
+	})
+
+	t.Run("returns 400 without data source", func(t *testing.T) {
+		srv := createImportService(createStore())
+		response := srv.RoutePostPrometheusRulesImport(createRequestContextWithPerms(orgID, perms, nil), apimodels.PrometheusRulesImport{
+			Rules: importTestRules,
+		}, folder.UID)
+		require.Equal(t, http.StatusBadRequest, response.Status())
+	})
+
+	t.Run("requires permission to create rules", func(t *testing.T) {
+		ruleStore := createStore()
+		readPerms := map[int64]map[string][]string{orgID: {
+			dashboards.ActionFoldersRead: {scope},
+			ac.ActionAlertingRuleRead:    {scope},
+			datasources.ActionQuery:      {datasources.ScopeAll},
+		}}
+		srv := createImportService(ruleStore)
+		response := srv.RoutePostPrometheusRulesImport(createRequestContextWithPerms(orgID, readPerms, nil), apimodels.PrometheusRulesImport{
+			Rules:         importTestRules,
+			DatasourceUID: "prometheus",
+		}, folder.UID)
+		require.Equal(t, http.StatusForbidden, response.Status())
+		require.Empty(t, listRules(t, ruleStore))
+	})
+}
@@ -0,0 +1,247 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/util/cmputil"
+)
+
+// RouteGetRuleVersionsByUID returns the versions of the rule, most recent first.
+func (srv RulerSrv) RouteGetRuleVersionsByUID(c *contextmodel.ReqContext, ruleUID string) response.Response {
+	rule, errResp := srv.getVersionedRule(c, ruleUID, false)
+	if errResp != nil {
+		return errResp
+	}
+	versions, err := srv.store.GetAlertRuleVersions(c.Req.Context(), rule.OrgID, rule.UID)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get rule versions", err)
+	}
+	result := make(apimodels.GettableRuleVersions, 0, len(versions))
+	for _, version := range versions {
+		result = append(result, toGettableRuleVersion(version))
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// RouteGetRuleVersionsDiff returns the changes of the rule between two versions.
+func (srv RulerSrv) RouteGetRuleVersionsDiff(c *contextmodel.ReqContext, ruleUID string) response.Response {
+	from, err := strconv.ParseInt(c.Query("from"), 10, 64)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, errors.New("from must be a version of the rule"), "")
+	}
+	to, err := strconv.ParseInt(c.Query("to"), 10, 64)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, errors.New("to must be a version of the rule"), "")
+	}
+	rule, errResp := srv.getVersionedRule(c, ruleUID, false)
+	if errResp != nil {
+		return errResp
+	}
+	fromVersion, errResp := srv.getRuleVersion(c, rule, from)
+	if errResp != nil {
+		return errResp
+	}
+	toVersion, errResp := srv.getRuleVersion(c, rule, to)
+	if errResp != nil {
+		return errResp
+	}
+	return response.JSON(http.StatusOK, toRuleVersionDiff(fromVersion, toVersion))
+}
+
+// RoutePostRuleVersionRestore restores the definition that the rule had in the version. The change is applied to the
+// group of the rule like any other change, which validates it and checks that the user can make it.
+func (srv RulerSrv) RoutePostRuleVersionRestore(c *contextmodel.ReqContext, body apimodels.RuleVersionRestore, ruleUID string, version string) response.Response {
+	v, err := strconv.ParseInt(version, 10, 64)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, errors.New("version must be a number"), "")
+	}
+	rule, errResp := srv.getVersionedRule(c, ruleUID, true)
+	if errResp != nil {
+		return errResp
+	}
+	ruleVersion, errResp := srv.getRuleVersion(c, rule, v)
+	if errResp != nil {
+		return errResp
+	}
+	restored, err := restoreRuleVersion(rule, ruleVersion)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+
+	groupKey := rule.GetGroupKey()
+	group, err := srv.getAuthorizedRuleGroup(c.Req.Context(), c, groupKey)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get rule group", err)
+	}
+	rules := make([]*ngmodels.AlertRuleWithOptionals, 0, len(group))
+	for _, r := range group {
+		if r.UID == restored.UID {
+			r = restored
+		}
+		rules = append(rules, &ngmodels.AlertRuleWithOptionals{AlertRule: *r, HasPause: true})
+	}
+
+	message := body.Message
+	if message == "" {
+		message = fmt.Sprintf("Restored version %d", ruleVersion.Version)
+	}
+	return srv.updateAlertRulesInGroup(c, groupKey, rules, message)
+}
+
+// getVersionedRule returns the rule if the user can access it, and, if update is true, change it.
+func (srv RulerSrv) getVersionedRule(c *contextmodel.ReqContext, ruleUID string, update bool) (*ngmodels.AlertRule, response.Response) {
+	ctx := c.Req.Context()
+	rule, err := srv.getAuthorizedRuleByUid(ctx, c, ruleUID)
+	if err != nil {
+		if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
+			return nil, response.Empty(http.StatusNotFound)
+		}
+		return nil, response.ErrOrFallback(http.StatusInternalServerError, "failed to get rule by UID", err)
+	}
+	if update {
+		if err := srv.authz.AuthorizeRuleUpdate(ctx, c.SignedInUser, &rule); err != nil {
+			return nil, response.ErrOrFallback(http.StatusInternalServerError, "failed to authorize access to rule", err)
+		}
+	}
+	return &rule, nil
+}
+
+func (srv RulerSrv) getRuleVersion(c *contextmodel.ReqContext, rule *ngmodels.AlertRule, version int64) (*ngmodels.AlertRuleVersion, response.Response) {
+	result, err := srv.store.GetAlertRuleVersion(c.Req.Context(), rule.OrgID, rule.UID, version)
+	if err != nil {
+		if errors.Is(err, ngmodels.ErrAlertRuleVersionNotFound) {
+			return nil, ErrResp(http.StatusNotFound, err, "")
+		}
+		return nil, response.ErrOrFallback(http.StatusInternalServerError, "failed to get rule version", err)
+	}
+	return result, nil
+}
+
+// restoreRuleVersion returns a copy of the rule with the definition that it had in the version. The rule keeps its
+// folder, group, position in the group and evaluation interval, which are set by the group. It also keeps its paused
+// state if the version did not record it.
+func restoreRuleVersion(rule *ngmodels.AlertRule, version *ngmodels.AlertRuleVersion) (*ngmodels.AlertRule, error) {
+	restored := ngmodels.CopyRule(rule)
+	restored.Title = version.Title
+	restored.Condition = version.Condition
+	restored.Data = version.Data
+	restored.Record = version.Record
+	restored.NoDataState = version.NoDataState
+	restored.ExecErrState = version.ExecErrState
+	restored.For = version.For
+	restored.KeepFiringFor = version.KeepFiringFor
+	restored.Annotations = version.Annotations
+	restored.Labels = version.Labels
+	if version.HasPause {
+		restored.IsPaused = version.IsPaused
+	}
+	restored.NotificationSettings = version.NotificationSettings
+	restored.DependsOn = version.DependsOn
+	restored.DashboardUID = nil
+	restored.PanelID = nil
+	if err := restored.SetDashboardAndPanelFromAnnotations(); err != nil {
+		return nil, err
+	}
+	return restored, nil
+}
+
+func toGettableRuleVersion(version *ngmodels.AlertRuleVersion) apimodels.GettableRuleVersion {
+	return apimodels.GettableRuleVersion{
+		Version:       version.Version,
+		ParentVersion: version.ParentVersion,
+		Created:       version.Created,
+		UpdatedBy:     version.UpdatedBy,
+		Message:       version.Message,
+		Rule:          toGettableExtendedRuleNode(*version.ToAlertRule(), nil),
+	}
+}
+
+func toRuleVersionDiff(from, to *ngmodels.AlertRuleVersion) apimodels.RuleVersionDiff {
+	fromRule, toRule := from.ToAlertRule(), to.ToAlertRule()
+	diff := fromRule.Diff(toRule, store.AlertRuleFieldsToIgnoreInDiff[:]...)
+	changes := make([]apimodels.RuleVersionChange, 0, len(diff))
+	for _, d := range diff {
+		changes = append(changes, apimodels.RuleVersionChange{
+			Path: d.Path,
+			Kind: ruleVersionChangeKind(d, fromRule, toRule),
+			Old:  ruleVersionChangeValue(d.Left),
+			New:  ruleVersionChangeValue(d.Right),
+		})
+	}
+	return apimodels.RuleVersionDiff{
+		RuleUID: to.RuleUID,
+		From:    from.Version,
+		To:      to.Version,
+		Changes: changes,
+	}
+}
+
+// ruleVersionChangeKind tells which part of the rule the change is in. Changes of the data of the rule are changes of
+// a query or of an expression, depending on the data source of the changed element.
+func ruleVersionChangeKind(d cmputil.Diff, from, to *ngmodels.AlertRule) string {
+	field, _, _ := strings.Cut(d.Path, ".")
+	field, _, _ = strings.Cut(field, "[")
+	switch field {
+	case "Data":
+		var query *ngmodels.AlertQuery
+		var idx int
+		if _, err := fmt.Sscanf(d.Path, "Data[%d]", &idx); err == nil {
+			if idx < len(to.Data) {
+				query = &to.Data[idx]
+			} else if idx < len(from.Data) {
+				query = &from.Data[idx]
+			}
+		} else {
+			for _, v := range []reflect.Value{d.Right, d.Left} {
+				if q, ok := reflectValueInterface(v).(ngmodels.AlertQuery); ok {
+					query = &q
+					break
+				}
+			}
+		}
+		if query != nil {
+			if isExpr, _ := query.IsExpression(); isExpr {
+				return "expression"
+			}
+		}
+		return "query"
+	case "Labels":
+		return "labels"
+	case "Annotations":
+		return "annotations"
+	case "NotificationSettings":
+		return "notification_settings"
+	}
+	return "rule"
+}
+
+func ruleVersionChangeValue(v reflect.Value) any {
+	switch value := reflectValueInterface(v).(type) {
+	case time.Duration:
+		return value.String()
+	case ngmodels.AlertQuery:
+		return ApiAlertQueriesFromAlertQueries([]ngmodels.AlertQuery{value})[0]
+	case []ngmodels.AlertQuery:
+		return ApiAlertQueriesFromAlertQueries(value)
+	default:
+		return value
+	}
+}
+
+func reflectValueInterface(v reflect.Value) any {
+	if !v.IsValid() || !v.CanInterface() {
+		return nil
+	}
+	return v.Interface()
+}
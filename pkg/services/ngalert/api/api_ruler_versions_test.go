@@ -0,0 +1,239 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
+	"github.com/grafana/grafana/pkg/services/quota/quotatest"
+)
+
+func TestRouteRuleVersions(t *testing.T) {
+	orgID := rand.Int63()
+	folder := randFolder()
+	groupKey := models.GenerateGroupKey(orgID)
+	groupKey.NamespaceUID = folder.UID
+	gen := models.RuleGen.With(
+		models.RuleGen.WithGroupKey(groupKey),
+		models.RuleGen.WithNoNotificationSettings(),
+		models.RuleGen.WithLabels(data.Labels{"severity": "critical"}),
+	)
+	rule := gen.GenerateRef()
+	rule.DashboardUID = nil
+	rule.PanelID = nil
+	delete(rule.Annotations, models.DashboardUIDAnnotation)
+	delete(rule.Annotations, models.PanelIDAnnotation)
+
+	first := &models.AlertRuleVersion{
+		RuleOrgID:        orgID,
+		RuleUID:          rule.UID,
+		RuleNamespaceUID: rule.NamespaceUID,
+		RuleGroup:        rule.RuleGroup,
+		Version:          1,
+		Created:          time.Now().Add(-time.Hour),
+		Title:            "old title",
+		Condition:        rule.Condition,
+		Data:             rule.Data,
+		IntervalSeconds:  rule.IntervalSeconds,
+		NoDataState:      rule.NoDataState,
+		ExecErrState:     rule.ExecErrState,
+		For:              rule.For,
+		Annotations:      rule.Annotations,
+		Labels:           map[string]string{"severity": "warning"},
+		UpdatedBy:        "admin",
+		Message:          "create rule",
+	}
+	second := &models.AlertRuleVersion{
+		RuleOrgID:        orgID,
+		RuleUID:          rule.UID,
+		RuleNamespaceUID: rule.NamespaceUID,
+		RuleGroup:        rule.RuleGroup,
+		ParentVersion:    1,
+		Version:          2,
+		Created:          time.Now(),
+		Title:            rule.Title,
+		Condition:        rule.Condition,
+		Data:             rule.Data,
+		IntervalSeconds:  rule.IntervalSeconds,
+		NoDataState:      rule.NoDataState,
+		ExecErrState:     rule.ExecErrState,
+		For:              rule.For,
+		Annotations:      rule.Annotations,
+		Labels:           rule.Labels,
+		UpdatedBy:        "editor",
+	}
+
+	createStore := func() *fakes.RuleStore {
+		ruleStore := fakes.NewRuleStore(t)
+		ruleStore.Folders[orgID] = append(ruleStore.Folders[orgID], folder)
+		ruleStore.PutRule(context.Background(), models.CopyRule(rule))
+		ruleStore.Versions[orgID] = []*models.AlertRuleVersion{first, second}
+		return ruleStore
+	}
+	createVersionService := func(ruleStore *fakes.RuleStore) *RulerSrv {
+		srv := createService(ruleStore)
+		srv.QuotaService = quotatest.New(false, nil)
+		srv.conditionValidator = &recordingConditionValidator{}
+		return srv
+	}
+
+	readPerms := createPermissionsForRules([]*models.AlertRule{rule}, orgID)
+	writePerms := createPermissionsForRules([]*models.AlertRule{rule}, orgID)
+	writePerms[orgID][ac.ActionAlertingRuleUpdate] = []string{dashboards.ScopeFoldersProvider.GetResourceScopeUID(folder.UID)}
+
+	t.Run("lists the versions of the rule", func(t *testing.T) {
+		srv := createVersionService(createStore())
+
+		response := srv.RouteGetRuleVersionsByUID(createRequestContextWithPerms(orgID, readPerms, nil), rule.UID)
+		require.Equal(t, http.StatusOK, response.Status())
+
+		result := apimodels.GettableRuleVersions{}
+		require.NoError(t, json.Unmarshal(response.Body(), &result))
+		require.Len(t, result, 2)
+		require.Equal(t, int64(2), result[0].Version)
+		require.Equal(t, "editor", result[0].UpdatedBy)
+		require.Equal(t, rule.Title, result[0].Rule.GrafanaManagedAlert.Title)
+		require.Equal(t, int64(1), result[1].Version)
+		require.Equal(t, "admin", result[1].UpdatedBy)
+		require.Equal(t, "create rule", result[1].Message)
+		require.Equal(t, "old title", result[1].Rule.GrafanaManagedAlert.Title)
+	})
+
+	t.Run("compares two versions of the rule", func(t *testing.T) {
+		srv := createVersionService(createStore())
+
+		c := createRequestContextWithPerms(orgID, readPerms, nil)
+		c.Req.Form.Set("from", "1")
+		c.Req.Form.Set("to", "2")
+		response := srv.RouteGetRuleVersionsDiff(c, rule.UID)
+		require.Equal(t, http.StatusOK, response.Status())
+
+		result := apimodels.RuleVersionDiff{}
+		require.NoError(t, json.Unmarshal(response.Body(), &result))
+		require.Equal(t, int64(1), result.From)
+		require.Equal(t, int64(2), result.To)
+		changes := make(map[string]apimodels.RuleVersionChange, len(result.Changes))
+		for _, change := range result.Changes {
+			changes[change.Path] = change
+		}
+		require.Len(t, changes, 2)
+		require.Equal(t, apimodels.RuleVersionChange{Path: "Title", Kind: "rule", Old: "old title", New: rule.Title}, changes["Title"])
+		require.Equal(t, apimodels.RuleVersionChange{Path: "Labels[severity]", Kind: "labels", Old: "warning", New: "critical"}, changes["Labels[severity]"])
+	})
+
+	t.Run("returns 400 if the versions are not numbers", func(t *testing.T) {
+		srv := createVersionService(createStore())
+
+		c := createRequestContextWithPerms(orgID, readPerms, nil)
+		c.Req.Form.Set("from", "first")
+		c.Req.Form.Set("to", "2")
+		response := srv.RouteGetRuleVersionsDiff(c, rule.UID)
+		require.Equal(t, http.StatusBadRequest, response.Status())
+	})
+
+	t.Run("restores a version of the rule", func(t *testing.T) {
+		ruleStore := createStore()
+		srv := createVersionService(ruleStore)
+
+		c := createRequestContextWithPerms(orgID, writePerms, nil)
+		c.SignedInUser.Login = "editor"
+		response := srv.RoutePostRuleVersionRestore(c, apimodels.RuleVersionRestore{}, rule.UID, "1")
+		require.Equal(t, http.StatusAccepted, response.Status())
+
+		updates := ruleStore.GetRecordedCommands(func(cmd any) (any, bool) {
+			c, ok := cmd.([]models.UpdateRule)
+			return c, ok
+		})
+		require.Len(t, updates, 1)
+		update := updates[0].([]models.UpdateRule)
+		require.Len(t, update, 1)
+		require.Equal(t, rule.UID, update[0].New.UID)
+		require.Equal(t, "old title", update[0].New.Title)
+		require.Equal(t, map[string]string{"severity": "warning"}, update[0].New.Labels)
+		require.Equal(t, rule.RuleGroup, update[0].New.RuleGroup)
+		require.Equal(t, "editor", update[0].New.UpdatedBy)
+		require.Equal(t, "Restored version 1", update[0].New.Message)
+	})
+
+	t.Run("stores the message of the restore", func(t *testing.T) {
+		ruleStore := createStore()
+		srv := createVersionService(ruleStore)
+
+		response := srv.RoutePostRuleVersionRestore(createRequestContextWithPerms(orgID, writePerms, nil), apimodels.RuleVersionRestore{Message: "revert threshold"}, rule.UID, "1")
+		require.Equal(t, http.StatusAccepted, response.Status())
+
+		updates := ruleStore.GetRecordedCommands(func(cmd any) (any, bool) {
+			c, ok := cmd.([]models.UpdateRule)
+			return c, ok
+		})
+		require.Len(t, updates, 1)
+		require.Equal(t, "revert threshold", updates[0].([]models.UpdateRule)[0].New.Message)
+	})
+
+	t.Run("restoring keeps the paused state if the version did not record it", func(t *testing.T) {
+		paused := models.CopyRule(rule)
+		paused.IsPaused = true
+		restore := func(version *models.AlertRuleVersion) models.AlertRule {
+			ruleStore := fakes.NewRuleStore(t)
+			ruleStore.Folders[orgID] = append(ruleStore.Folders[orgID], folder)
+			ruleStore.PutRule(context.Background(), models.CopyRule(paused))
+			ruleStore.Versions[orgID] = []*models.AlertRuleVersion{version}
+			srv := createVersionService(ruleStore)
+
+			response := srv.RoutePostRuleVersionRestore(createRequestContextWithPerms(orgID, writePerms, nil), apimodels.RuleVersionRestore{}, rule.UID, "1")
+			require.Equal(t, http.StatusAccepted, response.Status())
+			updates := ruleStore.GetRecordedCommands(func(cmd any) (any, bool) {
+				c, ok := cmd.([]models.UpdateRule)
+				return c, ok
+			})
+			require.Len(t, updates, 1)
+			return updates[0].([]models.UpdateRule)[0].New
+		}
+
+		legacy := *first
+		require.True(t, restore(&legacy).IsPaused)
+
+		recorded := *first
+		recorded.HasPause = true
+		require.False(t, restore(&recorded).IsPaused)
+	})
+
+	t.Run("restoring requires permission to update the rule", func(t *testing.T) {
+		srv := createVersionService(createStore())
+
+		response := srv.RoutePostRuleVersionRestore(createRequestContextWithPerms(orgID, readPerms, nil), apimodels.RuleVersionRestore{}, rule.UID, "1")
+		require.Equal(t, http.StatusForbidden, response.Status())
+	})
+
+	t.Run("restoring rejects provisioned rules", func(t *testing.T) {
+		ruleStore := createStore()
+		provenanceStore := fakes.NewFakeProvisioningStore()
+		require.NoError(t, provenanceStore.SetProvenance(context.Background(), rule, orgID, models.ProvenanceAPI))
+		srv := createVersionService(ruleStore)
+		srv.provenanceStore = provenanceStore
+
+		response := srv.RoutePostRuleVersionRestore(createRequestContextWithPerms(orgID, writePerms, nil), apimodels.RuleVersionRestore{}, rule.UID, "1")
+		require.Equal(t, http.StatusBadRequest, response.Status())
+	})
+
+	t.Run("returns 404 for unknown versions and rules", func(t *testing.T) {
+		srv := createVersionService(createStore())
+
+		response := srv.RoutePostRuleVersionRestore(createRequestContextWithPerms(orgID, writePerms, nil), apimodels.RuleVersionRestore{}, rule.UID, "3")
+		require.Equal(t, http.StatusNotFound, response.Status())
+
+		response = srv.RouteGetRuleVersionsByUID(createRequestContextWithPerms(orgID, readPerms, nil), "unknown")
+		require.Equal(t, http.StatusNotFound, response.Status())
+	})
+}
@@ -18,6 +18,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
@@ -57,9 +58,29 @@ type TestingApiSrv struct {
 // as true as possible to what would be generated by the ruler except that the resulting alerts are not filtered to
 // only Resolved / Firing and ready to send.
 func (srv TestingApiSrv) RouteTestGrafanaRuleConfig(c *contextmodel.ReqContext, body apimodels.PostableExtendedRuleNodeExtended) response.Response {
+	alerts, errResp := srv.testGrafanaRule(c.Req.Context(), c, body)
+	if errResp != nil {
+		return errResp
+	}
+	return response.JSON(http.StatusOK, alerts)
+}
+
+// RouteTestGrafanaRuleConfigDebug is the same as RouteTestGrafanaRuleConfig but it also returns a trace of the execution
+// of the queries and expressions of the rule.
+func (srv TestingApiSrv) RouteTestGrafanaRuleConfigDebug(c *contextmodel.ReqContext, body apimodels.PostableExtendedRuleNodeExtended) response.Response {
+	trace := &expr.Trace{}
+	alerts, errResp := srv.testGrafanaRule(expr.WithTrace(c.Req.Context(), trace), c, body)
+	if errResp != nil {
+		return errResp
+	}
+	return response.JSON(http.StatusOK, apimodels.TestGrafanaRuleDebugResult{Alerts: alerts, Trace: trace.Frame()})
+}
+
+// testGrafanaRule evaluates the rule with the context ctx and returns the alerts that it would generate.
+func (srv TestingApiSrv) testGrafanaRule(ctx context.Context, c *contextmodel.ReqContext, body apimodels.PostableExtendedRuleNodeExtended) ([]*amv2.PostableAlert, response.Response) {
 	folder, err := srv.folderService.GetNamespaceByUID(c.Req.Context(), body.NamespaceUID, c.OrgID, c.SignedInUser)
 	if err != nil {
-		return toNamespaceErrorResponse(dashboards.ErrFolderAccessDenied)
+		return nil, toNamespaceErrorResponse(dashboards.ErrFolderAccessDenied)
 	}
 	rule, err := validateRuleNode(
 		&body.Rule,
@@ -70,28 +91,28 @@ func (srv TestingApiSrv) RouteTestGrafanaRuleConfig(c *contextmodel.ReqContext,
 		RuleLimitsFromConfig(srv.cfg, srv.featureManager),
 	)
 	if err != nil {
-		return ErrResp(http.StatusBadRequest, err, "")
+		return nil, ErrResp(http.StatusBadRequest, err, "")
 	}
 
 	if err := srv.authz.AuthorizeDatasourceAccessForRule(c.Req.Context(), c.SignedInUser, rule); err != nil {
-		return response.ErrOrFallback(http.StatusInternalServerError, "failed to authorize access to rule group", err)
+		return nil, response.ErrOrFallback(http.StatusInternalServerError, "failed to authorize access to rule group", err)
 	}
 
 	if srv.featureManager.IsEnabled(c.Req.Context(), featuremgmt.FlagAlertingQueryOptimization) {
 		if _, err := store.OptimizeAlertQueries(rule.Data); err != nil {
-			return ErrResp(http.StatusInternalServerError, err, "Failed to optimize query")
+			return nil, ErrResp(http.StatusInternalServerError, err, "Failed to optimize query")
 		}
 	}
 
 	evaluator, err := srv.evaluator.Create(eval.NewContext(c.Req.Context(), c.SignedInUser), rule.GetEvalCondition().WithSource("preview"))
 	if err != nil {
-		return ErrResp(http.StatusBadRequest, err, "Failed to build evaluator for queries and expressions")
+		return nil, ErrResp(http.StatusBadRequest, err, "Failed to build evaluator for queries and expressions")
 	}
 
 	now := time.Now()
-	results, err := evaluator.Evaluate(c.Req.Context(), now)
+	results, err := evaluator.Evaluate(ctx, now)
 	if err != nil {
-		return ErrResp(http.StatusInternalServerError, err, "Failed to evaluate queries")
+		return nil, ErrResp(http.StatusInternalServerError, err, "Failed to evaluate queries")
 	}
 
 	cfg := state.ManagerCfg{
@@ -119,8 +140,7 @@ func (srv TestingApiSrv) RouteTestGrafanaRuleConfig(c *contextmodel.ReqContext,
 	for _, alertState := range transitions {
 		alerts = append(alerts, state.StateToPostableAlert(alertState, srv.appUrl))
 	}
-
-	return response.JSON(http.StatusOK, alerts)
+	return alerts, nil
 }
 
 func (srv TestingApiSrv) RouteTestRuleConfig(c *contextmodel.ReqContext, body apimodels.TestRulePayload, datasourceUID string) response.Response {
@@ -195,13 +215,21 @@ func (srv TestingApiSrv) RouteEvalQueries(c *contextmodel.ReqContext, cmd apimod
 		now = timeNow()
 	}
 
-	evalResults, err := evaluator.EvaluateRaw(c.Req.Context(), now)
+	ctx := c.Req.Context()
+	var trace *expr.Trace
+	if cmd.Debug {
+		trace = &expr.Trace{}
+		ctx = expr.WithTrace(ctx, trace)
+	}
+
+	evalResults, err := evaluator.EvaluateRaw(ctx, now)
 
 	if err != nil {
 		return ErrResp(http.StatusInternalServerError, err, "Failed to evaluate queries and expressions")
 	}
 
 	addOptimizedQueryWarnings(evalResults, optimizations)
+	trace.AddToResponse(evalResults)
 	return response.JSONStreaming(http.StatusOK, evalResults)
 }
 
@@ -234,6 +262,13 @@ func (srv TestingApiSrv) BacktestAlertRule(c *contextmodel.ReqContext, cmd apimo
 	if err != nil {
 		return ErrResp(400, err, "")
 	}
+	var execErrState ngmodels.ExecutionErrorState
+	if cmd.ExecErrState != "" {
+		execErrState, err = ngmodels.ErrStateFromString(string(cmd.ExecErrState))
+		if err != nil {
+			return ErrResp(400, err, "")
+		}
+	}
 	forInterval := time.Duration(cmd.For)
 	if forInterval < 0 {
 		return ErrResp(400, nil, "Bad For interval")
@@ -249,11 +284,21 @@ func (srv TestingApiSrv) BacktestAlertRule(c *contextmodel.ReqContext, cmd apimo
 		return errorToResponse(err)
 	}
 
+	var folderTitle string
+	var namespaceUID string
+	if cmd.FolderUID != "" {
+		folder, err := srv.folderService.GetNamespaceByUID(c.Req.Context(), cmd.FolderUID, c.SignedInUser.GetOrgID(), c.SignedInUser)
+		if err != nil {
+			return toNamespaceErrorResponse(dashboards.ErrFolderAccessDenied)
+		}
+		folderTitle = folder.Fullpath
+		namespaceUID = folder.UID
+	}
+
 	rule := &ngmodels.AlertRule{
 		// ID:             0,
 		// Updated:        time.Time{},
 		// Version:        0,
-		// NamespaceUID:   "",
 		// DashboardUID:   nil,
 		// PanelID:        nil,
 		// RuleGroup:      "",
@@ -267,12 +312,14 @@ func (srv TestingApiSrv) BacktestAlertRule(c *contextmodel.ReqContext, cmd apimo
 		Data:            queries,
 		IntervalSeconds: intervalSeconds,
 		NoDataState:     noDataState,
+		ExecErrState:    execErrState,
 		For:             forInterval,
 		Annotations:     cmd.Annotations,
 		Labels:          cmd.Labels,
+		NamespaceUID:    namespaceUID,
 	}
 
-	result, err := srv.backtesting.Test(c.Req.Context(), c.SignedInUser, rule, cmd.From, cmd.To)
+	result, err := srv.backtesting.Test(c.Req.Context(), c.SignedInUser, rule, folderTitle, cmd.From, cmd.To)
 	if err != nil {
 		if errors.Is(err, backtesting.ErrInvalidInputData) {
 			return ErrResp(400, err, "Failed to evaluate")
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"testing"
@@ -12,6 +13,7 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
 	acMock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
@@ -240,6 +242,47 @@ func TestRouteTestGrafanaRuleConfig(t *testing.T) {
 
 			evaluator.AssertCalled(t, "Evaluate", mock.Anything, mock.Anything)
 		})
+
+		t.Run("should return the alerts with the trace of the evaluation in debug", func(t *testing.T) {
+			gen := models.RuleGen
+			data1 := gen.GenerateQuery()
+
+			ac := acMock.New().WithPermissions([]ac.Permission{
+				{Action: datasources.ActionQuery, Scope: datasources.ScopeProvider.GetResourceScopeUID(data1.DatasourceUID)},
+			})
+
+			ds := &fakes.FakeCacheService{DataSources: []*datasources.DataSource{
+				{UID: data1.DatasourceUID},
+			}}
+
+			var result []eval.Result
+			hasTrace := mock.MatchedBy(func(ctx context.Context) bool {
+				return expr.TraceFromContext(ctx) != nil
+			})
+			evaluator := &eval_mocks.ConditionEvaluatorMock{}
+			evaluator.EXPECT().Evaluate(hasTrace, mock.Anything).Return(result, nil)
+
+			f := randFolder()
+			ruleStore := fakes2.NewRuleStore(t)
+			ruleStore.Folders[rc.OrgID] = []*folder.Folder{f}
+
+			srv := createTestingApiSrv(t, ds, ac, eval_mocks.NewEvaluatorFactory(evaluator), featuremgmt.WithFeatures(), ruleStore)
+
+			rule := validRule()
+			rule.GrafanaManagedAlert.Data = ApiAlertQueriesFromAlertQueries([]models.AlertQuery{data1})
+			rule.GrafanaManagedAlert.Condition = data1.RefID
+			response := srv.RouteTestGrafanaRuleConfigDebug(rc, definitions.PostableExtendedRuleNodeExtended{
+				Rule:           rule,
+				NamespaceUID:   f.UID,
+				NamespaceTitle: f.Title,
+			})
+
+			require.Equal(t, http.StatusOK, response.Status())
+			var body definitions.TestGrafanaRuleDebugResult
+			require.NoError(t, json.Unmarshal(response.Body(), &body))
+			require.NotNil(t, body.Trace)
+			require.Empty(t, body.Alerts)
+		})
 	})
 }
 
@@ -316,6 +359,47 @@ func TestRouteEvalQueries(t *testing.T) {
 		})
 	})
 
+	t.Run("when debug is requested", func(t *testing.T) {
+		rc := &contextmodel.ReqContext{
+			Context: &web.Context{
+				Req: &http.Request{},
+			},
+			SignedInUser: &user.SignedInUser{
+				OrgID: 1,
+			},
+		}
+		t.Run("should return the trace of the evaluation", func(t *testing.T) {
+			data1 := models.GenerateAlertQuery()
+			ac := acMock.New().WithPermissions([]ac.Permission{
+				{Action: datasources.ActionQuery, Scope: datasources.ScopeProvider.GetResourceScopeUID(data1.DatasourceUID)},
+			})
+			ds := &fakes.FakeCacheService{DataSources: []*datasources.DataSource{
+				{UID: data1.DatasourceUID},
+			}}
+
+			evaluator := &eval_mocks.ConditionEvaluatorMock{}
+			result := &backend.QueryDataResponse{
+				Responses: map[string]backend.DataResponse{
+					data1.RefID: {},
+				},
+			}
+			hasTrace := mock.MatchedBy(func(ctx context.Context) bool {
+				return expr.TraceFromContext(ctx) != nil
+			})
+			evaluator.EXPECT().EvaluateRaw(hasTrace, mock.Anything).Return(result, nil)
+
+			srv := createTestingApiSrv(t, ds, ac, eval_mocks.NewEvaluatorFactory(evaluator), featuremgmt.WithFeatures(), fakes2.NewRuleStore(t))
+
+			response := srv.RouteEvalQueries(rc, definitions.EvalQueriesPayload{
+				Data:  ApiAlertQueriesFromAlertQueries([]models.AlertQuery{data1}),
+				Debug: true,
+			})
+
+			require.Equal(t, http.StatusOK, response.Status())
+			require.Contains(t, result.Responses, expr.TraceRefID)
+		})
+	})
+
 	t.Run("when query is optimizable", func(t *testing.T) {
 		rc := &contextmodel.ReqContext{
 			Context: &web.Context{
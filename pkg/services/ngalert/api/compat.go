@@ -28,11 +28,13 @@ func AlertRuleFromProvisionedAlertRule(a definitions.ProvisionedAlertRule) (mode
 		NoDataState:          models.NoDataState(a.NoDataState),          // TODO there must be a validation
 		ExecErrState:         models.ExecutionErrorState(a.ExecErrState), // TODO there must be a validation
 		For:                  time.Duration(a.For),
+		KeepFiringFor:        time.Duration(a.KeepFiringFor),
 		Annotations:          a.Annotations,
 		Labels:               a.Labels,
 		IsPaused:             a.IsPaused,
 		NotificationSettings: NotificationSettingsFromAlertRuleNotificationSettings(a.NotificationSettings),
 		Record:               ModelRecordFromApiRecord(a.Record),
+		DependsOn:            a.DependsOn,
 	}, nil
 }
 
@@ -46,6 +48,7 @@ func ProvisionedAlertRuleFromAlertRule(rule models.AlertRule, provenance models.
 		RuleGroup:            rule.RuleGroup,
 		Title:                rule.Title,
 		For:                  model.Duration(rule.For),
+		KeepFiringFor:        model.Duration(rule.KeepFiringFor),
 		Condition:            rule.Condition,
 		Data:                 ApiAlertQueriesFromAlertQueries(rule.Data),
 		Updated:              rule.Updated,
@@ -57,6 +60,7 @@ func ProvisionedAlertRuleFromAlertRule(rule models.AlertRule, provenance models.
 		IsPaused:             rule.IsPaused,
 		NotificationSettings: AlertRuleNotificationSettingsFromNotificationSettings(rule.NotificationSettings),
 		Record:               ApiRecordFromModelRecord(rule.Record),
+		DependsOn:            rule.DependsOn,
 	}
 }
 
@@ -199,6 +203,7 @@ func AlertRuleExportFromAlertRule(rule models.AlertRule) (definitions.AlertRuleE
 		UID:                  rule.UID,
 		Title:                rule.Title,
 		For:                  model.Duration(rule.For),
+		KeepFiringFor:        model.Duration(rule.KeepFiringFor),
 		Condition:            cPtr,
 		Data:                 data,
 		DashboardUID:         rule.DashboardUID,
@@ -212,12 +217,18 @@ func AlertRuleExportFromAlertRule(rule models.AlertRule) (definitions.AlertRuleE
 	if rule.For.Seconds() > 0 {
 		result.ForString = util.Pointer(model.Duration(rule.For).String())
 	}
+	if rule.KeepFiringFor.Seconds() > 0 {
+		result.KeepFiringForString = util.Pointer(model.Duration(rule.KeepFiringFor).String())
+	}
 	if rule.Annotations != nil {
 		result.Annotations = &rule.Annotations
 	}
 	if rule.Labels != nil {
 		result.Labels = &rule.Labels
 	}
+	if len(rule.DependsOn) > 0 {
+		result.DependsOn = &rule.DependsOn
+	}
 	return result, nil
 }
 
@@ -414,6 +425,101 @@ func MuteTimingIntervalToMuteTimeIntervalHclExport(m definitions.MuteTimeInterva
 	return result, err
 }
 
+// MaintenanceWindowFromApiMaintenanceWindow converts definitions.MaintenanceWindow to models.MaintenanceWindow
+func MaintenanceWindowFromApiMaintenanceWindow(w definitions.MaintenanceWindow) models.MaintenanceWindow {
+	return models.MaintenanceWindow{
+		UID:        w.UID,
+		Title:      w.Title,
+		Schedule:   w.Schedule,
+		Duration:   time.Duration(w.Duration),
+		TimeZone:   w.TimeZone,
+		Matchers:   w.Matchers,
+		Comment:    w.Comment,
+		Provenance: models.Provenance(w.Provenance),
+	}
+}
+
+// ApiMaintenanceWindowFromMaintenanceWindow converts models.MaintenanceWindow to definitions.MaintenanceWindow
+func ApiMaintenanceWindowFromMaintenanceWindow(w models.MaintenanceWindow) definitions.MaintenanceWindow {
+	return definitions.MaintenanceWindow{
+		UID:        w.UID,
+		Title:      w.Title,
+		Schedule:   w.Schedule,
+		Duration:   model.Duration(w.Duration),
+		TimeZone:   w.TimeZone,
+		Matchers:   w.Matchers,
+		Comment:    w.Comment,
+		Provenance: definitions.Provenance(w.Provenance),
+	}
+}
+
+// EscalationChainFromApiEscalationChain converts definitions.EscalationChain to models.EscalationChain
+func EscalationChainFromApiEscalationChain(c definitions.EscalationChain) models.EscalationChain {
+	steps := make([]models.EscalationStep, 0, len(c.Steps))
+	for _, s := range c.Steps {
+		steps = append(steps, models.EscalationStep{Receiver: s.Receiver, Delay: time.Duration(s.Delay)})
+	}
+	return models.EscalationChain{
+		UID:        c.UID,
+		Title:      c.Title,
+		Receiver:   c.Receiver,
+		Steps:      steps,
+		Provenance: models.Provenance(c.Provenance),
+	}
+}
+
+// ApiEscalationChainFromEscalationChain converts models.EscalationChain to definitions.EscalationChain
+func ApiEscalationChainFromEscalationChain(c models.EscalationChain) definitions.EscalationChain {
+	steps := make([]definitions.EscalationStep, 0, len(c.Steps))
+	for _, s := range c.Steps {
+		steps = append(steps, definitions.EscalationStep{Receiver: s.Receiver, Delay: model.Duration(s.Delay)})
+	}
+	return definitions.EscalationChain{
+		UID:        c.UID,
+		Title:      c.Title,
+		Receiver:   c.Receiver,
+		Steps:      steps,
+		Provenance: definitions.Provenance(c.Provenance),
+	}
+}
+
+func AlertGroupEscalationToApiAlertGroupEscalation(e *models.AlertGroupEscalation) definitions.AlertGroupEscalation {
+	result := definitions.AlertGroupEscalation{
+		GroupKey:       e.GroupKey,
+		Receiver:       e.Receiver,
+		GroupLabels:    e.GroupLabels,
+		ChainUID:       e.ChainUID,
+		Step:           e.Step,
+		StartedAt:      e.StartedAt,
+		EscalatedAt:    e.EscalatedAt,
+		Acknowledged:   e.Acknowledged,
+		AcknowledgedBy: e.AcknowledgedBy,
+		Comment:        e.Comment,
+	}
+	if e.Acknowledged {
+		at := e.AcknowledgedAt
+		result.AcknowledgedAt = &at
+	}
+	return result
+}
+
+func AlertInstanceAcknowledgementToApiAlertAcknowledgement(a models.AlertInstanceAcknowledgement) definitions.AlertAcknowledgement {
+	result := definitions.AlertAcknowledgement{
+		Acknowledged:   a.Acknowledged,
+		AcknowledgedBy: a.AcknowledgedBy,
+		ExpiresAt:      a.ExpiresAt,
+		Assignee:       a.Assignee,
+		Comment:        a.Comment,
+		Updated:        a.Updated,
+		UpdatedBy:      a.UpdatedBy,
+	}
+	if a.Acknowledged {
+		at := a.AcknowledgedAt
+		result.AcknowledgedAt = &at
+	}
+	return result
+}
+
 // AlertRuleNotificationSettingsFromNotificationSettings converts []models.NotificationSettings to definitions.AlertRuleNotificationSettings
 func AlertRuleNotificationSettingsFromNotificationSettings(ns []models.NotificationSettings) *definitions.AlertRuleNotificationSettings {
 	if len(ns) == 0 {
@@ -476,10 +582,14 @@ func AlertRuleRecordExportFromRecord(r *models.Record) *definitions.AlertRuleRec
 	if r == nil {
 		return nil
 	}
-	return &definitions.AlertRuleRecordExport{
+	result := &definitions.AlertRuleRecordExport{
 		Metric: r.Metric,
 		From:   r.From,
 	}
+	if r.TargetDatasourceUID != "" {
+		result.TargetDatasourceUID = &r.TargetDatasourceUID
+	}
+	return result
 }
 
 func ModelRecordFromApiRecord(r *definitions.Record) *models.Record {
@@ -487,8 +597,9 @@ func ModelRecordFromApiRecord(r *definitions.Record) *models.Record {
 		return nil
 	}
 	return &models.Record{
-		Metric: r.Metric,
-		From:   r.From,
+		Metric:              r.Metric,
+		From:                r.From,
+		TargetDatasourceUID: r.TargetDatasourceUID,
 	}
 }
 
@@ -497,7 +608,8 @@ func ApiRecordFromModelRecord(r *models.Record) *definitions.Record {
 		return nil
 	}
 	return &definitions.Record{
-		Metric: r.Metric,
-		From:   r.From,
+		Metric:              r.Metric,
+		From:                r.From,
+		TargetDatasourceUID: r.TargetDatasourceUID,
 	}
 }
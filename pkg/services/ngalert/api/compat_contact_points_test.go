@@ -3,6 +3,7 @@ package api
 import (
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	"strings"
 	"testing"
 
@@ -13,6 +14,8 @@ import (
 
 	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/integrations/matrix"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/integrations/zulip"
 	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
 )
 
@@ -89,6 +92,32 @@ func TestContactPointFromContactPointExports(t *testing.T) {
 		})
 	}
 
+	// Matrix and Zulip are implemented in Grafana, so they are not parsed by the alerting library.
+	for integrationType, cfg := range map[string]string{
+		matrix.Type: matrix.FullValidConfigForTesting,
+		zulip.Type:  zulip.FullValidConfigForTesting,
+	} {
+		t.Run(integrationType, func(t *testing.T) {
+			recCfg := &notify.APIReceiver{
+				ConfigReceiver: notify.ConfigReceiver{Name: "test-receiver"},
+				GrafanaIntegrations: notify.GrafanaIntegrations{
+					Integrations: []*notify.GrafanaIntegrationConfig{
+						{Type: integrationType, Settings: json.RawMessage(cfg)},
+					},
+				},
+			}
+
+			result, err := ContactPointFromContactPointExport(getContactPointExport(t, recCfg))
+			require.NoError(t, err)
+
+			back, err := ContactPointToContactPointExport(result)
+			require.NoError(t, err)
+			require.Len(t, back.Integrations, 1)
+			require.Equal(t, integrationType, back.Integrations[0].Type)
+			require.JSONEq(t, cfg, string(back.Integrations[0].Settings))
+		})
+	}
+
 	t.Run("pushover optional numbers as string", func(t *testing.T) {
 		export := definitions.ContactPointExport{
 			Name: "test",
@@ -190,6 +190,22 @@ func (f *AlertmanagerApiHandler) handleRoutePostTestGrafanaReceivers(ctx *contex
 	return f.GrafanaSvc.RoutePostTestReceivers(ctx, conf)
 }
 
+func (f *AlertmanagerApiHandler) handleRoutePostPreviewGrafanaTemplates(ctx *contextmodel.ReqContext, body apimodels.PreviewNotificationsBodyParams) response.Response {
+	return f.GrafanaSvc.RoutePostPreviewTemplates(ctx, body)
+}
+
+func (f *AlertmanagerApiHandler) handleRouteGetGrafanaAlertGroupEscalations(ctx *contextmodel.ReqContext) response.Response {
+	return f.GrafanaSvc.RouteGetAlertGroupEscalations(ctx)
+}
+
+func (f *AlertmanagerApiHandler) handleRoutePostGrafanaAlertGroupAcknowledgement(ctx *contextmodel.ReqContext, body apimodels.PostableAlertGroupAcknowledgement) response.Response {
+	return f.GrafanaSvc.RoutePostAlertGroupAcknowledgement(ctx, body)
+}
+
+func (f *AlertmanagerApiHandler) handleRouteDeleteGrafanaAlertGroupAcknowledgement(ctx *contextmodel.ReqContext, groupKey string) response.Response {
+	return f.GrafanaSvc.RouteDeleteAlertGroupAcknowledgement(ctx, groupKey)
+}
+
 func (f *AlertmanagerApiHandler) handleRoutePostTestGrafanaTemplates(ctx *contextmodel.ReqContext, conf apimodels.TestTemplatesConfigBodyParams) response.Response {
 	return f.GrafanaSvc.RoutePostTestTemplates(ctx, conf)
 }
@@ -46,6 +46,18 @@ func (f *PrometheusApiHandler) handleRouteGetGrafanaRuleStatuses(ctx *contextmod
 	return f.GrafanaSvc.RouteGetRuleStatuses(ctx)
 }
 
+func (f *PrometheusApiHandler) handleRoutePostGrafanaAlertAcknowledge(ctx *contextmodel.ReqContext, body apimodels.PostableAlertAcknowledgement) response.Response {
+	return f.GrafanaSvc.RoutePostAlertAcknowledge(ctx, body)
+}
+
+func (f *PrometheusApiHandler) handleRoutePostGrafanaAlertUnacknowledge(ctx *contextmodel.ReqContext, body apimodels.AlertInstanceReference) response.Response {
+	return f.GrafanaSvc.RoutePostAlertUnacknowledge(ctx, body)
+}
+
+func (f *PrometheusApiHandler) handleRoutePostGrafanaAlertAssign(ctx *contextmodel.ReqContext, body apimodels.PostableAlertAssignment) response.Response {
+	return f.GrafanaSvc.RoutePostAlertAssign(ctx, body)
+}
+
 func (f *PrometheusApiHandler) getService(ctx *contextmodel.ReqContext) (*LotexProm, error) {
 	_, err := getDatasourceByUID(ctx, f.DatasourceCache, apimodels.LoTexRulerBackend)
 	if err != nil {
@@ -97,6 +97,34 @@ func (f *RulerApiHandler) handleRouteGetRuleByUID(ctx *contextmodel.ReqContext,
 	return f.GrafanaRuler.RouteGetRuleByUID(ctx, ruleUID)
 }
 
+func (f *RulerApiHandler) handleRouteGetRuleBackfills(ctx *contextmodel.ReqContext, ruleUID string) response.Response {
+	return f.GrafanaRuler.RouteGetRuleBackfills(ctx, ruleUID)
+}
+
+func (f *RulerApiHandler) handleRoutePostRuleBackfill(ctx *contextmodel.ReqContext, conf apimodels.BackfillConfig, ruleUID string) response.Response {
+	return f.GrafanaRuler.RoutePostRuleBackfill(ctx, conf, ruleUID)
+}
+
+func (f *RulerApiHandler) handleRoutePostRuleBackfillResume(ctx *contextmodel.ReqContext, ruleUID string, backfillUID string) response.Response {
+	return f.GrafanaRuler.RoutePostRuleBackfillResume(ctx, ruleUID, backfillUID)
+}
+
+func (f *RulerApiHandler) handleRouteDeleteRuleBackfill(ctx *contextmodel.ReqContext, ruleUID string, backfillUID string) response.Response {
+	return f.GrafanaRuler.RouteDeleteRuleBackfill(ctx, ruleUID, backfillUID)
+}
+
+func (f *RulerApiHandler) handleRouteGetRuleVersionsByUID(ctx *contextmodel.ReqContext, ruleUID string) response.Response {
+	return f.GrafanaRuler.RouteGetRuleVersionsByUID(ctx, ruleUID)
+}
+
+func (f *RulerApiHandler) handleRouteGetRuleVersionsDiff(ctx *contextmodel.ReqContext, ruleUID string) response.Response {
+	return f.GrafanaRuler.RouteGetRuleVersionsDiff(ctx, ruleUID)
+}
+
+func (f *RulerApiHandler) handleRoutePostRuleVersionRestore(ctx *contextmodel.ReqContext, conf apimodels.RuleVersionRestore, ruleUID string, version string) response.Response {
+	return f.GrafanaRuler.RoutePostRuleVersionRestore(ctx, conf, ruleUID, version)
+}
+
 func (f *RulerApiHandler) handleRoutePostNameGrafanaRulesConfig(ctx *contextmodel.ReqContext, conf apimodels.PostableRuleGroupConfig, namespace string) response.Response {
 	payloadType := conf.Type()
 	if payloadType != apimodels.GrafanaBackend {
@@ -113,6 +141,10 @@ func (f *RulerApiHandler) handleRoutePostRulesGroupForExport(ctx *contextmodel.R
 	return f.GrafanaRuler.ExportFromPayload(ctx, conf, namespace)
 }
 
+func (f *RulerApiHandler) handleRoutePostPrometheusRulesImport(ctx *contextmodel.ReqContext, conf apimodels.PrometheusRulesImport, namespace string) response.Response {
+	return f.GrafanaRuler.RoutePostPrometheusRulesImport(ctx, conf, namespace)
+}
+
 func (f *RulerApiHandler) handleRouteGetRulesForExport(ctx *contextmodel.ReqContext) response.Response {
 	return f.GrafanaRuler.ExportRules(ctx)
 }
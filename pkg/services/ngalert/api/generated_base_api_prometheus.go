@@ -14,6 +14,7 @@ import (
 	"github.com/grafana/grafana/pkg/middleware"
 	"github.com/grafana/grafana/pkg/middleware/requestmeta"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	"github.com/grafana/grafana/pkg/web"
 )
@@ -23,6 +24,9 @@ type PrometheusApi interface {
 	RouteGetGrafanaAlertStatuses(*contextmodel.ReqContext) response.Response
 	RouteGetGrafanaRuleStatuses(*contextmodel.ReqContext) response.Response
 	RouteGetRuleStatuses(*contextmodel.ReqContext) response.Response
+	RoutePostGrafanaAlertAcknowledge(*contextmodel.ReqContext) response.Response
+	RoutePostGrafanaAlertAssign(*contextmodel.ReqContext) response.Response
+	RoutePostGrafanaAlertUnacknowledge(*contextmodel.ReqContext) response.Response
 }
 
 func (f *PrometheusApiHandler) RouteGetAlertStatuses(ctx *contextmodel.ReqContext) response.Response {
@@ -41,6 +45,30 @@ func (f *PrometheusApiHandler) RouteGetRuleStatuses(ctx *contextmodel.ReqContext
 	datasourceUIDParam := web.Params(ctx.Req)[":DatasourceUID"]
 	return f.handleRouteGetRuleStatuses(ctx, datasourceUIDParam)
 }
+func (f *PrometheusApiHandler) RoutePostGrafanaAlertAcknowledge(ctx *contextmodel.ReqContext) response.Response {
+	// Parse Request Body
+	conf := apimodels.PostableAlertAcknowledgement{}
+	if err := web.Bind(ctx.Req, &conf); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	return f.handleRoutePostGrafanaAlertAcknowledge(ctx, conf)
+}
+func (f *PrometheusApiHandler) RoutePostGrafanaAlertAssign(ctx *contextmodel.ReqContext) response.Response {
+	// Parse Request Body
+	conf := apimodels.PostableAlertAssignment{}
+	if err := web.Bind(ctx.Req, &conf); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	return f.handleRoutePostGrafanaAlertAssign(ctx, conf)
+}
+func (f *PrometheusApiHandler) RoutePostGrafanaAlertUnacknowledge(ctx *contextmodel.ReqContext) response.Response {
+	// Parse Request Body
+	conf := apimodels.AlertInstanceReference{}
+	if err := web.Bind(ctx.Req, &conf); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	return f.handleRoutePostGrafanaAlertUnacknowledge(ctx, conf)
+}
 
 func (api *API) RegisterPrometheusApiEndpoints(srv PrometheusApi, m *metrics.API) {
 	api.RouteRegister.Group("", func(group routing.RouteRegister) {
@@ -92,5 +120,41 @@ func (api *API) RegisterPrometheusApiEndpoints(srv PrometheusApi, m *metrics.API
 				m,
 			),
 		)
+		group.Post(
+			toMacaronPath("/api/prometheus/grafana/api/v1/alerts/acknowledge"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodPost, "/api/prometheus/grafana/api/v1/alerts/acknowledge"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/prometheus/grafana/api/v1/alerts/acknowledge",
+				api.Hooks.Wrap(srv.RoutePostGrafanaAlertAcknowledge),
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/prometheus/grafana/api/v1/alerts/assign"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodPost, "/api/prometheus/grafana/api/v1/alerts/assign"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/prometheus/grafana/api/v1/alerts/assign",
+				api.Hooks.Wrap(srv.RoutePostGrafanaAlertAssign),
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/prometheus/grafana/api/v1/alerts/unacknowledge"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodPost, "/api/prometheus/grafana/api/v1/alerts/unacknowledge"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/prometheus/grafana/api/v1/alerts/unacknowledge",
+				api.Hooks.Wrap(srv.RoutePostGrafanaAlertUnacknowledge),
+				m,
+			),
+		)
 	}, middleware.ReqSignedIn)
 }
@@ -64,6 +64,46 @@ func (f *ProvisioningApiHandler) handleRouteDeleteTemplate(ctx *contextmodel.Req
 	return f.svc.RouteDeleteTemplate(ctx, name)
 }
 
+func (f *ProvisioningApiHandler) handleRouteGetEscalationChains(ctx *contextmodel.ReqContext) response.Response {
+	return f.svc.RouteGetEscalationChains(ctx)
+}
+
+func (f *ProvisioningApiHandler) handleRouteGetEscalationChain(ctx *contextmodel.ReqContext, uid string) response.Response {
+	return f.svc.RouteGetEscalationChain(ctx, uid)
+}
+
+func (f *ProvisioningApiHandler) handleRoutePostEscalationChain(ctx *contextmodel.ReqContext, c apimodels.EscalationChain) response.Response {
+	return f.svc.RoutePostEscalationChain(ctx, c)
+}
+
+func (f *ProvisioningApiHandler) handleRoutePutEscalationChain(ctx *contextmodel.ReqContext, c apimodels.EscalationChain, uid string) response.Response {
+	return f.svc.RoutePutEscalationChain(ctx, c, uid)
+}
+
+func (f *ProvisioningApiHandler) handleRouteDeleteEscalationChain(ctx *contextmodel.ReqContext, uid string) response.Response {
+	return f.svc.RouteDeleteEscalationChain(ctx, uid)
+}
+
+func (f *ProvisioningApiHandler) handleRouteGetMaintenanceWindows(ctx *contextmodel.ReqContext) response.Response {
+	return f.svc.RouteGetMaintenanceWindows(ctx)
+}
+
+func (f *ProvisioningApiHandler) handleRouteGetMaintenanceWindow(ctx *contextmodel.ReqContext, uid string) response.Response {
+	return f.svc.RouteGetMaintenanceWindow(ctx, uid)
+}
+
+func (f *ProvisioningApiHandler) handleRoutePostMaintenanceWindow(ctx *contextmodel.ReqContext, w apimodels.MaintenanceWindow) response.Response {
+	return f.svc.RoutePostMaintenanceWindow(ctx, w)
+}
+
+func (f *ProvisioningApiHandler) handleRoutePutMaintenanceWindow(ctx *contextmodel.ReqContext, w apimodels.MaintenanceWindow, uid string) response.Response {
+	return f.svc.RoutePutMaintenanceWindow(ctx, w, uid)
+}
+
+func (f *ProvisioningApiHandler) handleRouteDeleteMaintenanceWindow(ctx *contextmodel.ReqContext, uid string) response.Response {
+	return f.svc.RouteDeleteMaintenanceWindow(ctx, uid)
+}
+
 func (f *ProvisioningApiHandler) handleRouteGetMuteTiming(ctx *contextmodel.ReqContext, name string) response.Response {
 	return f.svc.RouteGetMuteTiming(ctx, name)
 }
@@ -32,7 +32,7 @@ func NewFakeAlertInstanceManager(t *testing.T) *fakeAlertInstanceManager {
 	}
 }
 
-func (f *fakeAlertInstanceManager) GetAll(orgID int64) []*state.State {
+func (f *fakeAlertInstanceManager) GetAll(_ context.Context, orgID int64) []*state.State {
 	f.mtx.Lock()
 	defer f.mtx.Unlock()
 	var s []*state.State
@@ -46,7 +46,7 @@ func (f *fakeAlertInstanceManager) GetAll(orgID int64) []*state.State {
 	return s
 }
 
-func (f *fakeAlertInstanceManager) GetStatesForRuleUID(orgID int64, alertRuleUID string) []*state.State {
+func (f *fakeAlertInstanceManager) GetStatesForRuleUID(_ context.Context, orgID int64, alertRuleUID string) []*state.State {
 	f.mtx.Lock()
 	defer f.mtx.Unlock()
 	return f.states[orgID][alertRuleUID]
@@ -161,3 +161,7 @@ func (f fakeRuleAccessControlService) AuthorizeDatasourceAccessForRule(ctx conte
 func (f fakeRuleAccessControlService) AuthorizeDatasourceAccessForRuleGroup(ctx context.Context, user identity.Requester, rules models.RulesGroup) error {
 	return nil
 }
+
+func (f fakeRuleAccessControlService) AuthorizeRuleUpdate(ctx context.Context, user identity.Requester, rule *models.AlertRule) error {
+	return nil
+}
@@ -25,6 +25,10 @@ func (f *TestingApiHandler) handleRouteTestRuleGrafanaConfig(c *contextmodel.Req
 	return f.svc.RouteTestGrafanaRuleConfig(c, body)
 }
 
+func (f *TestingApiHandler) handleRouteTestRuleGrafanaConfigDebug(c *contextmodel.ReqContext, body apimodels.PostableExtendedRuleNodeExtended) response.Response {
+	return f.svc.RouteTestGrafanaRuleConfigDebug(c, body)
+}
+
 func (f *TestingApiHandler) handleRouteEvalQueries(c *contextmodel.ReqContext, body apimodels.EvalQueriesPayload) response.Response {
 	return f.svc.RouteEvalQueries(c, body)
 }
@@ -179,6 +179,44 @@ import (
 //       403: PermissionDenied
 //       409: AlertManagerNotReady
 
+// swagger:route POST /alertmanager/grafana/config/api/v1/templates/preview alertmanager RoutePostPreviewGrafanaTemplates
+//
+// Render the notifications that a contact point would send for the current alert instances, without sending them.
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//
+//       200: NotificationPreviewResults
+//       400: ValidationError
+//       403: PermissionDenied
+//       404: NotFound
+//       409: AlertManagerNotReady
+
+// swagger:route GET /alertmanager/grafana/api/v2/alerts/groups/escalations alertmanager RouteGetGrafanaAlertGroupEscalations
+//
+// Get the escalation state of the firing alert groups.
+//
+//     Responses:
+//       200: AlertGroupEscalations
+
+// swagger:route POST /alertmanager/grafana/api/v2/alerts/groups/acknowledgements alertmanager RoutePostGrafanaAlertGroupAcknowledgement
+//
+// Acknowledge an alert group. Acknowledged alert groups are not escalated further.
+//
+//     Responses:
+//       200: AlertGroupEscalation
+//       400: ValidationError
+//       404: NotFound
+
+// swagger:route DELETE /alertmanager/grafana/api/v2/alerts/groups/acknowledgements/{GroupKey} alertmanager RouteDeleteGrafanaAlertGroupAcknowledgement
+//
+// Remove the acknowledgement of an alert group. The alert group continues to be escalated.
+//
+//     Responses:
+//       200: AlertGroupEscalation
+//       404: NotFound
+
 // swagger:route GET /alertmanager/grafana/api/v2/silences alertmanager RouteGetGrafanaSilences
 //
 // get silences
@@ -367,6 +405,113 @@ type TestTemplatesErrorResult struct {
 	Message string `json:"message"`
 }
 
+// swagger:parameters RoutePostGrafanaAlertGroupAcknowledgement
+type AlertGroupAcknowledgementParams struct {
+	// in:body
+	Body PostableAlertGroupAcknowledgement
+}
+
+type PostableAlertGroupAcknowledgement struct {
+	// Name of the contact point the alert group is routed to.
+	// required: true
+	Receiver string `json:"receiver"`
+
+	// Labels the alerts of the group are grouped by.
+	Labels map[string]string `json:"labels"`
+
+	Comment string `json:"comment,omitempty"`
+}
+
+// swagger:parameters RouteDeleteGrafanaAlertGroupAcknowledgement
+type AlertGroupKeyParams struct {
+	// in:path
+	// required: true
+	GroupKey string
+}
+
+// swagger:model
+type AlertGroupEscalations []AlertGroupEscalation
+
+// AlertGroupEscalation is the position of a firing alert group in the escalation chain of its contact point.
+// swagger:model
+type AlertGroupEscalation struct {
+	GroupKey    string            `json:"groupKey"`
+	Receiver    string            `json:"receiver"`
+	GroupLabels map[string]string `json:"groupLabels"`
+	ChainUID    string            `json:"chainUid,omitempty"`
+
+	// Number of steps of the escalation chain whose contact points were notified.
+	Step        int       `json:"step"`
+	StartedAt   time.Time `json:"startedAt"`
+	EscalatedAt time.Time `json:"escalatedAt"`
+
+	Acknowledged   bool       `json:"acknowledged"`
+	AcknowledgedBy string     `json:"acknowledgedBy,omitempty"`
+	AcknowledgedAt *time.Time `json:"acknowledgedAt,omitempty"`
+	Comment        string     `json:"comment,omitempty"`
+}
+
+// swagger:parameters RoutePostPreviewGrafanaTemplates
+type PreviewNotificationsParams struct {
+	// in:body
+	Body PreviewNotificationsBodyParams
+}
+
+type PreviewNotificationsBodyParams struct {
+	// Name of the contact point to render the notifications of.
+	Receiver string `json:"receiver"`
+
+	// UID of the rule whose alert instances are used. Either RuleUID or Matchers must be set.
+	RuleUID string `json:"ruleUid,omitempty"`
+
+	// Matchers select the alert instances of all rules by their labels, for example `team="ops"`.
+	Matchers []string `json:"matchers,omitempty"`
+
+	// Template replaces the template file of the same name, or is added to the template files if there is none.
+	// It makes it possible to preview changes to a template before saving it.
+	Template *PreviewTemplate `json:"template,omitempty"`
+}
+
+type PreviewTemplate struct {
+	// Name of the template file.
+	Name string `json:"name"`
+
+	// Content of the template file.
+	Template string `json:"template"`
+}
+
+// swagger:model
+type NotificationPreviewResults struct {
+	Groups []NotificationPreviewGroup `json:"groups"`
+}
+
+// NotificationPreviewGroup is a group of alerts that would be sent to the contact point in a single notification.
+type NotificationPreviewGroup struct {
+	// Labels the alerts are grouped by in the notification policy that routes them to the contact point.
+	GroupLabels map[string]string `json:"groupLabels"`
+
+	// Routed is false if no notification policy routes the alerts to the contact point.
+	Routed bool `json:"routed"`
+
+	// Labels of the alerts of the group.
+	Alerts []map[string]string `json:"alerts"`
+
+	Integrations []NotificationPreviewIntegration `json:"integrations"`
+}
+
+// NotificationPreviewIntegration is the notification that an integration of the contact point would send.
+type NotificationPreviewIntegration struct {
+	UID  string `json:"uid"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+
+	// Rendered settings of the integration that are templates, such as the title and the message, by setting name.
+	Fields map[string]string `json:"fields"`
+
+	// Errors of the settings that failed to render, by setting name.
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
 // swagger:enum TemplateErrorKind
 type TemplateErrorKind string
 
@@ -85,6 +85,19 @@ type LineIntegration struct {
 	Description *string `json:"description,omitempty" yaml:"description,omitempty" hcl:"description"`
 }
 
+type MatrixIntegration struct {
+	DisableResolveMessage *bool `json:"-" yaml:"-" hcl:"disable_resolve_message"`
+
+	HomeserverURL string `json:"homeserverUrl" yaml:"homeserverUrl" hcl:"homeserver_url"`
+	AccessToken   Secret `json:"accessToken" yaml:"accessToken" hcl:"access_token"`
+	RoomID        string `json:"roomId" yaml:"roomId" hcl:"room_id"`
+
+	Title          *string `json:"title,omitempty" yaml:"title,omitempty" hcl:"title"`
+	Message        *string `json:"message,omitempty" yaml:"message,omitempty" hcl:"message"`
+	MessageFormat  *string `json:"messageFormat,omitempty" yaml:"messageFormat,omitempty" hcl:"message_format"`
+	DisableThreads *bool   `json:"disableThreads,omitempty" yaml:"disableThreads,omitempty" hcl:"disable_threads"`
+}
+
 type MqttIntegration struct {
 	DisableResolveMessage *bool `json:"-" yaml:"-" hcl:"disable_resolve_message"`
 
@@ -303,6 +316,19 @@ type WecomIntegration struct {
 	ToUser  *string `json:"touser,omitempty" yaml:"touser,omitempty" hcl:"to_user"`
 }
 
+type ZulipIntegration struct {
+	DisableResolveMessage *bool `json:"-" yaml:"-" hcl:"disable_resolve_message"`
+
+	URL      string `json:"url" yaml:"url" hcl:"url"`
+	BotEmail string `json:"botEmail" yaml:"botEmail" hcl:"bot_email"`
+	APIKey   Secret `json:"apiKey" yaml:"apiKey" hcl:"api_key"`
+	Stream   string `json:"stream" yaml:"stream" hcl:"stream"`
+
+	Topic   *string `json:"topic,omitempty" yaml:"topic,omitempty" hcl:"topic"`
+	Title   *string `json:"title,omitempty" yaml:"title,omitempty" hcl:"title"`
+	Message *string `json:"message,omitempty" yaml:"message,omitempty" hcl:"message"`
+}
+
 type ContactPoint struct {
 	Name         string                    `json:"name" yaml:"name" hcl:"name"`
 	Alertmanager []AlertmanagerIntegration `json:"alertmanager" yaml:"alertmanager" hcl:"alertmanager,block"`
@@ -312,6 +338,7 @@ type ContactPoint struct {
 	Googlechat   []GooglechatIntegration   `json:"googlechat" yaml:"googlechat" hcl:"googlechat,block"`
 	Kafka        []KafkaIntegration        `json:"kafka" yaml:"kafka" hcl:"kafka,block"`
 	Line         []LineIntegration         `json:"line" yaml:"line" hcl:"line,block"`
+	Matrix       []MatrixIntegration       `json:"matrix" yaml:"matrix" hcl:"matrix,block"`
 	Mqtt         []MqttIntegration         `json:"mqtt" yaml:"mqtt" hcl:"mqtt,block"`
 	Opsgenie     []OpsgenieIntegration     `json:"opsgenie" yaml:"opsgenie" hcl:"opsgenie,block"`
 	Pagerduty    []PagerdutyIntegration    `json:"pagerduty" yaml:"pagerduty" hcl:"pagerduty,block"`
@@ -327,4 +354,5 @@ type ContactPoint struct {
 	Webhook      []WebhookIntegration      `json:"webhook" yaml:"webhook" hcl:"webhook,block"`
 	Wecom        []WecomIntegration        `json:"wecom" yaml:"wecom" hcl:"wecom,block"`
 	Webex        []WebexIntegration        `json:"webex" yaml:"webex" hcl:"webex,block"`
+	Zulip        []ZulipIntegration        `json:"zulip" yaml:"zulip" hcl:"zulip,block"`
 }
@@ -0,0 +1,109 @@
+package definitions
+
+import "time"
+
+// swagger:route Post /ruler/grafana/api/v1/rule/{RuleUID}/backfill ruler RoutePostRuleBackfill
+//
+// Start a backfill of a recording rule. The rule is evaluated at its interval over the time range and the results
+// are written to the target data source of the rule with the timestamps of the evaluations.
+//
+//     Consumes:
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       202: GettableRecordingRuleBackfill
+//       400: ValidationError
+//       403: ForbiddenError
+//       404: description: Not found.
+//       409: description: Conflict.
+
+// swagger:route Get /ruler/grafana/api/v1/rule/{RuleUID}/backfill ruler RouteGetRuleBackfills
+//
+// List the backfills of a recording rule, most recent first.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: GettableRecordingRuleBackfills
+//       403: ForbiddenError
+//       404: description: Not found.
+
+// swagger:route Post /ruler/grafana/api/v1/rule/{RuleUID}/backfill/{BackfillUID}/resume ruler RoutePostRuleBackfillResume
+//
+// Resume a failed or cancelled backfill of a recording rule from the first evaluation that was not written.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       202: GettableRecordingRuleBackfill
+//       400: ValidationError
+//       403: ForbiddenError
+//       404: description: Not found.
+//       409: description: Conflict.
+
+// swagger:route Delete /ruler/grafana/api/v1/rule/{RuleUID}/backfill/{BackfillUID} ruler RouteDeleteRuleBackfill
+//
+// Cancel a running backfill of a recording rule. It can be resumed later.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: GettableRecordingRuleBackfill
+//       403: ForbiddenError
+//       404: description: Not found.
+
+// swagger:parameters RoutePostRuleBackfill
+type PostRuleBackfillParams struct {
+	// in: path
+	RuleUID string
+	// in: body
+	Body BackfillConfig
+}
+
+// swagger:parameters RouteGetRuleBackfills
+type GetRuleBackfillsParams struct {
+	// in: path
+	RuleUID string
+}
+
+// swagger:parameters RoutePostRuleBackfillResume RouteDeleteRuleBackfill
+type RuleBackfillParams struct {
+	// in: path
+	RuleUID string
+	// in: path
+	BackfillUID string
+}
+
+// swagger:model
+type BackfillConfig struct {
+	// The time of the first evaluation.
+	// required: true
+	From time.Time `json:"from"`
+	// The end of the time range. It must not be in the future.
+	// required: true
+	To time.Time `json:"to"`
+}
+
+// swagger:model
+type GettableRecordingRuleBackfill struct {
+	UID     string    `json:"uid"`
+	RuleUID string    `json:"ruleUid"`
+	From    time.Time `json:"from"`
+	To      time.Time `json:"to"`
+	// The time of the next evaluation. All evaluations before it have been written.
+	Next time.Time `json:"next"`
+	// The state of the backfill: running, completed, failed or cancelled.
+	State   string    `json:"state"`
+	Error   string    `json:"error,omitempty"`
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
+}
+
+// swagger:model
+type GettableRecordingRuleBackfills []GettableRecordingRuleBackfill
@@ -0,0 +1,68 @@
+package definitions
+
+// swagger:route POST /ruler/grafana/api/v1/rules/{Namespace}/import ruler RoutePostPrometheusRulesImport
+//
+// Import the rule groups of a Prometheus rule file as Grafana-managed alerting and recording rules. Each group of
+// the file replaces the group with the same name in the folder: rules are matched to the existing rules of the
+// folder by title, and rules of the group that are not in the file are deleted. With dryRun, the changes are
+// returned without being saved.
+//
+//     Consumes:
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: PrometheusRulesImportResponse
+//       202: PrometheusRulesImportResponse
+//       400: ValidationError
+//       403: ForbiddenError
+//       404: description: Not found.
+
+// swagger:parameters RoutePostPrometheusRulesImport
+type PostPrometheusRulesImportParams struct {
+	// The UID of the rule folder
+	// in: path
+	Namespace string
+	// in: body
+	Body PrometheusRulesImport
+}
+
+// swagger:model
+type PrometheusRulesImport struct {
+	// The content of the Prometheus rule file in YAML format.
+	// required: true
+	Rules string `json:"rules"`
+	// The UID of the Prometheus data source that the expressions of the rules query.
+	// required: true
+	DatasourceUID string `json:"datasourceUid"`
+	// The UID of the data source that the recording rules write to. If empty, the configured target is used.
+	RecordingRulesTargetDatasourceUID string `json:"recordingRulesTargetDatasourceUid,omitempty"`
+	// The state of the alerting rules when the query returns no data. Defaults to OK, like in Prometheus.
+	NoDataState NoDataState `json:"noDataState,omitempty"`
+	// The state of the alerting rules when the query fails. Defaults to Error.
+	ExecErrState ExecutionErrorState `json:"execErrState,omitempty"`
+	// If true, the changes are calculated and returned but not saved.
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// swagger:model
+type PrometheusRulesImportResponse struct {
+	DryRun bool                              `json:"dryRun"`
+	Groups []PrometheusRulesImportGroupDelta `json:"groups"`
+}
+
+// PrometheusRulesImportGroupDelta lists the changes to the rules of a group by their titles.
+type PrometheusRulesImportGroupDelta struct {
+	Name    string                      `json:"name"`
+	Created []string                    `json:"created"`
+	Updated []PrometheusRulesImportDiff `json:"updated"`
+	Deleted []string                    `json:"deleted"`
+}
+
+type PrometheusRulesImportDiff struct {
+	Title string `json:"title"`
+	// The paths of the fields of the rule that change.
+	Fields []string `json:"fields"`
+}
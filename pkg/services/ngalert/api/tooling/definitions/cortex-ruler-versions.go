@@ -0,0 +1,121 @@
+package definitions
+
+import "time"
+
+// swagger:route Get /ruler/grafana/api/v1/rule/{RuleUID}/versions ruler RouteGetRuleVersionsByUID
+//
+// List the versions of a rule, most recent first.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: GettableRuleVersions
+//       403: ForbiddenError
+//       404: description: Not found.
+
+// swagger:route Get /ruler/grafana/api/v1/rule/{RuleUID}/versions/diff ruler RouteGetRuleVersionsDiff
+//
+// Compare two versions of a rule.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: RuleVersionDiff
+//       400: ValidationError
+//       403: ForbiddenError
+//       404: description: Not found.
+
+// swagger:route Post /ruler/grafana/api/v1/rule/{RuleUID}/versions/{Version}/restore ruler RoutePostRuleVersionRestore
+//
+// Restore the definition that a rule had in a version. The rule keeps its folder, group and evaluation interval.
+// The change is validated like any other change of the rule group and creates a new version.
+//
+//     Consumes:
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       202: UpdateRuleGroupResponse
+//       400: ValidationError
+//       403: ForbiddenError
+//       404: description: Not found.
+
+// swagger:parameters RouteGetRuleVersionsByUID
+type GetRuleVersionsParams struct {
+	// in: path
+	RuleUID string
+}
+
+// swagger:parameters RouteGetRuleVersionsDiff
+type GetRuleVersionsDiffParams struct {
+	// in: path
+	RuleUID string
+	// The version to compare from.
+	// in: query
+	// required: true
+	From int64 `json:"from"`
+	// The version to compare to.
+	// in: query
+	// required: true
+	To int64 `json:"to"`
+}
+
+// swagger:parameters RoutePostRuleVersionRestore
+type PostRuleVersionRestoreParams struct {
+	// in: path
+	RuleUID string
+	// in: path
+	Version int64
+	// in: body
+	Body RuleVersionRestore
+}
+
+// swagger:parameters RoutePostNameGrafanaRulesConfig RoutePostPrometheusRulesImport
+type RuleChangeMessageHeader struct {
+	// Message that describes the change. It is stored with the versions of the changed rules.
+	// in: header
+	XChangeMessage string `json:"X-Change-Message"`
+}
+
+// swagger:model
+type RuleVersionRestore struct {
+	// Message that describes the change. Defaults to a message that tells which version was restored.
+	Message string `json:"message,omitempty"`
+}
+
+// swagger:model
+type GettableRuleVersion struct {
+	Version       int64     `json:"version"`
+	ParentVersion int64     `json:"parentVersion"`
+	Created       time.Time `json:"created"`
+	// Login of the user that created the version. It is empty if the version was created by Grafana.
+	UpdatedBy string                   `json:"updatedBy,omitempty"`
+	Message   string                   `json:"message,omitempty"`
+	Rule      GettableExtendedRuleNode `json:"rule"`
+}
+
+// swagger:model
+type GettableRuleVersions []GettableRuleVersion
+
+// swagger:model
+type RuleVersionDiff struct {
+	RuleUID string              `json:"ruleUid"`
+	From    int64               `json:"from"`
+	To      int64               `json:"to"`
+	Changes []RuleVersionChange `json:"changes"`
+}
+
+type RuleVersionChange struct {
+	// Path of the changed field, for example Data[1].Model or Labels[severity].
+	Path string `json:"path"`
+	// The kind of change: query, expression, labels, annotations, notification_settings or rule.
+	Kind string `json:"kind"`
+	// The value in the version to compare from. It is not set if the value was added.
+	Old any `json:"old,omitempty"`
+	// The value in the version to compare to. It is not set if the value was removed.
+	New any `json:"new,omitempty"`
+}
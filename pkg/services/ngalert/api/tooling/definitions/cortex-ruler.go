@@ -487,6 +487,9 @@ type Record struct {
 	// required: true
 	// example: A
 	From string `json:"from" yaml:"from"`
+	// UID of the data source the recorded metric is written to. If empty, the globally configured target is used.
+	// example: my-prometheus
+	TargetDatasourceUID string `json:"targetDatasourceUid,omitempty" yaml:"targetDatasourceUid,omitempty"`
 }
 
 // swagger:model
@@ -500,6 +503,8 @@ type PostableGrafanaRule struct {
 	IsPaused             *bool                          `json:"is_paused" yaml:"is_paused"`
 	NotificationSettings *AlertRuleNotificationSettings `json:"notification_settings" yaml:"notification_settings"`
 	Record               *Record                        `json:"record" yaml:"record"`
+	// UIDs of the rules of the same organization this rule depends on. Notifications of the rule are inhibited while any of them is firing.
+	DependsOn []string `json:"depends_on,omitempty" yaml:"depends_on,omitempty"`
 }
 
 // swagger:model
@@ -521,6 +526,9 @@ type GettableGrafanaRule struct {
 	IsPaused             bool                           `json:"is_paused" yaml:"is_paused"`
 	NotificationSettings *AlertRuleNotificationSettings `json:"notification_settings,omitempty" yaml:"notification_settings,omitempty"`
 	Record               *Record                        `json:"record,omitempty" yaml:"record,omitempty"`
+	DependsOn            []string                       `json:"depends_on,omitempty" yaml:"depends_on,omitempty"`
+	// Login of the user that last changed the rule.
+	UpdatedBy string `json:"updated_by,omitempty" yaml:"updated_by,omitempty"`
 }
 
 // AlertQuery represents a single query associated with an alert definition.
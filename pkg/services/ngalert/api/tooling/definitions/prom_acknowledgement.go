@@ -0,0 +1,94 @@
+package definitions
+
+import (
+	"time"
+)
+
+// swagger:route POST /prometheus/grafana/api/v1/alerts/acknowledge prometheus RoutePostGrafanaAlertAcknowledge
+//
+// Acknowledge an alert. From its next evaluation until it is resolved or the acknowledgement expires, the alert keeps
+// its labels and is silenced, and a copy of the alert with the label grafana_acknowledged="true" is sent instead.
+// Notification policies can match the label to route acknowledged alerts, for example to stop repeated notifications.
+//
+//     Responses:
+//       200: AlertAcknowledgement
+//       400: ValidationError
+//       404: NotFound
+
+// swagger:route POST /prometheus/grafana/api/v1/alerts/unacknowledge prometheus RoutePostGrafanaAlertUnacknowledge
+//
+// Remove the acknowledgement of an alert.
+//
+//     Responses:
+//       200: AlertAcknowledgement
+//       400: ValidationError
+//       404: NotFound
+
+// swagger:route POST /prometheus/grafana/api/v1/alerts/assign prometheus RoutePostGrafanaAlertAssign
+//
+// Assign an alert to a user, or remove its assignment if the assignee is empty.
+//
+//     Responses:
+//       200: AlertAcknowledgement
+//       400: ValidationError
+//       404: NotFound
+
+// swagger:parameters RoutePostGrafanaAlertAcknowledge
+type AlertAcknowledgeParams struct {
+	// in:body
+	Body PostableAlertAcknowledgement
+}
+
+// swagger:parameters RoutePostGrafanaAlertUnacknowledge
+type AlertUnacknowledgeParams struct {
+	// in:body
+	Body AlertInstanceReference
+}
+
+// swagger:parameters RoutePostGrafanaAlertAssign
+type AlertAssignParams struct {
+	// in:body
+	Body PostableAlertAssignment
+}
+
+// AlertInstanceReference identifies an alert by its rule and its labels.
+type AlertInstanceReference struct {
+	// required: true
+	RuleUID string `json:"ruleUid"`
+	// Labels of the alert as returned by the alerts API, without internal labels.
+	// required: true
+	Labels map[string]string `json:"labels"`
+}
+
+type PostableAlertAcknowledgement struct {
+	// required: true
+	RuleUID string `json:"ruleUid"`
+	// required: true
+	Labels  map[string]string `json:"labels"`
+	Comment string            `json:"comment,omitempty"`
+	// Time after which the alert is no longer acknowledged. If not set, it is acknowledged until it is resolved.
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+type PostableAlertAssignment struct {
+	// required: true
+	RuleUID string `json:"ruleUid"`
+	// required: true
+	Labels map[string]string `json:"labels"`
+	// Login of the user the alert is assigned to.
+	Assignee string `json:"assignee"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+// AlertAcknowledgement tells who handles an alert.
+// swagger:model
+type AlertAcknowledgement struct {
+	Acknowledged   bool       `json:"acknowledged"`
+	AcknowledgedBy string     `json:"acknowledgedBy,omitempty"`
+	AcknowledgedAt *time.Time `json:"acknowledgedAt,omitempty"`
+	ExpiresAt      *time.Time `json:"expiresAt,omitempty"`
+	Assignee       string     `json:"assignee,omitempty"`
+	Comment        string     `json:"comment,omitempty"`
+	Updated        time.Time  `json:"updated"`
+	UpdatedBy      string     `json:"updatedBy,omitempty"`
+}
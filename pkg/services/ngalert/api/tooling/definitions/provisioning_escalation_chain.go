@@ -0,0 +1,98 @@
+package definitions
+
+import (
+	"github.com/prometheus/common/model"
+)
+
+// swagger:route GET /v1/provisioning/escalation-chains provisioning stable RouteGetEscalationChains
+//
+// Get all the escalation chains.
+//
+//     Responses:
+//       200: EscalationChains
+
+// swagger:route GET /v1/provisioning/escalation-chains/{UID} provisioning stable RouteGetEscalationChain
+//
+// Get an escalation chain.
+//
+//     Responses:
+//       200: EscalationChain
+//       404: description: Not found.
+
+// swagger:route POST /v1/provisioning/escalation-chains provisioning stable RoutePostEscalationChain
+//
+// Create a new escalation chain.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       201: EscalationChain
+//       400: ValidationError
+
+// swagger:route PUT /v1/provisioning/escalation-chains/{UID} provisioning stable RoutePutEscalationChain
+//
+// Replace an existing escalation chain.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       202: EscalationChain
+//       400: ValidationError
+//       404: description: Not found.
+
+// swagger:route DELETE /v1/provisioning/escalation-chains/{UID} provisioning stable RouteDeleteEscalationChain
+//
+// Delete an escalation chain.
+//
+//     Responses:
+//       204: description: The escalation chain was deleted successfully.
+
+// swagger:parameters RouteGetEscalationChain RoutePutEscalationChain RouteDeleteEscalationChain
+type EscalationChainUIDReference struct {
+	// Escalation chain UID
+	// in:path
+	UID string
+}
+
+// swagger:parameters RoutePostEscalationChain RoutePutEscalationChain
+type EscalationChainPayload struct {
+	// in:body
+	Body EscalationChain
+}
+
+// swagger:parameters RoutePostEscalationChain RoutePutEscalationChain RouteDeleteEscalationChain
+type EscalationChainHeaders struct {
+	// in:header
+	XDisableProvenance string `json:"X-Disable-Provenance"`
+}
+
+// swagger:model
+type EscalationChains []EscalationChain
+
+// EscalationChain escalates the alert groups that notification policies route to its contact point. If such a group
+// is still firing and has not been acknowledged after the delay of a step, the contact point of the step is notified.
+// swagger:model
+type EscalationChain struct {
+	UID string `json:"uid,omitempty"`
+	// required: true
+	// example: Database on-call
+	Title string `json:"title"`
+	// Contact point of the notification policies whose alert groups are escalated.
+	// required: true
+	// example: database-team
+	Receiver string `json:"receiver"`
+	// required: true
+	Steps      []EscalationStep `json:"steps"`
+	Provenance Provenance       `json:"provenance,omitempty"`
+}
+
+// EscalationStep notifies a contact point once the delay has passed since the previous notification of the alert group.
+type EscalationStep struct {
+	// required: true
+	// example: database-on-call
+	Receiver string `json:"receiver"`
+	// required: true
+	Delay model.Duration `json:"delay"`
+}
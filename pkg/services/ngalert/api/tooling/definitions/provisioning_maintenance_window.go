@@ -0,0 +1,97 @@
+package definitions
+
+import (
+	"github.com/prometheus/common/model"
+)
+
+// swagger:route GET /v1/provisioning/maintenance-windows provisioning stable RouteGetMaintenanceWindows
+//
+// Get all the maintenance windows.
+//
+//     Responses:
+//       200: MaintenanceWindows
+
+// swagger:route GET /v1/provisioning/maintenance-windows/{UID} provisioning stable RouteGetMaintenanceWindow
+//
+// Get a maintenance window.
+//
+//     Responses:
+//       200: MaintenanceWindow
+//       404: description: Not found.
+
+// swagger:route POST /v1/provisioning/maintenance-windows provisioning stable RoutePostMaintenanceWindow
+//
+// Create a new maintenance window.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       201: MaintenanceWindow
+//       400: ValidationError
+
+// swagger:route PUT /v1/provisioning/maintenance-windows/{UID} provisioning stable RoutePutMaintenanceWindow
+//
+// Replace an existing maintenance window.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       202: MaintenanceWindow
+//       400: ValidationError
+//       404: description: Not found.
+
+// swagger:route DELETE /v1/provisioning/maintenance-windows/{UID} provisioning stable RouteDeleteMaintenanceWindow
+//
+// Delete a maintenance window.
+//
+//     Responses:
+//       204: description: The maintenance window was deleted successfully.
+
+// swagger:parameters RouteGetMaintenanceWindow RoutePutMaintenanceWindow RouteDeleteMaintenanceWindow
+type MaintenanceWindowUIDReference struct {
+	// Maintenance window UID
+	// in:path
+	UID string
+}
+
+// swagger:parameters RoutePostMaintenanceWindow RoutePutMaintenanceWindow
+type MaintenanceWindowPayload struct {
+	// in:body
+	Body MaintenanceWindow
+}
+
+// swagger:parameters RoutePostMaintenanceWindow RoutePutMaintenanceWindow RouteDeleteMaintenanceWindow
+type MaintenanceWindowHeaders struct {
+	// in:header
+	XDisableProvenance string `json:"X-Disable-Provenance"`
+}
+
+// swagger:model
+type MaintenanceWindows []MaintenanceWindow
+
+// MaintenanceWindow silences the alerts that match its matchers during each occurrence of a recurring schedule.
+// swagger:model
+type MaintenanceWindow struct {
+	UID string `json:"uid,omitempty"`
+	// required: true
+	// example: Database upgrades
+	Title string `json:"title"`
+	// Cron expression with five fields, a descriptor such as @daily, or an iCalendar recurrence rule such as RRULE:FREQ=MONTHLY;BYDAY=1SA;BYHOUR=2, that defines the start of the occurrences in the time zone of the window.
+	// required: true
+	// example: 0 2 * * 6
+	Schedule string `json:"schedule"`
+	// Duration of each occurrence.
+	// required: true
+	Duration model.Duration `json:"duration"`
+	// IANA name of the time zone of the schedule. Defaults to UTC.
+	// example: Europe/Berlin
+	TimeZone string `json:"timeZone,omitempty"`
+	// Matchers of the alerts that are silenced, in the format of silence matchers.
+	// required: true
+	// example: ["service=\"db\""]
+	Matchers   []string   `json:"matchers"`
+	Comment    string     `json:"comment,omitempty"`
+	Provenance Provenance `json:"provenance,omitempty"`
+}
@@ -28,6 +28,21 @@ import (
 //       400: ValidationError
 //       404: NotFound
 
+// swagger:route Post /v1/rule/test/grafana/debug testing RouteTestRuleGrafanaConfigDebug
+//
+// Test a rule against Grafana ruler and trace the execution of its queries and expressions
+//
+//     Consumes:
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: TestGrafanaRuleDebugResult
+//       400: ValidationError
+//       404: NotFound
+
 // swagger:route Post /v1/rule/test/{DatasourceUID} testing RouteTestRuleConfig
 //
 // Test a rule against external data source ruler
@@ -94,7 +109,7 @@ type TestGrafanaRuleResponse struct {
 	Body []amv2.PostableAlert
 }
 
-// swagger:parameters RouteTestRuleGrafanaConfig
+// swagger:parameters RouteTestRuleGrafanaConfig RouteTestRuleGrafanaConfigDebug
 type TestGrafanaRuleRequest struct {
 	// in:body
 	Body PostableExtendedRuleNodeExtended
@@ -112,6 +127,13 @@ type PostableExtendedRuleNodeExtended struct {
 	RuleGroup string `json:"ruleGroup"`
 }
 
+// swagger:model
+type TestGrafanaRuleDebugResult struct {
+	Alerts []*amv2.PostableAlert `json:"alerts"`
+	// Trace has a row per query and expression, the full trace is in the custom metadata of the frame.
+	Trace *data.Frame `json:"trace"`
+}
+
 func (n *PostableExtendedRuleNodeExtended) UnmarshalJSON(b []byte) error {
 	type plain PostableExtendedRuleNodeExtended
 	if err := json.Unmarshal(b, (*plain)(n)); err != nil {
@@ -131,6 +153,8 @@ type EvalQueriesPayload struct {
 	Condition string       `json:"condition"`
 	Data      []AlertQuery `json:"data"`
 	Now       time.Time    `json:"now"`
+	// Debug adds a trace of the execution of the queries and expressions to the response.
+	Debug bool `json:"debug,omitempty"`
 }
 
 func (p *TestRulePayload) UnmarshalJSON(b []byte) error {
@@ -229,7 +253,11 @@ type BacktestConfig struct {
 	Labels      map[string]string `json:"labels,omitempty"`
 	Annotations map[string]string `json:"annotations,omitempty"`
 
-	NoDataState NoDataState `json:"no_data_state"`
+	NoDataState  NoDataState         `json:"no_data_state"`
+	ExecErrState ExecutionErrorState `json:"exec_err_state,omitempty"`
+
+	// The UID of the folder of the rule. The full path of the folder is the value of the grafana_folder label of the alerts.
+	FolderUID string `json:"folder_uid,omitempty"`
 }
 
 // swagger:model
@@ -0,0 +1,396 @@
+package backtesting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"golang.org/x/time/rate"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/schedule"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+var (
+	// ErrBackfillNotResumable is returned when a backfill is running or has already completed.
+	ErrBackfillNotResumable = errors.New("backfill cannot be resumed")
+	// ErrBackfillerNotRunning is returned when a backfill is started before the backfiller runs.
+	ErrBackfillerNotRunning = errors.New("backfiller is not running")
+
+	errBackfillCancelled = errors.New("backfill cancelled")
+)
+
+const (
+	// backfillHeartbeatInterval is how often a Grafana instance reports that it still runs its backfills, and looks
+	// for running backfills that no other instance runs.
+	backfillHeartbeatInterval = 30 * time.Second
+	// backfillHeartbeatTimeout is how old the heartbeat of a running backfill must be before another instance takes
+	// it over.
+	backfillHeartbeatTimeout = 3 * backfillHeartbeatInterval
+)
+
+// BackfillStore persists recording rule backfills.
+type BackfillStore interface {
+	InsertRecordingRuleBackfill(ctx context.Context, backfill *models.RecordingRuleBackfill) error
+	UpdateRecordingRuleBackfill(ctx context.Context, backfill *models.RecordingRuleBackfill, owner string) error
+	ResumeRecordingRuleBackfill(ctx context.Context, backfill *models.RecordingRuleBackfill) error
+	ClaimRecordingRuleBackfill(ctx context.Context, backfill *models.RecordingRuleBackfill, staleBefore time.Time) (bool, error)
+	HeartbeatRecordingRuleBackfills(ctx context.Context, owner string, at time.Time) error
+	GetRecordingRuleBackfill(ctx context.Context, orgID int64, uid string) (*models.RecordingRuleBackfill, error)
+	ListRecordingRuleBackfills(ctx context.Context, orgID int64, ruleUID string) ([]*models.RecordingRuleBackfill, error)
+	ListRecordingRuleBackfillsByState(ctx context.Context, state models.BackfillState) ([]*models.RecordingRuleBackfill, error)
+}
+
+// BackfillRuleStore gets the rules of backfills that are taken over.
+type BackfillRuleStore interface {
+	GetAlertRuleByUID(ctx context.Context, query *models.GetAlertRuleByUIDQuery) (*models.AlertRule, error)
+}
+
+// Backfiller evaluates recording rules over past time ranges and writes the results with the timestamps of the
+// evaluations. All backfills share a single rate limit so that they do not overload the data sources. The progress
+// of every backfill is saved after each evaluation.
+//
+// Every running backfill is owned by a single Grafana instance, which regularly updates its heartbeat. Backfills whose
+// owner stopped, including the backfills that were running when Grafana stopped, are taken over by the first
+// instance that finds their heartbeat older than backfillHeartbeatTimeout.
+type Backfiller struct {
+	evalFactory eval.EvaluatorFactory
+	writer      schedule.RecordingWriter
+	store       BackfillStore
+	rules       BackfillRuleStore
+	limiter     *rate.Limiter
+	maxRange    time.Duration
+	clock       clock.Clock
+	log         log.Logger
+	// owner identifies this instance as the owner of the backfills it runs.
+	owner             string
+	heartbeatInterval time.Duration
+	heartbeatTimeout  time.Duration
+
+	mtx     sync.Mutex
+	ctx     context.Context
+	running map[string]context.CancelCauseFunc
+	wg      sync.WaitGroup
+}
+
+func NewBackfiller(cfg setting.RecordingRuleSettings, evalFactory eval.EvaluatorFactory, writer schedule.RecordingWriter, store BackfillStore, rules BackfillRuleStore, clk clock.Clock) *Backfiller {
+	return &Backfiller{
+		evalFactory:       evalFactory,
+		writer:            writer,
+		store:             store,
+		rules:             rules,
+		limiter:           rate.NewLimiter(rate.Limit(cfg.BackfillRate), 1),
+		maxRange:          cfg.BackfillMaxRange,
+		clock:             clk,
+		log:               log.New("ngalert.backtesting.backfill"),
+		owner:             util.GenerateShortUID(),
+		heartbeatInterval: backfillHeartbeatInterval,
+		heartbeatTimeout:  backfillHeartbeatTimeout,
+		running:           make(map[string]context.CancelCauseFunc),
+	}
+}
+
+// Run takes over the running backfills that no other Grafana instance runs and blocks until the context is cancelled.
+// While it runs, it keeps the heartbeat of its backfills up to date and takes over the backfills of instances that
+// stopped. Backfills that are interrupted by the shutdown stay in the running state, and are taken over when their
+// heartbeat times out.
+func (b *Backfiller) Run(ctx context.Context) error {
+	b.mtx.Lock()
+	b.ctx = ctx
+	b.mtx.Unlock()
+
+	b.claim(ctx)
+	ticker := b.clock.Ticker(b.heartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			b.wg.Wait()
+			return nil
+		case <-ticker.C:
+			if err := b.store.HeartbeatRecordingRuleBackfills(ctx, b.owner, b.clock.Now()); err != nil {
+				b.log.Error("Failed to update the heartbeat of backfills", "error", err)
+			}
+			b.claim(ctx)
+		}
+	}
+}
+
+// claim runs the running backfills that have no owner, or whose owner stopped updating their heartbeat.
+func (b *Backfiller) claim(ctx context.Context) {
+	backfills, err := b.store.ListRecordingRuleBackfillsByState(ctx, models.BackfillStateRunning)
+	if err != nil {
+		b.log.Error("Failed to get running backfills", "error", err)
+		return
+	}
+	for _, backfill := range backfills {
+		b.mtx.Lock()
+		_, ok := b.running[backfill.UID]
+		b.mtx.Unlock()
+		if ok {
+			continue
+		}
+
+		now := b.clock.Now()
+		backfill.Owner = b.owner
+		backfill.Heartbeat = now
+		claimed, err := b.store.ClaimRecordingRuleBackfill(ctx, backfill, now.Add(-b.heartbeatTimeout))
+		if err != nil {
+			b.log.Error("Failed to claim the backfill", "error", err, "backfill", backfill.UID)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		rule, err := b.rules.GetAlertRuleByUID(ctx, &models.GetAlertRuleByUIDQuery{OrgID: backfill.OrgID, UID: backfill.RuleUID})
+		if err != nil {
+			b.log.Error("Failed to get the rule of the backfill", "error", err, "backfill", backfill.UID, "rule_uid", backfill.RuleUID)
+			b.finish(ctx, backfill, fmt.Errorf("failed to get the rule: %w", err))
+			continue
+		}
+		b.log.Info("Taking over the backfill", "backfill", backfill.UID, "rule_uid", backfill.RuleUID)
+		b.mtx.Lock()
+		b.launch(rule, backfill)
+		b.mtx.Unlock()
+	}
+}
+
+// Start validates the time range and starts a backfill of the recording rule.
+func (b *Backfiller) Start(ctx context.Context, rule *models.AlertRule, from, to time.Time) (*models.RecordingRuleBackfill, error) {
+	if rule.Type() != models.RuleTypeRecording {
+		return nil, fmt.Errorf("%w: only recording rules can be backfilled", ErrInvalidInputData)
+	}
+	if !from.Before(to) {
+		return nil, fmt.Errorf("%w: invalid time range of the backfill [%d,%d]", ErrInvalidInputData, from.Unix(), to.Unix())
+	}
+	if to.After(b.clock.Now()) {
+		return nil, fmt.Errorf("%w: the time range of the backfill must not end in the future", ErrInvalidInputData)
+	}
+	if to.Sub(from).Seconds() < float64(rule.IntervalSeconds) {
+		return nil, fmt.Errorf("%w: time range of the backfill [%d,%d] is less than evaluation interval [%ds]", ErrInvalidInputData, from.Unix(), to.Unix(), rule.IntervalSeconds)
+	}
+	if b.maxRange > 0 && to.Sub(from) > b.maxRange {
+		return nil, fmt.Errorf("%w: time range of the backfill is longer than the maximum of %s", ErrInvalidInputData, b.maxRange)
+	}
+
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	if b.ctx == nil {
+		return nil, ErrBackfillerNotRunning
+	}
+
+	now := b.clock.Now()
+	backfill := &models.RecordingRuleBackfill{
+		UID:       util.GenerateShortUID(),
+		OrgID:     rule.OrgID,
+		RuleUID:   rule.UID,
+		From:      from,
+		To:        to,
+		Next:      from,
+		State:     models.BackfillStateRunning,
+		Created:   now,
+		Updated:   now,
+		Owner:     b.owner,
+		Heartbeat: now,
+	}
+	if err := b.store.InsertRecordingRuleBackfill(ctx, backfill); err != nil {
+		return nil, err
+	}
+	b.launch(rule, backfill)
+	return backfill, nil
+}
+
+// Resume continues a failed or cancelled backfill from the first evaluation that was not written.
+func (b *Backfiller) Resume(ctx context.Context, rule *models.AlertRule, uid string) (*models.RecordingRuleBackfill, error) {
+	backfill, err := b.store.GetRecordingRuleBackfill(ctx, rule.OrgID, uid)
+	if err != nil {
+		return nil, err
+	}
+	if backfill.RuleUID != rule.UID {
+		return nil, models.ErrBackfillNotFound
+	}
+	if !backfill.Resumable() {
+		return nil, fmt.Errorf("%w: backfill is %s", ErrBackfillNotResumable, backfill.State)
+	}
+
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	if b.ctx == nil {
+		return nil, ErrBackfillerNotRunning
+	}
+
+	now := b.clock.Now()
+	backfill.State = models.BackfillStateRunning
+	backfill.Error = ""
+	backfill.Updated = now
+	backfill.Owner = b.owner
+	backfill.Heartbeat = now
+	if err := b.store.ResumeRecordingRuleBackfill(ctx, backfill); err != nil {
+		if errors.Is(err, models.ErrBackfillStateChanged) {
+			return nil, fmt.Errorf("%w: %w", ErrBackfillNotResumable, err)
+		}
+		return nil, err
+	}
+	b.launch(rule, backfill)
+	return backfill, nil
+}
+
+// Cancel stops a running backfill. It can be resumed later.
+func (b *Backfiller) Cancel(ctx context.Context, rule *models.AlertRule, uid string) (*models.RecordingRuleBackfill, error) {
+	backfill, err := b.store.GetRecordingRuleBackfill(ctx, rule.OrgID, uid)
+	if err != nil {
+		return nil, err
+	}
+	if backfill.RuleUID != rule.UID {
+		return nil, models.ErrBackfillNotFound
+	}
+	if backfill.State != models.BackfillStateRunning {
+		return backfill, nil
+	}
+
+	b.mtx.Lock()
+	cancel, ok := b.running[backfill.UID]
+	b.mtx.Unlock()
+	if ok {
+		// The goroutine of the backfill saves the state.
+		cancel(errBackfillCancelled)
+		backfill.State = models.BackfillStateCancelled
+		return backfill, nil
+	}
+
+	// The backfill runs on another Grafana instance, or it is waiting to be taken over. The owner stops when it fails
+	// to save the progress of the backfill.
+	backfill.State = models.BackfillStateCancelled
+	backfill.Updated = b.clock.Now()
+	if err := b.store.UpdateRecordingRuleBackfill(ctx, backfill, ""); err != nil {
+		if errors.Is(err, models.ErrBackfillStateChanged) {
+			// The backfill has completed or failed in the meantime.
+			return b.store.GetRecordingRuleBackfill(ctx, rule.OrgID, uid)
+		}
+		return nil, err
+	}
+	return backfill, nil
+}
+
+// List returns the backfills of the recording rule, most recent first.
+func (b *Backfiller) List(ctx context.Context, rule *models.AlertRule) ([]*models.RecordingRuleBackfill, error) {
+	return b.store.ListRecordingRuleBackfills(ctx, rule.OrgID, rule.UID)
+}
+
+// launch runs the backfill in a new goroutine. It must be called with the mutex held.
+// The goroutine updates its own copy of the backfill, so the caller can keep using it.
+func (b *Backfiller) launch(rule *models.AlertRule, backfill *models.RecordingRuleBackfill) {
+	ctx, cancel := context.WithCancelCause(b.ctx)
+	b.running[backfill.UID] = cancel
+	b.wg.Add(1)
+	job := *backfill
+	go func() {
+		defer b.wg.Done()
+		defer cancel(nil)
+		// The backfill is removed only after its final state is saved, so that it is not claimed again in between.
+		defer func() {
+			b.mtx.Lock()
+			delete(b.running, job.UID)
+			b.mtx.Unlock()
+		}()
+		backfill := &job
+		err := b.run(ctx, rule, backfill)
+		if errors.Is(err, models.ErrBackfillStateChanged) {
+			// The backfill was cancelled or taken over by another Grafana instance, which saved its state.
+			b.log.Info("Backfill stopped because its state has changed", "backfill", backfill.UID)
+			return
+		}
+		if err != nil && ctx.Err() != nil {
+			err = context.Cause(ctx)
+			if !errors.Is(err, errBackfillCancelled) {
+				// Grafana is shutting down. Leave the backfill running so that it is taken over later.
+				return
+			}
+		}
+		// The context of the backfill can be cancelled, but the final state must still be saved.
+		b.finish(context.WithoutCancel(ctx), backfill, err)
+	}()
+}
+
+func (b *Backfiller) run(ctx context.Context, rule *models.AlertRule, backfill *models.RecordingRuleBackfill) error {
+	logger := b.log.FromContext(ctx).New(rule.GetKey().LogContext()...).New("backfill", backfill.UID)
+	if rule.Type() != models.RuleTypeRecording {
+		return errors.New("the rule is not a recording rule")
+	}
+	interval := time.Duration(rule.IntervalSeconds) * time.Second
+	if interval <= 0 {
+		return fmt.Errorf("invalid evaluation interval %s", interval)
+	}
+
+	evalCtx := eval.NewContext(ctx, schedule.SchedulerUserFor(rule.OrgID))
+	evaluator, err := b.evalFactory.Create(evalCtx, rule.GetEvalCondition().WithSource("backfill"))
+	if err != nil {
+		return fmt.Errorf("failed to build rule evaluator: %w", err)
+	}
+
+	logger.Info("Backfill started", "from", backfill.Next, "to", backfill.To, "interval", interval)
+	for !backfill.Next.After(backfill.To) {
+		if err := b.limiter.Wait(ctx); err != nil {
+			return err
+		}
+		if err := b.evaluate(ctx, evaluator, rule, backfill.Next); err != nil {
+			logger.Error("Backfill evaluation failed", "error", err, "time", backfill.Next)
+			return err
+		}
+		backfill.Next = backfill.Next.Add(interval)
+		backfill.Updated = b.clock.Now()
+		backfill.Heartbeat = backfill.Updated
+		if err := b.store.UpdateRecordingRuleBackfill(ctx, backfill, b.owner); err != nil {
+			return fmt.Errorf("failed to save the progress: %w", err)
+		}
+	}
+	logger.Info("Backfill completed")
+	return nil
+}
+
+func (b *Backfiller) evaluate(ctx context.Context, evaluator eval.ConditionEvaluator, rule *models.AlertRule, t time.Time) error {
+	result, err := evaluator.EvaluateRaw(ctx, t)
+	if err != nil {
+		return fmt.Errorf("failed to evaluate the rule at %s: %w", t.Format(time.RFC3339), err)
+	}
+	if err := eval.FindConditionError(result, rule.Record.From); err != nil {
+		return fmt.Errorf("the query failed with an error at %s: %w", t.Format(time.RFC3339), err)
+	}
+	if resp, ok := result.Responses[rule.Record.From]; ok && eval.IsNoData(resp) {
+		return nil
+	}
+	frames, err := schedule.RecordedFrames(rule.Record.From, result)
+	if err != nil {
+		return err
+	}
+	if err := b.writer.WriteDatasource(ctx, rule.Record.TargetDatasourceUID, rule.Record.Metric, t, frames, rule.OrgID, rule.Labels); err != nil {
+		return fmt.Errorf("failed to write the results at %s: %w", t.Format(time.RFC3339), err)
+	}
+	return nil
+}
+
+// finish saves the final state of the backfill.
+func (b *Backfiller) finish(ctx context.Context, backfill *models.RecordingRuleBackfill, err error) {
+	switch {
+	case err == nil:
+		backfill.State = models.BackfillStateCompleted
+	case errors.Is(err, errBackfillCancelled):
+		backfill.State = models.BackfillStateCancelled
+	default:
+		backfill.State = models.BackfillStateFailed
+		backfill.Error = err.Error()
+	}
+	backfill.Updated = b.clock.Now()
+	backfill.Heartbeat = backfill.Updated
+	if err := b.store.UpdateRecordingRuleBackfill(ctx, backfill, b.owner); err != nil {
+		b.log.Error("Failed to save the state of the backfill", "error", err, "backfill", backfill.UID)
+	}
+}
@@ -0,0 +1,471 @@
+package backtesting
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval/eval_mocks"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestBackfiller(t *testing.T) {
+	cfg := setting.RecordingRuleSettings{BackfillRate: 1000, BackfillMaxRange: 24 * time.Hour}
+	now := time.Now().Truncate(time.Second)
+	from := now.Add(-time.Hour)
+	to := from.Add(50 * time.Second)
+
+	newRule := func() *models.AlertRule {
+		return models.RuleGen.With(models.RuleMuts.WithAllRecordingRules(), models.RuleMuts.WithIntervalSeconds(10)).GenerateRef()
+	}
+
+	// start runs the backfiller until the end of the test.
+	start := func(t *testing.T, b *Backfiller) {
+		t.Helper()
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			_ = b.Run(ctx)
+			close(done)
+		}()
+		t.Cleanup(func() {
+			cancel()
+			<-done
+		})
+		require.Eventually(t, func() bool {
+			b.mtx.Lock()
+			defer b.mtx.Unlock()
+			return b.ctx != nil
+		}, time.Second, 10*time.Millisecond)
+	}
+
+	waitForState := func(t *testing.T, store *fakeBackfillStore, orgID int64, uid string, state models.BackfillState) *models.RecordingRuleBackfill {
+		t.Helper()
+		var result *models.RecordingRuleBackfill
+		require.Eventually(t, func() bool {
+			backfill, err := store.GetRecordingRuleBackfill(context.Background(), orgID, uid)
+			require.NoError(t, err)
+			result = backfill
+			return backfill.State == state
+		}, 5*time.Second, 10*time.Millisecond)
+		return result
+	}
+
+	t.Run("writes the results of every evaluation with its timestamp", func(t *testing.T) {
+		rule := newRule()
+		store := newFakeBackfillStore()
+		w := &fakeRecordingWriter{}
+		b := NewBackfiller(cfg, eval_mocks.NewEvaluatorFactory(&fakeRawEvaluator{refID: rule.Record.From}), w, store, store, clock.New())
+		start(t, b)
+
+		backfill, err := b.Start(context.Background(), rule, from, to)
+		require.NoError(t, err)
+		require.Equal(t, models.BackfillStateRunning, backfill.State)
+
+		result := waitForState(t, store, rule.OrgID, backfill.UID, models.BackfillStateCompleted)
+		require.Equal(t, to.Add(10*time.Second), result.Next)
+		require.Empty(t, result.Error)
+
+		expected := []time.Time{}
+		for ts := from; !ts.After(to); ts = ts.Add(10 * time.Second) {
+			expected = append(expected, ts)
+		}
+		require.Equal(t, expected, w.Times())
+		require.Equal(t, rule.Record.Metric, w.lastName)
+		require.Equal(t, rule.Record.TargetDatasourceUID, w.lastDatasourceUID)
+	})
+
+	t.Run("does not write when the query returns no data", func(t *testing.T) {
+		rule := newRule()
+		store := newFakeBackfillStore()
+		w := &fakeRecordingWriter{}
+		b := NewBackfiller(cfg, eval_mocks.NewEvaluatorFactory(&fakeRawEvaluator{refID: rule.Record.From, noData: true}), w, store, store, clock.New())
+		start(t, b)
+
+		backfill, err := b.Start(context.Background(), rule, from, to)
+		require.NoError(t, err)
+
+		waitForState(t, store, rule.OrgID, backfill.UID, models.BackfillStateCompleted)
+		require.Empty(t, w.Times())
+	})
+
+	t.Run("fails on write errors and resumes from the failed evaluation", func(t *testing.T) {
+		rule := newRule()
+		store := newFakeBackfillStore()
+		w := &fakeRecordingWriter{failAt: from.Add(20 * time.Second)}
+		b := NewBackfiller(cfg, eval_mocks.NewEvaluatorFactory(&fakeRawEvaluator{refID: rule.Record.From}), w, store, store, clock.New())
+		start(t, b)
+
+		backfill, err := b.Start(context.Background(), rule, from, to)
+		require.NoError(t, err)
+
+		result := waitForState(t, store, rule.OrgID, backfill.UID, models.BackfillStateFailed)
+		require.Equal(t, from.Add(20*time.Second), result.Next)
+		require.Contains(t, result.Error, "write failed")
+		require.Len(t, w.Times(), 2)
+
+		w.setFailAt(time.Time{})
+		_, err = b.Resume(context.Background(), rule, backfill.UID)
+		require.NoError(t, err)
+
+		result = waitForState(t, store, rule.OrgID, backfill.UID, models.BackfillStateCompleted)
+		require.Empty(t, result.Error)
+		require.Len(t, w.Times(), 6)
+		require.Equal(t, from.Add(20*time.Second), w.Times()[2])
+
+		_, err = b.Resume(context.Background(), rule, backfill.UID)
+		require.ErrorIs(t, err, ErrBackfillNotResumable)
+	})
+
+	t.Run("cancels a running backfill", func(t *testing.T) {
+		rule := newRule()
+		store := newFakeBackfillStore()
+		evaluator := &fakeRawEvaluator{refID: rule.Record.From, blockAt: from.Add(10 * time.Second)}
+		b := NewBackfiller(cfg, eval_mocks.NewEvaluatorFactory(evaluator), &fakeRecordingWriter{}, store, store, clock.New())
+		start(t, b)
+
+		backfill, err := b.Start(context.Background(), rule, from, to)
+		require.NoError(t, err)
+		require.Eventually(t, func() bool {
+			stored, err := store.GetRecordingRuleBackfill(context.Background(), rule.OrgID, backfill.UID)
+			require.NoError(t, err)
+			return stored.Next.Equal(from.Add(10 * time.Second))
+		}, 5*time.Second, 10*time.Millisecond)
+
+		_, err = b.Cancel(context.Background(), rule, backfill.UID)
+		require.NoError(t, err)
+
+		result := waitForState(t, store, rule.OrgID, backfill.UID, models.BackfillStateCancelled)
+		require.Equal(t, from.Add(10*time.Second), result.Next)
+		require.Empty(t, result.Error)
+	})
+
+	t.Run("resumes running backfills on start", func(t *testing.T) {
+		rule := newRule()
+		store := newFakeBackfillStore()
+		store.rules[rule.UID] = rule
+		backfill := &models.RecordingRuleBackfill{
+			UID:     "running",
+			OrgID:   rule.OrgID,
+			RuleUID: rule.UID,
+			From:    from,
+			To:      to,
+			Next:    from.Add(30 * time.Second),
+			State:   models.BackfillStateRunning,
+		}
+		require.NoError(t, store.InsertRecordingRuleBackfill(context.Background(), backfill))
+		w := &fakeRecordingWriter{}
+		b := NewBackfiller(cfg, eval_mocks.NewEvaluatorFactory(&fakeRawEvaluator{refID: rule.Record.From}), w, store, store, clock.New())
+		start(t, b)
+
+		waitForState(t, store, rule.OrgID, backfill.UID, models.BackfillStateCompleted)
+		require.Equal(t, []time.Time{from.Add(30 * time.Second), from.Add(40 * time.Second), from.Add(50 * time.Second)}, w.Times())
+	})
+
+	t.Run("does not run backfills of other instances until their heartbeat times out", func(t *testing.T) {
+		rule := newRule()
+		store := newFakeBackfillStore()
+		store.rules[rule.UID] = rule
+		clk := clock.NewMock()
+		clk.Set(now)
+		backfill := &models.RecordingRuleBackfill{
+			UID:       "owned",
+			OrgID:     rule.OrgID,
+			RuleUID:   rule.UID,
+			From:      from,
+			To:        to,
+			Next:      from,
+			State:     models.BackfillStateRunning,
+			Owner:     "other",
+			Heartbeat: now,
+		}
+		require.NoError(t, store.InsertRecordingRuleBackfill(context.Background(), backfill))
+		w := &fakeRecordingWriter{}
+		b := NewBackfiller(cfg, eval_mocks.NewEvaluatorFactory(&fakeRawEvaluator{refID: rule.Record.From}), w, store, store, clk)
+		start(t, b)
+
+		clk.Add(backfillHeartbeatInterval)
+		time.Sleep(50 * time.Millisecond)
+		require.Empty(t, w.Times())
+
+		clk.Add(backfillHeartbeatTimeout)
+		result := waitForState(t, store, rule.OrgID, backfill.UID, models.BackfillStateCompleted)
+		require.Equal(t, b.owner, result.Owner)
+		require.Len(t, w.Times(), 6)
+	})
+
+	t.Run("stops when the backfill is cancelled on another instance", func(t *testing.T) {
+		rule := newRule()
+		store := newFakeBackfillStore()
+		evaluator := &fakeRawEvaluator{refID: rule.Record.From}
+		w := &fakeRecordingWriter{}
+		b := NewBackfiller(setting.RecordingRuleSettings{BackfillRate: 10, BackfillMaxRange: 24 * time.Hour}, eval_mocks.NewEvaluatorFactory(evaluator), w, store, store, clock.New())
+		start(t, b)
+
+		backfill, err := b.Start(context.Background(), rule, from, to)
+		require.NoError(t, err)
+		require.Eventually(t, func() bool {
+			return len(w.Times()) > 0
+		}, 5*time.Second, 10*time.Millisecond)
+		store.setState(backfill.UID, models.BackfillStateCancelled)
+
+		require.Eventually(t, func() bool {
+			b.mtx.Lock()
+			defer b.mtx.Unlock()
+			return len(b.running) == 0
+		}, 5*time.Second, 10*time.Millisecond)
+		result, err := store.GetRecordingRuleBackfill(context.Background(), rule.OrgID, backfill.UID)
+		require.NoError(t, err)
+		require.Equal(t, models.BackfillStateCancelled, result.State)
+		require.Less(t, len(w.Times()), 6)
+	})
+
+	t.Run("rejects backfills that overlap a running backfill of the rule", func(t *testing.T) {
+		rule := newRule()
+		store := newFakeBackfillStore()
+		evaluator := &fakeRawEvaluator{refID: rule.Record.From, blockAt: from}
+		b := NewBackfiller(cfg, eval_mocks.NewEvaluatorFactory(evaluator), &fakeRecordingWriter{}, store, store, clock.New())
+		start(t, b)
+
+		_, err := b.Start(context.Background(), rule, from, to)
+		require.NoError(t, err)
+
+		_, err = b.Start(context.Background(), rule, to, to.Add(time.Minute))
+		require.ErrorIs(t, err, models.ErrBackfillConflict)
+
+		_, err = b.Start(context.Background(), rule, to.Add(time.Second), to.Add(time.Minute))
+		require.NoError(t, err)
+	})
+
+	t.Run("validates the time range", func(t *testing.T) {
+		rule := newRule()
+		store := newFakeBackfillStore()
+		b := NewBackfiller(cfg, eval_mocks.NewEvaluatorFactory(&fakeRawEvaluator{refID: rule.Record.From}), &fakeRecordingWriter{}, store, store, clock.New())
+		start(t, b)
+
+		testCases := []struct {
+			name     string
+			from, to time.Time
+		}{
+			{name: "from after to", from: to, to: from},
+			{name: "in the future", from: now, to: now.Add(time.Hour)},
+			{name: "shorter than interval", from: from, to: from.Add(5 * time.Second)},
+			{name: "longer than max range", from: now.Add(-48 * time.Hour), to: now.Add(-time.Minute)},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				_, err := b.Start(context.Background(), rule, tc.from, tc.to)
+				require.ErrorIs(t, err, ErrInvalidInputData)
+			})
+		}
+
+		t.Run("alerting rule", func(t *testing.T) {
+			_, err := b.Start(context.Background(), models.RuleGen.GenerateRef(), from, to)
+			require.ErrorIs(t, err, ErrInvalidInputData)
+		})
+	})
+}
+
+type fakeRawEvaluator struct {
+	refID  string
+	noData bool
+	// blockAt makes the evaluation at the time wait until the context is cancelled.
+	blockAt time.Time
+}
+
+func (e *fakeRawEvaluator) EvaluateRaw(ctx context.Context, now time.Time) (*backend.QueryDataResponse, error) {
+	if now.Equal(e.blockAt) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	frames := data.Frames{data.NewFrame("", data.NewField("value", data.Labels{"foo": "bar"}, []float64{1}))}
+	if e.noData {
+		frames = nil
+	}
+	return &backend.QueryDataResponse{Responses: backend.Responses{e.refID: backend.DataResponse{Frames: frames}}}, nil
+}
+
+func (e *fakeRawEvaluator) Evaluate(ctx context.Context, now time.Time) (eval.Results, error) {
+	return nil, errors.New("not implemented")
+}
+
+type fakeRecordingWriter struct {
+	mtx               sync.Mutex
+	times             []time.Time
+	failAt            time.Time
+	lastName          string
+	lastDatasourceUID string
+}
+
+func (w *fakeRecordingWriter) WriteDatasource(_ context.Context, dsUID string, name string, t time.Time, _ data.Frames, _ int64, _ map[string]string) error {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	if t.Equal(w.failAt) {
+		return errors.New("write failed")
+	}
+	w.times = append(w.times, t)
+	w.lastName, w.lastDatasourceUID = name, dsUID
+	return nil
+}
+
+func (w *fakeRecordingWriter) Times() []time.Time {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	return append([]time.Time{}, w.times...)
+}
+
+func (w *fakeRecordingWriter) setFailAt(t time.Time) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	w.failAt = t
+}
+
+type fakeBackfillStore struct {
+	mtx       sync.Mutex
+	backfills map[string]models.RecordingRuleBackfill
+	rules     map[string]*models.AlertRule
+}
+
+func newFakeBackfillStore() *fakeBackfillStore {
+	return &fakeBackfillStore{
+		backfills: map[string]models.RecordingRuleBackfill{},
+		rules:     map[string]*models.AlertRule{},
+	}
+}
+
+func (s *fakeBackfillStore) InsertRecordingRuleBackfill(_ context.Context, backfill *models.RecordingRuleBackfill) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if err := s.checkOverlap(backfill); err != nil {
+		return err
+	}
+	backfill.ID = int64(len(s.backfills) + 1)
+	s.backfills[backfill.UID] = *backfill
+	return nil
+}
+
+func (s *fakeBackfillStore) UpdateRecordingRuleBackfill(_ context.Context, backfill *models.RecordingRuleBackfill, owner string) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	stored, ok := s.backfills[backfill.UID]
+	if !ok || stored.State != models.BackfillStateRunning || owner != "" && stored.Owner != owner {
+		return models.ErrBackfillStateChanged
+	}
+	stored.Next, stored.State, stored.Error, stored.Updated, stored.Heartbeat = backfill.Next, backfill.State, backfill.Error, backfill.Updated, backfill.Heartbeat
+	s.backfills[backfill.UID] = stored
+	return nil
+}
+
+func (s *fakeBackfillStore) ResumeRecordingRuleBackfill(_ context.Context, backfill *models.RecordingRuleBackfill) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if err := s.checkOverlap(backfill); err != nil {
+		return err
+	}
+	stored, ok := s.backfills[backfill.UID]
+	if !ok || stored.State != models.BackfillStateFailed && stored.State != models.BackfillStateCancelled {
+		return models.ErrBackfillStateChanged
+	}
+	stored.State, stored.Error, stored.Updated, stored.Owner, stored.Heartbeat = backfill.State, backfill.Error, backfill.Updated, backfill.Owner, backfill.Heartbeat
+	s.backfills[backfill.UID] = stored
+	return nil
+}
+
+func (s *fakeBackfillStore) ClaimRecordingRuleBackfill(_ context.Context, backfill *models.RecordingRuleBackfill, staleBefore time.Time) (bool, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	stored, ok := s.backfills[backfill.UID]
+	if !ok || stored.State != models.BackfillStateRunning {
+		return false, nil
+	}
+	if stored.Owner != "" && stored.Owner != backfill.Owner && !stored.Heartbeat.Before(staleBefore) {
+		return false, nil
+	}
+	stored.Owner, stored.Heartbeat = backfill.Owner, backfill.Heartbeat
+	s.backfills[backfill.UID] = stored
+	return true, nil
+}
+
+func (s *fakeBackfillStore) HeartbeatRecordingRuleBackfills(_ context.Context, owner string, at time.Time) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for uid, backfill := range s.backfills {
+		if backfill.Owner == owner && backfill.State == models.BackfillStateRunning {
+			backfill.Heartbeat = at
+			s.backfills[uid] = backfill
+		}
+	}
+	return nil
+}
+
+func (s *fakeBackfillStore) checkOverlap(backfill *models.RecordingRuleBackfill) error {
+	for _, other := range s.backfills {
+		if other.UID != backfill.UID && other.OrgID == backfill.OrgID && other.RuleUID == backfill.RuleUID &&
+			other.State == models.BackfillStateRunning && backfill.Overlaps(other) {
+			return models.ErrBackfillConflict
+		}
+	}
+	return nil
+}
+
+// setState changes the state of a backfill as another Grafana instance would.
+func (s *fakeBackfillStore) setState(uid string, state models.BackfillState) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	backfill := s.backfills[uid]
+	backfill.State = state
+	s.backfills[uid] = backfill
+}
+
+func (s *fakeBackfillStore) GetRecordingRuleBackfill(_ context.Context, orgID int64, uid string) (*models.RecordingRuleBackfill, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	backfill, ok := s.backfills[uid]
+	if !ok || backfill.OrgID != orgID {
+		return nil, models.ErrBackfillNotFound
+	}
+	return &backfill, nil
+}
+
+func (s *fakeBackfillStore) ListRecordingRuleBackfills(_ context.Context, orgID int64, ruleUID string) ([]*models.RecordingRuleBackfill, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	var result []*models.RecordingRuleBackfill
+	for _, backfill := range s.backfills {
+		if backfill.OrgID == orgID && backfill.RuleUID == ruleUID {
+			result = append(result, &backfill)
+		}
+	}
+	return result, nil
+}
+
+func (s *fakeBackfillStore) ListRecordingRuleBackfillsByState(_ context.Context, state models.BackfillState) ([]*models.RecordingRuleBackfill, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	var result []*models.RecordingRuleBackfill
+	for _, backfill := range s.backfills {
+		if backfill.State == state {
+			result = append(result, &backfill)
+		}
+	}
+	return result, nil
+}
+
+func (s *fakeBackfillStore) GetAlertRuleByUID(_ context.Context, query *models.GetAlertRuleByUIDQuery) (*models.AlertRule, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	rule, ok := s.rules[query.UID]
+	if !ok {
+		return nil, models.ErrAlertRuleNotFound
+	}
+	return rule, nil
+}
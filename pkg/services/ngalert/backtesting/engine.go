@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/benbjohnson/clock"
+	"github.com/prometheus/alertmanager/dispatch"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 
@@ -19,6 +20,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/schedule"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
 )
 
 var (
@@ -42,11 +44,18 @@ type stateManager interface {
 type Engine struct {
 	evalFactory        eval.EvaluatorFactory
 	createStateManager func() stateManager
+	amConfigProvider   AlertmanagerConfigProvider
+	appUrl             *url.URL
+	// disableGrafanaFolder is true if the grafana_folder label is not added to the alerts.
+	disableGrafanaFolder bool
 }
 
-func NewEngine(appUrl *url.URL, evalFactory eval.EvaluatorFactory, tracer tracing.Tracer) *Engine {
+func NewEngine(appUrl *url.URL, evalFactory eval.EvaluatorFactory, tracer tracing.Tracer, amConfigProvider AlertmanagerConfigProvider, disableGrafanaFolder bool) *Engine {
 	return &Engine{
-		evalFactory: evalFactory,
+		evalFactory:          evalFactory,
+		amConfigProvider:     amConfigProvider,
+		appUrl:               appUrl,
+		disableGrafanaFolder: disableGrafanaFolder,
 		createStateManager: func() stateManager {
 			cfg := state.ManagerCfg{
 				Metrics:       nil,
@@ -63,7 +72,11 @@ func NewEngine(appUrl *url.URL, evalFactory eval.EvaluatorFactory, tracer tracin
 	}
 }
 
-func (e *Engine) Test(ctx context.Context, user identity.Requester, rule *models.AlertRule, from, to time.Time) (*data.Frame, error) {
+// Test evaluates the rule at every interval between from and to, and returns a frame with the state of each alert
+// instance at each evaluation. If the engine has an AlertmanagerConfigProvider, the custom metadata of the frame is
+// the NotificationTimeline of the notifications that the notification policies of the organization would have sent.
+// The folderTitle is the full path of the folder of the rule, which is the value of the grafana_folder label.
+func (e *Engine) Test(ctx context.Context, user identity.Requester, rule *models.AlertRule, folderTitle string, from, to time.Time) (*data.Frame, error) {
 	ruleCtx := models.WithRuleKey(ctx, rule.GetKey())
 	logger := logger.FromContext(ctx)
 
@@ -77,6 +90,19 @@ func (e *Engine) Test(ctx context.Context, user identity.Requester, rule *models
 
 	stateManager := e.createStateManager()
 
+	var notifications *notificationSimulator
+	if e.amConfigProvider != nil {
+		amConfig, err := e.amConfigProvider.GetAlertmanagerConfiguration(ctx, rule.OrgID, true)
+		if err != nil && !errors.Is(err, store.ErrNoAlertmanagerConfiguration) {
+			return nil, fmt.Errorf("failed to get the notification policies: %w", err)
+		}
+		// without a configuration there are no notification policies to simulate
+		if err == nil && amConfig.AlertmanagerConfig.Route != nil {
+			route := dispatch.NewRoute(amConfig.AlertmanagerConfig.Route.AsAMRoute(), nil)
+			notifications = newNotificationSimulator(route, e.appUrl, state.GetRuleExtraLabels(logger, rule, folderTitle, !e.disableGrafanaFolder))
+		}
+	}
+
 	evaluator, err := backtestingEvaluatorFactory(ruleCtx, e.evalFactory, user, rule.GetEvalCondition().WithSource("backtesting"), &schedule.AlertingResultsFromRuleState{
 		Manager: stateManager,
 		Rule:    rule,
@@ -98,6 +124,9 @@ func (e *Engine) Test(ctx context.Context, user identity.Requester, rule *models
 			return nil
 		}
 		states := stateManager.ProcessEvalResults(ruleCtx, currentTime, rule, results, nil, nil)
+		if notifications != nil {
+			notifications.observe(currentTime, states)
+		}
 		tsField.Set(idx, currentTime)
 		for _, s := range states {
 			field, ok := valueFields[s.CacheID]
@@ -126,6 +155,9 @@ func (e *Engine) Test(ctx context.Context, user identity.Requester, rule *models
 	if err != nil {
 		return nil, err
 	}
+	if notifications != nil {
+		result.SetMeta(&data.FrameMeta{Custom: notifications.timeline(to)})
+	}
 	logger.Info("Rule testing finished successfully", "duration", time.Since(start))
 	return result, nil
 }
@@ -0,0 +1,262 @@
+package backtesting
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/prometheus/alertmanager/dispatch"
+	"github.com/prometheus/common/model"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+// AlertmanagerConfigProvider provides the Alertmanager configuration of an organization.
+type AlertmanagerConfigProvider interface {
+	GetAlertmanagerConfiguration(ctx context.Context, org int64, withAutogen bool) (apimodels.GettableUserConfig, error)
+}
+
+// NotificationTimeline is the list of notifications that would have been sent during a backtest, by contact point.
+type NotificationTimeline struct {
+	ContactPoints []ContactPointNotifications `json:"contactPoints"`
+}
+
+// ContactPointNotifications are the notifications that would have been sent to a contact point, in order of time.
+type ContactPointNotifications struct {
+	Receiver      string         `json:"receiver"`
+	Notifications []Notification `json:"notifications"`
+}
+
+// Notification is a single notification of a group of alerts.
+type Notification struct {
+	Time        time.Time           `json:"time"`
+	GroupLabels map[string]string   `json:"groupLabels"`
+	Firing      []map[string]string `json:"firing"`
+	Resolved    []map[string]string `json:"resolved"`
+}
+
+// notificationSimulator replays the alerts of a backtest through the notification policy tree, and records the
+// notifications that the aggregation groups of the matching policies would send. It follows the grouping, group wait,
+// group interval and repeat interval of the policies, but not their mute timings or inhibition rules.
+type notificationSimulator struct {
+	route       *dispatch.Route
+	appURL      *url.URL
+	extraLabels model.LabelSet
+	groups      map[string]*simulatedGroup
+	byReceiver  map[string][]Notification
+}
+
+type simulatedGroup struct {
+	opts      *dispatch.RouteOpts
+	labels    model.LabelSet
+	alerts    map[model.Fingerprint]simulatedAlert
+	nextFlush time.Time
+	// notified is nil until the group sends its first notification.
+	notified *simulatedNotify
+}
+
+type simulatedAlert struct {
+	labels   model.LabelSet
+	resolved bool
+}
+
+type simulatedNotify struct {
+	at       time.Time
+	firing   map[model.Fingerprint]struct{}
+	resolved map[model.Fingerprint]struct{}
+}
+
+func newNotificationSimulator(route *dispatch.Route, appURL *url.URL, extraLabels map[string]string) *notificationSimulator {
+	ls := make(model.LabelSet, len(extraLabels))
+	for k, v := range extraLabels {
+		ls[model.LabelName(k)] = model.LabelValue(v)
+	}
+	return &notificationSimulator{
+		route:       route,
+		appURL:      appURL,
+		extraLabels: ls,
+		groups:      map[string]*simulatedGroup{},
+		byReceiver:  map[string][]Notification{},
+	}
+}
+
+// observe flushes the groups that are due before now, and then adds the alerts of the state transitions of the
+// evaluation at now to the groups of the policies they match.
+func (n *notificationSimulator) observe(now time.Time, transitions state.StateTransitions) {
+	n.advance(now)
+	for _, t := range transitions {
+		resolved := t.State.State == eval.Normal && t.ResolvedAt != nil && t.ResolvedAt.Equal(now)
+		switch t.State.State {
+		case eval.Alerting, eval.NoData, eval.Error:
+		default:
+			if !resolved {
+				continue
+			}
+		}
+		postable := state.StateToPostableAlert(t, n.appURL)
+		labels := n.extraLabels.Clone()
+		for k, v := range postable.Labels {
+			labels[model.LabelName(k)] = model.LabelValue(v)
+		}
+		for _, r := range n.route.Match(labels) {
+			groupLabels := groupLabelsFor(labels, &r.RouteOpts)
+			key := r.ID() + ":" + groupLabels.String()
+			g, ok := n.groups[key]
+			if !ok {
+				if resolved {
+					continue // the alert was never sent to this group
+				}
+				g = &simulatedGroup{
+					opts:      &r.RouteOpts,
+					labels:    groupLabels,
+					alerts:    map[model.Fingerprint]simulatedAlert{},
+					nextFlush: now.Add(r.RouteOpts.GroupWait),
+				}
+				n.groups[key] = g
+			}
+			g.alerts[labels.Fingerprint()] = simulatedAlert{labels: labels, resolved: resolved}
+		}
+	}
+}
+
+// advance flushes the groups, in order of time, until the given time.
+func (n *notificationSimulator) advance(until time.Time) {
+	for {
+		var next *simulatedGroup
+		var nextKey string
+		for key, g := range n.groups {
+			if g.nextFlush.After(until) {
+				continue
+			}
+			if next == nil || g.nextFlush.Before(next.nextFlush) || (g.nextFlush.Equal(next.nextFlush) && key < nextKey) {
+				next, nextKey = g, key
+			}
+		}
+		if next == nil {
+			return
+		}
+		n.flush(nextKey, next)
+	}
+}
+
+// flush sends a notification for the group if it would not be deduplicated, removes its resolved alerts, and
+// schedules its next flush.
+func (n *notificationSimulator) flush(key string, g *simulatedGroup) {
+	at := g.nextFlush
+	firing := map[model.Fingerprint]struct{}{}
+	resolved := map[model.Fingerprint]struct{}{}
+	for fp, a := range g.alerts {
+		if a.resolved {
+			resolved[fp] = struct{}{}
+		} else {
+			firing[fp] = struct{}{}
+		}
+	}
+
+	if g.needsNotification(at, firing, resolved) {
+		notification := Notification{
+			Time:        at,
+			GroupLabels: labelSetToMap(g.labels),
+			Firing:      []map[string]string{},
+			Resolved:    []map[string]string{},
+		}
+		for _, fp := range sortedFingerprints(g.alerts) {
+			a := g.alerts[fp]
+			if a.resolved {
+				notification.Resolved = append(notification.Resolved, labelSetToMap(a.labels))
+			} else {
+				notification.Firing = append(notification.Firing, labelSetToMap(a.labels))
+			}
+		}
+		n.byReceiver[g.opts.Receiver] = append(n.byReceiver[g.opts.Receiver], notification)
+		g.notified = &simulatedNotify{at: at, firing: firing, resolved: resolved}
+	}
+
+	for fp := range resolved {
+		delete(g.alerts, fp)
+	}
+	if len(g.alerts) == 0 {
+		delete(n.groups, key)
+		return
+	}
+	g.nextFlush = at.Add(g.opts.GroupInterval)
+}
+
+// needsNotification decides like the deduplication stage of the Alertmanager whether a notification is sent.
+func (g *simulatedGroup) needsNotification(at time.Time, firing, resolved map[model.Fingerprint]struct{}) bool {
+	if g.notified == nil {
+		return len(firing) > 0
+	}
+	if !isSubset(firing, g.notified.firing) {
+		return true
+	}
+	if len(firing) == 0 {
+		return len(g.notified.firing) > 0
+	}
+	if !isSubset(resolved, g.notified.resolved) {
+		return true
+	}
+	return !g.notified.at.Add(g.opts.RepeatInterval).After(at)
+}
+
+// timeline returns the notifications that were sent until the given time.
+func (n *notificationSimulator) timeline(until time.Time) NotificationTimeline {
+	n.advance(until)
+	receivers := make([]string, 0, len(n.byReceiver))
+	for receiver := range n.byReceiver {
+		receivers = append(receivers, receiver)
+	}
+	sort.Strings(receivers)
+	result := NotificationTimeline{ContactPoints: make([]ContactPointNotifications, 0, len(receivers))}
+	for _, receiver := range receivers {
+		notifications := n.byReceiver[receiver]
+		sort.SliceStable(notifications, func(i, j int) bool { return notifications[i].Time.Before(notifications[j].Time) })
+		result.ContactPoints = append(result.ContactPoints, ContactPointNotifications{
+			Receiver:      receiver,
+			Notifications: notifications,
+		})
+	}
+	return result
+}
+
+func groupLabelsFor(labels model.LabelSet, opts *dispatch.RouteOpts) model.LabelSet {
+	if opts.GroupByAll {
+		return labels.Clone()
+	}
+	groupLabels := model.LabelSet{}
+	for name := range opts.GroupBy {
+		if v, ok := labels[name]; ok {
+			groupLabels[name] = v
+		}
+	}
+	return groupLabels
+}
+
+func isSubset(set, superset map[model.Fingerprint]struct{}) bool {
+	for fp := range set {
+		if _, ok := superset[fp]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func sortedFingerprints(alerts map[model.Fingerprint]simulatedAlert) []model.Fingerprint {
+	fps := make([]model.Fingerprint, 0, len(alerts))
+	for fp := range alerts {
+		fps = append(fps, fp)
+	}
+	sort.Slice(fps, func(i, j int) bool { return alerts[fps[i]].labels.String() < alerts[fps[j]].labels.String() })
+	return fps
+}
+
+func labelSetToMap(ls model.LabelSet) map[string]string {
+	m := make(map[string]string, len(ls))
+	for k, v := range ls {
+		m[string(k)] = string(v)
+	}
+	return m
+}
@@ -0,0 +1,212 @@
+package backtesting
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	alertingModels "github.com/grafana/alerting/models"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/dispatch"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+func TestNotificationSimulator(t *testing.T) {
+	duration := func(d time.Duration) *model.Duration {
+		md := model.Duration(d)
+		return &md
+	}
+	teamMatcher, err := labels.NewMatcher(labels.MatchEqual, "team", "a")
+	require.NoError(t, err)
+	root := &config.Route{
+		Receiver:       "default",
+		GroupWait:      duration(30 * time.Second),
+		GroupInterval:  duration(time.Minute),
+		RepeatInterval: duration(3 * time.Minute),
+		Routes: []*config.Route{
+			{
+				Receiver: "team-a",
+				Matchers: config.Matchers{teamMatcher},
+			},
+		},
+	}
+	root.GroupBy = []model.LabelName{"alertname"} // set by the YAML unmarshaller from group_by
+
+	from := time.Unix(0, 0).UTC()
+	at := func(d time.Duration) time.Time { return from.Add(d) }
+	firing := func(l data.Labels) state.StateTransition {
+		return state.StateTransition{State: &state.State{Labels: l, State: eval.Alerting}}
+	}
+	resolved := func(l data.Labels, now time.Time) state.StateTransition {
+		return state.StateTransition{
+			State:         &state.State{Labels: l, State: eval.Normal, ResolvedAt: &now},
+			PreviousState: eval.Alerting,
+		}
+	}
+	pending := func(l data.Labels) state.StateTransition {
+		return state.StateTransition{State: &state.State{Labels: l, State: eval.Pending}}
+	}
+
+	teamA := data.Labels{"host": "1", "team": "a"}
+	other := data.Labels{"host": "2"}
+
+	sim := newNotificationSimulator(dispatch.NewRoute(root, nil), nil, map[string]string{"alertname": "rule"})
+	for d := time.Duration(0); d < 10*time.Minute; d += 10 * time.Second {
+		now := at(d)
+		var transitions state.StateTransitions
+		switch {
+		case d < 2*time.Minute:
+			transitions = append(transitions, firing(teamA))
+		case d == 2*time.Minute:
+			transitions = append(transitions, resolved(teamA, now))
+		}
+		if d < time.Minute {
+			transitions = append(transitions, pending(other))
+		} else {
+			transitions = append(transitions, firing(other))
+		}
+		sim.observe(now, transitions)
+	}
+	timeline := sim.timeline(at(10 * time.Minute))
+
+	teamALabels := map[string]string{"alertname": "rule", "host": "1", "team": "a"}
+	otherLabels := map[string]string{"alertname": "rule", "host": "2"}
+	groupLabels := map[string]string{"alertname": "rule"}
+	require.Equal(t, NotificationTimeline{ContactPoints: []ContactPointNotifications{
+		{
+			Receiver: "default",
+			Notifications: []Notification{
+				{Time: at(90 * time.Second), GroupLabels: groupLabels, Firing: []map[string]string{otherLabels}, Resolved: []map[string]string{}},
+				// repeated after the repeat interval, at the next group interval
+				{Time: at(270 * time.Second), GroupLabels: groupLabels, Firing: []map[string]string{otherLabels}, Resolved: []map[string]string{}},
+				{Time: at(450 * time.Second), GroupLabels: groupLabels, Firing: []map[string]string{otherLabels}, Resolved: []map[string]string{}},
+			},
+		},
+		{
+			Receiver: "team-a",
+			Notifications: []Notification{
+				{Time: at(30 * time.Second), GroupLabels: groupLabels, Firing: []map[string]string{teamALabels}, Resolved: []map[string]string{}},
+				{Time: at(150 * time.Second), GroupLabels: groupLabels, Firing: []map[string]string{}, Resolved: []map[string]string{teamALabels}},
+			},
+		},
+	}}, timeline)
+
+	t.Run("alerts resolved before the group wait are not notified", func(t *testing.T) {
+		sim := newNotificationSimulator(dispatch.NewRoute(root, nil), nil, nil)
+		sim.observe(at(0), state.StateTransitions{firing(teamA)})
+		sim.observe(at(10*time.Second), state.StateTransitions{resolved(teamA, at(10*time.Second))})
+		require.Empty(t, sim.timeline(at(10*time.Minute)).ContactPoints)
+	})
+}
+
+func TestEngineNotificationTimeline(t *testing.T) {
+	evaluator := &fakeBacktestingEvaluator{
+		evalCallback: func(now time.Time) (eval.Results, error) {
+			return eval.Results{}, nil
+		},
+	}
+	backtestingEvaluatorFactory = func(ctx context.Context, evalFactory eval.EvaluatorFactory, user identity.Requester, condition models.Condition, r eval.AlertingResultsReader) (backtestingEvaluator, error) {
+		return evaluator, nil
+	}
+	t.Cleanup(func() {
+		backtestingEvaluatorFactory = newBacktestingEvaluator
+	})
+
+	folderMatcher, err := labels.NewMatcher(labels.MatchEqual, models.FolderTitleLabel, "Parent/Folder")
+	require.NoError(t, err)
+	labels := data.Labels{"host": "1"}
+	manager := &fakeStateManager{
+		stateCallback: func(now time.Time) []state.StateTransition {
+			return []state.StateTransition{{State: &state.State{CacheID: labels.Fingerprint(), Labels: labels, State: eval.Alerting}}}
+		},
+	}
+	amConfig := apimodels.GettableUserConfig{}
+	amConfig.AlertmanagerConfig.Route = &apimodels.Route{Receiver: "default"}
+	engine := &Engine{
+		createStateManager: func() stateManager {
+			return manager
+		},
+		amConfigProvider: &fakeAlertmanagerConfigProvider{config: amConfig},
+	}
+
+	gen := models.RuleGen
+	rule := gen.With(gen.WithInterval(10 * time.Second)).GenerateRef()
+	from := time.Unix(0, 0)
+	frame, err := engine.Test(context.Background(), nil, rule, "", from, from.Add(time.Minute))
+	require.NoError(t, err)
+
+	require.NotNil(t, frame.Meta)
+	timeline, ok := frame.Meta.Custom.(NotificationTimeline)
+	require.True(t, ok)
+	require.Len(t, timeline.ContactPoints, 1)
+	require.Equal(t, "default", timeline.ContactPoints[0].Receiver)
+	require.Len(t, timeline.ContactPoints[0].Notifications, 1)
+	notification := timeline.ContactPoints[0].Notifications[0]
+	require.Equal(t, from.Add(30*time.Second), notification.Time)
+	require.Equal(t, rule.Title, notification.Firing[0]["alertname"])
+	require.Equal(t, rule.UID, notification.Firing[0][alertingModels.RuleUIDLabel])
+
+	t.Run("policies can match on the folder of the rule", func(t *testing.T) {
+		amConfig := apimodels.GettableUserConfig{}
+		amConfig.AlertmanagerConfig.Route = &apimodels.Route{
+			Receiver: "default",
+			Routes: []*apimodels.Route{
+				{Receiver: "folder", ObjectMatchers: apimodels.ObjectMatchers{folderMatcher}},
+			},
+		}
+		engine := &Engine{
+			createStateManager: func() stateManager {
+				return manager
+			},
+			amConfigProvider: &fakeAlertmanagerConfigProvider{config: amConfig},
+		}
+
+		frame, err := engine.Test(context.Background(), nil, rule, "Parent/Folder", from, from.Add(time.Minute))
+		require.NoError(t, err)
+		timeline := frame.Meta.Custom.(NotificationTimeline)
+		require.Len(t, timeline.ContactPoints, 1)
+		require.Equal(t, "folder", timeline.ContactPoints[0].Receiver)
+		require.Equal(t, "Parent/Folder", timeline.ContactPoints[0].Notifications[0].Firing[0][models.FolderTitleLabel])
+
+		t.Run("unless the label is disabled", func(t *testing.T) {
+			engine.disableGrafanaFolder = true
+
+			frame, err := engine.Test(context.Background(), nil, rule, "Parent/Folder", from, from.Add(time.Minute))
+			require.NoError(t, err)
+			timeline := frame.Meta.Custom.(NotificationTimeline)
+			require.Len(t, timeline.ContactPoints, 1)
+			require.Equal(t, "default", timeline.ContactPoints[0].Receiver)
+		})
+	})
+
+	t.Run("without an Alertmanager configuration there is no timeline", func(t *testing.T) {
+		engine.amConfigProvider = &fakeAlertmanagerConfigProvider{err: fmt.Errorf("failed to get latest configuration: %w", store.ErrNoAlertmanagerConfiguration)}
+
+		frame, err := engine.Test(context.Background(), nil, rule, "", from, from.Add(time.Minute))
+		require.NoError(t, err)
+		require.NotNil(t, frame)
+		if frame.Meta != nil {
+			require.Nil(t, frame.Meta.Custom)
+		}
+	})
+}
+
+type fakeAlertmanagerConfigProvider struct {
+	config apimodels.GettableUserConfig
+	err    error
+}
+
+func (f *fakeAlertmanagerConfigProvider) GetAlertmanagerConfiguration(_ context.Context, _ int64, _ bool) (apimodels.GettableUserConfig, error) {
+	return f.config, f.err
+}
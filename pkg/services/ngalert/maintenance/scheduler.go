@@ -0,0 +1,254 @@
+package maintenance
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/go-openapi/strfmt"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// DefaultSyncInterval is the interval at which the scheduler looks for new occurrences of maintenance windows.
+const DefaultSyncInterval = time.Minute
+
+// Store persists maintenance windows and their latest occurrences.
+type Store interface {
+	ListAllMaintenanceWindows(ctx context.Context) ([]*models.MaintenanceWindow, error)
+	ClaimMaintenanceWindowOccurrence(ctx context.Context, id int64, previous, occurrence int64) (bool, error)
+	SetMaintenanceWindowSilence(ctx context.Context, id int64, silenceID string) error
+}
+
+// SilenceService creates and expires the silences of the occurrences of maintenance windows.
+type SilenceService interface {
+	CreateSilence(ctx context.Context, orgID int64, ps models.Silence) (string, error)
+	DeleteSilence(ctx context.Context, orgID int64, silenceID string) error
+}
+
+// Scheduler creates a silence for each occurrence of the maintenance windows of all organizations. It also keeps
+// the occurrences that are in progress, so that the state manager can tell which maintenance window silences an alert.
+//
+// The occurrences are claimed in the database before their silence is created, so that when several Grafana
+// instances run the scheduler, a single silence is created for each occurrence.
+type Scheduler struct {
+	store    Store
+	silences SilenceService
+	interval time.Duration
+	clock    clock.Clock
+	log      log.Logger
+
+	mtx    sync.RWMutex
+	active map[int64][]occurrence
+
+	// silenceIDs are the silences of the maintenance windows seen in the latest sync, by ID of the window. They are
+	// used to expire the silence of a window once it is deleted.
+	silenceIDs map[int64]silenceRef
+}
+
+type occurrence struct {
+	title    string
+	matchers labels.Matchers
+	start    time.Time
+	end      time.Time
+}
+
+type silenceRef struct {
+	orgID int64
+	id    string
+}
+
+func NewScheduler(store Store, silences SilenceService, interval time.Duration, clk clock.Clock) *Scheduler {
+	if interval <= 0 {
+		interval = DefaultSyncInterval
+	}
+	return &Scheduler{
+		store:      store,
+		silences:   silences,
+		interval:   interval,
+		clock:      clk,
+		log:        log.New("ngalert.maintenance"),
+		active:     make(map[int64][]occurrence),
+		silenceIDs: make(map[int64]silenceRef),
+	}
+}
+
+// Run syncs the maintenance windows at every interval until the context is cancelled.
+func (s *Scheduler) Run(ctx context.Context) error {
+	ticker := s.clock.Ticker(s.interval)
+	defer ticker.Stop()
+	for {
+		if err := s.sync(ctx); err != nil {
+			s.log.Error("Failed to sync maintenance windows", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// MaintenanceWindow returns the title of a maintenance window with an occurrence in progress at t whose matchers
+// match the labels.
+func (s *Scheduler) MaintenanceWindow(orgID int64, lbls data.Labels, t time.Time) (string, bool) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	occurrences := s.active[orgID]
+	if len(occurrences) == 0 {
+		return "", false
+	}
+	set := make(model.LabelSet, len(lbls))
+	for k, v := range lbls {
+		set[model.LabelName(k)] = model.LabelValue(v)
+	}
+	for _, o := range occurrences {
+		if t.Before(o.start) || !t.Before(o.end) {
+			continue
+		}
+		if o.matchers.Matches(set) {
+			return o.title, true
+		}
+	}
+	return "", false
+}
+
+// sync creates the silences of the occurrences that start before the next sync, and expires the silences of
+// maintenance windows that were deleted or changed.
+func (s *Scheduler) sync(ctx context.Context) error {
+	now := s.clock.Now()
+	horizon := now.Add(s.interval)
+	windows, err := s.store.ListAllMaintenanceWindows(ctx)
+	if err != nil {
+		return err
+	}
+
+	active := make(map[int64][]occurrence)
+	silenceIDs := make(map[int64]silenceRef, len(windows))
+	for _, w := range windows {
+		logger := s.log.New("org_id", w.OrgID, "maintenance_window", w.UID)
+		matchers, err := w.ParseMatchers()
+		if err != nil {
+			logger.Error("Invalid maintenance window", "error", err)
+			continue
+		}
+		start, end, err := w.NextOccurrence(now)
+		if err != nil {
+			logger.Error("Invalid maintenance window", "error", err)
+			continue
+		}
+		if start.After(horizon) {
+			// The latest occurrence is reset when the window is changed. Its silence no longer reflects the window.
+			if w.LastOccurrence == 0 && w.SilenceID != "" {
+				s.expireSilence(ctx, logger, w)
+			}
+		} else {
+			if start.Unix() > w.LastOccurrence {
+				s.silenceOccurrence(ctx, logger, w, matchers, start, end)
+			}
+			active[w.OrgID] = append(active[w.OrgID], occurrence{
+				title:    w.Title,
+				matchers: matchers,
+				start:    start,
+				end:      end,
+			})
+		}
+		silenceIDs[w.ID] = silenceRef{orgID: w.OrgID, id: w.SilenceID}
+	}
+
+	for id, ref := range s.silenceIDs {
+		if _, ok := silenceIDs[id]; ok || ref.id == "" {
+			continue
+		}
+		s.deleteSilence(ctx, s.log.New("org_id", ref.orgID), ref.orgID, ref.id)
+	}
+	s.silenceIDs = silenceIDs
+
+	s.mtx.Lock()
+	s.active = active
+	s.mtx.Unlock()
+	return nil
+}
+
+func (s *Scheduler) silenceOccurrence(ctx context.Context, logger log.Logger, w *models.MaintenanceWindow, matchers labels.Matchers, start, end time.Time) {
+	claimed, err := s.store.ClaimMaintenanceWindowOccurrence(ctx, w.ID, w.LastOccurrence, start.Unix())
+	if err != nil {
+		logger.Error("Failed to claim occurrence of maintenance window", "error", err)
+		return
+	}
+	if !claimed {
+		logger.Debug("Occurrence of maintenance window is silenced by another instance", "starts_at", start)
+		return
+	}
+
+	if w.SilenceID != "" {
+		s.deleteSilence(ctx, logger, w.OrgID, w.SilenceID)
+	}
+	silenceID, err := s.silences.CreateSilence(ctx, w.OrgID, newSilence(w, matchers, start, end))
+	if err != nil {
+		logger.Error("Failed to create silence for occurrence of maintenance window", "error", err, "starts_at", start)
+		// Release the occurrence so that the silence is created by the next sync.
+		if _, err := s.store.ClaimMaintenanceWindowOccurrence(ctx, w.ID, start.Unix(), w.LastOccurrence); err != nil {
+			logger.Error("Failed to release occurrence of maintenance window", "error", err)
+		}
+		return
+	}
+	if err := s.store.SetMaintenanceWindowSilence(ctx, w.ID, silenceID); err != nil {
+		logger.Error("Failed to save silence of maintenance window", "error", err, "silence_id", silenceID)
+	}
+	w.LastOccurrence = start.Unix()
+	w.SilenceID = silenceID
+	logger.Info("Created silence for occurrence of maintenance window", "silence_id", silenceID, "starts_at", start, "ends_at", end)
+}
+
+func (s *Scheduler) expireSilence(ctx context.Context, logger log.Logger, w *models.MaintenanceWindow) {
+	if err := s.store.SetMaintenanceWindowSilence(ctx, w.ID, ""); err != nil {
+		logger.Error("Failed to save silence of maintenance window", "error", err)
+		return
+	}
+	s.deleteSilence(ctx, logger, w.OrgID, w.SilenceID)
+	w.SilenceID = ""
+}
+
+func (s *Scheduler) deleteSilence(ctx context.Context, logger log.Logger, orgID int64, silenceID string) {
+	// The silence could have been expired by a user or by another instance.
+	if err := s.silences.DeleteSilence(ctx, orgID, silenceID); err != nil {
+		logger.Debug("Failed to expire silence of maintenance window", "error", err, "silence_id", silenceID)
+		return
+	}
+	logger.Info("Expired silence of maintenance window", "silence_id", silenceID)
+}
+
+func newSilence(w *models.MaintenanceWindow, matchers labels.Matchers, start, end time.Time) models.Silence {
+	comment := fmt.Sprintf("Silenced by maintenance window %s", w.Title)
+	if w.Comment != "" {
+		comment = fmt.Sprintf("%s: %s", comment, w.Comment)
+	}
+	silence := models.Silence{}
+	silence.Silence = amv2.Silence{
+		Comment:   util.Pointer(comment),
+		CreatedBy: util.Pointer(models.MaintenanceWindowSilenceAuthor),
+		StartsAt:  util.Pointer(strfmt.DateTime(start)),
+		EndsAt:    util.Pointer(strfmt.DateTime(end)),
+		Matchers:  make(amv2.Matchers, 0, len(matchers)),
+	}
+	for _, m := range matchers {
+		isEqual := m.Type == labels.MatchEqual || m.Type == labels.MatchRegexp
+		isRegex := m.Type == labels.MatchRegexp || m.Type == labels.MatchNotRegexp
+		silence.Matchers = append(silence.Matchers, &amv2.Matcher{
+			Name:    util.Pointer(m.Name),
+			Value:   util.Pointer(m.Value),
+			IsEqual: &isEqual,
+			IsRegex: &isRegex,
+		})
+	}
+	return silence
+}
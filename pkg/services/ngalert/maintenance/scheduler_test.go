@@ -0,0 +1,217 @@
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestSchedulerSync(t *testing.T) {
+	ctx := context.Background()
+	// Saturday 2024-06-01 01:30 UTC.
+	start := time.Date(2024, 6, 1, 1, 30, 0, 0, time.UTC)
+	newWindow := func() *models.MaintenanceWindow {
+		return &models.MaintenanceWindow{
+			ID:       1,
+			UID:      "db",
+			OrgID:    1,
+			Title:    "Database upgrades",
+			Schedule: "0 2 * * 6",
+			Duration: 2 * time.Hour,
+			Matchers: []string{`service="db"`, `severity=~"warning|critical"`},
+		}
+	}
+
+	t.Run("creates a silence when an occurrence starts before the next sync", func(t *testing.T) {
+		clk := clock.NewMock()
+		clk.Set(start)
+		store := &fakeStore{windows: []*models.MaintenanceWindow{newWindow()}}
+		silences := &fakeSilences{}
+		sut := NewScheduler(store, silences, time.Hour, clk)
+
+		require.NoError(t, sut.sync(ctx))
+		require.Len(t, silences.created, 1)
+		silence := silences.created[0]
+		require.Equal(t, models.MaintenanceWindowSilenceAuthor, *silence.CreatedBy)
+		require.Equal(t, "Silenced by maintenance window Database upgrades", *silence.Comment)
+		require.Equal(t, time.Date(2024, 6, 1, 2, 0, 0, 0, time.UTC), time.Time(*silence.StartsAt))
+		require.Equal(t, time.Date(2024, 6, 1, 4, 0, 0, 0, time.UTC), time.Time(*silence.EndsAt))
+		require.Len(t, silence.Matchers, 2)
+		require.True(t, *silence.Matchers[1].IsRegex)
+		require.True(t, *silence.Matchers[1].IsEqual)
+
+		require.Equal(t, time.Date(2024, 6, 1, 2, 0, 0, 0, time.UTC).Unix(), store.windows[0].LastOccurrence)
+		require.Equal(t, "silence-1", store.windows[0].SilenceID)
+
+		t.Run("and does not create it again", func(t *testing.T) {
+			clk.Add(time.Hour)
+			require.NoError(t, sut.sync(ctx))
+			require.Len(t, silences.created, 1)
+		})
+	})
+
+	t.Run("does not create a silence for an occurrence after the next sync", func(t *testing.T) {
+		clk := clock.NewMock()
+		clk.Set(start)
+		store := &fakeStore{windows: []*models.MaintenanceWindow{newWindow()}}
+		silences := &fakeSilences{}
+		sut := NewScheduler(store, silences, time.Minute, clk)
+
+		require.NoError(t, sut.sync(ctx))
+		require.Empty(t, silences.created)
+	})
+
+	t.Run("does not create a silence for an occurrence claimed by another instance", func(t *testing.T) {
+		clk := clock.NewMock()
+		clk.Set(start)
+		store := &fakeStore{windows: []*models.MaintenanceWindow{newWindow()}, claimed: true}
+		silences := &fakeSilences{}
+		sut := NewScheduler(store, silences, time.Hour, clk)
+
+		require.NoError(t, sut.sync(ctx))
+		require.Empty(t, silences.created)
+	})
+
+	t.Run("releases the occurrence if the silence cannot be created", func(t *testing.T) {
+		clk := clock.NewMock()
+		clk.Set(start)
+		store := &fakeStore{windows: []*models.MaintenanceWindow{newWindow()}}
+		silences := &fakeSilences{err: errors.New("failed")}
+		sut := NewScheduler(store, silences, time.Hour, clk)
+
+		require.NoError(t, sut.sync(ctx))
+		require.Zero(t, store.windows[0].LastOccurrence)
+	})
+
+	t.Run("replaces the silence of an updated window", func(t *testing.T) {
+		clk := clock.NewMock()
+		clk.Set(start.Add(time.Hour))
+		w := newWindow()
+		w.SilenceID = "previous"
+		store := &fakeStore{windows: []*models.MaintenanceWindow{w}}
+		silences := &fakeSilences{}
+		sut := NewScheduler(store, silences, time.Minute, clk)
+
+		require.NoError(t, sut.sync(ctx))
+		require.Equal(t, []string{"previous"}, silences.deleted)
+		require.Len(t, silences.created, 1)
+	})
+
+	t.Run("expires the silence of an updated window without an occurrence in progress", func(t *testing.T) {
+		clk := clock.NewMock()
+		clk.Set(start.Add(-time.Hour))
+		w := newWindow()
+		w.SilenceID = "previous"
+		store := &fakeStore{windows: []*models.MaintenanceWindow{w}}
+		silences := &fakeSilences{}
+		sut := NewScheduler(store, silences, time.Minute, clk)
+
+		require.NoError(t, sut.sync(ctx))
+		require.Equal(t, []string{"previous"}, silences.deleted)
+		require.Empty(t, store.windows[0].SilenceID)
+	})
+
+	t.Run("expires the silence of a deleted window", func(t *testing.T) {
+		clk := clock.NewMock()
+		clk.Set(start)
+		store := &fakeStore{windows: []*models.MaintenanceWindow{newWindow()}}
+		silences := &fakeSilences{}
+		sut := NewScheduler(store, silences, time.Hour, clk)
+
+		require.NoError(t, sut.sync(ctx))
+		store.windows = nil
+		require.NoError(t, sut.sync(ctx))
+		require.Equal(t, []string{"silence-1"}, silences.deleted)
+	})
+}
+
+func TestSchedulerMaintenanceWindow(t *testing.T) {
+	clk := clock.NewMock()
+	clk.Set(time.Date(2024, 6, 1, 2, 30, 0, 0, time.UTC))
+	store := &fakeStore{windows: []*models.MaintenanceWindow{{
+		ID:       1,
+		UID:      "db",
+		OrgID:    1,
+		Title:    "Database upgrades",
+		Schedule: "0 2 * * 6",
+		Duration: time.Hour,
+		Matchers: []string{`service="db"`},
+	}}}
+	sut := NewScheduler(store, &fakeSilences{}, time.Minute, clk)
+	require.NoError(t, sut.sync(context.Background()))
+
+	title, ok := sut.MaintenanceWindow(1, data.Labels{"service": "db", "severity": "critical"}, clk.Now())
+	require.True(t, ok)
+	require.Equal(t, "Database upgrades", title)
+
+	_, ok = sut.MaintenanceWindow(1, data.Labels{"service": "web"}, clk.Now())
+	require.False(t, ok)
+	_, ok = sut.MaintenanceWindow(2, data.Labels{"service": "db"}, clk.Now())
+	require.False(t, ok)
+	_, ok = sut.MaintenanceWindow(1, data.Labels{"service": "db"}, clk.Now().Add(time.Hour))
+	require.False(t, ok)
+}
+
+type fakeStore struct {
+	windows []*models.MaintenanceWindow
+	// claimed simulates another instance that claims all occurrences first.
+	claimed bool
+}
+
+func (f *fakeStore) ListAllMaintenanceWindows(_ context.Context) ([]*models.MaintenanceWindow, error) {
+	result := make([]*models.MaintenanceWindow, 0, len(f.windows))
+	for _, w := range f.windows {
+		c := *w
+		result = append(result, &c)
+	}
+	return result, nil
+}
+
+func (f *fakeStore) ClaimMaintenanceWindowOccurrence(_ context.Context, id int64, previous, occurrence int64) (bool, error) {
+	if f.claimed {
+		return false, nil
+	}
+	for _, w := range f.windows {
+		if w.ID == id && w.LastOccurrence == previous {
+			w.LastOccurrence = occurrence
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakeStore) SetMaintenanceWindowSilence(_ context.Context, id int64, silenceID string) error {
+	for _, w := range f.windows {
+		if w.ID == id {
+			w.SilenceID = silenceID
+		}
+	}
+	return nil
+}
+
+type fakeSilences struct {
+	created []models.Silence
+	deleted []string
+	err     error
+}
+
+func (f *fakeSilences) CreateSilence(_ context.Context, _ int64, ps models.Silence) (string, error) {
+	if f.err != nil {
+		return "", f.err
+	}
+	f.created = append(f.created, ps)
+	return fmt.Sprintf("silence-%d", len(f.created)), nil
+}
+
+func (f *fakeSilences) DeleteSilence(_ context.Context, _ int64, silenceID string) error {
+	f.deleted = append(f.deleted, silenceID)
+	return nil
+}
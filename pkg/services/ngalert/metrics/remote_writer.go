@@ -8,6 +8,7 @@ import (
 type RemoteWriter struct {
 	WritesTotal   *prometheus.CounterVec
 	WriteDuration *prometheus.HistogramVec
+	WriteRetries  *prometheus.CounterVec
 }
 
 func NewRemoteWriterMetrics(r prometheus.Registerer) *RemoteWriter {
@@ -17,7 +18,7 @@ func NewRemoteWriterMetrics(r prometheus.Registerer) *RemoteWriter {
 			Subsystem: Subsystem,
 			Name:      "remote_writer_writes_total",
 			Help:      "The total number of remote writes attempted.",
-		}, []string{"org", "backend", "status_code"}),
+		}, []string{"org", "backend", "target", "status_code"}),
 		WriteDuration: promauto.With(r).NewHistogramVec(
 			prometheus.HistogramOpts{
 				Namespace: Namespace,
@@ -25,6 +26,12 @@ func NewRemoteWriterMetrics(r prometheus.Registerer) *RemoteWriter {
 				Name:      "remote_writer_write_duration_seconds",
 				Help:      "Histogram of remote write durations.",
 				Buckets:   prometheus.DefBuckets,
-			}, []string{"org", "backend"}),
+			}, []string{"org", "backend", "target"}),
+		WriteRetries: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      "remote_writer_write_retries_total",
+			Help:      "The total number of remote writes that were retried after a failure.",
+		}, []string{"org", "backend", "target"}),
 	}
 }
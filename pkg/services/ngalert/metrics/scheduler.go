@@ -31,6 +31,8 @@ type Scheduler struct {
 	UpdateSchedulableAlertRulesDuration prometheus.Histogram
 	Ticker                              *ticker.Metrics
 	EvaluationMissed                    *prometheus.CounterVec
+	EvalThrottleDuration                *prometheus.HistogramVec
+	ThrottledEvaluations                *prometheus.GaugeVec
 }
 
 func NewSchedulerMetrics(r prometheus.Registerer) *Scheduler {
@@ -182,5 +184,24 @@ func NewSchedulerMetrics(r prometheus.Registerer) *Scheduler {
 			},
 			[]string{"org", "name"},
 		),
+		EvalThrottleDuration: promauto.With(r).NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "rule_evaluation_throttle_duration_seconds",
+				Help:      "The time a rule evaluation waited for the evaluation limits of its folder and data sources.",
+				Buckets:   []float64{0, .01, .1, .5, 1, 5, 10, 15, 30, 60, 120, 180, 240, 300},
+			},
+			[]string{"org"},
+		),
+		ThrottledEvaluations: promauto.With(r).NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "rule_evaluations_throttled",
+				Help:      "The number of rule evaluations waiting for the evaluation limits of their folder and data sources.",
+			},
+			[]string{"org"},
+		),
 	}
 }
@@ -30,6 +30,8 @@ import (
 var (
 	// ErrAlertRuleNotFound is an error for an unknown alert rule.
 	ErrAlertRuleNotFound = fmt.Errorf("could not find alert rule")
+	// ErrAlertRuleVersionNotFound is an error for an unknown version of an alert rule.
+	ErrAlertRuleVersionNotFound = errors.New("could not find alert rule version")
 	// ErrAlertRuleFailedGenerateUniqueUID is an error for failure to generate alert rule UID
 	ErrAlertRuleFailedGenerateUniqueUID = errors.New("failed to generate alert rule UID")
 	// ErrCannotEditNamespace is an error returned if the user does not have permissions to edit the namespace
@@ -132,6 +134,21 @@ const (
 	// StateReasonAnnotation is the name of the annotation that explains the difference between evaluation state and alert state (i.e. changing state when NoData or Error).
 	StateReasonAnnotation = GrafanaReservedLabelPrefix + "state_reason"
 
+	// AcknowledgedLabel is the label of the copy of the alert that is sent for an acknowledged alert instance. Its only
+	// value is `true`. Notification policies can match it to route acknowledged alerts, for example to stop repeated
+	// notifications. The alert itself keeps its labels and is silenced, so that it is not resolved when it is acknowledged.
+	AcknowledgedLabel = GrafanaReservedLabelPrefix + "acknowledged"
+	// AcknowledgedByAnnotation is the name of the annotation with the login of the user who acknowledged the alert.
+	AcknowledgedByAnnotation = GrafanaReservedLabelPrefix + "acknowledged_by"
+	// AcknowledgedAtAnnotation is the name of the annotation with the time the alert was acknowledged at.
+	AcknowledgedAtAnnotation = GrafanaReservedLabelPrefix + "acknowledged_at"
+	// AcknowledgementExpiresAtAnnotation is the name of the annotation with the time the acknowledgement expires at.
+	AcknowledgementExpiresAtAnnotation = GrafanaReservedLabelPrefix + "acknowledgement_expires_at"
+	// AcknowledgementCommentAnnotation is the name of the annotation with the comment of the acknowledgement or assignment.
+	AcknowledgementCommentAnnotation = GrafanaReservedLabelPrefix + "acknowledgement_comment"
+	// AssigneeAnnotation is the name of the annotation with the login of the user the alert is assigned to.
+	AssigneeAnnotation = GrafanaReservedLabelPrefix + "assignee"
+
 	// MigratedLabelPrefix is a label prefix for all labels created during legacy migration.
 	MigratedLabelPrefix = "__legacy_"
 	// MigratedUseLegacyChannelsLabel is created during legacy migration to route to separate nested policies for migrated channels.
@@ -164,6 +181,8 @@ const (
 	StateReasonUpdated       = "Updated"
 	StateReasonRuleDeleted   = "RuleDeleted"
 	StateReasonKeepLast      = "KeepLast"
+	StateReasonKeepFiring    = "KeepFiring"
+	StateReasonInhibited     = "Inhibited"
 )
 
 func ConcatReasons(reasons ...string) string {
@@ -265,10 +284,18 @@ type AlertRule struct {
 	// ideally this field should have been apimodels.ApiDuration
 	// but this is currently not possible because of circular dependencies
 	For                  time.Duration
+	KeepFiringFor        time.Duration
 	Annotations          map[string]string
 	Labels               map[string]string
 	IsPaused             bool
 	NotificationSettings []NotificationSettings `xorm:"notification_settings"` // we use slice to workaround xorm mapping that does not serialize a struct to JSON unless it's a slice
+	// DependsOn are the UIDs of the rules of the same organization this rule depends on.
+	// Notifications of the rule are inhibited while any of them is firing.
+	DependsOn []string `xorm:"depends_on"`
+	// UpdatedBy is the login of the user that last changed the rule. It is empty if the rule was changed by Grafana.
+	UpdatedBy string `xorm:"updated_by"`
+	// Message describes the last change of the rule. It is stored only in the version of the rule that the change creates.
+	Message string `xorm:"-"`
 }
 
 // Namespaced describes a class of resources that are stored in a specific namespace.
@@ -562,6 +589,10 @@ func (alertRule *AlertRule) ValidateAlertRule(cfg setting.UnifiedAlertingSetting
 		return fmt.Errorf("%w: field `for` cannot be negative", ErrAlertRuleFailedValidation)
 	}
 
+	if alertRule.KeepFiringFor < 0 {
+		return fmt.Errorf("%w: field `keep_firing_for` cannot be negative", ErrAlertRuleFailedValidation)
+	}
+
 	if len(alertRule.Labels) > 0 {
 		for label := range alertRule.Labels {
 			if _, ok := LabelsUserCannotSpecify[label]; ok {
@@ -570,6 +601,10 @@ func (alertRule *AlertRule) ValidateAlertRule(cfg setting.UnifiedAlertingSetting
 		}
 	}
 
+	if err := validateDependsOn(alertRule); err != nil {
+		return err
+	}
+
 	if len(alertRule.NotificationSettings) > 0 {
 		if len(alertRule.NotificationSettings) != 1 {
 			return fmt.Errorf("%w: only one notification settings entry is allowed", ErrAlertRuleFailedValidation)
@@ -581,6 +616,23 @@ func (alertRule *AlertRule) ValidateAlertRule(cfg setting.UnifiedAlertingSetting
 	return nil
 }
 
+func validateDependsOn(rule *AlertRule) error {
+	seen := make(map[string]struct{}, len(rule.DependsOn))
+	for _, uid := range rule.DependsOn {
+		if uid == "" {
+			return fmt.Errorf("%w: field `depends_on` cannot contain an empty rule UID", ErrAlertRuleFailedValidation)
+		}
+		if uid == rule.UID {
+			return fmt.Errorf("%w: rule cannot depend on itself", ErrAlertRuleFailedValidation)
+		}
+		if _, ok := seen[uid]; ok {
+			return fmt.Errorf("%w: rule %s is listed more than once in field `depends_on`", ErrAlertRuleFailedValidation, uid)
+		}
+		seen[uid] = struct{}{}
+	}
+	return nil
+}
+
 func validateAlertRuleFields(rule *AlertRule) error {
 	if _, err := ErrStateFromString(string(rule.ExecErrState)); err != nil {
 		return err
@@ -653,10 +705,48 @@ type AlertRuleVersion struct {
 	// ideally this field should have been apimodels.ApiDuration
 	// but this is currently not possible because of circular dependencies
 	For                  time.Duration
+	KeepFiringFor        time.Duration
 	Annotations          map[string]string
 	Labels               map[string]string
 	IsPaused             bool
 	NotificationSettings []NotificationSettings `xorm:"notification_settings"` // we use slice to workaround xorm mapping that does not serialize a struct to JSON unless it's a slice
+	// DependsOn are the UIDs of the rules of the same organization this rule depends on.
+	// Notifications of the rule are inhibited while any of them is firing.
+	DependsOn []string `xorm:"depends_on"`
+	UpdatedBy string   `xorm:"updated_by"`
+	Message   string
+	// HasPause tells whether IsPaused is the paused state of the rule in the version. Versions created before the
+	// paused state was recorded have IsPaused false whether or not the rule was paused.
+	HasPause bool `xorm:"has_pause"`
+}
+
+// ToAlertRule returns the alert rule as it was in the version. The ID of the rule is not stored in versions and is not set.
+func (v *AlertRuleVersion) ToAlertRule() *AlertRule {
+	return &AlertRule{
+		OrgID:                v.RuleOrgID,
+		Title:                v.Title,
+		Condition:            v.Condition,
+		Data:                 v.Data,
+		Updated:              v.Created,
+		IntervalSeconds:      v.IntervalSeconds,
+		Version:              v.Version,
+		UID:                  v.RuleUID,
+		NamespaceUID:         v.RuleNamespaceUID,
+		RuleGroup:            v.RuleGroup,
+		RuleGroupIndex:       v.RuleGroupIndex,
+		Record:               v.Record,
+		NoDataState:          v.NoDataState,
+		ExecErrState:         v.ExecErrState,
+		For:                  v.For,
+		KeepFiringFor:        v.KeepFiringFor,
+		Annotations:          v.Annotations,
+		Labels:               v.Labels,
+		IsPaused:             v.IsPaused,
+		NotificationSettings: v.NotificationSettings,
+		DependsOn:            v.DependsOn,
+		UpdatedBy:            v.UpdatedBy,
+		Message:              v.Message,
+	}
 }
 
 // GetAlertRuleByUIDQuery is the query for retrieving/deleting an alert rule by UID and organisation ID.
@@ -795,6 +885,9 @@ func PatchPartialAlertRule(existingRule *AlertRule, ruleToPatch *AlertRuleWithOp
 	if ruleToPatch.For == -1 {
 		ruleToPatch.For = existingRule.For
 	}
+	if ruleToPatch.KeepFiringFor == -1 {
+		ruleToPatch.KeepFiringFor = existingRule.KeepFiringFor
+	}
 	if !ruleToPatch.HasPause {
 		ruleToPatch.IsPaused = existingRule.IsPaused
 	}
@@ -862,6 +955,9 @@ type Record struct {
 	Metric string
 	// From contains a query RefID, indicating which expression node is the output of the recording rule.
 	From string
+	// TargetDatasourceUID is the data source to write the recorded metric to.
+	// If empty, the globally configured recording rules target is used.
+	TargetDatasourceUID string
 }
 
 func (r *Record) Fingerprint() data.Fingerprint {
@@ -876,6 +972,7 @@ func (r *Record) Fingerprint() data.Fingerprint {
 
 	writeString(r.Metric)
 	writeString(r.From)
+	writeString(r.TargetDatasourceUID)
 	return data.Fingerprint(h.Sum64())
 }
 
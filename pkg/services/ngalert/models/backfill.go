@@ -0,0 +1,67 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrBackfillNotFound is returned when the backfill does not exist.
+	ErrBackfillNotFound = errors.New("backfill not found")
+	// ErrBackfillConflict is returned when the time range of a backfill overlaps a running backfill of the same rule.
+	ErrBackfillConflict = errors.New("the time range overlaps a running backfill of the rule")
+	// ErrBackfillStateChanged is returned when the backfill is no longer in the state that an update expects,
+	// for example because it was cancelled on another Grafana instance.
+	ErrBackfillStateChanged = errors.New("the state of the backfill has changed")
+)
+
+// BackfillState is the state of a recording rule backfill.
+type BackfillState string
+
+const (
+	// BackfillStateRunning means that the backfill is in progress, or was in progress when Grafana stopped.
+	BackfillStateRunning BackfillState = "running"
+	// BackfillStateCompleted means that all evaluations of the backfill have been written.
+	BackfillStateCompleted BackfillState = "completed"
+	// BackfillStateFailed means that an evaluation or a write failed. A failed backfill can be resumed.
+	BackfillStateFailed BackfillState = "failed"
+	// BackfillStateCancelled means that the backfill was cancelled by a user. A cancelled backfill can be resumed.
+	BackfillStateCancelled BackfillState = "cancelled"
+)
+
+// RecordingRuleBackfill is a job that evaluates a recording rule at its interval over a past time range,
+// and writes the results with the timestamps of the evaluations.
+type RecordingRuleBackfill struct {
+	ID      int64  `xorm:"pk autoincr 'id'"`
+	UID     string `xorm:"uid"`
+	OrgID   int64  `xorm:"org_id"`
+	RuleUID string `xorm:"rule_uid"`
+	// From is the time of the first evaluation.
+	From time.Time `xorm:"range_start"`
+	// To is the end of the time range. The last evaluation happens at or before it.
+	To time.Time `xorm:"range_end"`
+	// Next is the time of the next evaluation. All evaluations before it have been written.
+	Next    time.Time     `xorm:"next_evaluation"`
+	State   BackfillState `xorm:"state"`
+	Error   string        `xorm:"error_message"`
+	Created time.Time     `xorm:"created"`
+	Updated time.Time     `xorm:"updated"`
+	// Owner identifies the Grafana instance that runs the backfill.
+	Owner string `xorm:"owner"`
+	// Heartbeat is the last time the owner reported that it still runs the backfill.
+	Heartbeat time.Time `xorm:"heartbeat"`
+}
+
+func (RecordingRuleBackfill) TableName() string {
+	return "alert_rule_backfill"
+}
+
+// Resumable returns true if the backfill stopped before all evaluations were written.
+func (b RecordingRuleBackfill) Resumable() bool {
+	return (b.State == BackfillStateFailed || b.State == BackfillStateCancelled) && !b.Next.After(b.To)
+}
+
+// Overlaps returns true if the time ranges of the backfills overlap.
+func (b RecordingRuleBackfill) Overlaps(other RecordingRuleBackfill) bool {
+	return !b.From.After(other.To) && !other.From.After(b.To)
+}
@@ -0,0 +1,139 @@
+package models
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"time"
+)
+
+var (
+	// ErrEscalationChainNotFound is returned when the escalation chain does not exist.
+	ErrEscalationChainNotFound = errors.New("escalation chain not found")
+	// ErrAlertGroupEscalationNotFound is returned when the escalation of the alert group does not exist.
+	ErrAlertGroupEscalationNotFound = errors.New("alert group escalation not found")
+)
+
+// EscalationChain escalates the alert groups that notification policies route to its contact point. If such a group
+// is still firing and has not been acknowledged after the delay of the first step, the contact point of the step is
+// notified, and so on for the following steps.
+type EscalationChain struct {
+	ID    int64  `xorm:"pk autoincr 'id'"`
+	UID   string `xorm:"uid"`
+	OrgID int64  `xorm:"org_id"`
+	Title string `xorm:"title"`
+	// Receiver is the contact point of the notification policies whose alert groups are escalated.
+	Receiver string           `xorm:"receiver"`
+	Steps    []EscalationStep `xorm:"steps"`
+	Updated  time.Time        `xorm:"updated"`
+
+	Provenance Provenance `xorm:"-"`
+}
+
+// EscalationStep notifies a contact point once the delay has passed since the previous notification of the alert group.
+type EscalationStep struct {
+	Receiver string        `json:"receiver"`
+	Delay    time.Duration `json:"delay"`
+}
+
+func (EscalationChain) TableName() string {
+	return "alert_escalation_chain"
+}
+
+func (c *EscalationChain) ResourceType() string {
+	return "escalationChain"
+}
+
+func (c *EscalationChain) ResourceID() string {
+	return c.UID
+}
+
+// Receivers returns the names of all contact points of the escalation chain.
+func (c *EscalationChain) Receivers() []string {
+	result := make([]string, 0, len(c.Steps)+1)
+	result = append(result, c.Receiver)
+	for _, s := range c.Steps {
+		result = append(result, s.Receiver)
+	}
+	return result
+}
+
+// Validate checks that the escalation chain has a title, a contact point and at least one step with a positive delay.
+func (c *EscalationChain) Validate() error {
+	if c.Title == "" {
+		return errors.New("title must not be empty")
+	}
+	if c.Receiver == "" {
+		return errors.New("receiver must not be empty")
+	}
+	if len(c.Steps) == 0 {
+		return errors.New("at least one step is required")
+	}
+	for i, s := range c.Steps {
+		if s.Receiver == "" {
+			return fmt.Errorf("receiver of step %d must not be empty", i+1)
+		}
+		if s.Delay <= 0 {
+			return fmt.Errorf("delay of step %d must be positive", i+1)
+		}
+	}
+	return nil
+}
+
+// AlertGroupEscalation is the position of a firing alert group in its escalation chain, and whether the group was
+// acknowledged. It is kept in the database, so that escalations continue after restarts and are shared by all
+// Grafana instances.
+type AlertGroupEscalation struct {
+	ID    int64 `xorm:"pk autoincr 'id'"`
+	OrgID int64 `xorm:"org_id"`
+	// GroupKey identifies the alert group by its notification policy, its contact point and its group labels.
+	// See AlertGroupKey.
+	GroupKey    string            `xorm:"group_key"`
+	Receiver    string            `xorm:"receiver"`
+	GroupLabels map[string]string `xorm:"group_labels"`
+	// ChainUID is the escalation chain of the group. It is empty if the group was acknowledged but is not escalated.
+	ChainUID string `xorm:"chain_uid"`
+	// Step is the number of steps of the escalation chain whose contact points were notified.
+	Step int `xorm:"step"`
+	// StartedAt is when the group was first seen firing.
+	StartedAt time.Time `xorm:"started_at"`
+	// EscalatedAt is when the contact point of the latest step was notified, or StartedAt if none was.
+	EscalatedAt time.Time `xorm:"escalated_at"`
+	// LastSeenAt is when an instance last saw the group firing. The escalation is deleted once the group has not been
+	// seen for a while, so that it is not deleted while the alerts of the group are not sent yet, e.g. after a restart.
+	LastSeenAt time.Time `xorm:"last_seen_at"`
+
+	Acknowledged   bool      `xorm:"acknowledged"`
+	AcknowledgedBy string    `xorm:"acknowledged_by"`
+	AcknowledgedAt time.Time `xorm:"acknowledged_at"`
+	Comment        string    `xorm:"comment"`
+}
+
+func (AlertGroupEscalation) TableName() string {
+	return "alert_group_escalation"
+}
+
+// AlertGroupKey returns the key of the alert group of the notification policy with the given route ID, the contact
+// point and the group labels. Groups of different policies that have the same contact point and group labels have
+// different keys, like in the Alertmanager dispatcher.
+func AlertGroupKey(routeID, receiver string, groupLabels map[string]string) string {
+	names := make([]string, 0, len(groupLabels))
+	for name := range groupLabels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(routeID))
+	_, _ = h.Write([]byte{0xff})
+	_, _ = h.Write([]byte(receiver))
+	for _, name := range names {
+		_, _ = h.Write([]byte{0xff})
+		_, _ = h.Write([]byte(name))
+		_, _ = h.Write([]byte{0xfe})
+		_, _ = h.Write([]byte(groupLabels[name]))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEscalationChainValidate(t *testing.T) {
+	valid := EscalationChain{
+		Title:    "Ops",
+		Receiver: "team",
+		Steps: []EscalationStep{
+			{Receiver: "on-call", Delay: 5 * time.Minute},
+			{Receiver: "manager", Delay: 10 * time.Minute},
+		},
+	}
+	require.NoError(t, valid.Validate())
+	require.Equal(t, []string{"team", "on-call", "manager"}, valid.Receivers())
+
+	testCases := map[string]func(c *EscalationChain){
+		"empty title":         func(c *EscalationChain) { c.Title = "" },
+		"empty receiver":      func(c *EscalationChain) { c.Receiver = "" },
+		"no steps":            func(c *EscalationChain) { c.Steps = nil },
+		"empty step receiver": func(c *EscalationChain) { c.Steps = []EscalationStep{{Delay: time.Minute}} },
+		"zero step delay":     func(c *EscalationChain) { c.Steps = []EscalationStep{{Receiver: "on-call"}} },
+	}
+	for name, mutate := range testCases {
+		t.Run(name, func(t *testing.T) {
+			c := valid
+			mutate(&c)
+			require.Error(t, c.Validate())
+		})
+	}
+}
+
+func TestAlertGroupKey(t *testing.T) {
+	key := AlertGroupKey("{}", "team", map[string]string{"a": "1", "b": "2"})
+	require.Equal(t, key, AlertGroupKey("{}", "team", map[string]string{"b": "2", "a": "1"}))
+	require.NotEqual(t, key, AlertGroupKey("{}/{team=\"ops\"}/0", "team", map[string]string{"a": "1", "b": "2"}))
+	require.NotEqual(t, key, AlertGroupKey("{}", "other", map[string]string{"a": "1", "b": "2"}))
+	require.NotEqual(t, key, AlertGroupKey("{}", "team", map[string]string{"a": "12"}))
+}
@@ -1,10 +1,13 @@
 package models
 
 import (
+	"errors"
 	"fmt"
 	"time"
 )
 
+var ErrAlertInstanceAcknowledgementNotFound = errors.New("alert instance acknowledgement not found")
+
 // AlertInstance represents a single alert instance.
 type AlertInstance struct {
 	AlertInstanceKey  `xorm:"extends"`
@@ -16,6 +19,7 @@ type AlertInstance struct {
 	LastEvalTime      time.Time
 	LastSentAt        *time.Time
 	ResolvedAt        *time.Time
+	KeepFiringSince   *time.Time
 	ResultFingerprint string
 }
 
@@ -74,3 +78,58 @@ func ValidateAlertInstance(alertInstance AlertInstance) error {
 
 	return nil
 }
+
+// AlertInstanceAcknowledgement tells who handles an alert instance. It is stored by the key of the alert instance,
+// and is deleted once the alert instance is resolved.
+//
+// The alert of an acknowledged alert instance keeps its labels, so that it keeps its fingerprint in the Alertmanager
+// and is not resolved when it is acknowledged. Its notifications are suppressed by a silence that matches its labels,
+// and a copy of the alert with the label AcknowledgedLabel is sent instead, which notification policies can match.
+type AlertInstanceAcknowledgement struct {
+	AlertInstanceKey `xorm:"extends"`
+	Acknowledged     bool
+	AcknowledgedBy   string
+	AcknowledgedAt   time.Time
+	// ExpiresAt, if set, is the time after which the alert instance is no longer acknowledged.
+	ExpiresAt *time.Time
+	// Assignee is the login of the user the alert instance is assigned to.
+	Assignee  string
+	Comment   string
+	Updated   time.Time
+	UpdatedBy string
+	// SilenceID is the silence that suppresses the notifications of the alert instance while it is acknowledged.
+	SilenceID string `xorm:"silence_id"`
+}
+
+func (a AlertInstanceAcknowledgement) TableName() string {
+	return "alert_instance_acknowledgement"
+}
+
+// IsAcknowledged returns true if the alert instance is acknowledged at t.
+func (a AlertInstanceAcknowledgement) IsAcknowledged(t time.Time) bool {
+	return a.Acknowledged && (a.ExpiresAt == nil || t.Before(*a.ExpiresAt))
+}
+
+// IsEmpty returns true if the alert instance is neither acknowledged at t nor assigned to a user.
+func (a AlertInstanceAcknowledgement) IsEmpty(t time.Time) bool {
+	return !a.IsAcknowledged(t) && a.Assignee == ""
+}
+
+// Annotations returns the annotations that describe the acknowledgement and the assignee of the alert instance at t.
+func (a AlertInstanceAcknowledgement) Annotations(t time.Time) map[string]string {
+	result := make(map[string]string)
+	if a.IsAcknowledged(t) {
+		result[AcknowledgedByAnnotation] = a.AcknowledgedBy
+		result[AcknowledgedAtAnnotation] = a.AcknowledgedAt.UTC().Format(time.RFC3339)
+		if a.ExpiresAt != nil {
+			result[AcknowledgementExpiresAtAnnotation] = a.ExpiresAt.UTC().Format(time.RFC3339)
+		}
+	}
+	if a.Assignee != "" {
+		result[AssigneeAnnotation] = a.Assignee
+	}
+	if a.Comment != "" && len(result) > 0 {
+		result[AcknowledgementCommentAnnotation] = a.Comment
+	}
+	return result
+}
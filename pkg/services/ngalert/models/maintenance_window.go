@@ -0,0 +1,135 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/robfig/cron/v3"
+)
+
+var (
+	// ErrMaintenanceWindowNotFound is returned when the maintenance window does not exist.
+	ErrMaintenanceWindowNotFound = errors.New("maintenance window not found")
+)
+
+// MaintenanceWindowSilenceAuthor is the author of the silences that are created for the occurrences of maintenance windows.
+const MaintenanceWindowSilenceAuthor = "Grafana maintenance window"
+
+// StateReasonMaintenanceWindow returns the state reason of firing alerts that are silenced by the maintenance window
+// with the given title.
+func StateReasonMaintenanceWindow(title string) string {
+	return "Silenced by maintenance window " + title
+}
+
+// MaintenanceWindow silences the alerts that match its matchers during each occurrence of a recurring schedule.
+// An occurrence starts at every time of the schedule, in the time zone of the window, and lasts for the duration.
+// A silence is created for each occurrence.
+type MaintenanceWindow struct {
+	ID    int64  `xorm:"pk autoincr 'id'"`
+	UID   string `xorm:"uid"`
+	OrgID int64  `xorm:"org_id"`
+	Title string `xorm:"title"`
+	// Schedule is a cron expression with five fields, a descriptor such as @daily or @weekly, or an iCalendar
+	// recurrence rule such as RRULE:FREQ=MONTHLY;BYDAY=1SA;BYHOUR=2, that defines the start of the occurrences.
+	Schedule string        `xorm:"schedule"`
+	Duration time.Duration `xorm:"duration"`
+	// TimeZone is the IANA name of the time zone of the schedule. UTC is used if empty.
+	TimeZone string `xorm:"time_zone"`
+	// Matchers select the alerts that are silenced, in the same format as the matchers of silences, e.g. severity="warning".
+	Matchers []string  `xorm:"matchers"`
+	Comment  string    `xorm:"comment"`
+	Updated  time.Time `xorm:"updated"`
+	// LastOccurrence is the start, as Unix time in seconds, of the latest occurrence that a silence was created for.
+	LastOccurrence int64 `xorm:"last_occurrence"`
+	// SilenceID is the ID of the silence of the latest occurrence.
+	SilenceID string `xorm:"silence_id"`
+
+	Provenance Provenance `xorm:"-"`
+}
+
+func (MaintenanceWindow) TableName() string {
+	return "alert_maintenance_window"
+}
+
+func (w *MaintenanceWindow) ResourceType() string {
+	return "maintenanceWindow"
+}
+
+func (w *MaintenanceWindow) ResourceID() string {
+	return w.UID
+}
+
+// Validate checks that the schedule, the time zone and the matchers of the maintenance window can be parsed.
+func (w *MaintenanceWindow) Validate() error {
+	if w.Title == "" {
+		return errors.New("title must not be empty")
+	}
+	if w.Duration <= 0 {
+		return errors.New("duration must be positive")
+	}
+	if _, err := w.ParseSchedule(); err != nil {
+		return err
+	}
+	if len(w.Matchers) == 0 {
+		return errors.New("at least one matcher is required")
+	}
+	if _, err := w.ParseMatchers(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ParseSchedule returns the schedule of the start of the occurrences in the time zone of the maintenance window.
+func (w *MaintenanceWindow) ParseSchedule() (cron.Schedule, error) {
+	tz := w.TimeZone
+	if tz == "" {
+		tz = "UTC"
+	}
+	location, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time zone '%s': %w", tz, err)
+	}
+	if isRecurrenceRule(w.Schedule) {
+		rule, err := parseRecurrenceRule(w.Schedule, location)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule '%s': %w", w.Schedule, err)
+		}
+		return rule, nil
+	}
+	schedule, err := cron.ParseStandard(fmt.Sprintf("CRON_TZ=%s %s", tz, w.Schedule))
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule '%s': %w", w.Schedule, err)
+	}
+	return schedule, nil
+}
+
+// ParseMatchers returns the parsed matchers of the maintenance window.
+func (w *MaintenanceWindow) ParseMatchers() (labels.Matchers, error) {
+	result := make(labels.Matchers, 0, len(w.Matchers))
+	for _, s := range w.Matchers {
+		m, err := labels.ParseMatcher(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid matcher '%s': %w", s, err)
+		}
+		result = append(result, m)
+	}
+	return result, nil
+}
+
+// NextOccurrence returns the start and the end of the first occurrence that has not ended at t. The occurrence
+// is in progress if its start is not after t.
+func (w *MaintenanceWindow) NextOccurrence(t time.Time) (time.Time, time.Time, error) {
+	schedule, err := w.ParseSchedule()
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	// The schedule returns the first start strictly after the given time, so the occurrences that started after
+	// t - duration are the ones that have not ended at t.
+	start := schedule.Next(t.Add(-w.Duration))
+	if start.IsZero() {
+		return time.Time{}, time.Time{}, errors.New("schedule has no occurrences")
+	}
+	return start, start.Add(w.Duration), nil
+}
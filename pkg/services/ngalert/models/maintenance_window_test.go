@@ -0,0 +1,120 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaintenanceWindowNextOccurrence(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	w := MaintenanceWindow{
+		Title:    "Database upgrades",
+		Schedule: "0 2 * * 6",
+		Duration: 2 * time.Hour,
+		TimeZone: "Europe/Berlin",
+		Matchers: []string{`service="db"`},
+	}
+	require.NoError(t, w.Validate())
+
+	testCases := []struct {
+		name          string
+		at            time.Time
+		expectedStart time.Time
+	}{
+		{
+			name:          "before the occurrence",
+			at:            time.Date(2024, 6, 1, 1, 0, 0, 0, berlin),
+			expectedStart: time.Date(2024, 6, 1, 2, 0, 0, 0, berlin),
+		},
+		{
+			name:          "at the start of the occurrence",
+			at:            time.Date(2024, 6, 1, 2, 0, 0, 0, berlin),
+			expectedStart: time.Date(2024, 6, 1, 2, 0, 0, 0, berlin),
+		},
+		{
+			name:          "during the occurrence",
+			at:            time.Date(2024, 6, 1, 3, 59, 0, 0, berlin),
+			expectedStart: time.Date(2024, 6, 1, 2, 0, 0, 0, berlin),
+		},
+		{
+			name:          "at the end of the occurrence",
+			at:            time.Date(2024, 6, 1, 4, 0, 0, 0, berlin),
+			expectedStart: time.Date(2024, 6, 8, 2, 0, 0, 0, berlin),
+		},
+		{
+			name:          "in UTC",
+			at:            time.Date(2024, 6, 1, 0, 30, 0, 0, time.UTC),
+			expectedStart: time.Date(2024, 6, 1, 2, 0, 0, 0, berlin),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			start, end, err := w.NextOccurrence(tc.at)
+			require.NoError(t, err)
+			require.True(t, tc.expectedStart.Equal(start), "expected start %s but got %s", tc.expectedStart, start)
+			require.True(t, tc.expectedStart.Add(2*time.Hour).Equal(end))
+		})
+	}
+}
+
+func TestMaintenanceWindowNextOccurrenceOfRecurrenceRule(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	w := MaintenanceWindow{
+		Title:    "Patch day",
+		Schedule: "RRULE:FREQ=MONTHLY;BYDAY=2TU;BYHOUR=22",
+		Duration: 4 * time.Hour,
+		TimeZone: "America/New_York",
+		Matchers: []string{`env="prod"`},
+	}
+	require.NoError(t, w.Validate())
+
+	start, end, err := w.NextOccurrence(time.Date(2024, 3, 13, 1, 0, 0, 0, newYork))
+	require.NoError(t, err)
+	require.True(t, time.Date(2024, 3, 12, 22, 0, 0, 0, newYork).Equal(start), "the occurrence in progress is returned, got %s", start)
+	require.True(t, start.Add(4*time.Hour).Equal(end))
+
+	start, _, err = w.NextOccurrence(time.Date(2024, 3, 13, 2, 0, 0, 0, newYork))
+	require.NoError(t, err)
+	require.True(t, time.Date(2024, 4, 9, 22, 0, 0, 0, newYork).Equal(start), "expected the second Tuesday of April but got %s", start)
+
+	w.Schedule = "DTSTART:20240101T000000Z RRULE:FREQ=DAILY;COUNT=1"
+	_, _, err = w.NextOccurrence(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC))
+	require.Error(t, err)
+}
+
+func TestMaintenanceWindowValidate(t *testing.T) {
+	valid := MaintenanceWindow{
+		Title:    "Nightly backups",
+		Schedule: "@daily",
+		Duration: time.Hour,
+		Matchers: []string{`job=~"backup.*"`},
+	}
+	require.NoError(t, valid.Validate())
+
+	t.Run("recurrence rule", func(t *testing.T) {
+		w := valid
+		w.Schedule = "RRULE:FREQ=WEEKLY;BYDAY=SA;BYHOUR=2"
+		require.NoError(t, w.Validate())
+	})
+
+	testCases := map[string]func(w *MaintenanceWindow){
+		"empty title":       func(w *MaintenanceWindow) { w.Title = "" },
+		"invalid schedule":  func(w *MaintenanceWindow) { w.Schedule = "0 2 * *" },
+		"invalid rule":      func(w *MaintenanceWindow) { w.Schedule = "RRULE:FREQ=WEEKLY;BYDAY=XX" },
+		"invalid time zone": func(w *MaintenanceWindow) { w.TimeZone = "Nowhere/Else" },
+		"zero duration":     func(w *MaintenanceWindow) { w.Duration = 0 },
+		"no matchers":       func(w *MaintenanceWindow) { w.Matchers = nil },
+		"invalid matcher":   func(w *MaintenanceWindow) { w.Matchers = []string{`job=~"("`} },
+	}
+	for name, mutate := range testCases {
+		t.Run(name, func(t *testing.T) {
+			w := valid
+			mutate(&w)
+			require.Error(t, w.Validate())
+		})
+	}
+}
@@ -0,0 +1,391 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxEmptyPeriods is the number of consecutive periods without occurrences after which a recurrence rule is
+// considered to have no more occurrences, e.g. FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=30.
+const maxEmptyPeriods = 1000
+
+type recurrenceFrequency int
+
+const (
+	frequencyDaily recurrenceFrequency = iota + 1
+	frequencyWeekly
+	frequencyMonthly
+	frequencyYearly
+)
+
+var recurrenceFrequencies = map[string]recurrenceFrequency{
+	"DAILY":   frequencyDaily,
+	"WEEKLY":  frequencyWeekly,
+	"MONTHLY": frequencyMonthly,
+	"YEARLY":  frequencyYearly,
+}
+
+var recurrenceWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// recurrenceWeekday is a weekday of the BYDAY rule part. If n is not zero, it is the nth occurrence of the weekday
+// in the month or the year, counted from the end if negative.
+type recurrenceWeekday struct {
+	weekday time.Weekday
+	n       int
+}
+
+// recurrenceRule is a schedule that is defined by an iCalendar recurrence rule (RFC 5545), such as
+// FREQ=MONTHLY;BYDAY=1SA;BYHOUR=2. The rule can be preceded by a DTSTART line, otherwise it starts at
+// 1970-01-01T00:00:00. The DTSTART and UNTIL times are in the time zone of the schedule unless they end with Z.
+// The frequencies DAILY, WEEKLY, MONTHLY and YEARLY, and the rule parts INTERVAL, COUNT, UNTIL, BYMONTH,
+// BYMONTHDAY, BYDAY, BYHOUR, BYMINUTE and WKST are supported. The time of the day of the occurrences is the one
+// of BYHOUR and BYMINUTE, or of DTSTART if they are not set.
+type recurrenceRule struct {
+	location   *time.Location
+	start      time.Time
+	frequency  recurrenceFrequency
+	interval   int
+	count      int
+	until      time.Time
+	byMonth    []int
+	byMonthDay []int
+	byDay      []recurrenceWeekday
+	byHour     []int
+	byMinute   []int
+	weekStart  time.Weekday
+}
+
+// isRecurrenceRule returns true if the schedule looks like an iCalendar recurrence rule, e.g. RRULE:FREQ=WEEKLY;BYDAY=SA.
+func isRecurrenceRule(schedule string) bool {
+	s := strings.ToUpper(strings.TrimSpace(schedule))
+	return strings.HasPrefix(s, "RRULE:") || strings.HasPrefix(s, "DTSTART") || strings.Contains(s, "FREQ=")
+}
+
+// parseRecurrenceRule parses the recurrence rule s, optionally preceded by a DTSTART line, in the given location.
+func parseRecurrenceRule(s string, location *time.Location) (*recurrenceRule, error) {
+	r := &recurrenceRule{
+		location:  location,
+		start:     time.Date(1970, 1, 1, 0, 0, 0, 0, location),
+		interval:  1,
+		weekStart: time.Monday,
+	}
+	rule := ""
+	for _, line := range strings.Fields(s) {
+		upper := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(upper, "DTSTART"):
+			name, value, ok := strings.Cut(line, ":")
+			if !ok {
+				return nil, fmt.Errorf("invalid DTSTART '%s'", line)
+			}
+			if strings.Contains(name, ";") {
+				return nil, errors.New("DTSTART parameters such as TZID are not supported, the time zone of the maintenance window is used")
+			}
+			start, err := parseRecurrenceTime(value, location)
+			if err != nil {
+				return nil, fmt.Errorf("invalid DTSTART: %w", err)
+			}
+			r.start = start
+			continue
+		case strings.HasPrefix(upper, "RRULE:"):
+			line = line[len("RRULE:"):]
+		}
+		if rule != "" {
+			return nil, errors.New("only one recurrence rule is supported")
+		}
+		rule = line
+	}
+	if rule == "" {
+		return nil, errors.New("missing recurrence rule")
+	}
+
+	for _, part := range strings.Split(rule, ";") {
+		name, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid rule part '%s'", part)
+		}
+		var err error
+		switch strings.ToUpper(name) {
+		case "FREQ":
+			frequency, ok := recurrenceFrequencies[strings.ToUpper(value)]
+			if !ok {
+				return nil, fmt.Errorf("unsupported frequency '%s', use DAILY, WEEKLY, MONTHLY or YEARLY", value)
+			}
+			r.frequency = frequency
+		case "INTERVAL":
+			r.interval, err = parseRecurrenceNumber(value, 1, -1)
+		case "COUNT":
+			r.count, err = parseRecurrenceNumber(value, 1, -1)
+		case "UNTIL":
+			r.until, err = parseRecurrenceTime(value, location)
+		case "BYMONTH":
+			r.byMonth, err = parseRecurrenceNumbers(value, 1, 12)
+		case "BYMONTHDAY":
+			r.byMonthDay, err = parseRecurrenceNumbers(value, -31, 31)
+		case "BYDAY":
+			r.byDay, err = parseRecurrenceWeekdays(value)
+		case "BYHOUR":
+			r.byHour, err = parseRecurrenceNumbers(value, 0, 23)
+		case "BYMINUTE":
+			r.byMinute, err = parseRecurrenceNumbers(value, 0, 59)
+		case "WKST":
+			weekStart, ok := recurrenceWeekdays[strings.ToUpper(value)]
+			if !ok {
+				err = fmt.Errorf("invalid weekday '%s'", value)
+			}
+			r.weekStart = weekStart
+		default:
+			return nil, fmt.Errorf("unsupported rule part '%s'", name)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", strings.ToUpper(name), err)
+		}
+	}
+
+	if r.frequency == 0 {
+		return nil, errors.New("missing FREQ")
+	}
+	if r.count > 0 && !r.until.IsZero() {
+		return nil, errors.New("COUNT and UNTIL must not be used together")
+	}
+	if r.frequency == frequencyWeekly && len(r.byMonthDay) > 0 {
+		return nil, errors.New("BYMONTHDAY must not be used with FREQ=WEEKLY")
+	}
+	if r.frequency == frequencyDaily || r.frequency == frequencyWeekly {
+		for _, d := range r.byDay {
+			if d.n != 0 {
+				return nil, errors.New("numbered weekdays in BYDAY are only supported with FREQ=MONTHLY and FREQ=YEARLY")
+			}
+		}
+	}
+	return r, nil
+}
+
+// parseRecurrenceTime parses a DATE or DATE-TIME value, e.g. 20240601, 20240601T020000 or 20240601T020000Z.
+func parseRecurrenceTime(value string, location *time.Location) (time.Time, error) {
+	if strings.HasSuffix(value, "Z") {
+		return time.Parse("20060102T150405Z", value)
+	}
+	layout := "20060102T150405"
+	if len(value) == len("20060102") {
+		layout = "20060102"
+	}
+	return time.ParseInLocation(layout, value, location)
+}
+
+// parseRecurrenceNumber parses an integer between minimum and maximum that is not zero. If maximum is negative,
+// there is no upper bound.
+func parseRecurrenceNumber(value string, minimum, maximum int) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, err
+	}
+	if n < minimum || (maximum >= 0 && n > maximum) || (n == 0 && minimum < 0) {
+		return 0, fmt.Errorf("%d is out of range", n)
+	}
+	return n, nil
+}
+
+// parseRecurrenceNumbers parses a sorted comma-separated list of integers with parseRecurrenceNumber.
+func parseRecurrenceNumbers(value string, minimum, maximum int) ([]int, error) {
+	var result []int
+	for _, s := range strings.Split(value, ",") {
+		n, err := parseRecurrenceNumber(s, minimum, maximum)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, n)
+	}
+	slices.Sort(result)
+	return result, nil
+}
+
+// parseRecurrenceWeekdays parses a comma-separated list of weekdays, each optionally preceded by a number, e.g. MO,-1FR.
+func parseRecurrenceWeekdays(value string) ([]recurrenceWeekday, error) {
+	var result []recurrenceWeekday
+	for _, s := range strings.Split(value, ",") {
+		if len(s) < 2 {
+			return nil, fmt.Errorf("invalid weekday '%s'", s)
+		}
+		weekday, ok := recurrenceWeekdays[strings.ToUpper(s[len(s)-2:])]
+		if !ok {
+			return nil, fmt.Errorf("invalid weekday '%s'", s)
+		}
+		d := recurrenceWeekday{weekday: weekday}
+		if number := s[:len(s)-2]; number != "" {
+			n, err := parseRecurrenceNumber(number, -53, 53)
+			if err != nil {
+				return nil, fmt.Errorf("invalid weekday '%s': %w", s, err)
+			}
+			d.n = n
+		}
+		result = append(result, d)
+	}
+	return result, nil
+}
+
+// Next returns the first occurrence of the rule after t, or the zero time if there is none.
+func (r *recurrenceRule) Next(t time.Time) time.Time {
+	period, count := 0, 0
+	if r.count == 0 {
+		// Without COUNT the occurrences before t do not matter, so the search starts at the period of t.
+		if p := r.periodOf(t); p > 0 {
+			period = p - p%r.interval
+		}
+	}
+	for empty := 0; empty < maxEmptyPeriods; period += r.interval {
+		occurrences := r.occurrences(period)
+		if len(occurrences) == 0 {
+			empty++
+			continue
+		}
+		empty = 0
+		for _, o := range occurrences {
+			if o.Before(r.start) {
+				continue
+			}
+			if !r.until.IsZero() && o.After(r.until) {
+				return time.Time{}
+			}
+			count++
+			if r.count > 0 && count > r.count {
+				return time.Time{}
+			}
+			if o.After(t) {
+				return o
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// periodOf returns the number of days, weeks, months or years between the start of the rule and t.
+func (r *recurrenceRule) periodOf(t time.Time) int {
+	start, date := civilDate(r.start.In(r.location)), civilDate(t.In(r.location))
+	switch r.frequency {
+	case frequencyDaily:
+		return daysBetween(start, date)
+	case frequencyWeekly:
+		return daysBetween(r.startOfWeek(start), r.startOfWeek(date)) / 7
+	case frequencyMonthly:
+		return (date.Year()-start.Year())*12 + int(date.Month()) - int(start.Month())
+	default:
+		return date.Year() - start.Year()
+	}
+}
+
+// occurrences returns the sorted occurrences of the given period, including the ones before the start of the rule.
+func (r *recurrenceRule) occurrences(period int) []time.Time {
+	start := civilDate(r.start.In(r.location))
+	var spans [][2]time.Time
+	switch r.frequency {
+	case frequencyDaily:
+		day := start.AddDate(0, 0, period)
+		spans = append(spans, [2]time.Time{day, day})
+	case frequencyWeekly:
+		first := r.startOfWeek(start).AddDate(0, 0, 7*period)
+		spans = append(spans, [2]time.Time{first, first.AddDate(0, 0, 6)})
+	case frequencyMonthly:
+		first := time.Date(start.Year(), start.Month()+time.Month(period), 1, 0, 0, 0, 0, time.UTC)
+		spans = append(spans, [2]time.Time{first, first.AddDate(0, 1, -1)})
+	case frequencyYearly:
+		year := start.Year() + period
+		if len(r.byMonth) > 0 || len(r.byMonthDay) > 0 {
+			// numbered weekdays are counted in each month
+			for month := time.January; month <= time.December; month++ {
+				first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+				spans = append(spans, [2]time.Time{first, first.AddDate(0, 1, -1)})
+			}
+		} else {
+			spans = append(spans, [2]time.Time{time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC), time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC)})
+		}
+	}
+
+	hours, minutes := r.byHour, r.byMinute
+	if len(hours) == 0 {
+		hours = []int{r.start.In(r.location).Hour()}
+	}
+	if len(minutes) == 0 {
+		minutes = []int{r.start.In(r.location).Minute()}
+	}
+	second := r.start.In(r.location).Second()
+
+	var result []time.Time
+	for _, span := range spans {
+		for day := span[0]; !day.After(span[1]); day = day.AddDate(0, 0, 1) {
+			if !r.matches(day, span[0], span[1], start) {
+				continue
+			}
+			for _, hour := range hours {
+				for _, minute := range minutes {
+					result = append(result, time.Date(day.Year(), day.Month(), day.Day(), hour, minute, second, 0, r.location))
+				}
+			}
+		}
+	}
+	// times in a daylight saving time transition can be moved to another hour
+	slices.SortFunc(result, func(a, b time.Time) int { return a.Compare(b) })
+	return result
+}
+
+// matches returns true if the day, in a span of days between first and last, is a day of the rule.
+func (r *recurrenceRule) matches(day, first, last, start time.Time) bool {
+	if len(r.byMonth) > 0 && !slices.Contains(r.byMonth, int(day.Month())) {
+		return false
+	}
+	if len(r.byMonthDay) > 0 {
+		daysInMonth := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+		if !slices.ContainsFunc(r.byMonthDay, func(d int) bool { return d == day.Day() || d == day.Day()-daysInMonth-1 }) {
+			return false
+		}
+	}
+	if len(r.byDay) > 0 {
+		fromStart, fromEnd := daysBetween(first, day)/7+1, -(daysBetween(day, last)/7 + 1)
+		if !slices.ContainsFunc(r.byDay, func(d recurrenceWeekday) bool {
+			return d.weekday == day.Weekday() && (d.n == 0 || d.n == fromStart || d.n == fromEnd)
+		}) {
+			return false
+		}
+	}
+	if len(r.byMonthDay) > 0 || len(r.byDay) > 0 {
+		return true
+	}
+	// without BYMONTHDAY and BYDAY the day is the one of the start of the rule
+	switch r.frequency {
+	case frequencyWeekly:
+		return day.Weekday() == start.Weekday()
+	case frequencyMonthly:
+		return day.Day() == start.Day()
+	case frequencyYearly:
+		return day.Day() == start.Day() && (len(r.byMonth) > 0 || day.Month() == start.Month())
+	default:
+		return true
+	}
+}
+
+// startOfWeek returns the first day of the week of the day, according to WKST.
+func (r *recurrenceRule) startOfWeek(day time.Time) time.Time {
+	return day.AddDate(0, 0, -((int(day.Weekday()) - int(r.weekStart) + 7) % 7))
+}
+
+// civilDate returns the date of t at midnight in UTC, so that days can be added regardless of daylight saving time.
+func civilDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// daysBetween returns the number of days between two civil dates.
+func daysBetween(from, to time.Time) int {
+	return int(to.Sub(from).Hours()) / 24
+}
@@ -0,0 +1,171 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecurrenceRuleNext(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	at := func(year int, month time.Month, day, hour, minute int) time.Time {
+		return time.Date(year, month, day, hour, minute, 0, 0, berlin)
+	}
+
+	testCases := []struct {
+		name     string
+		rule     string
+		at       time.Time
+		expected time.Time
+	}{
+		{
+			name:     "weekly on saturday",
+			rule:     "RRULE:FREQ=WEEKLY;BYDAY=SA;BYHOUR=2",
+			at:       at(2024, 6, 1, 1, 0),
+			expected: at(2024, 6, 1, 2, 0),
+		},
+		{
+			name:     "next week after the occurrence",
+			rule:     "RRULE:FREQ=WEEKLY;BYDAY=SA;BYHOUR=2",
+			at:       at(2024, 6, 1, 2, 0),
+			expected: at(2024, 6, 8, 2, 0),
+		},
+		{
+			name:     "without prefix",
+			rule:     "FREQ=DAILY;BYHOUR=6,18;BYMINUTE=30",
+			at:       at(2024, 6, 1, 7, 0),
+			expected: at(2024, 6, 1, 18, 30),
+		},
+		{
+			name:     "across a daylight saving time change",
+			rule:     "FREQ=DAILY;BYHOUR=3",
+			at:       at(2024, 3, 30, 12, 0),
+			expected: time.Date(2024, 3, 31, 1, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "last friday of the month",
+			rule:     "FREQ=MONTHLY;BYDAY=-1FR;BYHOUR=18",
+			at:       at(2024, 2, 1, 0, 0),
+			expected: at(2024, 2, 23, 18, 0),
+		},
+		{
+			name:     "last day of the month",
+			rule:     "FREQ=MONTHLY;BYMONTHDAY=-1",
+			at:       at(2024, 2, 10, 0, 0),
+			expected: at(2024, 2, 29, 0, 0),
+		},
+		{
+			name:     "months without the day are skipped",
+			rule:     "FREQ=MONTHLY;BYMONTHDAY=31",
+			at:       at(2024, 4, 1, 0, 0),
+			expected: at(2024, 5, 31, 0, 0),
+		},
+		{
+			name:     "every other week from the start",
+			rule:     "DTSTART:20240106T020000\nRRULE:FREQ=WEEKLY;INTERVAL=2",
+			at:       at(2024, 6, 1, 0, 0),
+			expected: at(2024, 6, 8, 2, 0),
+		},
+		{
+			name:     "start in UTC is converted to the time zone",
+			rule:     "DTSTART:20240106T020000Z\nRRULE:FREQ=DAILY",
+			at:       at(2024, 6, 1, 0, 0),
+			expected: at(2024, 6, 1, 3, 0),
+		},
+		{
+			name:     "before the start",
+			rule:     "DTSTART:20240106T020000 RRULE:FREQ=DAILY",
+			at:       at(2023, 6, 1, 0, 0),
+			expected: at(2024, 1, 6, 2, 0),
+		},
+		{
+			name:     "within the count",
+			rule:     "DTSTART:20240101T090000 RRULE:FREQ=DAILY;COUNT=3",
+			at:       at(2024, 1, 2, 10, 0),
+			expected: at(2024, 1, 3, 9, 0),
+		},
+		{
+			name: "after the count",
+			rule: "DTSTART:20240101T090000 RRULE:FREQ=DAILY;COUNT=3",
+			at:   at(2024, 1, 3, 9, 0),
+		},
+		{
+			name:     "before until",
+			rule:     "FREQ=DAILY;BYHOUR=9;UNTIL=20240105T000000",
+			at:       at(2024, 1, 3, 10, 0),
+			expected: at(2024, 1, 4, 9, 0),
+		},
+		{
+			name: "after until",
+			rule: "FREQ=DAILY;BYHOUR=9;UNTIL=20240105T000000",
+			at:   at(2024, 1, 5, 0, 0),
+		},
+		{
+			name:     "leap day",
+			rule:     "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=29",
+			at:       at(2024, 3, 1, 0, 0),
+			expected: at(2028, 2, 29, 0, 0),
+		},
+		{
+			name:     "fourth thursday of november",
+			rule:     "FREQ=YEARLY;BYMONTH=11;BYDAY=4TH",
+			at:       at(2024, 1, 1, 0, 0),
+			expected: at(2024, 11, 28, 0, 0),
+		},
+		{
+			name: "day that does not exist",
+			rule: "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=30",
+			at:   at(2024, 1, 1, 0, 0),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rule, err := parseRecurrenceRule(tc.rule, berlin)
+			require.NoError(t, err)
+			next := rule.Next(tc.at)
+			require.True(t, tc.expected.Equal(next), "expected %s but got %s", tc.expected, next)
+		})
+	}
+
+	t.Run("week start", func(t *testing.T) {
+		newYork, err := time.LoadLocation("America/New_York")
+		require.NoError(t, err)
+		// examples of RFC 5545
+		for weekStart, expected := range map[string][]int{"MO": {5, 10, 19, 24}, "SU": {5, 17, 19, 31}} {
+			rule, err := parseRecurrenceRule("DTSTART:19970805T090000\nRRULE:FREQ=WEEKLY;INTERVAL=2;COUNT=4;BYDAY=TU,SU;WKST="+weekStart, newYork)
+			require.NoError(t, err)
+			var days []int
+			for next := rule.Next(time.Time{}); !next.IsZero(); next = rule.Next(next) {
+				require.Equal(t, 9, next.Hour())
+				days = append(days, next.Day())
+			}
+			require.Equal(t, expected, days, "WKST=%s", weekStart)
+		}
+	})
+}
+
+func TestParseRecurrenceRule(t *testing.T) {
+	for _, rule := range []string{
+		"",
+		"RRULE:BYDAY=SA",
+		"RRULE:FREQ=HOURLY",
+		"RRULE:FREQ=WEEKLY;BYDAY=XX",
+		"RRULE:FREQ=WEEKLY;BYDAY=1SA",
+		"RRULE:FREQ=WEEKLY;BYMONTHDAY=1",
+		"RRULE:FREQ=MONTHLY;BYMONTHDAY=0",
+		"RRULE:FREQ=DAILY;BYHOUR=24",
+		"RRULE:FREQ=DAILY;INTERVAL=0",
+		"RRULE:FREQ=DAILY;COUNT=2;UNTIL=20240101",
+		"RRULE:FREQ=DAILY;BYSETPOS=1",
+		"RRULE:FREQ=DAILY RRULE:FREQ=WEEKLY",
+		"DTSTART;TZID=Europe/Berlin:20240101T000000 RRULE:FREQ=DAILY",
+		"DTSTART:2024 RRULE:FREQ=DAILY",
+	} {
+		t.Run(rule, func(t *testing.T) {
+			_, err := parseRecurrenceRule(rule, time.UTC)
+			require.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// AlertRuleOwner records the member of the high availability cluster that evaluates an alert rule when the evaluation
+// of rules is split between the members of the cluster.
+type AlertRuleOwner struct {
+	RuleOrgID int64  `xorm:"rule_org_id"`
+	RuleUID   string `xorm:"rule_uid"`
+	// Owner is the name of the member that evaluates the rule. It is empty when the previous owner has saved the
+	// state of the rule and released it, so that any member can take over.
+	Owner   string    `xorm:"owner"`
+	Updated time.Time `xorm:"updated"`
+}
+
+func (AlertRuleOwner) TableName() string {
+	return "alert_rule_owner"
+}
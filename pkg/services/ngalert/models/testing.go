@@ -311,6 +311,12 @@ func (a *AlertRuleMutators) WithForNTimes(timesOfInterval int64) AlertRuleMutato
 	}
 }
 
+func (a *AlertRuleMutators) WithKeepFiringFor(duration time.Duration) AlertRuleMutator {
+	return func(rule *AlertRule) {
+		rule.KeepFiringFor = duration
+	}
+}
+
 func (a *AlertRuleMutators) WithNoDataExecAs(nodata NoDataState) AlertRuleMutator {
 	return func(rule *AlertRule) {
 		rule.NoDataState = nodata
@@ -478,6 +484,12 @@ func (a *AlertRuleMutators) WithNotificationSettings(ns NotificationSettings) Al
 	}
 }
 
+func (a *AlertRuleMutators) WithDependsOn(uids ...string) AlertRuleMutator {
+	return func(rule *AlertRule) {
+		rule.DependsOn = uids
+	}
+}
+
 func (a *AlertRuleMutators) WithNoNotificationSettings() AlertRuleMutator {
 	return func(rule *AlertRule) {
 		rule.NotificationSettings = nil
@@ -604,7 +616,11 @@ func CopyRule(r *AlertRule, mutators ...AlertRuleMutator) *AlertRule {
 		NoDataState:     r.NoDataState,
 		ExecErrState:    r.ExecErrState,
 		For:             r.For,
+		KeepFiringFor:   r.KeepFiringFor,
 		Record:          r.Record,
+		IsPaused:        r.IsPaused,
+		UpdatedBy:       r.UpdatedBy,
+		Message:         r.Message,
 	}
 
 	if r.DashboardUID != nil {
@@ -644,8 +660,9 @@ func CopyRule(r *AlertRule, mutators ...AlertRuleMutator) *AlertRule {
 
 	if r.Record != nil {
 		result.Record = &Record{
-			From:   r.Record.From,
-			Metric: r.Record.Metric,
+			From:                r.Record.From,
+			Metric:              r.Record.Metric,
+			TargetDatasourceUID: r.Record.TargetDatasourceUID,
 		}
 	}
 
@@ -653,6 +670,11 @@ func CopyRule(r *AlertRule, mutators ...AlertRuleMutator) *AlertRule {
 		result.NotificationSettings = append(result.NotificationSettings, CopyNotificationSettings(s))
 	}
 
+	if r.DependsOn != nil {
+		result.DependsOn = make([]string, len(r.DependsOn))
+		copy(result.DependsOn, r.DependsOn)
+	}
+
 	if len(mutators) > 0 {
 		for _, mutator := range mutators {
 			mutator(&result)
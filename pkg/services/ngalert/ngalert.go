@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/benbjohnson/clock"
+	"github.com/prometheus/alertmanager/dispatch"
 	"github.com/prometheus/alertmanager/featurecontrol"
 	"github.com/prometheus/alertmanager/matchers/compat"
 	"golang.org/x/sync/errgroup"
@@ -27,11 +28,15 @@ import (
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/live"
 	ac "github.com/grafana/grafana/pkg/services/ngalert/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/ngalert/acknowledgement"
 	"github.com/grafana/grafana/pkg/services/ngalert/api"
 	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/backtesting"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/image"
+	"github.com/grafana/grafana/pkg/services/ngalert/maintenance"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
@@ -41,6 +46,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/schedule"
 	"github.com/grafana/grafana/pkg/services/ngalert/sender"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	stateevents "github.com/grafana/grafana/pkg/services/ngalert/state/events"
 	"github.com/grafana/grafana/pkg/services/ngalert/state/historian"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/services/ngalert/writer"
@@ -77,6 +83,7 @@ func ProvideService(
 	tracer tracing.Tracer,
 	ruleStore *store.DBstore,
 	httpClientProvider httpclient.Provider,
+	liveService *live.GrafanaLive,
 ) (*AlertNG, error) {
 	ng := &AlertNG{
 		Cfg:                  cfg,
@@ -104,6 +111,7 @@ func ProvideService(
 		tracer:               tracer,
 		store:                ruleStore,
 		httpClientProvider:   httpClientProvider,
+		live:                 liveService,
 	}
 
 	if ng.IsDisabled() {
@@ -136,12 +144,18 @@ type AlertNG struct {
 	renderService       rendering.Service
 	ImageService        image.ImageService
 	RecordingWriter     schedule.RecordingWriter
+	backfiller          *backtesting.Backfiller
+	stateEvents         *stateevents.Dispatcher
+	maintenanceWindows  *maintenance.Scheduler
+	escalator           *notifier.Escalator
+	acknowledgements    *acknowledgement.Service
 	schedule            schedule.ScheduleService
 	stateManager        *state.Manager
 	folderService       folder.Service
 	dashboardService    dashboards.DashboardService
 	Api                 *api.API
 	httpClientProvider  httpclient.Provider
+	live                *live.GrafanaLive
 
 	// Alerting notification services
 	MultiOrgAlertmanager *notifier.MultiOrgAlertmanager
@@ -350,12 +364,18 @@ func (ng *AlertNG) init() error {
 		// Force-disable the feature if the feature toggle is not on - sets us up for feature toggle removal.
 		ng.Cfg.UnifiedAlerting.RecordingRules.Enabled = false
 	}
-	recordingWriter, err := createRecordingWriter(ng.FeatureToggles, ng.Cfg.UnifiedAlerting.RecordingRules, ng.httpClientProvider, clk, ng.Metrics.GetRemoteWriterMetrics())
+	recordingWriter, err := createRecordingWriter(ng.FeatureToggles, ng.Cfg.UnifiedAlerting.RecordingRules, ng.DataSourceService, ng.httpClientProvider, ng.SQLStore, clk, ng.Metrics.GetRemoteWriterMetrics())
 	if err != nil {
 		return fmt.Errorf("failed to initialize recording writer: %w", err)
 	}
 	ng.RecordingWriter = recordingWriter
 
+	var backfiller api.RecordingRuleBackfiller
+	if ng.Cfg.UnifiedAlerting.RecordingRules.Enabled {
+		ng.backfiller = backtesting.NewBackfiller(ng.Cfg.UnifiedAlerting.RecordingRules, evalFactory, recordingWriter, ng.store, ng.store, clk)
+		backfiller = ng.backfiller
+	}
+
 	schedCfg := schedule.SchedulerCfg{
 		MaxAttempts:          ng.Cfg.UnifiedAlerting.MaxAttempts,
 		C:                    clk,
@@ -372,12 +392,24 @@ func (ng *AlertNG) init() error {
 		Tracer:               ng.tracer,
 		Log:                  log.New("ngalert.scheduler"),
 		RecordingWriter:      ng.RecordingWriter,
+		EvaluationLimits:     ng.Cfg.UnifiedAlerting.EvaluationLimits,
+	}
+
+	var sharding bool
+	if ng.Cfg.UnifiedAlerting.HAEvaluationSharding {
+		if membership := moa.ClusterMembership(); membership != nil {
+			schedCfg.ClusterMembership = membership
+			schedCfg.RuleOwners = ng.store
+			sharding = true
+		} else {
+			ng.Log.Warn("Sharding of rule evaluation is enabled but high availability is not configured. All rules are evaluated by this instance")
+		}
 	}
 
 	// There are a set of feature toggles available that act as short-circuits for common configurations.
 	// If any are set, override the config accordingly.
 	ApplyStateHistoryFeatureToggles(&ng.Cfg.UnifiedAlerting.StateHistory, ng.FeatureToggles, ng.Log)
-	history, err := configureHistorianBackend(initCtx, ng.Cfg.UnifiedAlerting.StateHistory, ng.annotationsRepo, ng.dashboardService, ng.store, ng.Metrics.GetHistorianMetrics(), ng.Log, ng.tracer, ac.NewRuleService(ng.accesscontrol))
+	history, err := configureHistorianBackend(initCtx, ng.Cfg.UnifiedAlerting.StateHistory, ng.annotationsRepo, ng.dashboardService, ng.SQLStore, ng.store, ng.Metrics.GetHistorianMetrics(), ng.Log, ng.tracer, ac.NewRuleService(ng.accesscontrol))
 	if err != nil {
 		return err
 	}
@@ -397,9 +429,30 @@ func (ng *AlertNG) init() error {
 		Log:                            log.New("ngalert.state.manager"),
 		ResolvedRetention:              ng.Cfg.UnifiedAlerting.ResolvedAlertRetention,
 	}
+	if sharding {
+		cfg.ShardedRuleReader = ng.store
+	}
+	ng.stateEvents, err = configureStateEvents(ng.Cfg.UnifiedAlerting.StateEvents, ng.httpClientProvider, ng.live, clk)
+	if err != nil {
+		return fmt.Errorf("failed to configure state events: %w", err)
+	}
+	if ng.stateEvents != nil {
+		cfg.Events = ng.stateEvents
+	}
+	ng.maintenanceWindows = maintenance.NewScheduler(ng.store, ng.MultiOrgAlertmanager, maintenance.DefaultSyncInterval, clk)
+	cfg.MaintenanceWindows = ng.maintenanceWindows
+	// The alerts of a firing alert group are missing from the Alertmanager until they are sent again, e.g. after a
+	// restart, so its escalation is kept for longer than the resend delay plus the group interval.
+	escalationResolveTimeout := 2 * (state.ResendDelay + dispatch.DefaultRouteOpts.GroupInterval)
+	ng.escalator = notifier.NewEscalator(ng.store, ng.MultiOrgAlertmanager, notifier.DefaultEscalationInterval, escalationResolveTimeout, clk)
+	ng.acknowledgements = acknowledgement.NewService(ng.store, ng.MultiOrgAlertmanager, acknowledgement.DefaultSyncInterval, clk)
+	cfg.Acknowledgements = ng.acknowledgements
 	logger := log.New("ngalert.state.manager.persist")
 	statePersister := state.NewSyncStatePersisiter(logger, cfg)
-	if ng.FeatureToggles.IsEnabledGlobally(featuremgmt.FlagAlertingSaveStatePeriodic) {
+	if ng.FeatureToggles.IsEnabledGlobally(featuremgmt.FlagAlertingSaveStatePeriodic) && sharding {
+		// The periodic save replaces the state of all rules, including the ones evaluated by other instances.
+		ng.Log.Warn("Periodic saving of the alert state is not supported when sharding rule evaluation. The state is saved after every evaluation")
+	} else if ng.FeatureToggles.IsEnabledGlobally(featuremgmt.FlagAlertingSaveStatePeriodic) {
 		ticker := clock.New().Ticker(ng.Cfg.UnifiedAlerting.StatePeriodicSaveInterval)
 		statePersister = state.NewAsyncStatePersister(logger, ticker, cfg)
 	}
@@ -437,11 +490,13 @@ func (ng *AlertNG) init() error {
 	contactPointService := provisioning.NewContactPointService(configStore, ng.SecretsService, ng.store, ng.store, provisioningReceiverService, ng.Log, ng.store)
 	templateService := provisioning.NewTemplateService(configStore, ng.store, ng.store, ng.Log)
 	muteTimingService := provisioning.NewMuteTimingService(configStore, ng.store, ng.store, ng.Log, ng.store)
+	maintenanceWindowService := provisioning.NewMaintenanceWindowService(ng.store, ng.store, ng.store, ng.Log)
+	escalationChainService := provisioning.NewEscalationChainService(ng.store, configStore, ng.store, ng.store, ng.Log)
 	alertRuleService := provisioning.NewAlertRuleService(ng.store, ng.store, ng.folderService, ng.QuotaService, ng.store,
 		int64(ng.Cfg.UnifiedAlerting.DefaultRuleEvaluationInterval.Seconds()),
 		int64(ng.Cfg.UnifiedAlerting.BaseInterval.Seconds()),
 		ng.Cfg.UnifiedAlerting.RulesPerRuleGroupLimit, ng.Log, notifier.NewNotificationSettingsValidationService(ng.store),
-		ac.NewRuleService(ng.accesscontrol))
+		ac.NewRuleService(ng.accesscontrol), ng.DataSourceService)
 
 	ng.Api = &api.API{
 		Cfg:                  ng.Cfg,
@@ -463,6 +518,10 @@ func (ng *AlertNG) init() error {
 		ContactPointService:  contactPointService,
 		Templates:            templateService,
 		MuteTimings:          muteTimingService,
+		MaintenanceWindows:   maintenanceWindowService,
+		EscalationChains:     escalationChainService,
+		Escalator:            ng.escalator,
+		Acknowledgements:     ng.acknowledgements,
 		AlertRules:           alertRuleService,
 		AlertsRouter:         alertsRouter,
 		EvaluatorFactory:     evalFactory,
@@ -470,6 +529,7 @@ func (ng *AlertNG) init() error {
 		FeatureManager:       ng.FeatureToggles,
 		AppUrl:               appUrl,
 		Historian:            history,
+		Backfiller:           backfiller,
 		Hooks:                api.NewHooks(ng.Log),
 		Tracer:               ng.tracer,
 	}
@@ -517,6 +577,24 @@ func (ng *AlertNG) Run(ctx context.Context) error {
 	children.Go(func() error {
 		return ng.AlertsRouter.Run(subCtx)
 	})
+	// The silences of maintenance windows apply to all alerts received by the Alertmanager, including the ones
+	// that are not evaluated by this instance.
+	if ng.maintenanceWindows != nil {
+		children.Go(func() error {
+			return ng.maintenanceWindows.Run(subCtx)
+		})
+	}
+	// Alert groups are escalated by all instances. A step is notified only by the instance that claims it.
+	if ng.escalator != nil {
+		children.Go(func() error {
+			return ng.escalator.Run(subCtx)
+		})
+	}
+	if ng.acknowledgements != nil {
+		children.Go(func() error {
+			return ng.acknowledgements.Run(subCtx)
+		})
+	}
 
 	if ng.Cfg.UnifiedAlerting.ExecuteAlerts {
 		// Only Warm() the state manager if we are actually executing alerts.
@@ -536,6 +614,16 @@ func (ng *AlertNG) Run(ctx context.Context) error {
 		children.Go(func() error {
 			return ng.stateManager.Run(subCtx)
 		})
+		if ng.backfiller != nil {
+			children.Go(func() error {
+				return ng.backfiller.Run(subCtx)
+			})
+		}
+		if ng.stateEvents != nil {
+			children.Go(func() error {
+				return ng.stateEvents.Run(subCtx)
+			})
+		}
 	}
 	return children.Wait()
 }
@@ -560,7 +648,7 @@ type Historian interface {
 	state.Historian
 }
 
-func configureHistorianBackend(ctx context.Context, cfg setting.UnifiedAlertingStateHistorySettings, ar annotations.Repository, ds dashboards.DashboardService, rs historian.RuleStore, met *metrics.Historian, l log.Logger, tracer tracing.Tracer, ac historian.AccessControl) (Historian, error) {
+func configureHistorianBackend(ctx context.Context, cfg setting.UnifiedAlertingStateHistorySettings, ar annotations.Repository, ds dashboards.DashboardService, sqlStore db.DB, rs historian.RuleStore, met *metrics.Historian, l log.Logger, tracer tracing.Tracer, ac historian.AccessControl) (Historian, error) {
 	if !cfg.Enabled {
 		met.Info.WithLabelValues("noop").Set(0)
 		return historian.NewNopHistorian(), nil
@@ -575,7 +663,7 @@ func configureHistorianBackend(ctx context.Context, cfg setting.UnifiedAlertingS
 	if backend == historian.BackendTypeMultiple {
 		primaryCfg := cfg
 		primaryCfg.Backend = cfg.MultiPrimary
-		primary, err := configureHistorianBackend(ctx, primaryCfg, ar, ds, rs, met, l, tracer, ac)
+		primary, err := configureHistorianBackend(ctx, primaryCfg, ar, ds, sqlStore, rs, met, l, tracer, ac)
 		if err != nil {
 			return nil, fmt.Errorf("multi-backend target \"%s\" was misconfigured: %w", cfg.MultiPrimary, err)
 		}
@@ -584,7 +672,7 @@ func configureHistorianBackend(ctx context.Context, cfg setting.UnifiedAlertingS
 		for _, b := range cfg.MultiSecondaries {
 			secCfg := cfg
 			secCfg.Backend = b
-			sec, err := configureHistorianBackend(ctx, secCfg, ar, ds, rs, met, l, tracer, ac)
+			sec, err := configureHistorianBackend(ctx, secCfg, ar, ds, sqlStore, rs, met, l, tracer, ac)
 			if err != nil {
 				return nil, fmt.Errorf("multi-backend target \"%s\" was miconfigured: %w", b, err)
 			}
@@ -615,6 +703,10 @@ func configureHistorianBackend(ctx context.Context, cfg setting.UnifiedAlertingS
 		}
 		return backend, nil
 	}
+	if backend == historian.BackendTypeSQL {
+		sqlBackendLogger := log.New("ngalert.state.historian", "backend", "sql")
+		return historian.NewSQLBackend(sqlBackendLogger, sqlStore, met, rs, ac), nil
+	}
 
 	return nil, fmt.Errorf("unrecognized state history backend: %s", backend)
 }
@@ -668,11 +760,53 @@ func createRemoteAlertmanager(cfg remote.AlertmanagerConfig, kvstore kvstore.KVS
 	return remote.NewAlertmanager(cfg, notifier.NewFileStore(cfg.OrgID, kvstore), decryptFn, autogenFn, m, tracer)
 }
 
-func createRecordingWriter(featureToggles featuremgmt.FeatureToggles, settings setting.RecordingRuleSettings, httpClientProvider httpclient.Provider, clock clock.Clock, m *metrics.RemoteWriter) (schedule.RecordingWriter, error) {
+func configureStateEvents(cfg setting.UnifiedAlertingStateEventsSettings, httpClientProvider httpclient.Provider, liveService *live.GrafanaLive, clk clock.Clock) (*stateevents.Dispatcher, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	if len(cfg.Sinks) == 0 {
+		return nil, fmt.Errorf("no sinks are configured")
+	}
+
+	sinks := make(map[stateevents.SinkType]stateevents.Sink, len(cfg.Sinks))
+	for _, name := range cfg.Sinks {
+		sinkType, err := stateevents.ParseSinkType(name)
+		if err != nil {
+			return nil, err
+		}
+		switch sinkType {
+		case stateevents.SinkTypeWebhook:
+			client, err := httpClientProvider.New()
+			if err != nil {
+				return nil, err
+			}
+			client.Timeout = cfg.WebhookTimeout
+			sink, err := stateevents.NewWebhookSink(cfg, client, clk, log.New("ngalert.state.events", "sink", "webhook"))
+			if err != nil {
+				return nil, err
+			}
+			sinks[sinkType] = sink
+		case stateevents.SinkTypeLive:
+			if liveService == nil {
+				return nil, fmt.Errorf("grafana live is not available")
+			}
+			sinks[sinkType] = stateevents.NewLiveSink(liveService)
+		case stateevents.SinkTypeFile:
+			sink, err := stateevents.NewFileSink(cfg.FilePath)
+			if err != nil {
+				return nil, err
+			}
+			sinks[sinkType] = sink
+		}
+	}
+	return stateevents.NewDispatcher(sinks, cfg.QueueSize, log.New("ngalert.state.events")), nil
+}
+
+func createRecordingWriter(featureToggles featuremgmt.FeatureToggles, settings setting.RecordingRuleSettings, datasources writer.DatasourceService, httpClientProvider httpclient.Provider, store db.DB, clock clock.Clock, m *metrics.RemoteWriter) (schedule.RecordingWriter, error) {
 	logger := log.New("ngalert.writer")
 
 	if settings.Enabled {
-		return writer.NewPrometheusWriter(settings, httpClientProvider, clock, logger, m)
+		return writer.NewDatasourceWriter(settings, datasources, httpClientProvider, store, clock, logger, m)
 	}
 
 	return writer.NoopWriter{}, nil
@@ -9,6 +9,9 @@ import (
 	alertingOpsgenie "github.com/grafana/alerting/receivers/opsgenie"
 	alertingPagerduty "github.com/grafana/alerting/receivers/pagerduty"
 	alertingTemplates "github.com/grafana/alerting/templates"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/integrations/matrix"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/integrations/zulip"
 )
 
 // GetAvailableNotifiers returns the metadata of all the notification channels that can be configured.
@@ -1594,6 +1597,145 @@ func GetAvailableNotifiers() []*NotifierPlugin {
 				},
 			},
 		},
+		{
+			Type:        matrix.Type,
+			Name:        "Matrix",
+			Description: "Sends notifications to a Matrix room",
+			Heading:     "Matrix settings",
+			Info:        "Notifications of the same alert group are sent as replies to the first notification of the group.",
+			Options: []NotifierOption{
+				{
+					Label:        "Homeserver URL",
+					Description:  "The URL of the homeserver of the bot account.",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Placeholder:  "https://matrix.example.org",
+					PropertyName: "homeserverUrl",
+					Required:     true,
+				},
+				{
+					Label:        "Access Token",
+					Description:  "Access token of the bot account that will post messages. The account must have joined the room.",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					PropertyName: "accessToken",
+					Required:     true,
+					Secure:       true,
+				},
+				{
+					Label:        "Room ID",
+					Description:  "The ID of the room to send messages to.",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Placeholder:  "!abcdefghijklmnop:matrix.example.org",
+					PropertyName: "roomId",
+					Required:     true,
+				},
+				{
+					Label:        "Title",
+					Description:  "Templated title of the message",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Placeholder:  alertingTemplates.DefaultMessageTitleEmbed,
+					PropertyName: "title",
+				},
+				{
+					Label:        "Message",
+					Element:      ElementTypeTextArea,
+					Placeholder:  alertingTemplates.DefaultMessageEmbed,
+					PropertyName: "message",
+				},
+				{
+					Label:   "Message Format",
+					Element: ElementTypeSelect,
+					SelectOptions: []SelectOption{
+						{
+							Value: matrix.MessageFormatText,
+							Label: "Text",
+						},
+						{
+							Value: matrix.MessageFormatHTML,
+							Label: "HTML",
+						},
+					},
+					Description:  "Whether the message is sent as plain text or as HTML. Default is 'text'",
+					PropertyName: "messageFormat",
+				},
+				{
+					Label:        "Disable Threads",
+					Description:  "Sends every notification as a new message instead of a reply to the first notification of the alert group",
+					Element:      ElementTypeCheckbox,
+					PropertyName: "disableThreads",
+				},
+			},
+		},
+		{
+			Type:        zulip.Type,
+			Name:        "Zulip",
+			Description: "Sends notifications to a Zulip stream",
+			Heading:     "Zulip settings",
+			Info:        "Notifications are sent to a topic of the stream, so that notifications with the same topic are threaded together.",
+			Options: []NotifierOption{
+				{
+					Label:        "Zulip URL",
+					Description:  "The URL of the Zulip organization.",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Placeholder:  "https://example.zulipchat.com",
+					PropertyName: "url",
+					Required:     true,
+				},
+				{
+					Label:        "Bot Email",
+					Description:  "The email address of the bot that will post messages.",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Placeholder:  "grafana-bot@example.zulipchat.com",
+					PropertyName: "botEmail",
+					Required:     true,
+				},
+				{
+					Label:        "API Key",
+					Description:  "The API key of the bot.",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					PropertyName: "apiKey",
+					Required:     true,
+					Secure:       true,
+				},
+				{
+					Label:        "Stream",
+					Description:  "The name of the stream to send messages to.",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					PropertyName: "stream",
+					Required:     true,
+				},
+				{
+					Label:        "Topic",
+					Description:  "Templated topic of the message. Zulip truncates topics to 60 characters.",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Placeholder:  zulip.DefaultTopic,
+					PropertyName: "topic",
+				},
+				{
+					Label:        "Title",
+					Description:  "Templated title of the message",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Placeholder:  alertingTemplates.DefaultMessageTitleEmbed,
+					PropertyName: "title",
+				},
+				{
+					Label:        "Message",
+					Description:  "Templated message. Markdown is supported.",
+					Element:      ElementTypeTextArea,
+					Placeholder:  alertingTemplates.DefaultMessageEmbed,
+					PropertyName: "message",
+				},
+			},
+		},
 	}
 }
 
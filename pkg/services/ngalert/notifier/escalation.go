@@ -0,0 +1,491 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/prometheus/alertmanager/dispatch"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/infra/log"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// DefaultEscalationInterval is the interval at which the escalator checks the firing alert groups.
+const DefaultEscalationInterval = 30 * time.Second
+
+var ErrAlertGroupEscalationNotFound = errutil.NotFound("alerting.notifications.escalations.notFound")
+
+// EscalationStore persists the escalation chains and the escalations of alert groups.
+type EscalationStore interface {
+	ListEscalationChains(ctx context.Context, orgID int64) ([]*models.EscalationChain, error)
+	ListAllEscalationChains(ctx context.Context) ([]*models.EscalationChain, error)
+	ListAlertGroupEscalations(ctx context.Context, orgID int64) ([]*models.AlertGroupEscalation, error)
+	GetAlertGroupEscalation(ctx context.Context, orgID int64, groupKey string) (*models.AlertGroupEscalation, error)
+	InsertAlertGroupEscalation(ctx context.Context, escalation *models.AlertGroupEscalation) (bool, error)
+	ClaimAlertGroupEscalationStep(ctx context.Context, id int64, previous, step int, at time.Time) (bool, error)
+	SetAlertGroupAcknowledgement(ctx context.Context, escalation *models.AlertGroupEscalation) error
+	SetAlertGroupEscalationsSeen(ctx context.Context, at time.Time, ids ...int64) error
+	DeleteAlertGroupEscalations(ctx context.Context, notSeenSince time.Time, ids ...int64) error
+}
+
+// EscalationAlertmanager provides the firing alert groups of an organization and notifies the contact points of
+// escalation steps. It is implemented by MultiOrgAlertmanager.
+type EscalationAlertmanager interface {
+	GetFiringAlertGroups(ctx context.Context, orgID int64) ([]FiringAlertGroup, error)
+	NotifyReceiver(ctx context.Context, orgID int64, receiver, groupKey string, group *apimodels.AlertGroup, now time.Time) error
+}
+
+// FiringAlertGroup is a firing alert group of the Alertmanager and the ID of the notification policy that created it.
+type FiringAlertGroup struct {
+	*apimodels.AlertGroup
+	// RouteID identifies the notification policy of the group, see dispatch.Route.ID. It is empty if no policy of
+	// the current configuration creates the group.
+	RouteID string
+}
+
+// Key returns the key of the alert group, see models.AlertGroupKey.
+func (g FiringAlertGroup) Key() string {
+	return models.AlertGroupKey(g.RouteID, *g.Receiver.Name, g.Labels)
+}
+
+// Escalator escalates the firing alert groups that are routed to the contact point of an escalation chain. When
+// the delay of the next step of the chain has passed since the previous notification, and the group has not been
+// acknowledged, the contact point of the step is notified.
+//
+// The position of each group in its chain is kept in the database. The steps are claimed in the database before
+// their contact point is notified, so that when several Grafana instances run the escalator, each step is notified
+// once. The escalation of a group is deleted when no instance has seen it firing for the resolve timeout, so that it
+// starts over if it fires again. The timeout must be longer than it takes for the alerts of a group to be sent to the
+// Alertmanager again after a restart, so that the escalations are not deleted while the Alertmanager has no alerts.
+type Escalator struct {
+	store          EscalationStore
+	alertmanagers  EscalationAlertmanager
+	interval       time.Duration
+	resolveTimeout time.Duration
+	clock          clock.Clock
+	log            log.Logger
+}
+
+func NewEscalator(store EscalationStore, alertmanagers EscalationAlertmanager, interval, resolveTimeout time.Duration, clk clock.Clock) *Escalator {
+	if interval <= 0 {
+		interval = DefaultEscalationInterval
+	}
+	return &Escalator{
+		store:          store,
+		alertmanagers:  alertmanagers,
+		interval:       interval,
+		resolveTimeout: resolveTimeout,
+		clock:          clk,
+		log:            log.New("ngalert.notifier.escalator"),
+	}
+}
+
+// Run escalates the alert groups at every interval until the context is cancelled.
+func (e *Escalator) Run(ctx context.Context) error {
+	ticker := e.clock.Ticker(e.interval)
+	defer ticker.Stop()
+	for {
+		if err := e.sync(ctx); err != nil {
+			e.log.Error("Failed to escalate alert groups", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// GetAlertGroupEscalations returns the escalations of the firing alert groups of the organization.
+func (e *Escalator) GetAlertGroupEscalations(ctx context.Context, orgID int64) ([]*models.AlertGroupEscalation, error) {
+	return e.store.ListAlertGroupEscalations(ctx, orgID)
+}
+
+// Acknowledge stops the escalation of the firing alert groups of the contact point with the group labels. The groups
+// do not need to be escalated yet. The acknowledgement is removed when the groups no longer fire.
+func (e *Escalator) Acknowledge(ctx context.Context, orgID int64, receiver string, groupLabels map[string]string, by, comment string) (*models.AlertGroupEscalation, error) {
+	chains, err := e.store.ListEscalationChains(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := e.alertmanagers.GetFiringAlertGroups(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	chainUID := ""
+	for _, c := range chains {
+		if c.Receiver == receiver {
+			chainUID = c.UID
+			break
+		}
+	}
+
+	now := e.clock.Now()
+	var result *models.AlertGroupEscalation
+	for _, group := range groups {
+		if group.Receiver == nil || group.Receiver.Name == nil || *group.Receiver.Name != receiver || !maps.Equal(map[string]string(group.Labels), groupLabels) {
+			continue
+		}
+		escalation := &models.AlertGroupEscalation{
+			OrgID:       orgID,
+			GroupKey:    group.Key(),
+			Receiver:    receiver,
+			GroupLabels: groupLabels,
+			ChainUID:    chainUID,
+			StartedAt:   now,
+			EscalatedAt: now,
+			LastSeenAt:  now,
+		}
+		if _, err := e.store.InsertAlertGroupEscalation(ctx, escalation); err != nil {
+			return nil, err
+		}
+		acknowledged, err := e.setAcknowledgement(ctx, &models.AlertGroupEscalation{
+			OrgID:          orgID,
+			GroupKey:       escalation.GroupKey,
+			Acknowledged:   true,
+			AcknowledgedBy: by,
+			AcknowledgedAt: now,
+			Comment:        comment,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if result == nil {
+			result = acknowledged
+		}
+	}
+	if result == nil {
+		return nil, WithPublicError(ErrAlertGroupEscalationNotFound.Errorf("alert group of contact point %q is not firing", receiver))
+	}
+	return result, nil
+}
+
+// Unacknowledge resumes the escalation of the alert group with the given key. The next step is notified as soon as
+// its delay has passed since the previous notification.
+func (e *Escalator) Unacknowledge(ctx context.Context, orgID int64, groupKey string) (*models.AlertGroupEscalation, error) {
+	return e.setAcknowledgement(ctx, &models.AlertGroupEscalation{OrgID: orgID, GroupKey: groupKey})
+}
+
+func (e *Escalator) setAcknowledgement(ctx context.Context, ack *models.AlertGroupEscalation) (*models.AlertGroupEscalation, error) {
+	if err := e.store.SetAlertGroupAcknowledgement(ctx, ack); err != nil {
+		if errors.Is(err, models.ErrAlertGroupEscalationNotFound) {
+			return nil, WithPublicError(ErrAlertGroupEscalationNotFound.Errorf("alert group %s is not escalated", ack.GroupKey))
+		}
+		return nil, err
+	}
+	return e.store.GetAlertGroupEscalation(ctx, ack.OrgID, ack.GroupKey)
+}
+
+// sync starts the escalation of the new firing alert groups, notifies the contact points of the steps that are due,
+// and deletes the escalations of the groups that no longer fire.
+func (e *Escalator) sync(ctx context.Context) error {
+	now := e.clock.Now()
+	chains, err := e.store.ListAllEscalationChains(ctx)
+	if err != nil {
+		return err
+	}
+	escalations, err := e.store.ListAlertGroupEscalations(ctx, 0)
+	if err != nil {
+		return err
+	}
+
+	chainsByOrg := make(map[int64]map[string]*models.EscalationChain)
+	for _, c := range chains {
+		if chainsByOrg[c.OrgID] == nil {
+			chainsByOrg[c.OrgID] = make(map[string]*models.EscalationChain)
+		}
+		chainsByOrg[c.OrgID][c.Receiver] = c
+	}
+	escalationsByOrg := make(map[int64]map[string]*models.AlertGroupEscalation)
+	for _, esc := range escalations {
+		if escalationsByOrg[esc.OrgID] == nil {
+			escalationsByOrg[esc.OrgID] = make(map[string]*models.AlertGroupEscalation)
+		}
+		escalationsByOrg[esc.OrgID][esc.GroupKey] = esc
+	}
+	orgIDs := make(map[int64]struct{}, len(chainsByOrg)+len(escalationsByOrg))
+	for orgID := range chainsByOrg {
+		orgIDs[orgID] = struct{}{}
+	}
+	for orgID := range escalationsByOrg {
+		orgIDs[orgID] = struct{}{}
+	}
+
+	for orgID := range orgIDs {
+		e.syncOrg(ctx, orgID, chainsByOrg[orgID], escalationsByOrg[orgID], now)
+	}
+	return nil
+}
+
+func (e *Escalator) syncOrg(ctx context.Context, orgID int64, chains map[string]*models.EscalationChain, escalations map[string]*models.AlertGroupEscalation, now time.Time) {
+	logger := e.log.New("org_id", orgID)
+	groups, err := e.alertmanagers.GetFiringAlertGroups(ctx, orgID)
+	if err != nil {
+		// The escalations are kept until the alert groups are known.
+		logger.Debug("Failed to get alert groups", "error", err)
+		return
+	}
+
+	firing := make(map[string]struct{}, len(groups))
+	var seen []int64
+	for _, group := range groups {
+		if group.Receiver == nil || group.Receiver.Name == nil {
+			continue
+		}
+		receiver := *group.Receiver.Name
+		groupLabels := make(map[string]string, len(group.Labels))
+		for k, v := range group.Labels {
+			groupLabels[k] = v
+		}
+		key := group.Key()
+		firing[key] = struct{}{}
+
+		chain, ok := chains[receiver]
+		if !ok {
+			if esc, ok := escalations[key]; ok {
+				// The group was acknowledged before it was escalated.
+				seen = append(seen, esc.ID)
+			}
+			continue
+		}
+		groupLogger := logger.New("escalation_chain", chain.UID, "group_key", key)
+		esc, ok := escalations[key]
+		if !ok {
+			// The contact point of the chain is notified by the notification policy, so the first step is due once
+			// its delay has passed.
+			esc = &models.AlertGroupEscalation{
+				OrgID:       orgID,
+				GroupKey:    key,
+				Receiver:    receiver,
+				GroupLabels: groupLabels,
+				ChainUID:    chain.UID,
+				StartedAt:   now,
+				EscalatedAt: now,
+				LastSeenAt:  now,
+			}
+			if _, err := e.store.InsertAlertGroupEscalation(ctx, esc); err != nil {
+				groupLogger.Error("Failed to start escalation of alert group", "error", err)
+			}
+			continue
+		}
+		seen = append(seen, esc.ID)
+		e.escalate(ctx, groupLogger, chain, esc, group, now)
+	}
+	if err := e.store.SetAlertGroupEscalationsSeen(ctx, now, seen...); err != nil {
+		logger.Error("Failed to save firing alert groups", "error", err)
+	}
+
+	// The Alertmanager of this instance may not have the alerts of a group that fires, e.g. right after a restart,
+	// or if the group is seen by other instances only. Escalations are deleted only if no instance has seen their
+	// group for the resolve timeout.
+	var resolved []int64
+	for key, esc := range escalations {
+		if _, ok := firing[key]; !ok && now.Sub(lastSeen(esc)) > e.resolveTimeout {
+			resolved = append(resolved, esc.ID)
+		}
+	}
+	if err := e.store.DeleteAlertGroupEscalations(ctx, now.Add(-e.resolveTimeout), resolved...); err != nil {
+		logger.Error("Failed to delete escalations of resolved alert groups", "error", err)
+	}
+}
+
+// lastSeen returns when the alert group of the escalation was last seen firing.
+func lastSeen(esc *models.AlertGroupEscalation) time.Time {
+	if esc.LastSeenAt.IsZero() {
+		return esc.EscalatedAt
+	}
+	return esc.LastSeenAt
+}
+
+// escalate notifies the contact point of the next step of the chain if its delay has passed.
+func (e *Escalator) escalate(ctx context.Context, logger log.Logger, chain *models.EscalationChain, esc *models.AlertGroupEscalation, group FiringAlertGroup, now time.Time) {
+	if esc.Acknowledged || esc.Step >= len(chain.Steps) {
+		return
+	}
+	step := chain.Steps[esc.Step]
+	if now.Before(esc.EscalatedAt.Add(step.Delay)) {
+		return
+	}
+
+	claimed, err := e.store.ClaimAlertGroupEscalationStep(ctx, esc.ID, esc.Step, esc.Step+1, now)
+	if err != nil {
+		logger.Error("Failed to claim escalation step", "error", err, "step", esc.Step+1)
+		return
+	}
+	if !claimed {
+		logger.Debug("Escalation step is notified by another instance or the alert group was acknowledged", "step", esc.Step+1)
+		return
+	}
+
+	if err := e.alertmanagers.NotifyReceiver(ctx, esc.OrgID, step.Receiver, esc.GroupKey, group.AlertGroup, now); err != nil {
+		logger.Error("Failed to notify contact point of escalation step", "error", err, "step", esc.Step+1, "receiver", step.Receiver)
+		// Release the step so that it is notified by the next sync.
+		if _, err := e.store.ClaimAlertGroupEscalationStep(ctx, esc.ID, esc.Step+1, esc.Step, esc.EscalatedAt); err != nil {
+			logger.Error("Failed to release escalation step", "error", err)
+		}
+		return
+	}
+	logger.Info("Escalated alert group", "step", esc.Step+1, "receiver", step.Receiver)
+	esc.Step++
+	esc.EscalatedAt = now
+}
+
+// GetFiringAlertGroups returns the alert groups of the organization with alerts that are neither silenced nor
+// inhibited, and the notification policies that created them.
+func (moa *MultiOrgAlertmanager) GetFiringAlertGroups(ctx context.Context, orgID int64) ([]FiringAlertGroup, error) {
+	orgAM, err := moa.AlertmanagerFor(orgID)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := orgAM.GetAlertGroups(ctx, true, false, false, nil, "")
+	if err != nil {
+		return nil, err
+	}
+
+	amConfig, err := moa.configStore.GetLatestAlertmanagerConfiguration(ctx, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest configuration: %w", err)
+	}
+	cfg, err := Load([]byte(amConfig.AlertmanagerConfiguration))
+	if err != nil {
+		return nil, err
+	}
+	if am, ok := orgAM.(*alertmanager); ok && am.withAutogen {
+		if err := AddAutogenConfig(ctx, moa.logger, moa.configStore, orgID, &cfg.AlertmanagerConfig, true); err != nil {
+			return nil, err
+		}
+	}
+	return firingAlertGroups(dispatch.NewRoute(cfg.AlertmanagerConfig.Route.AsAMRoute(), nil), groups), nil
+}
+
+// firingAlertGroups returns the alert groups with the IDs of the notification policies that created them. The
+// Alertmanager API does not return the policy of a group, so it is the first policy that routes the alerts of the
+// group to its contact point and groups them by the group labels, and that is not the policy of another group with
+// the same contact point and group labels.
+func firingAlertGroups(route *dispatch.Route, groups apimodels.AlertGroups) []FiringAlertGroup {
+	result := make([]FiringAlertGroup, 0, len(groups))
+	keys := make(map[string]struct{}, len(groups))
+	for _, group := range groups {
+		if group.Receiver == nil || group.Receiver.Name == nil {
+			continue
+		}
+		firing := FiringAlertGroup{AlertGroup: group}
+		if len(group.Alerts) > 0 {
+			lset := make(model.LabelSet, len(group.Alerts[0].Labels))
+			for k, v := range group.Alerts[0].Labels {
+				lset[model.LabelName(k)] = model.LabelValue(v)
+			}
+			for _, r := range route.Match(lset) {
+				if r.RouteOpts.Receiver != *group.Receiver.Name || !maps.Equal(labelSetToMap(groupLabels(lset, &r.RouteOpts)), map[string]string(group.Labels)) {
+					continue
+				}
+				candidate := FiringAlertGroup{AlertGroup: group, RouteID: r.ID()}
+				if _, ok := keys[candidate.Key()]; ok {
+					continue
+				}
+				firing = candidate
+				break
+			}
+		}
+		keys[firing.Key()] = struct{}{}
+		result = append(result, firing)
+	}
+	return result
+}
+
+// NotifyReceiver sends a notification of the alert group with the given key to the integrations of the contact point,
+// regardless of the notification policies. It is only supported by the internal Alertmanager.
+func (moa *MultiOrgAlertmanager) NotifyReceiver(ctx context.Context, orgID int64, receiver, groupKey string, group *apimodels.AlertGroup, now time.Time) error {
+	orgAM, err := moa.AlertmanagerFor(orgID)
+	if err != nil {
+		return err
+	}
+	am, ok := orgAM.(*alertmanager)
+	if !ok {
+		return fmt.Errorf("notifying a contact point is not supported by the Alertmanager of the organization")
+	}
+
+	amConfig, err := moa.configStore.GetLatestAlertmanagerConfiguration(ctx, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to get latest configuration: %w", err)
+	}
+	cfg, err := Load([]byte(amConfig.AlertmanagerConfiguration))
+	if err != nil {
+		return err
+	}
+	var apiReceiver *apimodels.PostableApiReceiver
+	for _, r := range cfg.AlertmanagerConfig.Receivers {
+		if r.Name == receiver {
+			apiReceiver = r
+			break
+		}
+	}
+	if apiReceiver == nil {
+		return ErrReceiverNotFound.Errorf("contact point %q does not exist", receiver)
+	}
+
+	tmpl, err := am.Base.GetTemplate()
+	if err != nil {
+		return err
+	}
+	integrations, err := am.buildReceiverIntegrations(PostableApiReceiverToApiReceiver(apiReceiver), tmpl)
+	if err != nil {
+		return err
+	}
+
+	groupLabels := make(model.LabelSet, len(group.Labels))
+	for k, v := range group.Labels {
+		groupLabels[model.LabelName(k)] = model.LabelValue(v)
+	}
+	alerts := make([]*types.Alert, 0, len(group.Alerts))
+	for _, a := range group.Alerts {
+		alerts = append(alerts, gettableAlertToAlert(a))
+	}
+	groupCtx := notify.WithGroupKey(ctx, groupKey+":"+receiver)
+	groupCtx = notify.WithReceiverName(groupCtx, receiver)
+	groupCtx = notify.WithGroupLabels(groupCtx, groupLabels)
+	groupCtx = notify.WithNow(groupCtx, now)
+
+	var errs []error
+	for _, integration := range integrations {
+		if _, err := integration.Notify(groupCtx, alerts...); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", integration.String(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func gettableAlertToAlert(a *apimodels.GettableAlert) *types.Alert {
+	alert := &types.Alert{
+		Alert: model.Alert{
+			Labels:       make(model.LabelSet, len(a.Labels)),
+			Annotations:  make(model.LabelSet, len(a.Annotations)),
+			GeneratorURL: a.GeneratorURL.String(),
+		},
+	}
+	for k, v := range a.Labels {
+		alert.Labels[model.LabelName(k)] = model.LabelValue(v)
+	}
+	for k, v := range a.Annotations {
+		alert.Annotations[model.LabelName(k)] = model.LabelValue(v)
+	}
+	if a.StartsAt != nil {
+		alert.StartsAt = time.Time(*a.StartsAt)
+	}
+	if a.EndsAt != nil {
+		alert.EndsAt = time.Time(*a.EndsAt)
+	}
+	if a.UpdatedAt != nil {
+		alert.UpdatedAt = time.Time(*a.UpdatedAt)
+	}
+	return alert
+}
@@ -0,0 +1,392 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/dispatch"
+	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func TestEscalatorSync(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2024, 6, 1, 2, 0, 0, 0, time.UTC)
+	newChain := func() *models.EscalationChain {
+		return &models.EscalationChain{
+			ID:       1,
+			UID:      "ops",
+			OrgID:    1,
+			Title:    "Ops",
+			Receiver: "team",
+			Steps: []models.EscalationStep{
+				{Receiver: "on-call", Delay: 5 * time.Minute},
+				{Receiver: "manager", Delay: 10 * time.Minute},
+			},
+		}
+	}
+	group := newFakeAlertGroup("team", map[string]string{"alertname": "HighLatency"})
+	key := models.AlertGroupKey("{}", "team", map[string]string{"alertname": "HighLatency"})
+
+	t.Run("notifies the contact points of the steps when their delays have passed", func(t *testing.T) {
+		clk := clock.NewMock()
+		clk.Set(start)
+		store := &fakeEscalationStore{chains: []*models.EscalationChain{newChain()}}
+		ams := &fakeEscalationAlertmanager{groups: map[int64][]FiringAlertGroup{1: {group}}}
+		sut := NewEscalator(store, ams, time.Minute, 10*time.Minute, clk)
+
+		require.NoError(t, sut.sync(ctx))
+		require.Len(t, store.escalations, 1)
+		require.Equal(t, key, store.escalations[0].GroupKey)
+		require.Equal(t, "ops", store.escalations[0].ChainUID)
+		require.Empty(t, ams.notified)
+
+		clk.Add(4 * time.Minute)
+		require.NoError(t, sut.sync(ctx))
+		require.Empty(t, ams.notified)
+
+		clk.Add(time.Minute)
+		require.NoError(t, sut.sync(ctx))
+		require.Equal(t, []string{"on-call"}, ams.notified)
+		require.Equal(t, 1, store.escalations[0].Step)
+		require.Equal(t, clk.Now(), ams.notifiedAt)
+
+		clk.Add(10 * time.Minute)
+		require.NoError(t, sut.sync(ctx))
+		require.Equal(t, []string{"on-call", "manager"}, ams.notified)
+
+		clk.Add(time.Hour)
+		require.NoError(t, sut.sync(ctx))
+		require.Equal(t, []string{"on-call", "manager"}, ams.notified)
+	})
+
+	t.Run("does not escalate acknowledged alert groups", func(t *testing.T) {
+		clk := clock.NewMock()
+		clk.Set(start)
+		store := &fakeEscalationStore{chains: []*models.EscalationChain{newChain()}}
+		ams := &fakeEscalationAlertmanager{groups: map[int64][]FiringAlertGroup{1: {group}}}
+		sut := NewEscalator(store, ams, time.Minute, 10*time.Minute, clk)
+
+		require.NoError(t, sut.sync(ctx))
+		ack, err := sut.Acknowledge(ctx, 1, "team", map[string]string{"alertname": "HighLatency"}, "admin", "looking into it")
+		require.NoError(t, err)
+		require.True(t, ack.Acknowledged)
+		require.Equal(t, "admin", ack.AcknowledgedBy)
+		require.Equal(t, "looking into it", ack.Comment)
+
+		clk.Add(time.Hour)
+		require.NoError(t, sut.sync(ctx))
+		require.Empty(t, ams.notified)
+
+		t.Run("and resumes once unacknowledged", func(t *testing.T) {
+			_, err := sut.Unacknowledge(ctx, 1, key)
+			require.NoError(t, err)
+			require.NoError(t, sut.sync(ctx))
+			require.Equal(t, []string{"on-call"}, ams.notified)
+		})
+	})
+
+	t.Run("does not notify a step claimed by another instance", func(t *testing.T) {
+		clk := clock.NewMock()
+		clk.Set(start)
+		store := &fakeEscalationStore{chains: []*models.EscalationChain{newChain()}}
+		ams := &fakeEscalationAlertmanager{groups: map[int64][]FiringAlertGroup{1: {group}}}
+		sut := NewEscalator(store, ams, time.Minute, 10*time.Minute, clk)
+
+		require.NoError(t, sut.sync(ctx))
+		store.claimed = true
+		clk.Add(time.Hour)
+		require.NoError(t, sut.sync(ctx))
+		require.Empty(t, ams.notified)
+	})
+
+	t.Run("releases the step if the contact point cannot be notified", func(t *testing.T) {
+		clk := clock.NewMock()
+		clk.Set(start)
+		store := &fakeEscalationStore{chains: []*models.EscalationChain{newChain()}}
+		ams := &fakeEscalationAlertmanager{groups: map[int64][]FiringAlertGroup{1: {group}}, err: errors.New("failed")}
+		sut := NewEscalator(store, ams, time.Minute, 10*time.Minute, clk)
+
+		require.NoError(t, sut.sync(ctx))
+		clk.Add(time.Hour)
+		require.NoError(t, sut.sync(ctx))
+		require.Zero(t, store.escalations[0].Step)
+		require.Equal(t, start, store.escalations[0].EscalatedAt)
+	})
+
+	t.Run("deletes the escalation of an alert group that no longer fires after the resolve timeout", func(t *testing.T) {
+		clk := clock.NewMock()
+		clk.Set(start)
+		store := &fakeEscalationStore{chains: []*models.EscalationChain{newChain()}}
+		ams := &fakeEscalationAlertmanager{groups: map[int64][]FiringAlertGroup{1: {group}}}
+		sut := NewEscalator(store, ams, time.Minute, 10*time.Minute, clk)
+
+		require.NoError(t, sut.sync(ctx))
+		require.Len(t, store.escalations, 1)
+		clk.Add(time.Minute)
+		require.NoError(t, sut.sync(ctx))
+		require.Equal(t, start.Add(time.Minute), store.escalations[0].LastSeenAt)
+
+		ams.groups[1] = nil
+		clk.Add(10 * time.Minute)
+		require.NoError(t, sut.sync(ctx))
+		require.Len(t, store.escalations, 1)
+
+		clk.Add(time.Minute)
+		require.NoError(t, sut.sync(ctx))
+		require.Empty(t, store.escalations)
+	})
+
+	t.Run("keeps the escalations when the Alertmanager has no alerts yet after a restart", func(t *testing.T) {
+		clk := clock.NewMock()
+		clk.Set(start)
+		store := &fakeEscalationStore{chains: []*models.EscalationChain{newChain()}}
+		store.escalations = []*models.AlertGroupEscalation{{
+			ID:          1,
+			OrgID:       1,
+			GroupKey:    key,
+			Receiver:    "team",
+			ChainUID:    "ops",
+			StartedAt:   start.Add(-time.Hour),
+			EscalatedAt: start.Add(-time.Hour),
+			LastSeenAt:  start.Add(-time.Minute),
+		}}
+		ams := &fakeEscalationAlertmanager{groups: map[int64][]FiringAlertGroup{1: nil}}
+		sut := NewEscalator(store, ams, time.Minute, 10*time.Minute, clk)
+
+		require.NoError(t, sut.sync(ctx))
+		require.Len(t, store.escalations, 1)
+
+		ams.groups[1] = []FiringAlertGroup{group}
+		require.NoError(t, sut.sync(ctx))
+		require.Equal(t, []string{"on-call"}, ams.notified)
+	})
+
+	t.Run("escalates alert groups of different notification policies separately", func(t *testing.T) {
+		clk := clock.NewMock()
+		clk.Set(start)
+		store := &fakeEscalationStore{chains: []*models.EscalationChain{newChain()}}
+		other := group
+		other.RouteID = "{}/{team=\"ops\"}/0"
+		ams := &fakeEscalationAlertmanager{groups: map[int64][]FiringAlertGroup{1: {group, other}}}
+		sut := NewEscalator(store, ams, time.Minute, 10*time.Minute, clk)
+
+		require.NoError(t, sut.sync(ctx))
+		require.Len(t, store.escalations, 2)
+		require.NotEqual(t, store.escalations[0].GroupKey, store.escalations[1].GroupKey)
+	})
+
+	t.Run("keeps the escalations if the alert groups are not known", func(t *testing.T) {
+		clk := clock.NewMock()
+		clk.Set(start)
+		store := &fakeEscalationStore{chains: []*models.EscalationChain{newChain()}}
+		ams := &fakeEscalationAlertmanager{groups: map[int64][]FiringAlertGroup{1: {group}}}
+		sut := NewEscalator(store, ams, time.Minute, 10*time.Minute, clk)
+
+		require.NoError(t, sut.sync(ctx))
+		delete(ams.groups, 1)
+		require.NoError(t, sut.sync(ctx))
+		require.Len(t, store.escalations, 1)
+	})
+
+	t.Run("does not escalate alert groups of other contact points", func(t *testing.T) {
+		clk := clock.NewMock()
+		clk.Set(start)
+		store := &fakeEscalationStore{chains: []*models.EscalationChain{newChain()}}
+		other := newFakeAlertGroup("other", map[string]string{"alertname": "HighLatency"})
+		ams := &fakeEscalationAlertmanager{groups: map[int64][]FiringAlertGroup{1: {other}}}
+		sut := NewEscalator(store, ams, time.Minute, 10*time.Minute, clk)
+
+		require.NoError(t, sut.sync(ctx))
+		require.Empty(t, store.escalations)
+	})
+}
+
+func TestEscalatorAcknowledgementNotFound(t *testing.T) {
+	ams := &fakeEscalationAlertmanager{groups: map[int64][]FiringAlertGroup{1: nil}}
+	sut := NewEscalator(&fakeEscalationStore{}, ams, time.Minute, 10*time.Minute, clock.NewMock())
+	_, err := sut.Acknowledge(context.Background(), 1, "team", map[string]string{"alertname": "HighLatency"}, "admin", "")
+	require.ErrorIs(t, err, ErrAlertGroupEscalationNotFound)
+
+	_, err = sut.Unacknowledge(context.Background(), 1, "unknown")
+	require.ErrorIs(t, err, ErrAlertGroupEscalationNotFound)
+}
+
+func TestFiringAlertGroups(t *testing.T) {
+	cfg, err := Load([]byte(`{
+		"alertmanager_config": {
+			"route": {
+				"receiver": "team",
+				"group_by": ["alertname"],
+				"routes": [
+					{"receiver": "team", "object_matchers": [["severity", "=", "critical"]], "continue": true},
+					{"receiver": "team", "object_matchers": [["service", "=", "db"]]}
+				]
+			},
+			"receivers": [{"name": "team"}]
+		}
+	}`))
+	require.NoError(t, err)
+	route := dispatch.NewRoute(cfg.AlertmanagerConfig.Route.AsAMRoute(), nil)
+
+	newGroup := func(alertLabels map[string]string) *apimodels.AlertGroup {
+		return &apimodels.AlertGroup{
+			Receiver: &amv2.Receiver{Name: util.Pointer("team")},
+			Labels:   map[string]string{"alertname": alertLabels["alertname"]},
+			Alerts:   []*apimodels.GettableAlert{{Alert: amv2.Alert{Labels: alertLabels}}},
+		}
+	}
+	// An alert that matches both nested policies is in one group of each.
+	alert := map[string]string{"alertname": "HighLatency", "severity": "critical", "service": "db"}
+	result := firingAlertGroups(route, apimodels.AlertGroups{newGroup(alert), newGroup(alert), newGroup(map[string]string{"alertname": "HighLatency"})})
+	require.Len(t, result, 3)
+	require.Equal(t, route.Routes[0].ID(), result[0].RouteID)
+	require.Equal(t, route.Routes[1].ID(), result[1].RouteID)
+	require.Equal(t, route.ID(), result[2].RouteID)
+	require.NotEqual(t, result[0].Key(), result[1].Key())
+}
+
+func newFakeAlertGroup(receiver string, groupLabels map[string]string) FiringAlertGroup {
+	return FiringAlertGroup{
+		AlertGroup: &apimodels.AlertGroup{
+			Receiver: &amv2.Receiver{Name: util.Pointer(receiver)},
+			Labels:   groupLabels,
+			Alerts:   []*apimodels.GettableAlert{},
+		},
+		RouteID: "{}",
+	}
+}
+
+type fakeEscalationStore struct {
+	chains      []*models.EscalationChain
+	escalations []*models.AlertGroupEscalation
+	// claimed simulates another instance that claims all steps first.
+	claimed bool
+}
+
+func (f *fakeEscalationStore) ListEscalationChains(_ context.Context, orgID int64) ([]*models.EscalationChain, error) {
+	var result []*models.EscalationChain
+	for _, c := range f.chains {
+		if c.OrgID == orgID {
+			result = append(result, c)
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeEscalationStore) ListAllEscalationChains(_ context.Context) ([]*models.EscalationChain, error) {
+	return f.chains, nil
+}
+
+func (f *fakeEscalationStore) ListAlertGroupEscalations(_ context.Context, orgID int64) ([]*models.AlertGroupEscalation, error) {
+	var result []*models.AlertGroupEscalation
+	for _, e := range f.escalations {
+		if orgID == 0 || e.OrgID == orgID {
+			c := *e
+			result = append(result, &c)
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeEscalationStore) GetAlertGroupEscalation(_ context.Context, orgID int64, groupKey string) (*models.AlertGroupEscalation, error) {
+	for _, e := range f.escalations {
+		if e.OrgID == orgID && e.GroupKey == groupKey {
+			c := *e
+			return &c, nil
+		}
+	}
+	return nil, models.ErrAlertGroupEscalationNotFound
+}
+
+func (f *fakeEscalationStore) InsertAlertGroupEscalation(_ context.Context, escalation *models.AlertGroupEscalation) (bool, error) {
+	for _, e := range f.escalations {
+		if e.OrgID == escalation.OrgID && e.GroupKey == escalation.GroupKey {
+			return false, nil
+		}
+	}
+	c := *escalation
+	c.ID = int64(len(f.escalations) + 1)
+	f.escalations = append(f.escalations, &c)
+	return true, nil
+}
+
+func (f *fakeEscalationStore) ClaimAlertGroupEscalationStep(_ context.Context, id int64, previous, step int, at time.Time) (bool, error) {
+	if f.claimed {
+		return false, nil
+	}
+	for _, e := range f.escalations {
+		if e.ID == id && e.Step == previous && !e.Acknowledged {
+			e.Step = step
+			e.EscalatedAt = at
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakeEscalationStore) SetAlertGroupAcknowledgement(_ context.Context, ack *models.AlertGroupEscalation) error {
+	for _, e := range f.escalations {
+		if e.OrgID == ack.OrgID && e.GroupKey == ack.GroupKey {
+			e.Acknowledged = ack.Acknowledged
+			e.AcknowledgedBy = ack.AcknowledgedBy
+			e.AcknowledgedAt = ack.AcknowledgedAt
+			e.Comment = ack.Comment
+			return nil
+		}
+	}
+	return models.ErrAlertGroupEscalationNotFound
+}
+
+func (f *fakeEscalationStore) SetAlertGroupEscalationsSeen(_ context.Context, at time.Time, ids ...int64) error {
+	for _, e := range f.escalations {
+		if slices.Contains(ids, e.ID) {
+			e.LastSeenAt = at
+		}
+	}
+	return nil
+}
+
+func (f *fakeEscalationStore) DeleteAlertGroupEscalations(_ context.Context, notSeenSince time.Time, ids ...int64) error {
+	result := f.escalations[:0]
+	for _, e := range f.escalations {
+		deleted := slices.Contains(ids, e.ID) && e.LastSeenAt.Before(notSeenSince)
+		if !deleted {
+			result = append(result, e)
+		}
+	}
+	f.escalations = result
+	return nil
+}
+
+type fakeEscalationAlertmanager struct {
+	groups     map[int64][]FiringAlertGroup
+	notified   []string
+	notifiedAt time.Time
+	err        error
+}
+
+func (f *fakeEscalationAlertmanager) GetFiringAlertGroups(_ context.Context, orgID int64) ([]FiringAlertGroup, error) {
+	groups, ok := f.groups[orgID]
+	if !ok {
+		return nil, ErrAlertmanagerNotReady
+	}
+	return groups, nil
+}
+
+func (f *fakeEscalationAlertmanager) NotifyReceiver(_ context.Context, _ int64, receiver, _ string, _ *apimodels.AlertGroup, now time.Time) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.notified = append(f.notified, receiver)
+	f.notifiedAt = now
+	return nil
+}
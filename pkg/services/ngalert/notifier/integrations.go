@@ -0,0 +1,119 @@
+package notifier
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/alerting/images"
+	alertingNotify "github.com/grafana/alerting/notify"
+	"github.com/grafana/alerting/receivers"
+	alertingTemplates "github.com/grafana/alerting/templates"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/integrations/matrix"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/integrations/zulip"
+)
+
+// matrixThreadsTTL is how long the first message of an alert group is replied to after the last notification of the group.
+const matrixThreadsTTL = 7 * 24 * time.Hour
+
+// grafanaReceiverConfig holds the parsed configurations of the integrations that are implemented in Grafana
+// rather than in the alerting library.
+type grafanaReceiverConfig struct {
+	MatrixConfigs []*alertingNotify.NotifierConfig[matrix.Config]
+	ZulipConfigs  []*alertingNotify.NotifierConfig[zulip.Config]
+}
+
+func isGrafanaIntegration(integrationType string) bool {
+	switch strings.ToLower(integrationType) {
+	case matrix.Type, zulip.Type:
+		return true
+	}
+	return false
+}
+
+// ValidateReceiverConfiguration returns an error if an integration of the receiver is not valid.
+func ValidateReceiverConfiguration(ctx context.Context, api *alertingNotify.APIReceiver, decrypt alertingNotify.GetDecryptedValueFn) error {
+	_, _, err := buildReceiverConfiguration(ctx, api, decrypt)
+	return err
+}
+
+// buildReceiverConfiguration parses the integrations of the receiver, both those of the alerting library and those
+// implemented in Grafana.
+func buildReceiverConfiguration(ctx context.Context, api *alertingNotify.APIReceiver, decrypt alertingNotify.GetDecryptedValueFn) (alertingNotify.GrafanaReceiverConfig, grafanaReceiverConfig, error) {
+	libReceiver := *api
+	libReceiver.Integrations = make([]*alertingNotify.GrafanaIntegrationConfig, 0, len(api.Integrations))
+	var result grafanaReceiverConfig
+	for _, integration := range api.Integrations {
+		if !isGrafanaIntegration(integration.Type) {
+			libReceiver.Integrations = append(libReceiver.Integrations, integration)
+			continue
+		}
+		if err := parseGrafanaIntegration(ctx, &result, integration, decrypt); err != nil {
+			return alertingNotify.GrafanaReceiverConfig{}, grafanaReceiverConfig{}, alertingNotify.IntegrationValidationError{
+				Integration: integration,
+				Err:         err,
+			}
+		}
+	}
+	libResult, err := alertingNotify.BuildReceiverConfiguration(ctx, &libReceiver, decrypt)
+	if err != nil {
+		return alertingNotify.GrafanaReceiverConfig{}, grafanaReceiverConfig{}, err
+	}
+	return libResult, result, nil
+}
+
+func parseGrafanaIntegration(ctx context.Context, result *grafanaReceiverConfig, integration *alertingNotify.GrafanaIntegrationConfig, decrypt alertingNotify.GetDecryptedValueFn) error {
+	secureSettings := make(map[string][]byte, len(integration.SecureSettings))
+	for k, v := range integration.SecureSettings {
+		// Secure settings that are not base-64 encoded are used as they are, like in the alerting library.
+		if d, err := base64.StdEncoding.DecodeString(v); err == nil {
+			secureSettings[k] = d
+		} else {
+			secureSettings[k] = []byte(v)
+		}
+	}
+	decryptFn := func(key string, fallback string) string {
+		return decrypt(ctx, secureSettings, key, fallback)
+	}
+	meta := receivers.Metadata{
+		UID:                   integration.UID,
+		Name:                  integration.Name,
+		Type:                  integration.Type,
+		DisableResolveMessage: integration.DisableResolveMessage,
+	}
+
+	switch strings.ToLower(integration.Type) {
+	case matrix.Type:
+		cfg, err := matrix.NewConfig(integration.Settings, decryptFn)
+		if err != nil {
+			return err
+		}
+		result.MatrixConfigs = append(result.MatrixConfigs, &alertingNotify.NotifierConfig[matrix.Config]{Metadata: meta, Settings: cfg})
+	case zulip.Type:
+		cfg, err := zulip.NewConfig(integration.Settings, decryptFn)
+		if err != nil {
+			return err
+		}
+		result.ZulipConfigs = append(result.ZulipConfigs, &alertingNotify.NotifierConfig[zulip.Config]{Metadata: meta, Settings: cfg})
+	default:
+		return fmt.Errorf("notifier %s is not supported", integration.Type)
+	}
+	return nil
+}
+
+// buildGrafanaIntegrations builds the integrations that are implemented in Grafana.
+func buildGrafanaIntegrations(cfg grafanaReceiverConfig, tmpl *alertingTemplates.Template, img images.Provider, sender receivers.WebhookSender, threads matrix.Threads) []*alertingNotify.Integration {
+	integrations := make([]*alertingNotify.Integration, 0, len(cfg.MatrixConfigs)+len(cfg.ZulipConfigs))
+	for i, c := range cfg.MatrixConfigs {
+		n := matrix.New(c.Settings, c.Metadata, tmpl, sender, img, threads, LoggerFactory("ngalert.notifier."+c.Type, "notifierUID", c.UID))
+		integrations = append(integrations, alertingNotify.NewIntegration(n, n, c.Type, i, c.Name))
+	}
+	for i, c := range cfg.ZulipConfigs {
+		n := zulip.New(c.Settings, c.Metadata, tmpl, sender, img, LoggerFactory("ngalert.notifier."+c.Type, "notifierUID", c.UID))
+		integrations = append(integrations, alertingNotify.NewIntegration(n, n, c.Type, i, c.Name))
+	}
+	return integrations
+}
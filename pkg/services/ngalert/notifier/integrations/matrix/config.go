@@ -0,0 +1,66 @@
+package matrix
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/templates"
+)
+
+const (
+	// MessageFormatText sends the message as plain text, with the title in bold.
+	MessageFormatText = "text"
+	// MessageFormatHTML sends the message as HTML that Matrix clients render.
+	MessageFormatHTML = "html"
+)
+
+type Config struct {
+	HomeserverURL string `json:"homeserverUrl,omitempty" yaml:"homeserverUrl,omitempty"`
+	AccessToken   string `json:"accessToken,omitempty" yaml:"accessToken,omitempty"`
+	RoomID        string `json:"roomId,omitempty" yaml:"roomId,omitempty"`
+	Title         string `json:"title,omitempty" yaml:"title,omitempty"`
+	Message       string `json:"message,omitempty" yaml:"message,omitempty"`
+	MessageFormat string `json:"messageFormat,omitempty" yaml:"messageFormat,omitempty"`
+	// DisableThreads sends every notification as a new message instead of a reply to the first notification of the alert group.
+	DisableThreads bool `json:"disableThreads,omitempty" yaml:"disableThreads,omitempty"`
+}
+
+func NewConfig(jsonData json.RawMessage, decryptFn receivers.DecryptFunc) (Config, error) {
+	settings := Config{}
+	if err := json.Unmarshal(jsonData, &settings); err != nil {
+		return settings, fmt.Errorf("failed to unmarshal settings: %w", err)
+	}
+	if settings.HomeserverURL == "" {
+		return settings, errors.New("could not find homeserver URL in settings")
+	}
+	if _, err := url.ParseRequestURI(settings.HomeserverURL); err != nil {
+		return settings, fmt.Errorf("invalid homeserver URL: %w", err)
+	}
+	settings.HomeserverURL = strings.TrimSuffix(settings.HomeserverURL, "/")
+	settings.AccessToken = decryptFn("accessToken", settings.AccessToken)
+	if settings.AccessToken == "" {
+		return settings, errors.New("could not find access token in settings")
+	}
+	if settings.RoomID == "" {
+		return settings, errors.New("could not find room ID in settings")
+	}
+	if settings.Title == "" {
+		settings.Title = templates.DefaultMessageTitleEmbed
+	}
+	if settings.Message == "" {
+		settings.Message = templates.DefaultMessageEmbed
+	}
+	switch strings.ToLower(settings.MessageFormat) {
+	case "", MessageFormatText:
+		settings.MessageFormat = MessageFormatText
+	case MessageFormatHTML:
+		settings.MessageFormat = MessageFormatHTML
+	default:
+		return settings, fmt.Errorf("unknown message format %q, must be %s or %s", settings.MessageFormat, MessageFormatText, MessageFormatHTML)
+	}
+	return settings, nil
+}
@@ -0,0 +1,102 @@
+package matrix
+
+import (
+	"encoding/json"
+	"testing"
+
+	receiversTesting "github.com/grafana/alerting/receivers/testing"
+	"github.com/grafana/alerting/templates"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewConfig(t *testing.T) {
+	cases := []struct {
+		name              string
+		settings          string
+		secureSettings    map[string][]byte
+		expectedConfig    Config
+		expectedInitError string
+	}{
+		{
+			name:              "Error if empty",
+			settings:          "",
+			expectedInitError: `failed to unmarshal settings`,
+		},
+		{
+			name:              "Error if homeserver URL is missing",
+			settings:          `{ "accessToken": "token", "roomId": "!room:localhost" }`,
+			expectedInitError: `could not find homeserver URL in settings`,
+		},
+		{
+			name:              "Error if homeserver URL is invalid",
+			settings:          `{ "homeserverUrl": "localhost", "accessToken": "token", "roomId": "!room:localhost" }`,
+			expectedInitError: `invalid homeserver URL`,
+		},
+		{
+			name:              "Error if access token is missing",
+			settings:          `{ "homeserverUrl": "http://localhost:8008", "roomId": "!room:localhost" }`,
+			expectedInitError: `could not find access token in settings`,
+		},
+		{
+			name:              "Error if room ID is missing",
+			settings:          `{ "homeserverUrl": "http://localhost:8008", "accessToken": "token" }`,
+			expectedInitError: `could not find room ID in settings`,
+		},
+		{
+			name:              "Error if message format is unknown",
+			settings:          `{ "homeserverUrl": "http://localhost:8008", "accessToken": "token", "roomId": "!room:localhost", "messageFormat": "markdown" }`,
+			expectedInitError: `unknown message format "markdown"`,
+		},
+		{
+			name:     "Minimal valid configuration",
+			settings: `{ "homeserverUrl": "http://localhost:8008/", "accessToken": "token", "roomId": "!room:localhost" }`,
+			expectedConfig: Config{
+				HomeserverURL: "http://localhost:8008",
+				AccessToken:   "token",
+				RoomID:        "!room:localhost",
+				Title:         templates.DefaultMessageTitleEmbed,
+				Message:       templates.DefaultMessageEmbed,
+				MessageFormat: MessageFormatText,
+			},
+		},
+		{
+			name:           "Access token from secrets",
+			settings:       `{ "homeserverUrl": "http://localhost:8008", "accessToken": "token", "roomId": "!room:localhost", "messageFormat": "HTML" }`,
+			secureSettings: map[string][]byte{"accessToken": []byte("secret-token")},
+			expectedConfig: Config{
+				HomeserverURL: "http://localhost:8008",
+				AccessToken:   "secret-token",
+				RoomID:        "!room:localhost",
+				Title:         templates.DefaultMessageTitleEmbed,
+				Message:       templates.DefaultMessageEmbed,
+				MessageFormat: MessageFormatHTML,
+			},
+		},
+		{
+			name:           "All fields from the full valid configuration and secrets",
+			settings:       FullValidConfigForTesting,
+			secureSettings: receiversTesting.ReadSecretsJSONForTesting(FullValidSecretsForTesting),
+			expectedConfig: Config{
+				HomeserverURL:  "http://localhost:8008",
+				AccessToken:    "test-secret-token",
+				RoomID:         "!room:localhost",
+				Title:          "test-title",
+				Message:        "test-message",
+				MessageFormat:  MessageFormatHTML,
+				DisableThreads: true,
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			actual, err := NewConfig(json.RawMessage(c.settings), receiversTesting.DecryptForTesting(c.secureSettings))
+			if c.expectedInitError != "" {
+				require.ErrorContains(t, err, c.expectedInitError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.expectedConfig, actual)
+		})
+	}
+}
@@ -0,0 +1,231 @@
+package matrix
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"mime"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/alerting/images"
+	"github.com/grafana/alerting/logging"
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/templates"
+)
+
+// Type is the type of the Matrix integration.
+const Type = "matrix"
+
+var (
+	// newTransactionID returns the ID that makes the homeserver send a message only once. It can be overwritten in tests.
+	newTransactionID = uuid.NewString
+)
+
+// Notifier is responsible for sending alert notifications to a Matrix room.
+// It uses two endpoints of the Matrix client-server API:
+// - https://spec.matrix.org/latest/client-server-api/#put_matrixclientv3roomsroomidsendeventtypetxnid for sending messages
+// - https://spec.matrix.org/latest/client-server-api/#post_matrixmediav3upload for uploading images (only if alerts contain references to them)
+// The notifications of an alert group after the first one are sent as replies to the first one, until the group is resolved.
+type Notifier struct {
+	*receivers.Base
+	log      logging.Logger
+	ns       receivers.WebhookSender
+	images   images.Provider
+	tmpl     *templates.Template
+	threads  Threads
+	settings Config
+}
+
+// New is the constructor for the Matrix notifier.
+func New(cfg Config, meta receivers.Metadata, template *templates.Template, sender receivers.WebhookSender, images images.Provider, threads Threads, logger logging.Logger) *Notifier {
+	return &Notifier{
+		Base:     receivers.NewBase(meta),
+		log:      logger,
+		ns:       sender,
+		images:   images,
+		tmpl:     template,
+		threads:  threads,
+		settings: cfg,
+	}
+}
+
+type event struct {
+	MsgType       string     `json:"msgtype"`
+	Body          string     `json:"body"`
+	Format        string     `json:"format,omitempty"`
+	FormattedBody string     `json:"formatted_body,omitempty"`
+	URL           string     `json:"url,omitempty"`
+	RelatesTo     *relatesTo `json:"m.relates_to,omitempty"`
+}
+
+type relatesTo struct {
+	InReplyTo inReplyTo `json:"m.in_reply_to"`
+}
+
+type inReplyTo struct {
+	EventID string `json:"event_id"`
+}
+
+// Notify sends an alert notification to Matrix.
+func (n *Notifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	key, err := notify.ExtractGroupKey(ctx)
+	if err != nil {
+		return false, err
+	}
+	threadKey := n.UID + "/" + key.Hash()
+
+	var tmplErr error
+	tmpl, _ := templates.TmplText(ctx, n.tmpl, as, n.log, &tmplErr)
+	msg := n.buildMessage(tmpl(n.settings.Title), tmpl(n.settings.Message))
+	if tmplErr != nil {
+		n.log.Warn("failed to template Matrix message", "error", tmplErr)
+	}
+
+	var threadID string
+	if !n.settings.DisableThreads {
+		threadID, _ = n.threads.Get(threadKey)
+		msg.RelatesTo = replyTo(threadID)
+	}
+	eventID, err := n.sendEvent(ctx, msg)
+	if err != nil {
+		return false, fmt.Errorf("failed to send Matrix message: %w", err)
+	}
+	if threadID == "" {
+		threadID = eventID
+	}
+
+	_ = images.WithStoredImages(ctx, n.log, n.images, func(_ int, image images.Image) error {
+		if image.Path == "" {
+			return nil
+		}
+		contentURI, err := n.upload(ctx, image.Path)
+		if err != nil {
+			return fmt.Errorf("failed to upload image to Matrix: %w", err)
+		}
+		img := event{MsgType: "m.image", Body: filepath.Base(image.Path), URL: contentURI}
+		if !n.settings.DisableThreads {
+			img.RelatesTo = replyTo(threadID)
+		}
+		if _, err := n.sendEvent(ctx, img); err != nil {
+			return fmt.Errorf("failed to send image to Matrix: %w", err)
+		}
+		return nil
+	}, as...)
+
+	if !n.settings.DisableThreads {
+		// The next notification of a resolved group starts a new thread.
+		if types.Alerts(as...).Status() == model.AlertResolved {
+			n.threads.Delete(threadKey)
+		} else {
+			n.threads.Set(threadKey, threadID)
+		}
+	}
+	return true, nil
+}
+
+func (n *Notifier) buildMessage(title, message string) event {
+	formatted := message
+	if n.settings.MessageFormat == MessageFormatText {
+		formatted = strings.ReplaceAll(html.EscapeString(message), "\n", "<br>")
+	}
+	return event{
+		MsgType:       "m.text",
+		Body:          title + "\n\n" + message,
+		Format:        "org.matrix.custom.html",
+		FormattedBody: "<strong>" + html.EscapeString(title) + "</strong><br><br>" + formatted,
+	}
+}
+
+func replyTo(eventID string) *relatesTo {
+	if eventID == "" {
+		return nil
+	}
+	return &relatesTo{InReplyTo: inReplyTo{EventID: eventID}}
+}
+
+// sendEvent sends the event to the room and returns the ID of the event.
+func (n *Notifier) sendEvent(ctx context.Context, e event) (string, error) {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return "", err
+	}
+	var res struct {
+		EventID string `json:"event_id"`
+	}
+	cmd := &receivers.SendWebhookSettings{
+		URL:        fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/send/m.room.message/%s", n.settings.HomeserverURL, url.PathEscape(n.settings.RoomID), url.PathEscape(newTransactionID())),
+		Body:       string(body),
+		HTTPMethod: "PUT",
+		HTTPHeader: n.authorization(),
+		Validation: validateResponse(&res),
+	}
+	if err := n.ns.SendWebhook(ctx, cmd); err != nil {
+		return "", err
+	}
+	return res.EventID, nil
+}
+
+// upload uploads the file to the media repository of the homeserver and returns its content URI.
+func (n *Notifier) upload(ctx context.Context, path string) (string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read image: %w", err)
+	}
+	contentType := mime.TypeByExtension(filepath.Ext(path))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	var res struct {
+		ContentURI string `json:"content_uri"`
+	}
+	cmd := &receivers.SendWebhookSettings{
+		URL:         fmt.Sprintf("%s/_matrix/media/v3/upload?filename=%s", n.settings.HomeserverURL, url.QueryEscape(filepath.Base(path))),
+		Body:        string(b),
+		HTTPMethod:  "POST",
+		HTTPHeader:  n.authorization(),
+		ContentType: contentType,
+		Validation:  validateResponse(&res),
+	}
+	if err := n.ns.SendWebhook(ctx, cmd); err != nil {
+		return "", err
+	}
+	return res.ContentURI, nil
+}
+
+func (n *Notifier) authorization() map[string]string {
+	return map[string]string{"Authorization": "Bearer " + n.settings.AccessToken}
+}
+
+// validateResponse returns the error of the Matrix API, or unmarshals the successful response into res.
+func validateResponse(res any) func(body []byte, statusCode int) error {
+	return func(body []byte, statusCode int) error {
+		if statusCode/100 != 2 {
+			var apiErr struct {
+				ErrCode string `json:"errcode"`
+				Error   string `json:"error"`
+			}
+			if err := json.Unmarshal(body, &apiErr); err == nil && apiErr.ErrCode != "" {
+				return fmt.Errorf("%s: %s", apiErr.ErrCode, apiErr.Error)
+			}
+			return fmt.Errorf("unexpected status code %d", statusCode)
+		}
+		if err := json.Unmarshal(body, res); err != nil {
+			return errors.New("failed to parse the response of the Matrix API")
+		}
+		return nil
+	}
+}
+
+func (n *Notifier) SendResolved() bool {
+	return !n.GetDisableResolveMessage()
+}
@@ -0,0 +1,157 @@
+package matrix
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alerting/images"
+	"github.com/grafana/alerting/logging"
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/templates"
+)
+
+// fakeHomeserver records the requests and answers them like a Matrix homeserver.
+type fakeHomeserver struct {
+	calls []receivers.SendWebhookSettings
+}
+
+func (f *fakeHomeserver) SendWebhook(_ context.Context, cmd *receivers.SendWebhookSettings) error {
+	f.calls = append(f.calls, *cmd)
+	body := fmt.Sprintf(`{"event_id":"$event-%d"}`, len(f.calls))
+	if strings.Contains(cmd.URL, "/_matrix/media/") {
+		body = fmt.Sprintf(`{"content_uri":"mxc://localhost/media-%d"}`, len(f.calls))
+	}
+	return cmd.Validation([]byte(body), 200)
+}
+
+func (f *fakeHomeserver) event(t *testing.T, i int) event {
+	t.Helper()
+	var e event
+	require.NoError(t, json.Unmarshal([]byte(f.calls[i].Body), &e))
+	return e
+}
+
+func TestNotify(t *testing.T) {
+	newTransactionID = func() string { return "txn" }
+	t.Cleanup(func() { newTransactionID = uuid.NewString })
+
+	tmpl := templates.ForTests(t)
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	settings := Config{
+		HomeserverURL: "http://localhost:8008",
+		AccessToken:   "token",
+		RoomID:        "!room:localhost",
+		Title:         "{{ .Status }} {{ .CommonLabels.alertname }}",
+		Message:       "<b>{{ len .Alerts }}</b> alerts",
+		MessageFormat: MessageFormatText,
+	}
+	firing := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "alert1"}, StartsAt: time.Now()}}
+	resolved := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "alert1"}, StartsAt: time.Now().Add(-time.Hour), EndsAt: time.Now().Add(-time.Minute)}}
+	ctx := notify.WithGroupKey(context.Background(), "alertname")
+	ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": "alert1"})
+
+	t.Run("sends the message to the room", func(t *testing.T) {
+		server := &fakeHomeserver{}
+		n := New(settings, receivers.Metadata{UID: "uid"}, tmpl, server, &images.UnavailableProvider{}, NewMemoryThreads(time.Hour), &logging.FakeLogger{})
+
+		ok, err := n.Notify(ctx, firing)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		require.Len(t, server.calls, 1)
+		require.Equal(t, "http://localhost:8008/_matrix/client/v3/rooms/%21room:localhost/send/m.room.message/txn", server.calls[0].URL)
+		require.Equal(t, "PUT", server.calls[0].HTTPMethod)
+		require.Equal(t, map[string]string{"Authorization": "Bearer token"}, server.calls[0].HTTPHeader)
+		require.Equal(t, event{
+			MsgType:       "m.text",
+			Body:          "firing alert1\n\n<b>1</b> alerts",
+			Format:        "org.matrix.custom.html",
+			FormattedBody: "<strong>firing alert1</strong><br><br>&lt;b&gt;1&lt;/b&gt; alerts",
+		}, server.event(t, 0))
+	})
+
+	t.Run("sends the message as HTML", func(t *testing.T) {
+		server := &fakeHomeserver{}
+		cfg := settings
+		cfg.MessageFormat = MessageFormatHTML
+		n := New(cfg, receivers.Metadata{UID: "uid"}, tmpl, server, &images.UnavailableProvider{}, NewMemoryThreads(time.Hour), &logging.FakeLogger{})
+
+		_, err := n.Notify(ctx, firing)
+		require.NoError(t, err)
+		require.Equal(t, "<strong>firing alert1</strong><br><br><b>1</b> alerts", server.event(t, 0).FormattedBody)
+	})
+
+	t.Run("replies to the first notification of the group until it is resolved", func(t *testing.T) {
+		server := &fakeHomeserver{}
+		n := New(settings, receivers.Metadata{UID: "uid"}, tmpl, server, &images.UnavailableProvider{}, NewMemoryThreads(time.Hour), &logging.FakeLogger{})
+
+		for _, a := range []*types.Alert{firing, firing, resolved, firing} {
+			_, err := n.Notify(ctx, a)
+			require.NoError(t, err)
+		}
+
+		require.Len(t, server.calls, 4)
+		require.Nil(t, server.event(t, 0).RelatesTo)
+		require.Equal(t, replyTo("$event-1"), server.event(t, 1).RelatesTo)
+		require.Equal(t, replyTo("$event-1"), server.event(t, 2).RelatesTo)
+		require.Nil(t, server.event(t, 3).RelatesTo)
+	})
+
+	t.Run("does not reply if threads are disabled", func(t *testing.T) {
+		server := &fakeHomeserver{}
+		cfg := settings
+		cfg.DisableThreads = true
+		n := New(cfg, receivers.Metadata{UID: "uid"}, tmpl, server, &images.UnavailableProvider{}, NewMemoryThreads(time.Hour), &logging.FakeLogger{})
+
+		for i := 0; i < 2; i++ {
+			_, err := n.Notify(ctx, firing)
+			require.NoError(t, err)
+		}
+		require.Nil(t, server.event(t, 1).RelatesTo)
+	})
+
+	t.Run("uploads the images of the alerts and sends them in the thread", func(t *testing.T) {
+		server := &fakeHomeserver{}
+		n := New(settings, receivers.Metadata{UID: "uid"}, tmpl, server, images.NewFakeProviderWithFile(t, 1), NewMemoryThreads(time.Hour), &logging.FakeLogger{})
+		withImage := &types.Alert{Alert: model.Alert{
+			Labels:      model.LabelSet{"alertname": "alert1"},
+			Annotations: model.LabelSet{"__alertImageToken__": "test-image-1"},
+			StartsAt:    time.Now(),
+		}}
+
+		_, err := n.Notify(ctx, withImage)
+		require.NoError(t, err)
+
+		require.Len(t, server.calls, 3)
+		require.True(t, strings.HasPrefix(server.calls[1].URL, "http://localhost:8008/_matrix/media/v3/upload?filename=test-image-"))
+		require.Equal(t, "image/png", server.calls[1].ContentType)
+		img := server.event(t, 2)
+		require.Equal(t, "m.image", img.MsgType)
+		require.Equal(t, "mxc://localhost/media-2", img.URL)
+		require.Equal(t, replyTo("$event-1"), img.RelatesTo)
+	})
+
+	t.Run("returns the error of the Matrix API", func(t *testing.T) {
+		sender := receivers.MockNotificationService()
+		sender.ShouldError = validateResponse(nil)([]byte(`{"errcode":"M_FORBIDDEN","error":"not in room"}`), 403)
+		n := New(settings, receivers.Metadata{UID: "uid"}, tmpl, sender, &images.UnavailableProvider{}, NewMemoryThreads(time.Hour), &logging.FakeLogger{})
+
+		ok, err := n.Notify(ctx, firing)
+		require.ErrorContains(t, err, "M_FORBIDDEN: not in room")
+		require.False(t, ok)
+	})
+}
@@ -0,0 +1,17 @@
+package matrix
+
+// FullValidConfigForTesting is a string representation of a JSON object that contains all fields supported by the notifier Config. It can be used without secrets.
+const FullValidConfigForTesting = `{
+	"homeserverUrl": "http://localhost:8008",
+	"accessToken": "test-token",
+	"roomId": "!room:localhost",
+	"title": "test-title",
+	"message": "test-message",
+	"messageFormat": "html",
+	"disableThreads": true
+}`
+
+// FullValidSecretsForTesting is a string representation of JSON object that contains all fields that can be overridden from secrets
+const FullValidSecretsForTesting = `{
+	"accessToken": "test-secret-token"
+}`
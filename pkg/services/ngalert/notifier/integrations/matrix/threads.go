@@ -0,0 +1,65 @@
+package matrix
+
+import (
+	"sync"
+	"time"
+)
+
+// Threads keeps the ID of the first message sent for each alert group, so that the following notifications of the
+// group can reply to it.
+type Threads interface {
+	Get(key string) (string, bool)
+	Set(key, eventID string)
+	Delete(key string)
+}
+
+// MemoryThreads keeps the threads in memory. Threads that are not used for longer than the TTL are forgotten, so
+// that groups that are never resolved, for example because the rule was deleted, do not accumulate.
+type MemoryThreads struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mtx     sync.Mutex
+	threads map[string]thread
+}
+
+type thread struct {
+	eventID  string
+	lastUsed time.Time
+}
+
+func NewMemoryThreads(ttl time.Duration) *MemoryThreads {
+	return &MemoryThreads{
+		ttl:     ttl,
+		now:     time.Now,
+		threads: make(map[string]thread),
+	}
+}
+
+func (m *MemoryThreads) Get(key string) (string, bool) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	t, ok := m.threads[key]
+	if !ok || m.now().Sub(t.lastUsed) > m.ttl {
+		return "", false
+	}
+	return t.eventID, true
+}
+
+func (m *MemoryThreads) Set(key, eventID string) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	now := m.now()
+	for k, t := range m.threads {
+		if now.Sub(t.lastUsed) > m.ttl {
+			delete(m.threads, k)
+		}
+	}
+	m.threads[key] = thread{eventID: eventID, lastUsed: now}
+}
+
+func (m *MemoryThreads) Delete(key string) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	delete(m.threads, key)
+}
@@ -0,0 +1,38 @@
+package matrix
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryThreads(t *testing.T) {
+	now := time.Now()
+	threads := NewMemoryThreads(time.Hour)
+	threads.now = func() time.Time { return now }
+
+	_, ok := threads.Get("a")
+	require.False(t, ok)
+
+	threads.Set("a", "$event-a")
+	id, ok := threads.Get("a")
+	require.True(t, ok)
+	require.Equal(t, "$event-a", id)
+
+	t.Run("threads that are not used for longer than the TTL are forgotten", func(t *testing.T) {
+		now = now.Add(2 * time.Hour)
+		_, ok := threads.Get("a")
+		require.False(t, ok)
+
+		threads.Set("b", "$event-b")
+		require.NotContains(t, threads.threads, "a")
+		require.Contains(t, threads.threads, "b")
+	})
+
+	t.Run("deleted threads are forgotten", func(t *testing.T) {
+		threads.Delete("b")
+		_, ok := threads.Get("b")
+		require.False(t, ok)
+	})
+}
@@ -0,0 +1,60 @@
+package zulip
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/templates"
+)
+
+// DefaultTopic puts the notifications of the same alert rule in the same topic. It falls back to the labels the alerts
+// are grouped by for groups of several rules.
+const DefaultTopic = `{{ if .CommonLabels.alertname }}{{ .CommonLabels.alertname }}{{ else }}{{ .GroupLabels.Values | join " " }}{{ end }}`
+
+type Config struct {
+	URL      string `json:"url,omitempty" yaml:"url,omitempty"`
+	BotEmail string `json:"botEmail,omitempty" yaml:"botEmail,omitempty"`
+	APIKey   string `json:"apiKey,omitempty" yaml:"apiKey,omitempty"`
+	Stream   string `json:"stream,omitempty" yaml:"stream,omitempty"`
+	Topic    string `json:"topic,omitempty" yaml:"topic,omitempty"`
+	Title    string `json:"title,omitempty" yaml:"title,omitempty"`
+	Message  string `json:"message,omitempty" yaml:"message,omitempty"`
+}
+
+func NewConfig(jsonData json.RawMessage, decryptFn receivers.DecryptFunc) (Config, error) {
+	settings := Config{}
+	if err := json.Unmarshal(jsonData, &settings); err != nil {
+		return settings, fmt.Errorf("failed to unmarshal settings: %w", err)
+	}
+	if settings.URL == "" {
+		return settings, errors.New("could not find Zulip URL in settings")
+	}
+	if _, err := url.ParseRequestURI(settings.URL); err != nil {
+		return settings, fmt.Errorf("invalid Zulip URL: %w", err)
+	}
+	settings.URL = strings.TrimSuffix(settings.URL, "/")
+	if settings.BotEmail == "" {
+		return settings, errors.New("could not find bot email in settings")
+	}
+	settings.APIKey = decryptFn("apiKey", settings.APIKey)
+	if settings.APIKey == "" {
+		return settings, errors.New("could not find API key in settings")
+	}
+	if settings.Stream == "" {
+		return settings, errors.New("could not find stream in settings")
+	}
+	if settings.Topic == "" {
+		settings.Topic = DefaultTopic
+	}
+	if settings.Title == "" {
+		settings.Title = templates.DefaultMessageTitleEmbed
+	}
+	if settings.Message == "" {
+		settings.Message = templates.DefaultMessageEmbed
+	}
+	return settings, nil
+}
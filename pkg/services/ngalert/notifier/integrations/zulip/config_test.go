@@ -0,0 +1,90 @@
+package zulip
+
+import (
+	"encoding/json"
+	"testing"
+
+	receiversTesting "github.com/grafana/alerting/receivers/testing"
+	"github.com/grafana/alerting/templates"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewConfig(t *testing.T) {
+	cases := []struct {
+		name              string
+		settings          string
+		secureSettings    map[string][]byte
+		expectedConfig    Config
+		expectedInitError string
+	}{
+		{
+			name:              "Error if empty",
+			settings:          "",
+			expectedInitError: `failed to unmarshal settings`,
+		},
+		{
+			name:              "Error if URL is missing",
+			settings:          `{ "botEmail": "bot@localhost", "apiKey": "key", "stream": "alerts" }`,
+			expectedInitError: `could not find Zulip URL in settings`,
+		},
+		{
+			name:              "Error if URL is invalid",
+			settings:          `{ "url": "localhost", "botEmail": "bot@localhost", "apiKey": "key", "stream": "alerts" }`,
+			expectedInitError: `invalid Zulip URL`,
+		},
+		{
+			name:              "Error if bot email is missing",
+			settings:          `{ "url": "http://localhost:9991", "apiKey": "key", "stream": "alerts" }`,
+			expectedInitError: `could not find bot email in settings`,
+		},
+		{
+			name:              "Error if API key is missing",
+			settings:          `{ "url": "http://localhost:9991", "botEmail": "bot@localhost", "stream": "alerts" }`,
+			expectedInitError: `could not find API key in settings`,
+		},
+		{
+			name:              "Error if stream is missing",
+			settings:          `{ "url": "http://localhost:9991", "botEmail": "bot@localhost", "apiKey": "key" }`,
+			expectedInitError: `could not find stream in settings`,
+		},
+		{
+			name:     "Minimal valid configuration",
+			settings: `{ "url": "http://localhost:9991/", "botEmail": "bot@localhost", "apiKey": "key", "stream": "alerts" }`,
+			expectedConfig: Config{
+				URL:      "http://localhost:9991",
+				BotEmail: "bot@localhost",
+				APIKey:   "key",
+				Stream:   "alerts",
+				Topic:    DefaultTopic,
+				Title:    templates.DefaultMessageTitleEmbed,
+				Message:  templates.DefaultMessageEmbed,
+			},
+		},
+		{
+			name:           "All fields from the full valid configuration and secrets",
+			settings:       FullValidConfigForTesting,
+			secureSettings: receiversTesting.ReadSecretsJSONForTesting(FullValidSecretsForTesting),
+			expectedConfig: Config{
+				URL:      "http://localhost:9991",
+				BotEmail: "grafana-bot@localhost",
+				APIKey:   "test-secret-api-key",
+				Stream:   "alerts",
+				Topic:    "test-topic",
+				Title:    "test-title",
+				Message:  "test-message",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			actual, err := NewConfig(json.RawMessage(c.settings), receiversTesting.DecryptForTesting(c.secureSettings))
+			if c.expectedInitError != "" {
+				require.ErrorContains(t, err, c.expectedInitError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.expectedConfig, actual)
+		})
+	}
+}
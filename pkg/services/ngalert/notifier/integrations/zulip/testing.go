@@ -0,0 +1,17 @@
+package zulip
+
+// FullValidConfigForTesting is a string representation of a JSON object that contains all fields supported by the notifier Config. It can be used without secrets.
+const FullValidConfigForTesting = `{
+	"url": "http://localhost:9991",
+	"botEmail": "grafana-bot@localhost",
+	"apiKey": "test-api-key",
+	"stream": "alerts",
+	"topic": "test-topic",
+	"title": "test-title",
+	"message": "test-message"
+}`
+
+// FullValidSecretsForTesting is a string representation of JSON object that contains all fields that can be overridden from secrets
+const FullValidSecretsForTesting = `{
+	"apiKey": "test-secret-api-key"
+}`
@@ -0,0 +1,191 @@
+package zulip
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+
+	"github.com/grafana/alerting/images"
+	"github.com/grafana/alerting/logging"
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/templates"
+)
+
+// Type is the type of the Zulip integration.
+const Type = "zulip"
+
+const (
+	// Zulip supports topics of 60 characters at most and messages of 10000 characters at most.
+	zulipMaxTopicLenRunes   = 60
+	zulipMaxMessageLenRunes = 10000
+	// fallbackTopic is used if the topic template renders to an empty string, because Zulip requires a topic.
+	fallbackTopic = "Grafana alerts"
+)
+
+// Notifier is responsible for sending alert notifications to a Zulip stream.
+// It uses two endpoints of the Zulip API:
+// - https://zulip.com/api/upload-file for uploading images (only if alerts contain references to them)
+// - https://zulip.com/api/send-message for sending the message to the topic of the stream
+// The notifications of an alert group are threaded by sending them to the same topic.
+type Notifier struct {
+	*receivers.Base
+	log      logging.Logger
+	ns       receivers.WebhookSender
+	images   images.Provider
+	tmpl     *templates.Template
+	settings Config
+}
+
+// New is the constructor for the Zulip notifier.
+func New(cfg Config, meta receivers.Metadata, template *templates.Template, sender receivers.WebhookSender, images images.Provider, logger logging.Logger) *Notifier {
+	return &Notifier{
+		Base:     receivers.NewBase(meta),
+		log:      logger,
+		ns:       sender,
+		images:   images,
+		tmpl:     template,
+		settings: cfg,
+	}
+}
+
+// Notify sends an alert notification to Zulip.
+func (n *Notifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	var tmplErr error
+	tmpl, _ := templates.TmplText(ctx, n.tmpl, as, n.log, &tmplErr)
+	topic := strings.TrimSpace(tmpl(n.settings.Topic))
+	title := tmpl(n.settings.Title)
+	message := tmpl(n.settings.Message)
+	if tmplErr != nil {
+		n.log.Warn("failed to template Zulip message", "error", tmplErr)
+	}
+	if topic == "" {
+		topic = fallbackTopic
+	}
+	topic, _ = receivers.TruncateInRunes(topic, zulipMaxTopicLenRunes)
+
+	var content strings.Builder
+	content.WriteString("**" + title + "**\n\n" + message)
+	_ = images.WithStoredImages(ctx, n.log, n.images, func(_ int, image images.Image) error {
+		uri := image.URL
+		if image.Path != "" {
+			var err error
+			if uri, err = n.upload(ctx, image.Path); err != nil {
+				return fmt.Errorf("failed to upload image to Zulip: %w", err)
+			}
+		}
+		if uri != "" {
+			content.WriteString("\n[" + filepath.Base(uri) + "](" + uri + ")")
+		}
+		return nil
+	}, as...)
+
+	text, truncated := receivers.TruncateInRunes(content.String(), zulipMaxMessageLenRunes)
+	if truncated {
+		key, err := notify.ExtractGroupKey(ctx)
+		if err != nil {
+			return false, err
+		}
+		n.log.Warn("Truncated message", "alert", key, "max_runes", zulipMaxMessageLenRunes)
+	}
+
+	form := url.Values{}
+	form.Set("type", "stream")
+	form.Set("to", n.settings.Stream)
+	form.Set("topic", topic)
+	form.Set("content", text)
+	cmd := &receivers.SendWebhookSettings{
+		URL:         n.settings.URL + "/api/v1/messages",
+		User:        n.settings.BotEmail,
+		Password:    n.settings.APIKey,
+		Body:        form.Encode(),
+		HTTPMethod:  "POST",
+		ContentType: "application/x-www-form-urlencoded",
+		Validation:  validateResponse(nil),
+	}
+	if err := n.ns.SendWebhook(ctx, cmd); err != nil {
+		return false, fmt.Errorf("failed to send Zulip message: %w", err)
+	}
+	return true, nil
+}
+
+// upload uploads the file to Zulip and returns its URI, which can be linked in messages.
+func (n *Notifier) upload(ctx context.Context, path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open image: %w", err)
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			n.log.Warn("failed to close image", "error", err)
+		}
+	}()
+
+	b := bytes.Buffer{}
+	w := multipart.NewWriter(&b)
+	if boundary := receivers.GetBoundary(); boundary != "" {
+		if err := w.SetBoundary(boundary); err != nil {
+			return "", err
+		}
+	}
+	fw, err := w.CreateFormFile("filename", filepath.Base(path))
+	if err != nil {
+		return "", fmt.Errorf("failed to create form file: %w", err)
+	}
+	if _, err := io.Copy(fw, f); err != nil {
+		return "", fmt.Errorf("failed to write to form file: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return "", fmt.Errorf("failed to close multipart: %w", err)
+	}
+
+	var res struct {
+		URI string `json:"uri"`
+	}
+	cmd := &receivers.SendWebhookSettings{
+		URL:         n.settings.URL + "/api/v1/user_uploads",
+		User:        n.settings.BotEmail,
+		Password:    n.settings.APIKey,
+		Body:        b.String(),
+		HTTPMethod:  "POST",
+		ContentType: w.FormDataContentType(),
+		Validation:  validateResponse(&res),
+	}
+	if err := n.ns.SendWebhook(ctx, cmd); err != nil {
+		return "", err
+	}
+	return res.URI, nil
+}
+
+// validateResponse returns the error of the Zulip API, or unmarshals the successful response into res if it is not nil.
+func validateResponse(res any) func(body []byte, statusCode int) error {
+	return func(body []byte, statusCode int) error {
+		var result struct {
+			Result string `json:"result"`
+			Msg    string `json:"msg"`
+		}
+		if err := json.Unmarshal(body, &result); err != nil {
+			return fmt.Errorf("unexpected response with status code %d", statusCode)
+		}
+		if statusCode/100 != 2 || result.Result != "success" {
+			return fmt.Errorf("%s (status code %d)", result.Msg, statusCode)
+		}
+		if res == nil {
+			return nil
+		}
+		return json.Unmarshal(body, res)
+	}
+}
+
+func (n *Notifier) SendResolved() bool {
+	return !n.GetDisableResolveMessage()
+}
@@ -0,0 +1,150 @@
+package zulip
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alerting/images"
+	"github.com/grafana/alerting/logging"
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/templates"
+)
+
+// fakeZulip records the requests and answers them like the Zulip API.
+type fakeZulip struct {
+	calls []receivers.SendWebhookSettings
+}
+
+func (f *fakeZulip) SendWebhook(_ context.Context, cmd *receivers.SendWebhookSettings) error {
+	f.calls = append(f.calls, *cmd)
+	body := fmt.Sprintf(`{"result":"success","msg":"","id":%d}`, len(f.calls))
+	if strings.HasSuffix(cmd.URL, "/user_uploads") {
+		body = `{"result":"success","msg":"","uri":"/user_uploads/1/ab/image.png"}`
+	}
+	return cmd.Validation([]byte(body), 200)
+}
+
+func TestNotify(t *testing.T) {
+	tmpl := templates.ForTests(t)
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	settings := Config{
+		URL:      "http://localhost:9991",
+		BotEmail: "bot@localhost",
+		APIKey:   "key",
+		Stream:   "alerts",
+		Topic:    DefaultTopic,
+		Title:    "{{ .Status }} {{ .CommonLabels.alertname }}",
+		Message:  "{{ len .Alerts }} alerts",
+	}
+	ctx := notify.WithGroupKey(context.Background(), "alertname")
+	ctx = notify.WithGroupLabels(ctx, model.LabelSet{"team": "a"})
+
+	cases := []struct {
+		name     string
+		settings func(Config) Config
+		alerts   []*types.Alert
+		images   images.Provider
+		expCalls int
+		expTopic string
+		expBody  string
+	}{
+		{
+			name:     "A single alert is sent to the topic of its rule",
+			alerts:   []*types.Alert{{Alert: model.Alert{Labels: model.LabelSet{"alertname": "alert1"}}}},
+			expTopic: "alert1",
+			expBody:  "**firing alert1**\n\n1 alerts",
+		},
+		{
+			name: "Alerts of several rules are sent to the topic of the group labels",
+			alerts: []*types.Alert{
+				{Alert: model.Alert{Labels: model.LabelSet{"alertname": "alert1", "team": "a"}}},
+				{Alert: model.Alert{Labels: model.LabelSet{"alertname": "alert2", "team": "a"}}},
+			},
+			expTopic: "a",
+			expBody:  "**firing **\n\n2 alerts",
+		},
+		{
+			name:     "A topic that renders empty falls back to the default topic",
+			settings: func(c Config) Config { c.Topic = "{{ .CommonLabels.missing }}"; return c },
+			alerts:   []*types.Alert{{Alert: model.Alert{Labels: model.LabelSet{"alertname": "alert1"}}}},
+			expTopic: fallbackTopic,
+			expBody:  "**firing alert1**\n\n1 alerts",
+		},
+		{
+			name:     "Long topics are truncated",
+			settings: func(c Config) Config { c.Topic = strings.Repeat("a", 100); return c },
+			alerts:   []*types.Alert{{Alert: model.Alert{Labels: model.LabelSet{"alertname": "alert1"}}}},
+			expTopic: strings.Repeat("a", zulipMaxTopicLenRunes-1) + "…",
+			expBody:  "**firing alert1**\n\n1 alerts",
+		},
+		{
+			name: "Images are uploaded and linked in the message",
+			alerts: []*types.Alert{{Alert: model.Alert{
+				Labels:      model.LabelSet{"alertname": "alert1"},
+				Annotations: model.LabelSet{"__alertImageToken__": "test-image-1"},
+			}}},
+			images:   images.NewFakeProviderWithFile(t, 1),
+			expCalls: 2,
+			expTopic: "alert1",
+			expBody:  "**firing alert1**\n\n1 alerts\n[image.png](/user_uploads/1/ab/image.png)",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg := settings
+			if c.settings != nil {
+				cfg = c.settings(cfg)
+			}
+			img := c.images
+			if img == nil {
+				img = &images.UnavailableProvider{}
+			}
+			server := &fakeZulip{}
+			n := New(cfg, receivers.Metadata{}, tmpl, server, img, &logging.FakeLogger{})
+
+			ok, err := n.Notify(ctx, c.alerts...)
+			require.NoError(t, err)
+			require.True(t, ok)
+
+			expCalls := c.expCalls
+			if expCalls == 0 {
+				expCalls = 1
+			}
+			require.Len(t, server.calls, expCalls)
+			msg := server.calls[len(server.calls)-1]
+			require.Equal(t, "http://localhost:9991/api/v1/messages", msg.URL)
+			require.Equal(t, "bot@localhost", msg.User)
+			require.Equal(t, "key", msg.Password)
+			form, err := url.ParseQuery(msg.Body)
+			require.NoError(t, err)
+			require.Equal(t, url.Values{
+				"type":    {"stream"},
+				"to":      {"alerts"},
+				"topic":   {c.expTopic},
+				"content": {c.expBody},
+			}, form)
+		})
+	}
+
+	t.Run("returns the error of the Zulip API", func(t *testing.T) {
+		sender := receivers.MockNotificationService()
+		sender.ShouldError = validateResponse(nil)([]byte(`{"result":"error","msg":"Stream 'alerts' does not exist"}`), 400)
+		n := New(settings, receivers.Metadata{}, tmpl, sender, &images.UnavailableProvider{}, &logging.FakeLogger{})
+
+		ok, err := n.Notify(ctx, &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "alert1"}}})
+		require.ErrorContains(t, err, "Stream 'alerts' does not exist (status code 400)")
+		require.False(t, ok)
+	})
+}
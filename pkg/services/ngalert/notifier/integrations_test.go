@@ -0,0 +1,61 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	alertingNotify "github.com/grafana/alerting/notify"
+	receiversTesting "github.com/grafana/alerting/receivers/testing"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/integrations/matrix"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/integrations/zulip"
+)
+
+func TestBuildReceiverConfiguration(t *testing.T) {
+	decrypt := func(_ context.Context, sjd map[string][]byte, key string, fallback string) string {
+		return receiversTesting.DecryptForTesting(sjd)(key, fallback)
+	}
+	receiver := func(integrations ...*alertingNotify.GrafanaIntegrationConfig) *alertingNotify.APIReceiver {
+		return &alertingNotify.APIReceiver{
+			ConfigReceiver:      alertingNotify.ConfigReceiver{Name: "test"},
+			GrafanaIntegrations: alertingNotify.GrafanaIntegrations{Integrations: integrations},
+		}
+	}
+
+	t.Run("integrations of the alerting library and Grafana are parsed together", func(t *testing.T) {
+		libCfg, grafanaCfg, err := buildReceiverConfiguration(context.Background(), receiver(
+			&alertingNotify.GrafanaIntegrationConfig{UID: "1", Type: "webhook", Settings: json.RawMessage(`{"url": "http://localhost"}`)},
+			&alertingNotify.GrafanaIntegrationConfig{UID: "2", Type: "matrix", Settings: json.RawMessage(matrix.FullValidConfigForTesting)},
+			&alertingNotify.GrafanaIntegrationConfig{UID: "3", Type: "zulip", Settings: json.RawMessage(zulip.FullValidConfigForTesting)},
+		), decrypt)
+		require.NoError(t, err)
+		require.Len(t, libCfg.WebhookConfigs, 1)
+		require.Len(t, grafanaCfg.MatrixConfigs, 1)
+		require.Equal(t, "2", grafanaCfg.MatrixConfigs[0].UID)
+		require.Len(t, grafanaCfg.ZulipConfigs, 1)
+		require.Equal(t, "3", grafanaCfg.ZulipConfigs[0].UID)
+	})
+
+	t.Run("secure settings override the settings", func(t *testing.T) {
+		_, grafanaCfg, err := buildReceiverConfiguration(context.Background(), receiver(
+			&alertingNotify.GrafanaIntegrationConfig{
+				Type:           "matrix",
+				Settings:       json.RawMessage(matrix.FullValidConfigForTesting),
+				SecureSettings: map[string]string{"accessToken": "c2VjcmV0"},
+			},
+		), decrypt)
+		require.NoError(t, err)
+		require.Equal(t, "secret", grafanaCfg.MatrixConfigs[0].Settings.AccessToken)
+	})
+
+	t.Run("invalid integration returns validation error", func(t *testing.T) {
+		integration := &alertingNotify.GrafanaIntegrationConfig{UID: "1", Type: "zulip", Settings: json.RawMessage(`{}`)}
+		err := ValidateReceiverConfiguration(context.Background(), receiver(integration), decrypt)
+		var validationErr alertingNotify.IntegrationValidationError
+		require.ErrorAs(t, err, &validationErr)
+		require.Equal(t, integration, validationErr.Integration)
+		require.ErrorContains(t, err, "could not find Zulip URL in settings")
+	})
+}
@@ -401,6 +401,42 @@ func (moa *MultiOrgAlertmanager) StopAndWait() {
 	}
 }
 
+// ClusterMembership provides the live members of the high availability cluster.
+type ClusterMembership interface {
+	Self() string
+	Members() []string
+}
+
+// ClusterMembership returns the members of the high availability cluster that the Alertmanagers are part of.
+// It returns nil if high availability is not configured.
+func (moa *MultiOrgAlertmanager) ClusterMembership() ClusterMembership {
+	switch p := moa.peer.(type) {
+	case *redisPeer:
+		return p
+	case *alertingCluster.Peer:
+		return gossipMembership{peer: p}
+	}
+	return nil
+}
+
+// gossipMembership provides the members of the memberlist cluster.
+type gossipMembership struct {
+	peer *alertingCluster.Peer
+}
+
+func (m gossipMembership) Self() string {
+	return m.peer.Name()
+}
+
+func (m gossipMembership) Members() []string {
+	peers := m.peer.Peers()
+	members := make([]string, 0, len(peers))
+	for _, peer := range peers {
+		members = append(members, peer.Name())
+	}
+	return members
+}
+
 // AlertmanagerFor returns the Alertmanager instance for the organization provided.
 // When the organization does not have an active Alertmanager, it returns a ErrNoAlertmanagerForOrg.
 // When the Alertmanager of the organization is not ready, it returns a ErrAlertmanagerNotReady.
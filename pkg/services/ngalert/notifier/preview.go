@@ -0,0 +1,313 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	alertingNotify "github.com/grafana/alerting/notify"
+	"github.com/grafana/alerting/receivers"
+	alertingTemplates "github.com/grafana/alerting/templates"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/dispatch"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/channels_config"
+)
+
+var (
+	ErrPreviewInvalidTemplate = errutil.ValidationFailed("alerting.notifications.preview.invalidTemplate")
+	ErrPreviewInvalidAlerts   = errutil.ValidationFailed("alerting.notifications.preview.invalidAlerts")
+)
+
+// PreviewNotificationsParams are the parameters of MultiOrgAlertmanager.PreviewNotifications.
+type PreviewNotificationsParams struct {
+	// Receiver is the name of the contact point.
+	Receiver string
+	Alerts   amv2.PostableAlerts
+	// Template, if not nil, replaces the template file of the same name or is added to the template files.
+	Template *apimodels.PreviewTemplate
+}
+
+// previewIntegration is an integration of a contact point and those of its settings that are templates.
+type previewIntegration struct {
+	receivers.Metadata
+	templates map[string]string
+}
+
+// PreviewNotifications renders the notifications that the integrations of a contact point would send for the alerts,
+// without sending them. The alerts are grouped the same way as by the notification policies that route them to the
+// contact point, and the alerts that no policy routes to the contact point are put into a single group.
+// Only the settings of the integrations that are templates, such as titles and messages, are rendered.
+func (moa *MultiOrgAlertmanager) PreviewNotifications(ctx context.Context, orgID int64, params PreviewNotificationsParams) (apimodels.NotificationPreviewResults, error) {
+	amConfig, err := moa.configStore.GetLatestAlertmanagerConfiguration(ctx, orgID)
+	if err != nil {
+		return apimodels.NotificationPreviewResults{}, fmt.Errorf("failed to get latest configuration: %w", err)
+	}
+	cfg, err := Load([]byte(amConfig.AlertmanagerConfiguration))
+	if err != nil {
+		return apimodels.NotificationPreviewResults{}, err
+	}
+	if moa.featureManager.IsEnabled(ctx, featuremgmt.FlagAlertingSimplifiedRouting) {
+		if err := AddAutogenConfig(ctx, moa.logger, moa.configStore, orgID, &cfg.AlertmanagerConfig, true); err != nil {
+			return apimodels.NotificationPreviewResults{}, err
+		}
+	}
+
+	var receiver *apimodels.PostableApiReceiver
+	for _, r := range cfg.AlertmanagerConfig.Receivers {
+		if r.Name == params.Receiver {
+			receiver = r
+			break
+		}
+	}
+	if receiver == nil {
+		return apimodels.NotificationPreviewResults{}, WithPublicError(ErrReceiverNotFound.Errorf("contact point %q does not exist", params.Receiver))
+	}
+
+	tmpl, err := moa.previewTemplate(cfg, params.Template)
+	if err != nil {
+		return apimodels.NotificationPreviewResults{}, err
+	}
+	receiverCfg, grafanaCfg, err := buildReceiverConfiguration(ctx, PostableApiReceiverToApiReceiver(receiver), moa.decryptFn)
+	if err != nil {
+		return apimodels.NotificationPreviewResults{}, fmt.Errorf("failed to build the integrations of the contact point: %w", err)
+	}
+	integrations := templatedIntegrations(receiverCfg, grafanaCfg)
+
+	alerts, validationErr := alertingNotify.PostableAlertsToAlertmanagerAlerts(params.Alerts, time.Now())
+	if validationErr != nil {
+		return apimodels.NotificationPreviewResults{}, WithPublicError(ErrPreviewInvalidAlerts.Errorf("invalid alerts: %s", validationErr.Error()))
+	}
+	groups := groupPreviewAlerts(dispatch.NewRoute(cfg.AlertmanagerConfig.Route.AsAMRoute(), nil), receiver.Name, alerts)
+
+	result := apimodels.NotificationPreviewResults{Groups: make([]apimodels.NotificationPreviewGroup, 0, len(groups))}
+	for _, g := range groups {
+		groupCtx := notify.WithGroupKey(ctx, g.key)
+		groupCtx = notify.WithReceiverName(groupCtx, receiver.Name)
+		groupCtx = notify.WithGroupLabels(groupCtx, g.labels)
+		data := alertingTemplates.ExtendData(notify.GetTemplateData(groupCtx, tmpl, g.alerts, moa.logger), moa.logger)
+
+		group := apimodels.NotificationPreviewGroup{
+			GroupLabels:  labelSetToMap(g.labels),
+			Routed:       g.routed,
+			Alerts:       make([]map[string]string, 0, len(g.alerts)),
+			Integrations: make([]apimodels.NotificationPreviewIntegration, 0, len(integrations)),
+		}
+		for _, a := range g.alerts {
+			group.Alerts = append(group.Alerts, labelSetToMap(a.Labels))
+		}
+		for _, integration := range integrations {
+			rendered := apimodels.NotificationPreviewIntegration{
+				UID:    integration.UID,
+				Name:   integration.Name,
+				Type:   integration.Type,
+				Fields: make(map[string]string, len(integration.templates)),
+			}
+			for name, text := range integration.templates {
+				s, err := tmpl.ExecuteTextString(text, data)
+				if err != nil {
+					if rendered.Errors == nil {
+						rendered.Errors = make(map[string]string)
+					}
+					rendered.Errors[name] = err.Error()
+					continue
+				}
+				rendered.Fields[name] = s
+			}
+			group.Integrations = append(group.Integrations, rendered)
+		}
+		result.Groups = append(result.Groups, group)
+	}
+	return result, nil
+}
+
+// previewTemplate returns the templates of the configuration, with the template file of the same name replaced by override.
+func (moa *MultiOrgAlertmanager) previewTemplate(cfg *apimodels.PostableUserConfig, override *apimodels.PreviewTemplate) (*alertingTemplates.Template, error) {
+	files := make(map[string]string, len(cfg.TemplateFiles)+1)
+	for name, content := range cfg.TemplateFiles {
+		files[name] = content
+	}
+	if override != nil {
+		files[override.Name] = override.Template
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	contents := make([]string, 0, len(names))
+	for _, name := range names {
+		contents = append(contents, files[name])
+	}
+
+	tmpl, err := alertingTemplates.FromContent(contents)
+	if err != nil {
+		return nil, WithPublicError(ErrPreviewInvalidTemplate.Errorf("invalid template: %s", err))
+	}
+	tmpl.ExternalURL, err = url.Parse(moa.settings.AppURL)
+	if err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
+type previewGroup struct {
+	key    string
+	labels model.LabelSet
+	routed bool
+	alerts []*types.Alert
+}
+
+// groupPreviewAlerts groups the alerts by the routes that send them to the receiver.
+func groupPreviewAlerts(route *dispatch.Route, receiver string, alerts []*types.Alert) []*previewGroup {
+	var result []*previewGroup
+	groups := make(map[string]*previewGroup)
+	add := func(key string, labels model.LabelSet, routed bool, alert *types.Alert) {
+		g, ok := groups[key]
+		if !ok {
+			g = &previewGroup{key: key, labels: labels, routed: routed}
+			groups[key] = g
+			result = append(result, g)
+		}
+		g.alerts = append(g.alerts, alert)
+	}
+	for _, alert := range alerts {
+		routed := false
+		for _, r := range route.Match(alert.Labels) {
+			if r.RouteOpts.Receiver != receiver {
+				continue
+			}
+			routed = true
+			labels := groupLabels(alert.Labels, &r.RouteOpts)
+			add(r.ID()+":"+labels.String(), labels, true, alert)
+		}
+		if !routed {
+			add("", model.LabelSet{}, false, alert)
+		}
+	}
+	return result
+}
+
+// groupLabels returns the labels of the alert that the route groups by.
+func groupLabels(labels model.LabelSet, opts *dispatch.RouteOpts) model.LabelSet {
+	if opts.GroupByAll {
+		return labels.Clone()
+	}
+	result := model.LabelSet{}
+	for name := range opts.GroupBy {
+		if v, ok := labels[name]; ok {
+			result[name] = v
+		}
+	}
+	return result
+}
+
+// templatedIntegrations returns the integrations of the receiver with their settings that are templates. The settings
+// are taken from the parsed configuration, so that they include the default templates of the settings that are not set.
+func templatedIntegrations(cfg alertingNotify.GrafanaReceiverConfig, grafanaCfg grafanaReceiverConfig) []previewIntegration {
+	var result []previewIntegration
+	// Every exported slice of GrafanaReceiverConfig and grafanaReceiverConfig holds the parsed configurations of
+	// one type of integration.
+	for _, v := range []reflect.Value{reflect.ValueOf(cfg), reflect.ValueOf(grafanaCfg)} {
+		for i := 0; i < v.NumField(); i++ {
+			configs := v.Field(i)
+			if configs.Kind() != reflect.Slice {
+				continue
+			}
+			for j := 0; j < configs.Len(); j++ {
+				config := reflect.Indirect(configs.Index(j))
+				meta, ok := config.FieldByName("Metadata").Interface().(receivers.Metadata)
+				if !ok {
+					continue
+				}
+				// Secrets are never rendered, even if they look like templates.
+				secrets, _ := channels_config.GetSecretKeysForContactPointType(meta.Type)
+				templates := make(map[string]string)
+				collectTemplates(templates, "", config.FieldByName("Settings"), secrets)
+				result = append(result, previewIntegration{Metadata: meta, templates: templates})
+			}
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Type != result[j].Type {
+			return result[i].Type < result[j].Type
+		}
+		return result[i].UID < result[j].UID
+	})
+	return result
+}
+
+// collectTemplates adds the string settings that contain templates to dst. The settings are named by their JSON keys,
+// or by their field names in lower camel case if they have none, since not all integrations define JSON keys.
+func collectTemplates(dst map[string]string, name string, v reflect.Value, secrets []string) {
+	v = reflect.Indirect(v)
+	switch v.Kind() {
+	case reflect.String:
+		if isSecretSetting(name, secrets) {
+			return
+		}
+		if s := v.String(); strings.Contains(s, "{{") {
+			dst[name] = s
+		}
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return
+		}
+		for _, k := range v.MapKeys() {
+			collectTemplates(dst, settingName(name, k.String()), v.MapIndex(k), secrets)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			fieldName, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if fieldName == "-" {
+				continue
+			}
+			if fieldName == "" {
+				fieldName = strings.ToLower(field.Name[:1]) + field.Name[1:]
+			}
+			collectTemplates(dst, settingName(name, fieldName), v.Field(i), secrets)
+		}
+	}
+}
+
+func settingName(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
+
+// isSecretSetting compares the names regardless of case and underscores, because the secret keys are the keys of
+// the settings in the API, while the names of the parsed settings can be derived from field names.
+func isSecretSetting(name string, secrets []string) bool {
+	normalize := func(s string) string {
+		return strings.ToLower(strings.ReplaceAll(s, "_", ""))
+	}
+	name = normalize(name)
+	return slices.ContainsFunc(secrets, func(secret string) bool {
+		return normalize(secret) == name
+	})
+}
+
+func labelSetToMap(labels model.LabelSet) map[string]string {
+	result := make(map[string]string, len(labels))
+	for k, v := range labels {
+		result[string(k)] = string(v)
+	}
+	return result
+}
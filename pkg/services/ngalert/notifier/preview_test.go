@@ -0,0 +1,157 @@
+package notifier
+
+import (
+	"context"
+	"testing"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+var previewConfig = `
+{
+	"template_files": {
+		"custom": "{{ define \"custom.title\" }}{{ len .Alerts }} alerts grouped by {{ .GroupLabels.SortedPairs.Names }}{{ end }}"
+	},
+	"alertmanager_config": {
+		"route": {
+			"receiver": "default",
+			"group_by": ["alertname"],
+			"routes": [{
+				"receiver": "ops",
+				"object_matchers": [["team", "=", "ops"]],
+				"group_by": ["alertname", "severity"]
+			}]
+		},
+		"receivers": [{
+			"name": "default",
+			"grafana_managed_receiver_configs": [{
+				"uid": "email-uid",
+				"name": "default",
+				"type": "email",
+				"settings": {"addresses": "example@email.com"}
+			}]
+		}, {
+			"name": "ops",
+			"grafana_managed_receiver_configs": [{
+				"uid": "webhook-uid",
+				"name": "ops",
+				"type": "webhook",
+				"settings": {
+					"url": "http://localhost/webhook",
+					"title": "{{ template \"custom.title\" . }}",
+					"message": "{{ range .Alerts }}{{ .Labels.alertname }} {{ end }}",
+					"password": "{{ secret }}"
+				}
+			}]
+		}]
+	}
+}`
+
+func previewAlert(labels amv2.LabelSet) *amv2.PostableAlert {
+	return &amv2.PostableAlert{Alert: amv2.Alert{Labels: labels}}
+}
+
+func TestMultiOrgAlertmanager_PreviewNotifications(t *testing.T) {
+	ctx := context.Background()
+	mam := setupMam(t, nil)
+	require.NoError(t, mam.configStore.SaveAlertmanagerConfiguration(ctx, &models.SaveAlertmanagerConfigurationCmd{
+		AlertmanagerConfiguration: previewConfig,
+		Default:                   false,
+		OrgID:                     1,
+		LastApplied:               0,
+	}))
+
+	alerts := amv2.PostableAlerts{
+		previewAlert(amv2.LabelSet{"alertname": "cpu", "team": "ops", "severity": "critical"}),
+		previewAlert(amv2.LabelSet{"alertname": "cpu", "team": "ops", "severity": "critical", "instance": "b"}),
+		previewAlert(amv2.LabelSet{"alertname": "cpu", "team": "ops", "severity": "warning"}),
+		previewAlert(amv2.LabelSet{"alertname": "disk", "team": "dev"}),
+	}
+
+	t.Run("should group alerts like the notification policies that route them to the contact point", func(t *testing.T) {
+		res, err := mam.PreviewNotifications(ctx, 1, PreviewNotificationsParams{Receiver: "ops", Alerts: alerts})
+		require.NoError(t, err)
+		require.Len(t, res.Groups, 3)
+
+		critical := res.Groups[0]
+		require.True(t, critical.Routed)
+		require.Equal(t, map[string]string{"alertname": "cpu", "severity": "critical"}, critical.GroupLabels)
+		require.Len(t, critical.Alerts, 2)
+		require.Equal(t, []apimodels.NotificationPreviewIntegration{{
+			UID:  "webhook-uid",
+			Name: "ops",
+			Type: "webhook",
+			Fields: map[string]string{
+				"title":   "2 alerts grouped by [alertname severity]",
+				"message": "cpu cpu ",
+			},
+		}}, critical.Integrations, "secrets should not be rendered")
+
+		warning := res.Groups[1]
+		require.True(t, warning.Routed)
+		require.Equal(t, map[string]string{"alertname": "cpu", "severity": "warning"}, warning.GroupLabels)
+		require.Equal(t, "1 alerts grouped by [alertname severity]", warning.Integrations[0].Fields["title"])
+
+		unrouted := res.Groups[2]
+		require.False(t, unrouted.Routed)
+		require.Empty(t, unrouted.GroupLabels)
+		require.Equal(t, []map[string]string{{"alertname": "disk", "team": "dev"}}, unrouted.Alerts)
+		require.Equal(t, "disk ", unrouted.Integrations[0].Fields["message"])
+	})
+
+	t.Run("should use the template that replaces the saved one", func(t *testing.T) {
+		res, err := mam.PreviewNotifications(ctx, 1, PreviewNotificationsParams{
+			Receiver: "ops",
+			Alerts:   alerts[:1],
+			Template: &apimodels.PreviewTemplate{
+				Name:     "custom",
+				Template: `{{ define "custom.title" }}[{{ .Status | toUpper }}] {{ .CommonLabels.alertname }}{{ end }}`,
+			},
+		})
+		require.NoError(t, err)
+		require.Len(t, res.Groups, 1)
+		require.Equal(t, "[FIRING] cpu", res.Groups[0].Integrations[0].Fields["title"])
+	})
+
+	t.Run("should return errors of templates that fail to render", func(t *testing.T) {
+		res, err := mam.PreviewNotifications(ctx, 1, PreviewNotificationsParams{
+			Receiver: "ops",
+			Alerts:   alerts[:1],
+			Template: &apimodels.PreviewTemplate{
+				Name:     "custom",
+				Template: `{{ define "custom.title" }}{{ .Missing.Field }}{{ end }}`,
+			},
+		})
+		require.NoError(t, err)
+		integration := res.Groups[0].Integrations[0]
+		require.NotContains(t, integration.Fields, "title")
+		require.Contains(t, integration.Errors, "title")
+		require.Equal(t, "cpu ", integration.Fields["message"])
+	})
+
+	t.Run("should fail if the template is invalid", func(t *testing.T) {
+		_, err := mam.PreviewNotifications(ctx, 1, PreviewNotificationsParams{
+			Receiver: "ops",
+			Alerts:   alerts,
+			Template: &apimodels.PreviewTemplate{Name: "custom", Template: `{{ define "custom.title" }}`},
+		})
+		require.ErrorIs(t, err, ErrPreviewInvalidTemplate)
+	})
+
+	t.Run("should fail if the alerts are invalid", func(t *testing.T) {
+		_, err := mam.PreviewNotifications(ctx, 1, PreviewNotificationsParams{
+			Receiver: "ops",
+			Alerts:   amv2.PostableAlerts{previewAlert(amv2.LabelSet{"alertname": "cpu"}), previewAlert(amv2.LabelSet{})},
+		})
+		require.ErrorIs(t, err, ErrPreviewInvalidAlerts)
+	})
+
+	t.Run("should fail if the contact point does not exist", func(t *testing.T) {
+		_, err := mam.PreviewNotifications(ctx, 1, PreviewNotificationsParams{Receiver: "unknown", Alerts: alerts})
+		require.ErrorIs(t, err, ErrReceiverNotFound)
+	})
+}
@@ -403,6 +403,11 @@ func (p *redisPeer) GetHealthScore() int {
 	return 0
 }
 
+// Self returns the name of this peer as it appears in Members.
+func (p *redisPeer) Self() string {
+	return p.withPrefix(p.name)
+}
+
 // Members returns a list of active cluster Members.
 func (p *redisPeer) Members() []string {
 	p.membersMtx.Lock()
@@ -0,0 +1,219 @@
+package prom
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/prometheus/model/rulefmt"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+const (
+	// QueryRefID is the RefID of the query that runs the expression of the Prometheus rule.
+	QueryRefID = "query"
+	// MathRefID is the RefID of the math expression that turns every series returned by the query into 1,
+	// the same way Prometheus fires an alert for every series returned by the expression of an alerting rule.
+	MathRefID = "prometheus_math"
+	// ThresholdRefID is the RefID of the threshold expression that is the condition of alerting rules.
+	ThresholdRefID = "threshold"
+
+	// queryTimeRange is the relative time range of the query. Prometheus rules run instant queries, so only the
+	// end of the range matters.
+	queryTimeRange = 10 * time.Minute
+)
+
+var (
+	ErrNoDatasource     = errors.New("data source UID is required")
+	ErrUnsupportedField = errors.New("field is not supported by Grafana-managed rules")
+)
+
+// Config configures how Prometheus rules are converted to Grafana-managed rules.
+type Config struct {
+	// DatasourceUID is the UID of the data source that the expressions of the rules query.
+	DatasourceUID string
+	// DatasourceType is the type of the data source. Prometheus is used if empty.
+	DatasourceType string
+	// DefaultInterval is the evaluation interval of groups that do not define one.
+	DefaultInterval time.Duration
+	// NoDataState and ExecErrState are the states of the converted alerting rules. Prometheus does not fire
+	// alerts when the expression returns no series, so OK is used if NoDataState is empty. Error is used if
+	// ExecErrState is empty.
+	NoDataState  models.NoDataState
+	ExecErrState models.ExecutionErrorState
+	// RecordingRulesTargetDatasourceUID is the data source the converted recording rules write to. If empty, the
+	// globally configured target is used.
+	RecordingRulesTargetDatasourceUID string
+}
+
+// Converter converts Prometheus rule groups to groups of Grafana-managed alerting and recording rules.
+// Each rule runs its expression as an instant query against the configured data source.
+type Converter struct {
+	cfg Config
+}
+
+func NewConverter(cfg Config) (*Converter, error) {
+	if cfg.DatasourceUID == "" {
+		return nil, ErrNoDatasource
+	}
+	if cfg.DatasourceType == "" {
+		cfg.DatasourceType = datasources.DS_PROMETHEUS
+	}
+	if cfg.DefaultInterval <= 0 {
+		return nil, fmt.Errorf("invalid default interval %s", cfg.DefaultInterval)
+	}
+	if cfg.NoDataState == "" {
+		cfg.NoDataState = models.OK
+	}
+	if cfg.ExecErrState == "" {
+		cfg.ExecErrState = models.ErrorErrState
+	}
+	return &Converter{cfg: cfg}, nil
+}
+
+// PrometheusRulesToGrafana converts the Prometheus rule groups to rule groups in the folder. Titles of rules must
+// be unique in a folder while Prometheus allows several alerting rules with the same name, so rules after the
+// first one with a given name get a numeric suffix. The suffixes only depend on the order of the rules, so that
+// converting the same groups again results in the same titles.
+func (c *Converter) PrometheusRulesToGrafana(orgID int64, namespaceUID string, groups []rulefmt.RuleGroup) ([]models.AlertRuleGroup, error) {
+	titles := make(map[string]int)
+	result := make([]models.AlertRuleGroup, 0, len(groups))
+	for _, group := range groups {
+		g, err := c.convertRuleGroup(orgID, namespaceUID, group, titles)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert rule group '%s': %w", group.Name, err)
+		}
+		result = append(result, g)
+	}
+	return result, nil
+}
+
+func (c *Converter) convertRuleGroup(orgID int64, namespaceUID string, group rulefmt.RuleGroup, titles map[string]int) (models.AlertRuleGroup, error) {
+	if group.Limit != 0 {
+		return models.AlertRuleGroup{}, fmt.Errorf("%w: limit", ErrUnsupportedField)
+	}
+	interval := time.Duration(group.Interval)
+	if interval == 0 {
+		interval = c.cfg.DefaultInterval
+	}
+
+	rules := make([]models.AlertRule, 0, len(group.Rules))
+	for i, node := range group.Rules {
+		rule, err := c.convertRule(orgID, namespaceUID, group.Name, interval, node)
+		if err != nil {
+			return models.AlertRuleGroup{}, fmt.Errorf("failed to convert rule %d: %w", i+1, err)
+		}
+		titles[rule.Title]++
+		if n := titles[rule.Title]; n > 1 {
+			rule.Title = fmt.Sprintf("%s (%d)", rule.Title, n)
+		}
+		rule.RuleGroupIndex = i + 1
+		rules = append(rules, rule)
+	}
+
+	return models.AlertRuleGroup{
+		Title:     group.Name,
+		FolderUID: namespaceUID,
+		Interval:  int64(interval.Seconds()),
+		Rules:     rules,
+	}, nil
+}
+
+func (c *Converter) convertRule(orgID int64, namespaceUID, group string, interval time.Duration, node rulefmt.RuleNode) (models.AlertRule, error) {
+	query, err := c.createQuery(node.Expr.Value)
+	if err != nil {
+		return models.AlertRule{}, err
+	}
+	rule := models.AlertRule{
+		OrgID:           orgID,
+		NamespaceUID:    namespaceUID,
+		RuleGroup:       group,
+		IntervalSeconds: int64(interval.Seconds()),
+		Labels:          node.Labels,
+		Annotations:     node.Annotations,
+	}
+
+	if node.Record.Value != "" {
+		rule.Title = node.Record.Value
+		rule.Data = []models.AlertQuery{query}
+		rule.Record = &models.Record{
+			Metric:              node.Record.Value,
+			From:                QueryRefID,
+			TargetDatasourceUID: c.cfg.RecordingRulesTargetDatasourceUID,
+		}
+		return rule, nil
+	}
+
+	rule.Title = node.Alert.Value
+	rule.Data = []models.AlertQuery{query, createMathExpression(), createThresholdExpression()}
+	rule.Condition = ThresholdRefID
+	rule.For = time.Duration(node.For)
+	rule.KeepFiringFor = time.Duration(node.KeepFiringFor)
+	rule.NoDataState = c.cfg.NoDataState
+	rule.ExecErrState = c.cfg.ExecErrState
+	return rule, nil
+}
+
+func (c *Converter) createQuery(expression string) (models.AlertQuery, error) {
+	model, err := json.Marshal(map[string]any{
+		"refId":   QueryRefID,
+		"expr":    expression,
+		"instant": true,
+		"range":   false,
+		"datasource": map[string]string{
+			"type": c.cfg.DatasourceType,
+			"uid":  c.cfg.DatasourceUID,
+		},
+	})
+	if err != nil {
+		return models.AlertQuery{}, err
+	}
+	return models.AlertQuery{
+		RefID:             QueryRefID,
+		DatasourceUID:     c.cfg.DatasourceUID,
+		RelativeTimeRange: models.RelativeTimeRange{From: models.Duration(queryTimeRange)},
+		Model:             model,
+	}, nil
+}
+
+func createMathExpression() models.AlertQuery {
+	return createExpression(MathRefID, map[string]any{
+		"type":       "math",
+		"expression": fmt.Sprintf("is_number($%[1]s) || is_nan($%[1]s) || is_inf($%[1]s)", QueryRefID),
+	})
+}
+
+func createThresholdExpression() models.AlertQuery {
+	return createExpression(ThresholdRefID, map[string]any{
+		"type":       "threshold",
+		"expression": MathRefID,
+		"conditions": []any{
+			map[string]any{
+				"evaluator": map[string]any{
+					"type":   "gt",
+					"params": []float64{0},
+				},
+			},
+		},
+	})
+}
+
+func createExpression(refID string, props map[string]any) models.AlertQuery {
+	props["refId"] = refID
+	props["datasource"] = map[string]string{
+		"type": expr.DatasourceType,
+		"uid":  expr.DatasourceUID,
+	}
+	// Marshalling a map of strings, numbers and slices cannot fail.
+	model, _ := json.Marshal(props)
+	return models.AlertQuery{
+		RefID:         refID,
+		QueryType:     expr.DatasourceType,
+		DatasourceUID: expr.DatasourceUID,
+		Model:         model,
+	}
+}
@@ -0,0 +1,139 @@
+package prom
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/rulefmt"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestNewConverter(t *testing.T) {
+	t.Run("requires data source", func(t *testing.T) {
+		_, err := NewConverter(Config{DefaultInterval: time.Minute})
+		require.ErrorIs(t, err, ErrNoDatasource)
+	})
+
+	t.Run("requires default interval", func(t *testing.T) {
+		_, err := NewConverter(Config{DatasourceUID: "prometheus"})
+		require.Error(t, err)
+	})
+
+	t.Run("sets defaults", func(t *testing.T) {
+		c, err := NewConverter(Config{DatasourceUID: "prometheus", DefaultInterval: time.Minute})
+		require.NoError(t, err)
+		require.Equal(t, "prometheus", c.cfg.DatasourceType)
+		require.Equal(t, models.OK, c.cfg.NoDataState)
+		require.Equal(t, models.ErrorErrState, c.cfg.ExecErrState)
+	})
+}
+
+func TestPrometheusRulesToGrafana(t *testing.T) {
+	c, err := NewConverter(Config{
+		DatasourceUID:                     "prometheus",
+		DefaultInterval:                   time.Minute,
+		RecordingRulesTargetDatasourceUID: "target",
+	})
+	require.NoError(t, err)
+
+	t.Run("converts alerting rules", func(t *testing.T) {
+		groups, err := c.PrometheusRulesToGrafana(1, "folder", []rulefmt.RuleGroup{{
+			Name:     "group",
+			Interval: model.Duration(30 * time.Second),
+			Rules: []rulefmt.RuleNode{{
+				Alert:         yamlString("HighLatency"),
+				Expr:          yamlString("latency_seconds > 1"),
+				For:           model.Duration(5 * time.Minute),
+				KeepFiringFor: model.Duration(time.Minute),
+				Labels:        map[string]string{"severity": "warning"},
+				Annotations:   map[string]string{"summary": "latency is high"},
+			}},
+		}})
+		require.NoError(t, err)
+		require.Len(t, groups, 1)
+		require.Equal(t, "group", groups[0].Title)
+		require.Equal(t, "folder", groups[0].FolderUID)
+		require.EqualValues(t, 30, groups[0].Interval)
+		require.Len(t, groups[0].Rules, 1)
+
+		rule := groups[0].Rules[0]
+		require.EqualValues(t, 1, rule.OrgID)
+		require.Equal(t, "folder", rule.NamespaceUID)
+		require.Equal(t, "group", rule.RuleGroup)
+		require.Equal(t, "HighLatency", rule.Title)
+		require.EqualValues(t, 30, rule.IntervalSeconds)
+		require.Equal(t, 5*time.Minute, rule.For)
+		require.Equal(t, time.Minute, rule.KeepFiringFor)
+		require.Equal(t, map[string]string{"severity": "warning"}, rule.Labels)
+		require.Equal(t, map[string]string{"summary": "latency is high"}, rule.Annotations)
+		require.Equal(t, models.OK, rule.NoDataState)
+		require.Equal(t, models.ErrorErrState, rule.ExecErrState)
+		require.Equal(t, 1, rule.RuleGroupIndex)
+		require.Nil(t, rule.Record)
+
+		require.Equal(t, ThresholdRefID, rule.Condition)
+		require.Len(t, rule.Data, 3)
+		require.Equal(t, QueryRefID, rule.Data[0].RefID)
+		require.Equal(t, "prometheus", rule.Data[0].DatasourceUID)
+		query := map[string]any{}
+		require.NoError(t, json.Unmarshal(rule.Data[0].Model, &query))
+		require.Equal(t, "latency_seconds > 1", query["expr"])
+		require.Equal(t, true, query["instant"])
+		require.Equal(t, MathRefID, rule.Data[1].RefID)
+		require.Equal(t, expr.DatasourceUID, rule.Data[1].DatasourceUID)
+		require.Equal(t, ThresholdRefID, rule.Data[2].RefID)
+		require.Equal(t, expr.DatasourceUID, rule.Data[2].DatasourceUID)
+	})
+
+	t.Run("converts recording rules", func(t *testing.T) {
+		groups, err := c.PrometheusRulesToGrafana(1, "folder", []rulefmt.RuleGroup{{
+			Name: "group",
+			Rules: []rulefmt.RuleNode{{
+				Record: yamlString("job:requests:rate5m"),
+				Expr:   yamlString("sum by (job) (rate(requests_total[5m]))"),
+				Labels: map[string]string{"team": "a"},
+			}},
+		}})
+		require.NoError(t, err)
+
+		rule := groups[0].Rules[0]
+		require.Equal(t, "job:requests:rate5m", rule.Title)
+		require.Equal(t, map[string]string{"team": "a"}, rule.Labels)
+		require.EqualValues(t, 60, rule.IntervalSeconds)
+		require.Empty(t, rule.Condition)
+		require.Len(t, rule.Data, 1)
+		require.Equal(t, &models.Record{Metric: "job:requests:rate5m", From: QueryRefID, TargetDatasourceUID: "target"}, rule.Record)
+	})
+
+	t.Run("adds suffix to duplicate titles", func(t *testing.T) {
+		node := rulefmt.RuleNode{Alert: yamlString("Down"), Expr: yamlString("up == 0")}
+		groups, err := c.PrometheusRulesToGrafana(1, "folder", []rulefmt.RuleGroup{
+			{Name: "group1", Rules: []rulefmt.RuleNode{node, node}},
+			{Name: "group2", Rules: []rulefmt.RuleNode{node}},
+		})
+		require.NoError(t, err)
+		require.Equal(t, "Down", groups[0].Rules[0].Title)
+		require.Equal(t, "Down (2)", groups[0].Rules[1].Title)
+		require.Equal(t, 2, groups[0].Rules[1].RuleGroupIndex)
+		require.Equal(t, "Down (3)", groups[1].Rules[0].Title)
+	})
+
+	t.Run("fails for groups with limit", func(t *testing.T) {
+		_, err := c.PrometheusRulesToGrafana(1, "folder", []rulefmt.RuleGroup{{
+			Name:  "group",
+			Limit: 10,
+			Rules: []rulefmt.RuleNode{{Alert: yamlString("Down"), Expr: yamlString("up == 0")}},
+		}})
+		require.ErrorIs(t, err, ErrUnsupportedField)
+	})
+}
+
+func yamlString(s string) yaml.Node {
+	return yaml.Node{Kind: yaml.ScalarNode, Value: s}
+}
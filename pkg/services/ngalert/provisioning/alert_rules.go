@@ -15,6 +15,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
 	"github.com/grafana/grafana/pkg/services/ngalert/provisioning/validation"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/ngalert/writer"
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/util"
 )
@@ -45,6 +46,7 @@ type AlertRuleService struct {
 	log                    log.Logger
 	nsValidatorProvider    NotificationSettingsValidatorProvider
 	authz                  ruleAccessControlService
+	datasources            writer.TargetDatasourceGetter
 }
 
 func NewAlertRuleService(ruleStore RuleStore,
@@ -58,6 +60,7 @@ func NewAlertRuleService(ruleStore RuleStore,
 	log log.Logger,
 	ns NotificationSettingsValidatorProvider,
 	authz RuleAccessControlService,
+	datasources writer.TargetDatasourceGetter,
 ) *AlertRuleService {
 	return &AlertRuleService{
 		defaultIntervalSeconds: defaultIntervalSeconds,
@@ -71,6 +74,7 @@ func NewAlertRuleService(ruleStore RuleStore,
 		log:                    log,
 		nsValidatorProvider:    ns,
 		authz:                  newRuleAccessControlService(authz),
+		datasources:            datasources,
 	}
 }
 
@@ -220,6 +224,7 @@ func (service *AlertRuleService) CreateAlertRule(ctx context.Context, user ident
 		return models.AlertRule{}, err
 	}
 	rule.Updated = time.Now()
+	rule.UpdatedBy = updatedBy(user)
 	if len(rule.NotificationSettings) > 0 {
 		validator, err := service.nsValidatorProvider.Validator(ctx, rule.OrgID)
 		if err != nil {
@@ -231,6 +236,16 @@ func (service *AlertRuleService) CreateAlertRule(ctx context.Context, user ident
 			}
 		}
 	}
+	delta := &store.GroupDelta{
+		GroupKey: rule.GetGroupKey(),
+		New:      []*models.AlertRule{&rule},
+	}
+	if err = service.validateRecordingTargets(ctx, delta); err != nil {
+		return models.AlertRule{}, err
+	}
+	if err = store.ValidateDependencies(ctx, service.ruleStore, delta); err != nil {
+		return models.AlertRule{}, err
+	}
 	err = service.xact.InTransaction(ctx, func(ctx context.Context) error {
 		ids, err := service.ruleStore.InsertAlertRules(ctx, []models.AlertRule{
 			rule,
@@ -321,6 +336,7 @@ func (service *AlertRuleService) UpdateRuleGroup(ctx context.Context, user ident
 			}
 			newRule := *rule
 			newRule.IntervalSeconds = intervalSeconds
+			newRule.UpdatedBy = updatedBy(user)
 			updateRules = append(updateRules, models.UpdateRule{
 				Existing: rule,
 				New:      newRule,
@@ -477,6 +493,12 @@ func (service *AlertRuleService) calcDelta(ctx context.Context, user identity.Re
 }
 
 func (service *AlertRuleService) persistDelta(ctx context.Context, user identity.Requester, delta *store.GroupDelta, provenance models.Provenance) error {
+	if err := service.validateRecordingTargets(ctx, delta); err != nil {
+		return err
+	}
+	if err := store.ValidateDependencies(ctx, service.ruleStore, delta); err != nil {
+		return err
+	}
 	return service.xact.InTransaction(ctx, func(ctx context.Context) error {
 		// Delete first as this could prevent future unique constraint violations.
 		if len(delta.Delete) > 0 {
@@ -506,6 +528,7 @@ func (service *AlertRuleService) persistDelta(ctx context.Context, user identity
 				if canUpdate := validation.CanUpdateProvenanceInRuleGroup(storedProvenance, provenance); !canUpdate {
 					return fmt.Errorf("cannot update with provided provenance '%s', needs '%s'", provenance, storedProvenance)
 				}
+				update.New.UpdatedBy = updatedBy(user)
 				updates = append(updates, models.UpdateRule{
 					Existing: update.Existing,
 					New:      *update.New,
@@ -522,7 +545,11 @@ func (service *AlertRuleService) persistDelta(ctx context.Context, user identity
 		}
 
 		if len(delta.New) > 0 {
-			uids, err := service.ruleStore.InsertAlertRules(ctx, withoutNilAlertRules(delta.New))
+			inserts := withoutNilAlertRules(delta.New)
+			for idx := range inserts {
+				inserts[idx].UpdatedBy = updatedBy(user)
+			}
+			uids, err := service.ruleStore.InsertAlertRules(ctx, inserts)
 			if err != nil {
 				return fmt.Errorf("failed to insert alert rules: %w", err)
 			}
@@ -596,12 +623,27 @@ func (service *AlertRuleService) UpdateAlertRule(ctx context.Context, user ident
 		}
 	}
 	rule.Updated = time.Now()
+	rule.UpdatedBy = updatedBy(user)
 	rule.ID = storedRule.ID
 	rule.IntervalSeconds = storedRule.IntervalSeconds
 	err = rule.SetDashboardAndPanelFromAnnotations()
 	if err != nil {
 		return models.AlertRule{}, err
 	}
+	delta := &store.GroupDelta{
+		GroupKey: rule.GetGroupKey(),
+		Update: []store.RuleDelta{{
+			Existing: storedRule,
+			New:      &rule,
+			Diff:     storedRule.Diff(&rule, store.AlertRuleFieldsToIgnoreInDiff[:]...),
+		}},
+	}
+	if err = service.validateRecordingTargets(ctx, delta); err != nil {
+		return models.AlertRule{}, err
+	}
+	if err = store.ValidateDependencies(ctx, service.ruleStore, delta); err != nil {
+		return models.AlertRule{}, err
+	}
 	err = service.xact.InTransaction(ctx, func(ctx context.Context) error {
 		err := service.ruleStore.UpdateAlertRules(ctx, []models.UpdateRule{
 			{
@@ -648,6 +690,14 @@ func (service *AlertRuleService) DeleteAlertRule(ctx context.Context, user ident
 		}
 	}
 
+	err = store.ValidateDependencies(ctx, service.ruleStore, &store.GroupDelta{
+		GroupKey: models.AlertRuleGroupKey{OrgID: rule.OrgID},
+		Delete:   []*models.AlertRule{rule},
+	})
+	if err != nil {
+		return err
+	}
+
 	// The single delete is idempotent, and doesn't error when deleting a group that already doesn't exist.
 	// This is different from deleting groups. We delete the rules directly rather than persisting a delta here to keep the semantics the same.
 	// TODO: Either persist a delta here as a breaking change, or deprecate this endpoint in favor of the group endpoint.
@@ -656,6 +706,18 @@ func (service *AlertRuleService) DeleteAlertRule(ctx context.Context, user ident
 	})
 }
 
+// validateRecordingTargets checks that recording rules of the changes can write to the data sources they target.
+func (service *AlertRuleService) validateRecordingTargets(ctx context.Context, delta *store.GroupDelta) error {
+	uids := delta.NewOrUpdatedRecordingTargets()
+	if len(uids) == 0 {
+		return nil
+	}
+	if err := writer.ValidateTargetDatasources(ctx, service.datasources, delta.GroupKey.OrgID, uids...); err != nil {
+		return errors.Join(models.ErrAlertRuleFailedValidation, err)
+	}
+	return nil
+}
+
 // checkLimitsTransactionCtx checks whether the current transaction (as identified by the ctx) breaches configured alert rule limits.
 func (service *AlertRuleService) checkLimitsTransactionCtx(ctx context.Context, user identity.Requester) error {
 	// default to 0 if there is no user
@@ -856,3 +918,12 @@ func (service *AlertRuleService) ensureRuleNamespace(ctx context.Context, user i
 
 	return nil
 }
+
+// updatedBy returns the login of the user that changes the rules, which is stored with the versions of the rules. It is
+// empty if there is no user.
+func updatedBy(user identity.Requester) string {
+	if user == nil {
+		return ""
+	}
+	return user.GetLogin()
+}
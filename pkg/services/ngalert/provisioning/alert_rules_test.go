@@ -21,6 +21,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	acmock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
 	"github.com/grafana/grafana/pkg/services/dashboards"
+	dsfakes "github.com/grafana/grafana/pkg/services/datasources/fakes"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/folder"
 	"github.com/grafana/grafana/pkg/services/folder/folderimpl"
@@ -75,6 +76,44 @@ func TestAlertRuleService(t *testing.T) {
 		require.Equal(t, interval, rule.IntervalSeconds)
 	})
 
+	t.Run("changes should be stored with the login of the user", func(t *testing.T) {
+		editor := &user.SignedInUser{UserID: 1, OrgID: orgID, Login: "editor"}
+		admin := &user.SignedInUser{UserID: 2, OrgID: orgID, Login: "admin"}
+		getUpdatedBy := func(uid string) string {
+			rule, _, err := ruleService.GetAlertRule(context.Background(), u, uid)
+			require.NoError(t, err)
+			return rule.UpdatedBy
+		}
+
+		rule := dummyRule("test-updated-by", orgID)
+		rule.RuleGroup = "updated-by"
+		rule, err := ruleService.CreateAlertRule(context.Background(), editor, rule, models.ProvenanceNone)
+		require.NoError(t, err)
+		require.Equal(t, "editor", getUpdatedBy(rule.UID))
+
+		require.NoError(t, ruleService.UpdateRuleGroup(context.Background(), admin, rule.NamespaceUID, rule.RuleGroup, 120))
+		require.Equal(t, "admin", getUpdatedBy(rule.UID))
+
+		rule, _, err = ruleService.GetAlertRule(context.Background(), u, rule.UID)
+		require.NoError(t, err)
+		rule.Title = "test-updated-by-renamed"
+		_, err = ruleService.UpdateAlertRule(context.Background(), editor, rule, models.ProvenanceNone)
+		require.NoError(t, err)
+		require.Equal(t, "editor", getUpdatedBy(rule.UID))
+
+		group, err := ruleService.GetRuleGroup(context.Background(), u, rule.NamespaceUID, rule.RuleGroup)
+		require.NoError(t, err)
+		group.Rules[0].Title = "test-updated-by-replaced"
+		group.Rules = append(group.Rules, createTestRule("test-updated-by-new", rule.RuleGroup, orgID, rule.NamespaceUID))
+		require.NoError(t, ruleService.ReplaceRuleGroup(context.Background(), admin, group, models.ProvenanceNone))
+		group, err = ruleService.GetRuleGroup(context.Background(), u, rule.NamespaceUID, rule.RuleGroup)
+		require.NoError(t, err)
+		require.Len(t, group.Rules, 2)
+		for _, r := range group.Rules {
+			require.Equal(t, "admin", r.UpdatedBy)
+		}
+	})
+
 	t.Run("if a folder was renamed the interval should be fetched from the renamed folder", func(t *testing.T) {
 		var orgID int64 = 2
 		rule := dummyRule("test#1", orgID)
@@ -573,6 +612,24 @@ func TestCreateAlertRule(t *testing.T) {
 		return service, ruleStore, provenanceStore, ac
 	}
 
+	t.Run("should reject recording rules that write to unknown data sources", func(t *testing.T) {
+		rule := gen.With(gen.WithOrgID(orgID), gen.WithAllRecordingRules()).Generate()
+		rule.Record.TargetDatasourceUID = "unknown"
+		service, ruleStore, _, ac := initServiceWithData(t)
+		ac.CanWriteAllRulesFunc = func(ctx context.Context, user identity.Requester) (bool, error) {
+			return true, nil
+		}
+
+		_, err := service.CreateAlertRule(context.Background(), u, rule, models.ProvenanceAPI)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+
+		inserts := ruleStore.GetRecordedCommands(func(cmd any) (any, bool) {
+			a, ok := cmd.([]models.AlertRule)
+			return a, ok
+		})
+		require.Empty(t, inserts)
+	})
+
 	t.Run("when user can write all rules", func(t *testing.T) {
 		t.Run("and a new rule creates a new group", func(t *testing.T) {
 			rule := gen.With(gen.WithOrgID(orgID)).Generate()
@@ -940,6 +997,20 @@ func TestDeleteAlertRule(t *testing.T) {
 		deletes := getDeleteQueries(ruleStore)
 		require.Len(t, deletes, 1)
 	})
+	t.Run("it should not delete rules other rules depend on", func(t *testing.T) {
+		service, ruleStore, _, ac := initServiceWithData(t)
+		ac.CanWriteAllRulesFunc = func(ctx context.Context, user identity.Requester) (bool, error) {
+			return true, nil
+		}
+		downstream := gen.With(gen.WithGroupKey(groupKey), gen.WithDependsOn(rules[0].UID)).GenerateRef()
+		ruleStore.PutRule(context.Background(), downstream)
+
+		err := service.DeleteAlertRule(context.Background(), u, rules[0].UID, groupProvenance)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+
+		deletes := getDeleteQueries(ruleStore)
+		require.Empty(t, deletes)
+	})
 	t.Run("when user cannot write all rules", func(t *testing.T) {
 		rule := models.CopyRule(rules[0])
 		rule.Title = rule.Title + "_new"
@@ -1663,6 +1734,7 @@ func initService(t *testing.T) (*AlertRuleService, *fakes.RuleStore, *fakes.Fake
 		defaultIntervalSeconds: 60,
 		authz:                  ac,
 		nsValidatorProvider:    &NotificationSettingsValidatorProviderFake{},
+		datasources:            &dsfakes.FakeDataSourceService{},
 	}
 
 	return service, ruleStore, provenanceStore, ac
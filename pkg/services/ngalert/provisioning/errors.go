@@ -26,6 +26,14 @@ var (
 	ErrTemplateInvalid  = errutil.BadRequest("alerting.notifications.templates.invalidFormat").MustTemplate("Invalid format of the submitted template", errutil.WithPublic("Template is in invalid format. Correct the payload and try again."))
 	ErrTemplateExists   = errutil.BadRequest("alerting.notifications.templates.nameExists", errutil.WithPublicMessage("Template file with this name already exists. Use a different name or update existing one."))
 
+	ErrMaintenanceWindowNotFound = errutil.NotFound("alerting.maintenance-windows.notFound", errutil.WithPublicMessage("Maintenance window not found"))
+	ErrMaintenanceWindowExists   = errutil.BadRequest("alerting.maintenance-windows.titleExists", errutil.WithPublicMessage("Maintenance window with this title already exists. Use a different title or update existing one."))
+	ErrMaintenanceWindowInvalid  = errutil.BadRequest("alerting.maintenance-windows.invalidFormat").MustTemplate("Invalid format of the submitted maintenance window", errutil.WithPublic("Maintenance window is in invalid format: {{ .Public.Error }}"))
+
+	ErrEscalationChainNotFound = errutil.NotFound("alerting.escalation-chains.notFound", errutil.WithPublicMessage("Escalation chain not found"))
+	ErrEscalationChainExists   = errutil.BadRequest("alerting.escalation-chains.titleExists", errutil.WithPublicMessage("Escalation chain with this title or contact point already exists. Use a different title or contact point, or update existing one."))
+	ErrEscalationChainInvalid  = errutil.BadRequest("alerting.escalation-chains.invalidFormat").MustTemplate("Invalid format of the submitted escalation chain", errutil.WithPublic("Escalation chain is in invalid format: {{ .Public.Error }}"))
+
 	ErrContactPointReferenced = errutil.Conflict("alerting.notifications.contact-points.referenced", errutil.WithPublicMessage("Contact point is currently referenced by a notification policy."))
 	ErrContactPointUsedInRule = errutil.Conflict("alerting.notifications.contact-points.used-by-rule", errutil.WithPublicMessage("Contact point is currently used in the notification settings of one or many alert rules."))
 )
@@ -90,3 +98,27 @@ func MakeErrTimeIntervalDependentResourcesProvenance(usedByRoutes bool, rules []
 		Public: data,
 	})
 }
+
+// MakeErrMaintenanceWindowInvalid creates an error with the ErrMaintenanceWindowInvalid template
+func MakeErrMaintenanceWindowInvalid(err error) error {
+	data := errutil.TemplateData{
+		Public: map[string]interface{}{
+			"Error": err.Error(),
+		},
+		Error: err,
+	}
+
+	return ErrMaintenanceWindowInvalid.Build(data)
+}
+
+// MakeErrEscalationChainInvalid creates an error with the ErrEscalationChainInvalid template
+func MakeErrEscalationChainInvalid(err error) error {
+	data := errutil.TemplateData{
+		Public: map[string]interface{}{
+			"Error": err.Error(),
+		},
+		Error: err,
+	}
+
+	return ErrEscalationChainInvalid.Build(data)
+}
@@ -0,0 +1,204 @@
+package provisioning
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/legacy_storage"
+	"github.com/grafana/grafana/pkg/services/ngalert/provisioning/validation"
+)
+
+type EscalationChainService struct {
+	store           EscalationChainStore
+	configStore     alertmanagerConfigStore
+	provenanceStore ProvisioningStore
+	xact            TransactionManager
+	log             log.Logger
+	validator       validation.ProvenanceStatusTransitionValidator
+}
+
+func NewEscalationChainService(store EscalationChainStore, config alertmanagerConfigStore, prov ProvisioningStore, xact TransactionManager, log log.Logger) *EscalationChainService {
+	return &EscalationChainService{
+		store:           store,
+		configStore:     config,
+		provenanceStore: prov,
+		xact:            xact,
+		log:             log,
+		validator:       validation.ValidateProvenanceRelaxed,
+	}
+}
+
+// GetEscalationChains returns all escalation chains of the organization ordered by title.
+func (svc *EscalationChainService) GetEscalationChains(ctx context.Context, orgID int64) ([]models.EscalationChain, error) {
+	chains, err := svc.store.ListEscalationChains(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if len(chains) == 0 {
+		return []models.EscalationChain{}, nil
+	}
+
+	provenances, err := svc.provenanceStore.GetProvenances(ctx, orgID, (&models.EscalationChain{}).ResourceType())
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]models.EscalationChain, 0, len(chains))
+	for _, c := range chains {
+		if prov, ok := provenances[c.ResourceID()]; ok {
+			c.Provenance = prov
+		}
+		result = append(result, *c)
+	}
+	return result, nil
+}
+
+// GetEscalationChain returns the escalation chain with the given UID. If it does not exist, ErrEscalationChainNotFound is returned.
+func (svc *EscalationChainService) GetEscalationChain(ctx context.Context, orgID int64, uid string) (models.EscalationChain, error) {
+	c, err := svc.getEscalationChain(ctx, orgID, uid)
+	if err != nil {
+		return models.EscalationChain{}, err
+	}
+	prov, err := svc.provenanceStore.GetProvenance(ctx, c, orgID)
+	if err != nil {
+		return models.EscalationChain{}, err
+	}
+	c.Provenance = prov
+	return *c, nil
+}
+
+// CreateEscalationChain adds a new escalation chain to the organization. The created escalation chain is returned.
+func (svc *EscalationChainService) CreateEscalationChain(ctx context.Context, orgID int64, c models.EscalationChain) (models.EscalationChain, error) {
+	c.OrgID = orgID
+	if err := svc.validate(ctx, &c); err != nil {
+		return models.EscalationChain{}, err
+	}
+
+	existing, err := svc.store.ListEscalationChains(ctx, orgID)
+	if err != nil {
+		return models.EscalationChain{}, err
+	}
+	for _, e := range existing {
+		if e.Title == c.Title || e.Receiver == c.Receiver || (c.UID != "" && e.UID == c.UID) {
+			return models.EscalationChain{}, ErrEscalationChainExists.Errorf("")
+		}
+	}
+
+	err = svc.xact.InTransaction(ctx, func(ctx context.Context) error {
+		if err := svc.store.InsertEscalationChain(ctx, &c); err != nil {
+			return err
+		}
+		return svc.provenanceStore.SetProvenance(ctx, &c, orgID, c.Provenance)
+	})
+	if err != nil {
+		return models.EscalationChain{}, err
+	}
+	return c, nil
+}
+
+// UpdateEscalationChain replaces the escalation chain with the same UID. The alert groups that are escalated by the
+// chain keep their position in it. If the escalation chain does not exist, ErrEscalationChainNotFound is returned.
+func (svc *EscalationChainService) UpdateEscalationChain(ctx context.Context, orgID int64, c models.EscalationChain) (models.EscalationChain, error) {
+	c.OrgID = orgID
+	if err := svc.validate(ctx, &c); err != nil {
+		return models.EscalationChain{}, err
+	}
+
+	existing, err := svc.store.ListEscalationChains(ctx, orgID)
+	if err != nil {
+		return models.EscalationChain{}, err
+	}
+	found := false
+	for _, e := range existing {
+		if e.UID == c.UID {
+			found = true
+			continue
+		}
+		if e.Title == c.Title || e.Receiver == c.Receiver {
+			return models.EscalationChain{}, ErrEscalationChainExists.Errorf("")
+		}
+	}
+	if !found {
+		return models.EscalationChain{}, ErrEscalationChainNotFound.Errorf("")
+	}
+
+	// check that provenance is not changed in an invalid way
+	storedProvenance, err := svc.provenanceStore.GetProvenance(ctx, &c, orgID)
+	if err != nil {
+		return models.EscalationChain{}, err
+	}
+	if err := svc.validator(storedProvenance, c.Provenance); err != nil {
+		return models.EscalationChain{}, err
+	}
+
+	err = svc.xact.InTransaction(ctx, func(ctx context.Context) error {
+		if err := svc.store.UpdateEscalationChain(ctx, &c); err != nil {
+			return err
+		}
+		return svc.provenanceStore.SetProvenance(ctx, &c, orgID, c.Provenance)
+	})
+	if err != nil {
+		return models.EscalationChain{}, err
+	}
+	return svc.GetEscalationChain(ctx, orgID, c.UID)
+}
+
+// DeleteEscalationChain deletes the escalation chain with the given UID. The alert groups that were escalated by the
+// chain are no longer escalated. If the escalation chain does not exist, no error is returned.
+func (svc *EscalationChainService) DeleteEscalationChain(ctx context.Context, orgID int64, uid string, provenance models.Provenance) error {
+	existing, err := svc.getEscalationChain(ctx, orgID, uid)
+	if err != nil {
+		if errors.Is(err, ErrEscalationChainNotFound) {
+			svc.log.FromContext(ctx).Debug("Escalation chain was not found. Skip deleting", "uid", uid)
+			return nil
+		}
+		return err
+	}
+
+	// check that provenance is not changed in an invalid way
+	storedProvenance, err := svc.provenanceStore.GetProvenance(ctx, existing, orgID)
+	if err != nil {
+		return err
+	}
+	if err := svc.validator(storedProvenance, provenance); err != nil {
+		return err
+	}
+
+	return svc.xact.InTransaction(ctx, func(ctx context.Context) error {
+		if err := svc.store.DeleteEscalationChain(ctx, orgID, uid); err != nil {
+			return err
+		}
+		return svc.provenanceStore.DeleteProvenance(ctx, existing, orgID)
+	})
+}
+
+// validate checks the escalation chain and that its contact points exist.
+func (svc *EscalationChainService) validate(ctx context.Context, c *models.EscalationChain) error {
+	if err := c.Validate(); err != nil {
+		return MakeErrEscalationChainInvalid(err)
+	}
+	rev, err := svc.configStore.Get(ctx, c.OrgID)
+	if err != nil {
+		return err
+	}
+	for _, name := range c.Receivers() {
+		if rev.GetReceiver(legacy_storage.NameToUid(name)) == nil {
+			return MakeErrEscalationChainInvalid(fmt.Errorf("contact point '%s' does not exist", name))
+		}
+	}
+	return nil
+}
+
+func (svc *EscalationChainService) getEscalationChain(ctx context.Context, orgID int64, uid string) (*models.EscalationChain, error) {
+	c, err := svc.store.GetEscalationChain(ctx, orgID, uid)
+	if err != nil {
+		if errors.Is(err, models.ErrEscalationChainNotFound) {
+			return nil, ErrEscalationChainNotFound.Errorf("")
+		}
+		return nil, err
+	}
+	return c, nil
+}
@@ -0,0 +1,190 @@
+package provisioning
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/legacy_storage"
+	"github.com/grafana/grafana/pkg/services/ngalert/provisioning/validation"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func TestEscalationChainService(t *testing.T) {
+	orgID := int64(1)
+	newChain := func(title string) models.EscalationChain {
+		return models.EscalationChain{
+			Title:    title,
+			Receiver: "grafana-default-email",
+			Steps:    []models.EscalationStep{{Receiver: "slack receiver", Delay: 5 * time.Minute}},
+		}
+	}
+
+	t.Run("creates escalation chain with provenance", func(t *testing.T) {
+		sut, store, prov := createEscalationChainSvcSut()
+		prov.EXPECT().SetProvenance(mock.Anything, mock.Anything, orgID, models.ProvenanceAPI).Return(nil)
+
+		c := newChain("ops")
+		c.Provenance = models.ProvenanceAPI
+		result, err := sut.CreateEscalationChain(context.Background(), orgID, c)
+		require.NoError(t, err)
+		require.NotEmpty(t, result.UID)
+		require.Equal(t, orgID, result.OrgID)
+		require.Len(t, store.chains, 1)
+		prov.AssertExpectations(t)
+	})
+
+	t.Run("rejects invalid escalation chain", func(t *testing.T) {
+		sut, store, _ := createEscalationChainSvcSut()
+		for _, mutate := range []func(c *models.EscalationChain){
+			func(c *models.EscalationChain) { c.Title = "" },
+			func(c *models.EscalationChain) { c.Receiver = "" },
+			func(c *models.EscalationChain) { c.Steps = nil },
+			func(c *models.EscalationChain) { c.Steps[0].Delay = 0 },
+			func(c *models.EscalationChain) { c.Receiver = "missing" },
+			func(c *models.EscalationChain) { c.Steps[0].Receiver = "missing" },
+		} {
+			c := newChain("ops")
+			mutate(&c)
+			_, err := sut.CreateEscalationChain(context.Background(), orgID, c)
+			require.ErrorIs(t, err, ErrEscalationChainInvalid)
+		}
+		require.Empty(t, store.chains)
+	})
+
+	t.Run("rejects duplicate title or contact point", func(t *testing.T) {
+		sut, store, _ := createEscalationChainSvcSut()
+		store.put(orgID, newChain("ops"))
+
+		_, err := sut.CreateEscalationChain(context.Background(), orgID, newChain("ops"))
+		require.ErrorIs(t, err, ErrEscalationChainExists)
+		_, err = sut.CreateEscalationChain(context.Background(), orgID, newChain("other"))
+		require.ErrorIs(t, err, ErrEscalationChainExists)
+	})
+
+	t.Run("updates escalation chain", func(t *testing.T) {
+		sut, store, prov := createEscalationChainSvcSut()
+		existing := store.put(orgID, newChain("ops"))
+		prov.EXPECT().GetProvenance(mock.Anything, mock.Anything, orgID).Return(models.ProvenanceNone, nil)
+		prov.EXPECT().SetProvenance(mock.Anything, mock.Anything, orgID, models.ProvenanceNone).Return(nil)
+
+		update := newChain("operations")
+		update.UID = existing.UID
+		result, err := sut.UpdateEscalationChain(context.Background(), orgID, update)
+		require.NoError(t, err)
+		require.Equal(t, "operations", result.Title)
+		require.Equal(t, "operations", store.chains[existing.UID].Title)
+	})
+
+	t.Run("update returns not found", func(t *testing.T) {
+		sut, _, _ := createEscalationChainSvcSut()
+		update := newChain("ops")
+		update.UID = "missing"
+		_, err := sut.UpdateEscalationChain(context.Background(), orgID, update)
+		require.ErrorIs(t, err, ErrEscalationChainNotFound)
+	})
+
+	t.Run("does not update provisioned escalation chain from API", func(t *testing.T) {
+		sut, store, prov := createEscalationChainSvcSut()
+		sut.validator = validation.ValidateProvenanceRelaxed
+		existing := store.put(orgID, newChain("ops"))
+		prov.EXPECT().GetProvenance(mock.Anything, mock.Anything, orgID).Return(models.ProvenanceFile, nil)
+
+		update := newChain("operations")
+		update.UID = existing.UID
+		_, err := sut.UpdateEscalationChain(context.Background(), orgID, update)
+		require.ErrorIs(t, err, validation.ErrProvenanceChangeNotAllowed)
+		require.Equal(t, "ops", store.chains[existing.UID].Title)
+	})
+
+	t.Run("deletes escalation chain and provenance", func(t *testing.T) {
+		sut, store, prov := createEscalationChainSvcSut()
+		existing := store.put(orgID, newChain("ops"))
+		prov.EXPECT().GetProvenance(mock.Anything, mock.Anything, orgID).Return(models.ProvenanceNone, nil)
+		prov.EXPECT().DeleteProvenance(mock.Anything, mock.Anything, orgID).Return(nil)
+
+		require.NoError(t, sut.DeleteEscalationChain(context.Background(), orgID, existing.UID, models.ProvenanceNone))
+		require.Empty(t, store.chains)
+		prov.AssertExpectations(t)
+
+		require.NoError(t, sut.DeleteEscalationChain(context.Background(), orgID, existing.UID, models.ProvenanceNone))
+	})
+}
+
+func createEscalationChainSvcSut() (*EscalationChainService, *fakeEscalationChainStore, *MockProvisioningStore) {
+	store := &fakeEscalationChainStore{chains: map[string]*models.EscalationChain{}}
+	prov := &MockProvisioningStore{}
+	return &EscalationChainService{
+		store:           store,
+		configStore:     legacy_storage.NewAlertmanagerConfigStore(fakes.NewFakeAlertmanagerConfigStore(defaultAlertmanagerConfigJSON)),
+		provenanceStore: prov,
+		xact:            newNopTransactionManager(),
+		log:             log.NewNopLogger(),
+		validator: func(from, to models.Provenance) error {
+			return nil
+		},
+	}, store, prov
+}
+
+type fakeEscalationChainStore struct {
+	chains map[string]*models.EscalationChain
+}
+
+func (f *fakeEscalationChainStore) put(orgID int64, c models.EscalationChain) models.EscalationChain {
+	c.OrgID = orgID
+	c.UID = util.GenerateShortUID()
+	f.chains[c.UID] = &c
+	return c
+}
+
+func (f *fakeEscalationChainStore) ListEscalationChains(_ context.Context, orgID int64) ([]*models.EscalationChain, error) {
+	var result []*models.EscalationChain
+	for _, c := range f.chains {
+		if c.OrgID == orgID {
+			cp := *c
+			result = append(result, &cp)
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeEscalationChainStore) GetEscalationChain(_ context.Context, orgID int64, uid string) (*models.EscalationChain, error) {
+	c, ok := f.chains[uid]
+	if !ok || c.OrgID != orgID {
+		return nil, models.ErrEscalationChainNotFound
+	}
+	cp := *c
+	return &cp, nil
+}
+
+func (f *fakeEscalationChainStore) InsertEscalationChain(_ context.Context, c *models.EscalationChain) error {
+	if c.UID == "" {
+		c.UID = util.GenerateShortUID()
+	}
+	cp := *c
+	f.chains[c.UID] = &cp
+	return nil
+}
+
+func (f *fakeEscalationChainStore) UpdateEscalationChain(_ context.Context, c *models.EscalationChain) error {
+	if _, ok := f.chains[c.UID]; !ok {
+		return models.ErrEscalationChainNotFound
+	}
+	cp := *c
+	f.chains[c.UID] = &cp
+	return nil
+}
+
+func (f *fakeEscalationChainStore) DeleteEscalationChain(_ context.Context, _ int64, uid string) error {
+	if _, ok := f.chains[uid]; !ok {
+		return models.ErrEscalationChainNotFound
+	}
+	delete(f.chains, uid)
+	return nil
+}
@@ -0,0 +1,185 @@
+package provisioning
+
+import (
+	"context"
+	"errors"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/provisioning/validation"
+)
+
+type MaintenanceWindowService struct {
+	store           MaintenanceWindowStore
+	provenanceStore ProvisioningStore
+	xact            TransactionManager
+	log             log.Logger
+	validator       validation.ProvenanceStatusTransitionValidator
+}
+
+func NewMaintenanceWindowService(store MaintenanceWindowStore, prov ProvisioningStore, xact TransactionManager, log log.Logger) *MaintenanceWindowService {
+	return &MaintenanceWindowService{
+		store:           store,
+		provenanceStore: prov,
+		xact:            xact,
+		log:             log,
+		validator:       validation.ValidateProvenanceRelaxed,
+	}
+}
+
+// GetMaintenanceWindows returns all maintenance windows of the organization ordered by title.
+func (svc *MaintenanceWindowService) GetMaintenanceWindows(ctx context.Context, orgID int64) ([]models.MaintenanceWindow, error) {
+	windows, err := svc.store.ListMaintenanceWindows(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if len(windows) == 0 {
+		return []models.MaintenanceWindow{}, nil
+	}
+
+	provenances, err := svc.provenanceStore.GetProvenances(ctx, orgID, (&models.MaintenanceWindow{}).ResourceType())
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]models.MaintenanceWindow, 0, len(windows))
+	for _, w := range windows {
+		if prov, ok := provenances[w.ResourceID()]; ok {
+			w.Provenance = prov
+		}
+		result = append(result, *w)
+	}
+	return result, nil
+}
+
+// GetMaintenanceWindow returns the maintenance window with the given UID. If it does not exist, ErrMaintenanceWindowNotFound is returned.
+func (svc *MaintenanceWindowService) GetMaintenanceWindow(ctx context.Context, orgID int64, uid string) (models.MaintenanceWindow, error) {
+	w, err := svc.getMaintenanceWindow(ctx, orgID, uid)
+	if err != nil {
+		return models.MaintenanceWindow{}, err
+	}
+	prov, err := svc.provenanceStore.GetProvenance(ctx, w, orgID)
+	if err != nil {
+		return models.MaintenanceWindow{}, err
+	}
+	w.Provenance = prov
+	return *w, nil
+}
+
+// CreateMaintenanceWindow adds a new maintenance window to the organization. The created maintenance window is returned.
+func (svc *MaintenanceWindowService) CreateMaintenanceWindow(ctx context.Context, orgID int64, w models.MaintenanceWindow) (models.MaintenanceWindow, error) {
+	w.OrgID = orgID
+	if err := w.Validate(); err != nil {
+		return models.MaintenanceWindow{}, MakeErrMaintenanceWindowInvalid(err)
+	}
+
+	existing, err := svc.store.ListMaintenanceWindows(ctx, orgID)
+	if err != nil {
+		return models.MaintenanceWindow{}, err
+	}
+	for _, e := range existing {
+		if e.Title == w.Title || (w.UID != "" && e.UID == w.UID) {
+			return models.MaintenanceWindow{}, ErrMaintenanceWindowExists.Errorf("")
+		}
+	}
+
+	err = svc.xact.InTransaction(ctx, func(ctx context.Context) error {
+		if err := svc.store.InsertMaintenanceWindow(ctx, &w); err != nil {
+			return err
+		}
+		return svc.provenanceStore.SetProvenance(ctx, &w, orgID, w.Provenance)
+	})
+	if err != nil {
+		return models.MaintenanceWindow{}, err
+	}
+	return w, nil
+}
+
+// UpdateMaintenanceWindow replaces the maintenance window with the same UID. The silence of the occurrence in progress,
+// if any, is replaced by one that reflects the new definition. If the maintenance window does not exist,
+// ErrMaintenanceWindowNotFound is returned.
+func (svc *MaintenanceWindowService) UpdateMaintenanceWindow(ctx context.Context, orgID int64, w models.MaintenanceWindow) (models.MaintenanceWindow, error) {
+	w.OrgID = orgID
+	if err := w.Validate(); err != nil {
+		return models.MaintenanceWindow{}, MakeErrMaintenanceWindowInvalid(err)
+	}
+
+	existing, err := svc.store.ListMaintenanceWindows(ctx, orgID)
+	if err != nil {
+		return models.MaintenanceWindow{}, err
+	}
+	found := false
+	for _, e := range existing {
+		if e.UID == w.UID {
+			found = true
+			continue
+		}
+		if e.Title == w.Title {
+			return models.MaintenanceWindow{}, ErrMaintenanceWindowExists.Errorf("")
+		}
+	}
+	if !found {
+		return models.MaintenanceWindow{}, ErrMaintenanceWindowNotFound.Errorf("")
+	}
+
+	// check that provenance is not changed in an invalid way
+	storedProvenance, err := svc.provenanceStore.GetProvenance(ctx, &w, orgID)
+	if err != nil {
+		return models.MaintenanceWindow{}, err
+	}
+	if err := svc.validator(storedProvenance, w.Provenance); err != nil {
+		return models.MaintenanceWindow{}, err
+	}
+
+	err = svc.xact.InTransaction(ctx, func(ctx context.Context) error {
+		if err := svc.store.UpdateMaintenanceWindow(ctx, &w); err != nil {
+			return err
+		}
+		return svc.provenanceStore.SetProvenance(ctx, &w, orgID, w.Provenance)
+	})
+	if err != nil {
+		return models.MaintenanceWindow{}, err
+	}
+	return svc.GetMaintenanceWindow(ctx, orgID, w.UID)
+}
+
+// DeleteMaintenanceWindow deletes the maintenance window with the given UID. The silence of the occurrence in
+// progress, if any, is expired by the maintenance window scheduler. If the maintenance window does not exist, no
+// error is returned.
+func (svc *MaintenanceWindowService) DeleteMaintenanceWindow(ctx context.Context, orgID int64, uid string, provenance models.Provenance) error {
+	existing, err := svc.getMaintenanceWindow(ctx, orgID, uid)
+	if err != nil {
+		if errors.Is(err, ErrMaintenanceWindowNotFound) {
+			svc.log.FromContext(ctx).Debug("Maintenance window was not found. Skip deleting", "uid", uid)
+			return nil
+		}
+		return err
+	}
+
+	// check that provenance is not changed in an invalid way
+	storedProvenance, err := svc.provenanceStore.GetProvenance(ctx, existing, orgID)
+	if err != nil {
+		return err
+	}
+	if err := svc.validator(storedProvenance, provenance); err != nil {
+		return err
+	}
+
+	return svc.xact.InTransaction(ctx, func(ctx context.Context) error {
+		if err := svc.store.DeleteMaintenanceWindow(ctx, orgID, uid); err != nil {
+			return err
+		}
+		return svc.provenanceStore.DeleteProvenance(ctx, existing, orgID)
+	})
+}
+
+func (svc *MaintenanceWindowService) getMaintenanceWindow(ctx context.Context, orgID int64, uid string) (*models.MaintenanceWindow, error) {
+	w, err := svc.store.GetMaintenanceWindow(ctx, orgID, uid)
+	if err != nil {
+		if errors.Is(err, models.ErrMaintenanceWindowNotFound) {
+			return nil, ErrMaintenanceWindowNotFound.Errorf("")
+		}
+		return nil, err
+	}
+	return w, nil
+}
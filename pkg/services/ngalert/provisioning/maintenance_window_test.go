@@ -0,0 +1,188 @@
+package provisioning
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/provisioning/validation"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func TestMaintenanceWindowService(t *testing.T) {
+	orgID := int64(1)
+	newWindow := func(title string) models.MaintenanceWindow {
+		return models.MaintenanceWindow{
+			Title:    title,
+			Schedule: "0 2 * * 6",
+			Duration: 2 * time.Hour,
+			TimeZone: "Europe/Berlin",
+			Matchers: []string{`service="db"`},
+		}
+	}
+
+	t.Run("creates maintenance window with provenance", func(t *testing.T) {
+		sut, store, prov := createMaintenanceWindowSvcSut()
+		prov.EXPECT().SetProvenance(mock.Anything, mock.Anything, orgID, models.ProvenanceFile).Return(nil)
+
+		w := newWindow("db")
+		w.Provenance = models.ProvenanceFile
+		result, err := sut.CreateMaintenanceWindow(context.Background(), orgID, w)
+		require.NoError(t, err)
+		require.NotEmpty(t, result.UID)
+		require.Equal(t, orgID, result.OrgID)
+		require.Len(t, store.windows, 1)
+		prov.AssertExpectations(t)
+	})
+
+	t.Run("rejects invalid maintenance window", func(t *testing.T) {
+		sut, store, _ := createMaintenanceWindowSvcSut()
+		for _, mutate := range []func(w *models.MaintenanceWindow){
+			func(w *models.MaintenanceWindow) { w.Title = "" },
+			func(w *models.MaintenanceWindow) { w.Schedule = "every saturday" },
+			func(w *models.MaintenanceWindow) { w.Schedule = "RRULE:FREQ=HOURLY" },
+			func(w *models.MaintenanceWindow) { w.TimeZone = "Mars/Olympus" },
+			func(w *models.MaintenanceWindow) { w.Duration = 0 },
+			func(w *models.MaintenanceWindow) { w.Matchers = nil },
+			func(w *models.MaintenanceWindow) { w.Matchers = []string{"service"} },
+		} {
+			w := newWindow("db")
+			mutate(&w)
+			_, err := sut.CreateMaintenanceWindow(context.Background(), orgID, w)
+			require.ErrorIs(t, err, ErrMaintenanceWindowInvalid)
+		}
+		require.Empty(t, store.windows)
+	})
+
+	t.Run("rejects duplicate title", func(t *testing.T) {
+		sut, store, _ := createMaintenanceWindowSvcSut()
+		store.put(orgID, newWindow("db"))
+
+		_, err := sut.CreateMaintenanceWindow(context.Background(), orgID, newWindow("db"))
+		require.ErrorIs(t, err, ErrMaintenanceWindowExists)
+	})
+
+	t.Run("updates maintenance window", func(t *testing.T) {
+		sut, store, prov := createMaintenanceWindowSvcSut()
+		existing := store.put(orgID, newWindow("db"))
+		prov.EXPECT().GetProvenance(mock.Anything, mock.Anything, orgID).Return(models.ProvenanceNone, nil)
+		prov.EXPECT().SetProvenance(mock.Anything, mock.Anything, orgID, models.ProvenanceNone).Return(nil)
+
+		update := newWindow("database")
+		update.UID = existing.UID
+		result, err := sut.UpdateMaintenanceWindow(context.Background(), orgID, update)
+		require.NoError(t, err)
+		require.Equal(t, "database", result.Title)
+		require.Equal(t, "database", store.windows[existing.UID].Title)
+	})
+
+	t.Run("update returns not found", func(t *testing.T) {
+		sut, _, _ := createMaintenanceWindowSvcSut()
+		update := newWindow("db")
+		update.UID = "missing"
+		_, err := sut.UpdateMaintenanceWindow(context.Background(), orgID, update)
+		require.ErrorIs(t, err, ErrMaintenanceWindowNotFound)
+	})
+
+	t.Run("does not update provisioned maintenance window from API", func(t *testing.T) {
+		sut, store, prov := createMaintenanceWindowSvcSut()
+		sut.validator = validation.ValidateProvenanceRelaxed
+		existing := store.put(orgID, newWindow("db"))
+		prov.EXPECT().GetProvenance(mock.Anything, mock.Anything, orgID).Return(models.ProvenanceFile, nil)
+
+		update := newWindow("database")
+		update.UID = existing.UID
+		_, err := sut.UpdateMaintenanceWindow(context.Background(), orgID, update)
+		require.ErrorIs(t, err, validation.ErrProvenanceChangeNotAllowed)
+		require.Equal(t, "db", store.windows[existing.UID].Title)
+	})
+
+	t.Run("deletes maintenance window and provenance", func(t *testing.T) {
+		sut, store, prov := createMaintenanceWindowSvcSut()
+		existing := store.put(orgID, newWindow("db"))
+		prov.EXPECT().GetProvenance(mock.Anything, mock.Anything, orgID).Return(models.ProvenanceNone, nil)
+		prov.EXPECT().DeleteProvenance(mock.Anything, mock.Anything, orgID).Return(nil)
+
+		require.NoError(t, sut.DeleteMaintenanceWindow(context.Background(), orgID, existing.UID, models.ProvenanceNone))
+		require.Empty(t, store.windows)
+		prov.AssertExpectations(t)
+
+		require.NoError(t, sut.DeleteMaintenanceWindow(context.Background(), orgID, existing.UID, models.ProvenanceNone))
+	})
+}
+
+func createMaintenanceWindowSvcSut() (*MaintenanceWindowService, *fakeMaintenanceWindowStore, *MockProvisioningStore) {
+	store := &fakeMaintenanceWindowStore{windows: map[string]*models.MaintenanceWindow{}}
+	prov := &MockProvisioningStore{}
+	return &MaintenanceWindowService{
+		store:           store,
+		provenanceStore: prov,
+		xact:            newNopTransactionManager(),
+		log:             log.NewNopLogger(),
+		validator: func(from, to models.Provenance) error {
+			return nil
+		},
+	}, store, prov
+}
+
+type fakeMaintenanceWindowStore struct {
+	windows map[string]*models.MaintenanceWindow
+}
+
+func (f *fakeMaintenanceWindowStore) put(orgID int64, w models.MaintenanceWindow) models.MaintenanceWindow {
+	w.OrgID = orgID
+	w.UID = util.GenerateShortUID()
+	f.windows[w.UID] = &w
+	return w
+}
+
+func (f *fakeMaintenanceWindowStore) ListMaintenanceWindows(_ context.Context, orgID int64) ([]*models.MaintenanceWindow, error) {
+	var result []*models.MaintenanceWindow
+	for _, w := range f.windows {
+		if w.OrgID == orgID {
+			c := *w
+			result = append(result, &c)
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeMaintenanceWindowStore) GetMaintenanceWindow(_ context.Context, orgID int64, uid string) (*models.MaintenanceWindow, error) {
+	w, ok := f.windows[uid]
+	if !ok || w.OrgID != orgID {
+		return nil, models.ErrMaintenanceWindowNotFound
+	}
+	c := *w
+	return &c, nil
+}
+
+func (f *fakeMaintenanceWindowStore) InsertMaintenanceWindow(_ context.Context, w *models.MaintenanceWindow) error {
+	if w.UID == "" {
+		w.UID = util.GenerateShortUID()
+	}
+	c := *w
+	f.windows[w.UID] = &c
+	return nil
+}
+
+func (f *fakeMaintenanceWindowStore) UpdateMaintenanceWindow(_ context.Context, w *models.MaintenanceWindow) error {
+	if _, ok := f.windows[w.UID]; !ok {
+		return models.ErrMaintenanceWindowNotFound
+	}
+	c := *w
+	f.windows[w.UID] = &c
+	return nil
+}
+
+func (f *fakeMaintenanceWindowStore) DeleteMaintenanceWindow(_ context.Context, _ int64, uid string) error {
+	if _, ok := f.windows[uid]; !ok {
+		return models.ErrMaintenanceWindowNotFound
+	}
+	delete(f.windows, uid)
+	return nil
+}
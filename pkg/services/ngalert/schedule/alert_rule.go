@@ -59,6 +59,7 @@ func newRuleFactory(
 	clock clock.Clock,
 	rrCfg setting.RecordingRuleSettings,
 	met *metrics.Scheduler,
+	limiter *evaluationLimiter,
 	logger log.Logger,
 	tracer tracing.Tracer,
 	recordingWriter RecordingWriter,
@@ -76,6 +77,7 @@ func newRuleFactory(
 				rrCfg,
 				logger,
 				met,
+				limiter,
 				tracer,
 				recordingWriter,
 				evalAppliedHook,
@@ -94,6 +96,7 @@ func newRuleFactory(
 			ruleProvider,
 			clock,
 			met,
+			limiter,
 			logger,
 			tracer,
 			evalAppliedHook,
@@ -132,6 +135,7 @@ type alertRule struct {
 	stopAppliedHook stopAppliedFunc
 
 	metrics *metrics.Scheduler
+	limiter *evaluationLimiter
 	logger  log.Logger
 	tracer  tracing.Tracer
 }
@@ -148,6 +152,7 @@ func newAlertRule(
 	ruleProvider ruleProvider,
 	clock clock.Clock,
 	met *metrics.Scheduler,
+	limiter *evaluationLimiter,
 	logger log.Logger,
 	tracer tracing.Tracer,
 	evalAppliedHook func(ngmodels.AlertRuleKey, time.Time),
@@ -171,6 +176,7 @@ func newAlertRule(
 		evalAppliedHook:      evalAppliedHook,
 		stopAppliedHook:      stopAppliedHook,
 		metrics:              met,
+		limiter:              limiter,
 		logger:               logger.FromContext(ctx),
 		tracer:               tracer,
 	}
@@ -349,6 +355,12 @@ func (a *alertRule) Run() error {
 				states := a.stateManager.DeleteStateByRuleUID(ngmodels.WithRuleKey(ctx, a.key), a.key, ngmodels.StateReasonRuleDeleted)
 				a.expireAndSend(grafanaCtx, states)
 			}
+			// hand the state over to the instance that evaluates the rule now
+			if errors.Is(grafanaCtx.Err(), errRuleReassigned) {
+				ctx, cancelFunc := context.WithTimeout(context.Background(), time.Minute)
+				defer cancelFunc()
+				a.stateManager.ReleaseRule(ngmodels.WithRuleKey(ctx, a.key), a.key)
+			}
 			a.logger.Debug("Stopping alert rule routine")
 			return nil
 		}
@@ -363,6 +375,12 @@ func (a *alertRule) evaluate(ctx context.Context, e *Evaluation, span trace.Span
 	processDuration := a.metrics.ProcessDuration.WithLabelValues(orgID)
 	sendDuration := a.metrics.SendDuration.WithLabelValues(orgID)
 
+	release, err := a.limiter.wait(ctx, e.rule)
+	if err != nil {
+		span.SetStatus(codes.Error, "rule evaluation cancelled")
+		logger.Debug("Skip evaluation because the context has been cancelled while waiting for the evaluation limits", "error", err)
+		return nil
+	}
 	start := a.clock.Now()
 
 	evalCtx := eval.NewContextWithPreviousResults(ctx, SchedulerUserFor(e.rule.OrgID), a.newLoadedMetricsReader(e.rule))
@@ -379,6 +397,7 @@ func (a *alertRule) evaluate(ctx context.Context, e *Evaluation, span trace.Span
 			logger.Error("Failed to evaluate rule", "error", err, "duration", dur)
 		}
 	}
+	release()
 
 	evalAttemptTotal.Inc()
 
@@ -456,7 +475,9 @@ func (a *alertRule) evaluate(ctx context.Context, e *Evaluation, span trace.Span
 func (a *alertRule) send(ctx context.Context, logger log.Logger, states state.StateTransitions) definitions.PostableAlerts {
 	alerts := definitions.PostableAlerts{PostableAlerts: make([]models.PostableAlert, 0, len(states))}
 	for _, alertState := range states {
-		alerts.PostableAlerts = append(alerts.PostableAlerts, *state.StateToPostableAlert(alertState, a.appURL))
+		for _, alert := range state.StateToPostableAlerts(alertState, a.appURL) {
+			alerts.PostableAlerts = append(alerts.PostableAlerts, *alert)
+		}
 	}
 
 	if len(alerts.PostableAlerts) > 0 {
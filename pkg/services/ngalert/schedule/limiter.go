@@ -0,0 +1,155 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// limit restricts the evaluations of the rules that share a folder or a data source.
+type limit struct {
+	// sem has a slot for every rule that can be evaluated at the same time. It is nil if concurrency is not limited.
+	sem chan struct{}
+	// rate is nil if the rate of evaluations is not limited.
+	rate *rate.Limiter
+}
+
+func newLimit(cfg setting.EvaluationLimit) *limit {
+	l := &limit{}
+	if cfg.MaxConcurrent > 0 {
+		l.sem = make(chan struct{}, cfg.MaxConcurrent)
+	}
+	if cfg.Rate > 0 {
+		l.rate = rate.NewLimiter(rate.Limit(cfg.Rate), cfg.Burst)
+	}
+	return l
+}
+
+// evaluationLimiter makes rule evaluations wait until they are within the limits of their folder and data sources.
+// A nil evaluationLimiter does not limit anything.
+type evaluationLimiter struct {
+	cfg     setting.UnifiedAlertingEvaluationLimitsSettings
+	metrics *metrics.Scheduler
+
+	mtx    sync.Mutex
+	limits map[string]*limit
+}
+
+// newEvaluationLimiter returns nil if the settings do not limit evaluations.
+func newEvaluationLimiter(cfg setting.UnifiedAlertingEvaluationLimitsSettings, met *metrics.Scheduler) *evaluationLimiter {
+	if cfg.IsUnlimited() {
+		return nil
+	}
+	return &evaluationLimiter{
+		cfg:     cfg,
+		metrics: met,
+		limits:  make(map[string]*limit),
+	}
+}
+
+// wait blocks until the rule can be evaluated. The returned function must be called when the evaluation is done.
+// It returns an error if the context is cancelled while waiting.
+func (l *evaluationLimiter) wait(ctx context.Context, rule *ngmodels.AlertRule) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+	limits := l.limitsFor(rule)
+	if len(limits) == 0 {
+		return func() {}, nil
+	}
+
+	orgID := fmt.Sprint(rule.OrgID)
+	throttled := l.metrics.ThrottledEvaluations.WithLabelValues(orgID)
+	throttled.Inc()
+	start := time.Now()
+	defer func() {
+		throttled.Dec()
+		l.metrics.EvalThrottleDuration.WithLabelValues(orgID).Observe(time.Since(start).Seconds())
+	}()
+
+	acquired := make([]*limit, 0, len(limits))
+	release := func() {
+		for _, lim := range acquired {
+			if lim.sem != nil {
+				<-lim.sem
+			}
+		}
+	}
+	for _, lim := range limits {
+		if lim.sem != nil {
+			select {
+			case lim.sem <- struct{}{}:
+			case <-ctx.Done():
+				release()
+				return nil, ctx.Err()
+			}
+		}
+		acquired = append(acquired, lim)
+	}
+	for _, lim := range limits {
+		if lim.rate == nil {
+			continue
+		}
+		if err := lim.rate.Wait(ctx); err != nil {
+			release()
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, err
+		}
+	}
+	return release, nil
+}
+
+// limitsFor returns the limits that apply to the rule, in the same order for all rules so that
+// rules that wait for the same limits cannot deadlock.
+func (l *evaluationLimiter) limitsFor(rule *ngmodels.AlertRule) []*limit {
+	keys := make([]string, 0, len(rule.Data)+1)
+	for _, q := range rule.Data {
+		if expr.NodeTypeFromDatasourceUID(q.DatasourceUID) == expr.TypeDatasourceNode {
+			keys = append(keys, "datasource/"+q.DatasourceUID)
+		}
+	}
+	slices.Sort(keys)
+	keys = slices.Compact(keys)
+	keys = append(keys, "folder/"+rule.NamespaceUID)
+
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	result := make([]*limit, 0, len(keys))
+	for _, key := range keys {
+		lim, ok := l.limits[key]
+		if !ok {
+			lim = newLimit(l.configFor(key))
+			l.limits[key] = lim
+		}
+		if lim.sem != nil || lim.rate != nil {
+			result = append(result, lim)
+		}
+	}
+	return result
+}
+
+func (l *evaluationLimiter) configFor(key string) setting.EvaluationLimit {
+	if uid, ok := strings.CutPrefix(key, "datasource/"); ok {
+		if cfg, ok := l.cfg.Datasources[uid]; ok {
+			return cfg
+		}
+		return l.cfg.Datasource
+	}
+	uid, _ := strings.CutPrefix(key, "folder/")
+	if cfg, ok := l.cfg.Folders[uid]; ok {
+		return cfg
+	}
+	return l.cfg.Folder
+}
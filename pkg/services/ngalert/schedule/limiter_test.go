@@ -0,0 +1,147 @@
+package schedule
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func limitedRule(folderUID string, datasourceUIDs ...string) *ngmodels.AlertRule {
+	rule := &ngmodels.AlertRule{OrgID: 1, NamespaceUID: folderUID}
+	for _, uid := range datasourceUIDs {
+		rule.Data = append(rule.Data, ngmodels.AlertQuery{DatasourceUID: uid})
+	}
+	return rule
+}
+
+// waitBlocked returns true if the rule cannot be evaluated within a short time.
+func waitBlocked(t *testing.T, l *evaluationLimiter, rule *ngmodels.AlertRule) bool {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	release, err := l.wait(ctx, rule)
+	if err != nil {
+		return true
+	}
+	release()
+	return false
+}
+
+func TestEvaluationLimiter(t *testing.T) {
+	newLimiter := func(t *testing.T, cfg setting.UnifiedAlertingEvaluationLimitsSettings) (*evaluationLimiter, *metrics.Scheduler) {
+		t.Helper()
+		m := metrics.NewSchedulerMetrics(prometheus.NewPedanticRegistry())
+		l := newEvaluationLimiter(cfg, m)
+		require.NotNil(t, l)
+		return l, m
+	}
+
+	t.Run("should be nil if nothing is limited", func(t *testing.T) {
+		l := newEvaluationLimiter(setting.UnifiedAlertingEvaluationLimitsSettings{}, nil)
+		require.Nil(t, l)
+
+		release, err := l.wait(context.Background(), limitedRule("folder", "ds"))
+		require.NoError(t, err)
+		release()
+	})
+
+	t.Run("should limit concurrent evaluations of a data source", func(t *testing.T) {
+		l, m := newLimiter(t, setting.UnifiedAlertingEvaluationLimitsSettings{
+			Datasource: setting.EvaluationLimit{MaxConcurrent: 1},
+		})
+
+		release, err := l.wait(context.Background(), limitedRule("folder-1", "ds-1"))
+		require.NoError(t, err)
+
+		require.True(t, waitBlocked(t, l, limitedRule("folder-2", "ds-1")))
+		require.False(t, waitBlocked(t, l, limitedRule("folder-2", "ds-2")), "other data sources should not be limited")
+		require.False(t, waitBlocked(t, l, limitedRule("folder-1", expr.DatasourceUID)), "expressions should not be limited")
+
+		release()
+		require.False(t, waitBlocked(t, l, limitedRule("folder-2", "ds-1")))
+
+		require.Equal(t, 0.0, testutil.ToFloat64(m.ThrottledEvaluations.WithLabelValues("1")))
+		require.Equal(t, 1, testutil.CollectAndCount(m.EvalThrottleDuration), "wait time should be recorded")
+	})
+
+	t.Run("should use the limit of the folder", func(t *testing.T) {
+		l, _ := newLimiter(t, setting.UnifiedAlertingEvaluationLimitsSettings{
+			Folders: map[string]setting.EvaluationLimit{
+				"folder-1": {MaxConcurrent: 2},
+			},
+		})
+
+		release1, err := l.wait(context.Background(), limitedRule("folder-1", "ds-1"))
+		require.NoError(t, err)
+		release2, err := l.wait(context.Background(), limitedRule("folder-1", "ds-2"))
+		require.NoError(t, err)
+
+		require.True(t, waitBlocked(t, l, limitedRule("folder-1", "ds-3")))
+		require.False(t, waitBlocked(t, l, limitedRule("folder-2", "ds-3")), "folders without override should not be limited")
+
+		release1()
+		release2()
+		require.False(t, waitBlocked(t, l, limitedRule("folder-1", "ds-3")))
+	})
+
+	t.Run("should release acquired limits if the context is cancelled", func(t *testing.T) {
+		l, _ := newLimiter(t, setting.UnifiedAlertingEvaluationLimitsSettings{
+			Datasource: setting.EvaluationLimit{MaxConcurrent: 1},
+		})
+
+		release, err := l.wait(context.Background(), limitedRule("folder", "ds-2"))
+		require.NoError(t, err)
+
+		// ds-1 is acquired first, then the rule waits for ds-2.
+		require.True(t, waitBlocked(t, l, limitedRule("folder", "ds-1", "ds-2")))
+		require.False(t, waitBlocked(t, l, limitedRule("folder", "ds-1")))
+		release()
+	})
+
+	t.Run("should limit the rate of evaluations", func(t *testing.T) {
+		l, _ := newLimiter(t, setting.UnifiedAlertingEvaluationLimitsSettings{
+			Datasources: map[string]setting.EvaluationLimit{
+				"ds-1": {Rate: 0.1, Burst: 2},
+			},
+		})
+
+		require.False(t, waitBlocked(t, l, limitedRule("folder", "ds-1")))
+		require.False(t, waitBlocked(t, l, limitedRule("folder", "ds-1")))
+		require.True(t, waitBlocked(t, l, limitedRule("folder", "ds-1")))
+		require.False(t, waitBlocked(t, l, limitedRule("folder", "ds-2")))
+	})
+
+	t.Run("should queue throttled rules until they can be evaluated", func(t *testing.T) {
+		l, m := newLimiter(t, setting.UnifiedAlertingEvaluationLimitsSettings{
+			Folder: setting.EvaluationLimit{MaxConcurrent: 1},
+		})
+
+		release, err := l.wait(context.Background(), limitedRule("folder", "ds"))
+		require.NoError(t, err)
+
+		done := make(chan error)
+		go func() {
+			release, err := l.wait(context.Background(), limitedRule("folder", "ds"))
+			if err == nil {
+				release()
+			}
+			done <- err
+		}()
+
+		require.Eventually(t, func() bool {
+			return testutil.ToFloat64(m.ThrottledEvaluations.WithLabelValues("1")) == 1
+		}, time.Second, 10*time.Millisecond)
+		release()
+		require.NoError(t, <-done)
+		require.Equal(t, 0.0, testutil.ToFloat64(m.ThrottledEvaluations.WithLabelValues("1")))
+	})
+}
@@ -1,6 +1,8 @@
 package schedule
 
 import (
+	"context"
+
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
@@ -18,7 +20,7 @@ func (a *alertRule) newLoadedMetricsReader(rule *ngmodels.AlertRule) eval.Alerti
 }
 
 type RuleStateProvider interface {
-	GetStatesForRuleUID(orgID int64, alertRuleUID string) []*state.State
+	GetStatesForRuleUID(ctx context.Context, orgID int64, alertRuleUID string) []*state.State
 }
 
 // AlertingResultsFromRuleState implements eval.AlertingResultsReader that gets the data from state manager.
@@ -29,7 +31,8 @@ type AlertingResultsFromRuleState struct {
 }
 
 func (n AlertingResultsFromRuleState) Read() map[data.Fingerprint]struct{} {
-	states := n.Manager.GetStatesForRuleUID(n.Rule.OrgID, n.Rule.UID)
+	// The rule is evaluated by this instance, so its state is read from the cache.
+	states := n.Manager.GetStatesForRuleUID(context.Background(), n.Rule.OrgID, n.Rule.UID)
 
 	active := map[data.Fingerprint]struct{}{}
 	for _, st := range states {
@@ -53,10 +53,11 @@ type recordingRule struct {
 
 	logger  log.Logger
 	metrics *metrics.Scheduler
+	limiter *evaluationLimiter
 	tracer  tracing.Tracer
 }
 
-func newRecordingRule(parent context.Context, key ngmodels.AlertRuleKey, maxAttempts int64, clock clock.Clock, evalFactory eval.EvaluatorFactory, cfg setting.RecordingRuleSettings, logger log.Logger, metrics *metrics.Scheduler, tracer tracing.Tracer, writer RecordingWriter, evalAppliedHook evalAppliedFunc, stopAppliedHook stopAppliedFunc) *recordingRule {
+func newRecordingRule(parent context.Context, key ngmodels.AlertRuleKey, maxAttempts int64, clock clock.Clock, evalFactory eval.EvaluatorFactory, cfg setting.RecordingRuleSettings, logger log.Logger, metrics *metrics.Scheduler, limiter *evaluationLimiter, tracer tracing.Tracer, writer RecordingWriter, evalAppliedHook evalAppliedFunc, stopAppliedHook stopAppliedFunc) *recordingRule {
 	ctx, stop := util.WithCancelCause(ngmodels.WithRuleKey(parent, key))
 	return &recordingRule{
 		key:                 key,
@@ -75,6 +76,7 @@ func newRecordingRule(parent context.Context, key ngmodels.AlertRuleKey, maxAtte
 		stopAppliedHook:     stopAppliedHook,
 		logger:              logger.FromContext(ctx),
 		metrics:             metrics,
+		limiter:             limiter,
 		tracer:              tracer,
 		writer:              writer,
 	}
@@ -234,10 +236,15 @@ func (r *recordingRule) doEvaluate(ctx context.Context, ev *Evaluation) {
 }
 
 func (r *recordingRule) tryEvaluation(ctx context.Context, ev *Evaluation, logger log.Logger) error {
+	release, err := r.limiter.wait(ctx, ev.rule)
+	if err != nil {
+		return fmt.Errorf("failed to wait for the evaluation limits: %w", err)
+	}
 	evalStart := r.clock.Now()
 	evalCtx := eval.NewContext(ctx, SchedulerUserFor(ev.rule.OrgID))
 	result, err := r.buildAndExecutePipeline(ctx, evalCtx, ev, logger)
 	evalDur := r.clock.Now().Sub(evalStart)
+	release()
 	if err != nil {
 		return fmt.Errorf("server side expressions pipeline returned an error: %w", err)
 	}
@@ -254,7 +261,7 @@ func (r *recordingRule) tryEvaluation(ctx context.Context, ev *Evaluation, logge
 		attribute.Int64("results", int64(len(result.Responses))),
 	))
 
-	frames, err := r.frameRef(ev.rule.Record.From, result)
+	frames, err := RecordedFrames(ev.rule.Record.From, result)
 	if err != nil {
 		span.AddEvent("query returned no data, nothing to write", trace.WithAttributes(
 			attribute.String("reason", err.Error()),
@@ -265,7 +272,7 @@ func (r *recordingRule) tryEvaluation(ctx context.Context, ev *Evaluation, logge
 	}
 
 	writeStart := r.clock.Now()
-	err = r.writer.Write(ctx, ev.rule.Record.Metric, ev.scheduledAt, frames, ev.rule.OrgID, ev.rule.Labels)
+	err = r.writer.WriteDatasource(ctx, ev.rule.Record.TargetDatasourceUID, ev.rule.Record.Metric, ev.scheduledAt, frames, ev.rule.OrgID, ev.rule.Labels)
 	writeDur := r.clock.Now().Sub(writeStart)
 
 	if err != nil {
@@ -304,8 +311,8 @@ func (r *recordingRule) evaluationDoneTestHook(ev *Evaluation) {
 	r.evalAppliedHook(r.key, ev.scheduledAt)
 }
 
-// frameRef gets frames from a QueryDataResponse for a particular refID. It returns an error if the frames do not exist or have no data.
-func (r *recordingRule) frameRef(refID string, resp *backend.QueryDataResponse) (data.Frames, error) {
+// RecordedFrames gets frames from a QueryDataResponse for a particular refID. It returns an error if the frames do not exist or have no data.
+func RecordedFrames(refID string, resp *backend.QueryDataResponse) (data.Frames, error) {
 	if len(resp.Responses) == 0 {
 		return nil, fmt.Errorf("no responses returned from rule evaluation")
 	}
@@ -3,6 +3,7 @@ package schedule
 import (
 	"bytes"
 	context "context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"math/rand"
@@ -156,7 +157,7 @@ func blankRecordingRuleForTests(ctx context.Context) *recordingRule {
 	st := setting.RecordingRuleSettings{
 		Enabled: true,
 	}
-	return newRecordingRule(context.Background(), models.AlertRuleKey{}, 0, nil, nil, st, log.NewNopLogger(), nil, nil, writer.FakeWriter{}, nil, nil)
+	return newRecordingRule(context.Background(), models.AlertRuleKey{}, 0, nil, nil, st, log.NewNopLogger(), nil, nil, nil, writer.FakeWriter{}, nil, nil)
 }
 
 func TestRecordingRule_Integration(t *testing.T) {
@@ -267,23 +268,23 @@ func TestRecordingRule_Integration(t *testing.T) {
 				`
 				# HELP grafana_alerting_remote_writer_write_duration_seconds Histogram of remote write durations.
 				# TYPE grafana_alerting_remote_writer_write_duration_seconds histogram
-				grafana_alerting_remote_writer_write_duration_seconds_bucket{backend="prometheus",org="%[1]d",le="0.005"} 1
-				grafana_alerting_remote_writer_write_duration_seconds_bucket{backend="prometheus",org="%[1]d",le="0.01"} 1
-				grafana_alerting_remote_writer_write_duration_seconds_bucket{backend="prometheus",org="%[1]d",le="0.025"} 1
-				grafana_alerting_remote_writer_write_duration_seconds_bucket{backend="prometheus",org="%[1]d",le="0.05"} 1
-				grafana_alerting_remote_writer_write_duration_seconds_bucket{backend="prometheus",org="%[1]d",le="0.1"} 1
-				grafana_alerting_remote_writer_write_duration_seconds_bucket{backend="prometheus",org="%[1]d",le="0.25"} 1
-				grafana_alerting_remote_writer_write_duration_seconds_bucket{backend="prometheus",org="%[1]d",le="0.5"} 1
-				grafana_alerting_remote_writer_write_duration_seconds_bucket{backend="prometheus",org="%[1]d",le="1"} 1
-				grafana_alerting_remote_writer_write_duration_seconds_bucket{backend="prometheus",org="%[1]d",le="2.5"} 1
-				grafana_alerting_remote_writer_write_duration_seconds_bucket{backend="prometheus",org="%[1]d",le="5"} 1
-				grafana_alerting_remote_writer_write_duration_seconds_bucket{backend="prometheus",org="%[1]d",le="10"} 1
-				grafana_alerting_remote_writer_write_duration_seconds_bucket{backend="prometheus",org="%[1]d",le="+Inf"} 1
-				grafana_alerting_remote_writer_write_duration_seconds_sum{backend="prometheus",org="%[1]d"} 0
-				grafana_alerting_remote_writer_write_duration_seconds_count{backend="prometheus",org="%[1]d"} 1
+				grafana_alerting_remote_writer_write_duration_seconds_bucket{backend="prometheus",org="%[1]d",target="default",le="0.005"} 1
+				grafana_alerting_remote_writer_write_duration_seconds_bucket{backend="prometheus",org="%[1]d",target="default",le="0.01"} 1
+				grafana_alerting_remote_writer_write_duration_seconds_bucket{backend="prometheus",org="%[1]d",target="default",le="0.025"} 1
+				grafana_alerting_remote_writer_write_duration_seconds_bucket{backend="prometheus",org="%[1]d",target="default",le="0.05"} 1
+				grafana_alerting_remote_writer_write_duration_seconds_bucket{backend="prometheus",org="%[1]d",target="default",le="0.1"} 1
+				grafana_alerting_remote_writer_write_duration_seconds_bucket{backend="prometheus",org="%[1]d",target="default",le="0.25"} 1
+				grafana_alerting_remote_writer_write_duration_seconds_bucket{backend="prometheus",org="%[1]d",target="default",le="0.5"} 1
+				grafana_alerting_remote_writer_write_duration_seconds_bucket{backend="prometheus",org="%[1]d",target="default",le="1"} 1
+				grafana_alerting_remote_writer_write_duration_seconds_bucket{backend="prometheus",org="%[1]d",target="default",le="2.5"} 1
+				grafana_alerting_remote_writer_write_duration_seconds_bucket{backend="prometheus",org="%[1]d",target="default",le="5"} 1
+				grafana_alerting_remote_writer_write_duration_seconds_bucket{backend="prometheus",org="%[1]d",target="default",le="10"} 1
+				grafana_alerting_remote_writer_write_duration_seconds_bucket{backend="prometheus",org="%[1]d",target="default",le="+Inf"} 1
+				grafana_alerting_remote_writer_write_duration_seconds_sum{backend="prometheus",org="%[1]d",target="default"} 0
+				grafana_alerting_remote_writer_write_duration_seconds_count{backend="prometheus",org="%[1]d",target="default"} 1
 				# HELP grafana_alerting_remote_writer_writes_total The total number of remote writes attempted.
 				# TYPE grafana_alerting_remote_writer_writes_total counter
-				grafana_alerting_remote_writer_writes_total{backend="prometheus", org="%[1]d", status_code="200"} 1
+				grafana_alerting_remote_writer_writes_total{backend="prometheus", org="%[1]d", status_code="200", target="default"} 1
 				`,
 				rule.OrgID,
 			)
@@ -406,23 +407,23 @@ func TestRecordingRule_Integration(t *testing.T) {
 				`
 				# HELP grafana_alerting_remote_writer_write_duration_seconds Histogram of remote write durations.
 				# TYPE grafana_alerting_remote_writer_write_duration_seconds histogram
-				grafana_alerting_remote_writer_write_duration_seconds_bucket{backend="prometheus",org="%[1]d",le="0.005"} 1
-				grafana_alerting_remote_writer_write_duration_seconds_bucket{backend="prometheus",org="%[1]d",le="0.01"} 1
-				grafana_alerting_remote_writer_write_duration_seconds_bucket{backend="prometheus",org="%[1]d",le="0.025"} 1
-				grafana_alerting_remote_writer_write_duration_seconds_bucket{backend="prometheus",org="%[1]d",le="0.05"} 1
-				grafana_alerting_remote_writer_write_duration_seconds_bucket{backend="prometheus",org="%[1]d",le="0.1"} 1
-				grafana_alerting_remote_writer_write_duration_seconds_bucket{backend="prometheus",org="%[1]d",le="0.25"} 1
-				grafana_alerting_remote_writer_write_duration_seconds_bucket{backend="prometheus",org="%[1]d",le="0.5"} 1
-				grafana_alerting_remote_writer_write_duration_seconds_bucket{backend="prometheus",org="%[1]d",le="1"} 1
-				grafana_alerting_remote_writer_write_duration_seconds_bucket{backend="prometheus",org="%[1]d",le="2.5"} 1
-				grafana_alerting_remote_writer_write_duration_seconds_bucket{backend="prometheus",org="%[1]d",le="5"} 1
-				grafana_alerting_remote_writer_write_duration_seconds_bucket{backend="prometheus",org="%[1]d",le="10"} 1
-				grafana_alerting_remote_writer_write_duration_seconds_bucket{backend="prometheus",org="%[1]d",le="+Inf"} 1
-				grafana_alerting_remote_writer_write_duration_seconds_sum{backend="prometheus",org="%[1]d"} 0
-				grafana_alerting_remote_writer_write_duration_seconds_count{backend="prometheus",org="%[1]d"} 1
+				grafana_alerting_remote_writer_write_duration_seconds_bucket{backend="prometheus",org="%[1]d",target="default",le="0.005"} 1
+				grafana_alerting_remote_writer_write_duration_seconds_bucket{backend="prometheus",org="%[1]d",target="default",le="0.01"} 1
+				grafana_alerting_remote_writer_write_duration_seconds_bucket{backend="prometheus",org="%[1]d",target="default",le="0.025"} 1
+				grafana_alerting_remote_writer_write_duration_seconds_bucket{backend="prometheus",org="%[1]d",target="default",le="0.05"} 1
+				grafana_alerting_remote_writer_write_duration_seconds_bucket{backend="prometheus",org="%[1]d",target="default",le="0.1"} 1
+				grafana_alerting_remote_writer_write_duration_seconds_bucket{backend="prometheus",org="%[1]d",target="default",le="0.25"} 1
+				grafana_alerting_remote_writer_write_duration_seconds_bucket{backend="prometheus",org="%[1]d",target="default",le="0.5"} 1
+				grafana_alerting_remote_writer_write_duration_seconds_bucket{backend="prometheus",org="%[1]d",target="default",le="1"} 1
+				grafana_alerting_remote_writer_write_duration_seconds_bucket{backend="prometheus",org="%[1]d",target="default",le="2.5"} 1
+				grafana_alerting_remote_writer_write_duration_seconds_bucket{backend="prometheus",org="%[1]d",target="default",le="5"} 1
+				grafana_alerting_remote_writer_write_duration_seconds_bucket{backend="prometheus",org="%[1]d",target="default",le="10"} 1
+				grafana_alerting_remote_writer_write_duration_seconds_bucket{backend="prometheus",org="%[1]d",target="default",le="+Inf"} 1
+				grafana_alerting_remote_writer_write_duration_seconds_sum{backend="prometheus",org="%[1]d",target="default"} 0
+				grafana_alerting_remote_writer_write_duration_seconds_count{backend="prometheus",org="%[1]d",target="default"} 1
 				# HELP grafana_alerting_remote_writer_writes_total The total number of remote writes attempted.
 				# TYPE grafana_alerting_remote_writer_writes_total counter
-				grafana_alerting_remote_writer_writes_total{backend="prometheus", org="%[1]d", status_code="200"} 1
+				grafana_alerting_remote_writer_writes_total{backend="prometheus", org="%[1]d", status_code="200", target="default"} 1
 				`,
 				rule.OrgID,
 			)
@@ -530,10 +531,10 @@ func withQueryForHealth(health string) models.AlertRuleMutator {
 	}
 }
 
-func setupWriter(t *testing.T, target *writer.TestRemoteWriteTarget, reg prometheus.Registerer) *writer.PrometheusWriter {
+func setupWriter(t *testing.T, target *writer.TestRemoteWriteTarget, reg prometheus.Registerer) *writer.DatasourceWriter {
 	provider := testClientProvider{}
 	m := metrics.NewNGAlert(reg)
-	wr, err := writer.NewPrometheusWriter(target.ClientSettings(), provider, clock.NewMock(), log.NewNopLogger(), m.GetRemoteWriterMetrics())
+	wr, err := writer.NewDatasourceWriter(target.ClientSettings(), nil, provider, nil, clock.NewMock(), log.NewNopLogger(), m.GetRemoteWriterMetrics())
 	require.NoError(t, err)
 	return wr
 }
@@ -543,3 +544,11 @@ type testClientProvider struct{}
 func (t testClientProvider) New(options ...httpclient.Options) (*http.Client, error) {
 	return &http.Client{}, nil
 }
+
+func (t testClientProvider) GetTransport(options ...httpclient.Options) (http.RoundTripper, error) {
+	return http.DefaultTransport, nil
+}
+
+func (t testClientProvider) GetTLSConfig(options ...httpclient.Options) (*tls.Config, error) {
+	return nil, nil
+}
@@ -16,8 +16,9 @@ import (
 )
 
 var (
-	errRuleDeleted   = errors.New("rule deleted")
-	errRuleRestarted = errors.New("rule restarted")
+	errRuleDeleted    = errors.New("rule deleted")
+	errRuleRestarted  = errors.New("rule restarted")
+	errRuleReassigned = errors.New("rule reassigned to another instance")
 )
 
 type ruleFactory interface {
@@ -304,11 +305,16 @@ func (r ruleWithFolder) Fingerprint() fingerprint {
 		writeBytes(tmp)
 	}
 
+	for _, uid := range rule.DependsOn {
+		writeString(uid)
+	}
+
 	// fields that do not affect the state.
 	// TODO consider removing fields below from the fingerprint
 	writeInt(rule.ID)
 	writeInt(rule.OrgID)
 	writeInt(int64(rule.For))
+	writeInt(int64(rule.KeepFiringFor))
 	if rule.DashboardUID != nil {
 		writeString(*rule.DashboardUID)
 	}
@@ -194,6 +194,8 @@ func TestRuleWithFolderFingerprint(t *testing.T) {
 			ExecErrState:    "test-err",
 			Record:          &models.Record{Metric: "my_metric", From: "A"},
 			For:             12,
+			KeepFiringFor:   13,
+			DependsOn:       []string{"dependency-1"},
 			Annotations: map[string]string{
 				"key-annotation": "value-annotation",
 			},
@@ -233,6 +235,8 @@ func TestRuleWithFolderFingerprint(t *testing.T) {
 			ExecErrState:    "test-err2",
 			Record:          &models.Record{Metric: "my_metric2", From: "B"},
 			For:             1141,
+			KeepFiringFor:   1142,
+			DependsOn:       []string{"dependency-2"},
 			Annotations: map[string]string{
 				"key-annotation2": "value-annotation",
 			},
@@ -248,6 +252,8 @@ func TestRuleWithFolderFingerprint(t *testing.T) {
 		excludedFields := map[string]struct{}{
 			"Version":         {},
 			"Updated":         {},
+			"UpdatedBy":       {},
+			"Message":         {},
 			"IntervalSeconds": {},
 			"Annotations":     {},
 		}
@@ -48,8 +48,10 @@ type RulesStore interface {
 	GetAlertRulesForScheduling(ctx context.Context, query *ngmodels.GetAlertRulesForSchedulingQuery) error
 }
 
+// RecordingWriter writes the output of recording rules to the data source with the given UID.
+// An empty UID selects the default target.
 type RecordingWriter interface {
-	Write(ctx context.Context, name string, t time.Time, frames data.Frames, orgID int64, extraLabels map[string]string) error
+	WriteDatasource(ctx context.Context, dsUID string, name string, t time.Time, frames data.Frames, orgID int64, extraLabels map[string]string) error
 }
 
 type schedule struct {
@@ -104,6 +106,14 @@ type schedule struct {
 	tracer tracing.Tracer
 
 	recordingWriter RecordingWriter
+
+	// sharder is nil when every rule is evaluated by this instance.
+	sharder *ruleSharder
+	// unownedRules contains the rules that are evaluated by other members of the cluster.
+	unownedRules map[ngmodels.AlertRuleKey]struct{}
+
+	// limiter is nil when evaluations are not limited.
+	limiter *evaluationLimiter
 }
 
 // SchedulerCfg is the scheduler configuration.
@@ -123,6 +133,12 @@ type SchedulerCfg struct {
 	Tracer               tracing.Tracer
 	Log                  log.Logger
 	RecordingWriter      RecordingWriter
+	// ClusterMembership, if not nil, splits the evaluation of the rules between the members of the cluster.
+	ClusterMembership ClusterMembership
+	// RuleOwners records which member of the cluster evaluates each rule. It is required with ClusterMembership.
+	RuleOwners RuleOwnerStore
+	// EvaluationLimits limit the evaluations of rules that query the same data source or belong to the same folder.
+	EvaluationLimits setting.UnifiedAlertingEvaluationLimitsSettings
 }
 
 // NewScheduler returns a new scheduler.
@@ -153,6 +169,12 @@ func NewScheduler(cfg SchedulerCfg, stateManager *state.Manager) *schedule {
 		alertsSender:                       cfg.AlertSender,
 		tracer:                             cfg.Tracer,
 		recordingWriter:                    cfg.RecordingWriter,
+		unownedRules:                       make(map[ngmodels.AlertRuleKey]struct{}),
+		limiter:                            newEvaluationLimiter(cfg.EvaluationLimits, cfg.Metrics),
+	}
+
+	if cfg.ClusterMembership != nil {
+		sch.sharder = newRuleSharder(cfg.ClusterMembership, cfg.RuleOwners)
 	}
 
 	return &sch
@@ -253,6 +275,16 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 
 	sch.updateRulesMetrics(alertRules)
 
+	if sch.sharder != nil && sch.sharder.refresh() {
+		sch.log.Info("Cluster membership has changed, rebalancing alert rules")
+	}
+	unownedRules := make(map[ngmodels.AlertRuleKey]struct{})
+	var claimed map[ngmodels.AlertRuleKey]bool
+	var toRelease []ngmodels.AlertRuleKey
+	if sch.sharder != nil {
+		claimed = sch.claimAlertRules(ctx, alertRules)
+	}
+
 	readyToRun := make([]readyToRunItem, 0)
 	updatedRules := make([]ngmodels.AlertRuleKeyWithVersion, 0, len(updated)) // this is needed for tests only
 	restartedRules := make([]Rule, 0)
@@ -268,6 +300,7 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 		sch.clock,
 		sch.rrCfg,
 		sch.metrics,
+		sch.limiter,
 		sch.log,
 		sch.tracer,
 		sch.recordingWriter,
@@ -275,10 +308,32 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 		sch.stopAppliedFunc,
 	)
 	for _, item := range alertRules {
-		ruleRoutine, newRoutine := sch.registry.getOrCreate(ctx, item, ruleFactory)
 		key := item.GetKey()
 		logger := sch.log.FromContext(ctx).New(key.LogContext()...)
 
+		if sch.sharder != nil {
+			if !sch.sharder.owns(key) {
+				unownedRules[key] = struct{}{}
+				if _, ok := sch.unownedRules[key]; !ok && sch.releaseAlertRule(key) {
+					toRelease = append(toRelease, key)
+				}
+				delete(registeredDefinitions, key)
+				continue
+			}
+			if takenOver, ok := claimed[key]; ok && takenOver {
+				// Another instance evaluated the rule until now. Continue from the state it saved.
+				logger.Debug("Rule has been assigned to this instance")
+				sch.stateManager.LoadRuleState(ctx, item)
+			} else if !ok && !sch.sharder.isClaimed(key) {
+				// The previous owner has not saved the state of the rule and released it yet.
+				logger.Debug("Rule has been assigned to this instance, waiting for the previous owner to release it")
+				delete(registeredDefinitions, key)
+				continue
+			}
+		}
+
+		ruleRoutine, newRoutine := sch.registry.getOrCreate(ctx, item, ruleFactory)
+
 		// enforce minimum evaluation interval
 		if item.IntervalSeconds < int64(sch.minRuleInterval.Seconds()) {
 			logger.Debug("Interval adjusted", "originalInterval", item.IntervalSeconds, "adjustedInterval", sch.minRuleInterval.Seconds())
@@ -297,7 +352,13 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 
 		if newRoutine && !invalidInterval {
 			dispatcherGroup.Go(func() error {
-				return ruleRoutine.Run()
+				err := ruleRoutine.Run()
+				if sch.sharder != nil {
+					if err := sch.sharder.stopped(key, ruleRoutine); err != nil {
+						logger.Error("Failed to release the rule", "error", err)
+					}
+				}
+				return err
 			})
 		}
 
@@ -390,5 +451,46 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 		toDelete = append(toDelete, key)
 	}
 	sch.deleteAlertRule(toDelete...)
+	if sch.sharder != nil {
+		sch.sharder.unclaim(toDelete...)
+		if err := sch.sharder.release(ctx, toRelease...); err != nil {
+			sch.log.Error("Failed to release alert rules", "error", err)
+		}
+	}
+	sch.unownedRules = unownedRules
 	return readyToRun, registeredDefinitions, updatedRules
 }
+
+// claimAlertRules claims the rules that are assigned to this instance and that it does not evaluate yet. It returns
+// the rules that were claimed and, for each of them, whether another member evaluated it before.
+func (sch *schedule) claimAlertRules(ctx context.Context, rules []*ngmodels.AlertRule) map[ngmodels.AlertRuleKey]bool {
+	keys := make([]ngmodels.AlertRuleKey, 0)
+	for _, rule := range rules {
+		key := rule.GetKey()
+		if sch.sharder.owns(key) && !sch.sharder.isClaimed(key) {
+			keys = append(keys, key)
+		}
+	}
+	claimed, err := sch.sharder.claim(ctx, keys)
+	if err != nil {
+		sch.log.Error("Failed to claim alert rules", "error", err)
+		return nil
+	}
+	return claimed
+}
+
+// releaseAlertRule stops the evaluation of the rule that is now evaluated by another member of the cluster.
+// The routine of the rule saves its state for the new owner and the rule is released when the routine has stopped.
+// If the rule was not running, its state is only removed from the cache, as the state in the database is maintained
+// by the owner, and releaseAlertRule returns true to tell that the rule can be released right away.
+func (sch *schedule) releaseAlertRule(key ngmodels.AlertRuleKey) bool {
+	sch.log.Debug("Rule has been assigned to another instance", key.LogContext()...)
+	ruleRoutine, ok := sch.registry.del(key)
+	if !ok {
+		sch.stateManager.ForgetRule(key)
+		return true
+	}
+	sch.sharder.releaseAfterStop(key, ruleRoutine)
+	ruleRoutine.Stop(errRuleReassigned)
+	return false
+}
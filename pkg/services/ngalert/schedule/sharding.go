@@ -0,0 +1,195 @@
+package schedule
+
+import (
+	"context"
+	"hash/fnv"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// ringTokensPerMember is the number of virtual nodes every member gets in the hash ring.
+// More tokens spread the rules more evenly at the cost of a bigger ring.
+const ringTokensPerMember = 128
+
+// ClusterMembership provides the live members of the high availability cluster.
+type ClusterMembership interface {
+	// Self returns the name of this instance as it appears in Members.
+	Self() string
+	// Members returns the names of the live members of the cluster.
+	Members() []string
+}
+
+// RuleOwnerStore records which member of the cluster evaluates each rule, so that a rule moves to another member
+// only after its previous owner has saved its state.
+type RuleOwnerStore interface {
+	ClaimAlertRules(ctx context.Context, owner string, members []string, keys []ngmodels.AlertRuleKey) (map[ngmodels.AlertRuleKey]bool, error)
+	ReleaseAlertRules(ctx context.Context, owner string, keys ...ngmodels.AlertRuleKey) error
+}
+
+type ringToken struct {
+	hash   uint64
+	member string
+}
+
+// ruleSharder assigns every alert rule to a single member of the cluster using consistent hashing,
+// so that only a fraction of the rules move when members join or leave.
+//
+// The members can briefly disagree about the members of the cluster, and the previous owner of a rule needs time to
+// save its state. Therefore, a member evaluates a rule only after it has claimed it in the store, which succeeds once
+// the previous owner has released the rule or has left the cluster.
+type ruleSharder struct {
+	membership ClusterMembership
+	store      RuleOwnerStore
+
+	mtx     sync.RWMutex
+	members []string
+	ring    []ringToken
+	// claimed contains the rules that this instance has claimed and evaluates.
+	claimed map[ngmodels.AlertRuleKey]struct{}
+	// releasing contains the routines of the rules that are released once the routines have stopped.
+	releasing map[ngmodels.AlertRuleKey]Rule
+}
+
+func newRuleSharder(membership ClusterMembership, store RuleOwnerStore) *ruleSharder {
+	s := &ruleSharder{
+		membership: membership,
+		store:      store,
+		claimed:    make(map[ngmodels.AlertRuleKey]struct{}),
+		releasing:  make(map[ngmodels.AlertRuleKey]Rule),
+	}
+	s.refresh()
+	return s
+}
+
+// refresh rebuilds the ring from the current members of the cluster. It returns true if the members changed.
+func (s *ruleSharder) refresh() bool {
+	self := s.membership.Self()
+	members := slices.Clone(s.membership.Members())
+	// This instance evaluates rules even when it is not yet known to the rest of the cluster.
+	if !slices.Contains(members, self) {
+		members = append(members, self)
+	}
+	slices.Sort(members)
+	members = slices.Compact(members)
+
+	s.mtx.RLock()
+	changed := !slices.Equal(s.members, members)
+	s.mtx.RUnlock()
+	if !changed {
+		return false
+	}
+
+	ring := make([]ringToken, 0, len(members)*ringTokensPerMember)
+	for _, member := range members {
+		for i := 0; i < ringTokensPerMember; i++ {
+			ring = append(ring, ringToken{hash: hashString(member + "-" + strconv.Itoa(i)), member: member})
+		}
+	}
+	sort.Slice(ring, func(i, j int) bool {
+		return ring[i].hash < ring[j].hash
+	})
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.members = members
+	s.ring = ring
+	return true
+}
+
+// owner returns the member that evaluates the rule.
+func (s *ruleSharder) owner(key ngmodels.AlertRuleKey) string {
+	h := hashString(strconv.FormatInt(key.OrgID, 10) + "/" + key.UID)
+
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	idx := sort.Search(len(s.ring), func(i int) bool {
+		return s.ring[i].hash >= h
+	})
+	if idx == len(s.ring) {
+		idx = 0
+	}
+	return s.ring[idx].member
+}
+
+// owns returns true if this instance evaluates the rule.
+func (s *ruleSharder) owns(key ngmodels.AlertRuleKey) bool {
+	return s.owner(key) == s.membership.Self()
+}
+
+func hashString(s string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(s))
+	return h.Sum64()
+}
+
+// isClaimed returns true if this instance has claimed the rule.
+func (s *ruleSharder) isClaimed(key ngmodels.AlertRuleKey) bool {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	_, ok := s.claimed[key]
+	return ok
+}
+
+// claim claims the rules in the store. It returns the rules that this instance evaluates now and, for each of them,
+// whether another member evaluated it before.
+func (s *ruleSharder) claim(ctx context.Context, keys []ngmodels.AlertRuleKey) (map[ngmodels.AlertRuleKey]bool, error) {
+	s.mtx.RLock()
+	members := slices.Clone(s.members)
+	s.mtx.RUnlock()
+
+	claimed, err := s.store.ClaimAlertRules(ctx, s.membership.Self(), members, keys)
+	if err != nil {
+		return nil, err
+	}
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for key := range claimed {
+		s.claimed[key] = struct{}{}
+	}
+	return claimed, nil
+}
+
+// unclaim forgets that this instance evaluates the rules. It does not change the store.
+func (s *ruleSharder) unclaim(keys ...ngmodels.AlertRuleKey) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for _, key := range keys {
+		delete(s.claimed, key)
+	}
+}
+
+// release releases the rules in the store so that other members can claim them.
+func (s *ruleSharder) release(ctx context.Context, keys ...ngmodels.AlertRuleKey) error {
+	s.unclaim(keys...)
+	return s.store.ReleaseAlertRules(ctx, s.membership.Self(), keys...)
+}
+
+// releaseAfterStop makes stopped release the rule when its routine stops.
+func (s *ruleSharder) releaseAfterStop(key ngmodels.AlertRuleKey, routine Rule) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	delete(s.claimed, key)
+	s.releasing[key] = routine
+}
+
+// stopped is called when the routine of a rule has stopped. If the rule was reassigned to another member, it is
+// released, as the routine has saved its state before it stopped.
+func (s *ruleSharder) stopped(key ngmodels.AlertRuleKey, routine Rule) error {
+	s.mtx.Lock()
+	r, ok := s.releasing[key]
+	if ok && r == routine {
+		delete(s.releasing, key)
+	}
+	s.mtx.Unlock()
+	if !ok || r != routine {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	return s.store.ReleaseAlertRules(ctx, s.membership.Self(), key)
+}
@@ -0,0 +1,215 @@
+package schedule
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+type fakeClusterMembership struct {
+	mtx     sync.Mutex
+	self    string
+	members []string
+}
+
+func (f *fakeClusterMembership) Self() string {
+	return f.self
+}
+
+func (f *fakeClusterMembership) Members() []string {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	return f.members
+}
+
+func (f *fakeClusterMembership) setMembers(members ...string) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.members = members
+}
+
+func TestRuleSharder(t *testing.T) {
+	members := []string{"a", "b", "c"}
+	sharders := make(map[string]*ruleSharder, len(members))
+	for _, m := range members {
+		sharders[m] = newRuleSharder(&fakeClusterMembership{self: m, members: members}, nil)
+	}
+	keys := make([]models.AlertRuleKey, 0, 300)
+	for i := 0; i < 300; i++ {
+		keys = append(keys, models.GenerateRuleKey(1))
+	}
+
+	t.Run("every rule is owned by exactly one member", func(t *testing.T) {
+		owned := make(map[string]int)
+		for _, key := range keys {
+			owners := 0
+			for m, s := range sharders {
+				if s.owns(key) {
+					owners++
+					owned[m]++
+				}
+			}
+			require.Equal(t, 1, owners)
+		}
+		for _, m := range members {
+			require.Positive(t, owned[m], "member %s does not own any rule", m)
+		}
+	})
+
+	t.Run("only rules of the member that left are reassigned", func(t *testing.T) {
+		before := make(map[models.AlertRuleKey]string, len(keys))
+		for _, key := range keys {
+			before[key] = sharders["a"].owner(key)
+		}
+
+		membership := &fakeClusterMembership{self: "a", members: members}
+		s := newRuleSharder(membership, nil)
+		membership.setMembers("a", "b")
+		require.True(t, s.refresh())
+		require.False(t, s.refresh())
+
+		for _, key := range keys {
+			if before[key] != "c" {
+				require.Equal(t, before[key], s.owner(key))
+			}
+			require.NotEqual(t, "c", s.owner(key))
+		}
+	})
+
+	t.Run("owns all rules when it is not a member yet", func(t *testing.T) {
+		s := newRuleSharder(&fakeClusterMembership{self: "a"}, nil)
+		for _, key := range keys {
+			require.True(t, s.owns(key))
+		}
+	})
+}
+
+func TestProcessTicksWithSharding(t *testing.T) {
+	ruleStore := newFakeRulesStore()
+	instanceStore := &state.FakeInstanceStore{}
+	sch := setupScheduler(t, ruleStore, instanceStore, nil, nil, nil)
+	membership := &fakeClusterMembership{self: "a", members: []string{"a", "b"}}
+	owners := newFakeRuleOwnerStore()
+	sch.sharder = newRuleSharder(membership, owners)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	dispatcherGroup, ctx := errgroup.WithContext(ctx)
+
+	var owned, other *models.AlertRule
+	for owned == nil || other == nil {
+		rule := models.RuleGen.With(models.RuleGen.WithOrgID(1), models.RuleGen.WithInterval(time.Second)).GenerateRef()
+		if sch.sharder.owns(rule.GetKey()) {
+			owned = rule
+		} else {
+			other = rule
+		}
+	}
+	ruleStore.PutRule(ctx, owned, other)
+	// Member b evaluated all rules before this instance joined the cluster.
+	owners.set(owned.GetKey(), "b")
+	owners.set(other.GetKey(), "b")
+
+	tick := time.Time{}.Add(time.Second)
+	scheduled, _, _ := sch.processTick(ctx, dispatcherGroup, tick)
+	require.Empty(t, scheduled)
+	require.False(t, sch.registry.exists(owned.GetKey()))
+	require.False(t, sch.registry.exists(other.GetKey()))
+
+	t.Run("takes over the rule and its state after the previous owner released it", func(t *testing.T) {
+		require.NoError(t, owners.ReleaseAlertRules(ctx, "b", owned.GetKey()))
+		tick = tick.Add(time.Second)
+		scheduled, _, _ := sch.processTick(ctx, dispatcherGroup, tick)
+		require.Len(t, scheduled, 1)
+		require.Equal(t, owned.GetKey(), scheduled[0].rule.GetKey())
+		require.Equal(t, "a", owners.get(owned.GetKey()))
+		require.Contains(t, instanceStore.RecordedOps(), models.ListAlertInstancesQuery{RuleOrgID: owned.OrgID, RuleUID: owned.UID})
+	})
+
+	var routine Rule
+	t.Run("takes over the rule and its state when the other member leaves", func(t *testing.T) {
+		membership.setMembers("a")
+		tick = tick.Add(time.Second)
+		scheduled, stopped, _ := sch.processTick(ctx, dispatcherGroup, tick)
+		require.Len(t, scheduled, 2)
+		require.Empty(t, stopped)
+		require.Equal(t, "a", owners.get(other.GetKey()))
+		require.Contains(t, instanceStore.RecordedOps(), models.ListAlertInstancesQuery{RuleOrgID: other.OrgID, RuleUID: other.UID})
+		for _, item := range scheduled {
+			if item.rule.GetKey() == other.GetKey() {
+				routine = item.ruleRoutine
+			}
+		}
+	})
+
+	t.Run("stops the rule when it is reassigned and releases it after the routine stopped", func(t *testing.T) {
+		require.NotNil(t, routine)
+
+		membership.setMembers("a", "b")
+		tick = tick.Add(time.Second)
+		scheduled, stopped, _ := sch.processTick(ctx, dispatcherGroup, tick)
+		require.Len(t, scheduled, 1)
+		require.Empty(t, stopped)
+		require.False(t, sch.registry.exists(other.GetKey()))
+		require.ErrorIs(t, routine.(*alertRule).ctx.Err(), errRuleReassigned)
+		require.Eventually(t, func() bool {
+			return owners.get(other.GetKey()) == ""
+		}, 5*time.Second, 10*time.Millisecond)
+		require.Equal(t, "a", owners.get(owned.GetKey()))
+	})
+}
+
+type fakeRuleOwnerStore struct {
+	mtx    sync.Mutex
+	owners map[models.AlertRuleKey]string
+}
+
+func newFakeRuleOwnerStore() *fakeRuleOwnerStore {
+	return &fakeRuleOwnerStore{owners: make(map[models.AlertRuleKey]string)}
+}
+
+func (f *fakeRuleOwnerStore) ClaimAlertRules(_ context.Context, owner string, members []string, keys []models.AlertRuleKey) (map[models.AlertRuleKey]bool, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	result := make(map[models.AlertRuleKey]bool)
+	for _, key := range keys {
+		previous, ok := f.owners[key]
+		if ok && previous != owner && previous != "" && slices.Contains(members, previous) {
+			continue
+		}
+		f.owners[key] = owner
+		result[key] = ok && previous != owner
+	}
+	return result, nil
+}
+
+func (f *fakeRuleOwnerStore) ReleaseAlertRules(_ context.Context, owner string, keys ...models.AlertRuleKey) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	for _, key := range keys {
+		if f.owners[key] == owner {
+			f.owners[key] = ""
+		}
+	}
+	return nil
+}
+
+func (f *fakeRuleOwnerStore) set(key models.AlertRuleKey, owner string) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.owners[key] = owner
+}
+
+func (f *fakeRuleOwnerStore) get(key models.AlertRuleKey) string {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	return f.owners[key]
+}
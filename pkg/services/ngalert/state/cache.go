@@ -302,6 +302,42 @@ func (c *cache) getAll(orgID int64, skipNormalState bool) []*State {
 	return states
 }
 
+// hasFiringStates returns true if any state of the rule is Alerting. The second value is false if the cache does not
+// contain the states of the rule.
+func (c *cache) hasFiringStates(orgID int64, alertRuleUID string) (bool, bool) {
+	c.mtxStates.RLock()
+	defer c.mtxStates.RUnlock()
+	rs, ok := c.states[orgID][alertRuleUID]
+	if !ok {
+		return false, false
+	}
+	for _, state := range rs.states {
+		if state.State == eval.Alerting {
+			return true, true
+		}
+	}
+	return false, true
+}
+
+// containsRule returns true if the cache contains the states of the rule.
+func (c *cache) containsRule(orgID int64, alertRuleUID string) bool {
+	c.mtxStates.RLock()
+	defer c.mtxStates.RUnlock()
+	_, ok := c.states[orgID][alertRuleUID]
+	return ok
+}
+
+// ruleUIDs returns the UIDs of the rules of the organization whose states are in the cache.
+func (c *cache) ruleUIDs(orgID int64) map[string]struct{} {
+	c.mtxStates.RLock()
+	defer c.mtxStates.RUnlock()
+	result := make(map[string]struct{}, len(c.states[orgID]))
+	for uid := range c.states[orgID] {
+		result[uid] = struct{}{}
+	}
+	return result
+}
+
 func (c *cache) getStatesForRuleUID(orgID int64, alertRuleUID string, skipNormalState bool) []*State {
 	c.mtxStates.RLock()
 	defer c.mtxStates.RUnlock()
@@ -371,6 +407,7 @@ func (c *cache) asInstances(skipNormalState bool) []ngModels.AlertInstance {
 					CurrentStateEnd:   v2.EndsAt,
 					ResolvedAt:        v2.ResolvedAt,
 					LastSentAt:        v2.LastSentAt,
+					KeepFiringSince:   v2.KeepFiringSince,
 					ResultFingerprint: v2.ResultFingerprint.String(),
 				})
 			}
@@ -17,6 +17,7 @@ import (
 
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngModels "github.com/grafana/grafana/pkg/services/ngalert/models"
 )
 
 const (
@@ -56,6 +57,13 @@ func StateToPostableAlert(transition StateTransition, appURL *url.URL) *models.P
 		nA[alertingModels.StateReasonAnnotation] = alertState.StateReason
 	}
 
+	// The acknowledgement is only added to the annotations, so that it does not change the fingerprint of the alert.
+	if ack := alertState.Acknowledgement; ack != nil {
+		for k, v := range ack.Annotations(alertState.LastEvaluationTime) {
+			nA[k] = v
+		}
+	}
+
 	if alertState.OrgID != 0 {
 		nA[alertingModels.OrgIDAnnotation] = strconv.FormatInt(alertState.OrgID, 10)
 	}
@@ -98,6 +106,35 @@ func StateToPostableAlert(transition StateTransition, appURL *url.URL) *models.P
 	}
 }
 
+// StateToPostableAlerts converts a state to the models that are sent to the Alertmanager. These are the alert of
+// StateToPostableAlert and, if the alert instance is acknowledged or was unacknowledged at the latest evaluation, the
+// acknowledged copy of the alert. The copy has the additional label ngModels.AcknowledgedLabel that notification
+// policies can match, and it is resolved once the acknowledgement ends.
+func StateToPostableAlerts(transition StateTransition, appURL *url.URL) []*models.PostableAlert {
+	alert := StateToPostableAlert(transition, appURL)
+	if !transition.Acknowledged && !transition.AcknowledgementChanged {
+		return []*models.PostableAlert{alert}
+	}
+	labels := make(models.LabelSet, len(alert.Labels)+1)
+	for k, v := range alert.Labels {
+		labels[k] = v
+	}
+	labels[ngModels.AcknowledgedLabel] = "true"
+	acknowledged := &models.PostableAlert{
+		Annotations: alert.Annotations,
+		StartsAt:    alert.StartsAt,
+		EndsAt:      alert.EndsAt,
+		Alert: models.Alert{
+			Labels:       labels,
+			GeneratorURL: alert.GeneratorURL,
+		},
+	}
+	if !transition.Acknowledged {
+		acknowledged.EndsAt = strfmt.DateTime(transition.LastEvaluationTime)
+	}
+	return []*models.PostableAlert{alert, acknowledged}
+}
+
 // NoDataAlert is a special alert sent by Grafana to the Alertmanager, that indicates we received no data from the datasource.
 // It effectively replaces the legacy behavior of "Keep Last State" by separating the regular alerting flow from the no data scenario into a separate alerts.
 // The Alert is defined as:
@@ -147,9 +184,10 @@ func FromAlertsStateToStoppedAlert(firingStates []StateTransition, appURL *url.U
 		if transition.PreviousState == eval.Normal || transition.PreviousState == eval.Pending {
 			continue
 		}
-		postableAlert := StateToPostableAlert(transition, appURL)
-		postableAlert.EndsAt = strfmt.DateTime(ts)
-		alerts.PostableAlerts = append(alerts.PostableAlerts, *postableAlert)
+		for _, postableAlert := range StateToPostableAlerts(transition, appURL) {
+			postableAlert.EndsAt = strfmt.DateTime(ts)
+			alerts.PostableAlerts = append(alerts.PostableAlerts, *postableAlert)
+		}
 	}
 	return alerts
 }
@@ -309,6 +309,21 @@ func Test_FromAlertsStateToStoppedAlert(t *testing.T) {
 	result := FromAlertsStateToStoppedAlert(states, appURL, clk)
 
 	require.Equal(t, expected, result.PostableAlerts)
+
+	t.Run("the acknowledged copy of the alert is stopped too", func(t *testing.T) {
+		transition := randomTransition(eval.Alerting, eval.Alerting)
+		transition.Labels["service"] = "db"
+		transition.Acknowledged = true
+
+		result := FromAlertsStateToStoppedAlert([]StateTransition{transition}, appURL, clk)
+
+		require.Len(t, result.PostableAlerts, 2)
+		require.Equal(t, models.LabelSet{"service": "db"}, result.PostableAlerts[0].Labels)
+		require.Equal(t, models.LabelSet{"service": "db", ngModels.AcknowledgedLabel: "true"}, result.PostableAlerts[1].Labels)
+		for _, alert := range result.PostableAlerts {
+			require.Equal(t, clk.Now(), time.Time(alert.EndsAt))
+		}
+	})
 }
 
 func randomMapOfStrings() map[string]string {
@@ -0,0 +1,160 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	history_model "github.com/grafana/grafana/pkg/services/ngalert/state/historian/model"
+)
+
+// SinkType identifies different kinds of state event sinks.
+type SinkType string
+
+// String implements Stringer for SinkType.
+func (st SinkType) String() string {
+	return string(st)
+}
+
+const (
+	SinkTypeWebhook SinkType = "webhook"
+	SinkTypeLive    SinkType = "live"
+	SinkTypeFile    SinkType = "file"
+)
+
+func ParseSinkType(s string) (SinkType, error) {
+	norm := strings.ToLower(strings.TrimSpace(s))
+
+	types := map[SinkType]struct{}{
+		SinkTypeWebhook: {},
+		SinkTypeLive:    {},
+		SinkTypeFile:    {},
+	}
+	p := SinkType(norm)
+	if _, ok := types[p]; !ok {
+		return "", fmt.Errorf("unrecognized state event sink: %s", p)
+	}
+	return p, nil
+}
+
+// Event is a change of the state of an alert instance.
+type Event struct {
+	OrgID     int64  `json:"orgId"`
+	RuleUID   string `json:"ruleUid"`
+	RuleTitle string `json:"ruleTitle"`
+	RuleGroup string `json:"ruleGroup"`
+	FolderUID string `json:"folderUid"`
+	// Fingerprint identifies the alert instance within the rule.
+	Fingerprint   string             `json:"fingerprint"`
+	Labels        data.Labels        `json:"labels"`
+	PreviousState string             `json:"previousState"`
+	State         string             `json:"state"`
+	Values        map[string]float64 `json:"values,omitempty"`
+	Timestamp     time.Time          `json:"timestamp"`
+	StartsAt      time.Time          `json:"startsAt"`
+	EndsAt        time.Time          `json:"endsAt"`
+}
+
+// Payload is the message sent by the webhook and live sinks.
+type Payload struct {
+	Events []Event `json:"events"`
+}
+
+// Sink delivers events to an external system.
+type Sink interface {
+	// Write delivers events that belong to the same rule.
+	Write(ctx context.Context, events []Event) error
+}
+
+// NewEvents returns the events of the transitions that changed the state or the reason of the state.
+func NewEvents(rule history_model.RuleMeta, transitions []state.StateTransition) []Event {
+	events := make([]Event, 0, len(transitions))
+	for _, t := range transitions {
+		if !t.Changed() {
+			continue
+		}
+		events = append(events, Event{
+			OrgID:         rule.OrgID,
+			RuleUID:       rule.UID,
+			RuleTitle:     rule.Title,
+			RuleGroup:     rule.Group,
+			FolderUID:     rule.NamespaceUID,
+			Fingerprint:   t.CacheID.String(),
+			Labels:        t.Labels,
+			PreviousState: t.PreviousFormatted(),
+			State:         t.Formatted(),
+			Values:        t.Values,
+			Timestamp:     t.LastEvaluationTime,
+			StartsAt:      t.StartsAt,
+			EndsAt:        t.EndsAt,
+		})
+	}
+	return events
+}
+
+type sinkQueue struct {
+	name   string
+	sink   Sink
+	events chan []Event
+}
+
+// Dispatcher sends events to sinks. Every sink has its own queue so that a slow sink does not delay the others,
+// and events are dropped when the queue of a sink is full so that the evaluation of rules is never blocked.
+type Dispatcher struct {
+	queues []sinkQueue
+	log    log.Logger
+}
+
+func NewDispatcher(sinks map[SinkType]Sink, queueSize int, l log.Logger) *Dispatcher {
+	d := &Dispatcher{log: l}
+	for name, sink := range sinks {
+		d.queues = append(d.queues, sinkQueue{
+			name:   name.String(),
+			sink:   sink,
+			events: make(chan []Event, queueSize),
+		})
+	}
+	return d
+}
+
+// SendEvents implements state.EventSender.
+func (d *Dispatcher) SendEvents(ctx context.Context, rule history_model.RuleMeta, transitions []state.StateTransition) {
+	events := NewEvents(rule, transitions)
+	if len(events) == 0 {
+		return
+	}
+	for _, q := range d.queues {
+		select {
+		case q.events <- events:
+		default:
+			d.log.FromContext(ctx).Warn("State event queue is full, dropping events", "sink", q.name, "ruleUID", rule.UID, "events", len(events))
+		}
+	}
+}
+
+// Run delivers queued events until the context is cancelled.
+func (d *Dispatcher) Run(ctx context.Context) error {
+	d.log.Info("Starting state event dispatcher", "sinks", len(d.queues))
+	g, ctx := errgroup.WithContext(ctx)
+	for _, q := range d.queues {
+		g.Go(func() error {
+			for {
+				select {
+				case <-ctx.Done():
+					return nil
+				case events := <-q.events:
+					if err := q.sink.Write(ctx, events); err != nil {
+						d.log.Error("Failed to send state events", "sink", q.name, "ruleUID", events[0].RuleUID, "events", len(events), "error", err)
+					}
+				}
+			}
+		})
+	}
+	return g.Wait()
+}
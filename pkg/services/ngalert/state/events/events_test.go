@@ -0,0 +1,133 @@
+package events
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	history_model "github.com/grafana/grafana/pkg/services/ngalert/state/historian/model"
+)
+
+func TestParseSinkType(t *testing.T) {
+	st, err := ParseSinkType(" Webhook ")
+	require.NoError(t, err)
+	require.Equal(t, SinkTypeWebhook, st)
+
+	_, err = ParseSinkType("kafka")
+	require.Error(t, err)
+}
+
+func TestNewEvents(t *testing.T) {
+	rule := history_model.RuleMeta{OrgID: 1, UID: "rule", Title: "title", Group: "group", NamespaceUID: "folder"}
+	now := time.Now()
+
+	transitions := []state.StateTransition{
+		transition(eval.Pending, "", eval.Normal, "", now),
+		transition(eval.Normal, "", eval.Normal, models.StateReasonNoData, now),
+		transition(eval.Alerting, "", eval.Alerting, "", now),
+	}
+
+	events := NewEvents(rule, transitions)
+	require.Len(t, events, 2)
+	require.Equal(t, Event{
+		OrgID:         1,
+		RuleUID:       "rule",
+		RuleTitle:     "title",
+		RuleGroup:     "group",
+		FolderUID:     "folder",
+		Fingerprint:   transitions[0].CacheID.String(),
+		Labels:        transitions[0].Labels,
+		PreviousState: "Pending",
+		State:         "Normal",
+		Timestamp:     now,
+	}, events[0])
+	require.Equal(t, "Normal", events[1].PreviousState)
+	require.Equal(t, "Normal (NoData)", events[1].State)
+}
+
+func TestDispatcher(t *testing.T) {
+	rule := history_model.RuleMeta{OrgID: 1, UID: "rule"}
+	changed := []state.StateTransition{transition(eval.Normal, "", eval.Alerting, "", time.Now())}
+
+	t.Run("sends events to all sinks", func(t *testing.T) {
+		webhook, file := &fakeSink{}, &fakeSink{}
+		d := NewDispatcher(map[SinkType]Sink{SinkTypeWebhook: webhook, SinkTypeFile: file}, 10, log.NewNopLogger())
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() {
+			done <- d.Run(ctx)
+		}()
+
+		d.SendEvents(ctx, rule, changed)
+		d.SendEvents(ctx, rule, []state.StateTransition{transition(eval.Alerting, "", eval.Alerting, "", time.Now())})
+
+		require.Eventually(t, func() bool {
+			return len(webhook.written()) == 1 && len(file.written()) == 1
+		}, time.Second, 10*time.Millisecond)
+		cancel()
+		require.NoError(t, <-done)
+	})
+
+	t.Run("drops events when the queue is full", func(t *testing.T) {
+		sink := &fakeSink{}
+		d := NewDispatcher(map[SinkType]Sink{SinkTypeWebhook: sink}, 1, log.NewNopLogger())
+
+		d.SendEvents(context.Background(), rule, changed)
+		d.SendEvents(context.Background(), rule, changed)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() {
+			done <- d.Run(ctx)
+		}()
+		require.Eventually(t, func() bool {
+			return len(sink.written()) == 1
+		}, time.Second, 10*time.Millisecond)
+		cancel()
+		require.NoError(t, <-done)
+		require.Len(t, sink.written(), 1)
+	})
+}
+
+func transition(previous eval.State, previousReason string, current eval.State, reason string, evaluatedAt time.Time) state.StateTransition {
+	labels := data.Labels{"instance": "a"}
+	return state.StateTransition{
+		State: &state.State{
+			OrgID:              1,
+			AlertRuleUID:       "rule",
+			CacheID:            labels.Fingerprint(),
+			Labels:             labels,
+			State:              current,
+			StateReason:        reason,
+			LastEvaluationTime: evaluatedAt,
+		},
+		PreviousState:       previous,
+		PreviousStateReason: previousReason,
+	}
+}
+
+type fakeSink struct {
+	mtx    sync.Mutex
+	events [][]Event
+}
+
+func (f *fakeSink) Write(_ context.Context, events []Event) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.events = append(f.events, events)
+	return nil
+}
+
+func (f *fakeSink) written() [][]Event {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	return f.events
+}
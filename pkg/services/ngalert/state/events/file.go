@@ -0,0 +1,52 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// FileSink appends events to a local file, one JSON object per line.
+type FileSink struct {
+	mtx  sync.Mutex
+	path string
+}
+
+func NewFileSink(path string) (*FileSink, error) {
+	if path == "" {
+		return nil, fmt.Errorf("path of the state events file is not configured")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create the directory of the state events file: %w", err)
+	}
+	return &FileSink{path: path}, nil
+}
+
+// Write appends the events to the file. The file is opened for every write so that it can be rotated externally.
+func (f *FileSink) Write(_ context.Context, events []Event) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, e := range events {
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+	}
+
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	// nolint:gosec
+	// The path comes from the configuration of Grafana.
+	file, err := os.OpenFile(f.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o640)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(buf.Bytes()); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
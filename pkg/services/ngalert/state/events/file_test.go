@@ -0,0 +1,37 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "alerting", "events.log")
+	sink, err := NewFileSink(path)
+	require.NoError(t, err)
+
+	require.NoError(t, sink.Write(context.Background(), []Event{{RuleUID: "a", State: "Pending"}, {RuleUID: "a", State: "Alerting"}}))
+	require.NoError(t, sink.Write(context.Background(), []Event{{RuleUID: "b", State: "Normal"}}))
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = f.Close() })
+	var states []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e Event
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &e))
+		states = append(states, e.RuleUID+"/"+e.State)
+	}
+	require.NoError(t, scanner.Err())
+	require.Equal(t, []string{"a/Pending", "a/Alerting", "b/Normal"}, states)
+
+	_, err = NewFileSink("")
+	require.Error(t, err)
+}
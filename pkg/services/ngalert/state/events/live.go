@@ -0,0 +1,32 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// LiveChannel is the Grafana Live channel the events of an organization are published to.
+const LiveChannel = "grafana/alerting/events"
+
+// LivePublisher publishes messages to Grafana Live channels of an organization.
+type LivePublisher interface {
+	Publish(orgID int64, channel string, data []byte) error
+}
+
+// LiveSink publishes events to Grafana Live.
+type LiveSink struct {
+	publisher LivePublisher
+}
+
+func NewLiveSink(publisher LivePublisher) *LiveSink {
+	return &LiveSink{publisher: publisher}
+}
+
+// Write publishes the events to the channel of the organization of the rule.
+func (l *LiveSink) Write(_ context.Context, events []Event) error {
+	data, err := json.Marshal(Payload{Events: events})
+	if err != nil {
+		return err
+	}
+	return l.publisher.Publish(events[0].OrgID, LiveChannel, data)
+}
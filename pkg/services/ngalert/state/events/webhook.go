@@ -0,0 +1,119 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/benbjohnson/clock"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	// SignatureHeader contains the hex-encoded HMAC-SHA256 of the value of TimestampHeader, a dot, and the body.
+	SignatureHeader = "X-Grafana-Alerting-Signature"
+	// TimestampHeader contains the time the request was signed, in seconds since the epoch.
+	TimestampHeader = "X-Grafana-Alerting-Timestamp"
+)
+
+// WebhookSink posts events to an HTTP endpoint.
+type WebhookSink struct {
+	url         string
+	secret      []byte
+	client      *http.Client
+	maxAttempts int
+	backoff     time.Duration
+	clock       clock.Clock
+	log         log.Logger
+}
+
+func NewWebhookSink(cfg setting.UnifiedAlertingStateEventsSettings, client *http.Client, clk clock.Clock, l log.Logger) (*WebhookSink, error) {
+	u, err := url.Parse(cfg.WebhookURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid webhook URL %q: the scheme must be http or https", cfg.WebhookURL)
+	}
+	return &WebhookSink{
+		url:         cfg.WebhookURL,
+		secret:      []byte(cfg.WebhookSecret),
+		client:      client,
+		maxAttempts: cfg.WebhookMaxAttempts,
+		backoff:     cfg.WebhookRetryBackoff,
+		clock:       clk,
+		log:         l,
+	}, nil
+}
+
+// Write posts the events. Requests that fail with a network error, 429 or 5xx are retried.
+// The delay between attempts starts at the configured backoff and doubles after every retry.
+func (w *WebhookSink) Write(ctx context.Context, events []Event) error {
+	body, err := json.Marshal(Payload{Events: events})
+	if err != nil {
+		return err
+	}
+
+	backoff := w.backoff
+	for attempt := 1; ; attempt++ {
+		retry, err := w.send(ctx, body)
+		if err == nil || !retry || attempt >= w.maxAttempts {
+			return err
+		}
+		w.log.Debug("Retrying webhook request", "attempt", attempt, "error", err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-w.clock.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// send makes a single request. It returns true if the request failed and can be retried.
+func (w *WebhookSink) send(ctx context.Context, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(w.secret) > 0 {
+		timestamp := strconv.FormatInt(w.clock.Now().Unix(), 10)
+		req.Header.Set(TimestampHeader, timestamp)
+		req.Header.Set(SignatureHeader, Sign(w.secret, timestamp, body))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode/100 == 2 {
+		return false, nil
+	}
+	err = fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError, err
+}
+
+// Sign returns the signature of the request body sent at the given timestamp.
+func Sign(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	_, _ = mac.Write([]byte(timestamp))
+	_, _ = mac.Write([]byte("."))
+	_, _ = mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
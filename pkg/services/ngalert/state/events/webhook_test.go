@@ -0,0 +1,103 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestWebhookSink(t *testing.T) {
+	events := []Event{{OrgID: 1, RuleUID: "rule", Labels: data.Labels{"instance": "a"}, PreviousState: "Pending", State: "Normal"}}
+	newSink := func(t *testing.T, url string, secret string) *WebhookSink {
+		t.Helper()
+		sink, err := NewWebhookSink(setting.UnifiedAlertingStateEventsSettings{
+			WebhookURL:          url,
+			WebhookSecret:       secret,
+			WebhookMaxAttempts:  3,
+			WebhookRetryBackoff: time.Millisecond,
+		}, http.DefaultClient, clock.New(), log.NewNopLogger())
+		require.NoError(t, err)
+		return sink
+	}
+
+	t.Run("posts signed events", func(t *testing.T) {
+		var received Payload
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			require.Equal(t, Sign([]byte("secret"), r.Header.Get(TimestampHeader), body), r.Header.Get(SignatureHeader))
+			require.NoError(t, json.Unmarshal(body, &received))
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		t.Cleanup(server.Close)
+
+		require.NoError(t, newSink(t, server.URL, "secret").Write(context.Background(), events))
+		require.Equal(t, events, received.Events)
+	})
+
+	t.Run("does not sign events without a secret", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Empty(t, r.Header.Get(SignatureHeader))
+			w.WriteHeader(http.StatusOK)
+		}))
+		t.Cleanup(server.Close)
+
+		require.NoError(t, newSink(t, server.URL, "").Write(context.Background(), events))
+	})
+
+	t.Run("retries server errors", func(t *testing.T) {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if calls.Add(1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		t.Cleanup(server.Close)
+
+		require.NoError(t, newSink(t, server.URL, "secret").Write(context.Background(), events))
+		require.EqualValues(t, 3, calls.Load())
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		t.Cleanup(server.Close)
+
+		require.Error(t, newSink(t, server.URL, "secret").Write(context.Background(), events))
+		require.EqualValues(t, 3, calls.Load())
+	})
+
+	t.Run("does not retry client errors", func(t *testing.T) {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		t.Cleanup(server.Close)
+
+		require.Error(t, newSink(t, server.URL, "secret").Write(context.Background(), events))
+		require.EqualValues(t, 1, calls.Load())
+	})
+
+	t.Run("rejects invalid URL", func(t *testing.T) {
+		_, err := NewWebhookSink(setting.UnifiedAlertingStateEventsSettings{WebhookURL: "ftp://example.com"}, http.DefaultClient, clock.New(), log.NewNopLogger())
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,326 @@
+package historian
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	history_model "github.com/grafana/grafana/pkg/services/ngalert/state/historian/model"
+	ngstore "github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+const (
+	stateHistoryTable = "alert_state_history"
+	// stateHistoryCleanupBatchSize is the maximum number of entries removed by a single delete statement during cleanup.
+	stateHistoryCleanupBatchSize = 1000
+)
+
+// stateHistoryEntry is a single state transition as it is stored in the alert_state_history table.
+type stateHistoryEntry struct {
+	ID                int64  `xorm:"pk autoincr 'id'"`
+	OrgID             int64  `xorm:"org_id"`
+	RuleUID           string `xorm:"rule_uid"`
+	RuleID            int64  `xorm:"rule_id"`
+	RuleTitle         string `xorm:"rule_title"`
+	RuleGroup         string `xorm:"rule_group"`
+	NamespaceUID      string `xorm:"namespace_uid"`
+	DashboardUID      string `xorm:"dashboard_uid"`
+	PanelID           int64  `xorm:"panel_id"`
+	PreviousState     string `xorm:"previous_state"`
+	CurrentState      string `xorm:"current_state"`
+	ErrorMessage      string `xorm:"error_message"`
+	Labels            string `xorm:"labels"`
+	LabelsFingerprint string `xorm:"labels_fingerprint"`
+	StateValues       string `xorm:"state_values"`
+	ConditionRef      string `xorm:"condition_ref"`
+	EvaluatedAt       int64  `xorm:"evaluated_at"`
+}
+
+func (stateHistoryEntry) TableName() string {
+	return stateHistoryTable
+}
+
+// SQLBackend is a state.Historian that records state history to a dedicated table in the Grafana database.
+type SQLBackend struct {
+	db        db.DB
+	clock     clock.Clock
+	metrics   *metrics.Historian
+	log       log.Logger
+	ac        AccessControl
+	ruleStore RuleStore
+}
+
+func NewSQLBackend(logger log.Logger, store db.DB, metrics *metrics.Historian, ruleStore RuleStore, ac AccessControl) *SQLBackend {
+	return &SQLBackend{
+		db:        store,
+		clock:     clock.New(),
+		metrics:   metrics,
+		log:       logger,
+		ac:        ac,
+		ruleStore: ruleStore,
+	}
+}
+
+// Record writes a number of state transitions for a given rule to the alert_state_history table.
+func (h *SQLBackend) Record(ctx context.Context, rule history_model.RuleMeta, states []state.StateTransition) <-chan error {
+	logger := h.log.FromContext(ctx)
+	entries := statesToHistoryEntries(rule, states, logger)
+
+	errCh := make(chan error, 1)
+	if len(entries) == 0 {
+		close(errCh)
+		return errCh
+	}
+
+	// This is a new background job, so let's create a brand new context for it.
+	// We want it to be isolated, i.e. we don't want grafana shutdowns to interrupt this work
+	// immediately but rather try to flush writes.
+	writeCtx := context.Background()
+	writeCtx, cancel := context.WithTimeout(writeCtx, StateHistoryWriteTimeout)
+	writeCtx = history_model.WithRuleData(writeCtx, rule)
+	writeCtx = trace.ContextWithSpan(writeCtx, trace.SpanFromContext(ctx))
+
+	go func(ctx context.Context) {
+		defer cancel()
+		defer close(errCh)
+		logger := h.log.FromContext(ctx)
+		logger.Debug("Saving state history batch", "samples", len(entries))
+		org := fmt.Sprint(rule.OrgID)
+		h.metrics.WritesTotal.WithLabelValues(org, "sql").Inc()
+		h.metrics.TransitionsTotal.WithLabelValues(org).Add(float64(len(entries)))
+
+		err := h.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+			_, err := sess.BulkInsert(stateHistoryTable, entries, sqlstore.NativeSettingsForDialect(h.db.GetDialect()))
+			return err
+		})
+		if err != nil {
+			logger.Error("Failed to save alert state history batch", "error", err)
+			h.metrics.WritesFailed.WithLabelValues(org, "sql").Inc()
+			h.metrics.TransitionsFailed.WithLabelValues(org).Add(float64(len(entries)))
+			errCh <- fmt.Errorf("failed to save alert state history batch: %w", err)
+			return
+		}
+		logger.Debug("Done saving alert state history batch", "samples", len(entries))
+	}(writeCtx)
+	return errCh
+}
+
+// Query retrieves state history entries from the alert_state_history table and formats the results into a dataframe.
+// The dataframe has the same shape as the one returned by the Loki backend.
+func (h *SQLBackend) Query(ctx context.Context, query models.HistoryQuery) (*data.Frame, error) {
+	uids, err := folderUIDsForFilter(ctx, query, h.ac, h.ruleStore)
+	if err != nil {
+		return nil, err
+	}
+
+	now := h.clock.Now().UTC()
+	if query.To.IsZero() {
+		query.To = now
+	}
+	if query.From.IsZero() {
+		query.From = now.Add(-defaultQueryRange)
+	}
+	limit := query.Limit
+	if limit < 1 {
+		limit = defaultPageSize
+	}
+	if limit > maximumPageSize {
+		limit = maximumPageSize
+	}
+
+	entries := make([]stateHistoryEntry, 0)
+	err = h.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		q := sess.Table(stateHistoryTable).
+			Where("org_id = ?", query.OrgID).
+			And("evaluated_at >= ?", query.From.UnixNano()).
+			And("evaluated_at <= ?", query.To.UnixNano())
+		if query.RuleUID != "" {
+			q = q.And("rule_uid = ?", query.RuleUID)
+		}
+		if query.DashboardUID != "" {
+			q = q.And("dashboard_uid = ?", query.DashboardUID)
+		}
+		if query.PanelID != 0 {
+			q = q.And("panel_id = ?", query.PanelID)
+		}
+		if len(uids) > 0 {
+			args := make([]any, 0, len(uids))
+			for _, uid := range uids {
+				args = append(args, uid)
+			}
+			q = q.In("namespace_uid", args...)
+		}
+		// Return the most recent entries first, so that the limit cuts off the oldest ones.
+		q = q.Desc("evaluated_at", "id")
+
+		// Instance labels are stored as a JSON blob, so label filters are applied while reading the rows.
+		if len(query.Labels) == 0 {
+			return q.Limit(limit).Find(&entries)
+		}
+		rows, err := q.Rows(new(stateHistoryEntry))
+		if err != nil {
+			return err
+		}
+		defer func() { _ = rows.Close() }()
+		for len(entries) < limit && rows.Next() {
+			var entry stateHistoryEntry
+			if err := rows.Scan(&entry); err != nil {
+				return err
+			}
+			matches, err := matchesLabels(&entry, query.Labels)
+			if err != nil {
+				return err
+			}
+			if matches {
+				entries = append(entries, entry)
+			}
+		}
+		// Rows reports sql.ErrNoRows once the result set is exhausted.
+		if err := rows.Err(); err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query state history: %w", err)
+	}
+	slices.Reverse(entries)
+	return historyEntriesToFrame(entries)
+}
+
+// DeleteExpiredStateHistory removes state history entries written by the SQL backend that were evaluated before the given time.
+// It returns the number of deleted entries.
+func DeleteExpiredStateHistory(ctx context.Context, store db.DB, before time.Time) (int64, error) {
+	return ngstore.DeleteInBatches(ctx, store, stateHistoryTable, &stateHistoryEntry{}, stateHistoryCleanupBatchSize, "evaluated_at < ?", before.UnixNano())
+}
+
+func statesToHistoryEntries(rule history_model.RuleMeta, states []state.StateTransition, logger log.Logger) []stateHistoryEntry {
+	entries := make([]stateHistoryEntry, 0, len(states))
+	for _, state := range states {
+		if !shouldRecord(state) {
+			continue
+		}
+
+		sanitizedLabels := removePrivateLabels(state.Labels)
+		labels, err := json.Marshal(sanitizedLabels)
+		if err != nil {
+			logger.Error("Failed to serialize labels of history record for state, skipping", "error", err)
+			continue
+		}
+		values, err := json.Marshal(valuesAsDataBlob(state.State))
+		if err != nil {
+			logger.Error("Failed to serialize values of history record for state, skipping", "error", err)
+			continue
+		}
+
+		entry := stateHistoryEntry{
+			OrgID:             rule.OrgID,
+			RuleUID:           rule.UID,
+			RuleID:            rule.ID,
+			RuleTitle:         rule.Title,
+			RuleGroup:         rule.Group,
+			NamespaceUID:      rule.NamespaceUID,
+			DashboardUID:      rule.DashboardUID,
+			PanelID:           rule.PanelID,
+			PreviousState:     state.PreviousFormatted(),
+			CurrentState:      state.Formatted(),
+			Labels:            string(labels),
+			LabelsFingerprint: labelFingerprint(sanitizedLabels),
+			StateValues:       string(values),
+			ConditionRef:      rule.Condition,
+			EvaluatedAt:       state.State.LastEvaluationTime.UnixNano(),
+		}
+		if state.State.State == eval.Error && state.Error != nil {
+			entry.ErrorMessage = state.Error.Error()
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func matchesLabels(entry *stateHistoryEntry, filter map[string]string) (bool, error) {
+	var labels map[string]string
+	if err := json.Unmarshal([]byte(entry.Labels), &labels); err != nil {
+		return false, fmt.Errorf("failed to unmarshal labels of entry %d: %w", entry.ID, err)
+	}
+	for k, v := range filter {
+		if labels[k] != v {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// historyEntriesToFrame converts stored entries to the same `time`, `line` and `labels` vectors that the Loki backend returns.
+func historyEntriesToFrame(entries []stateHistoryEntry) (*data.Frame, error) {
+	frame := data.NewFrame("states")
+	lbls := data.Labels(map[string]string{})
+
+	times := make([]time.Time, 0, len(entries))
+	lines := make([]json.RawMessage, 0, len(entries))
+	labels := make([]json.RawMessage, 0, len(entries))
+	for _, e := range entries {
+		entry := LokiEntry{
+			SchemaVersion: 1,
+			Previous:      e.PreviousState,
+			Current:       e.CurrentState,
+			Error:         e.ErrorMessage,
+			Values:        simplejson.New(),
+			Condition:     e.ConditionRef,
+			DashboardUID:  e.DashboardUID,
+			PanelID:       e.PanelID,
+			Fingerprint:   e.LabelsFingerprint,
+			RuleTitle:     e.RuleTitle,
+			RuleID:        e.RuleID,
+			RuleUID:       e.RuleUID,
+		}
+		if e.StateValues != "" {
+			values, err := simplejson.NewJson([]byte(e.StateValues))
+			if err != nil {
+				return nil, fmt.Errorf("failed to unmarshal values of entry %d: %w", e.ID, err)
+			}
+			entry.Values = values
+		}
+		if err := json.Unmarshal([]byte(e.Labels), &entry.InstanceLabels); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal labels of entry %d: %w", e.ID, err)
+		}
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize entry %d: %w", e.ID, err)
+		}
+		streamLbls, err := json.Marshal(map[string]string{
+			StateHistoryLabelKey: StateHistoryLabelValue,
+			OrgIDLabel:           fmt.Sprint(e.OrgID),
+			GroupLabel:           e.RuleGroup,
+			FolderUIDLabel:       e.NamespaceUID,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize stream labels: %w", err)
+		}
+
+		times = append(times, time.Unix(0, e.EvaluatedAt))
+		lines = append(lines, line)
+		labels = append(labels, streamLbls)
+	}
+
+	frame.Fields = append(frame.Fields, data.NewField(dfTime, lbls, times))
+	frame.Fields = append(frame.Fields, data.NewField(dfLine, lbls, lines))
+	frame.Fields = append(frame.Fields, data.NewField(dfLabels, lbls, labels))
+	return frame, nil
+}
@@ -0,0 +1,196 @@
+package historian
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	acfakes "github.com/grafana/grafana/pkg/services/ngalert/accesscontrol/fakes"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestStatesToHistoryEntries(t *testing.T) {
+	logger := log.NewNopLogger()
+	rule := createTestRule()
+	rule.Condition = "B"
+
+	t.Run("skips non-transitory states", func(t *testing.T) {
+		states := singleFromNormal(&state.State{State: eval.Normal})
+
+		require.Empty(t, statesToHistoryEntries(rule, states, logger))
+	})
+
+	t.Run("maps rule, labels and values", func(t *testing.T) {
+		now := time.Now()
+		states := singleFromNormal(&state.State{
+			State:              eval.Alerting,
+			Labels:             data.Labels{"a": "b", "__private__": "c"},
+			Values:             map[string]float64{"A": 1.5},
+			LastEvaluationTime: now,
+		})
+
+		entries := statesToHistoryEntries(rule, states, logger)
+
+		require.Len(t, entries, 1)
+		e := entries[0]
+		require.Equal(t, rule.UID, e.RuleUID)
+		require.Equal(t, rule.NamespaceUID, e.NamespaceUID)
+		require.Equal(t, rule.Group, e.RuleGroup)
+		require.Equal(t, "B", e.ConditionRef)
+		require.Equal(t, "Normal", e.PreviousState)
+		require.Equal(t, "Alerting", e.CurrentState)
+		require.JSONEq(t, `{"a":"b"}`, e.Labels)
+		require.JSONEq(t, `{"A":1.5}`, e.StateValues)
+		require.Equal(t, labelFingerprint(data.Labels{"a": "b"}), e.LabelsFingerprint)
+		require.Equal(t, now.UnixNano(), e.EvaluatedAt)
+	})
+
+	t.Run("maps evaluation errors", func(t *testing.T) {
+		states := singleFromNormal(&state.State{State: eval.Error, Error: errors.New("oh no")})
+
+		entries := statesToHistoryEntries(rule, states, logger)
+
+		require.Len(t, entries, 1)
+		require.Equal(t, "oh no", entries[0].ErrorMessage)
+		require.JSONEq(t, `{}`, entries[0].StateValues)
+	})
+}
+
+func TestIntegrationSQLBackend(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	sqlStore := db.InitTestDB(t)
+	met := metrics.NewHistorianMetrics(prometheus.NewRegistry(), metrics.Subsystem)
+	ac := &acfakes.FakeRuleService{}
+	ac.CanReadAllRulesFunc = func(ctx context.Context, user identity.Requester) (bool, error) {
+		return true, nil
+	}
+	backend := NewSQLBackend(log.NewNopLogger(), sqlStore, met, fakes.NewRuleStore(t), ac)
+	usr := accesscontrol.BackgroundUser("test", 1, org.RoleNone, nil)
+
+	rule := createTestRule()
+	otherRule := createTestRule()
+	otherRule.UID = "other-rule-uid"
+	otherRule.NamespaceUID = "other-folder"
+
+	start := time.Now().Add(-time.Hour).Truncate(time.Second)
+	for i := 0; i < 3; i++ {
+		states := singleFromNormal(&state.State{
+			State:              eval.Alerting,
+			Labels:             data.Labels{"instance": "a"},
+			Values:             map[string]float64{"A": float64(i)},
+			LastEvaluationTime: start.Add(time.Duration(i) * time.Minute),
+		})
+		require.NoError(t, <-backend.Record(context.Background(), rule, states))
+	}
+	states := singleFromNormal(&state.State{
+		State:              eval.Alerting,
+		Labels:             data.Labels{"instance": "b"},
+		LastEvaluationTime: start.Add(10 * time.Minute),
+	})
+	require.NoError(t, <-backend.Record(context.Background(), otherRule, states))
+
+	t.Run("returns entries of a rule in ascending order", func(t *testing.T) {
+		frame, err := backend.Query(context.Background(), models.HistoryQuery{OrgID: rule.OrgID, RuleUID: rule.UID, SignedInUser: usr})
+		require.NoError(t, err)
+
+		require.Equal(t, 3, frame.Rows())
+		for i := 0; i < 3; i++ {
+			require.Equal(t, start.Add(time.Duration(i)*time.Minute).UnixNano(), frame.Fields[0].At(i).(time.Time).UnixNano())
+
+			var entry LokiEntry
+			require.NoError(t, json.Unmarshal(frame.Fields[1].At(i).(json.RawMessage), &entry))
+			require.Equal(t, rule.UID, entry.RuleUID)
+			require.Equal(t, "Alerting", entry.Current)
+			require.Equal(t, map[string]string{"instance": "a"}, entry.InstanceLabels)
+			require.Equal(t, float64(i), entry.Values.Get("A").MustFloat64())
+
+			var lbls map[string]string
+			require.NoError(t, json.Unmarshal(frame.Fields[2].At(i).(json.RawMessage), &lbls))
+			require.Equal(t, map[string]string{
+				StateHistoryLabelKey: StateHistoryLabelValue,
+				OrgIDLabel:           "1",
+				GroupLabel:           rule.Group,
+				FolderUIDLabel:       rule.NamespaceUID,
+			}, lbls)
+		}
+	})
+
+	t.Run("keeps the most recent entries when limited", func(t *testing.T) {
+		frame, err := backend.Query(context.Background(), models.HistoryQuery{OrgID: rule.OrgID, RuleUID: rule.UID, Limit: 2, SignedInUser: usr})
+		require.NoError(t, err)
+
+		require.Equal(t, 2, frame.Rows())
+		require.Equal(t, start.Add(time.Minute).UnixNano(), frame.Fields[0].At(0).(time.Time).UnixNano())
+	})
+
+	t.Run("filters by labels", func(t *testing.T) {
+		frame, err := backend.Query(context.Background(), models.HistoryQuery{OrgID: rule.OrgID, Labels: map[string]string{"instance": "b"}, SignedInUser: usr})
+		require.NoError(t, err)
+
+		require.Equal(t, 1, frame.Rows())
+		var entry LokiEntry
+		require.NoError(t, json.Unmarshal(frame.Fields[1].At(0).(json.RawMessage), &entry))
+		require.Equal(t, otherRule.UID, entry.RuleUID)
+	})
+
+	t.Run("filters by time range", func(t *testing.T) {
+		frame, err := backend.Query(context.Background(), models.HistoryQuery{OrgID: rule.OrgID, From: start.Add(time.Minute), To: start.Add(5 * time.Minute), SignedInUser: usr})
+		require.NoError(t, err)
+
+		require.Equal(t, 2, frame.Rows())
+	})
+
+	t.Run("returns only entries in folders the user can access", func(t *testing.T) {
+		ac := &acfakes.FakeRuleService{}
+		ac.HasAccessInFolderFunc = func(ctx context.Context, user identity.Requester, namespaced models.Namespaced) (bool, error) {
+			return namespaced.GetNamespaceUID() == otherRule.NamespaceUID, nil
+		}
+		rules := fakes.NewRuleStore(t)
+		rules.PutRule(context.Background(),
+			&models.AlertRule{OrgID: rule.OrgID, UID: rule.UID, NamespaceUID: rule.NamespaceUID},
+			&models.AlertRule{OrgID: otherRule.OrgID, UID: otherRule.UID, NamespaceUID: otherRule.NamespaceUID},
+		)
+		restricted := NewSQLBackend(log.NewNopLogger(), sqlStore, met, rules, ac)
+
+		frame, err := restricted.Query(context.Background(), models.HistoryQuery{OrgID: rule.OrgID, SignedInUser: usr})
+		require.NoError(t, err)
+
+		require.Equal(t, 1, frame.Rows())
+		var lbls map[string]string
+		require.NoError(t, json.Unmarshal(frame.Fields[2].At(0).(json.RawMessage), &lbls))
+		require.Equal(t, otherRule.NamespaceUID, lbls[FolderUIDLabel])
+	})
+
+	t.Run("deletes expired entries", func(t *testing.T) {
+		deleted, err := DeleteExpiredStateHistory(context.Background(), sqlStore, start.Add(2*time.Minute))
+		require.NoError(t, err)
+		require.EqualValues(t, 2, deleted)
+
+		frame, err := backend.Query(context.Background(), models.HistoryQuery{OrgID: rule.OrgID, SignedInUser: usr})
+		require.NoError(t, err)
+		require.Equal(t, 2, frame.Rows())
+	})
+}
@@ -3,6 +3,7 @@ package state
 import (
 	"context"
 	"net/url"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -26,8 +27,8 @@ var (
 
 // AlertInstanceManager defines the interface for querying the current alert instances.
 type AlertInstanceManager interface {
-	GetAll(orgID int64) []*State
-	GetStatesForRuleUID(orgID int64, alertRuleUID string) []*State
+	GetAll(ctx context.Context, orgID int64) []*State
+	GetStatesForRuleUID(ctx context.Context, orgID int64, alertRuleUID string) []*State
 }
 
 type StatePersister interface {
@@ -51,7 +52,12 @@ type Manager struct {
 	instanceStore InstanceStore
 	images        ImageCapturer
 	historian     Historian
+	events        EventSender
+	windows       MaintenanceWindows
+	acks          Acknowledgements
 	externalURL   *url.URL
+	// shardedRules is not nil when other members of the cluster evaluate some of the rules.
+	shardedRules RuleReader
 
 	doNotSaveNormalState           bool
 	applyNoDataAndErrorToAllStates bool
@@ -67,6 +73,16 @@ type ManagerCfg struct {
 	Images        ImageCapturer
 	Clock         clock.Clock
 	Historian     Historian
+	// Events, if not nil, receives all state transitions of alert instances.
+	Events EventSender
+	// MaintenanceWindows, if not nil, is used to add the maintenance window that silences a firing state to its reason.
+	MaintenanceWindows MaintenanceWindows
+	// Acknowledgements, if not nil, is used to add the acknowledgement and the assignee of an alert instance to the
+	// alerts that are sent to the Alertmanager.
+	Acknowledgements Acknowledgements
+	// ShardedRuleReader, if not nil, makes the get methods read the states of the rules that are evaluated by other
+	// members of the cluster from the database. It is set when the evaluation of the rules is split between the members.
+	ShardedRuleReader RuleReader
 	// DoNotSaveNormalState controls whether eval.Normal state is persisted to the database and returned by get methods
 	DoNotSaveNormalState bool
 	// MaxStateSaveConcurrency controls the number of goroutines (per rule) that can save alert state in parallel.
@@ -102,6 +118,10 @@ func NewManager(cfg ManagerCfg, statePersister StatePersister) *Manager {
 		instanceStore:                  cfg.InstanceStore,
 		images:                         cfg.Images,
 		historian:                      cfg.Historian,
+		events:                         cfg.Events,
+		windows:                        cfg.MaintenanceWindows,
+		acks:                           cfg.Acknowledgements,
+		shardedRules:                   cfg.ShardedRuleReader,
 		clock:                          cfg.Clock,
 		externalURL:                    cfg.ExternalURL,
 		doNotSaveNormalState:           cfg.DoNotSaveNormalState,
@@ -187,44 +207,14 @@ func (st *Manager) Warm(ctx context.Context, rulesReader RuleReader) {
 				continue
 			}
 
-			// nil safety.
-			annotations := ruleForEntry.Annotations
-			if annotations == nil {
-				annotations = make(map[string]string)
-			}
-
 			rulesStates, ok := orgStates[entry.RuleUID]
 			if !ok {
 				rulesStates = &ruleStates{states: make(map[data.Fingerprint]*State)}
 				orgStates[entry.RuleUID] = rulesStates
 			}
 
-			lbs := map[string]string(entry.Labels)
-			cacheID := entry.Labels.Fingerprint()
-			var resultFp data.Fingerprint
-			if entry.ResultFingerprint != "" {
-				fp, err := strconv.ParseUint(entry.ResultFingerprint, 16, 64)
-				if err != nil {
-					st.log.Error("Failed to parse result fingerprint of alert instance", "error", err, "ruleUID", entry.RuleUID)
-				}
-				resultFp = data.Fingerprint(fp)
-			}
-			rulesStates.states[cacheID] = &State{
-				AlertRuleUID:         entry.RuleUID,
-				OrgID:                entry.RuleOrgID,
-				CacheID:              cacheID,
-				Labels:               lbs,
-				State:                translateInstanceState(entry.CurrentState),
-				StateReason:          entry.CurrentReason,
-				LastEvaluationString: "",
-				StartsAt:             entry.CurrentStateSince,
-				EndsAt:               entry.CurrentStateEnd,
-				LastEvaluationTime:   entry.LastEvalTime,
-				Annotations:          annotations,
-				ResultFingerprint:    resultFp,
-				ResolvedAt:           entry.ResolvedAt,
-				LastSentAt:           entry.LastSentAt,
-			}
+			state := st.stateFromInstance(ruleForEntry, entry)
+			rulesStates.states[state.CacheID] = state
 			statesCount++
 		}
 	}
@@ -233,6 +223,89 @@ func (st *Manager) Warm(ctx context.Context, rulesReader RuleReader) {
 	st.log.Info("State cache has been initialized", "states", statesCount, "duration", time.Since(startTime))
 }
 
+// stateFromInstance restores the state of the rule from the alert instance saved in the database.
+func (st *Manager) stateFromInstance(rule *ngModels.AlertRule, entry *ngModels.AlertInstance) *State {
+	// nil safety.
+	annotations := rule.Annotations
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+
+	var resultFp data.Fingerprint
+	if entry.ResultFingerprint != "" {
+		fp, err := strconv.ParseUint(entry.ResultFingerprint, 16, 64)
+		if err != nil {
+			st.log.Error("Failed to parse result fingerprint of alert instance", "error", err, "ruleUID", entry.RuleUID)
+		}
+		resultFp = data.Fingerprint(fp)
+	}
+	return &State{
+		AlertRuleUID:         entry.RuleUID,
+		OrgID:                entry.RuleOrgID,
+		CacheID:              entry.Labels.Fingerprint(),
+		Labels:               map[string]string(entry.Labels),
+		State:                translateInstanceState(entry.CurrentState),
+		StateReason:          entry.CurrentReason,
+		LastEvaluationString: "",
+		StartsAt:             entry.CurrentStateSince,
+		EndsAt:               entry.CurrentStateEnd,
+		LastEvaluationTime:   entry.LastEvalTime,
+		Annotations:          annotations,
+		ResultFingerprint:    resultFp,
+		ResolvedAt:           entry.ResolvedAt,
+		LastSentAt:           entry.LastSentAt,
+		KeepFiringSince:      entry.KeepFiringSince,
+	}
+}
+
+// LoadRuleState replaces the cached state of the rule with the state saved in the database.
+// It is used when this instance takes over the evaluation of the rule from another member of the cluster.
+func (st *Manager) LoadRuleState(ctx context.Context, rule *ngModels.AlertRule) {
+	if st.instanceStore == nil {
+		return
+	}
+	logger := st.log.FromContext(ctx).New(rule.GetKey().LogContext()...)
+	alertInstances, err := st.instanceStore.ListAlertInstances(ctx, &ngModels.ListAlertInstancesQuery{
+		RuleOrgID: rule.OrgID,
+		RuleUID:   rule.UID,
+	})
+	if err != nil {
+		logger.Error("Unable to fetch previous state of the rule", "error", err)
+		return
+	}
+
+	st.cache.removeByRuleUID(rule.OrgID, rule.UID)
+	for _, entry := range alertInstances {
+		st.cache.set(st.stateFromInstance(rule, entry))
+	}
+	logger.Debug("Loaded state of the rule", "states", len(alertInstances))
+}
+
+// ReleaseRule saves the cached state of the rule through the state persister and removes it from the cache,
+// so that another member of the cluster can continue the evaluation of the rule from it.
+func (st *Manager) ReleaseRule(ctx context.Context, ruleKey ngModels.AlertRuleKey) {
+	states := st.cache.getStatesForRuleUID(ruleKey.OrgID, ruleKey.UID, false)
+	if len(states) > 0 {
+		ctx, span := st.tracer.Start(ctx, "alert rule state release")
+		defer span.End()
+		transitions := make(StateTransitions, 0, len(states))
+		for _, s := range states {
+			transitions = append(transitions, StateTransition{
+				State:               s,
+				PreviousState:       s.State,
+				PreviousStateReason: s.StateReason,
+			})
+		}
+		st.persister.Sync(ctx, span, transitions)
+	}
+	st.ForgetRule(ruleKey)
+}
+
+// ForgetRule removes the state of the rule from the cache without changing the database.
+func (st *Manager) ForgetRule(ruleKey ngModels.AlertRuleKey) {
+	st.cache.removeByRuleUID(ruleKey.OrgID, ruleKey.UID)
+}
+
 func (st *Manager) Get(orgID int64, alertRuleUID string, stateId data.Fingerprint) *State {
 	return st.cache.get(orgID, alertRuleUID, stateId)
 }
@@ -293,11 +366,17 @@ func (st *Manager) ResetStateByRuleUID(ctx context.Context, rule *ngModels.Alert
 	ruleKey := rule.GetKey()
 	transitions := st.DeleteStateByRuleUID(ctx, ruleKey, reason)
 
-	if rule == nil || st.historian == nil || len(transitions) == 0 {
+	if rule == nil || len(transitions) == 0 {
 		return transitions
 	}
 
 	ruleMeta := history_model.NewRuleMeta(rule, st.log)
+	if st.events != nil {
+		st.events.SendEvents(ctx, ruleMeta, transitions)
+	}
+	if st.historian == nil {
+		return transitions
+	}
 	errCh := st.historian.Record(ctx, ruleMeta, transitions)
 	go func() {
 		err := <-errCh
@@ -340,17 +419,35 @@ func (st *Manager) ProcessEvalResults(
 
 	allChanges := StateTransitions(append(states, staleStates...))
 
+	notInhibited := allChanges
+	if firing := st.firingDependencies(ctx, logger, alertRule); len(firing) > 0 {
+		logger.Debug("Notifications are inhibited by firing rules the rule depends on", "dependencies", firing)
+		span.AddEvent("notifications inhibited", trace.WithAttributes(
+			attribute.StringSlice("dependencies", firing),
+		))
+		notInhibited = inhibit(allChanges)
+	}
+	if st.windows != nil {
+		st.markSilencedByMaintenanceWindows(alertRule.OrgID, allChanges, evaluatedAt)
+	}
+	if st.acks != nil {
+		st.applyAcknowledgements(ctx, logger, allChanges)
+	}
+
 	// It's important that this is done *before* we sync the states to the persister. Otherwise, we will not persist
 	// the LastSentAt field to the store.
 	var statesToSend StateTransitions
 	if send != nil {
-		statesToSend = st.updateLastSentAt(allChanges, evaluatedAt)
+		statesToSend = st.updateLastSentAt(notInhibited, evaluatedAt)
 	}
 
 	st.persister.Sync(ctx, span, allChanges)
 	if st.historian != nil {
 		st.historian.Record(ctx, history_model.NewRuleMeta(alertRule, logger), allChanges)
 	}
+	if st.events != nil {
+		st.events.SendEvents(ctx, history_model.NewRuleMeta(alertRule, logger), allChanges)
+	}
 
 	// Optional callback intended for sending the states to an alertmanager.
 	// Some uses ,such as backtesting or the testing api, do not send.
@@ -361,6 +458,134 @@ func (st *Manager) ProcessEvalResults(
 	return allChanges
 }
 
+// firingDependencies returns the UIDs of the rules that the given rule depends on and that have Alerting states.
+// The states of the rules that are not in the cache, for example because another member of the cluster evaluates them,
+// are read from the database.
+func (st *Manager) firingDependencies(ctx context.Context, logger log.Logger, alertRule *ngModels.AlertRule) []string {
+	var result []string
+	for _, uid := range alertRule.DependsOn {
+		firing, ok := st.cache.hasFiringStates(alertRule.OrgID, uid)
+		if !ok {
+			firing = st.hasFiringInstances(ctx, logger, alertRule.OrgID, uid)
+		}
+		if firing {
+			result = append(result, uid)
+		}
+	}
+	return result
+}
+
+// hasFiringInstances returns true if any alert instance of the rule saved in the database is firing.
+func (st *Manager) hasFiringInstances(ctx context.Context, logger log.Logger, orgID int64, alertRuleUID string) bool {
+	if st.instanceStore == nil {
+		return false
+	}
+	instances, err := st.instanceStore.ListAlertInstances(ctx, &ngModels.ListAlertInstancesQuery{
+		RuleOrgID: orgID,
+		RuleUID:   alertRuleUID,
+	})
+	if err != nil {
+		logger.Error("Unable to fetch the state of the rule the rule depends on", "dependency", alertRuleUID, "error", err)
+		return false
+	}
+	for _, instance := range instances {
+		if instance.CurrentState == ngModels.InstanceStateFiring {
+			return true
+		}
+	}
+	return false
+}
+
+// inhibit marks the firing states of the transitions as inhibited, and returns the transitions that are not.
+// Inhibited states are not sent to the Alertmanager, and therefore, alerts that were sent before they got inhibited
+// are resolved by the Alertmanager once they expire. Resolved states are not inhibited.
+func inhibit(transitions StateTransitions) StateTransitions {
+	result := make(StateTransitions, 0, len(transitions))
+	for _, t := range transitions {
+		switch t.State.State {
+		case eval.Alerting, eval.NoData, eval.Error:
+			if t.StateReason == "" {
+				t.StateReason = ngModels.StateReasonInhibited
+			} else {
+				t.StateReason = ngModels.ConcatReasons(t.StateReason, ngModels.StateReasonInhibited)
+			}
+			continue
+		}
+		result = append(result, t)
+	}
+	return result
+}
+
+// markSilencedByMaintenanceWindows adds the maintenance window that silences the firing states of the transitions to
+// their state reason, so that the state history tells why no notifications were sent. The states are still sent to
+// the Alertmanager, where the silence of the maintenance window applies.
+func (st *Manager) markSilencedByMaintenanceWindows(orgID int64, transitions StateTransitions, evaluatedAt time.Time) {
+	for _, t := range transitions {
+		switch t.State.State {
+		case eval.Alerting, eval.NoData, eval.Error:
+		default:
+			continue
+		}
+		title, ok := st.windows.MaintenanceWindow(orgID, t.Labels, evaluatedAt)
+		if !ok {
+			continue
+		}
+		if t.StateReason == "" {
+			t.StateReason = ngModels.StateReasonMaintenanceWindow(title)
+		} else {
+			t.StateReason = ngModels.ConcatReasons(t.StateReason, ngModels.StateReasonMaintenanceWindow(title))
+		}
+	}
+}
+
+// applyAcknowledgements sets the acknowledgements of the firing and pending states of the transitions, and silences the
+// notifications of the acknowledged firing states. The labels of the states are not changed, so that acknowledging an
+// alert does not resolve it in the Alertmanager. Instead, the acknowledged firing states are marked as Acknowledged,
+// so that an acknowledged copy of their alerts is sent, which is resolved once they are no longer acknowledged.
+// Resolved states keep the acknowledgement they had while firing, so that the resolved alert has the same annotations
+// as the firing one, and their acknowledgements are released before the resolved alerts are sent.
+func (st *Manager) applyAcknowledgements(ctx context.Context, logger log.Logger, transitions StateTransitions) {
+	var resolved []ngModels.AlertInstanceKey
+	for _, t := range transitions {
+		wasAcknowledged := t.State.Acknowledged
+		t.State.Acknowledged = false
+		switch t.State.State {
+		case eval.Alerting, eval.Pending, eval.NoData, eval.Error:
+			t.State.Acknowledgement = nil
+			key, err := t.State.GetAlertInstanceKey()
+			if err != nil {
+				logger.Warn("Failed to get alert instance key", "error", err)
+				break
+			}
+			ack, ok := st.acks.Acknowledgement(key)
+			if !ok {
+				break
+			}
+			t.State.Acknowledgement = &ack
+			if t.State.State == eval.Alerting && ack.IsAcknowledged(t.State.LastEvaluationTime) {
+				t.State.Acknowledged = true
+				if err := st.acks.Suppress(ctx, key, t.State.Labels); err != nil {
+					logger.Warn("Failed to silence acknowledged alert instance", "error", err)
+				}
+			}
+		case eval.Normal:
+			if t.PreviousState == eval.Normal || t.State.Acknowledgement == nil {
+				break
+			}
+			key, err := t.State.GetAlertInstanceKey()
+			if err != nil {
+				logger.Warn("Failed to get alert instance key", "error", err)
+				break
+			}
+			resolved = append(resolved, key)
+		}
+		t.State.AcknowledgementChanged = t.State.Acknowledged != wasAcknowledged
+	}
+	if err := st.acks.Release(ctx, resolved...); err != nil {
+		logger.Warn("Failed to release the acknowledgements of resolved alert instances", "error", err)
+	}
+}
+
 // updateLastSentAt returns the subset StateTransitions that need sending and updates their LastSentAt field.
 // Note: This is not idempotent, running this twice can (and usually will) return different results.
 func (st *Manager) updateLastSentAt(states StateTransitions, evaluatedAt time.Time) StateTransitions {
@@ -503,6 +728,11 @@ func (st *Manager) setNextState(ctx context.Context, alertRule *ngModels.AlertRu
 		currentState.StateReason = resultStateReason(result, alertRule)
 	}
 
+	// An Alerting state whose condition has cleared is kept firing for the keep_firing_for duration of the rule
+	if currentState.State == eval.Alerting && currentState.KeepFiringSince != nil {
+		currentState.StateReason = ngModels.StateReasonKeepFiring
+	}
+
 	// Set Resolved property so the scheduler knows to send a postable alert
 	// to Alertmanager.
 	newlyResolved := false
@@ -548,12 +778,79 @@ func resultStateReason(result eval.Result, rule *ngModels.AlertRule) string {
 	return result.State.String()
 }
 
-func (st *Manager) GetAll(orgID int64) []*State {
+// GetAll returns the states of all rules of the organization. When the evaluation of the rules is split between the
+// members of the cluster, the states of the rules that other members evaluate are read from the database.
+func (st *Manager) GetAll(ctx context.Context, orgID int64) []*State {
 	allStates := st.cache.getAll(orgID, st.doNotSaveNormalState)
-	return allStates
+	if st.shardedRules == nil {
+		return allStates
+	}
+	cached := st.cache.ruleUIDs(orgID)
+	return append(allStates, st.statesFromDatabase(ctx, &ngModels.ListAlertInstancesQuery{RuleOrgID: orgID}, func(uid string) bool {
+		_, ok := cached[uid]
+		return !ok
+	})...)
 }
-func (st *Manager) GetStatesForRuleUID(orgID int64, alertRuleUID string) []*State {
-	return st.cache.getStatesForRuleUID(orgID, alertRuleUID, st.doNotSaveNormalState)
+
+// GetStatesForRuleUID returns the states of the rule. When the evaluation of the rules is split between the members of
+// the cluster and the rule is evaluated by another member, the states are read from the database.
+func (st *Manager) GetStatesForRuleUID(ctx context.Context, orgID int64, alertRuleUID string) []*State {
+	if st.shardedRules == nil || st.cache.containsRule(orgID, alertRuleUID) {
+		return st.cache.getStatesForRuleUID(orgID, alertRuleUID, st.doNotSaveNormalState)
+	}
+	return st.statesFromDatabase(ctx, &ngModels.ListAlertInstancesQuery{RuleOrgID: orgID, RuleUID: alertRuleUID}, nil)
+}
+
+// statesFromDatabase restores the states of the alert instances that match the query from the database, where the
+// members of the cluster save the states of the rules they evaluate. If include is not nil, only the instances of the
+// rules it accepts are restored.
+func (st *Manager) statesFromDatabase(ctx context.Context, query *ngModels.ListAlertInstancesQuery, include func(ruleUID string) bool) []*State {
+	if st.instanceStore == nil {
+		return nil
+	}
+	logger := st.log.FromContext(ctx)
+	alertInstances, err := st.instanceStore.ListAlertInstances(ctx, query)
+	if err != nil {
+		logger.Error("Unable to fetch the state of rules evaluated by other instances", "error", err)
+		return nil
+	}
+	entries := make([]*ngModels.AlertInstance, 0, len(alertInstances))
+	ruleUIDs := make([]string, 0)
+	for _, entry := range alertInstances {
+		if include != nil && !include(entry.RuleUID) {
+			continue
+		}
+		if !slices.Contains(ruleUIDs, entry.RuleUID) {
+			ruleUIDs = append(ruleUIDs, entry.RuleUID)
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+
+	rules, err := st.shardedRules.ListAlertRules(ctx, &ngModels.ListAlertRulesQuery{OrgID: query.RuleOrgID, RuleUIDs: ruleUIDs})
+	if err != nil {
+		logger.Error("Unable to fetch the rules evaluated by other instances", "error", err)
+		return nil
+	}
+	ruleByUID := make(map[string]*ngModels.AlertRule, len(rules))
+	for _, rule := range rules {
+		ruleByUID[rule.UID] = rule
+	}
+	result := make([]*State, 0, len(entries))
+	for _, entry := range entries {
+		rule, ok := ruleByUID[entry.RuleUID]
+		if !ok {
+			continue
+		}
+		s := st.stateFromInstance(rule, entry)
+		if st.doNotSaveNormalState && IsNormalStateWithNoReason(s) {
+			continue
+		}
+		result = append(result, s)
+	}
+	return result
 }
 
 func (st *Manager) Put(states []*State) {
@@ -16,6 +16,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
@@ -34,6 +35,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/services/ngalert/state/historian"
+	history_model "github.com/grafana/grafana/pkg/services/ngalert/state/historian/model"
 	"github.com/grafana/grafana/pkg/services/ngalert/tests"
 	"github.com/grafana/grafana/pkg/tests/testsuite"
 	"github.com/grafana/grafana/pkg/util"
@@ -77,6 +79,7 @@ func TestWarmStateCache(t *testing.T) {
 			LastEvaluationTime: evaluationTime,
 			LastSentAt:         util.Pointer(evaluationTime.Add(-1 * time.Minute)),
 			ResolvedAt:         nil,
+			KeepFiringSince:    util.Pointer(evaluationTime.Add(-30 * time.Second)),
 			Annotations:        map[string]string{"testAnnoKey": "testAnnoValue"},
 			ResultFingerprint:  data.Fingerprint(math.MaxUint64 - 1),
 		},
@@ -158,6 +161,7 @@ func TestWarmStateCache(t *testing.T) {
 		CurrentStateEnd:   evaluationTime.Add(1 * time.Minute),
 		LastSentAt:        util.Pointer(evaluationTime.Add(-1 * time.Minute)),
 		ResolvedAt:        nil,
+		KeepFiringSince:   util.Pointer(evaluationTime.Add(-30 * time.Second)),
 		Labels:            labels,
 		ResultFingerprint: data.Fingerprint(math.MaxUint64 - 1).String(),
 	})
@@ -597,6 +601,104 @@ func TestProcessEvalResults(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc:      "alerting -> normal keeps firing when KeepFiringFor is set but not exceeded",
+			alertRule: baseRuleWith(m.WithKeepFiringFor(2 * evaluationInterval)),
+			evalResults: map[time.Time]eval.Results{
+				t1: {
+					newResult(eval.WithState(eval.Alerting), eval.WithLabels(labels1)),
+				},
+				t2: {
+					newResult(eval.WithState(eval.Normal), eval.WithLabels(labels1)),
+				},
+				t3: {
+					newResult(eval.WithState(eval.Normal), eval.WithLabels(labels1)),
+				},
+			},
+			expectedAnnotations: 2,
+			expectedStates: []*state.State{
+				{
+					Labels:             labels["system + rule + labels1"],
+					ResultFingerprint:  labels1.Fingerprint(),
+					State:              eval.Alerting,
+					StateReason:        models.StateReasonKeepFiring,
+					LatestResult:       newEvaluation(t3, eval.Normal),
+					StartsAt:           t1,
+					EndsAt:             t3.Add(state.ResendDelay * 4),
+					LastEvaluationTime: t3,
+					KeepFiringSince:    &t2,
+					LastSentAt:         &t1,
+				},
+			},
+		},
+		{
+			desc:      "alerting -> normal resolves when KeepFiringFor is exceeded",
+			alertRule: baseRuleWith(m.WithKeepFiringFor(2 * evaluationInterval)),
+			evalResults: map[time.Time]eval.Results{
+				t1: {
+					newResult(eval.WithState(eval.Alerting), eval.WithLabels(labels1)),
+				},
+				t2: {
+					newResult(eval.WithState(eval.Normal), eval.WithLabels(labels1)),
+				},
+				t3: {
+					newResult(eval.WithState(eval.Normal), eval.WithLabels(labels1)),
+				},
+				tn(4): {
+					newResult(eval.WithState(eval.Normal), eval.WithLabels(labels1)),
+				},
+			},
+			expectedAnnotations: 3,
+			expectedStates: []*state.State{
+				{
+					Labels:             labels["system + rule + labels1"],
+					ResultFingerprint:  labels1.Fingerprint(),
+					State:              eval.Normal,
+					LatestResult:       newEvaluation(tn(4), eval.Normal),
+					StartsAt:           tn(4),
+					EndsAt:             tn(4),
+					LastEvaluationTime: tn(4),
+					ResolvedAt:         util.Pointer(tn(4)),
+					LastSentAt:         util.Pointer(tn(4)),
+				},
+			},
+		},
+		{
+			desc:      "alerting -> normal -> alerting -> normal restarts KeepFiringFor",
+			alertRule: baseRuleWith(m.WithKeepFiringFor(2 * evaluationInterval)),
+			evalResults: map[time.Time]eval.Results{
+				t1: {
+					newResult(eval.WithState(eval.Alerting), eval.WithLabels(labels1)),
+				},
+				t2: {
+					newResult(eval.WithState(eval.Normal), eval.WithLabels(labels1)),
+				},
+				t3: {
+					newResult(eval.WithState(eval.Alerting), eval.WithLabels(labels1)),
+				},
+				tn(4): {
+					newResult(eval.WithState(eval.Normal), eval.WithLabels(labels1)),
+				},
+				tn(5): {
+					newResult(eval.WithState(eval.Normal), eval.WithLabels(labels1)),
+				},
+			},
+			expectedAnnotations: 4,
+			expectedStates: []*state.State{
+				{
+					Labels:             labels["system + rule + labels1"],
+					ResultFingerprint:  labels1.Fingerprint(),
+					State:              eval.Alerting,
+					StateReason:        models.StateReasonKeepFiring,
+					LatestResult:       newEvaluation(tn(5), eval.Normal),
+					StartsAt:           t1,
+					EndsAt:             tn(5).Add(state.ResendDelay * 4),
+					LastEvaluationTime: tn(5),
+					KeepFiringSince:    util.Pointer(tn(4)),
+					LastSentAt:         util.Pointer(tn(4)),
+				},
+			},
+		},
 		{
 			desc:      "pending -> alerting -> normal -> pending resolves and resets ResolvedAt at t4",
 			alertRule: baseRuleWith(m.WithForNTimes(1)),
@@ -1530,7 +1632,7 @@ func TestProcessEvalResults(t *testing.T) {
 				results += len(res)
 			}
 
-			states := st.GetStatesForRuleUID(tc.alertRule.OrgID, tc.alertRule.UID)
+			states := st.GetStatesForRuleUID(context.Background(), tc.alertRule.OrgID, tc.alertRule.UID)
 			assert.Len(t, states, len(tc.expectedStates))
 
 			expectedStates := make(map[data.Fingerprint]*state.State, len(tc.expectedStates))
@@ -1628,7 +1730,7 @@ func TestProcessEvalResults(t *testing.T) {
 
 		_ = st.ProcessEvalResults(context.Background(), time, rule, res, systemLabels, state.NoopSender)
 
-		states := st.GetStatesForRuleUID(rule.OrgID, rule.UID)
+		states := st.GetStatesForRuleUID(context.Background(), rule.OrgID, rule.UID)
 		require.Len(t, states, 1)
 		state := states[0]
 		require.NotNil(t, state.Values)
@@ -1673,6 +1775,171 @@ func TestProcessEvalResults(t *testing.T) {
 	})
 }
 
+func TestProcessEvalResultsDependsOn(t *testing.T) {
+	cfg := state.ManagerCfg{
+		Metrics:                 metrics.NewNGAlert(prometheus.NewPedanticRegistry()).GetStateMetrics(),
+		InstanceStore:           &state.FakeInstanceStore{},
+		Images:                  &state.NotAvailableImageService{},
+		Clock:                   clock.NewMock(),
+		Historian:               &state.FakeHistorian{},
+		Tracer:                  tracing.InitializeTracerForTest(),
+		Log:                     log.New("ngalert.state.manager"),
+		MaxStateSaveConcurrency: 1,
+	}
+	st := state.NewManager(cfg, state.NewNoopPersister())
+
+	gen := models.RuleGen
+	upstream := gen.With(gen.WithOrgID(1), gen.WithFor(0)).GenerateRef()
+	rule := gen.With(gen.WithOrgID(1), gen.WithFor(0), gen.WithKeepFiringFor(0), gen.WithDependsOn(upstream.UID)).GenerateRef()
+
+	evaluate := func(r *models.AlertRule, s eval.State, at time.Time) (state.StateTransitions, state.StateTransitions) {
+		var sent state.StateTransitions
+		results := eval.Results{{State: s, Instance: data.Labels{}, EvaluatedAt: at}}
+		transitions := st.ProcessEvalResults(context.Background(), at, r, results, nil, func(_ context.Context, toSend state.StateTransitions) {
+			sent = toSend
+		})
+		return transitions, sent
+	}
+
+	t1 := time.Unix(0, 0)
+	_, sent := evaluate(upstream, eval.Alerting, t1)
+	require.Len(t, sent, 1)
+
+	transitions, sent := evaluate(rule, eval.Alerting, t1)
+	require.Len(t, transitions, 1)
+	require.Equal(t, eval.Alerting, transitions[0].State.State)
+	require.Equal(t, models.StateReasonInhibited, transitions[0].StateReason)
+	require.Empty(t, sent, "notifications should be inhibited while the upstream rule is firing")
+
+	t2 := t1.Add(time.Minute)
+	_, _ = evaluate(upstream, eval.Normal, t2)
+	transitions, sent = evaluate(rule, eval.Alerting, t2)
+	require.Empty(t, transitions[0].StateReason)
+	require.Len(t, sent, 1, "notifications should be sent once the upstream rule is resolved")
+}
+
+func TestProcessEvalResultsDependsOnRuleOfAnotherInstance(t *testing.T) {
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, 1)
+
+	const mainOrgID int64 = 1
+	upstream := tests.CreateTestAlertRule(t, ctx, dbstore, 600, mainOrgID)
+	cfg := state.ManagerCfg{
+		Metrics:                 metrics.NewNGAlert(prometheus.NewPedanticRegistry()).GetStateMetrics(),
+		InstanceStore:           dbstore,
+		Images:                  &state.NotAvailableImageService{},
+		Clock:                   clock.NewMock(),
+		Historian:               &state.FakeHistorian{},
+		Tracer:                  tracing.InitializeTracerForTest(),
+		Log:                     log.New("ngalert.state.manager"),
+		MaxStateSaveConcurrency: 1,
+	}
+	// the state of the upstream rule is not in the cache of the manager, as if it was evaluated by another instance.
+	st := state.NewManager(cfg, state.NewNoopPersister())
+
+	gen := models.RuleGen
+	rule := gen.With(gen.WithOrgID(mainOrgID), gen.WithFor(0), gen.WithKeepFiringFor(0), gen.WithDependsOn(upstream.UID)).GenerateRef()
+	evaluate := func(at time.Time) (state.StateTransitions, state.StateTransitions) {
+		var sent state.StateTransitions
+		results := eval.Results{{State: eval.Alerting, Instance: data.Labels{}, EvaluatedAt: at}}
+		transitions := st.ProcessEvalResults(ctx, at, rule, results, nil, func(_ context.Context, toSend state.StateTransitions) {
+			sent = toSend
+		})
+		return transitions, sent
+	}
+
+	labels := models.InstanceLabels{"test": "upstream"}
+	_, hash, _ := labels.StringAndHash()
+	instance := models.AlertInstance{
+		AlertInstanceKey: models.AlertInstanceKey{
+			RuleOrgID:  upstream.OrgID,
+			RuleUID:    upstream.UID,
+			LabelsHash: hash,
+		},
+		Labels:            labels,
+		CurrentState:      models.InstanceStateFiring,
+		LastEvalTime:      time.Unix(0, 0),
+		CurrentStateSince: time.Unix(0, 0),
+		CurrentStateEnd:   time.Unix(0, 0).Add(time.Hour),
+	}
+	require.NoError(t, dbstore.SaveAlertInstance(ctx, instance))
+
+	t1 := time.Unix(0, 0)
+	transitions, sent := evaluate(t1)
+	require.Equal(t, models.StateReasonInhibited, transitions[0].StateReason)
+	require.Empty(t, sent, "notifications should be inhibited while the upstream rule is firing")
+
+	instance.CurrentState = models.InstanceStateNormal
+	require.NoError(t, dbstore.SaveAlertInstance(ctx, instance))
+
+	transitions, sent = evaluate(t1.Add(time.Minute))
+	require.Empty(t, transitions[0].StateReason)
+	require.Len(t, sent, 1, "notifications should be sent once the upstream rule is resolved")
+}
+
+type fakeMaintenanceWindows struct {
+	title string
+	start time.Time
+	end   time.Time
+}
+
+func (f fakeMaintenanceWindows) MaintenanceWindow(_ int64, lbls data.Labels, t time.Time) (string, bool) {
+	if lbls["service"] != "db" || t.Before(f.start) || !t.Before(f.end) {
+		return "", false
+	}
+	return f.title, true
+}
+
+func TestProcessEvalResultsMaintenanceWindows(t *testing.T) {
+	t1 := time.Unix(0, 0)
+	cfg := state.ManagerCfg{
+		Metrics:                 metrics.NewNGAlert(prometheus.NewPedanticRegistry()).GetStateMetrics(),
+		InstanceStore:           &state.FakeInstanceStore{},
+		Images:                  &state.NotAvailableImageService{},
+		Clock:                   clock.NewMock(),
+		Historian:               &state.FakeHistorian{},
+		MaintenanceWindows:      fakeMaintenanceWindows{title: "Database upgrades", start: t1, end: t1.Add(time.Hour)},
+		Tracer:                  tracing.InitializeTracerForTest(),
+		Log:                     log.New("ngalert.state.manager"),
+		MaxStateSaveConcurrency: 1,
+	}
+	st := state.NewManager(cfg, state.NewNoopPersister())
+
+	gen := models.RuleGen
+	rule := gen.With(gen.WithOrgID(1), gen.WithFor(0), gen.WithKeepFiringFor(0)).GenerateRef()
+
+	evaluate := func(s eval.State, at time.Time) (state.StateTransitions, state.StateTransitions) {
+		var sent state.StateTransitions
+		results := eval.Results{
+			{State: s, Instance: data.Labels{"service": "db"}, EvaluatedAt: at},
+			{State: s, Instance: data.Labels{"service": "web"}, EvaluatedAt: at},
+		}
+		transitions := st.ProcessEvalResults(context.Background(), at, rule, results, nil, func(_ context.Context, toSend state.StateTransitions) {
+			sent = toSend
+		})
+		return transitions, sent
+	}
+
+	byService := func(transitions state.StateTransitions) map[string]state.StateTransition {
+		result := make(map[string]state.StateTransition, len(transitions))
+		for _, tr := range transitions {
+			result[tr.Labels["service"]] = tr
+		}
+		return result
+	}
+
+	transitions, sent := evaluate(eval.Alerting, t1)
+	require.Len(t, transitions, 2)
+	services := byService(transitions)
+	require.Equal(t, models.StateReasonMaintenanceWindow("Database upgrades"), services["db"].StateReason)
+	require.Equal(t, "Alerting (Silenced by maintenance window Database upgrades)", services["db"].Formatted())
+	require.Empty(t, services["web"].StateReason)
+	require.Len(t, sent, 2, "alerts should be sent to the Alertmanager where the silence applies")
+
+	transitions, _ = evaluate(eval.Alerting, t1.Add(time.Hour))
+	require.Empty(t, byService(transitions)["db"].StateReason, "the reason should be removed after the occurrence")
+}
+
 func printAllAnnotations(annos map[int64]annotations.Item) string {
 	b := strings.Builder{}
 	b.WriteRune('[')
@@ -1692,6 +1959,47 @@ func printAllAnnotations(annos map[int64]annotations.Item) string {
 	return b.String()
 }
 
+func TestProcessEvalResultsSendsEvents(t *testing.T) {
+	events := &fakeEventSender{}
+	cfg := state.ManagerCfg{
+		Metrics:                 metrics.NewNGAlert(prometheus.NewPedanticRegistry()).GetStateMetrics(),
+		InstanceStore:           &state.FakeInstanceStore{},
+		Images:                  &state.NotAvailableImageService{},
+		Clock:                   clock.NewMock(),
+		Historian:               &state.FakeHistorian{},
+		Events:                  events,
+		Tracer:                  tracing.InitializeTracerForTest(),
+		Log:                     log.New("ngalert.state.manager"),
+		MaxStateSaveConcurrency: 1,
+	}
+	st := state.NewManager(cfg, state.NewNoopPersister())
+	rule := models.RuleGen.With(models.RuleGen.WithOrgID(1), models.RuleGen.WithFor(time.Minute)).GenerateRef()
+
+	t1 := time.Unix(0, 0)
+	results := eval.Results{{State: eval.Alerting, Instance: data.Labels{}, EvaluatedAt: t1}}
+	st.ProcessEvalResults(context.Background(), t1, rule, results, nil, nil)
+
+	t2 := t1.Add(10 * time.Second)
+	results = eval.Results{{State: eval.Normal, Instance: data.Labels{}, EvaluatedAt: t2}}
+	st.ProcessEvalResults(context.Background(), t2, rule, results, nil, nil)
+
+	// Transitions from Pending to Normal are never sent to the Alertmanager, but are sent as events.
+	require.Len(t, events.rules, 2)
+	require.Equal(t, rule.UID, events.rules[1].UID)
+	require.Equal(t, eval.Pending, events.transitions[1][0].PreviousState)
+	require.Equal(t, eval.Normal, events.transitions[1][0].State.State)
+}
+
+type fakeEventSender struct {
+	rules       []history_model.RuleMeta
+	transitions [][]state.StateTransition
+}
+
+func (f *fakeEventSender) SendEvents(_ context.Context, rule history_model.RuleMeta, transitions []state.StateTransition) {
+	f.rules = append(f.rules, rule)
+	f.transitions = append(f.transitions, transitions)
+}
+
 func TestStaleResultsHandler(t *testing.T) {
 	evaluationTime := time.Now().Truncate(time.Second).UTC() // Truncate to the second since we don't store sub-second precision.
 	interval := time.Minute
@@ -1814,7 +2122,7 @@ func TestStaleResultsHandler(t *testing.T) {
 		}
 		st := state.NewManager(cfg, state.NewNoopPersister())
 		st.Warm(ctx, dbstore)
-		existingStatesForRule := st.GetStatesForRuleUID(rule.OrgID, rule.UID)
+		existingStatesForRule := st.GetStatesForRuleUID(context.Background(), rule.OrgID, rule.UID)
 
 		// We have loaded the expected number of entries from the db
 		assert.Equal(t, tc.startingStateCount, len(existingStatesForRule))
@@ -1836,7 +2144,7 @@ func TestStaleResultsHandler(t *testing.T) {
 				assert.Equal(t, s, cachedState)
 			}
 		}
-		existingStatesForRule = st.GetStatesForRuleUID(rule.OrgID, rule.UID)
+		existingStatesForRule = st.GetStatesForRuleUID(context.Background(), rule.OrgID, rule.UID)
 
 		// The expected number of state entries remains after results are processed
 		assert.Equal(t, tc.finalStateCount, len(existingStatesForRule))
@@ -1923,7 +2231,7 @@ func TestStaleResults(t *testing.T) {
 	// Check that it returns just those state transitions that needs to be sent.
 	checkExpectedStateTransitions(t, statesToSend, map[data.Fingerprint]struct{}{state1: {}, state2: {}}) // Does not contain the Normal state3.
 
-	currentStates := st.GetStatesForRuleUID(rule.OrgID, rule.UID)
+	currentStates := st.GetStatesForRuleUID(context.Background(), rule.OrgID, rule.UID)
 	statesMap := checkExpectedStates(t, currentStates, initStates)
 	require.Equal(t, eval.Alerting, statesMap[state2].State) // make sure the state is alerting because we need it to be resolved later
 
@@ -1956,7 +2264,7 @@ func TestStaleResults(t *testing.T) {
 	})
 
 	t.Run("should remove stale states from cache", func(t *testing.T) {
-		currentStates = st.GetStatesForRuleUID(rule.OrgID, rule.UID)
+		currentStates = st.GetStatesForRuleUID(context.Background(), rule.OrgID, rule.UID)
 		checkExpectedStates(t, currentStates, map[data.Fingerprint]struct{}{
 			getCacheID(t, rule, results[0]): {},
 		})
@@ -2076,7 +2384,7 @@ func TestDeleteStateByRuleUID(t *testing.T) {
 			st.Warm(ctx, dbstore)
 			q := &models.ListAlertInstancesQuery{RuleOrgID: rule.OrgID, RuleUID: rule.UID}
 			alerts, _ := dbstore.ListAlertInstances(ctx, q)
-			existingStatesForRule := st.GetStatesForRuleUID(rule.OrgID, rule.UID)
+			existingStatesForRule := st.GetStatesForRuleUID(context.Background(), rule.OrgID, rule.UID)
 
 			// We have loaded the expected number of entries from the db
 			assert.Equal(t, tc.startingStateCacheCount, len(existingStatesForRule))
@@ -2108,7 +2416,7 @@ func TestDeleteStateByRuleUID(t *testing.T) {
 
 			q = &models.ListAlertInstancesQuery{RuleOrgID: rule.OrgID, RuleUID: rule.UID}
 			alertInstances, _ := dbstore.ListAlertInstances(ctx, q)
-			existingStatesForRule = st.GetStatesForRuleUID(rule.OrgID, rule.UID)
+			existingStatesForRule = st.GetStatesForRuleUID(context.Background(), rule.OrgID, rule.UID)
 
 			// The expected number of state entries remains after states are deleted
 			assert.Equal(t, tc.finalStateCacheCount, len(existingStatesForRule))
@@ -2217,7 +2525,7 @@ func TestResetStateByRuleUID(t *testing.T) {
 			st.Warm(ctx, dbstore)
 			q := &models.ListAlertInstancesQuery{RuleOrgID: rule.OrgID, RuleUID: rule.UID}
 			alerts, _ := dbstore.ListAlertInstances(ctx, q)
-			existingStatesForRule := st.GetStatesForRuleUID(rule.OrgID, rule.UID)
+			existingStatesForRule := st.GetStatesForRuleUID(context.Background(), rule.OrgID, rule.UID)
 
 			// We have loaded the expected number of entries from the db
 			assert.Equal(t, tc.startingStateCacheCount, len(existingStatesForRule))
@@ -2252,7 +2560,7 @@ func TestResetStateByRuleUID(t *testing.T) {
 
 			q = &models.ListAlertInstancesQuery{RuleOrgID: rule.OrgID, RuleUID: rule.UID}
 			alertInstances, _ := dbstore.ListAlertInstances(ctx, q)
-			existingStatesForRule = st.GetStatesForRuleUID(rule.OrgID, rule.UID)
+			existingStatesForRule = st.GetStatesForRuleUID(context.Background(), rule.OrgID, rule.UID)
 
 			// The expected number of state entries remains after states are deleted
 			assert.Equal(t, tc.finalStateCacheCount, len(existingStatesForRule))
@@ -2261,6 +2569,137 @@ func TestResetStateByRuleUID(t *testing.T) {
 	}
 }
 
+func TestReleaseAndLoadRuleState(t *testing.T) {
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, 1)
+
+	const mainOrgID int64 = 1
+	rule := tests.CreateTestAlertRule(t, ctx, dbstore, 60, mainOrgID)
+
+	labels := models.InstanceLabels{"test1": "testValue1"}
+	_, hash, _ := labels.StringAndHash()
+	require.NoError(t, dbstore.SaveAlertInstance(ctx, models.AlertInstance{
+		AlertInstanceKey: models.AlertInstanceKey{
+			RuleOrgID:  rule.OrgID,
+			RuleUID:    rule.UID,
+			LabelsHash: hash,
+		},
+		CurrentState: models.InstanceStateNormal,
+		Labels:       labels,
+	}))
+
+	newManager := func() *state.Manager {
+		cfg := state.ManagerCfg{
+			Metrics:                 metrics.NewNGAlert(prometheus.NewPedanticRegistry()).GetStateMetrics(),
+			InstanceStore:           dbstore,
+			Images:                  &state.NoopImageService{},
+			Clock:                   clock.NewMock(),
+			Historian:               &state.FakeHistorian{},
+			Tracer:                  tracing.InitializeTracerForTest(),
+			Log:                     log.New("ngalert.state.manager"),
+			MaxStateSaveConcurrency: 1,
+		}
+		return state.NewManager(cfg, state.NewSyncStatePersisiter(log.New("ngalert.state.manager.persist"), cfg))
+	}
+
+	previousOwner := newManager()
+	previousOwner.Warm(ctx, dbstore)
+	newOwner := newManager()
+	newOwner.Warm(ctx, dbstore)
+
+	// The previous owner evaluated the rule since it was loaded by the new owner.
+	states := previousOwner.GetStatesForRuleUID(context.Background(), rule.OrgID, rule.UID)
+	require.Len(t, states, 1)
+	states[0].State = eval.Alerting
+	states[0].StateReason = "updated"
+
+	previousOwner.ReleaseRule(ctx, rule.GetKey())
+	require.Empty(t, previousOwner.GetStatesForRuleUID(context.Background(), rule.OrgID, rule.UID))
+
+	newOwner.LoadRuleState(ctx, rule)
+	states = newOwner.GetStatesForRuleUID(context.Background(), rule.OrgID, rule.UID)
+	require.Len(t, states, 1)
+	require.Equal(t, eval.Alerting, states[0].State)
+	require.Equal(t, "updated", states[0].StateReason)
+	require.Equal(t, data.Labels(labels), states[0].Labels)
+
+	newOwner.ForgetRule(rule.GetKey())
+	require.Empty(t, newOwner.GetStatesForRuleUID(context.Background(), rule.OrgID, rule.UID))
+	alertInstances, err := dbstore.ListAlertInstances(ctx, &models.ListAlertInstancesQuery{RuleOrgID: rule.OrgID, RuleUID: rule.UID})
+	require.NoError(t, err)
+	require.Len(t, alertInstances, 1)
+}
+
+func TestGetStatesOfRulesEvaluatedByOtherInstances(t *testing.T) {
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, 1)
+
+	const mainOrgID int64 = 1
+	remote := tests.CreateTestAlertRule(t, ctx, dbstore, 60, mainOrgID)
+	local := tests.CreateTestAlertRule(t, ctx, dbstore, 60, mainOrgID)
+
+	labels := models.InstanceLabels{"test1": "testValue1"}
+	_, hash, _ := labels.StringAndHash()
+	require.NoError(t, dbstore.SaveAlertInstance(ctx, models.AlertInstance{
+		AlertInstanceKey: models.AlertInstanceKey{
+			RuleOrgID:  remote.OrgID,
+			RuleUID:    remote.UID,
+			LabelsHash: hash,
+		},
+		CurrentState: models.InstanceStateFiring,
+		Labels:       labels,
+	}))
+
+	newManager := func(sharded bool) *state.Manager {
+		cfg := state.ManagerCfg{
+			Metrics:                 metrics.NewNGAlert(prometheus.NewPedanticRegistry()).GetStateMetrics(),
+			InstanceStore:           dbstore,
+			Images:                  &state.NoopImageService{},
+			Clock:                   clock.NewMock(),
+			Historian:               &state.FakeHistorian{},
+			Tracer:                  tracing.InitializeTracerForTest(),
+			Log:                     log.New("ngalert.state.manager"),
+			MaxStateSaveConcurrency: 1,
+		}
+		if sharded {
+			cfg.ShardedRuleReader = dbstore
+		}
+		st := state.NewManager(cfg, state.NewNoopPersister())
+		// Only the state of the rule that is evaluated by this instance is in the cache.
+		st.Put([]*state.State{setCacheID(&state.State{
+			OrgID:        local.OrgID,
+			AlertRuleUID: local.UID,
+			Labels:       data.Labels{"test2": "testValue2"},
+			State:        eval.Alerting,
+		})})
+		return st
+	}
+
+	t.Run("reads the states of rules evaluated by other instances from the database", func(t *testing.T) {
+		st := newManager(true)
+
+		states := st.GetStatesForRuleUID(ctx, remote.OrgID, remote.UID)
+		require.Len(t, states, 1)
+		require.Equal(t, eval.Alerting, states[0].State)
+		require.Equal(t, data.Labels(labels), states[0].Labels)
+		require.Equal(t, remote.Annotations, states[0].Annotations)
+
+		all := st.GetAll(ctx, mainOrgID)
+		require.Len(t, all, 2)
+		uids := []string{all[0].AlertRuleUID, all[1].AlertRuleUID}
+		require.ElementsMatch(t, []string{remote.UID, local.UID}, uids)
+	})
+
+	t.Run("reads only the cache when rules are not sharded", func(t *testing.T) {
+		st := newManager(false)
+
+		require.Empty(t, st.GetStatesForRuleUID(ctx, remote.OrgID, remote.UID))
+		all := st.GetAll(ctx, mainOrgID)
+		require.Len(t, all, 1)
+		require.Equal(t, local.UID, all[0].AlertRuleUID)
+	})
+}
+
 func setCacheID(s *state.State) *state.State {
 	if s.CacheID != 0 {
 		return s
@@ -2288,3 +2727,124 @@ func mergeLabels(a, b data.Labels) data.Labels {
 	}
 	return result
 }
+
+type fakeAcknowledgements struct {
+	acks       map[models.AlertInstanceKey]models.AlertInstanceAcknowledgement
+	suppressed []data.Labels
+	released   []models.AlertInstanceKey
+}
+
+func (f *fakeAcknowledgements) Acknowledgement(key models.AlertInstanceKey) (models.AlertInstanceAcknowledgement, bool) {
+	ack, ok := f.acks[key]
+	return ack, ok
+}
+
+func (f *fakeAcknowledgements) Suppress(_ context.Context, _ models.AlertInstanceKey, lbls data.Labels) error {
+	f.suppressed = append(f.suppressed, lbls)
+	return nil
+}
+
+func (f *fakeAcknowledgements) Release(_ context.Context, keys ...models.AlertInstanceKey) error {
+	f.released = append(f.released, keys...)
+	return nil
+}
+
+func TestProcessEvalResultsAcknowledgements(t *testing.T) {
+	t1 := time.Unix(0, 0).UTC()
+	acks := &fakeAcknowledgements{acks: map[models.AlertInstanceKey]models.AlertInstanceAcknowledgement{}}
+	cfg := state.ManagerCfg{
+		Metrics:                 metrics.NewNGAlert(prometheus.NewPedanticRegistry()).GetStateMetrics(),
+		InstanceStore:           &state.FakeInstanceStore{},
+		Images:                  &state.NotAvailableImageService{},
+		Clock:                   clock.NewMock(),
+		Historian:               &state.FakeHistorian{},
+		Acknowledgements:        acks,
+		Tracer:                  tracing.InitializeTracerForTest(),
+		Log:                     log.New("ngalert.state.manager"),
+		MaxStateSaveConcurrency: 1,
+	}
+	st := state.NewManager(cfg, state.NewNoopPersister())
+	st.ResendDelay = time.Hour
+
+	gen := models.RuleGen
+	rule := gen.With(gen.WithOrgID(1), gen.WithFor(0), gen.WithKeepFiringFor(0)).GenerateRef()
+
+	evaluate := func(s eval.State, at time.Time) state.StateTransitions {
+		var sent state.StateTransitions
+		results := eval.Results{{State: s, Instance: data.Labels{"service": "db"}, EvaluatedAt: at}}
+		st.ProcessEvalResults(context.Background(), at, rule, results, nil, func(_ context.Context, toSend state.StateTransitions) {
+			sent = toSend
+		})
+		return sent
+	}
+
+	sent := evaluate(eval.Alerting, t1)
+	require.Len(t, sent, 1)
+	require.Nil(t, sent[0].Acknowledgement)
+	require.Empty(t, acks.suppressed)
+	require.Len(t, state.StateToPostableAlerts(sent[0], nil), 1)
+	firing := state.StateToPostableAlert(sent[0], nil)
+	key, err := sent[0].GetAlertInstanceKey()
+	require.NoError(t, err)
+	acknowledgedLabels := amv2.LabelSet{models.AcknowledgedLabel: "true"}
+	for k, v := range firing.Labels {
+		acknowledgedLabels[k] = v
+	}
+
+	ack := models.AlertInstanceAcknowledgement{
+		AlertInstanceKey: key,
+		Acknowledged:     true,
+		AcknowledgedBy:   "admin",
+		AcknowledgedAt:   t1,
+		Assignee:         "editor",
+	}
+	acks.acks[key] = ack
+	t2 := t1.Add(time.Minute)
+	sent = evaluate(eval.Alerting, t2)
+	require.Len(t, sent, 1, "the acknowledged alert should be sent without waiting for the resend delay")
+	alerts := state.StateToPostableAlerts(sent[0], nil)
+	require.Len(t, alerts, 2)
+	// The Alertmanager fingerprints alerts by their labels, so the acknowledged alert must have the labels of the
+	// firing alert and must not end, or the Alertmanager would notify that the firing alert is resolved.
+	require.Equal(t, firing.Labels, alerts[0].Labels)
+	require.True(t, time.Time(alerts[0].EndsAt).After(t2), "the acknowledged alert should not be resolved")
+	require.Equal(t, "admin", alerts[0].Annotations[models.AcknowledgedByAnnotation])
+	require.Equal(t, "editor", alerts[0].Annotations[models.AssigneeAnnotation])
+	require.Equal(t, []data.Labels{sent[0].Labels}, acks.suppressed)
+	// The copy of the alert is what notification policies can match on.
+	require.Equal(t, acknowledgedLabels, alerts[1].Labels)
+	require.True(t, time.Time(alerts[1].EndsAt).After(t2))
+	require.Equal(t, "admin", alerts[1].Annotations[models.AcknowledgedByAnnotation])
+	require.Empty(t, acks.released)
+
+	t3 := t2.Add(time.Minute)
+	require.Empty(t, evaluate(eval.Alerting, t3), "the acknowledged alert should be resent after the resend delay")
+
+	delete(acks.acks, key)
+	t4 := t3.Add(time.Minute)
+	sent = evaluate(eval.Alerting, t4)
+	require.Len(t, sent, 1, "the copy of the unacknowledged alert should be resolved without waiting for the resend delay")
+	alerts = state.StateToPostableAlerts(sent[0], nil)
+	require.Len(t, alerts, 2)
+	require.Equal(t, firing.Labels, alerts[0].Labels)
+	require.True(t, time.Time(alerts[0].EndsAt).After(t4))
+	require.Equal(t, acknowledgedLabels, alerts[1].Labels)
+	require.Equal(t, t4, time.Time(alerts[1].EndsAt))
+
+	acks.acks[key] = ack
+	t5 := t4.Add(time.Minute)
+	sent = evaluate(eval.Alerting, t5)
+	require.Len(t, sent, 1)
+	require.Len(t, state.StateToPostableAlerts(sent[0], nil), 2)
+
+	t6 := t5.Add(time.Minute)
+	sent = evaluate(eval.Normal, t6)
+	require.Len(t, sent, 1)
+	alerts = state.StateToPostableAlerts(sent[0], nil)
+	require.Len(t, alerts, 2)
+	require.Equal(t, firing.Labels, alerts[0].Labels)
+	require.Equal(t, t6, time.Time(alerts[0].EndsAt))
+	require.Equal(t, acknowledgedLabels, alerts[1].Labels)
+	require.Equal(t, t6, time.Time(alerts[1].EndsAt))
+	require.Equal(t, []models.AlertInstanceKey{key}, acks.released)
+}
@@ -2,6 +2,9 @@ package state
 
 import (
 	"context"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	history_model "github.com/grafana/grafana/pkg/services/ngalert/state/historian/model"
@@ -30,6 +33,29 @@ type Historian interface {
 	Record(ctx context.Context, rule history_model.RuleMeta, states []StateTransition) <-chan error
 }
 
+// EventSender sends the state transitions of alert instances to external systems, independently of notifications.
+type EventSender interface {
+	// SendEvents must not block the evaluation of the rule.
+	SendEvents(ctx context.Context, rule history_model.RuleMeta, states []StateTransition)
+}
+
+// MaintenanceWindows tells which maintenance window silences an alert.
+type MaintenanceWindows interface {
+	// MaintenanceWindow returns the title of a maintenance window that is in progress at t and silences alerts with
+	// the given labels.
+	MaintenanceWindow(orgID int64, lbls data.Labels, t time.Time) (string, bool)
+}
+
+// Acknowledgements tells who handles an alert instance.
+type Acknowledgements interface {
+	// Acknowledgement returns the acknowledgement of the alert instance with the given key, if there is one.
+	Acknowledgement(key models.AlertInstanceKey) (models.AlertInstanceAcknowledgement, bool)
+	// Suppress silences the notifications of the acknowledged alert instance with the given key and labels.
+	Suppress(ctx context.Context, key models.AlertInstanceKey, lbls data.Labels) error
+	// Release deletes the acknowledgements of resolved alert instances and expires their silences.
+	Release(ctx context.Context, keys ...models.AlertInstanceKey) error
+}
+
 // ImageCapturer captures images.
 //
 //go:generate mockgen -destination=image_mock.go -package=state github.com/grafana/grafana/pkg/services/ngalert/state ImageCapturer
@@ -68,11 +68,26 @@ type State struct {
 	// ResolvedAt is set when the state is first resolved. That is to say, when the state first transitions
 	// from Alerting, NoData, or Error to Normal. It is reset to zero when the state transitions from Normal
 	// to any other state.
-	ResolvedAt           *time.Time
+	ResolvedAt *time.Time
+	// KeepFiringSince is set when the condition of an Alerting state first clears and the rule has a
+	// keep_firing_for duration. The state is kept Alerting until keep_firing_for has elapsed since then.
+	// It is reset when the condition fires again.
+	KeepFiringSince      *time.Time
 	LastSentAt           *time.Time
 	LastEvaluationString string
 	LastEvaluationTime   time.Time
 	EvaluationDuration   time.Duration
+
+	// Acknowledgement is the acknowledgement of the alert instance at the latest evaluation, if any. It is not
+	// persisted with the state.
+	Acknowledgement *models.AlertInstanceAcknowledgement
+	// Acknowledged is true if the alert instance was firing and acknowledged at the latest evaluation. The acknowledged
+	// copy of its alert is then sent along with the alert. It is not persisted with the state.
+	Acknowledged bool
+	// AcknowledgementChanged is true if Acknowledged changed at the latest evaluation. The state is then sent, so that
+	// the acknowledged copy of its alert is routed, or resolved if the alert instance was resolved or unacknowledged.
+	// It is not persisted with the state.
+	AcknowledgementChanged bool
 }
 
 func (a *State) GetRuleKey() models.AlertRuleKey {
@@ -263,7 +278,29 @@ func NewEvaluationValues(m map[string]eval.NumberValueCapture) map[string]float6
 	return result
 }
 
-func resultNormal(state *State, _ *models.AlertRule, result eval.Result, logger log.Logger, reason string) {
+func resultNormal(state *State, rule *models.AlertRule, result eval.Result, logger log.Logger, reason string) {
+	if state.State == eval.Alerting && rule.KeepFiringFor > 0 {
+		if state.KeepFiringSince == nil {
+			state.KeepFiringSince = &result.EvaluatedAt
+		}
+		// If the keep_firing_for duration has not been observed then the state should be kept Alerting
+		if result.EvaluatedAt.Sub(*state.KeepFiringSince) < rule.KeepFiringFor {
+			prevEndsAt := state.EndsAt
+			state.Maintain(rule.IntervalSeconds, result.EvaluatedAt)
+			logger.Debug("Keeping state firing",
+				"state",
+				state.State,
+				"keep_firing_since",
+				*state.KeepFiringSince,
+				"previous_ends_at",
+				prevEndsAt,
+				"next_ends_at",
+				state.EndsAt)
+			return
+		}
+	}
+	state.KeepFiringSince = nil
+
 	if state.State == eval.Normal {
 		logger.Debug("Keeping state", "state", state.State)
 	} else {
@@ -283,6 +320,8 @@ func resultNormal(state *State, _ *models.AlertRule, result eval.Result, logger
 }
 
 func resultAlerting(state *State, rule *models.AlertRule, result eval.Result, logger log.Logger, reason string) {
+	state.KeepFiringSince = nil
+
 	switch state.State {
 	case eval.Alerting:
 		prevEndsAt := state.EndsAt
@@ -472,6 +511,11 @@ func (a *State) NeedsSending(resendDelay time.Duration, resolvedRetention time.D
 		return false
 	}
 
+	// The acknowledged copy of the alert is sent or resolved as soon as the alert is acknowledged or unacknowledged.
+	if a.AcknowledgementChanged {
+		return true
+	}
+
 	// We should send a notification if the state has been resolved since the last notification.
 	if a.ResolvedAt != nil && (a.LastSentAt == nil || a.ResolvedAt.After(*a.LastSentAt)) {
 		return true
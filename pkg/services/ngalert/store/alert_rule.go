@@ -71,6 +71,12 @@ func (st DBstore) DeleteAlertRulesByUID(ctx context.Context, orgID int64, ruleUI
 			return err
 		}
 		logger.Debug("Deleted alert instances", "count", rows)
+
+		rows, err = sess.Table("alert_rule_owner").Where("rule_org_id = ?", orgID).In("rule_uid", ruleUID).Delete(ngmodels.AlertRuleOwner{})
+		if err != nil {
+			return err
+		}
+		logger.Debug("Deleted alert rule owners", "count", rows)
 		return nil
 	})
 }
@@ -113,6 +119,34 @@ func (st DBstore) GetAlertRuleByUID(ctx context.Context, query *ngmodels.GetAler
 	return result, err
 }
 
+// GetAlertRuleVersions returns the versions of the alert rule with the given UID, most recent first.
+func (st DBstore) GetAlertRuleVersions(ctx context.Context, orgID int64, ruleUID string) ([]*ngmodels.AlertRuleVersion, error) {
+	var result []*ngmodels.AlertRuleVersion
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Table("alert_rule_version").Where("rule_org_id = ? AND rule_uid = ?", orgID, ruleUID).Desc("version").Find(&result)
+	})
+	return result, err
+}
+
+// GetAlertRuleVersion returns the version of the alert rule with the given UID.
+func (st DBstore) GetAlertRuleVersion(ctx context.Context, orgID int64, ruleUID string, version int64) (*ngmodels.AlertRuleVersion, error) {
+	var result ngmodels.AlertRuleVersion
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		has, err := sess.Table("alert_rule_version").Where("rule_org_id = ? AND rule_uid = ? AND version = ?", orgID, ruleUID, version).Get(&result)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return ngmodels.ErrAlertRuleVersionNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
 // GetAlertRulesGroupByRuleUID is a handler for retrieving a group of alert rules from that database by UID and organisation ID of one of rules that belong to that group.
 func (st DBstore) GetAlertRulesGroupByRuleUID(ctx context.Context, query *ngmodels.GetAlertRulesGroupByRuleUIDQuery) (result []*ngmodels.AlertRule, err error) {
 	err = st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
@@ -175,6 +209,7 @@ func (st DBstore) InsertAlertRules(ctx context.Context, rules []ngmodels.AlertRu
 				RuleOrgID:            r.OrgID,
 				RuleNamespaceUID:     r.NamespaceUID,
 				RuleGroup:            r.RuleGroup,
+				RuleGroupIndex:       r.RuleGroupIndex,
 				ParentVersion:        0,
 				Version:              r.Version,
 				Created:              r.Updated,
@@ -185,10 +220,16 @@ func (st DBstore) InsertAlertRules(ctx context.Context, rules []ngmodels.AlertRu
 				NoDataState:          r.NoDataState,
 				ExecErrState:         r.ExecErrState,
 				For:                  r.For,
+				KeepFiringFor:        r.KeepFiringFor,
 				Annotations:          r.Annotations,
 				Labels:               r.Labels,
 				Record:               r.Record,
+				IsPaused:             r.IsPaused,
 				NotificationSettings: r.NotificationSettings,
+				DependsOn:            r.DependsOn,
+				UpdatedBy:            r.UpdatedBy,
+				Message:              r.Message,
+				HasPause:             true,
 			})
 		}
 		if len(newRules) > 0 {
@@ -267,9 +308,15 @@ func (st DBstore) UpdateAlertRules(ctx context.Context, rules []ngmodels.UpdateR
 				ExecErrState:         r.New.ExecErrState,
 				Record:               r.New.Record,
 				For:                  r.New.For,
+				KeepFiringFor:        r.New.KeepFiringFor,
 				Annotations:          r.New.Annotations,
 				Labels:               r.New.Labels,
+				IsPaused:             r.New.IsPaused,
 				NotificationSettings: r.New.NotificationSettings,
+				DependsOn:            r.New.DependsOn,
+				UpdatedBy:            r.New.UpdatedBy,
+				Message:              r.New.Message,
+				HasPause:             true,
 			})
 		}
 		if len(ruleVersions) > 0 {
@@ -825,6 +872,8 @@ func (st DBstore) RenameReceiverInNotificationSettings(ctx context.Context, orgI
 	updates := make([]ngmodels.UpdateRule, 0, len(rules))
 	for _, rule := range rules {
 		r := ngmodels.CopyRule(rule)
+		// The rule is changed by Grafana rather than by its last author.
+		r.UpdatedBy = ""
 		for idx := range r.NotificationSettings {
 			if r.NotificationSettings[idx].Receiver == oldReceiver {
 				r.NotificationSettings[idx].Receiver = newReceiver
@@ -892,6 +941,8 @@ func (st DBstore) RenameTimeIntervalInNotificationSettings(
 		}
 
 		r := ngmodels.CopyRule(rule)
+		// The rule is changed by Grafana rather than by its last author.
+		r.UpdatedBy = ""
 		for idx := range r.NotificationSettings {
 			for mtIdx := range r.NotificationSettings[idx].MuteTimeIntervals {
 				if r.NotificationSettings[idx].MuteTimeIntervals[mtIdx] == oldTimeInterval {
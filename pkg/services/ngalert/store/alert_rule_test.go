@@ -1395,3 +1395,84 @@ func setupFolderService(t *testing.T, sqlStore db.ReplDB, cfg *setting.Cfg, feat
 
 	return testutil.SetupFolderService(t, cfg, sqlStore.DB(), dashboardStore, folderStore, inProcBus, features, &actest.FakeAccessControl{ExpectedEvaluate: true})
 }
+
+func TestIntegrationAlertRuleVersions(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	orgID := int64(1)
+	sqlStore := db.InitTestReplDB(t)
+	cfg := setting.NewCfg()
+	cfg.UnifiedAlerting.BaseInterval = 1 * time.Second
+	store := &DBstore{
+		SQLStore:      sqlStore,
+		FolderService: setupFolderService(t, sqlStore, cfg, featuremgmt.WithFeatures()),
+		Logger:        log.New("test-dbstore"),
+		Cfg:           cfg.UnifiedAlerting,
+	}
+	gen := models.RuleGen.With(
+		models.RuleGen.WithOrgID(orgID),
+		models.RuleGen.WithIntervalMatching(store.Cfg.BaseInterval),
+	)
+
+	rule := gen.Generate()
+	rule.UpdatedBy = "admin"
+	rule.Message = "create rule"
+	ids, err := store.InsertAlertRules(context.Background(), []models.AlertRule{rule})
+	require.NoError(t, err)
+	ruleUID := ids[0].UID
+
+	existing, err := store.GetAlertRuleByUID(context.Background(), &models.GetAlertRuleByUIDQuery{OrgID: orgID, UID: ruleUID})
+	require.NoError(t, err)
+	require.Equal(t, "admin", existing.UpdatedBy)
+
+	updated := models.CopyRule(existing)
+	updated.Title = util.GenerateShortUID()
+	updated.IsPaused = true
+	updated.UpdatedBy = "editor"
+	updated.Message = "rename rule"
+	require.NoError(t, store.UpdateAlertRules(context.Background(), []models.UpdateRule{{Existing: existing, New: *updated}}))
+
+	versions, err := store.GetAlertRuleVersions(context.Background(), orgID, ruleUID)
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	require.Equal(t, int64(2), versions[0].Version)
+	require.Equal(t, int64(1), versions[0].ParentVersion)
+	require.Equal(t, updated.Title, versions[0].Title)
+	require.True(t, versions[0].IsPaused)
+	require.True(t, versions[0].HasPause)
+	require.Equal(t, "editor", versions[0].UpdatedBy)
+	require.Equal(t, "rename rule", versions[0].Message)
+	require.Equal(t, int64(1), versions[1].Version)
+	require.Equal(t, "admin", versions[1].UpdatedBy)
+	require.Equal(t, "create rule", versions[1].Message)
+
+	version, err := store.GetAlertRuleVersion(context.Background(), orgID, ruleUID, 1)
+	require.NoError(t, err)
+	require.Equal(t, rule.Title, version.Title)
+
+	_, err = store.GetAlertRuleVersion(context.Background(), orgID, ruleUID, 3)
+	require.ErrorIs(t, err, models.ErrAlertRuleVersionNotFound)
+
+	t.Run("should read rules and versions changed before authors were stored", func(t *testing.T) {
+		err := sqlStore.WithDbSession(context.Background(), func(sess *db.Session) error {
+			if _, err := sess.Exec("UPDATE alert_rule SET updated_by = NULL"); err != nil {
+				return err
+			}
+			_, err := sess.Exec("UPDATE alert_rule_version SET updated_by = NULL, message = NULL, has_pause = ?", false)
+			return err
+		})
+		require.NoError(t, err)
+
+		existing, err := store.GetAlertRuleByUID(context.Background(), &models.GetAlertRuleByUIDQuery{OrgID: orgID, UID: ruleUID})
+		require.NoError(t, err)
+		require.Empty(t, existing.UpdatedBy)
+		versions, err := store.GetAlertRuleVersions(context.Background(), orgID, ruleUID)
+		require.NoError(t, err)
+		require.Len(t, versions, 2)
+		require.Empty(t, versions[0].UpdatedBy)
+		require.Empty(t, versions[0].Message)
+		require.False(t, versions[0].HasPause)
+	})
+}
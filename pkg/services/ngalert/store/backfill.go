@@ -0,0 +1,157 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// InsertRecordingRuleBackfill saves a new recording rule backfill and sets its ID. It returns ErrBackfillConflict if
+// the time range overlaps a running backfill of the same rule.
+func (st DBstore) InsertRecordingRuleBackfill(ctx context.Context, backfill *models.RecordingRuleBackfill) error {
+	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if err := checkBackfillOverlap(sess, backfill); err != nil {
+			return err
+		}
+		if _, err := sess.Insert(backfill); err != nil {
+			return fmt.Errorf("failed to insert backfill: %w", err)
+		}
+		return nil
+	})
+}
+
+// UpdateRecordingRuleBackfill saves the progress and the state of a running recording rule backfill. If owner is not
+// empty, the backfill must be owned by it. It returns ErrBackfillStateChanged if the backfill is no longer running,
+// for example because it was cancelled on another Grafana instance, or if it was taken over by another instance.
+func (st DBstore) UpdateRecordingRuleBackfill(ctx context.Context, backfill *models.RecordingRuleBackfill, owner string) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		q := sess.ID(backfill.ID).Where("state = ?", models.BackfillStateRunning)
+		if owner != "" {
+			q = q.And("owner = ?", owner)
+		}
+		affected, err := q.Cols("next_evaluation", "state", "error_message", "updated", "heartbeat").Update(backfill)
+		if err != nil {
+			return fmt.Errorf("failed to update backfill: %w", err)
+		}
+		if affected == 0 {
+			return models.ErrBackfillStateChanged
+		}
+		return nil
+	})
+}
+
+// ResumeRecordingRuleBackfill moves a failed or cancelled backfill back to the running state and makes its owner run
+// it. It returns ErrBackfillStateChanged if the backfill is not failed or cancelled anymore, and ErrBackfillConflict
+// if the time range overlaps another running backfill of the same rule.
+func (st DBstore) ResumeRecordingRuleBackfill(ctx context.Context, backfill *models.RecordingRuleBackfill) error {
+	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if err := checkBackfillOverlap(sess, backfill); err != nil {
+			return err
+		}
+		affected, err := sess.ID(backfill.ID).
+			In("state", models.BackfillStateFailed, models.BackfillStateCancelled).
+			Cols("state", "error_message", "updated", "owner", "heartbeat").
+			Update(backfill)
+		if err != nil {
+			return fmt.Errorf("failed to update backfill: %w", err)
+		}
+		if affected == 0 {
+			return models.ErrBackfillStateChanged
+		}
+		return nil
+	})
+}
+
+// ClaimRecordingRuleBackfill makes the owner of the backfill run it, if it is running and has no owner, is already
+// owned by it, or if the heartbeat of its current owner is older than staleBefore. It returns false if the backfill
+// is run by another Grafana instance or is not running anymore.
+func (st DBstore) ClaimRecordingRuleBackfill(ctx context.Context, backfill *models.RecordingRuleBackfill, staleBefore time.Time) (bool, error) {
+	var claimed bool
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.ID(backfill.ID).
+			Where("state = ?", models.BackfillStateRunning).
+			And("(owner = '' OR owner = ? OR heartbeat IS NULL OR heartbeat < ?)", backfill.Owner, staleBefore.UTC()).
+			Cols("owner", "heartbeat").
+			Update(backfill)
+		if err != nil {
+			return fmt.Errorf("failed to claim backfill: %w", err)
+		}
+		claimed = affected > 0
+		return nil
+	})
+	return claimed, err
+}
+
+// HeartbeatRecordingRuleBackfills reports that the owner still runs its backfills.
+func (st DBstore) HeartbeatRecordingRuleBackfills(ctx context.Context, owner string, at time.Time) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Where("owner = ? AND state = ?", owner, models.BackfillStateRunning).
+			Cols("heartbeat").
+			Update(&models.RecordingRuleBackfill{Heartbeat: at})
+		if err != nil {
+			return fmt.Errorf("failed to update the heartbeat of backfills: %w", err)
+		}
+		return nil
+	})
+}
+
+func checkBackfillOverlap(sess *db.Session, backfill *models.RecordingRuleBackfill) error {
+	running := make([]*models.RecordingRuleBackfill, 0)
+	err := sess.Where("org_id = ? AND rule_uid = ? AND state = ? AND id <> ?", backfill.OrgID, backfill.RuleUID, models.BackfillStateRunning, backfill.ID).
+		Find(&running)
+	if err != nil {
+		return fmt.Errorf("failed to get running backfills: %w", err)
+	}
+	for _, other := range running {
+		if backfill.Overlaps(*other) {
+			return fmt.Errorf("%w: %s", models.ErrBackfillConflict, other.UID)
+		}
+	}
+	return nil
+}
+
+// GetRecordingRuleBackfill returns the backfill with the given UID. It returns ErrBackfillNotFound if it does not exist.
+func (st DBstore) GetRecordingRuleBackfill(ctx context.Context, orgID int64, uid string) (*models.RecordingRuleBackfill, error) {
+	var backfill models.RecordingRuleBackfill
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Get(&backfill)
+		if err != nil {
+			return fmt.Errorf("failed to get backfill: %w", err)
+		}
+		if !exists {
+			return models.ErrBackfillNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &backfill, nil
+}
+
+// ListRecordingRuleBackfills returns the backfills of the given rule, most recent first.
+func (st DBstore) ListRecordingRuleBackfills(ctx context.Context, orgID int64, ruleUID string) ([]*models.RecordingRuleBackfill, error) {
+	result := make([]*models.RecordingRuleBackfill, 0)
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ? AND rule_uid = ?", orgID, ruleUID).Desc("created", "id").Find(&result)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backfills: %w", err)
+	}
+	return result, nil
+}
+
+// ListRecordingRuleBackfillsByState returns the backfills of all organizations that are in the given state.
+func (st DBstore) ListRecordingRuleBackfillsByState(ctx context.Context, state models.BackfillState) ([]*models.RecordingRuleBackfill, error) {
+	result := make([]*models.RecordingRuleBackfill, 0)
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("state = ?", state).Asc("id").Find(&result)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backfills: %w", err)
+	}
+	return result, nil
+}
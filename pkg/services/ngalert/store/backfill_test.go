@@ -0,0 +1,144 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestIntegrationRecordingRuleBackfills(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	// our database schema uses second precision for timestamps
+	now := time.Now().UTC().Truncate(time.Second)
+	newBackfill := func(uid string, orgID int64, ruleUID string, from, created time.Time) *models.RecordingRuleBackfill {
+		return &models.RecordingRuleBackfill{
+			UID:       uid,
+			OrgID:     orgID,
+			RuleUID:   ruleUID,
+			From:      from,
+			To:        from.Add(30 * time.Minute),
+			Next:      from,
+			State:     models.BackfillStateRunning,
+			Created:   created,
+			Updated:   created,
+			Owner:     "instance-a",
+			Heartbeat: created,
+		}
+	}
+
+	first := newBackfill("first", 1, "rule", now.Add(-2*time.Hour), now.Add(-time.Minute))
+	second := newBackfill("second", 1, "rule", now.Add(-time.Hour), now)
+	other := newBackfill("other", 2, "rule", now.Add(-time.Hour), now)
+	for _, b := range []*models.RecordingRuleBackfill{first, second, other} {
+		require.NoError(t, dbstore.InsertRecordingRuleBackfill(ctx, b))
+		require.NotZero(t, b.ID)
+	}
+
+	t.Run("gets backfill by UID in the organization", func(t *testing.T) {
+		result, err := dbstore.GetRecordingRuleBackfill(ctx, 1, "first")
+		require.NoError(t, err)
+		require.Equal(t, "rule", result.RuleUID)
+		require.Equal(t, "instance-a", result.Owner)
+		require.True(t, first.From.Equal(result.From))
+
+		_, err = dbstore.GetRecordingRuleBackfill(ctx, 2, "first")
+		require.ErrorIs(t, err, models.ErrBackfillNotFound)
+	})
+
+	t.Run("lists backfills of the rule, most recent first", func(t *testing.T) {
+		result, err := dbstore.ListRecordingRuleBackfills(ctx, 1, "rule")
+		require.NoError(t, err)
+		require.Len(t, result, 2)
+		require.Equal(t, "second", result[0].UID)
+		require.Equal(t, "first", result[1].UID)
+	})
+
+	t.Run("rejects backfills that overlap a running backfill of the rule", func(t *testing.T) {
+		overlapping := newBackfill("overlapping", 1, "rule", now.Add(-100*time.Minute), now)
+		require.ErrorIs(t, dbstore.InsertRecordingRuleBackfill(ctx, overlapping), models.ErrBackfillConflict)
+
+		_, err := dbstore.GetRecordingRuleBackfill(ctx, 1, "overlapping")
+		require.ErrorIs(t, err, models.ErrBackfillNotFound)
+	})
+
+	t.Run("updates progress and state of running backfills of the owner", func(t *testing.T) {
+		first.Next = now.Add(-90 * time.Minute)
+		first.State = models.BackfillStateFailed
+		first.Error = "write failed"
+		require.ErrorIs(t, dbstore.UpdateRecordingRuleBackfill(ctx, first, "instance-b"), models.ErrBackfillStateChanged)
+		require.NoError(t, dbstore.UpdateRecordingRuleBackfill(ctx, first, "instance-a"))
+
+		result, err := dbstore.GetRecordingRuleBackfill(ctx, 1, "first")
+		require.NoError(t, err)
+		require.Equal(t, models.BackfillStateFailed, result.State)
+		require.Equal(t, "write failed", result.Error)
+		require.True(t, first.Next.Equal(result.Next))
+
+		// The backfill is not running anymore.
+		first.State = models.BackfillStateCancelled
+		require.ErrorIs(t, dbstore.UpdateRecordingRuleBackfill(ctx, first, ""), models.ErrBackfillStateChanged)
+	})
+
+	t.Run("resumes failed backfills", func(t *testing.T) {
+		first.State = models.BackfillStateRunning
+		first.Error = ""
+		first.Owner = "instance-b"
+		require.NoError(t, dbstore.ResumeRecordingRuleBackfill(ctx, first))
+
+		result, err := dbstore.GetRecordingRuleBackfill(ctx, 1, "first")
+		require.NoError(t, err)
+		require.Equal(t, models.BackfillStateRunning, result.State)
+		require.Equal(t, "instance-b", result.Owner)
+		require.Empty(t, result.Error)
+
+		require.ErrorIs(t, dbstore.ResumeRecordingRuleBackfill(ctx, first), models.ErrBackfillStateChanged)
+	})
+
+	t.Run("claims backfills whose owner stopped", func(t *testing.T) {
+		claim := *first
+		claim.Owner = "instance-c"
+		claim.Heartbeat = now
+		claimed, err := dbstore.ClaimRecordingRuleBackfill(ctx, &claim, first.Heartbeat.Add(-time.Minute))
+		require.NoError(t, err)
+		require.False(t, claimed)
+
+		claimed, err = dbstore.ClaimRecordingRuleBackfill(ctx, &claim, first.Heartbeat.Add(time.Minute))
+		require.NoError(t, err)
+		require.True(t, claimed)
+
+		require.NoError(t, dbstore.HeartbeatRecordingRuleBackfills(ctx, "instance-c", now.Add(time.Minute)))
+		result, err := dbstore.GetRecordingRuleBackfill(ctx, 1, "first")
+		require.NoError(t, err)
+		require.Equal(t, "instance-c", result.Owner)
+		require.True(t, now.Add(time.Minute).Equal(result.Heartbeat))
+	})
+
+	t.Run("lists backfills by state in all organizations", func(t *testing.T) {
+		second.State = models.BackfillStateCompleted
+		require.NoError(t, dbstore.UpdateRecordingRuleBackfill(ctx, second, ""))
+
+		result, err := dbstore.ListRecordingRuleBackfillsByState(ctx, models.BackfillStateRunning)
+		require.NoError(t, err)
+		uids := make([]string, 0, len(result))
+		for _, b := range result {
+			uids = append(uids, b.UID)
+		}
+		require.Equal(t, []string{"first", "other"}, uids)
+	})
+
+	t.Run("returns state changed when updating unknown backfill", func(t *testing.T) {
+		err := dbstore.UpdateRecordingRuleBackfill(ctx, &models.RecordingRuleBackfill{ID: 1000, State: models.BackfillStateCancelled}, "")
+		require.ErrorIs(t, err, models.ErrBackfillStateChanged)
+	})
+}
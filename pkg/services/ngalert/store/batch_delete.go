@@ -0,0 +1,45 @@
+package store
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+// DeleteInBatches deletes the rows of the table that match the condition, at most batchSize rows at a time, so that
+// deleting a large number of rows does not lock the table for long. The table must have an id column, and bean is
+// the model of the table. It returns the number of deleted rows.
+func DeleteInBatches(ctx context.Context, store db.DB, table string, bean any, batchSize int, condition string, args ...any) (int64, error) {
+	var total int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+		var affected int64
+		err := store.WithDbSession(ctx, func(sess *db.Session) error {
+			var ids []int64
+			err := sess.Table(table).
+				Where(condition, args...).
+				Asc("id").
+				Limit(batchSize).
+				Cols("id").
+				Find(&ids)
+			if err != nil || len(ids) == 0 {
+				return err
+			}
+			idArgs := make([]any, 0, len(ids))
+			for _, id := range ids {
+				idArgs = append(idArgs, id)
+			}
+			affected, err = sess.Table(table).In("id", idArgs...).Delete(bean)
+			return err
+		})
+		total += affected
+		if err != nil {
+			return total, err
+		}
+		if affected == 0 {
+			return total, nil
+		}
+	}
+}
@@ -3,13 +3,15 @@ package store
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/util/cmputil"
 )
 
 // AlertRuleFieldsToIgnoreInDiff contains fields that are ignored when calculating the RuleDelta.Diff.
-var AlertRuleFieldsToIgnoreInDiff = [...]string{"ID", "Version", "Updated"}
+var AlertRuleFieldsToIgnoreInDiff = [...]string{"ID", "Version", "Updated", "UpdatedBy", "Message"}
 
 type RuleDelta struct {
 	Existing *models.AlertRule
@@ -52,6 +54,125 @@ func (c *GroupDelta) NewOrUpdatedNotificationSettings() []models.NotificationSet
 	return settings
 }
 
+// NewOrUpdatedDependencies returns the UIDs of the rules that new rules, or rules with updated dependencies, depend on.
+func (c *GroupDelta) NewOrUpdatedDependencies() []string {
+	var uids []string
+	for _, rule := range c.New {
+		uids = append(uids, rule.DependsOn...)
+	}
+	for _, delta := range c.Update {
+		if len(delta.New.DependsOn) == 0 {
+			continue
+		}
+		d := delta.Diff.GetDiffsForField("DependsOn")
+		if len(d) == 0 {
+			continue
+		}
+		uids = append(uids, delta.New.DependsOn...)
+	}
+	slices.Sort(uids)
+	return slices.Compact(uids)
+}
+
+// NewOrUpdatedRecordingTargets returns the UIDs of the data sources that new recording rules, or recording rules with
+// updated targets, write to.
+func (c *GroupDelta) NewOrUpdatedRecordingTargets() []string {
+	var uids []string
+	for _, rule := range c.New {
+		if rule.Record != nil && rule.Record.TargetDatasourceUID != "" {
+			uids = append(uids, rule.Record.TargetDatasourceUID)
+		}
+	}
+	for _, delta := range c.Update {
+		if delta.New.Record == nil || delta.New.Record.TargetDatasourceUID == "" {
+			continue
+		}
+		if delta.Existing.Record != nil && delta.Existing.Record.TargetDatasourceUID == delta.New.Record.TargetDatasourceUID {
+			continue
+		}
+		uids = append(uids, delta.New.Record.TargetDatasourceUID)
+	}
+	slices.Sort(uids)
+	return slices.Compact(uids)
+}
+
+// ValidateDependencies checks that the rules that new or updated rules depend on exist in the organization, that the
+// dependencies do not form a cycle, and that the rules deleted by the changes are not dependencies of other rules.
+func ValidateDependencies(ctx context.Context, ruleReader RuleReader, delta *GroupDelta) error {
+	uids := delta.NewOrUpdatedDependencies()
+	if len(uids) == 0 && len(delta.Delete) == 0 {
+		return nil
+	}
+	existing, err := ruleReader.ListAlertRules(ctx, &models.ListAlertRulesQuery{
+		OrgID: delta.GroupKey.OrgID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to fetch the rules of the organization: %w", err)
+	}
+	// dependencies contains the dependencies of all rules of the organization once the changes are applied.
+	dependencies := make(map[string][]string, len(existing)+len(delta.New))
+	for _, rule := range existing {
+		dependencies[rule.UID] = rule.DependsOn
+	}
+	for _, rule := range delta.New {
+		dependencies[rule.UID] = rule.DependsOn
+	}
+	for _, upd := range delta.Update {
+		dependencies[upd.New.UID] = upd.New.DependsOn
+	}
+	for _, rule := range delta.Delete {
+		delete(dependencies, rule.UID)
+	}
+
+	for _, uid := range uids {
+		if _, ok := dependencies[uid]; !ok {
+			return fmt.Errorf("%w: rule %s in field `depends_on` does not exist", models.ErrAlertRuleFailedValidation, uid)
+		}
+	}
+	for _, rule := range delta.Delete {
+		for uid, deps := range dependencies {
+			if slices.Contains(deps, rule.UID) {
+				return fmt.Errorf("%w: rule %s cannot be deleted because rule %s depends on it", models.ErrAlertRuleFailedValidation, rule.UID, uid)
+			}
+		}
+	}
+
+	const (
+		visiting = iota + 1
+		visited
+	)
+	marks := make(map[string]int, len(dependencies))
+	var visit func(uid string, path []string) error
+	visit = func(uid string, path []string) error {
+		path = append(path, uid)
+		switch marks[uid] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("%w: field `depends_on` forms a cycle %s", models.ErrAlertRuleFailedValidation, strings.Join(path, " -> "))
+		}
+		marks[uid] = visiting
+		for _, dep := range dependencies[uid] {
+			if err := visit(dep, path); err != nil {
+				return err
+			}
+		}
+		marks[uid] = visited
+		return nil
+	}
+	for _, rule := range delta.New {
+		if err := visit(rule.UID, nil); err != nil {
+			return err
+		}
+	}
+	for _, upd := range delta.Update {
+		if err := visit(upd.New.UID, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 type RuleReader interface {
 	ListAlertRules(ctx context.Context, query *models.ListAlertRulesQuery) (models.RulesGroup, error)
 	GetAlertRulesGroupByRuleUID(ctx context.Context, query *models.GetAlertRulesGroupByRuleUIDQuery) ([]*models.AlertRule, error)
@@ -684,3 +684,81 @@ func groupByUID(t *testing.T, list []*models.AlertRule) map[string]*models.Alert
 	}
 	return result
 }
+
+func TestValidateDependencies(t *testing.T) {
+	orgID := int64(rand.Int31())
+	gen := models.RuleGen.With(models.RuleGen.WithOrgID(orgID))
+	existing := gen.GenerateRef()
+	ruleStore := fakes.NewRuleStore(t)
+	ruleStore.PutRule(context.Background(), existing)
+
+	delta := func(rules ...*models.AlertRule) *GroupDelta {
+		return &GroupDelta{GroupKey: models.GenerateGroupKey(orgID), New: rules}
+	}
+
+	t.Run("accepts dependencies on existing rules", func(t *testing.T) {
+		rule := gen.With(gen.WithDependsOn(existing.UID)).GenerateRef()
+		require.NoError(t, ValidateDependencies(context.Background(), ruleStore, delta(rule)))
+	})
+
+	t.Run("accepts dependencies on rules created by the same changes", func(t *testing.T) {
+		upstream := gen.GenerateRef()
+		rule := gen.With(gen.WithDependsOn(upstream.UID)).GenerateRef()
+		require.NoError(t, ValidateDependencies(context.Background(), ruleStore, delta(upstream, rule)))
+	})
+
+	t.Run("rejects dependencies on unknown rules", func(t *testing.T) {
+		rule := gen.With(gen.WithDependsOn(existing.UID, "unknown")).GenerateRef()
+		err := ValidateDependencies(context.Background(), ruleStore, delta(rule))
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+		require.ErrorContains(t, err, "unknown")
+	})
+
+	t.Run("rejects dependencies on rules of another organization", func(t *testing.T) {
+		rule := gen.With(gen.WithDependsOn(existing.UID)).GenerateRef()
+		d := delta(rule)
+		d.GroupKey.OrgID = orgID + 1
+		require.ErrorIs(t, ValidateDependencies(context.Background(), ruleStore, d), models.ErrAlertRuleFailedValidation)
+	})
+
+	t.Run("rejects dependencies on deleted rules", func(t *testing.T) {
+		rule := gen.With(gen.WithDependsOn(existing.UID)).GenerateRef()
+		d := delta(rule)
+		d.Delete = []*models.AlertRule{existing}
+		require.ErrorIs(t, ValidateDependencies(context.Background(), ruleStore, d), models.ErrAlertRuleFailedValidation)
+	})
+
+	t.Run("rejects deleting rules other rules depend on", func(t *testing.T) {
+		ruleStore := fakes.NewRuleStore(t)
+		upstream := gen.GenerateRef()
+		downstream := gen.With(gen.WithDependsOn(upstream.UID)).GenerateRef()
+		ruleStore.PutRule(context.Background(), upstream, downstream)
+
+		d := &GroupDelta{GroupKey: upstream.GetGroupKey(), Delete: []*models.AlertRule{upstream}}
+		err := ValidateDependencies(context.Background(), ruleStore, d)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+		require.ErrorContains(t, err, downstream.UID)
+
+		d.Delete = append(d.Delete, downstream)
+		require.NoError(t, ValidateDependencies(context.Background(), ruleStore, d), "deleting both rules should be allowed")
+	})
+
+	t.Run("rejects cycles", func(t *testing.T) {
+		ruleStore := fakes.NewRuleStore(t)
+		first := gen.GenerateRef()
+		second := gen.With(gen.WithDependsOn(first.UID)).GenerateRef()
+		third := gen.With(gen.WithDependsOn(second.UID)).GenerateRef()
+		ruleStore.PutRule(context.Background(), first, second, third)
+
+		updated := models.CopyRule(first)
+		updated.DependsOn = []string{third.UID}
+		d := &GroupDelta{GroupKey: first.GetGroupKey(), Update: []RuleDelta{{
+			Existing: first,
+			New:      updated,
+			Diff:     first.Diff(updated, AlertRuleFieldsToIgnoreInDiff[:]...),
+		}}}
+		err := ValidateDependencies(context.Background(), ruleStore, d)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+		require.ErrorContains(t, err, "cycle")
+	})
+}
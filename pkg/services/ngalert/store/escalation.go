@@ -0,0 +1,233 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// ListEscalationChains returns the escalation chains of the organization ordered by title.
+func (st DBstore) ListEscalationChains(ctx context.Context, orgID int64) ([]*models.EscalationChain, error) {
+	result := make([]*models.EscalationChain, 0)
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ?", orgID).Asc("title").Find(&result)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list escalation chains: %w", err)
+	}
+	return result, nil
+}
+
+// ListAllEscalationChains returns the escalation chains of all organizations.
+func (st DBstore) ListAllEscalationChains(ctx context.Context) ([]*models.EscalationChain, error) {
+	result := make([]*models.EscalationChain, 0)
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Asc("id").Find(&result)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list escalation chains: %w", err)
+	}
+	return result, nil
+}
+
+// GetEscalationChain returns the escalation chain with the given UID. It returns ErrEscalationChainNotFound if it does not exist.
+func (st DBstore) GetEscalationChain(ctx context.Context, orgID int64, uid string) (*models.EscalationChain, error) {
+	var chain models.EscalationChain
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Get(&chain)
+		if err != nil {
+			return fmt.Errorf("failed to get escalation chain: %w", err)
+		}
+		if !exists {
+			return models.ErrEscalationChainNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &chain, nil
+}
+
+// InsertEscalationChain saves a new escalation chain. A UID is generated if the chain does not have one.
+func (st DBstore) InsertEscalationChain(ctx context.Context, chain *models.EscalationChain) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		if chain.UID == "" {
+			chain.UID = util.GenerateShortUID()
+		}
+		chain.Updated = time.Now()
+		if _, err := sess.Insert(chain); err != nil {
+			return fmt.Errorf("failed to insert escalation chain: %w", err)
+		}
+		return nil
+	})
+}
+
+// UpdateEscalationChain saves the escalation chain with the given UID. The alert groups that are escalated by the
+// chain keep their position in it.
+func (st DBstore) UpdateEscalationChain(ctx context.Context, chain *models.EscalationChain) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		chain.Updated = time.Now()
+		affected, err := sess.Where("org_id = ? AND uid = ?", chain.OrgID, chain.UID).
+			Cols("title", "receiver", "steps", "updated").
+			Update(chain)
+		if err != nil {
+			return fmt.Errorf("failed to update escalation chain: %w", err)
+		}
+		if affected == 0 {
+			return models.ErrEscalationChainNotFound
+		}
+		return nil
+	})
+}
+
+// DeleteEscalationChain deletes the escalation chain with the given UID. It returns ErrEscalationChainNotFound if it does not exist.
+func (st DBstore) DeleteEscalationChain(ctx context.Context, orgID int64, uid string) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Delete(&models.EscalationChain{})
+		if err != nil {
+			return fmt.Errorf("failed to delete escalation chain: %w", err)
+		}
+		if affected == 0 {
+			return models.ErrEscalationChainNotFound
+		}
+		return nil
+	})
+}
+
+// ListAlertGroupEscalations returns the escalations of the alert groups of the organization, or of all
+// organizations if orgID is 0.
+func (st DBstore) ListAlertGroupEscalations(ctx context.Context, orgID int64) ([]*models.AlertGroupEscalation, error) {
+	result := make([]*models.AlertGroupEscalation, 0)
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		if orgID != 0 {
+			sess.Where("org_id = ?", orgID)
+		}
+		return sess.Asc("id").Find(&result)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list alert group escalations: %w", err)
+	}
+	return result, nil
+}
+
+// GetAlertGroupEscalation returns the escalation of the alert group with the given key. It returns
+// ErrAlertGroupEscalationNotFound if it does not exist.
+func (st DBstore) GetAlertGroupEscalation(ctx context.Context, orgID int64, groupKey string) (*models.AlertGroupEscalation, error) {
+	var escalation models.AlertGroupEscalation
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where("org_id = ? AND group_key = ?", orgID, groupKey).Get(&escalation)
+		if err != nil {
+			return fmt.Errorf("failed to get alert group escalation: %w", err)
+		}
+		if !exists {
+			return models.ErrAlertGroupEscalationNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &escalation, nil
+}
+
+// InsertAlertGroupEscalation saves the escalation of an alert group unless the group already has one, and returns
+// whether it did. Only the first of several Grafana instances that see a new alert group saves its escalation.
+func (st DBstore) InsertAlertGroupEscalation(ctx context.Context, escalation *models.AlertGroupEscalation) (bool, error) {
+	var inserted bool
+	err := st.SQLStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where("org_id = ? AND group_key = ?", escalation.OrgID, escalation.GroupKey).Exist(&models.AlertGroupEscalation{})
+		if err != nil {
+			return fmt.Errorf("failed to get alert group escalation: %w", err)
+		}
+		if exists {
+			return nil
+		}
+		if _, err := sess.Insert(escalation); err != nil {
+			return fmt.Errorf("failed to insert alert group escalation: %w", err)
+		}
+		inserted = true
+		return nil
+	})
+	if err != nil {
+		// The unique index rejects the escalation if another instance saved one in the meantime.
+		if _, getErr := st.GetAlertGroupEscalation(ctx, escalation.OrgID, escalation.GroupKey); getErr == nil {
+			return false, nil
+		}
+		return false, err
+	}
+	return inserted, nil
+}
+
+// ClaimAlertGroupEscalationStep advances the escalation to the given step if it is still at the previous one and
+// the alert group is not acknowledged, and returns whether it did. Only the first of several Grafana instances that
+// claim the same step gets true, so that the contact point of each step is notified once.
+func (st DBstore) ClaimAlertGroupEscalationStep(ctx context.Context, id int64, previous, step int, at time.Time) (bool, error) {
+	var claimed bool
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.Where("id = ? AND step = ? AND acknowledged = ?", id, previous, false).
+			Cols("step", "escalated_at").
+			Update(&models.AlertGroupEscalation{Step: step, EscalatedAt: at})
+		if err != nil {
+			return fmt.Errorf("failed to claim alert group escalation step: %w", err)
+		}
+		claimed = affected == 1
+		return nil
+	})
+	return claimed, err
+}
+
+// SetAlertGroupAcknowledgement saves whether the alert group of the escalation is acknowledged, by whom and why.
+// It returns ErrAlertGroupEscalationNotFound if the escalation does not exist.
+func (st DBstore) SetAlertGroupAcknowledgement(ctx context.Context, escalation *models.AlertGroupEscalation) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.Where("org_id = ? AND group_key = ?", escalation.OrgID, escalation.GroupKey).
+			Cols("acknowledged", "acknowledged_by", "acknowledged_at", "comment").
+			Update(escalation)
+		if err != nil {
+			return fmt.Errorf("failed to save alert group acknowledgement: %w", err)
+		}
+		if affected == 0 {
+			return models.ErrAlertGroupEscalationNotFound
+		}
+		return nil
+	})
+}
+
+// SetAlertGroupEscalationsSeen saves that the alert groups of the escalations with the given IDs were seen firing at
+// the given time.
+func (st DBstore) SetAlertGroupEscalationsSeen(ctx context.Context, at time.Time, ids ...int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.In("id", ids).
+			Cols("last_seen_at").
+			Update(&models.AlertGroupEscalation{LastSeenAt: at})
+		if err != nil {
+			return fmt.Errorf("failed to update alert group escalations: %w", err)
+		}
+		return nil
+	})
+}
+
+// DeleteAlertGroupEscalations deletes the escalations with the given IDs whose alert groups were not seen firing
+// since the given time. Escalations that another instance saw in the meantime are kept.
+func (st DBstore) DeleteAlertGroupEscalations(ctx context.Context, notSeenSince time.Time, ids ...int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.In("id", ids).
+			Where("last_seen_at < ? OR (last_seen_at IS NULL AND escalated_at < ?)", notSeenSince.UTC(), notSeenSince.UTC()).
+			Delete(&models.AlertGroupEscalation{})
+		if err != nil {
+			return fmt.Errorf("failed to delete alert group escalations: %w", err)
+		}
+		return nil
+	})
+}
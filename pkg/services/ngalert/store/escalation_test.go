@@ -0,0 +1,176 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestIntegrationEscalationChains(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	newChain := func(orgID int64, title string) *models.EscalationChain {
+		return &models.EscalationChain{
+			OrgID:    orgID,
+			Title:    title,
+			Receiver: "team",
+			Steps:    []models.EscalationStep{{Receiver: "on-call", Delay: 5 * time.Minute}},
+		}
+	}
+
+	second := newChain(1, "second")
+	first := newChain(1, "first")
+	other := newChain(2, "other")
+	for _, c := range []*models.EscalationChain{second, first, other} {
+		require.NoError(t, dbstore.InsertEscalationChain(ctx, c))
+		require.NotZero(t, c.ID)
+		require.NotEmpty(t, c.UID)
+	}
+
+	t.Run("lists escalation chains of the organization by title", func(t *testing.T) {
+		result, err := dbstore.ListEscalationChains(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, result, 2)
+		require.Equal(t, "first", result[0].Title)
+		require.Equal(t, "second", result[1].Title)
+		require.Equal(t, first.Steps, result[0].Steps)
+
+		all, err := dbstore.ListAllEscalationChains(ctx)
+		require.NoError(t, err)
+		require.Len(t, all, 3)
+	})
+
+	t.Run("gets escalation chain by UID in the organization", func(t *testing.T) {
+		result, err := dbstore.GetEscalationChain(ctx, 1, first.UID)
+		require.NoError(t, err)
+		require.Equal(t, "first", result.Title)
+
+		_, err = dbstore.GetEscalationChain(ctx, 2, first.UID)
+		require.ErrorIs(t, err, models.ErrEscalationChainNotFound)
+	})
+
+	t.Run("updates escalation chain", func(t *testing.T) {
+		updated := *first
+		updated.Steps = []models.EscalationStep{{Receiver: "manager", Delay: time.Hour}}
+		require.NoError(t, dbstore.UpdateEscalationChain(ctx, &updated))
+
+		result, err := dbstore.GetEscalationChain(ctx, 1, first.UID)
+		require.NoError(t, err)
+		require.Equal(t, updated.Steps, result.Steps)
+
+		missing := *first
+		missing.UID = "missing"
+		require.ErrorIs(t, dbstore.UpdateEscalationChain(ctx, &missing), models.ErrEscalationChainNotFound)
+	})
+
+	t.Run("deletes escalation chain", func(t *testing.T) {
+		require.NoError(t, dbstore.DeleteEscalationChain(ctx, 1, second.UID))
+		require.ErrorIs(t, dbstore.DeleteEscalationChain(ctx, 1, second.UID), models.ErrEscalationChainNotFound)
+		result, err := dbstore.ListEscalationChains(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+	})
+}
+
+func TestIntegrationAlertGroupEscalations(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	groupLabels := map[string]string{"alertname": "HighLatency"}
+	escalation := &models.AlertGroupEscalation{
+		OrgID:       1,
+		GroupKey:    models.AlertGroupKey("{}", "team", groupLabels),
+		Receiver:    "team",
+		GroupLabels: groupLabels,
+		ChainUID:    "ops",
+		StartedAt:   now,
+		EscalatedAt: now,
+		LastSeenAt:  now,
+	}
+
+	t.Run("only the first insert of an alert group succeeds", func(t *testing.T) {
+		inserted, err := dbstore.InsertAlertGroupEscalation(ctx, escalation)
+		require.NoError(t, err)
+		require.True(t, inserted)
+
+		duplicate := *escalation
+		duplicate.ID = 0
+		inserted, err = dbstore.InsertAlertGroupEscalation(ctx, &duplicate)
+		require.NoError(t, err)
+		require.False(t, inserted)
+
+		result, err := dbstore.ListAlertGroupEscalations(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+		require.Equal(t, groupLabels, result[0].GroupLabels)
+
+		result, err = dbstore.ListAlertGroupEscalations(ctx, 2)
+		require.NoError(t, err)
+		require.Empty(t, result)
+	})
+
+	t.Run("only one claim of a step succeeds", func(t *testing.T) {
+		claimed, err := dbstore.ClaimAlertGroupEscalationStep(ctx, escalation.ID, 0, 1, now.Add(time.Minute))
+		require.NoError(t, err)
+		require.True(t, claimed)
+
+		claimed, err = dbstore.ClaimAlertGroupEscalationStep(ctx, escalation.ID, 0, 1, now.Add(time.Minute))
+		require.NoError(t, err)
+		require.False(t, claimed)
+
+		result, err := dbstore.GetAlertGroupEscalation(ctx, 1, escalation.GroupKey)
+		require.NoError(t, err)
+		require.Equal(t, 1, result.Step)
+		require.True(t, now.Add(time.Minute).Equal(result.EscalatedAt))
+	})
+
+	t.Run("steps of acknowledged alert groups cannot be claimed", func(t *testing.T) {
+		require.NoError(t, dbstore.SetAlertGroupAcknowledgement(ctx, &models.AlertGroupEscalation{
+			OrgID:          1,
+			GroupKey:       escalation.GroupKey,
+			Acknowledged:   true,
+			AcknowledgedBy: "admin",
+			AcknowledgedAt: now,
+			Comment:        "looking into it",
+		}))
+		claimed, err := dbstore.ClaimAlertGroupEscalationStep(ctx, escalation.ID, 1, 2, now.Add(time.Minute))
+		require.NoError(t, err)
+		require.False(t, claimed)
+
+		result, err := dbstore.GetAlertGroupEscalation(ctx, 1, escalation.GroupKey)
+		require.NoError(t, err)
+		require.True(t, result.Acknowledged)
+		require.Equal(t, "admin", result.AcknowledgedBy)
+		require.Equal(t, "looking into it", result.Comment)
+
+		err = dbstore.SetAlertGroupAcknowledgement(ctx, &models.AlertGroupEscalation{OrgID: 1, GroupKey: "missing"})
+		require.ErrorIs(t, err, models.ErrAlertGroupEscalationNotFound)
+	})
+
+	t.Run("deletes escalations that were not seen since the given time", func(t *testing.T) {
+		require.NoError(t, dbstore.SetAlertGroupEscalationsSeen(ctx, now.Add(time.Hour), escalation.ID))
+		require.NoError(t, dbstore.DeleteAlertGroupEscalations(ctx, now.Add(time.Hour-time.Second), escalation.ID))
+		result, err := dbstore.GetAlertGroupEscalation(ctx, 1, escalation.GroupKey)
+		require.NoError(t, err)
+		require.True(t, now.Add(time.Hour).Equal(result.LastSeenAt))
+
+		require.NoError(t, dbstore.DeleteAlertGroupEscalations(ctx, now.Add(time.Hour+time.Second), escalation.ID))
+		_, err = dbstore.GetAlertGroupEscalation(ctx, 1, escalation.GroupKey)
+		require.ErrorIs(t, err, models.ErrAlertGroupEscalationNotFound)
+	})
+}
@@ -69,12 +69,13 @@ func (st DBstore) SaveAlertInstance(ctx context.Context, alertInstance models.Al
 			nullableTimeToUnix(alertInstance.ResolvedAt),
 			nullableTimeToUnix(alertInstance.LastSentAt),
 			alertInstance.ResultFingerprint,
+			nullableTimeToUnix(alertInstance.KeepFiringSince),
 		)
 
 		upsertSQL := st.SQLStore.GetDialect().UpsertSQL(
 			"alert_instance",
 			[]string{"rule_org_id", "rule_uid", "labels_hash"},
-			[]string{"rule_org_id", "rule_uid", "labels", "labels_hash", "current_state", "current_reason", "current_state_since", "current_state_end", "last_eval_time", "resolved_at", "last_sent_at", "result_fingerprint"})
+			[]string{"rule_org_id", "rule_uid", "labels", "labels_hash", "current_state", "current_reason", "current_state_since", "current_state_end", "last_eval_time", "resolved_at", "last_sent_at", "result_fingerprint", "keep_firing_since"})
 		_, err = sess.SQL(upsertSQL, params...).Query()
 		if err != nil {
 			return err
@@ -234,7 +235,7 @@ func (st DBstore) FullSync(ctx context.Context, instances []models.AlertInstance
 			}
 
 			_, err = sess.Exec(
-				"INSERT INTO alert_instance (rule_org_id, rule_uid, labels, labels_hash, current_state, current_reason, current_state_since, current_state_end, last_eval_time, resolved_at, last_sent_at) VALUES (?,?,?,?,?,?,?,?,?,?,?)",
+				"INSERT INTO alert_instance (rule_org_id, rule_uid, labels, labels_hash, current_state, current_reason, current_state_since, current_state_end, last_eval_time, resolved_at, last_sent_at, keep_firing_since) VALUES (?,?,?,?,?,?,?,?,?,?,?,?)",
 				alertInstance.RuleOrgID,
 				alertInstance.RuleUID,
 				labelTupleJSON,
@@ -246,6 +247,7 @@ func (st DBstore) FullSync(ctx context.Context, instances []models.AlertInstance
 				alertInstance.LastEvalTime.Unix(),
 				nullableTimeToUnix(alertInstance.ResolvedAt),
 				nullableTimeToUnix(alertInstance.LastSentAt),
+				nullableTimeToUnix(alertInstance.KeepFiringSince),
 			)
 			if err != nil {
 				return fmt.Errorf("failed to insert into alert_instance table: %w", err)
@@ -266,3 +268,93 @@ func nullableTimeToUnix(t *time.Time) *int64 {
 	unix := t.Unix()
 	return &unix
 }
+
+// ListAlertInstanceAcknowledgements returns the acknowledgements of the alert instances of all organizations.
+func (st DBstore) ListAlertInstanceAcknowledgements(ctx context.Context) ([]*models.AlertInstanceAcknowledgement, error) {
+	result := make([]*models.AlertInstanceAcknowledgement, 0)
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Find(&result)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list alert instance acknowledgements: %w", err)
+	}
+	return result, nil
+}
+
+// GetAlertInstanceAcknowledgement returns the acknowledgement of the alert instance with the given key. It returns
+// ErrAlertInstanceAcknowledgementNotFound if there is none.
+func (st DBstore) GetAlertInstanceAcknowledgement(ctx context.Context, key models.AlertInstanceKey) (*models.AlertInstanceAcknowledgement, error) {
+	var result models.AlertInstanceAcknowledgement
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where("rule_org_id = ? AND rule_uid = ? AND labels_hash = ?", key.RuleOrgID, key.RuleUID, key.LabelsHash).Get(&result)
+		if err != nil {
+			return fmt.Errorf("failed to get alert instance acknowledgement: %w", err)
+		}
+		if !exists {
+			return models.ErrAlertInstanceAcknowledgementNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SaveAlertInstanceAcknowledgement inserts or replaces the acknowledgement of an alert instance.
+func (st DBstore) SaveAlertInstanceAcknowledgement(ctx context.Context, ack models.AlertInstanceAcknowledgement) error {
+	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		key := ack.AlertInstanceKey
+		exists, err := sess.Where("rule_org_id = ? AND rule_uid = ? AND labels_hash = ?", key.RuleOrgID, key.RuleUID, key.LabelsHash).Exist(&models.AlertInstanceAcknowledgement{})
+		if err != nil {
+			return fmt.Errorf("failed to save alert instance acknowledgement: %w", err)
+		}
+		if exists {
+			_, err = sess.Where("rule_org_id = ? AND rule_uid = ? AND labels_hash = ?", key.RuleOrgID, key.RuleUID, key.LabelsHash).
+				Cols("acknowledged", "acknowledged_by", "acknowledged_at", "expires_at", "assignee", "comment", "updated", "updated_by", "silence_id").
+				Update(&ack)
+		} else {
+			_, err = sess.Insert(&ack)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to save alert instance acknowledgement: %w", err)
+		}
+		return nil
+	})
+}
+
+// SetAlertInstanceAcknowledgementSilence saves the silence of the acknowledged alert instance if it is still acknowledged
+// and has the previous silence, and returns whether it did. Only the first of several Grafana instances that silence
+// the same alert instance gets true, so that the others can expire their silences.
+func (st DBstore) SetAlertInstanceAcknowledgementSilence(ctx context.Context, key models.AlertInstanceKey, previous, silenceID string) (bool, error) {
+	var saved bool
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.Table(models.AlertInstanceAcknowledgement{}).
+			Where("rule_org_id = ? AND rule_uid = ? AND labels_hash = ? AND silence_id = ?", key.RuleOrgID, key.RuleUID, key.LabelsHash, previous).
+			And("acknowledged = ?", true).
+			Cols("silence_id").
+			Update(map[string]any{"silence_id": silenceID})
+		if err != nil {
+			return fmt.Errorf("failed to save silence of alert instance acknowledgement: %w", err)
+		}
+		saved = affected == 1
+		return nil
+	})
+	return saved, err
+}
+
+// DeleteAlertInstanceAcknowledgements deletes the acknowledgements of the alert instances with the given keys.
+func (st DBstore) DeleteAlertInstanceAcknowledgements(ctx context.Context, keys ...models.AlertInstanceKey) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		for _, key := range keys {
+			_, err := sess.Exec("DELETE FROM alert_instance_acknowledgement WHERE rule_org_id = ? AND rule_uid = ? AND labels_hash = ?", key.RuleOrgID, key.RuleUID, key.LabelsHash)
+			if err != nil {
+				return fmt.Errorf("failed to delete alert instance acknowledgement: %w", err)
+			}
+		}
+		return nil
+	})
+}
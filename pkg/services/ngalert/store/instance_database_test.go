@@ -391,3 +391,76 @@ func generateTestAlertInstance(orgID int64, ruleID string) models.AlertInstance
 		CurrentReason:     "abc",
 	}
 }
+
+func TestIntegrationAlertInstanceAcknowledgements(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	expires := now.Add(time.Hour)
+	key := models.AlertInstanceKey{RuleOrgID: 1, RuleUID: "rule", LabelsHash: "hash"}
+	ack := models.AlertInstanceAcknowledgement{
+		AlertInstanceKey: key,
+		Acknowledged:     true,
+		AcknowledgedBy:   "admin",
+		AcknowledgedAt:   now,
+		ExpiresAt:        &expires,
+		Comment:          "looking into it",
+		Updated:          now,
+		UpdatedBy:        "admin",
+	}
+
+	t.Run("saves acknowledgement", func(t *testing.T) {
+		require.NoError(t, dbstore.SaveAlertInstanceAcknowledgement(ctx, ack))
+
+		result, err := dbstore.GetAlertInstanceAcknowledgement(ctx, key)
+		require.NoError(t, err)
+		require.True(t, result.Acknowledged)
+		require.Equal(t, "admin", result.AcknowledgedBy)
+		require.True(t, expires.Equal(*result.ExpiresAt))
+		require.Equal(t, "looking into it", result.Comment)
+	})
+
+	t.Run("replaces acknowledgement", func(t *testing.T) {
+		updated := ack
+		updated.Acknowledged = false
+		updated.ExpiresAt = nil
+		updated.Assignee = "editor"
+		require.NoError(t, dbstore.SaveAlertInstanceAcknowledgement(ctx, updated))
+
+		result, err := dbstore.ListAlertInstanceAcknowledgements(ctx)
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+		require.False(t, result[0].Acknowledged)
+		require.Nil(t, result[0].ExpiresAt)
+		require.Equal(t, "editor", result[0].Assignee)
+	})
+
+	t.Run("saves the silence only if it was not changed by another instance", func(t *testing.T) {
+		saved, err := dbstore.SetAlertInstanceAcknowledgementSilence(ctx, key, "", "first")
+		require.NoError(t, err)
+		require.False(t, saved, "unacknowledged alert instances should not be silenced")
+
+		require.NoError(t, dbstore.SaveAlertInstanceAcknowledgement(ctx, ack))
+		saved, err = dbstore.SetAlertInstanceAcknowledgementSilence(ctx, key, "", "first")
+		require.NoError(t, err)
+		require.True(t, saved)
+
+		saved, err = dbstore.SetAlertInstanceAcknowledgementSilence(ctx, key, "", "second")
+		require.NoError(t, err)
+		require.False(t, saved)
+
+		result, err := dbstore.GetAlertInstanceAcknowledgement(ctx, key)
+		require.NoError(t, err)
+		require.Equal(t, "first", result.SilenceID)
+	})
+
+	t.Run("deletes acknowledgement", func(t *testing.T) {
+		require.NoError(t, dbstore.DeleteAlertInstanceAcknowledgements(ctx, key))
+		_, err := dbstore.GetAlertInstanceAcknowledgement(ctx, key)
+		require.ErrorIs(t, err, models.ErrAlertInstanceAcknowledgementNotFound)
+	})
+}
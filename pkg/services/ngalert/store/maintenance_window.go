@@ -0,0 +1,131 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// ListMaintenanceWindows returns the maintenance windows of the organization ordered by title.
+func (st DBstore) ListMaintenanceWindows(ctx context.Context, orgID int64) ([]*models.MaintenanceWindow, error) {
+	result := make([]*models.MaintenanceWindow, 0)
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ?", orgID).Asc("title").Find(&result)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list maintenance windows: %w", err)
+	}
+	return result, nil
+}
+
+// ListAllMaintenanceWindows returns the maintenance windows of all organizations.
+func (st DBstore) ListAllMaintenanceWindows(ctx context.Context) ([]*models.MaintenanceWindow, error) {
+	result := make([]*models.MaintenanceWindow, 0)
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Asc("id").Find(&result)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list maintenance windows: %w", err)
+	}
+	return result, nil
+}
+
+// GetMaintenanceWindow returns the maintenance window with the given UID. It returns ErrMaintenanceWindowNotFound if it does not exist.
+func (st DBstore) GetMaintenanceWindow(ctx context.Context, orgID int64, uid string) (*models.MaintenanceWindow, error) {
+	var window models.MaintenanceWindow
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Get(&window)
+		if err != nil {
+			return fmt.Errorf("failed to get maintenance window: %w", err)
+		}
+		if !exists {
+			return models.ErrMaintenanceWindowNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &window, nil
+}
+
+// InsertMaintenanceWindow saves a new maintenance window. A UID is generated if the window does not have one.
+func (st DBstore) InsertMaintenanceWindow(ctx context.Context, window *models.MaintenanceWindow) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		if window.UID == "" {
+			window.UID = util.GenerateShortUID()
+		}
+		window.LastOccurrence = 0
+		window.SilenceID = ""
+		if _, err := sess.Insert(window); err != nil {
+			return fmt.Errorf("failed to insert maintenance window: %w", err)
+		}
+		return nil
+	})
+}
+
+// UpdateMaintenanceWindow saves the definition of the maintenance window with the given UID. The latest occurrence
+// is reset, so that the silence of the occurrence in progress is replaced by one that reflects the new definition.
+// The ID of the existing silence is kept, so that it can be expired.
+func (st DBstore) UpdateMaintenanceWindow(ctx context.Context, window *models.MaintenanceWindow) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		window.LastOccurrence = 0
+		affected, err := sess.Where("org_id = ? AND uid = ?", window.OrgID, window.UID).
+			Cols("title", "schedule", "duration", "time_zone", "matchers", "comment", "updated", "last_occurrence").
+			Update(window)
+		if err != nil {
+			return fmt.Errorf("failed to update maintenance window: %w", err)
+		}
+		if affected == 0 {
+			return models.ErrMaintenanceWindowNotFound
+		}
+		return nil
+	})
+}
+
+// DeleteMaintenanceWindow deletes the maintenance window with the given UID. It returns ErrMaintenanceWindowNotFound if it does not exist.
+func (st DBstore) DeleteMaintenanceWindow(ctx context.Context, orgID int64, uid string) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Delete(&models.MaintenanceWindow{})
+		if err != nil {
+			return fmt.Errorf("failed to delete maintenance window: %w", err)
+		}
+		if affected == 0 {
+			return models.ErrMaintenanceWindowNotFound
+		}
+		return nil
+	})
+}
+
+// ClaimMaintenanceWindowOccurrence sets the latest occurrence of the maintenance window if it is still the given
+// previous one, and returns whether it did. Only the first of several Grafana instances that claim the same
+// occurrence gets true, so that a single silence is created for each occurrence.
+func (st DBstore) ClaimMaintenanceWindowOccurrence(ctx context.Context, id int64, previous, occurrence int64) (bool, error) {
+	var claimed bool
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("UPDATE alert_maintenance_window SET last_occurrence = ? WHERE id = ? AND last_occurrence = ?", occurrence, id, previous)
+		if err != nil {
+			return fmt.Errorf("failed to claim maintenance window occurrence: %w", err)
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		claimed = affected == 1
+		return nil
+	})
+	return claimed, err
+}
+
+// SetMaintenanceWindowSilence saves the ID of the silence of the latest occurrence of the maintenance window.
+func (st DBstore) SetMaintenanceWindowSilence(ctx context.Context, id int64, silenceID string) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.Exec("UPDATE alert_maintenance_window SET silence_id = ? WHERE id = ?", silenceID, id); err != nil {
+			return fmt.Errorf("failed to save maintenance window silence: %w", err)
+		}
+		return nil
+	})
+}
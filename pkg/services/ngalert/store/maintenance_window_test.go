@@ -0,0 +1,104 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestIntegrationMaintenanceWindows(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	newWindow := func(orgID int64, title string) *models.MaintenanceWindow {
+		return &models.MaintenanceWindow{
+			OrgID:    orgID,
+			Title:    title,
+			Schedule: "0 2 * * 6",
+			Duration: 2 * time.Hour,
+			TimeZone: "Europe/Berlin",
+			Matchers: []string{`service="db"`},
+		}
+	}
+
+	second := newWindow(1, "second")
+	first := newWindow(1, "first")
+	other := newWindow(2, "other")
+	for _, w := range []*models.MaintenanceWindow{second, first, other} {
+		require.NoError(t, dbstore.InsertMaintenanceWindow(ctx, w))
+		require.NotZero(t, w.ID)
+		require.NotEmpty(t, w.UID)
+	}
+
+	t.Run("lists maintenance windows of the organization by title", func(t *testing.T) {
+		result, err := dbstore.ListMaintenanceWindows(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, result, 2)
+		require.Equal(t, "first", result[0].Title)
+		require.Equal(t, "second", result[1].Title)
+		require.Equal(t, []string{`service="db"`}, result[0].Matchers)
+		require.Equal(t, 2*time.Hour, result[0].Duration)
+
+		all, err := dbstore.ListAllMaintenanceWindows(ctx)
+		require.NoError(t, err)
+		require.Len(t, all, 3)
+	})
+
+	t.Run("gets maintenance window by UID in the organization", func(t *testing.T) {
+		result, err := dbstore.GetMaintenanceWindow(ctx, 1, first.UID)
+		require.NoError(t, err)
+		require.Equal(t, "first", result.Title)
+
+		_, err = dbstore.GetMaintenanceWindow(ctx, 2, first.UID)
+		require.ErrorIs(t, err, models.ErrMaintenanceWindowNotFound)
+	})
+
+	t.Run("only one claim of an occurrence succeeds", func(t *testing.T) {
+		claimed, err := dbstore.ClaimMaintenanceWindowOccurrence(ctx, first.ID, 0, 100)
+		require.NoError(t, err)
+		require.True(t, claimed)
+
+		claimed, err = dbstore.ClaimMaintenanceWindowOccurrence(ctx, first.ID, 0, 100)
+		require.NoError(t, err)
+		require.False(t, claimed)
+
+		require.NoError(t, dbstore.SetMaintenanceWindowSilence(ctx, first.ID, "silence"))
+		result, err := dbstore.GetMaintenanceWindow(ctx, 1, first.UID)
+		require.NoError(t, err)
+		require.EqualValues(t, 100, result.LastOccurrence)
+		require.Equal(t, "silence", result.SilenceID)
+	})
+
+	t.Run("update resets the latest occurrence and keeps the silence", func(t *testing.T) {
+		updated := *first
+		updated.Matchers = []string{`service="cache"`}
+		require.NoError(t, dbstore.UpdateMaintenanceWindow(ctx, &updated))
+
+		result, err := dbstore.GetMaintenanceWindow(ctx, 1, first.UID)
+		require.NoError(t, err)
+		require.Equal(t, []string{`service="cache"`}, result.Matchers)
+		require.Zero(t, result.LastOccurrence)
+		require.Equal(t, "silence", result.SilenceID)
+
+		missing := *first
+		missing.UID = "missing"
+		require.ErrorIs(t, dbstore.UpdateMaintenanceWindow(ctx, &missing), models.ErrMaintenanceWindowNotFound)
+	})
+
+	t.Run("deletes maintenance window", func(t *testing.T) {
+		require.NoError(t, dbstore.DeleteMaintenanceWindow(ctx, 1, second.UID))
+		require.ErrorIs(t, dbstore.DeleteMaintenanceWindow(ctx, 1, second.UID), models.ErrMaintenanceWindowNotFound)
+		result, err := dbstore.ListMaintenanceWindows(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+	})
+}
@@ -0,0 +1,105 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// ClaimAlertRules makes owner the member of the cluster that evaluates the rules. A rule can be claimed if no member
+// evaluated it yet, if its previous owner released it, or if its previous owner is not one of the live members of the
+// cluster anymore. It returns the rules that owner evaluates now and, for each of them, whether another member
+// evaluated it before, in which case the state that the previous owner saved must be loaded.
+func (st DBstore) ClaimAlertRules(ctx context.Context, owner string, members []string, keys []models.AlertRuleKey) (map[models.AlertRuleKey]bool, error) {
+	result := make(map[models.AlertRuleKey]bool, len(keys))
+	if len(keys) == 0 {
+		return result, nil
+	}
+	err := st.SQLStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		current, err := getAlertRuleOwners(sess, keys)
+		if err != nil {
+			return err
+		}
+		now := TimeNow()
+		for _, key := range keys {
+			previous, ok := current[key]
+			if !ok {
+				if _, err := sess.Insert(&models.AlertRuleOwner{RuleOrgID: key.OrgID, RuleUID: key.UID, Owner: owner, Updated: now}); err != nil {
+					return fmt.Errorf("failed to insert alert rule owner: %w", err)
+				}
+				result[key] = false
+				continue
+			}
+			if previous.Owner == owner {
+				result[key] = false
+				continue
+			}
+			if previous.Owner != "" && slices.Contains(members, previous.Owner) {
+				// The previous owner has not released the rule yet.
+				continue
+			}
+			affected, err := sess.Table(models.AlertRuleOwner{}).
+				Where("rule_org_id = ? AND rule_uid = ? AND owner = ?", key.OrgID, key.UID, previous.Owner).
+				Cols("owner", "updated").
+				Update(&models.AlertRuleOwner{Owner: owner, Updated: now})
+			if err != nil {
+				return fmt.Errorf("failed to update alert rule owner: %w", err)
+			}
+			if affected > 0 {
+				result[key] = true
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ReleaseAlertRules gives up the evaluation of the rules that are owned by owner, so that other members of the
+// cluster can claim them. It must be called after the state of the rules has been saved.
+func (st DBstore) ReleaseAlertRules(ctx context.Context, owner string, keys ...models.AlertRuleKey) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	byOrg := make(map[int64][]string)
+	for _, key := range keys {
+		byOrg[key.OrgID] = append(byOrg[key.OrgID], key.UID)
+	}
+	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		now := TimeNow()
+		for orgID, uids := range byOrg {
+			_, err := sess.Table(models.AlertRuleOwner{}).
+				Where("rule_org_id = ? AND owner = ?", orgID, owner).
+				In("rule_uid", uids).
+				Cols("owner", "updated").
+				Update(map[string]any{"owner": "", "updated": now})
+			if err != nil {
+				return fmt.Errorf("failed to release alert rules: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+func getAlertRuleOwners(sess *db.Session, keys []models.AlertRuleKey) (map[models.AlertRuleKey]models.AlertRuleOwner, error) {
+	byOrg := make(map[int64][]string)
+	for _, key := range keys {
+		byOrg[key.OrgID] = append(byOrg[key.OrgID], key.UID)
+	}
+	result := make(map[models.AlertRuleKey]models.AlertRuleOwner, len(keys))
+	for orgID, uids := range byOrg {
+		owners := make([]models.AlertRuleOwner, 0, len(uids))
+		if err := sess.Where("rule_org_id = ?", orgID).In("rule_uid", uids).Find(&owners); err != nil {
+			return nil, fmt.Errorf("failed to get alert rule owners: %w", err)
+		}
+		for _, owner := range owners {
+			result[models.AlertRuleKey{OrgID: owner.RuleOrgID, UID: owner.RuleUID}] = owner
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,56 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestIntegrationAlertRuleOwners(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	first := models.AlertRuleKey{OrgID: 1, UID: "first"}
+	second := models.AlertRuleKey{OrgID: 2, UID: "second"}
+
+	t.Run("claims rules that have no owner", func(t *testing.T) {
+		claimed, err := dbstore.ClaimAlertRules(ctx, "a", []string{"a", "b"}, []models.AlertRuleKey{first, second})
+		require.NoError(t, err)
+		require.Equal(t, map[models.AlertRuleKey]bool{first: false, second: false}, claimed)
+
+		claimed, err = dbstore.ClaimAlertRules(ctx, "a", []string{"a", "b"}, []models.AlertRuleKey{first})
+		require.NoError(t, err)
+		require.Equal(t, map[models.AlertRuleKey]bool{first: false}, claimed)
+	})
+
+	t.Run("does not claim rules of live members until they release them", func(t *testing.T) {
+		claimed, err := dbstore.ClaimAlertRules(ctx, "b", []string{"a", "b"}, []models.AlertRuleKey{first, second})
+		require.NoError(t, err)
+		require.Empty(t, claimed)
+
+		// Only the owner can release the rule.
+		require.NoError(t, dbstore.ReleaseAlertRules(ctx, "b", first))
+		claimed, err = dbstore.ClaimAlertRules(ctx, "b", []string{"a", "b"}, []models.AlertRuleKey{first})
+		require.NoError(t, err)
+		require.Empty(t, claimed)
+
+		require.NoError(t, dbstore.ReleaseAlertRules(ctx, "a", first))
+		claimed, err = dbstore.ClaimAlertRules(ctx, "b", []string{"a", "b"}, []models.AlertRuleKey{first, second})
+		require.NoError(t, err)
+		require.Equal(t, map[models.AlertRuleKey]bool{first: true}, claimed)
+	})
+
+	t.Run("claims rules of members that left the cluster", func(t *testing.T) {
+		claimed, err := dbstore.ClaimAlertRules(ctx, "b", []string{"b"}, []models.AlertRuleKey{second})
+		require.NoError(t, err)
+		require.Equal(t, map[models.AlertRuleKey]bool{second: true}, claimed)
+	})
+}
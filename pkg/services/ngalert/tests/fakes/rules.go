@@ -25,6 +25,8 @@ type RuleStore struct {
 	Hook        func(cmd any) error // use Hook if you need to intercept some query and return an error
 	RecordedOps []any
 	Folders     map[int64][]*folder.Folder
+	// OrgID -> Versions of rules
+	Versions map[int64][]*models.AlertRuleVersion
 }
 
 type GenericRecordedQuery struct {
@@ -39,7 +41,8 @@ func NewRuleStore(t *testing.T) *RuleStore {
 		Hook: func(any) error {
 			return nil
 		},
-		Folders: map[int64][]*folder.Folder{},
+		Folders:  map[int64][]*folder.Folder{},
+		Versions: map[int64][]*models.AlertRuleVersion{},
 	}
 }
 
@@ -285,6 +288,49 @@ func (f *RuleStore) InsertAlertRules(_ context.Context, q []models.AlertRule) ([
 	return ids, nil
 }
 
+// GetAlertRuleVersions returns the versions of the rule in Versions, most recent first.
+func (f *RuleStore) GetAlertRuleVersions(_ context.Context, orgID int64, ruleUID string) ([]*models.AlertRuleVersion, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	q := GenericRecordedQuery{
+		Name:   "GetAlertRuleVersions",
+		Params: []any{orgID, ruleUID},
+	}
+	f.RecordedOps = append(f.RecordedOps, q)
+	if err := f.Hook(q); err != nil {
+		return nil, err
+	}
+	var result []*models.AlertRuleVersion
+	for _, version := range f.Versions[orgID] {
+		if version.RuleUID == ruleUID {
+			result = append(result, version)
+		}
+	}
+	slices.SortFunc(result, func(a, b *models.AlertRuleVersion) int {
+		return int(b.Version - a.Version)
+	})
+	return result, nil
+}
+
+func (f *RuleStore) GetAlertRuleVersion(_ context.Context, orgID int64, ruleUID string, version int64) (*models.AlertRuleVersion, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	q := GenericRecordedQuery{
+		Name:   "GetAlertRuleVersion",
+		Params: []any{orgID, ruleUID, version},
+	}
+	f.RecordedOps = append(f.RecordedOps, q)
+	if err := f.Hook(q); err != nil {
+		return nil, err
+	}
+	for _, v := range f.Versions[orgID] {
+		if v.RuleUID == ruleUID && v.Version == version {
+			return v, nil
+		}
+	}
+	return nil, models.ErrAlertRuleVersionNotFound
+}
+
 func (f *RuleStore) InTransaction(ctx context.Context, fn func(c context.Context) error) error {
 	return fn(ctx)
 }
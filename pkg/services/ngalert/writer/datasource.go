@@ -0,0 +1,226 @@
+package writer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	sdkhttpclient "github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var (
+	ErrNoWriteTarget               = errors.New("recording rule has no target data source and no default is configured")
+	ErrUnsupportedTargetDatasource = errors.New("data source type does not support writing recording rule output")
+)
+
+// DatasourceService is the subset of datasources.DataSourceService that is needed to write to a data source.
+type DatasourceService interface {
+	GetDataSource(ctx context.Context, query *datasources.GetDataSourceQuery) (*datasources.DataSource, error)
+	GetHTTPTransport(ctx context.Context, ds *datasources.DataSource, provider httpclient.Provider, customMiddlewares ...sdkhttpclient.Middleware) (http.RoundTripper, error)
+	DecryptedValue(ctx context.Context, ds *datasources.DataSource, key string) (string, bool, error)
+	DecryptedPassword(ctx context.Context, ds *datasources.DataSource) (string, error)
+}
+
+// TargetDatasourceGetter is the subset of datasources.DataSourceService that is needed to validate the data sources
+// recording rules write to.
+type TargetDatasourceGetter interface {
+	GetDataSource(ctx context.Context, query *datasources.GetDataSourceQuery) (*datasources.DataSource, error)
+}
+
+// ValidateTargetDatasources checks that the data sources with the given UIDs exist in the organization, and that
+// recording rules can write to them.
+func ValidateTargetDatasources(ctx context.Context, getter TargetDatasourceGetter, orgID int64, uids ...string) error {
+	for _, uid := range uids {
+		if uid == "" || uid == GrafanaDatasourceUID {
+			continue
+		}
+		ds, err := getter.GetDataSource(ctx, &datasources.GetDataSourceQuery{UID: uid, OrgID: orgID})
+		if err != nil {
+			if errors.Is(err, datasources.ErrDataSourceNotFound) {
+				return fmt.Errorf("target data source %q does not exist", uid)
+			}
+			return fmt.Errorf("failed to get target data source %q: %w", uid, err)
+		}
+		if !isWritableDatasourceType(ds.Type) {
+			return fmt.Errorf("%w: %s", ErrUnsupportedTargetDatasource, ds.Type)
+		}
+	}
+	return nil
+}
+
+func isWritableDatasourceType(dsType string) bool {
+	return dsType == datasources.DS_PROMETHEUS || dsType == datasources.DS_INFLUXDB
+}
+
+// Writer writes the output of a recording rule to a single target.
+type Writer interface {
+	Write(ctx context.Context, name string, t time.Time, frames data.Frames, orgID int64, extraLabels map[string]string) error
+}
+
+type writerKey struct {
+	orgID int64
+	uid   string
+}
+
+type cachedWriter struct {
+	version int
+	writer  Writer
+}
+
+// DatasourceWriter routes the output of recording rules to the data source each rule targets.
+// Rules without a target data source write to the configured default data source or,
+// if there is none, to the globally configured remote write endpoint.
+type DatasourceWriter struct {
+	settings           setting.RecordingRuleSettings
+	datasources        DatasourceService
+	httpClientProvider httpclient.Provider
+	clock              clock.Clock
+	logger             log.Logger
+	metrics            *metrics.RemoteWriter
+
+	defaultWriter Writer
+	localWriter   Writer
+
+	mtx     sync.Mutex
+	writers map[writerKey]cachedWriter
+}
+
+func NewDatasourceWriter(
+	settings setting.RecordingRuleSettings,
+	datasources DatasourceService,
+	httpClientProvider httpclient.Provider,
+	store db.DB,
+	clock clock.Clock,
+	l log.Logger,
+	metrics *metrics.RemoteWriter,
+) (*DatasourceWriter, error) {
+	if err := validateSettings(settings); err != nil {
+		return nil, err
+	}
+
+	w := &DatasourceWriter{
+		settings:           settings,
+		datasources:        datasources,
+		httpClientProvider: httpClientProvider,
+		clock:              clock,
+		logger:             l,
+		metrics:            metrics,
+		writers:            make(map[writerKey]cachedWriter),
+	}
+	if settings.URL != "" {
+		defaultWriter, err := NewPrometheusWriter(settings, httpClientProvider, clock, l, metrics)
+		if err != nil {
+			return nil, err
+		}
+		w.defaultWriter = defaultWriter
+	}
+	if store != nil {
+		w.localWriter = NewLocalWriter(store, settings, clock, l, metrics)
+	}
+	return w, nil
+}
+
+// WriteDatasource writes the given frames to the data source with the given UID in the given organization.
+func (w *DatasourceWriter) WriteDatasource(ctx context.Context, dsUID string, name string, t time.Time, frames data.Frames, orgID int64, extraLabels map[string]string) error {
+	target, err := w.writerFor(ctx, dsUID, orgID)
+	if err != nil {
+		return err
+	}
+	return target.Write(ctx, name, t, frames, orgID, extraLabels)
+}
+
+func (w *DatasourceWriter) writerFor(ctx context.Context, dsUID string, orgID int64) (Writer, error) {
+	if dsUID == "" {
+		dsUID = w.settings.DefaultDatasourceUID
+	}
+	if dsUID == "" {
+		if w.defaultWriter == nil {
+			return nil, ErrNoWriteTarget
+		}
+		return w.defaultWriter, nil
+	}
+	if dsUID == GrafanaDatasourceUID {
+		if w.localWriter == nil {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedTargetDatasource, GrafanaDatasourceUID)
+		}
+		return w.localWriter, nil
+	}
+	if w.datasources == nil {
+		return nil, fmt.Errorf("cannot write to data source %q: data sources are not available", dsUID)
+	}
+
+	ds, err := w.datasources.GetDataSource(ctx, &datasources.GetDataSourceQuery{UID: dsUID, OrgID: orgID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get target data source %q: %w", dsUID, err)
+	}
+
+	key := writerKey{orgID: orgID, uid: dsUID}
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	// Data sources get a new version whenever they are updated, in which case the writer has to be recreated.
+	if cached, ok := w.writers[key]; ok && cached.version == ds.Version {
+		return cached.writer, nil
+	}
+
+	target, err := w.newWriter(ctx, ds)
+	if err != nil {
+		return nil, err
+	}
+	w.writers[key] = cachedWriter{version: ds.Version, writer: target}
+	return target, nil
+}
+
+func (w *DatasourceWriter) newWriter(ctx context.Context, ds *datasources.DataSource) (Writer, error) {
+	transport, err := w.datasources.GetHTTPTransport(ctx, ds, w.httpClientProvider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP transport for data source %q: %w", ds.UID, err)
+	}
+	cl := &http.Client{Transport: transport, Timeout: w.settings.Timeout}
+
+	switch ds.Type {
+	case datasources.DS_PROMETHEUS:
+		writeURL, err := prometheusWriteURL(ds)
+		if err != nil {
+			return nil, fmt.Errorf("invalid URL of data source %q: %w", ds.UID, err)
+		}
+		return newPrometheusWriter(writeURL, cl, w.settings, ds.UID, w.clock, w.logger, w.metrics)
+	case datasources.DS_INFLUXDB:
+		return newInfluxDBWriter(ctx, ds, cl, w.datasources, w.settings, w.clock, w.logger, w.metrics)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedTargetDatasource, ds.Type)
+	}
+}
+
+// prometheusWriteURL returns the remote write endpoint of a Prometheus data source.
+// Mimir and Cortex serve queries under the /prometheus prefix but accept writes on /api/v1/push.
+func prometheusWriteURL(ds *datasources.DataSource) (string, error) {
+	u, err := url.Parse(ds.URL)
+	if err != nil {
+		return "", err
+	}
+	flavor := ""
+	if ds.JsonData != nil {
+		flavor = ds.JsonData.Get("prometheusType").MustString()
+	}
+	switch flavor {
+	case "Mimir", "Cortex":
+		u.Path = strings.TrimSuffix(strings.TrimSuffix(u.Path, "/"), "/prometheus")
+		return u.JoinPath("api/v1/push").String(), nil
+	default:
+		return u.JoinPath("api/v1/write").String(), nil
+	}
+}
@@ -0,0 +1,271 @@
+package writer
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	dsfakes "github.com/grafana/grafana/pkg/services/datasources/fakes"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestPrometheusWriteURL(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		url      string
+		flavor   string
+		expected string
+	}{
+		{name: "prometheus", url: "http://localhost:9090", flavor: "Prometheus", expected: "http://localhost:9090/api/v1/write"},
+		{name: "no flavor", url: "http://localhost:9090/", expected: "http://localhost:9090/api/v1/write"},
+		{name: "mimir", url: "http://mimir/prometheus", flavor: "Mimir", expected: "http://mimir/api/v1/push"},
+		{name: "cortex", url: "http://cortex/prometheus/", flavor: "Cortex", expected: "http://cortex/api/v1/push"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ds := &datasources.DataSource{URL: tc.url, JsonData: simplejson.NewFromAny(map[string]any{"prometheusType": tc.flavor})}
+			actual, err := prometheusWriteURL(ds)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestDatasourceWriter(t *testing.T) {
+	srv := newRecordingServer(t)
+	dsService := &dsfakes.FakeDataSourceService{
+		DataSources: []*datasources.DataSource{
+			{UID: "prom", OrgID: 1, Type: datasources.DS_PROMETHEUS, URL: srv.URL()},
+			{UID: "mimir", OrgID: 2, Type: datasources.DS_PROMETHEUS, URL: srv.URL() + "/prometheus", JsonData: simplejson.NewFromAny(map[string]any{"prometheusType": "Mimir"})},
+			{UID: "influx", OrgID: 1, Type: datasources.DS_INFLUXDB, URL: srv.URL(), Database: "metrics"},
+			{UID: "loki", OrgID: 1, Type: datasources.DS_LOKI, URL: srv.URL()},
+		},
+	}
+	settings := setting.RecordingRuleSettings{Timeout: time.Second, MaxAttempts: 3}
+	now := time.Unix(1700000000, 0)
+	frames := frameGenFromLabels(t, data.FrameTypeNumericWide, []map[string]string{{"foo": "1"}})
+
+	newWriter := func(t *testing.T, settings setting.RecordingRuleSettings) (*DatasourceWriter, *metrics.RemoteWriter) {
+		t.Helper()
+		m := metrics.NewRemoteWriterMetrics(prometheus.NewRegistry())
+		w, err := NewDatasourceWriter(settings, dsService, httpclient.NewProvider(), nil, clock.New(), log.NewNopLogger(), m)
+		require.NoError(t, err)
+		return w, m
+	}
+
+	t.Run("writes to prometheus data source", func(t *testing.T) {
+		srv.Reset()
+		w, m := newWriter(t, settings)
+
+		err := w.WriteDatasource(context.Background(), "prom", "test", now, frames, 1, nil)
+		require.NoError(t, err)
+
+		require.Equal(t, []string{"/api/v1/write"}, srv.Paths())
+		require.Equal(t, 1.0, testutil.ToFloat64(m.WritesTotal.WithLabelValues("1", backendType, "prom", "200")))
+	})
+
+	t.Run("writes to mimir push endpoint", func(t *testing.T) {
+		srv.Reset()
+		w, _ := newWriter(t, settings)
+
+		err := w.WriteDatasource(context.Background(), "mimir", "test", now, frames, 2, nil)
+		require.NoError(t, err)
+
+		require.Equal(t, []string{"/api/v1/push"}, srv.Paths())
+	})
+
+	t.Run("writes line protocol to influxdb data source", func(t *testing.T) {
+		srv.Reset()
+		w, m := newWriter(t, settings)
+
+		err := w.WriteDatasource(context.Background(), "influx", "test", now, frames, 1, map[string]string{"extra": "label"})
+		require.NoError(t, err)
+
+		require.Equal(t, []string{"/write?db=metrics&precision=ns"}, srv.Paths())
+		require.True(t, strings.HasPrefix(srv.LastBody(), "test,extra=label,foo=1 value="))
+		require.True(t, strings.HasSuffix(srv.LastBody(), " 1700000000000000000"))
+		require.Equal(t, 1.0, testutil.ToFloat64(m.WritesTotal.WithLabelValues("1", influxDBBackendType, "influx", "200")))
+	})
+
+	t.Run("fails for unknown data sources", func(t *testing.T) {
+		w, _ := newWriter(t, settings)
+
+		err := w.WriteDatasource(context.Background(), "unknown", "test", now, frames, 1, nil)
+		require.ErrorIs(t, err, datasources.ErrDataSourceNotFound)
+	})
+
+	t.Run("fails for unsupported data source types", func(t *testing.T) {
+		w, _ := newWriter(t, settings)
+
+		err := w.WriteDatasource(context.Background(), "loki", "test", now, frames, 1, nil)
+		require.ErrorIs(t, err, ErrUnsupportedTargetDatasource)
+	})
+
+	t.Run("fails without target and default", func(t *testing.T) {
+		w, _ := newWriter(t, settings)
+
+		err := w.WriteDatasource(context.Background(), "", "test", now, frames, 1, nil)
+		require.ErrorIs(t, err, ErrNoWriteTarget)
+	})
+
+	t.Run("uses the default data source when rule has no target", func(t *testing.T) {
+		srv.Reset()
+		settings := settings
+		settings.DefaultDatasourceUID = "prom"
+		w, _ := newWriter(t, settings)
+
+		err := w.WriteDatasource(context.Background(), "", "test", now, frames, 1, nil)
+		require.NoError(t, err)
+
+		require.Equal(t, []string{"/api/v1/write"}, srv.Paths())
+	})
+
+	t.Run("uses the remote write URL when there is no default data source", func(t *testing.T) {
+		srv.Reset()
+		settings := settings
+		settings.URL = srv.URL() + "/custom/write"
+		w, m := newWriter(t, settings)
+
+		err := w.WriteDatasource(context.Background(), "", "test", now, frames, 1, nil)
+		require.NoError(t, err)
+
+		require.Equal(t, []string{"/custom/write"}, srv.Paths())
+		require.Equal(t, 1.0, testutil.ToFloat64(m.WritesTotal.WithLabelValues("1", backendType, defaultTarget, "200")))
+	})
+
+	t.Run("retries server errors", func(t *testing.T) {
+		srv.Reset()
+		srv.FailWith(http.StatusServiceUnavailable, 2)
+		w, m := newWriter(t, settings)
+
+		err := w.WriteDatasource(context.Background(), "influx", "test", now, frames, 1, nil)
+		require.NoError(t, err)
+
+		require.Len(t, srv.Paths(), 3)
+		require.Equal(t, 2.0, testutil.ToFloat64(m.WriteRetries.WithLabelValues("1", influxDBBackendType, "influx")))
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		srv.Reset()
+		srv.FailWith(http.StatusServiceUnavailable, 5)
+		w, _ := newWriter(t, settings)
+
+		err := w.WriteDatasource(context.Background(), "influx", "test", now, frames, 1, nil)
+		require.ErrorContains(t, err, "status code 503")
+
+		require.Len(t, srv.Paths(), 3)
+	})
+
+	t.Run("does not retry client errors", func(t *testing.T) {
+		srv.Reset()
+		srv.FailWith(http.StatusBadRequest, 1)
+		w, _ := newWriter(t, settings)
+
+		err := w.WriteDatasource(context.Background(), "influx", "test", now, frames, 1, nil)
+		require.ErrorContains(t, err, "status code 400")
+
+		require.Len(t, srv.Paths(), 1)
+	})
+
+	t.Run("splits series into batches", func(t *testing.T) {
+		srv.Reset()
+		settings := settings
+		settings.BatchSize = 2
+		w, _ := newWriter(t, settings)
+		frames := frameGenFromLabels(t, data.FrameTypeNumericWide, []map[string]string{{"foo": "1"}, {"foo": "2"}, {"foo": "3"}})
+
+		err := w.WriteDatasource(context.Background(), "influx", "test", now, frames, 1, nil)
+		require.NoError(t, err)
+
+		require.Len(t, srv.Paths(), 2)
+	})
+}
+
+// recordingServer is a write endpoint that records the requests it receives.
+func TestValidateTargetDatasources(t *testing.T) {
+	dsService := &dsfakes.FakeDataSourceService{DataSources: []*datasources.DataSource{
+		{UID: "prom", OrgID: 1, Type: datasources.DS_PROMETHEUS},
+		{UID: "influx", OrgID: 1, Type: datasources.DS_INFLUXDB},
+		{UID: "loki", OrgID: 1, Type: datasources.DS_LOKI},
+	}}
+
+	require.NoError(t, ValidateTargetDatasources(context.Background(), dsService, 1, "prom", "influx", GrafanaDatasourceUID))
+	require.ErrorIs(t, ValidateTargetDatasources(context.Background(), dsService, 1, "loki"), ErrUnsupportedTargetDatasource)
+	require.ErrorContains(t, ValidateTargetDatasources(context.Background(), dsService, 1, "unknown"), "does not exist")
+}
+
+type recordingServer struct {
+	srv *httptest.Server
+
+	mtx      sync.Mutex
+	paths    []string
+	lastBody string
+	failCode int
+	failures int
+}
+
+func newRecordingServer(t *testing.T) *recordingServer {
+	t.Helper()
+	s := &recordingServer{}
+	s.srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		s.mtx.Lock()
+		defer s.mtx.Unlock()
+		s.paths = append(s.paths, r.URL.RequestURI())
+		s.lastBody = string(body)
+		if s.failures > 0 {
+			s.failures--
+			w.WriteHeader(s.failCode)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(s.srv.Close)
+	return s
+}
+
+func (s *recordingServer) URL() string {
+	return s.srv.URL
+}
+
+// FailWith makes the next n requests fail with the given status code.
+func (s *recordingServer) FailWith(code, n int) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.failCode, s.failures = code, n
+}
+
+func (s *recordingServer) Paths() []string {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return append([]string(nil), s.paths...)
+}
+
+func (s *recordingServer) LastBody() string {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.lastBody
+}
+
+func (s *recordingServer) Reset() {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.paths, s.lastBody, s.failCode, s.failures = nil, "", 0, 0
+}
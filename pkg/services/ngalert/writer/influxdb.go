@@ -0,0 +1,229 @@
+package writer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const influxDBBackendType = "influxdb"
+
+// Query languages of the InfluxDB data source. They determine which write API the data source supports.
+const (
+	influxQLVersion = "InfluxQL"
+	fluxVersion     = "Flux"
+	sqlVersion      = "SQL"
+)
+
+// maxErrorBodySize is the maximum number of bytes of an error response that are included in the returned error.
+const maxErrorBodySize = 1024
+
+var (
+	measurementEscaper = strings.NewReplacer(`,`, `\,`, ` `, `\ `)
+	tagEscaper         = strings.NewReplacer(`,`, `\,`, `=`, `\=`, ` `, `\ `)
+)
+
+// InfluxDBWriter writes recording rule output to an InfluxDB data source using the line protocol.
+type InfluxDBWriter struct {
+	client   *http.Client
+	writeURL string
+	// authorization is the value of the Authorization header. It is empty if the data source uses basic auth or no auth.
+	authorization string
+	user          string
+	password      string
+	clock         clock.Clock
+	logger        log.Logger
+	metrics       *metrics.RemoteWriter
+	target        string
+	opts          writeOptions
+}
+
+func newInfluxDBWriter(ctx context.Context, ds *datasources.DataSource, cl *http.Client, secrets DatasourceService, settings setting.RecordingRuleSettings, clock clock.Clock, l log.Logger, metrics *metrics.RemoteWriter) (*InfluxDBWriter, error) {
+	jsonData := ds.JsonData
+	if jsonData == nil {
+		jsonData = simplejson.New()
+	}
+	version := jsonData.Get("version").MustString(influxQLVersion)
+	writeURL, err := influxDBWriteURL(ds.URL, version, ds.Database, jsonData)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &InfluxDBWriter{
+		client:   cl,
+		writeURL: writeURL,
+		clock:    clock,
+		logger:   l,
+		metrics:  metrics,
+		target:   ds.UID,
+		opts:     writeOptionsFromSettings(settings),
+	}
+	switch version {
+	case fluxVersion, sqlVersion:
+		token, ok, err := secrets.DecryptedValue(ctx, ds, "token")
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt token of data source %q: %w", ds.UID, err)
+		}
+		if ok && token != "" {
+			w.authorization = "Token " + token
+		}
+	default:
+		if ds.User != "" {
+			password, err := secrets.DecryptedPassword(ctx, ds)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decrypt password of data source %q: %w", ds.UID, err)
+			}
+			w.user, w.password = ds.User, password
+		}
+	}
+	return w, nil
+}
+
+// influxDBWriteURL returns the write endpoint of an InfluxDB data source.
+// InfluxQL data sources use the 1.x API, Flux and SQL data sources use the 2.x API, which InfluxDB 3 supports as well.
+func influxDBWriteURL(dsURL, version, database string, jsonData *simplejson.Json) (string, error) {
+	u, err := url.Parse(dsURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid data source URL: %w", err)
+	}
+	q := url.Values{}
+	switch version {
+	case fluxVersion:
+		u = u.JoinPath("api/v2/write")
+		q.Set("org", jsonData.Get("organization").MustString())
+		q.Set("bucket", jsonData.Get("defaultBucket").MustString())
+	case sqlVersion:
+		u = u.JoinPath("api/v2/write")
+		q.Set("bucket", jsonData.Get("dbName").MustString())
+	default:
+		if database == "" {
+			database = jsonData.Get("dbName").MustString()
+		}
+		u = u.JoinPath("write")
+		q.Set("db", database)
+	}
+	if q.Get("bucket") == "" && q.Get("db") == "" {
+		return "", fmt.Errorf("data source has no database or bucket configured")
+	}
+	q.Set("precision", "ns")
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// Write writes the given frames to InfluxDB. Every series becomes a point of the measurement with the given name,
+// with the series labels as tags and a single field called "value".
+func (w InfluxDBWriter) Write(ctx context.Context, name string, t time.Time, frames data.Frames, orgID int64, extraLabels map[string]string) error {
+	l := w.logger.FromContext(ctx)
+	lvs := []string{fmt.Sprint(orgID), influxDBBackendType, w.target}
+
+	points, err := PointsFromFrames(name, t, frames, extraLabels)
+	if err != nil {
+		return err
+	}
+
+	lines := make([]string, 0, len(points))
+	for _, p := range points {
+		if math.IsNaN(p.Metric.V) || math.IsInf(p.Metric.V, 0) {
+			l.Debug("Skipping point with a value InfluxDB cannot store", "name", name, "labels", p.Labels)
+			continue
+		}
+		lines = append(lines, lineProtocol(p))
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+
+	l.Debug("Writing metric", "name", name)
+	for _, b := range batch(lines, w.opts.batchSize) {
+		err := withRetry(ctx, w.clock, w.opts, func() {
+			w.metrics.WriteRetries.WithLabelValues(lvs...).Inc()
+		}, func() error {
+			return w.writeLines(ctx, b, lvs)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w InfluxDBWriter) writeLines(ctx context.Context, lines []string, lvs []string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.writeURL, bytes.NewBufferString(strings.Join(lines, "\n")))
+	if err != nil {
+		return fmt.Errorf("failed to create write request: %w", err)
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	req.Header.Set("User-Agent", "grafana-recording-rule")
+	if w.authorization != "" {
+		req.Header.Set("Authorization", w.authorization)
+	} else if w.user != "" {
+		req.SetBasicAuth(w.user, w.password)
+	}
+
+	writeStart := w.clock.Now()
+	resp, err := w.client.Do(req)
+	w.metrics.WriteDuration.WithLabelValues(lvs...).Observe(w.clock.Now().Sub(writeStart).Seconds())
+	if err != nil {
+		w.metrics.WritesTotal.WithLabelValues(lvs[0], lvs[1], lvs[2], "0").Inc()
+		return retryableError{err: fmt.Errorf("failed to write points: %w", err)}
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	w.metrics.WritesTotal.WithLabelValues(lvs[0], lvs[1], lvs[2], fmt.Sprint(resp.StatusCode)).Inc()
+
+	if resp.StatusCode/100 == 2 {
+		return nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	err = fmt.Errorf("failed to write points: status code %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	if isRetryableStatus(resp.StatusCode) {
+		return retryableError{err: err}
+	}
+	return err
+}
+
+// lineProtocol formats a point as a line of the InfluxDB line protocol.
+func lineProtocol(p Point) string {
+	keys := make([]string, 0, len(p.Labels))
+	for k := range p.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	b := strings.Builder{}
+	b.WriteString(measurementEscaper.Replace(p.Name))
+	for _, k := range keys {
+		v := p.Labels[k]
+		// InfluxDB does not accept tags with empty values.
+		if v == "" {
+			continue
+		}
+		b.WriteString(",")
+		b.WriteString(tagEscaper.Replace(k))
+		b.WriteString("=")
+		b.WriteString(tagEscaper.Replace(v))
+	}
+	b.WriteString(" value=")
+	b.WriteString(strconv.FormatFloat(p.Metric.V, 'g', -1, 64))
+	b.WriteString(" ")
+	b.WriteString(strconv.FormatInt(p.Metric.T.UnixNano(), 10))
+	return b.String()
+}
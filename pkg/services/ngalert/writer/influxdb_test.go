@@ -0,0 +1,102 @@
+package writer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+func TestLineProtocol(t *testing.T) {
+	ts := time.Unix(1700000000, 123)
+
+	for _, tc := range []struct {
+		name     string
+		point    Point
+		expected string
+	}{
+		{
+			name:     "without labels",
+			point:    Point{Name: "test", Metric: Metric{T: ts, V: 1.5}},
+			expected: "test value=1.5 1700000000000000123",
+		},
+		{
+			name:     "sorts labels",
+			point:    Point{Name: "test", Labels: map[string]string{"b": "2", "a": "1"}, Metric: Metric{T: ts, V: 3}},
+			expected: "test,a=1,b=2 value=3 1700000000000000123",
+		},
+		{
+			name:     "escapes special characters",
+			point:    Point{Name: "my test,metric", Labels: map[string]string{"a b": "c=d,e"}, Metric: Metric{T: ts, V: -1}},
+			expected: `my\ test\,metric,a\ b=c\=d\,e value=-1 1700000000000000123`,
+		},
+		{
+			name:     "skips empty label values",
+			point:    Point{Name: "test", Labels: map[string]string{"a": "", "b": "1"}, Metric: Metric{T: ts, V: 0}},
+			expected: "test,b=1 value=0 1700000000000000123",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, lineProtocol(tc.point))
+		})
+	}
+}
+
+func TestInfluxDBWriteURL(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		url      string
+		version  string
+		database string
+		jsonData map[string]any
+		expected string
+		err      bool
+	}{
+		{
+			name:     "influxql",
+			url:      "http://localhost:8086",
+			version:  influxQLVersion,
+			database: "metrics",
+			expected: "http://localhost:8086/write?db=metrics&precision=ns",
+		},
+		{
+			name:     "influxql with database in json data",
+			url:      "http://localhost:8086/",
+			version:  influxQLVersion,
+			jsonData: map[string]any{"dbName": "metrics"},
+			expected: "http://localhost:8086/write?db=metrics&precision=ns",
+		},
+		{
+			name:     "flux",
+			url:      "http://localhost:8086",
+			version:  fluxVersion,
+			jsonData: map[string]any{"organization": "my-org", "defaultBucket": "metrics"},
+			expected: "http://localhost:8086/api/v2/write?bucket=metrics&org=my-org&precision=ns",
+		},
+		{
+			name:     "sql",
+			url:      "http://localhost:8181",
+			version:  sqlVersion,
+			jsonData: map[string]any{"dbName": "metrics"},
+			expected: "http://localhost:8181/api/v2/write?bucket=metrics&precision=ns",
+		},
+		{
+			name:    "missing database",
+			url:     "http://localhost:8086",
+			version: influxQLVersion,
+			err:     true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := influxDBWriteURL(tc.url, tc.version, tc.database, simplejson.NewFromAny(tc.jsonData))
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package writer
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	ngstore "github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// GrafanaDatasourceUID is the UID of the built-in Grafana data source. Recording rules that target it write their
+// output to the Grafana database. It matches grafanads.DatasourceUID, which cannot be imported from here.
+const GrafanaDatasourceUID = "grafana"
+
+const (
+	localBackendType      = "grafana"
+	recordingSampleTable  = "alert_recording_sample"
+	localCleanupBatchSize = 1000
+)
+
+// recordingSample is a single sample as it is stored in the alert_recording_sample table.
+type recordingSample struct {
+	ID                int64   `xorm:"pk autoincr 'id'"`
+	OrgID             int64   `xorm:"org_id"`
+	Metric            string  `xorm:"metric"`
+	Labels            string  `xorm:"labels"`
+	LabelsFingerprint string  `xorm:"labels_fingerprint"`
+	SampleValue       float64 `xorm:"sample_value"`
+	SampleTime        int64   `xorm:"sample_time"`
+}
+
+func (recordingSample) TableName() string {
+	return recordingSampleTable
+}
+
+// LocalWriter writes recording rule output to the Grafana database, where it can be queried with the Grafana data source.
+type LocalWriter struct {
+	db      db.DB
+	clock   clock.Clock
+	logger  log.Logger
+	metrics *metrics.RemoteWriter
+	opts    writeOptions
+}
+
+func NewLocalWriter(store db.DB, settings setting.RecordingRuleSettings, clock clock.Clock, l log.Logger, metrics *metrics.RemoteWriter) *LocalWriter {
+	return &LocalWriter{
+		db:      store,
+		clock:   clock,
+		logger:  l,
+		metrics: metrics,
+		opts:    writeOptionsFromSettings(settings),
+	}
+}
+
+// Write stores the given frames in the alert_recording_sample table.
+func (w LocalWriter) Write(ctx context.Context, name string, t time.Time, frames data.Frames, orgID int64, extraLabels map[string]string) error {
+	l := w.logger.FromContext(ctx)
+	lvs := []string{fmt.Sprint(orgID), localBackendType, GrafanaDatasourceUID}
+
+	points, err := PointsFromFrames(name, t, frames, extraLabels)
+	if err != nil {
+		return err
+	}
+
+	samples := make([]recordingSample, 0, len(points))
+	for _, p := range points {
+		// Not every supported database can store NaN or infinite values.
+		if math.IsNaN(p.Metric.V) || math.IsInf(p.Metric.V, 0) {
+			l.Debug("Skipping sample with a value that cannot be stored", "name", name, "labels", p.Labels)
+			continue
+		}
+		labels, err := json.Marshal(p.Labels)
+		if err != nil {
+			return fmt.Errorf("failed to serialize labels: %w", err)
+		}
+		samples = append(samples, recordingSample{
+			OrgID:             orgID,
+			Metric:            name,
+			Labels:            string(labels),
+			LabelsFingerprint: sampleFingerprint(p.Labels),
+			SampleValue:       p.Metric.V,
+			SampleTime:        p.Metric.T.UnixNano(),
+		})
+	}
+	if len(samples) == 0 {
+		return nil
+	}
+
+	l.Debug("Writing metric", "name", name)
+	for _, b := range batch(samples, w.opts.batchSize) {
+		err := withRetry(ctx, w.clock, w.opts, func() {
+			w.metrics.WriteRetries.WithLabelValues(lvs...).Inc()
+		}, func() error {
+			return w.writeSamples(ctx, b, lvs)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w LocalWriter) writeSamples(ctx context.Context, samples []recordingSample, lvs []string) error {
+	writeStart := w.clock.Now()
+	err := w.db.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		_, err := sess.BulkInsert(recordingSampleTable, samples, sqlstore.NativeSettingsForDialect(w.db.GetDialect()))
+		return err
+	})
+	w.metrics.WriteDuration.WithLabelValues(lvs...).Observe(w.clock.Now().Sub(writeStart).Seconds())
+	if err != nil {
+		w.metrics.WritesTotal.WithLabelValues(lvs[0], lvs[1], lvs[2], "500").Inc()
+		// Database errors such as lock timeouts are usually transient.
+		return retryableError{err: fmt.Errorf("failed to write samples: %w", err)}
+	}
+	w.metrics.WritesTotal.WithLabelValues(lvs[0], lvs[1], lvs[2], "200").Inc()
+	return nil
+}
+
+// QueryLocalSamples returns the samples of the given metric that were written to the Grafana database between from and to.
+// Only series that have all the given labels are returned. Every series is returned as a separate frame.
+func QueryLocalSamples(ctx context.Context, store db.DB, orgID int64, metric string, labels map[string]string, from, to time.Time) (data.Frames, error) {
+	type series struct {
+		labels data.Labels
+		times  []time.Time
+		values []float64
+	}
+	var result []*series
+	byFingerprint := make(map[string]*series)
+
+	err := store.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		rows, err := sess.Table(recordingSampleTable).
+			Where("org_id = ?", orgID).
+			And("metric = ?", metric).
+			And("sample_time >= ?", from.UnixNano()).
+			And("sample_time <= ?", to.UnixNano()).
+			Asc("sample_time", "id").
+			Rows(new(recordingSample))
+		if err != nil {
+			return err
+		}
+		defer func() { _ = rows.Close() }()
+		for rows.Next() {
+			var sample recordingSample
+			if err := rows.Scan(&sample); err != nil {
+				return err
+			}
+			s, ok := byFingerprint[sample.LabelsFingerprint]
+			if !ok {
+				var lbls data.Labels
+				if err := json.Unmarshal([]byte(sample.Labels), &lbls); err != nil {
+					return fmt.Errorf("failed to unmarshal labels of sample %d: %w", sample.ID, err)
+				}
+				s = &series{labels: lbls}
+				byFingerprint[sample.LabelsFingerprint] = s
+				result = append(result, s)
+			}
+			s.times = append(s.times, time.Unix(0, sample.SampleTime).UTC())
+			s.values = append(s.values, sample.SampleValue)
+		}
+		// Rows reports sql.ErrNoRows once the result set is exhausted.
+		if err := rows.Err(); err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query recorded samples: %w", err)
+	}
+
+	frames := make(data.Frames, 0, len(result))
+	for _, s := range result {
+		if !hasLabels(s.labels, labels) {
+			continue
+		}
+		frame := data.NewFrame(metric,
+			data.NewField(data.TimeSeriesTimeFieldName, nil, s.times),
+			data.NewField(data.TimeSeriesValueFieldName, s.labels, s.values).SetConfig(&data.FieldConfig{DisplayNameFromDS: metric}),
+		)
+		frame.SetMeta(&data.FrameMeta{Type: data.FrameTypeTimeSeriesMulti, TypeVersion: data.FrameTypeVersion{0, 1}})
+		frames = append(frames, frame)
+	}
+	return frames, nil
+}
+
+// DeleteExpiredLocalSamples removes samples written to the Grafana database before the given time.
+// It returns the number of deleted samples.
+func DeleteExpiredLocalSamples(ctx context.Context, store db.DB, before time.Time) (int64, error) {
+	return ngstore.DeleteInBatches(ctx, store, recordingSampleTable, &recordingSample{}, localCleanupBatchSize, "sample_time < ?", before.UnixNano())
+}
+
+func sampleFingerprint(labels map[string]string) string {
+	return fmt.Sprintf("%016x", model.LabelsToSignature(labels))
+}
+
+func hasLabels(labels data.Labels, filter map[string]string) bool {
+	for k, v := range filter {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,88 @@
+package writer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestIntegrationLocalWriter(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	sqlStore := db.InitTestDB(t)
+	m := metrics.NewRemoteWriterMetrics(prometheus.NewRegistry())
+	w := NewLocalWriter(sqlStore, setting.RecordingRuleSettings{}, clock.New(), log.NewNopLogger(), m)
+	ctx := context.Background()
+
+	start := time.Now().Add(-time.Hour).Truncate(time.Second)
+	series := []map[string]string{{"foo": "1"}, {"foo": "2"}}
+	frames := frameGenFromLabels(t, data.FrameTypeNumericMulti, series)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, w.Write(ctx, "test_metric", start.Add(time.Duration(i)*time.Minute), frames, 1, map[string]string{"extra": "label"}))
+	}
+	require.NoError(t, w.Write(ctx, "other_metric", start, frames, 1, nil))
+	require.NoError(t, w.Write(ctx, "test_metric", start, frames, 2, nil))
+
+	t.Run("returns one frame per series", func(t *testing.T) {
+		result, err := QueryLocalSamples(ctx, sqlStore, 1, "test_metric", nil, start, start.Add(time.Hour))
+		require.NoError(t, err)
+
+		require.Len(t, result, len(series))
+		for _, frame := range result {
+			require.Equal(t, data.FrameTypeTimeSeriesMulti, frame.Meta.Type)
+			require.Equal(t, 3, frame.Rows())
+			require.Equal(t, "label", frame.Fields[1].Labels["extra"])
+			require.Equal(t, extractValue(t, frames, map[string]string{"foo": frame.Fields[1].Labels["foo"]}, data.FrameTypeNumericMulti), frame.Fields[1].At(0))
+			for i := 0; i < 3; i++ {
+				require.True(t, start.Add(time.Duration(i)*time.Minute).Equal(frame.Fields[0].At(i).(time.Time)))
+			}
+		}
+	})
+
+	t.Run("filters by labels and time range", func(t *testing.T) {
+		result, err := QueryLocalSamples(ctx, sqlStore, 1, "test_metric", map[string]string{"foo": "2"}, start.Add(time.Minute), start.Add(time.Hour))
+		require.NoError(t, err)
+
+		require.Len(t, result, 1)
+		require.Equal(t, "2", result[0].Fields[1].Labels["foo"])
+		require.Equal(t, 2, result[0].Rows())
+	})
+
+	t.Run("returns only samples of the organization", func(t *testing.T) {
+		result, err := QueryLocalSamples(ctx, sqlStore, 2, "test_metric", nil, start, start.Add(time.Hour))
+		require.NoError(t, err)
+
+		require.Len(t, result, len(series))
+		for _, frame := range result {
+			require.Equal(t, 1, frame.Rows())
+		}
+	})
+
+	t.Run("deletes expired samples", func(t *testing.T) {
+		deleted, err := DeleteExpiredLocalSamples(ctx, sqlStore, start.Add(time.Minute))
+		require.NoError(t, err)
+		// The first sample of both series of test_metric and other_metric in org 1, and of test_metric in org 2.
+		require.EqualValues(t, 6, deleted)
+
+		result, err := QueryLocalSamples(ctx, sqlStore, 1, "test_metric", nil, start, start.Add(time.Hour))
+		require.NoError(t, err)
+		require.Equal(t, 2, result[0].Rows())
+	})
+}
@@ -21,6 +21,9 @@ import (
 
 const backendType = "prometheus"
 
+// defaultTarget is the metrics label value of the globally configured remote write target.
+const defaultTarget = "default"
+
 const (
 	// Fixed error messages
 	MimirDuplicateTimestampError = "err-mimir-sample-duplicate-timestamp"
@@ -98,6 +101,8 @@ type PrometheusWriter struct {
 	clock   clock.Clock
 	logger  log.Logger
 	metrics *metrics.RemoteWriter
+	target  string
+	opts    writeOptions
 }
 
 func NewPrometheusWriter(
@@ -124,9 +129,14 @@ func NewPrometheusWriter(
 		return nil, err
 	}
 
+	return newPrometheusWriter(settings.URL, cl, settings, defaultTarget, clock, l, metrics)
+}
+
+// newPrometheusWriter creates a writer that sends series to the given remote write URL using the given HTTP client.
+func newPrometheusWriter(writeURL string, cl *http.Client, settings setting.RecordingRuleSettings, target string, clock clock.Clock, l log.Logger, metrics *metrics.RemoteWriter) (*PrometheusWriter, error) {
 	clientCfg := promremote.NewConfig(
 		promremote.UserAgent("grafana-recording-rule"),
-		promremote.WriteURLOption(settings.URL),
+		promremote.WriteURLOption(writeURL),
 		promremote.HTTPClientTimeoutOption(settings.Timeout),
 		promremote.HTTPClientOption(cl),
 	)
@@ -141,6 +151,8 @@ func NewPrometheusWriter(
 		clock:   clock,
 		logger:  l,
 		metrics: metrics,
+		target:  target,
+		opts:    writeOptionsFromSettings(settings),
 	}, nil
 }
 
@@ -173,9 +185,10 @@ func createAuthOpts(username, password string) *httpclient.BasicAuthOptions {
 }
 
 // Write writes the given frames to the Prometheus remote write endpoint.
+// Series are sent in batches, and batches that fail with a server or network error are retried.
 func (w PrometheusWriter) Write(ctx context.Context, name string, t time.Time, frames data.Frames, orgID int64, extraLabels map[string]string) error {
 	l := w.logger.FromContext(ctx)
-	lvs := []string{fmt.Sprint(orgID), backendType}
+	lvs := []string{fmt.Sprint(orgID), backendType, w.target}
 
 	points, err := PointsFromFrames(name, t, frames, extraLabels)
 	if err != nil {
@@ -194,15 +207,32 @@ func (w PrometheusWriter) Write(ctx context.Context, name string, t time.Time, f
 	}
 
 	l.Debug("Writing metric", "name", name)
+	for _, b := range batch(series, w.opts.batchSize) {
+		err := withRetry(ctx, w.clock, w.opts, func() {
+			w.metrics.WriteRetries.WithLabelValues(lvs...).Inc()
+		}, func() error {
+			return w.writeSeries(ctx, l, b, lvs)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w PrometheusWriter) writeSeries(ctx context.Context, l log.Logger, series []promremote.TimeSeries, lvs []string) error {
 	writeStart := w.clock.Now()
 	res, writeErr := w.client.WriteTimeSeries(ctx, series, promremote.WriteOptions{})
 	w.metrics.WriteDuration.WithLabelValues(lvs...).Observe(w.clock.Now().Sub(writeStart).Seconds())
-
-	lvs = append(lvs, fmt.Sprint(res.StatusCode))
-	w.metrics.WritesTotal.WithLabelValues(lvs...).Inc()
+	w.metrics.WritesTotal.WithLabelValues(lvs[0], lvs[1], lvs[2], fmt.Sprint(res.StatusCode)).Inc()
 
 	if err, ignored := checkWriteError(writeErr); err != nil {
-		return fmt.Errorf("failed to write time series: %w", err)
+		err = fmt.Errorf("failed to write time series: %w", err)
+		if isRetryableStatus(writeErr.StatusCode()) {
+			return retryableError{err: err}
+		}
+		return err
 	} else if ignored {
 		l.Debug("Ignored write error", "error", err, "status_code", res.StatusCode)
 	}
@@ -0,0 +1,82 @@
+package writer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/benbjohnson/clock"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// writeOptions controls how a writer splits its output into requests and how failed requests are retried.
+type writeOptions struct {
+	maxAttempts int
+	backoff     time.Duration
+	batchSize   int
+}
+
+func writeOptionsFromSettings(settings setting.RecordingRuleSettings) writeOptions {
+	return writeOptions{
+		maxAttempts: settings.MaxAttempts,
+		backoff:     settings.RetryBackoff,
+		batchSize:   settings.BatchSize,
+	}
+}
+
+// retryableError marks a write error that may go away if the same request is sent again.
+type retryableError struct {
+	err error
+}
+
+func (e retryableError) Error() string {
+	return e.err.Error()
+}
+
+func (e retryableError) Unwrap() error {
+	return e.err
+}
+
+// isRetryableStatus reports whether a write request that failed with the given HTTP status code should be retried.
+// A zero status code means the request did not receive a response at all.
+func isRetryableStatus(code int) bool {
+	return code == 0 || code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+}
+
+// withRetry calls write until it succeeds, fails with an error that is not retryable, or runs out of attempts.
+// The delay between attempts starts at the configured backoff and doubles after every retry.
+func withRetry(ctx context.Context, clk clock.Clock, opts writeOptions, onRetry func(), write func() error) error {
+	backoff := opts.backoff
+	for attempt := 1; ; attempt++ {
+		err := write()
+		var retryable retryableError
+		if err == nil || attempt >= opts.maxAttempts || !errors.As(err, &retryable) {
+			return err
+		}
+		onRetry()
+		if backoff <= 0 {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-clk.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// batch splits items into consecutive chunks of at most size items. A size less than 1 disables batching.
+func batch[T any](items []T, size int) [][]T {
+	if size < 1 || len(items) <= size {
+		return [][]T{items}
+	}
+	result := make([][]T, 0, (len(items)+size-1)/size)
+	for start := 0; start < len(items); start += size {
+		end := min(start+size, len(items))
+		result = append(result, items[start:end])
+	}
+	return result
+}
@@ -3,10 +3,12 @@ package alerting
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
 )
 
 const (
@@ -33,6 +35,7 @@ const (
 	testFileCorrectProperties_t         = "./testdata/templates/correct-properties"
 	testFileCorrectPropertiesWithOrg_t  = "./testdata/templates/correct-properties-with-org"
 	testFileMultipleTs                  = "./testdata/templates/multiple-templates"
+	testFileCorrectProperties_mw        = "./testdata/maintenance_windows/correct-properties"
 )
 
 func TestConfigReader(t *testing.T) {
@@ -159,4 +162,16 @@ func TestConfigReader(t *testing.T) {
 		require.NoError(t, err)
 		require.Len(t, file[0].Templates, 2)
 	})
+	t.Run("a maintenance window file with correct properties should not error", func(t *testing.T) {
+		file, err := configReader.readConfig(ctx, testFileCorrectProperties_mw)
+		require.NoError(t, err)
+		require.Len(t, file[0].MaintenanceWindows, 1)
+		window := file[0].MaintenanceWindows[0]
+		require.Equal(t, int64(1337), window.OrgID)
+		require.Equal(t, "db-upgrades", window.MaintenanceWindow.UID)
+		require.Equal(t, 2*time.Hour, window.MaintenanceWindow.Duration)
+		require.Equal(t, []string{`service="db"`, `severity=~"warning|critical"`}, window.MaintenanceWindow.Matchers)
+		require.Equal(t, models.ProvenanceFile, window.MaintenanceWindow.Provenance)
+		require.Equal(t, []DeleteMaintenanceWindow{{OrgID: 1, UID: "old-window"}}, file[0].DeleteMaintenanceWindows)
+	})
 }
@@ -0,0 +1,72 @@
+package alerting
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
+)
+
+type MaintenanceWindowsProvisioner interface {
+	Provision(ctx context.Context, files []*AlertingFile) error
+	Unprovision(ctx context.Context, files []*AlertingFile) error
+}
+
+type defaultMaintenanceWindowsProvisioner struct {
+	logger                   log.Logger
+	maintenanceWindowService provisioning.MaintenanceWindowService
+}
+
+func NewMaintenanceWindowsProvisioner(logger log.Logger,
+	maintenanceWindowService provisioning.MaintenanceWindowService) MaintenanceWindowsProvisioner {
+	return &defaultMaintenanceWindowsProvisioner{
+		logger:                   logger,
+		maintenanceWindowService: maintenanceWindowService,
+	}
+}
+
+func (c *defaultMaintenanceWindowsProvisioner) Provision(ctx context.Context,
+	files []*AlertingFile) error {
+	cache := map[int64]map[string]struct{}{}
+	for _, file := range files {
+		for _, window := range file.MaintenanceWindows {
+			if _, exists := cache[window.OrgID]; !exists {
+				windows, err := c.maintenanceWindowService.GetMaintenanceWindows(ctx, window.OrgID)
+				if err != nil {
+					return err
+				}
+				cache[window.OrgID] = make(map[string]struct{}, len(windows))
+				for _, w := range windows {
+					cache[window.OrgID][w.UID] = struct{}{}
+				}
+			}
+			if _, exists := cache[window.OrgID][window.MaintenanceWindow.UID]; exists {
+				_, err := c.maintenanceWindowService.UpdateMaintenanceWindow(ctx, window.OrgID, window.MaintenanceWindow)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+			_, err := c.maintenanceWindowService.CreateMaintenanceWindow(ctx, window.OrgID, window.MaintenanceWindow)
+			if err != nil {
+				return err
+			}
+			cache[window.OrgID][window.MaintenanceWindow.UID] = struct{}{}
+		}
+	}
+	return nil
+}
+
+func (c *defaultMaintenanceWindowsProvisioner) Unprovision(ctx context.Context,
+	files []*AlertingFile) error {
+	for _, file := range files {
+		for _, deleteWindow := range file.DeleteMaintenanceWindows {
+			err := c.maintenanceWindowService.DeleteMaintenanceWindow(ctx, deleteWindow.OrgID, deleteWindow.UID, models.ProvenanceFile)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package alerting
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/provisioning/values"
+)
+
+type MaintenanceWindowV1 struct {
+	OrgID    values.Int64Value    `json:"orgId" yaml:"orgId"`
+	UID      values.StringValue   `json:"uid" yaml:"uid"`
+	Title    values.StringValue   `json:"title" yaml:"title"`
+	Schedule values.StringValue   `json:"schedule" yaml:"schedule"`
+	Duration values.StringValue   `json:"duration" yaml:"duration"`
+	TimeZone values.StringValue   `json:"timeZone" yaml:"timeZone"`
+	Matchers []values.StringValue `json:"matchers" yaml:"matchers"`
+	Comment  values.StringValue   `json:"comment" yaml:"comment"`
+}
+
+func (v1 *MaintenanceWindowV1) mapToModel() (MaintenanceWindow, error) {
+	uid := strings.TrimSpace(v1.UID.Value())
+	if uid == "" {
+		return MaintenanceWindow{}, errors.New("maintenance window missing uid")
+	}
+	duration, err := model.ParseDuration(v1.Duration.Value())
+	if err != nil {
+		return MaintenanceWindow{}, fmt.Errorf("maintenance window '%s' has invalid duration: %w", uid, err)
+	}
+	orgID := v1.OrgID.Value()
+	if orgID < 1 {
+		orgID = 1
+	}
+	matchers := make([]string, 0, len(v1.Matchers))
+	for _, m := range v1.Matchers {
+		matchers = append(matchers, m.Value())
+	}
+	return MaintenanceWindow{
+		OrgID: orgID,
+		MaintenanceWindow: models.MaintenanceWindow{
+			UID:        uid,
+			OrgID:      orgID,
+			Title:      v1.Title.Value(),
+			Schedule:   v1.Schedule.Value(),
+			Duration:   time.Duration(duration),
+			TimeZone:   v1.TimeZone.Value(),
+			Matchers:   matchers,
+			Comment:    v1.Comment.Value(),
+			Provenance: models.ProvenanceFile,
+		},
+	}, nil
+}
+
+type MaintenanceWindow struct {
+	OrgID             int64
+	MaintenanceWindow models.MaintenanceWindow
+}
+
+type DeleteMaintenanceWindowV1 struct {
+	OrgID values.Int64Value  `json:"orgId" yaml:"orgId"`
+	UID   values.StringValue `json:"uid" yaml:"uid"`
+}
+
+func (v1 *DeleteMaintenanceWindowV1) mapToModel() (DeleteMaintenanceWindow, error) {
+	uid := strings.TrimSpace(v1.UID.Value())
+	if uid == "" {
+		return DeleteMaintenanceWindow{}, errors.New("delete maintenance window missing uid")
+	}
+	orgID := v1.OrgID.Value()
+	if orgID < 1 {
+		orgID = 1
+	}
+	return DeleteMaintenanceWindow{
+		OrgID: orgID,
+		UID:   uid,
+	}, nil
+}
+
+type DeleteMaintenanceWindow struct {
+	OrgID int64
+	UID   string
+}
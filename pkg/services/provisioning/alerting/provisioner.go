@@ -19,6 +19,7 @@ type ProvisionerConfig struct {
 	NotificiationPolicyService provisioning.NotificationPolicyService
 	MuteTimingService          provisioning.MuteTimingService
 	TemplateService            provisioning.TemplateService
+	MaintenanceWindowService   provisioning.MaintenanceWindowService
 }
 
 func Provision(ctx context.Context, cfg ProvisionerConfig) error {
@@ -71,6 +72,15 @@ func Provision(ctx context.Context, cfg ProvisionerConfig) error {
 	if err != nil {
 		return fmt.Errorf("alert rules: %w", err)
 	}
+	mwProvisioner := NewMaintenanceWindowsProvisioner(logger, cfg.MaintenanceWindowService)
+	err = mwProvisioner.Provision(ctx, files)
+	if err != nil {
+		return fmt.Errorf("maintenance windows: %w", err)
+	}
+	err = mwProvisioner.Unprovision(ctx, files)
+	if err != nil {
+		return fmt.Errorf("maintenance windows: %w", err)
+	}
 	err = cpProvisioner.Unprovision(ctx, files) // Unprovision contact points after rules to make sure all references in rules are updated
 	if err != nil {
 		return fmt.Errorf("contact points: %w", err)
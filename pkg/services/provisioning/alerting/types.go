@@ -26,6 +26,9 @@ type AlertingFile struct {
 	DeleteMuteTimes     []DeleteMuteTime
 	Templates           []Template
 	DeleteTemplates     []DeleteTemplate
+
+	MaintenanceWindows       []MaintenanceWindow
+	DeleteMaintenanceWindows []DeleteMaintenanceWindow
 }
 
 type AlertingFileV1 struct {
@@ -41,6 +44,9 @@ type AlertingFileV1 struct {
 	DeleteMuteTimes     []DeleteMuteTimeV1      `json:"deleteMuteTimes" yaml:"deleteMuteTimes"`
 	Templates           []TemplateV1            `json:"templates" yaml:"templates"`
 	DeleteTemplates     []DeleteTemplateV1      `json:"deleteTemplates" yaml:"deleteTemplates"`
+
+	MaintenanceWindows       []MaintenanceWindowV1       `json:"maintenanceWindows" yaml:"maintenanceWindows"`
+	DeleteMaintenanceWindows []DeleteMaintenanceWindowV1 `json:"deleteMaintenanceWindows" yaml:"deleteMaintenanceWindows"`
 }
 
 func (fileV1 *AlertingFileV1) MapToModel() (AlertingFile, error) {
@@ -61,6 +67,9 @@ func (fileV1 *AlertingFileV1) MapToModel() (AlertingFile, error) {
 	if err := fileV1.mapTemplates(&alertingFile); err != nil {
 		return AlertingFile{}, fmt.Errorf("failure parsing templates: %w", err)
 	}
+	if err := fileV1.mapMaintenanceWindows(&alertingFile); err != nil {
+		return AlertingFile{}, fmt.Errorf("failure parsing maintenance windows: %w", err)
+	}
 	return alertingFile, nil
 }
 
@@ -78,6 +87,24 @@ func (fileV1 *AlertingFileV1) mapTemplates(alertingFile *AlertingFile) error {
 	return nil
 }
 
+func (fileV1 *AlertingFileV1) mapMaintenanceWindows(alertingFile *AlertingFile) error {
+	for _, mwV1 := range fileV1.MaintenanceWindows {
+		mw, err := mwV1.mapToModel()
+		if err != nil {
+			return err
+		}
+		alertingFile.MaintenanceWindows = append(alertingFile.MaintenanceWindows, mw)
+	}
+	for _, deleteV1 := range fileV1.DeleteMaintenanceWindows {
+		delReq, err := deleteV1.mapToModel()
+		if err != nil {
+			return err
+		}
+		alertingFile.DeleteMaintenanceWindows = append(alertingFile.DeleteMaintenanceWindows, delReq)
+	}
+	return nil
+}
+
 func (fileV1 *AlertingFileV1) mapMuteTimes(alertingFile *AlertingFile) error {
 	for _, mtV1 := range fileV1.MuteTimes {
 		alertingFile.MuteTimes = append(alertingFile.MuteTimes, mtV1.mapToModel())
@@ -19,6 +19,7 @@ type parsedQuery struct {
 
 type parsedRequest struct {
 	hasExpression bool
+	debug         bool
 	parsedQueries map[string][]parsedQuery
 	dsTypes       map[string]bool
 }
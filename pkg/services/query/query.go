@@ -202,6 +202,7 @@ func buildErrorResponses(err error, queries []*simplejson.Json) splitResponse {
 // handleExpressions handles POST /api/ds/query when there is an expression.
 func (s *ServiceImpl) handleExpressions(ctx context.Context, user identity.Requester, parsedReq *parsedRequest) (*backend.QueryDataResponse, error) {
 	exprReq := expr.Request{
+		Debug:   parsedReq.debug,
 		Queries: []expr.Query{},
 	}
 
@@ -281,6 +282,7 @@ func (s *ServiceImpl) parseMetricRequest(ctx context.Context, user identity.Requ
 	timeRange := gtime.NewTimeRange(reqDTO.From, reqDTO.To)
 	req := &parsedRequest{
 		hasExpression: false,
+		debug:         reqDTO.Debug,
 		parsedQueries: make(map[string][]parsedQuery),
 		dsTypes:       make(map[string]bool),
 	}
@@ -384,6 +384,44 @@ func TestQueryDataMultipleSources(t *testing.T) {
 		require.NoError(t, err)
 	})
 
+	t.Run("debug adds the trace of the expressions to the response", func(t *testing.T) {
+		tc := setup(t)
+		query1, err := simplejson.NewJson([]byte(`
+			{
+				"datasource": {
+					"type": "mysql",
+					"uid": "ds1"
+				},
+				"refId": "A"
+			}
+		`))
+		require.NoError(t, err)
+		query2, err := simplejson.NewJson([]byte(`
+			{
+				"datasource": {
+					"name": "Expression",
+					"type": "__expr__",
+					"uid": "__expr__"
+				},
+				"expression": "$A + 1",
+				"refId": "EXPRESSION",
+				"type": "math"
+			}
+		`))
+		require.NoError(t, err)
+		reqDTO := dtos.MetricRequest{
+			From:    "2022-01-01",
+			To:      "2022-01-02",
+			Queries: []*simplejson.Json{query1, query2},
+			Debug:   true,
+		}
+
+		res, err := tc.queryService.QueryData(context.Background(), tc.signedInUser, true, reqDTO)
+		require.NoError(t, err)
+		require.Contains(t, res.Responses, expr.TraceRefID)
+		require.Equal(t, 2, res.Responses[expr.TraceRefID].Frames[0].Rows())
+	})
+
 	t.Run("error is returned in query when one of the queries fails", func(t *testing.T) {
 		tc := setup(t)
 
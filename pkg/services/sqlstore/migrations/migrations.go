@@ -126,6 +126,20 @@ func (oss *OSSMigrations) AddMigration(mg *Migrator) {
 
 	ualert.AddStateResolvedAtColumns(mg)
 
+	ualert.AddKeepFiringForColumns(mg)
+
+	ualert.AddRuleDependsOnColumns(mg)
+
+	ualert.AddStateHistoryTable(mg)
+
+	ualert.AddRecordingSampleTable(mg)
+	ualert.AddRecordingRuleBackfillTable(mg)
+	ualert.AddMaintenanceWindowTable(mg)
+	ualert.AddEscalationTables(mg)
+	ualert.AddAlertInstanceAcknowledgementTable(mg)
+	ualert.AddRuleVersionAuthorColumns(mg)
+	ualert.AddAlertRuleOwnerTable(mg)
+
 	enableTraceQLStreaming(mg, oss.features != nil && oss.features.IsEnabledGlobally(featuremgmt.FlagTraceQLStreaming))
 }
 
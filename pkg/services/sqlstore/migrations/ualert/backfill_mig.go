@@ -0,0 +1,44 @@
+package ualert
+
+import (
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+// AddRecordingRuleBackfillTable creates the table that keeps track of recording rule backfill jobs.
+func AddRecordingRuleBackfillTable(mg *migrator.Migrator) {
+	backfill := migrator.Table{
+		Name: "alert_rule_backfill",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "uid", Type: migrator.DB_NVarchar, Length: UIDMaxLength, Nullable: false},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "rule_uid", Type: migrator.DB_NVarchar, Length: UIDMaxLength, Nullable: false},
+			{Name: "range_start", Type: migrator.DB_DateTime, Nullable: false},
+			{Name: "range_end", Type: migrator.DB_DateTime, Nullable: false},
+			{Name: "next_evaluation", Type: migrator.DB_DateTime, Nullable: false},
+			{Name: "state", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "error_message", Type: migrator.DB_Text, Nullable: true},
+			{Name: "created", Type: migrator.DB_DateTime, Nullable: false},
+			{Name: "updated", Type: migrator.DB_DateTime, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "uid"}, Type: migrator.UniqueIndex},
+			{Cols: []string{"org_id", "rule_uid"}, Type: migrator.IndexType},
+			{Cols: []string{"state"}, Type: migrator.IndexType},
+		},
+	}
+
+	mg.AddMigration("create alert_rule_backfill table", migrator.NewAddTableMigration(backfill))
+	mg.AddMigration("add unique index alert_rule_backfill org_id, uid", migrator.NewAddIndexMigration(backfill, backfill.Indices[0]))
+	mg.AddMigration("add index alert_rule_backfill org_id, rule_uid", migrator.NewAddIndexMigration(backfill, backfill.Indices[1]))
+	mg.AddMigration("add index alert_rule_backfill state", migrator.NewAddIndexMigration(backfill, backfill.Indices[2]))
+
+	// The owner and the heartbeat make sure that only one Grafana instance runs a backfill, and that another
+	// instance takes it over when its owner stops.
+	mg.AddMigration("add owner column to alert_rule_backfill", migrator.NewAddColumnMigration(backfill, &migrator.Column{
+		Name: "owner", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false, Default: "''",
+	}))
+	mg.AddMigration("add heartbeat column to alert_rule_backfill", migrator.NewAddColumnMigration(backfill, &migrator.Column{
+		Name: "heartbeat", Type: migrator.DB_DateTime, Nullable: true,
+	}))
+}
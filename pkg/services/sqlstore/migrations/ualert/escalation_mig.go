@@ -0,0 +1,59 @@
+package ualert
+
+import (
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+// AddEscalationTables creates the tables that store the escalation chains and the escalations of alert groups.
+func AddEscalationTables(mg *migrator.Migrator) {
+	chain := migrator.Table{
+		Name: "alert_escalation_chain",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "uid", Type: migrator.DB_NVarchar, Length: UIDMaxLength, Nullable: false},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "title", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "receiver", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "steps", Type: migrator.DB_Text, Nullable: false},
+			{Name: "updated", Type: migrator.DB_DateTime, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "uid"}, Type: migrator.UniqueIndex},
+			{Cols: []string{"org_id", "title"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create alert_escalation_chain table", migrator.NewAddTableMigration(chain))
+	mg.AddMigration("add unique index alert_escalation_chain org_id, uid", migrator.NewAddIndexMigration(chain, chain.Indices[0]))
+	mg.AddMigration("add unique index alert_escalation_chain org_id, title", migrator.NewAddIndexMigration(chain, chain.Indices[1]))
+
+	escalation := migrator.Table{
+		Name: "alert_group_escalation",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "group_key", Type: migrator.DB_NVarchar, Length: UIDMaxLength, Nullable: false},
+			{Name: "receiver", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "group_labels", Type: migrator.DB_Text, Nullable: false},
+			{Name: "chain_uid", Type: migrator.DB_NVarchar, Length: UIDMaxLength, Nullable: true},
+			{Name: "step", Type: migrator.DB_Int, Nullable: false, Default: "0"},
+			{Name: "started_at", Type: migrator.DB_DateTime, Nullable: false},
+			{Name: "escalated_at", Type: migrator.DB_DateTime, Nullable: false},
+			{Name: "acknowledged", Type: migrator.DB_Bool, Nullable: false, Default: "0"},
+			{Name: "acknowledged_by", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: true},
+			{Name: "acknowledged_at", Type: migrator.DB_DateTime, Nullable: true},
+			{Name: "comment", Type: migrator.DB_Text, Nullable: true},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "group_key"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create alert_group_escalation table", migrator.NewAddTableMigration(escalation))
+	mg.AddMigration("add unique index alert_group_escalation org_id, group_key", migrator.NewAddIndexMigration(escalation, escalation.Indices[0]))
+
+	// The escalation of an alert group is deleted only after no instance has seen the group firing for a while.
+	mg.AddMigration("add last_seen_at column to alert_group_escalation", migrator.NewAddColumnMigration(escalation, &migrator.Column{
+		Name: "last_seen_at", Type: migrator.DB_DateTime, Nullable: true,
+	}))
+}
@@ -0,0 +1,34 @@
+package ualert
+
+import (
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+// AddAlertInstanceAcknowledgementTable creates the table that stores who handles an alert instance. It has the same
+// primary key as the alert_instance table.
+func AddAlertInstanceAcknowledgementTable(mg *migrator.Migrator) {
+	acknowledgement := migrator.Table{
+		Name: "alert_instance_acknowledgement",
+		Columns: []*migrator.Column{
+			{Name: "rule_org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "rule_uid", Type: migrator.DB_NVarchar, Length: UIDMaxLength, Nullable: false},
+			{Name: "labels_hash", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "acknowledged", Type: migrator.DB_Bool, Nullable: false, Default: "0"},
+			{Name: "acknowledged_by", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: true},
+			{Name: "acknowledged_at", Type: migrator.DB_DateTime, Nullable: true},
+			{Name: "expires_at", Type: migrator.DB_DateTime, Nullable: true},
+			{Name: "assignee", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: true},
+			{Name: "comment", Type: migrator.DB_Text, Nullable: true},
+			{Name: "updated", Type: migrator.DB_DateTime, Nullable: false},
+			{Name: "updated_by", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: true},
+		},
+		PrimaryKeys: []string{"rule_org_id", "rule_uid", "labels_hash"},
+	}
+
+	mg.AddMigration("create alert_instance_acknowledgement table", migrator.NewAddTableMigration(acknowledgement))
+
+	// Acknowledged alert instances are silenced instead of labelled, so that their labels do not change.
+	mg.AddMigration("add silence_id column to alert_instance_acknowledgement", migrator.NewAddColumnMigration(acknowledgement, &migrator.Column{
+		Name: "silence_id", Type: migrator.DB_NVarchar, Length: UIDMaxLength, Nullable: false, Default: "''",
+	}))
+}
@@ -0,0 +1,34 @@
+package ualert
+
+import (
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+// AddMaintenanceWindowTable creates the table that stores the recurring maintenance windows that silence alerts.
+func AddMaintenanceWindowTable(mg *migrator.Migrator) {
+	window := migrator.Table{
+		Name: "alert_maintenance_window",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "uid", Type: migrator.DB_NVarchar, Length: UIDMaxLength, Nullable: false},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "title", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "schedule", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "duration", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "time_zone", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: true},
+			{Name: "matchers", Type: migrator.DB_Text, Nullable: false},
+			{Name: "comment", Type: migrator.DB_Text, Nullable: true},
+			{Name: "updated", Type: migrator.DB_DateTime, Nullable: false},
+			{Name: "last_occurrence", Type: migrator.DB_BigInt, Nullable: false, Default: "0"},
+			{Name: "silence_id", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: true},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "uid"}, Type: migrator.UniqueIndex},
+			{Cols: []string{"org_id", "title"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create alert_maintenance_window table", migrator.NewAddTableMigration(window))
+	mg.AddMigration("add unique index alert_maintenance_window org_id, uid", migrator.NewAddIndexMigration(window, window.Indices[0]))
+	mg.AddMigration("add unique index alert_maintenance_window org_id, title", migrator.NewAddIndexMigration(window, window.Indices[1]))
+}
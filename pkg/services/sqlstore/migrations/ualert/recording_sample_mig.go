@@ -0,0 +1,29 @@
+package ualert
+
+import (
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+// AddRecordingSampleTable creates the table that recording rules targeting the Grafana database write their samples to.
+func AddRecordingSampleTable(mg *migrator.Migrator) {
+	sample := migrator.Table{
+		Name: "alert_recording_sample",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "metric", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "labels", Type: migrator.DB_Text, Nullable: false},
+			{Name: "labels_fingerprint", Type: migrator.DB_NVarchar, Length: 16, Nullable: false},
+			{Name: "sample_value", Type: migrator.DB_Double, Nullable: false},
+			{Name: "sample_time", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "metric", "sample_time"}, Type: migrator.IndexType},
+			{Cols: []string{"sample_time"}, Type: migrator.IndexType},
+		},
+	}
+
+	mg.AddMigration("create alert_recording_sample table", migrator.NewAddTableMigration(sample))
+	mg.AddMigration("add index alert_recording_sample org_id, metric, sample_time", migrator.NewAddIndexMigration(sample, sample.Indices[0]))
+	mg.AddMigration("add index alert_recording_sample sample_time", migrator.NewAddIndexMigration(sample, sample.Indices[1]))
+}
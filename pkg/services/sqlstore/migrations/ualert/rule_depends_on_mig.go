@@ -0,0 +1,20 @@
+package ualert
+
+import (
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+// AddRuleDependsOnColumns creates a column for the rules an alert rule depends on in the alert_rule and alert_rule_version tables.
+func AddRuleDependsOnColumns(mg *migrator.Migrator) {
+	mg.AddMigration("add depends_on column to alert_rule table", migrator.NewAddColumnMigration(migrator.Table{Name: "alert_rule"}, &migrator.Column{
+		Name:     "depends_on",
+		Type:     migrator.DB_Text,
+		Nullable: true,
+	}))
+
+	mg.AddMigration("add depends_on column to alert_rule_version table", migrator.NewAddColumnMigration(migrator.Table{Name: "alert_rule_version"}, &migrator.Column{
+		Name:     "depends_on",
+		Type:     migrator.DB_Text,
+		Nullable: true,
+	}))
+}
@@ -0,0 +1,22 @@
+package ualert
+
+import (
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+// AddAlertRuleOwnerTable creates the table that records which member of the high availability cluster evaluates each
+// alert rule when the evaluation of rules is split between the members.
+func AddAlertRuleOwnerTable(mg *migrator.Migrator) {
+	owner := migrator.Table{
+		Name: "alert_rule_owner",
+		Columns: []*migrator.Column{
+			{Name: "rule_org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "rule_uid", Type: migrator.DB_NVarchar, Length: UIDMaxLength, Nullable: false},
+			{Name: "owner", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false, Default: "''"},
+			{Name: "updated", Type: migrator.DB_DateTime, Nullable: false},
+		},
+		PrimaryKeys: []string{"rule_org_id", "rule_uid"},
+	}
+
+	mg.AddMigration("create alert_rule_owner table", migrator.NewAddTableMigration(owner))
+}
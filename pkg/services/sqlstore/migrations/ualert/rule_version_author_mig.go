@@ -0,0 +1,37 @@
+package ualert
+
+import (
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+// AddRuleVersionAuthorColumns creates columns for the user that last changed an alert rule in the alert_rule and
+// alert_rule_version tables, and for the message that describes the change in the alert_rule_version table. It also
+// creates a column that tells whether the version recorded if the rule was paused, which earlier versions did not.
+func AddRuleVersionAuthorColumns(mg *migrator.Migrator) {
+	mg.AddMigration("add updated_by column to alert_rule table", migrator.NewAddColumnMigration(migrator.Table{Name: "alert_rule"}, &migrator.Column{
+		Name:     "updated_by",
+		Type:     migrator.DB_NVarchar,
+		Length:   DefaultFieldMaxLength,
+		Nullable: true,
+	}))
+
+	mg.AddMigration("add updated_by column to alert_rule_version table", migrator.NewAddColumnMigration(migrator.Table{Name: "alert_rule_version"}, &migrator.Column{
+		Name:     "updated_by",
+		Type:     migrator.DB_NVarchar,
+		Length:   DefaultFieldMaxLength,
+		Nullable: true,
+	}))
+
+	mg.AddMigration("add message column to alert_rule_version table", migrator.NewAddColumnMigration(migrator.Table{Name: "alert_rule_version"}, &migrator.Column{
+		Name:     "message",
+		Type:     migrator.DB_Text,
+		Nullable: true,
+	}))
+
+	mg.AddMigration("add has_pause column to alert_rule_version table", migrator.NewAddColumnMigration(migrator.Table{Name: "alert_rule_version"}, &migrator.Column{
+		Name:     "has_pause",
+		Type:     migrator.DB_Bool,
+		Nullable: false,
+		Default:  "0",
+	}))
+}
@@ -64,7 +64,18 @@ const (
 	stateHistoryDefaultEnabled     = true
 	lokiDefaultMaxQueryLength      = 721 * time.Hour // 30d1h, matches the default value in Loki
 	defaultRecordingRequestTimeout = 10 * time.Second
+	defaultRecordingMaxAttempts    = 3
+	defaultRecordingRetryBackoff   = 500 * time.Millisecond
+	defaultRecordingBatchSize      = 1000
+	defaultRecordingLocalMaxAge    = "30d"
+	defaultRecordingBackfillRate   = 10.0
+	defaultRecordingBackfillRange  = "90d"
 	lokiDefaultMaxQuerySize        = 65536 // 64kb
+	sqlStateHistoryDefaultMaxAge   = "30d"
+	stateEventsDefaultQueueSize    = 1000
+	stateEventsDefaultTimeout      = 10 * time.Second
+	stateEventsDefaultMaxAttempts  = 3
+	stateEventsDefaultRetryBackoff = time.Second
 )
 
 type UnifiedAlertingSettings struct {
@@ -90,6 +101,7 @@ type UnifiedAlertingSettings struct {
 	HARedisMaxConns                 int
 	HARedisTLSEnabled               bool
 	HARedisTLSConfig                dstls.ClientConfig
+	HAEvaluationSharding            bool
 	MaxAttempts                     int64
 	MinInterval                     time.Duration
 	EvaluationTimeout               time.Duration
@@ -108,6 +120,8 @@ type UnifiedAlertingSettings struct {
 	ReservedLabels                UnifiedAlertingReservedLabelSettings
 	SkipClustering                bool
 	StateHistory                  UnifiedAlertingStateHistorySettings
+	StateEvents                   UnifiedAlertingStateEventsSettings
+	EvaluationLimits              UnifiedAlertingEvaluationLimitsSettings
 	RemoteAlertmanager            RemoteAlertmanagerSettings
 	RecordingRules                RecordingRuleSettings
 
@@ -130,6 +144,21 @@ type RecordingRuleSettings struct {
 	BasicAuthPassword string
 	CustomHeaders     map[string]string
 	Timeout           time.Duration
+	// DefaultDatasourceUID is the data source that recording rules without a target data source write to.
+	// If empty, rules without a target write to URL.
+	DefaultDatasourceUID string
+	// MaxAttempts is the maximum number of attempts of a single write request.
+	MaxAttempts int
+	// RetryBackoff is the delay before the first retry of a failed write. It doubles with every retry.
+	RetryBackoff time.Duration
+	// BatchSize is the maximum number of series sent in a single write request.
+	BatchSize int
+	// LocalMaxAge is how long samples written to the local Grafana database are kept. Zero keeps them forever.
+	LocalMaxAge time.Duration
+	// BackfillRate is the maximum number of evaluations per second of all backfills together.
+	BackfillRate float64
+	// BackfillMaxRange is the longest time range a single backfill can cover.
+	BackfillMaxRange time.Duration
 }
 
 // RemoteAlertmanagerSettings contains the configuration needed
@@ -169,6 +198,65 @@ type UnifiedAlertingStateHistorySettings struct {
 	MultiPrimary          string
 	MultiSecondaries      []string
 	ExternalLabels        map[string]string
+	// SQLMaxAge is how long state history written by the "sql" backend is kept. Zero keeps it forever.
+	SQLMaxAge time.Duration
+}
+
+type UnifiedAlertingStateEventsSettings struct {
+	Enabled bool
+	// Sinks are the names of the sinks that receive the state changes of alert instances.
+	Sinks []string
+	// QueueSize is the maximum number of batches of events waiting to be sent to a single sink.
+	QueueSize  int
+	WebhookURL string
+	// WebhookSecret is the key of the HMAC signature of the requests. Requests are not signed if it is empty.
+	WebhookSecret       string
+	WebhookTimeout      time.Duration
+	WebhookMaxAttempts  int
+	WebhookRetryBackoff time.Duration
+	FilePath            string
+}
+
+// EvaluationLimit limits the evaluations of rules that query the same data source or belong to the same folder.
+// A zero value of a field means that there is no limit.
+type EvaluationLimit struct {
+	// MaxConcurrent is the maximum number of rules evaluated at the same time.
+	MaxConcurrent int
+	// Rate is the maximum number of evaluations started per second.
+	Rate float64
+	// Burst is the number of evaluations that can be started at once when Rate is set.
+	Burst int
+}
+
+// IsUnlimited returns true if the limit does not restrict evaluations.
+func (l EvaluationLimit) IsUnlimited() bool {
+	return l.MaxConcurrent == 0 && l.Rate == 0
+}
+
+type UnifiedAlertingEvaluationLimitsSettings struct {
+	// Datasource is the limit of every data source that does not have an override in Datasources.
+	Datasource EvaluationLimit
+	// Folder is the limit of every folder that does not have an override in Folders.
+	Folder EvaluationLimit
+	// Datasources are the limits of individual data sources by UID.
+	Datasources map[string]EvaluationLimit
+	// Folders are the limits of individual folders by UID.
+	Folders map[string]EvaluationLimit
+}
+
+// IsUnlimited returns true if none of the limits restrict evaluations.
+func (s UnifiedAlertingEvaluationLimitsSettings) IsUnlimited() bool {
+	for _, l := range s.Datasources {
+		if !l.IsUnlimited() {
+			return false
+		}
+	}
+	for _, l := range s.Folders {
+		if !l.IsUnlimited() {
+			return false
+		}
+	}
+	return s.Datasource.IsUnlimited() && s.Folder.IsUnlimited()
 }
 
 // IsEnabled returns true if UnifiedAlertingSettings.Enabled is either nil or true.
@@ -274,6 +362,7 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 	uaCfg.HARedisTLSConfig.KeyPath = ua.Key("ha_redis_tls_key_path").MustString("")
 	uaCfg.HARedisTLSConfig.CAPath = ua.Key("ha_redis_tls_ca_path").MustString("")
 	uaCfg.HARedisTLSConfig.ServerName = ua.Key("ha_redis_tls_server_name").MustString("")
+	uaCfg.HAEvaluationSharding = ua.Key("ha_evaluation_sharding").MustBool(false)
 	uaCfg.HARedisTLSConfig.InsecureSkipVerify = ua.Key("ha_redis_tls_insecure_skip_verify").MustBool(false)
 	uaCfg.HARedisTLSConfig.CipherSuites = ua.Key("ha_redis_tls_cipher_suites").MustString("")
 	uaCfg.HARedisTLSConfig.MinVersion = ua.Key("ha_redis_tls_min_version").MustString("")
@@ -418,15 +507,69 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 		MultiSecondaries:      splitTrim(stateHistory.Key("secondaries").MustString(""), ","),
 		ExternalLabels:        stateHistoryLabels.KeysHash(),
 	}
+	stateHistorySQL := iniFile.Section("unified_alerting.state_history.sql")
+	uaCfgStateHistory.SQLMaxAge, err = gtime.ParseDuration(stateHistorySQL.Key("max_age").MustString(sqlStateHistoryDefaultMaxAge))
+	if err != nil {
+		return fmt.Errorf("failed to parse setting 'max_age' in section [unified_alerting.state_history.sql]: %w", err)
+	}
 	uaCfg.StateHistory = uaCfgStateHistory
 
+	stateEvents := iniFile.Section("unified_alerting.state_events")
+	stateEventsWebhook := iniFile.Section("unified_alerting.state_events.webhook")
+	stateEventsFile := iniFile.Section("unified_alerting.state_events.file")
+	uaCfgStateEvents := UnifiedAlertingStateEventsSettings{
+		Enabled:             stateEvents.Key("enabled").MustBool(false),
+		Sinks:               util.SplitString(stateEvents.Key("sinks").MustString("")),
+		QueueSize:           stateEvents.Key("queue_size").MustInt(stateEventsDefaultQueueSize),
+		WebhookURL:          stateEventsWebhook.Key("url").MustString(""),
+		WebhookSecret:       stateEventsWebhook.Key("secret").MustString(""),
+		WebhookTimeout:      stateEventsWebhook.Key("timeout").MustDuration(stateEventsDefaultTimeout),
+		WebhookMaxAttempts:  stateEventsWebhook.Key("max_attempts").MustInt(stateEventsDefaultMaxAttempts),
+		WebhookRetryBackoff: stateEventsWebhook.Key("retry_backoff").MustDuration(stateEventsDefaultRetryBackoff),
+		FilePath:            stateEventsFile.Key("path").MustString(""),
+	}
+	if uaCfgStateEvents.QueueSize <= 0 {
+		return fmt.Errorf("setting 'queue_size' in section [unified_alerting.state_events] must be greater than 0")
+	}
+	if uaCfgStateEvents.WebhookMaxAttempts <= 0 {
+		return fmt.Errorf("setting 'max_attempts' in section [unified_alerting.state_events.webhook] must be greater than 0")
+	}
+	uaCfg.StateEvents = uaCfgStateEvents
+
+	uaCfg.EvaluationLimits, err = readEvaluationLimitsSettings(iniFile)
+	if err != nil {
+		return err
+	}
+
 	rr := iniFile.Section("recording_rules")
 	uaCfgRecordingRules := RecordingRuleSettings{
-		Enabled:           rr.Key("enabled").MustBool(false),
-		URL:               rr.Key("url").MustString(""),
-		BasicAuthUsername: rr.Key("basic_auth_username").MustString(""),
-		BasicAuthPassword: rr.Key("basic_auth_password").MustString(""),
-		Timeout:           rr.Key("timeout").MustDuration(defaultRecordingRequestTimeout),
+		Enabled:              rr.Key("enabled").MustBool(false),
+		URL:                  rr.Key("url").MustString(""),
+		BasicAuthUsername:    rr.Key("basic_auth_username").MustString(""),
+		BasicAuthPassword:    rr.Key("basic_auth_password").MustString(""),
+		Timeout:              rr.Key("timeout").MustDuration(defaultRecordingRequestTimeout),
+		DefaultDatasourceUID: rr.Key("default_datasource_uid").MustString(""),
+		MaxAttempts:          rr.Key("max_attempts").MustInt(defaultRecordingMaxAttempts),
+		RetryBackoff:         rr.Key("retry_backoff").MustDuration(defaultRecordingRetryBackoff),
+		BatchSize:            rr.Key("batch_size").MustInt(defaultRecordingBatchSize),
+		BackfillRate:         rr.Key("backfill_rate").MustFloat64(defaultRecordingBackfillRate),
+	}
+	uaCfgRecordingRules.LocalMaxAge, err = gtime.ParseDuration(rr.Key("local_max_age").MustString(defaultRecordingLocalMaxAge))
+	if err != nil {
+		return fmt.Errorf("failed to parse setting 'local_max_age' in section [recording_rules]: %w", err)
+	}
+	uaCfgRecordingRules.BackfillMaxRange, err = gtime.ParseDuration(rr.Key("backfill_max_range").MustString(defaultRecordingBackfillRange))
+	if err != nil {
+		return fmt.Errorf("failed to parse setting 'backfill_max_range' in section [recording_rules]: %w", err)
+	}
+	if uaCfgRecordingRules.BackfillRate <= 0 {
+		return fmt.Errorf("setting 'backfill_rate' in section [recording_rules] must be greater than 0")
+	}
+	if uaCfgRecordingRules.MaxAttempts <= 0 {
+		return fmt.Errorf("setting 'max_attempts' in section [recording_rules] must be greater than 0")
+	}
+	if uaCfgRecordingRules.BatchSize <= 0 {
+		return fmt.Errorf("setting 'batch_size' in section [recording_rules] must be greater than 0")
 	}
 
 	rrHeaders := iniFile.Section("recording_rules.custom_headers")
@@ -470,3 +613,85 @@ func splitTrim(s string, sep string) []string {
 	}
 	return spl
 }
+
+func readEvaluationLimitsSettings(iniFile *ini.File) (UnifiedAlertingEvaluationLimitsSettings, error) {
+	const section = "unified_alerting.evaluation_limits"
+	limits := iniFile.Section(section)
+	result := UnifiedAlertingEvaluationLimitsSettings{
+		Datasource: EvaluationLimit{
+			MaxConcurrent: limits.Key("datasource_max_concurrent").MustInt(0),
+			Rate:          limits.Key("datasource_rate").MustFloat64(0),
+			Burst:         limits.Key("datasource_burst").MustInt(0),
+		},
+		Folder: EvaluationLimit{
+			MaxConcurrent: limits.Key("folder_max_concurrent").MustInt(0),
+			Rate:          limits.Key("folder_rate").MustFloat64(0),
+			Burst:         limits.Key("folder_burst").MustInt(0),
+		},
+		Datasources: make(map[string]EvaluationLimit),
+		Folders:     make(map[string]EvaluationLimit),
+	}
+	var err error
+	if result.Datasource, err = normalizeEvaluationLimit(result.Datasource); err != nil {
+		return result, fmt.Errorf("invalid data source limit in section [%s]: %w", section, err)
+	}
+	if result.Folder, err = normalizeEvaluationLimit(result.Folder); err != nil {
+		return result, fmt.Errorf("invalid folder limit in section [%s]: %w", section, err)
+	}
+
+	overrides := []struct {
+		section  string
+		defaults EvaluationLimit
+		result   map[string]EvaluationLimit
+	}{
+		{section: section + ".datasources", defaults: result.Datasource, result: result.Datasources},
+		{section: section + ".folders", defaults: result.Folder, result: result.Folders},
+	}
+	for _, o := range overrides {
+		for uid, value := range iniFile.Section(o.section).KeysHash() {
+			l, err := parseEvaluationLimit(value, o.defaults)
+			if err != nil {
+				return result, fmt.Errorf("invalid limit of %q in section [%s]: %w", uid, o.section, err)
+			}
+			o.result[uid] = l
+		}
+	}
+	return result, nil
+}
+
+// parseEvaluationLimit parses a space-separated list of key=value pairs, for example "max_concurrent=5 rate=2 burst=4".
+// Fields that are not in the list are taken from defaults.
+func parseEvaluationLimit(s string, defaults EvaluationLimit) (EvaluationLimit, error) {
+	l := defaults
+	for _, field := range strings.Fields(s) {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			return l, fmt.Errorf("expected key=value, got %q", field)
+		}
+		var err error
+		switch key {
+		case "max_concurrent":
+			l.MaxConcurrent, err = strconv.Atoi(value)
+		case "rate":
+			l.Rate, err = strconv.ParseFloat(value, 64)
+		case "burst":
+			l.Burst, err = strconv.Atoi(value)
+		default:
+			return l, fmt.Errorf("unknown key %q", key)
+		}
+		if err != nil {
+			return l, fmt.Errorf("invalid value of %q: %w", key, err)
+		}
+	}
+	return normalizeEvaluationLimit(l)
+}
+
+func normalizeEvaluationLimit(l EvaluationLimit) (EvaluationLimit, error) {
+	if l.MaxConcurrent < 0 || l.Rate < 0 || l.Burst < 0 {
+		return l, fmt.Errorf("max_concurrent, rate and burst must not be negative")
+	}
+	if l.Rate > 0 && l.Burst == 0 {
+		l.Burst = 1
+	}
+	return l, nil
+}
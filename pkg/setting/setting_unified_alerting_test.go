@@ -346,3 +346,74 @@ func TestHARedisTLSSettings(t *testing.T) {
 	require.Equal(t, cipherSuites, cfg.UnifiedAlerting.HARedisTLSConfig.CipherSuites)
 	require.Equal(t, minVersion, cfg.UnifiedAlerting.HARedisTLSConfig.MinVersion)
 }
+
+func TestEvaluationLimitsSettings(t *testing.T) {
+	t.Run("should be unlimited by default", func(t *testing.T) {
+		cfg := NewCfg()
+		require.NoError(t, cfg.ReadUnifiedAlertingSettings(ini.Empty()))
+		require.True(t, cfg.UnifiedAlerting.EvaluationLimits.IsUnlimited())
+	})
+
+	t.Run("should read defaults and overrides", func(t *testing.T) {
+		f, err := ini.Load([]byte(`
+[unified_alerting.evaluation_limits]
+datasource_max_concurrent = 10
+datasource_rate = 5
+folder_max_concurrent = 20
+
+[unified_alerting.evaluation_limits.datasources]
+slow-es = max_concurrent=2 rate=0.5 burst=3
+no-rate = rate=0
+
+[unified_alerting.evaluation_limits.folders]
+big-folder = rate=1
+`))
+		require.NoError(t, err)
+
+		cfg := NewCfg()
+		require.NoError(t, cfg.ReadUnifiedAlertingSettings(f))
+		limits := cfg.UnifiedAlerting.EvaluationLimits
+		require.False(t, limits.IsUnlimited())
+		require.Equal(t, EvaluationLimit{MaxConcurrent: 10, Rate: 5, Burst: 1}, limits.Datasource)
+		require.Equal(t, EvaluationLimit{MaxConcurrent: 20}, limits.Folder)
+		require.Equal(t, map[string]EvaluationLimit{
+			"slow-es": {MaxConcurrent: 2, Rate: 0.5, Burst: 3},
+			"no-rate": {MaxConcurrent: 10, Rate: 0, Burst: 1},
+		}, limits.Datasources)
+		require.Equal(t, map[string]EvaluationLimit{
+			"big-folder": {MaxConcurrent: 20, Rate: 1, Burst: 1},
+		}, limits.Folders)
+	})
+
+	t.Run("should fail if a limit is invalid", func(t *testing.T) {
+		testCases := map[string]string{
+			"negative default":  "[unified_alerting.evaluation_limits]\nfolder_rate = -1",
+			"negative override": "[unified_alerting.evaluation_limits.folders]\nuid = burst=-1",
+			"unknown key":       "[unified_alerting.evaluation_limits.datasources]\nuid = limit=1",
+			"missing value":     "[unified_alerting.evaluation_limits.datasources]\nuid = max_concurrent",
+			"invalid number":    "[unified_alerting.evaluation_limits.datasources]\nuid = rate=fast",
+		}
+		for name, config := range testCases {
+			t.Run(name, func(t *testing.T) {
+				f, err := ini.Load([]byte(config))
+				require.NoError(t, err)
+				require.Error(t, NewCfg().ReadUnifiedAlertingSettings(f))
+			})
+		}
+	})
+}
+
+func TestRecordingRulesSettings(t *testing.T) {
+	testCases := map[string]string{
+		"zero max attempts":   "[recording_rules]\nmax_attempts = 0",
+		"negative batch size": "[recording_rules]\nbatch_size = -1",
+		"zero backfill rate":  "[recording_rules]\nbackfill_rate = 0",
+	}
+	for name, config := range testCases {
+		t.Run(name, func(t *testing.T) {
+			f, err := ini.Load([]byte(config))
+			require.NoError(t, err)
+			require.Error(t, NewCfg().ReadUnifiedAlertingSettings(f))
+		})
+	}
+}
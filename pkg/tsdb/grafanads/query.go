@@ -14,6 +14,9 @@ const (
 	// currently only .csv files are supported,
 	// other file types will eventually be supported (parquet, etc)
 	queryTypeRead = "read"
+
+	// queryTypeRecordedMetrics returns the samples that recording rules wrote to the Grafana database
+	queryTypeRecordedMetrics = "recordedMetrics"
 )
 
 type listQueryModel struct {
@@ -22,3 +25,8 @@ type listQueryModel struct {
 type readQueryModel struct {
 	Path string `json:"path"`
 }
+
+type recordedMetricsQueryModel struct {
+	Metric string            `json:"metric"`
+	Labels map[string]string `json:"labels,omitempty"`
+}